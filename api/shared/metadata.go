@@ -0,0 +1,95 @@
+package shared
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+
+	"github.com/romberli/das/api/v1/auth"
+	"github.com/romberli/das/internal/app/audit"
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/das/pkg/request"
+	"github.com/romberli/das/pkg/resp"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+const (
+	// CascadeJSON is the query parameter which specifies if the metadata which reference the deleted one are deleted as well
+	CascadeJSON = "cascade"
+	// ETagHeader is the response header which contains the version of the metadata
+	ETagHeader = "ETag"
+	// IfMatchHeader is the request header which contains the versions that the metadata to update or delete must match
+	IfMatchHeader = "If-Match"
+)
+
+// GetCascade returns the value of the cascade query parameter, it is false if the parameter is not specified
+func GetCascade(c *gin.Context) (bool, error) {
+	cascadeStr := c.Query(CascadeJSON)
+	if cascadeStr == constant.EmptyString {
+		return false, nil
+	}
+
+	return strconv.ParseBool(cascadeStr)
+}
+
+// NewAuditor returns an auditor which records the metadata changes made by the request,
+// the actor is the authenticated user, it is anonymous if the request is not authenticated
+func NewAuditor(c *gin.Context) *audit.Auditor {
+	actor := auth.GetAccountName(c)
+	if actor == constant.EmptyString {
+		actor = audit.AnonymousActor
+	}
+
+	return audit.NewAuditorWithGlobal(actor, tracing.GetRequestID(c.Request.Context()))
+}
+
+// SetETag sets the ETag header of the response with the last update time of the metadata
+func SetETag(c *gin.Context, lastUpdateTime time.Time) {
+	c.Header(ETagHeader, metadata.GetETag(lastUpdateTime))
+}
+
+// blockedResponse is the data of the response when the metadata could not be deleted or undeleted because of the blockers
+type blockedResponse struct {
+	Blockers []*metadata.Blocker `json:"blockers"`
+}
+
+// ResponseNOK responses with the http status of the error if the metadata is not valid, does not exist,
+// was modified by others, already exists, does not match the If-Match header, or is blocked by the other metadata,
+// the message of given code is wrapped, and the field errors or the blockers are responded as the data,
+// otherwise, it responses with the http status of the code
+func ResponseNOK(c *gin.Context, err error, code int, values ...interface{}) {
+	msg := message.NewMessage(code, values...).Error()
+
+	var ce *metadata.ConflictError
+	var be *metadata.BlockedError
+	var ve *request.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		request.ResponseNOK(c, ve)
+	case errors.As(err, &be):
+		data, marshalErr := json.Marshal(&blockedResponse{Blockers: be.Blockers})
+		if marshalErr != nil {
+			resp.ResponseNOK(c, message.ErrDataConflict, msg)
+			return
+		}
+		resp.ResponseNOKWithData(c, string(data), message.ErrDataConflict, msg)
+	case errors.As(err, &ce) && ce.ETag != constant.EmptyString:
+		c.Header(ETagHeader, ce.ETag)
+		resp.ResponseNOK(c, message.ErrDataNotMatch, msg)
+	case errors.As(err, &ce), metadata.IsDuplicateKey(err):
+		resp.ResponseNOK(c, message.ErrDataConflict, msg)
+	case errors.Is(err, metadata.ErrDataNotExists):
+		resp.ResponseNOK(c, message.ErrDataNotExists, msg)
+	case errors.Is(err, metadata.ErrNotValidStateTransition):
+		resp.ResponseNOK(c, message.ErrDataConflict, msg)
+	case errors.Is(err, metadata.ErrNotValidQuery):
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, msg)
+	default:
+		resp.ResponseNOK(c, code, values...)
+	}
+}
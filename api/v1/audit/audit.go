@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/internal/app/audit"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/message"
+	msgaudit "github.com/romberli/das/pkg/message/audit"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	idJSON         = "id"
+	entityTypeJSON = "entity_type"
+)
+
+// @Tags audit
+// @Summary get the audit records of the metadata changes which match the filters, sorted and paginated, the latest records come first by default
+// @Produce  application/json
+// @Param field query string false "filter by any field, such as actor=admin, entity_type=mysql_server, action=update,delete and create_time_ge=2021-07-01"
+// @Param sort query string false "sort by the fields, such as -id, \"-\" means descending"
+// @Param limit query int false "max number of the returned records, 0 means no limit"
+// @Param offset query int false "number of the skipped records"
+// @Param cursor query int false "id of the last record of the previous page, could not be used with offset"
+// @Success 200 {string} string "{"code": 200, "data": {"records": [{"id": 1, "entity_type": "mysql_server", "entity_id": 1, "action": "update", "actor": "admin", "request_id": "", "before": {...}, "after": {...}, "create_time": "2021-07-01T10:00:00+08:00"}], "page": {...}}}"
+// @Router /api/v1/audit [get]
+func GetAudit(c *gin.Context) {
+	// get params
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
+	// init service
+	s := audit.NewServiceWithDefault()
+	// get records
+	err = s.GetByQuery(c.Request.Context(), query)
+	if err != nil {
+		resp.ResponseNOK(c, msgaudit.ErrAuditGetAll, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgaudit.DebugAuditGetAll, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgaudit.InfoAuditGetAll)
+}
+
+// @Tags audit
+// @Summary get the change history of the metadata entity, the latest records come first by default
+// @Produce  application/json
+// @Param entity_type path string true "entity type: app, db, env, middleware_cluster, middleware_server, monitor_system, mysql_cluster, mysql_server or user"
+// @Param id path int true "entity id"
+// @Param field query string false "filter by any field, such as actor=admin and action=update"
+// @Param sort query string false "sort by the fields, such as -id, \"-\" means descending"
+// @Param limit query int false "max number of the returned records, 0 means no limit"
+// @Param offset query int false "number of the skipped records"
+// @Param cursor query int false "id of the last record of the previous page, could not be used with offset"
+// @Success 200 {string} string "{"code": 200, "data": {"records": [{"id": 1, "entity_type": "mysql_server", "entity_id": 1, "action": "update", "actor": "admin", "request_id": "", "before": {...}, "after": {...}, "create_time": "2021-07-01T10:00:00+08:00"}], "page": {...}}}"
+// @Router /api/v1/audit/:entity_type/:id [get]
+func GetAuditByEntity(c *gin.Context) {
+	// get params
+	entityType := c.Param(entityTypeJSON)
+	if entityType == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, entityTypeJSON)
+		return
+	}
+	idStr := c.Param(idJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, idJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
+	// init service
+	s := audit.NewServiceWithDefault()
+	// get records
+	err = s.GetByEntity(c.Request.Context(), entityType, id, query)
+	if err != nil {
+		resp.ResponseNOK(c, msgaudit.ErrAuditGetByEntity, entityType, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgaudit.DebugAuditGetByEntity, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgaudit.InfoAuditGetByEntity, entityType, id)
+}
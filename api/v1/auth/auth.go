@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/internal/app/auth"
+	"github.com/romberli/das/pkg/message"
+	msgauth "github.com/romberli/das/pkg/message/auth"
+	"github.com/romberli/das/pkg/request"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	idJSON          = "id"
+	accountNameJSON = "account_name"
+	passwordJSON    = "password"
+	descriptionJSON = "description"
+	expirationJSON  = "expiration"
+)
+
+// loginRequest is the request body of login
+type loginRequest struct {
+	AccountName string `json:"account_name" validate:"required,max=100"`
+	Password    string `json:"password" validate:"required"`
+}
+
+// tokenRequest is the request body of creating an api token, the body could be empty
+type tokenRequest struct {
+	Description string `json:"description" validate:"max=100"`
+	Expiration  *int   `json:"expiration" validate:"omitempty,gt=0"`
+}
+
+// @Tags auth
+// @Summary bind to the ldap server as the user and issue a json web token, the user must exist in the user metadata with the same account name
+// @Accept application/json
+// @Produce application/json
+// @Param account_name body string true "account name"
+// @Param password body string true "ldap password"
+// @Success 200 {string} string "{"code": 209001, "message": "DAS-209001: auth: login completed. account name: zhangs", "data": {"token": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...", "tokens": [{"id": 1, "user_id": 1, "token_type": 2, "description": "login", "expire_time": "2021-07-02T10:00:00+08:00", "del_flag": 0, ...}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/auth/login [post]
+func Login(c *gin.Context) {
+	// bind request
+	req := &loginRequest{}
+	err := request.Bind(c, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	accountName := req.AccountName
+	// init service
+	s := auth.NewServiceWithDefault()
+	// login
+	err = s.Login(c.Request.Context(), accountName, req.Password)
+	if err == auth.ErrInvalidCredentials {
+		resp.ResponseNOK(c, msgauth.ErrAuthUnauthenticated, message.NewMessage(msgauth.ErrAuthLogin, accountName, err.Error()).Error())
+		return
+	}
+	if err != nil {
+		resp.ResponseNOK(c, msgauth.ErrAuthLogin, accountName, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalWithFields(auth.TokenStruct, auth.TokensStruct)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response, the token must not be logged
+	log.Debug(message.NewMessage(msgauth.DebugAuthLogin, accountName).Error())
+	resp.ResponseOK(c, string(jsonBytes), msgauth.InfoAuthLogin, accountName)
+}
+
+// @Tags auth
+// @Summary issue an api token to the authenticated user, the token is only returned once
+// @Accept application/json
+// @Produce application/json
+// @Param Authorization header string true "Bearer token"
+// @Param description body string false "description of the token"
+// @Param expiration body int false "the token expires after this value of seconds, default is 7776000(90 days)"
+// @Success 200 {string} string "{"code": 209002, "message": "DAS-209002: auth: create token completed. account name: zhangs", "data": {"token": "das_0123456789abcdef...", "tokens": [{"id": 2, "user_id": 1, "token_type": 1, "description": "ci", "expire_time": "2021-09-30T10:00:00+08:00", "del_flag": 0, ...}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/auth/token [post]
+func CreateToken(c *gin.Context) {
+	// get params
+	accountName, ok := RequireAccountName(c)
+	if !ok {
+		return
+	}
+	// bind request
+	req := &tokenRequest{}
+	err := request.Bind(c, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	description := req.Description
+	expiration := auth.DefaultAPITokenExpiration
+	if req.Expiration != nil {
+		expiration = *req.Expiration
+	}
+	// init service
+	s := auth.NewServiceWithDefault()
+	// create token
+	err = s.CreateToken(c.Request.Context(), accountName, description, expiration)
+	if err != nil {
+		resp.ResponseNOK(c, msgauth.ErrAuthCreateToken, accountName, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalWithFields(auth.TokenStruct, auth.TokensStruct)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response, the token must not be logged
+	log.Debug(message.NewMessage(msgauth.DebugAuthCreateToken, accountName).Error())
+	resp.ResponseOK(c, string(jsonBytes), msgauth.InfoAuthCreateToken, accountName)
+}
+
+// @Tags auth
+// @Summary get the tokens of the authenticated user, the revoked and expired tokens are also returned
+// @Produce application/json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {string} string "{"code": 209003, "message": "DAS-209003: auth: get tokens completed. account name: zhangs", "data": {"tokens": [{"id": 2, "user_id": 1, "token_type": 1, "description": "ci", "expire_time": "2021-09-30T10:00:00+08:00", "del_flag": 0, ...}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/auth/token [get]
+func GetTokens(c *gin.Context) {
+	// get params
+	accountName, ok := RequireAccountName(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := auth.NewServiceWithDefault()
+	// get tokens
+	err := s.GetByAccountName(c.Request.Context(), accountName)
+	if err != nil {
+		resp.ResponseNOK(c, msgauth.ErrAuthGetTokens, accountName, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgauth.DebugAuthGetTokens, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgauth.InfoAuthGetTokens, accountName)
+}
+
+// @Tags auth
+// @Summary revoke the token of the authenticated user, both api tokens and json web tokens could be revoked
+// @Produce application/json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "token id"
+// @Success 200 {string} string "{"code": 209004, "message": "DAS-209004: auth: revoke token completed. account name: zhangs, id: 1", "data": {"tokens": [...]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/auth/token/revoke/:id [post]
+func RevokeToken(c *gin.Context) {
+	// get params
+	accountName, ok := RequireAccountName(c)
+	if !ok {
+		return
+	}
+	idStr := c.Param(idJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, idJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := auth.NewServiceWithDefault()
+	// revoke token
+	err = s.RevokeToken(c.Request.Context(), accountName, id)
+	if err != nil {
+		resp.ResponseNOK(c, msgauth.ErrAuthRevokeToken, accountName, id, err.Error())
+		return
+	}
+	err = s.GetByAccountName(c.Request.Context(), accountName)
+	if err != nil {
+		resp.ResponseNOK(c, msgauth.ErrAuthGetTokens, accountName, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgauth.DebugAuthRevokeToken, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgauth.InfoAuthRevokeToken, accountName, id)
+}
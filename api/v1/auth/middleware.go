@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+	"github.com/spf13/viper"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/auth"
+	"github.com/romberli/das/pkg/message"
+	msgauth "github.com/romberli/das/pkg/message/auth"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	// AccountNameKey is the key of the gin context which stores the account name of the authenticated user
+	AccountNameKey      = "das_account_name"
+	authorizationHeader = "Authorization"
+	wwwAuthenticate     = "WWW-Authenticate"
+	bearerScheme        = "Bearer"
+)
+
+// Authenticate returns a middleware which authenticates the token of the request,
+// it does nothing if the authentication is disabled
+func Authenticate() gin.HandlerFunc {
+	if !viper.GetBool(config.AuthEnabledKey) {
+		log.Warn("authentication of the http api is disabled, anyone could access the api")
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	chain, initErr := auth.NewChainWithGlobal()
+	if initErr != nil {
+		// refuse all the requests rather than running without authentication
+		log.Errorf("init authenticators failed, all the requests will be refused.\n%s", initErr.Error())
+	}
+
+	return func(c *gin.Context) {
+		if initErr != nil {
+			unauthenticated(c, initErr)
+			return
+		}
+
+		accountName, err := chain.Authenticate(c.Request.Context(), getBearerToken(c))
+		if err != nil {
+			unauthenticated(c, err)
+			return
+		}
+
+		c.Set(AccountNameKey, accountName)
+		c.Next()
+	}
+}
+
+// Authorize returns a middleware which checks if the authenticated user is allowed to access the route,
+// it must be used after the authentication middleware, and it does nothing if the authentication is disabled
+func Authorize() gin.HandlerFunc {
+	if !viper.GetBool(config.AuthEnabledKey) {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	authorizer := auth.NewAuthorizerWithDefault()
+
+	return func(c *gin.Context) {
+		params := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = param.Value
+		}
+
+		err := authorizer.Authorize(c.Request.Context(), GetAccountName(c), c.Request.Method, c.FullPath(), params)
+		if err != nil {
+			if _, ok := err.(*auth.ForbiddenError); ok {
+				resp.ResponseNOK(c, msgauth.ErrAuthForbidden, err.Error())
+			} else {
+				resp.ResponseNOK(c, msgauth.ErrAuthAuthorize, err.Error())
+			}
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetAccountName returns the account name of the authenticated user, it returns empty string if the request is not authenticated
+func GetAccountName(c *gin.Context) string {
+	return c.GetString(AccountNameKey)
+}
+
+// getBearerToken returns the token of the Authorization header with Bearer scheme
+func getBearerToken(c *gin.Context) string {
+	fields := strings.Fields(c.GetHeader(authorizationHeader))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], bearerScheme) {
+		return constant.EmptyString
+	}
+
+	return fields[1]
+}
+
+// unauthenticated responses 401 and aborts the request
+func unauthenticated(c *gin.Context, err error) {
+	c.Header(wwwAuthenticate, bearerScheme)
+	resp.ResponseNOK(c, msgauth.ErrAuthUnauthenticated, err.Error())
+	c.Abort()
+}
+
+// RequireAccountName returns the account name of the authenticated user,
+// it responses 401 and returns false if the request is not authenticated, which happens when the authentication is disabled
+func RequireAccountName(c *gin.Context) (string, bool) {
+	accountName := GetAccountName(c)
+	if accountName == constant.EmptyString {
+		unauthenticated(c, message.NewMessage(msgauth.ErrAuthNotEnabled))
+		return constant.EmptyString, false
+	}
+
+	return accountName, true
+}
@@ -0,0 +1,127 @@
+package discovery
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/internal/app/discovery"
+	"github.com/romberli/das/pkg/message"
+	msgdiscovery "github.com/romberli/das/pkg/message/discovery"
+	"github.com/romberli/das/pkg/request"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	monitorSystemIDJSON = "monitor_system_id"
+)
+
+// seedRequest is the request body of discovering by seed, dry run is true if it is not specified,
+// the keys of service names are the addresses(host_ip:port_num) of the mysql servers
+type seedRequest struct {
+	HostIP          string            `json:"host_ip" validate:"required,ip"`
+	PortNum         int               `json:"port_num" validate:"required,min=1,max=65535"`
+	ClusterName     string            `json:"cluster_name" validate:"max=100"`
+	EnvID           int               `json:"env_id" validate:"gte=0"`
+	MonitorSystemID int               `json:"monitor_system_id" validate:"gte=0"`
+	DeploymentType  int               `json:"deployment_type" validate:"gte=0"`
+	ServiceNames    map[string]string `json:"service_names" validate:"dive,keys,hostname_port,endkeys,required,max=100"`
+	DryRun          *bool             `json:"dry_run"`
+}
+
+// monitorSystemRequest is the request body of discovering by monitor system, dry run is true if it is not specified
+type monitorSystemRequest struct {
+	DryRun *bool `json:"dry_run"`
+}
+
+// isDryRun returns true if dry run is not specified
+func isDryRun(dryRun *bool) bool {
+	return dryRun == nil || *dryRun
+}
+
+// @Tags discovery
+// @Summary discover the mysql cluster, mysql servers and databases from the seed mysql server
+// @Accept  application/json
+// @Produce  application/json
+// @Param body body string true "{"host_ip": "192.168.137.11", "port_num": 3306, "cluster_name": "cluster1", "env_id": 1, "monitor_system_id": 1, "deployment_type": 2, "service_names": {"192.168.137.11:3306": "mysql-11"}, "dry_run": true}"
+// @Success 200 {string} string "{"code": 205001, "message": "DAS-205001: discovery: discover by seed completed. host ip: 192.168.137.11, port num: 3306, dry run: true", "data": {"plans": [{"seed": "192.168.137.11:3306", "dry_run": true, "applied": false, "mysql_cluster": {"action": "create", ...}, "mysql_servers": [...], "dbs": [...], "warnings": []}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/discovery/seed [post]
+func DiscoverBySeed(c *gin.Context) {
+	// bind request
+	req := &seedRequest{}
+	err := request.Bind(c, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	dryRun := isDryRun(req.DryRun)
+	// init service
+	s := discovery.NewServiceWithDefault()
+	// discover
+	err = s.DiscoverBySeed(c.Request.Context(), req.HostIP, req.PortNum, req.ClusterName, req.EnvID, req.MonitorSystemID, req.DeploymentType,
+		req.ServiceNames, dryRun)
+	if err != nil {
+		resp.ResponseNOK(c, msgdiscovery.ErrDiscoveryDiscoverBySeed, req.HostIP, req.PortNum, dryRun, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgdiscovery.DebugDiscoveryDiscoverBySeed, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgdiscovery.InfoDiscoveryDiscoverBySeed, req.HostIP, req.PortNum, dryRun)
+}
+
+// @Tags discovery
+// @Summary discover all the registered mysql clusters which are monitored by the monitor system
+// @Accept  application/json
+// @Produce  application/json
+// @Param monitor_system_id path int true "monitor system id"
+// @Param body body string false "{"dry_run": true}"
+// @Success 200 {string} string "{"code": 205002, "message": "DAS-205002: discovery: discover by monitor system completed. monitor system id: 1, dry run: true", "data": {"plans": [{"seed": "192.168.137.11:3306", "dry_run": true, "applied": false, "mysql_cluster": {"action": "none", ...}, "mysql_servers": [...], "dbs": [...], "warnings": []}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/discovery/monitor-system/:monitor_system_id [post]
+func DiscoverByMonitorSystem(c *gin.Context) {
+	// get params
+	idStr := c.Param(monitorSystemIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, monitorSystemIDJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// bind request, the body is optional
+	req := &monitorSystemRequest{}
+	err = request.Bind(c, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	dryRun := isDryRun(req.DryRun)
+	// init service
+	s := discovery.NewServiceWithDefault()
+	// discover
+	err = s.DiscoverByMonitorSystem(c.Request.Context(), id, dryRun)
+	if err != nil {
+		resp.ResponseNOK(c, msgdiscovery.ErrDiscoveryDiscoverByMonitorSystem, id, dryRun, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgdiscovery.DebugDiscoveryDiscoverByMonitorSystem, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgdiscovery.InfoDiscoveryDiscoverByMonitorSystem, id, dryRun)
+}
@@ -1,7 +1,7 @@
 package healthcheck
 
 import (
-	"encoding/json"
+	"errors"
 	"strconv"
 	"time"
 
@@ -9,6 +9,7 @@ import (
 	"github.com/romberli/das/internal/app/healthcheck"
 	"github.com/romberli/das/pkg/message"
 	msghealth "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/das/pkg/request"
 	"github.com/romberli/das/pkg/resp"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/log"
@@ -16,20 +17,37 @@ import (
 
 const (
 	operationIDJSON = "operation_id"
-	serverIDJSON    = "server_id"
-	hostIPJSON      = "host_ip"
-	portNumJSON     = "port_num"
-	startTimeJSON   = "start_time"
-	endTimeJSON     = "end_time"
-	stepJSON        = "step"
-	reviewJSON      = "review"
 )
 
+// checkRequest is the request body of checking health of the mysql server,
+// the numbers are specified as strings to be compatible with the old clients
+type checkRequest struct {
+	ServerID  string `json:"server_id" validate:"required,numeric"`
+	StartTime string `json:"start_time" validate:"required"`
+	EndTime   string `json:"end_time" validate:"required"`
+	Step      string `json:"step" validate:"required"`
+}
+
+// checkByHostInfoRequest is the request body of checking health of the mysql server by host ip and port number
+type checkByHostInfoRequest struct {
+	HostIP    string `json:"host_ip" validate:"required,ip"`
+	PortNum   string `json:"port_num" validate:"required,numeric"`
+	StartTime string `json:"start_time" validate:"required"`
+	EndTime   string `json:"end_time" validate:"required"`
+	Step      string `json:"step" validate:"required"`
+}
+
+// reviewAccurateRequest is the request body of reviewing the accurate of the healthcheck result
+type reviewAccurateRequest struct {
+	OperationID int  `json:"operation_id" validate:"required,gt=0"`
+	Review      *int `json:"review" validate:"required"`
+}
+
 // @Tags healthcheck
 // @Summary get result by operation id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": []}"
-// @Router /api/v1/healthcheck/result/:id [get]
+// @Success 200 {string} string "{"code": 201001, "message": "DAS-201001: healthcheck: get result by operation id completed. operation_id: 1", "data": {"id": 1, "operation_id": 1, "weighted_average_score": 100, "db_config_score": 100, "db_config_data": "...", "db_config_advice": "...", ...}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/healthcheck/result/:operation_id [get]
 func GetResultByOperationID(c *gin.Context) {
 	// get data
 	operationIDStr := c.Param(operationIDJSON)
@@ -45,9 +63,13 @@ func GetResultByOperationID(c *gin.Context) {
 	// init service
 	s := healthcheck.NewServiceWithDefault()
 	// get entities
-	err = s.GetResultByOperationID(operationID)
+	err = s.GetResultByOperationID(c.Request.Context(), operationID)
 	if err != nil {
-		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetResultByOperationID)
+		if errors.Is(err, healthcheck.ErrDataNotExists) {
+			resp.ResponseNOK(c, message.ErrDataNotExists, message.NewMessage(msghealth.ErrHealthcheckGetResultByOperationID, operationID, err.Error()).Error())
+			return
+		}
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetResultByOperationID, operationID, err.Error())
 		return
 	}
 	// marshal service
@@ -65,65 +87,40 @@ func GetResultByOperationID(c *gin.Context) {
 // @Tags healthcheck
 // @Summary check health of the database
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": "healthcheck started.}"
+// @Success 200 {string} string "{"code": 201002, "message": "DAS-201002: healthcheck: check completed.", "data": "healthcheck started", "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/healthcheck/check [post]
 func Check(c *gin.Context) {
-	// get data
-	data, err := c.GetRawData()
+	// bind request
+	req := &checkRequest{}
+	err := request.Bind(c, req)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		request.ResponseNOK(c, err)
 		return
 	}
-	dataMap := make(map[string]string)
-	err = json.Unmarshal(data, &dataMap)
+	mysqlServerID, err := strconv.Atoi(req.ServerID)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	mysqlServerIDStr, mysqlServerIDExists := dataMap[serverIDJSON]
-	if !mysqlServerIDExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, serverIDJSON)
-		return
-	}
-	mysqlServerID, err := strconv.Atoi(mysqlServerIDStr)
-	if err != nil {
-		resp.ResponseNOK(c, msghealth.ErrHealthcheckCheck, err.Error())
-		return
-	}
-	startTimeStr, startTimeExists := dataMap[startTimeJSON]
-	if !startTimeExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, startTimeJSON)
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	startTime, err := time.ParseInLocation(constant.TimeLayoutSecond, startTimeStr, time.Local)
+	startTime, err := time.ParseInLocation(constant.TimeLayoutSecond, req.StartTime, time.Local)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, startTimeStr)
-		return
-	}
-	endTimeStr, endTimeExists := dataMap[endTimeJSON]
-	if !endTimeExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, endTimeJSON)
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, req.StartTime)
 		return
 	}
-	endTime, err := time.ParseInLocation(constant.TimeLayoutSecond, endTimeStr, time.Local)
+	endTime, err := time.ParseInLocation(constant.TimeLayoutSecond, req.EndTime, time.Local)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, endTimeStr)
-		return
-	}
-	stepStr, stepExists := dataMap[stepJSON]
-	if !stepExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, stepJSON)
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, req.EndTime)
 		return
 	}
-	step, err := time.ParseDuration(stepStr)
+	step, err := time.ParseDuration(req.Step)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrNotValidTimeDuration, step)
+		resp.ResponseNOK(c, message.ErrNotValidTimeDuration, req.Step)
 		return
 	}
 	// init service
 	s := healthcheck.NewServiceWithDefault()
 	// check health
-	err = s.Check(mysqlServerID, startTime, endTime, step)
+	err = s.Check(c.Request.Context(), mysqlServerID, startTime, endTime, step)
 	if err != nil {
 		resp.ResponseNOK(c, msghealth.ErrHealthcheckCheck)
 		return
@@ -136,67 +133,41 @@ func Check(c *gin.Context) {
 // @Tags healthcheck
 // @Summary check health of the database by host ip and port number
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": ""}"
+// @Success 200 {string} string "{"code": 201003, "message": "DAS-201003: healthcheck: check by host info completed.", "data": "healthcheck by host info started", "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/healthcheck/check/host-info [post]
 func CheckByHostInfo(c *gin.Context) {
-	// get data
-	data, err := c.GetRawData()
+	// bind request
+	req := &checkByHostInfoRequest{}
+	err := request.Bind(c, req)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		request.ResponseNOK(c, err)
 		return
 	}
-	dataMap := make(map[string]string)
-	err = json.Unmarshal(data, &dataMap)
-	if err != nil {
-		resp.ResponseNOK(c, msghealth.ErrHealthcheckCheck, err.Error())
-		return
-	}
-	hostIP, hostIPExists := dataMap[hostIPJSON]
-	if !hostIPExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, hostIPJSON)
-		return
-	}
-	portNumStr, portNumExists := dataMap[portNumJSON]
-	if !portNumExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, portNumJSON)
-		return
-	}
-	portNum, err := strconv.Atoi(portNumStr)
+	hostIP := req.HostIP
+	portNum, err := strconv.Atoi(req.PortNum)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	startTimeStr, startTimeExists := dataMap[startTimeJSON]
-	if !startTimeExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, startTimeJSON)
-		return
-	}
-	startTime, err := time.ParseInLocation(constant.TimeLayoutSecond, startTimeStr, time.Local)
+	startTime, err := time.ParseInLocation(constant.TimeLayoutSecond, req.StartTime, time.Local)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, startTimeStr)
-	}
-	endTimeStr, endTimeExists := dataMap[endTimeJSON]
-	if !endTimeExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, endTimeJSON)
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, req.StartTime)
 		return
 	}
-	endTime, err := time.ParseInLocation(constant.TimeLayoutSecond, endTimeStr, time.Local)
+	endTime, err := time.ParseInLocation(constant.TimeLayoutSecond, req.EndTime, time.Local)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, endTimeStr)
-	}
-	stepStr, stepExists := dataMap[stepJSON]
-	if !stepExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, stepJSON)
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, req.EndTime)
 		return
 	}
-	step, err := time.ParseDuration(stepStr)
+	step, err := time.ParseDuration(req.Step)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrNotValidTimeDuration, step)
+		resp.ResponseNOK(c, message.ErrNotValidTimeDuration, req.Step)
+		return
 	}
 	// init service
 	s := healthcheck.NewServiceWithDefault()
 	// get entities
-	err = s.CheckByHostInfo(hostIP, portNum, startTime, endTime, step)
+	err = s.CheckByHostInfo(c.Request.Context(), hostIP, portNum, startTime, endTime, step)
 	if err != nil {
 		resp.ResponseNOK(c, msghealth.ErrHealthcheckCheckByHostInfo)
 		return
@@ -209,35 +180,22 @@ func CheckByHostInfo(c *gin.Context) {
 // @Tags healthcheck
 // @Summary update accurate review
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": ""}"
+// @Success 200 {string} string "{"code": 201004, "message": "DAS-201004: healthcheck: review accurate completed.", "data": "reviewed accurate", "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/healthcheck/review [post]
 func ReviewAccurate(c *gin.Context) {
-	// get data
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	dataMap := make(map[string]int)
-	err = json.Unmarshal(data, &dataMap)
+	// bind request
+	req := &reviewAccurateRequest{}
+	err := request.Bind(c, req)
 	if err != nil {
-		resp.ResponseNOK(c, msghealth.ErrHealthcheckCheck, err.Error())
-		return
-	}
-	operationID, operationIDExists := dataMap[operationIDJSON]
-	if !operationIDExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, operationIDJSON)
-		return
-	}
-	review, reviewExists := dataMap[reviewJSON]
-	if !reviewExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, reviewJSON)
+		request.ResponseNOK(c, err)
 		return
 	}
+	operationID := req.OperationID
+	review := *req.Review
 	// init service
 	s := healthcheck.NewServiceWithDefault()
 	// review accurate
-	err = s.ReviewAccurate(operationID, review)
+	err = s.ReviewAccurate(c.Request.Context(), operationID, review)
 	if err != nil {
 		resp.ResponseNOK(c, msghealth.ErrHealthcheckReviewAccurate)
 		return
@@ -1,18 +1,18 @@
 package metadata
 
 import (
-	"encoding/json"
-	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/log"
 
+	"github.com/romberli/das/api/shared"
 	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 	msgmeta "github.com/romberli/das/pkg/message/metadata"
+	"github.com/romberli/das/pkg/request"
 	"github.com/romberli/das/pkg/resp"
 )
 
@@ -25,24 +25,56 @@ const (
 	appLevelStruct    = "Level"
 	appDelFlagStruct  = "DelFlag"
 	appDBIDListStruct = "DBIDList"
+	appAppsStruct     = "Apps"
 )
 
+// addAppRequest is the request body of adding an app, the level is one of 1(A), 2(B) and 3(C)
+type addAppRequest struct {
+	AppName *string `json:"app_name" validate:"required,min=1,max=100"`
+	Level   *int    `json:"level" validate:"required,oneof=1 2 3"`
+	OwnerID *int    `json:"owner_id" validate:"omitempty,gte=0"`
+}
+
+// updateAppRequest is the request body of updating an app, only the specified fields are updated
+type updateAppRequest struct {
+	AppName *string `json:"app_name" validate:"omitempty,min=1,max=100"`
+	Level   *int    `json:"level" validate:"omitempty,oneof=1 2 3"`
+	OwnerID *int    `json:"owner_id" validate:"omitempty,gte=0"`
+	DelFlag *int    `json:"del_flag" validate:"omitempty,oneof=0 1"`
+}
+
+// appDBRequest is the request body of adding or deleting the map of the app and the db
+type appDBRequest struct {
+	DBID int `json:"db_id" validate:"required,gt=0"`
+}
+
 // @Tags application
-// @Summary get all applications
+// @Summary get applications which match the filters, sorted and paginated
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 66, "system_name": "kkk", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00", "level": 8,"owner_id": 8,"owner_group": "k"}]}"
+// @Param field query string false "filter by any field, such as field=value, field=v1,v2, field_like=value, field_ge=value and field_le=value"
+// @Param sort query string false "sort by the fields, such as -id,create_time, \"-\" means descending"
+// @Param limit query int false "max number of the returned applications, 0 means no limit"
+// @Param offset query int false "number of the skipped applications"
+// @Param cursor query int false "id of the last application of the previous page, could not be used with offset"
+// @Success 200 {string} string "{"code": 200101, "message": "DAS-200101: metadata: get app all completed.", "data": {"apps": [{"id": 1, "app_name": "app1", "level": 1, "owner_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}], "page": {"total": 1, "limit": 0, "offset": 0, "next_cursor": 0}}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/app [get]
 func GetApp(c *gin.Context) {
+	// get params
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewAppServiceWithDefault()
 	// get entities
-	err := s.GetAll()
+	err = s.GetByQuery(c.Request.Context(), query)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetAppAll, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetAppAll, err.Error())
 		return
 	}
 	// marshal service
-	jsonBytes, err := s.Marshal()
+	jsonBytes, err := s.MarshalWithFields(appAppsStruct, pageStruct)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
@@ -56,8 +88,9 @@ func GetApp(c *gin.Context) {
 // @Tags application
 // @Summary get application by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{// @Success 200 {string} string "{"code": 200, "data": [{"id": 66, "system_name": "kkk", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00", "level": 8,"owner_id": 8,"owner_group": "k"}]}"
-// @Router /api/v1/metadata/app/:id [get]
+// @Header 200 {string} ETag "the version of the entity, specify it in the If-Match header when updating or deleting the entity"
+// @Success 200 {string} string "{"code": 200102, "message": "DAS-200102: metadata: get app by id completed. id: 1", "data": {"apps": [{"id": 1, "app_name": "app1", "level": 1, "owner_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/app/get/:id [get]
 func GetAppByID(c *gin.Context) {
 	// get param
 	idStr := c.Param(appIDJSON)
@@ -73,11 +106,12 @@ func GetAppByID(c *gin.Context) {
 	// init service
 	s := metadata.NewAppServiceWithDefault()
 	// get entity
-	err = s.GetByID(id)
+	err = s.GetByID(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetAppByID, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetAppByID, id, err.Error())
 		return
 	}
+	shared.SetETag(c, s.GetApps()[constant.ZeroInt].GetLastUpdateTime())
 	// marshal service
 	jsonBytes, err := s.Marshal()
 	if err != nil {
@@ -93,8 +127,8 @@ func GetAppByID(c *gin.Context) {
 // @Tags application
 // @Summary get application by system name
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 66, "app_name": "kkk", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00", "level": 8,"owner_id": 8,"owner_group": "k"}]}"
-// @Router /api/v1/metadata/app/app-name/:name [get]
+// @Success 200 {string} string "{"code": 200103, "message": "DAS-200103: metadata: get app by name completed. app_name: app1", "data": {"apps": [{"id": 1, "app_name": "app1", "level": 1, "owner_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/app/app-name/:app_name [get]
 func GetAppByName(c *gin.Context) {
 	// get params
 	appName := c.Param(appAppNameJSON)
@@ -105,9 +139,9 @@ func GetAppByName(c *gin.Context) {
 	// init service
 	s := metadata.NewAppServiceWithDefault()
 	// get entity
-	err := s.GetAppByName(appName)
+	err := s.GetAppByName(c.Request.Context(), appName)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetAppByName, appName, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetAppByName, appName, err.Error())
 		return
 	}
 	// marshal service
@@ -125,8 +159,8 @@ func GetAppByName(c *gin.Context) {
 // @Tags application
 // @Summary get db id list
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [1, 2]}"
-// @Router /api/vi/metadata/app/dbs/:id [get]
+// @Success 200 {string} string "{"code": 200104, "message": "DAS-200104: metadata: get db id list completed. id: 1", "data": {"db_id_list": [1, 2]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/app/dbs/:id [get]
 func GetDBIDList(c *gin.Context) {
 	// get params
 	idStr := c.Param(appIDJSON)
@@ -141,14 +175,12 @@ func GetDBIDList(c *gin.Context) {
 	// init service
 	s := metadata.NewAppServiceWithDefault()
 	// get entity
-	err = s.GetDBIDList(id)
+	err = s.GetDBIDList(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetDBIDList, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetDBIDList, id, err.Error())
 		return
 	}
 
-	b := s.DBIDList
-	fmt.Println(b)
 	// marshal service
 	jsonBytes, err := s.MarshalWithFields(appDBIDListStruct)
 	if err != nil {
@@ -164,37 +196,20 @@ func GetDBIDList(c *gin.Context) {
 // @Tags application
 // @Summary add a new application
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 66, "system_name": "kkk", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00", "level": 8,"owner_id": 8,"owner_group": "k"}]}"
+// @Success 200 {string} string "{"code": 200105, "message": "DAS-200105: metadata: add new app completed. app_name: app1", "data": {"apps": [{"id": 1, "app_name": "app1", "level": 1, "owner_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/app [post]
 func AddApp(c *gin.Context) {
-	var fields map[string]interface{}
-
-	// get data
-	data, err := c.GetRawData()
+	// bind request
+	fields, err := request.BindFields(c, &addAppRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.AppInfo{}, constant.DefaultMiddlewareTag)
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, ok := fields[appAppNameStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, appAppNameStruct)
-		return
-	}
-	_, ok = fields[appLevelStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, appLevelStruct)
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewAppServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// insert into middleware
-	err = s.Create(fields)
+	err = s.Create(c.Request.Context(), fields)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataAddApp, fields[appAppNameStruct], err.Error())
 		return
@@ -214,11 +229,10 @@ func AddApp(c *gin.Context) {
 // @Tags application
 // @Summary update application by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 66, "system_name": "kkk", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00", "level": 8,"owner_id": 8,"owner_group": "k"}]}"
-// @Router /api/v1/metadata/app/:id [post]
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200106, "message": "DAS-200106: metadata: update app completed. id: 1", "data": {"apps": [{"id": 1, "app_name": "app1", "level": 1, "owner_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/app/update/:id [post]
 func UpdateAppByID(c *gin.Context) {
-	var fields map[string]interface{}
-
 	// get params
 	idStr := c.Param(appIDJSON)
 	if idStr == constant.EmptyString {
@@ -230,30 +244,20 @@ func UpdateAppByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	data, err := c.GetRawData()
+	// bind request
+	fields, err := request.BindFields(c, &updateAppRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.AppInfo{}, constant.DefaultMiddlewareTag)
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, AppNameExists := fields[appAppNameStruct]
-	_, levelExists := fields[appLevelStruct]
-	_, delFlagExists := fields[appDelFlagStruct]
-	if !AppNameExists && !delFlagExists && !levelExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, fmt.Sprintf("%s and %s", appAppNameStruct, appDelFlagStruct))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewAppServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
 	// update entities
-	err = s.Update(id, fields)
+	err = s.Update(c.Request.Context(), id, fields)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataUpdateApp, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUpdateApp, id, err.Error())
 		return
 	}
 	// marshal service
@@ -271,7 +275,9 @@ func UpdateAppByID(c *gin.Context) {
 // @Tags application
 // @Summary delete app by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 66, "system_name": "kkk", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00", "level": 8,"owner_id": 8,"owner_group": "k"}]}"
+// @Param cascade query bool false "delete the metadata which reference the app as well, default is false"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200107, "message": "DAS-200107: metadata: delete app completed. id: 1", "data": {"apps": []}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/app/delete/:id [post]
 func DeleteAppByID(c *gin.Context) {
 	var fields map[string]interface{}
@@ -287,12 +293,23 @@ func DeleteAppByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
+	cascade, err := shared.GetCascade(c)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewAppServiceWithDefault()
-	// update entities
-	err = s.Delete(id)
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// delete entity
+	if cascade {
+		err = s.DeleteCascade(c.Request.Context(), id)
+	} else {
+		err = s.Delete(c.Request.Context(), id)
+	}
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataDeleteApp, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataDeleteApp, id, err.Error())
 		return
 	}
 	// marshal service
@@ -308,11 +325,12 @@ func DeleteAppByID(c *gin.Context) {
 }
 
 // @Tags application
-// @Summary add database map
+// @Summary undelete app by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 66, "system_name": "kkk", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00", "level": 8,"owner_id": 8,"owner_group": "k"}]}"
-// @Router /api/v1/metadata/app/add-db/:id [post]
-func AppAddDB(c *gin.Context) {
+// @Param id path int true "app id"
+// @Success 200 {string} string "{"code": 200110, "message": "DAS-200110: metadata: undelete app completed. id: 1", "data": {"apps": [{"id": 1, "app_name": "app1", "level": 1, "owner_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/app/undelete/:id [post]
+func UndeleteAppByID(c *gin.Context) {
 	// get params
 	idStr := c.Param(appIDJSON)
 	if idStr == constant.EmptyString {
@@ -324,27 +342,57 @@ func AppAddDB(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	data, err := c.GetRawData()
+	// init service
+	s := metadata.NewAppServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	// undelete entity
+	err = s.Undelete(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUndeleteApp, id, err.Error())
 		return
 	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataUndeleteApp, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataUndeleteApp, id)
+}
 
-	dataMap := make(map[string]int)
-	err = json.Unmarshal(data, &dataMap)
+// @Tags application
+// @Summary add database map
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200108, "message": "DAS-200108: metadata: add map of app and database completed. app_id: 1, db_id: 1", "data": {"db_id_list": [1, 2]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/app/add-db/:id [post]
+func AppAddDB(c *gin.Context) {
+	// get params
+	idStr := c.Param(appIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, appIDJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataAppAddDB, id, err.Error())
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	dbID, dbIDExists := dataMap[appDBIDJSON]
-	if !dbIDExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, appDBIDJSON)
+	// bind request
+	req := &appDBRequest{}
+	err = request.Bind(c, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
 		return
 	}
+	dbID := req.DBID
 	// init service
 	s := metadata.NewAppServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// update entities
-	err = s.AddDB(id, dbID)
+	err = s.AddDB(c.Request.Context(), id, dbID)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataAppAddDB, id, err.Error())
 		return
@@ -364,7 +412,7 @@ func AppAddDB(c *gin.Context) {
 // @Tags application
 // @Summary delete database map
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [1]}"
+// @Success 200 {string} string "{"code": 200109, "message": "DAS-200109: metadata: delete map of app and database completed. app_id: 1, db_id: 1", "data": {"db_id_list": [1]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/app/delete-db/:id [post]
 func AppDeleteDB(c *gin.Context) {
 	// get params
@@ -378,26 +426,19 @@ func AppDeleteDB(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	dataMap := make(map[string]int)
-	err = json.Unmarshal(data, &dataMap)
+	// bind request
+	req := &appDBRequest{}
+	err = request.Bind(c, req)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataAppDeleteDB, id, err.Error())
-		return
-	}
-	dbID, dbIDExists := dataMap[appDBIDJSON]
-	if !dbIDExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, appDBIDJSON)
+		request.ResponseNOK(c, err)
 		return
 	}
+	dbID := req.DBID
 	// init service
 	s := metadata.NewAppServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// update entities
-	err = s.DeleteDB(id, dbID)
+	err = s.DeleteDB(c.Request.Context(), id, dbID)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataAppDeleteDB, id, err.Error())
 		return
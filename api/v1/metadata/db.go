@@ -1,19 +1,19 @@
 package metadata
 
 import (
-	"encoding/json"
-	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/log"
 
+	"github.com/romberli/das/api/shared"
 	"github.com/romberli/das/internal/app/metadata"
 	msgmeta "github.com/romberli/das/pkg/message/metadata"
 
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/das/pkg/request"
 	"github.com/romberli/das/pkg/resp"
 )
 
@@ -31,24 +31,67 @@ const (
 	dbOwnerIDStruct     = "OwnerID"
 	dbEnvIDStruct       = "EnvID"
 	dbAppIDListStruct   = "AppIDList"
+	dbDBsStruct         = "DBs"
 )
 
+// addDBRequest is the request body of adding a db, the cluster type is one of 1(single) and 2(sharding)
+type addDBRequest struct {
+	DBName      *string `json:"db_name" validate:"required,min=1,max=100"`
+	ClusterID   *int    `json:"cluster_id" validate:"required,gt=0"`
+	ClusterType *int    `json:"cluster_type" validate:"required,oneof=1 2"`
+	OwnerID     *int    `json:"owner_id" validate:"omitempty,gte=0"`
+	EnvID       *int    `json:"env_id" validate:"required,gt=0"`
+}
+
+// updateDBRequest is the request body of updating a db, only the specified fields are updated
+type updateDBRequest struct {
+	DBName      *string `json:"db_name" validate:"omitempty,min=1,max=100"`
+	ClusterID   *int    `json:"cluster_id" validate:"omitempty,gt=0"`
+	ClusterType *int    `json:"cluster_type" validate:"omitempty,oneof=1 2"`
+	OwnerID     *int    `json:"owner_id" validate:"omitempty,gte=0"`
+	EnvID       *int    `json:"env_id" validate:"omitempty,gt=0"`
+	DelFlag     *int    `json:"del_flag" validate:"omitempty,oneof=0 1"`
+}
+
+// dbAppRequest is the request body of adding or deleting the map of the db and the app
+type dbAppRequest struct {
+	AppID int `json:"app_id" validate:"required,gt=0"`
+}
+
+// dbNameAndClusterInfoRequest is the request body of getting the db by the db name and the cluster info
+type dbNameAndClusterInfoRequest struct {
+	DBName      string `json:"db_name" validate:"required,max=100"`
+	ClusterID   int    `json:"cluster_id" validate:"required,gt=0"`
+	ClusterType int    `json:"cluster_type" validate:"required,oneof=1 2"`
+}
+
 // @Tags database
-// @Summary get all databases
+// @Summary get databases which match the filters, sorted and paginated
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Param field query string false "filter by any field, such as field=value, field=v1,v2, field_like=value, field_ge=value and field_le=value"
+// @Param sort query string false "sort by the fields, such as -id,create_time, \"-\" means descending"
+// @Param limit query int false "max number of the returned databases, 0 means no limit"
+// @Param offset query int false "number of the skipped databases"
+// @Param cursor query int false "id of the last database of the previous page, could not be used with offset"
+// @Success 200 {string} string "{"code": 200201, "message": "DAS-200201: metadata: get database all completed", "data": {"dbs": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}], "page": {"total": 1, "limit": 0, "offset": 0, "next_cursor": 0}}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/db [get]
 func GetDB(c *gin.Context) {
+	// get params
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewDBServiceWithDefault()
 	// get entities
-	err := s.GetAll()
+	err = s.GetByQuery(c.Request.Context(), query)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetDBAll, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetDBAll, err.Error())
 		return
 	}
 	// marshal service
-	jsonBytes, err := s.Marshal()
+	jsonBytes, err := s.MarshalWithFields(dbDBsStruct, pageStruct)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
@@ -62,7 +105,7 @@ func GetDB(c *gin.Context) {
 // @Tags database
 // @Summary get database by env_id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Success 200 {string} string "{"code": 200202, "message": "DAS-200202: metadata: get databases by environment completed. env_id: 1", "data": {"dbs": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/db/env/:env_id [get]
 func GetDBByEnv(c *gin.Context) {
 	// get param
@@ -80,9 +123,9 @@ func GetDBByEnv(c *gin.Context) {
 	// init service
 	s := metadata.NewDBServiceWithDefault()
 	// get entity
-	err = s.GetByEnv(envID)
+	err = s.GetByEnv(c.Request.Context(), envID)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetDBByEnv, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetDBByEnv, err.Error())
 		return
 	}
 	// marshal service
@@ -100,7 +143,8 @@ func GetDBByEnv(c *gin.Context) {
 // @Tags database
 // @Summary get database by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Header 200 {string} ETag "the version of the entity, specify it in the If-Match header when updating or deleting the entity"
+// @Success 200 {string} string "{"code": 200203, "message": "DAS-200203: metadata: get database by id completed. id: 1", "data": {"dbs": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/db/get/:id [get]
 func GetDBByID(c *gin.Context) {
 	// get param
@@ -117,11 +161,12 @@ func GetDBByID(c *gin.Context) {
 	// init service
 	s := metadata.NewDBServiceWithDefault()
 	// get entity
-	err = s.GetByID(id)
+	err = s.GetByID(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetDBByID, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetDBByID, id, err.Error())
 		return
 	}
+	shared.SetETag(c, s.GetDBs()[constant.ZeroInt].GetLastUpdateTime())
 	// marshal service
 	jsonBytes, err := s.Marshal()
 	if err != nil {
@@ -137,28 +182,22 @@ func GetDBByID(c *gin.Context) {
 // @Tags database
 // @Summary get database by db name and cluster info
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Success 200 {string} string "{"code": 200204, "message": "DAS-200204: metadata: get database by name and cluster info completed. db_name: db1, cluster_id: 1, cluster_type: 1", "data": {"dbs": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/db/name-and-cluster-info[get]
 func GetDBByNameAndClusterInfo(c *gin.Context) {
-	var dbInfo *metadata.DBInfo
-	// get data
-	data, err := c.GetRawData()
+	// bind request
+	dbInfo := &dbNameAndClusterInfoRequest{}
+	err := request.Bind(c, dbInfo)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	err = json.Unmarshal(data, dbInfo)
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewDBServiceWithDefault()
 	// get entity
-	err = s.GetByNameAndClusterInfo(dbInfo.DBName, dbInfo.ClusterID, dbInfo.ClusterType)
+	err = s.GetByNameAndClusterInfo(c.Request.Context(), dbInfo.DBName, dbInfo.ClusterID, dbInfo.ClusterType)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetDBByNameAndClusterInfo, dbInfo.DBName, dbInfo.ClusterID, dbInfo.ClusterType, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetDBByNameAndClusterInfo, dbInfo.DBName, dbInfo.ClusterID, dbInfo.ClusterType, err.Error())
 		return
 	}
 	// marshal service
@@ -176,7 +215,7 @@ func GetDBByNameAndClusterInfo(c *gin.Context) {
 // @Tags db
 // @Summary get app id list
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [1, 2]}"
+// @Success 200 {string} string "{"code": 200205, "message": "DAS-200205: metadata: get app id list completed. id: 1", "data": {"app_id_list": [1, 2]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/db/apps/:id [get]
 func GetAppIDList(c *gin.Context) {
 	// get params
@@ -192,9 +231,9 @@ func GetAppIDList(c *gin.Context) {
 	// init service
 	s := metadata.NewDBServiceWithDefault()
 	// get entity
-	err = s.GetAppIDList(id)
+	err = s.GetAppIDList(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetAppIDList, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetAppIDList, id, err.Error())
 		return
 	}
 	// marshal service
@@ -212,36 +251,20 @@ func GetAppIDList(c *gin.Context) {
 // @Tags database
 // @Summary add a new database
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Success 200 {string} string "{"code": 200206, "message": "DAS-200206: metadata: add new database completed. db_name: db1, cluster_id: 1, cluster_type: 1, env_id: 1", "data": {"dbs": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/db [post]
 func AddDB(c *gin.Context) {
-	var fields map[string]interface{}
-
-	// get data
-	data, err := c.GetRawData()
+	// bind request
+	fields, err := request.BindFields(c, &addDBRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.DBInfo{}, constant.DefaultMiddlewareTag)
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, dbNameExists := fields[dbDBNameStruct]
-	_, clusterIDExists := fields[dbClusterIDStruct]
-	_, clusterTypeExists := fields[dbClusterTypeStruct]
-	_, envIDExists := fields[dbEnvIDStruct]
-	if !dbNameExists || !clusterIDExists || !clusterTypeExists || !envIDExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, fmt.Sprintf("%s and %s and %s and %s",
-			dbDBNameStruct, dbClusterIDStruct, dbClusterTypeStruct, dbEnvIDStruct))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewDBServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// insert into middleware
-	err = s.Create(fields)
+	err = s.Create(c.Request.Context(), fields)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataAddDB,
 			fields[dbDBNameStruct], fields[dbClusterIDStruct], fields[dbClusterTypeStruct], fields[dbEnvIDStruct], err.Error())
@@ -262,11 +285,10 @@ func AddDB(c *gin.Context) {
 // @Tags database
 // @Summary update database by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200207, "message": "DAS-200207: metadata: update database completed. id: 1", "data": {"dbs": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/db/update/:id [post]
 func UpdateDBByID(c *gin.Context) {
-	var fields map[string]interface{}
-
 	// get params
 	idStr := c.Param(dbIDJSON)
 	if idStr == constant.EmptyString {
@@ -278,35 +300,20 @@ func UpdateDBByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	data, err := c.GetRawData()
+	// bind request
+	fields, err := request.BindFields(c, &updateDBRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.DBInfo{}, constant.DefaultMiddlewareTag)
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, dbNameExists := fields[dbDBNameStruct]
-	_, clusterIDExists := fields[dbClusterIDStruct]
-	_, clusterTypeExists := fields[dbClusterTypeStruct]
-	_, ownerIDExists := fields[dbOwnerIDStruct]
-	_, envIDExists := fields[dbEnvIDStruct]
-	_, delFlagExists := fields[delFlagStruct]
-	if !dbNameExists && !clusterIDExists && !clusterTypeExists && !ownerIDExists && !envIDExists && !delFlagExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists,
-			fmt.Sprintf("%s, %s, %s, %s, %s, %s",
-				dbDBNameStruct, dbClusterIDStruct, dbClusterTypeStruct, dbOwnerIDStruct, dbEnvIDStruct, delFlagStruct))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewDBServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
 	// update entity
-	err = s.Update(id, fields)
+	err = s.Update(c.Request.Context(), id, fields)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataUpdateDB, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUpdateDB, id, err.Error())
 		return
 	}
 	// marshal service
@@ -324,7 +331,9 @@ func UpdateDBByID(c *gin.Context) {
 // @Tags database
 // @Summary delete database by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Param cascade query bool false "delete the metadata which reference the database as well, default is false"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200208, "message": "DAS-200208: metadata: delete database completed. id: 1", "data": {"dbs": []}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/db/delete/:id [post]
 func DeleteDBByID(c *gin.Context) {
 	// get params
@@ -338,12 +347,23 @@ func DeleteDBByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
+	cascade, err := shared.GetCascade(c)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewDBServiceWithDefault()
-	// update entity
-	err = s.Delete(id)
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// delete entity
+	if cascade {
+		err = s.DeleteCascade(c.Request.Context(), id)
+	} else {
+		err = s.Delete(c.Request.Context(), id)
+	}
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataDeleteDB, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataDeleteDB, id, err.Error())
 		return
 	}
 	// marshal service
@@ -359,11 +379,12 @@ func DeleteDBByID(c *gin.Context) {
 }
 
 // @Tags database
-// @Summary add application map
+// @Summary undelete database by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [1, 2]}"
-// @Router /api/v1/metadata/db/add-app/:id [post]
-func DBAddApp(c *gin.Context) {
+// @Param id path int true "database id"
+// @Success 200 {string} string "{"code": 200211, "message": "DAS-200211: metadata: undelete database completed. id: 1", "data": {"dbs": [{"id": 1, "db_name": "db1", "cluster_id": 1, "cluster_type": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/db/undelete/:id [post]
+func UndeleteDBByID(c *gin.Context) {
 	// get params
 	idStr := c.Param(dbIDJSON)
 	if idStr == constant.EmptyString {
@@ -375,26 +396,57 @@ func DBAddApp(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	data, err := c.GetRawData()
+	// init service
+	s := metadata.NewDBServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	// undelete entity
+	err = s.Undelete(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUndeleteDB, id, err.Error())
 		return
 	}
-	dataMap := make(map[string]int)
-	err = json.Unmarshal(data, &dataMap)
+	// marshal service
+	jsonBytes, err := s.Marshal()
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataDBAddApp, id, err.Error())
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
 	}
-	appID, appIDExists := dataMap[dbAppIDJSON]
-	if !appIDExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, dbAppIDJSON)
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataUndeleteDB, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataUndeleteDB, id)
+}
+
+// @Tags database
+// @Summary add application map
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200209, "message": "DAS-200209: metadata: add map of database and app completed. db_id: 1, app_id: 1", "data": {"app_id_list": [1, 2]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/db/add-app/:id [post]
+func DBAddApp(c *gin.Context) {
+	// get params
+	idStr := c.Param(dbIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, dbIDJSON)
 		return
 	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// bind request
+	req := &dbAppRequest{}
+	err = request.Bind(c, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	appID := req.AppID
 	// init service
 	s := metadata.NewDBServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// update entities
-	err = s.AddApp(id, appID)
+	err = s.AddApp(c.Request.Context(), id, appID)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataDBAddApp, id, err.Error())
 		return
@@ -414,7 +466,7 @@ func DBAddApp(c *gin.Context) {
 // @Tags database
 // @Summary delete application map
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [1]}"
+// @Success 200 {string} string "{"code": 200210, "message": "DAS-200210: metadata: delete map of database and app completed. db_id: 1, app_id: 1", "data": {"app_id_list": [1]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/db/delete-app/:id [post]
 func DBDeleteApp(c *gin.Context) {
 	// get params
@@ -428,26 +480,19 @@ func DBDeleteApp(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	dataMap := make(map[string]int)
-	err = json.Unmarshal(data, &dataMap)
+	// bind request
+	req := &dbAppRequest{}
+	err = request.Bind(c, req)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataDBDeleteApp, id, err.Error())
-		return
-	}
-	appID, appIDExists := dataMap[dbAppIDJSON]
-	if !appIDExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, dbAppIDJSON)
+		request.ResponseNOK(c, err)
 		return
 	}
+	appID := req.AppID
 	// init service
 	s := metadata.NewDBServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// update entities
-	err = s.DeleteApp(id, appID)
+	err = s.DeleteApp(c.Request.Context(), id, appID)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataDBDeleteApp, id, err.Error())
 		return
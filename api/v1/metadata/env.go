@@ -1,17 +1,18 @@
 package metadata
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/log"
 
+	"github.com/romberli/das/api/shared"
 	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 	msgmeta "github.com/romberli/das/pkg/message/metadata"
+	"github.com/romberli/das/pkg/request"
 	"github.com/romberli/das/pkg/resp"
 )
 
@@ -21,25 +22,48 @@ const (
 
 	delFlagStruct = "DelFlag"
 	envNameStruct = "EnvName"
+	envEnvsStruct = "Envs"
 )
 
+// addEnvRequest is the request body of adding an environment
+type addEnvRequest struct {
+	EnvName *string `json:"env_name" validate:"required,min=1,max=100"`
+}
+
+// updateEnvRequest is the request body of updating an environment, only the specified fields are updated
+type updateEnvRequest struct {
+	EnvName *string `json:"env_name" validate:"omitempty,min=1,max=100"`
+	DelFlag *int    `json:"del_flag" validate:"omitempty,oneof=0 1"`
+}
+
 // @Tags	environment
-// @Summary	get all environments
+// @Summary	get environments which match the filters, sorted and paginated
 // @Accept	application/json
 // @Produce application/json
-// @Success	200 {string} string "{"code": 200, "data": [{"id": 1, "env_name": "online", "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Param field query string false "filter by any field, such as field=value, field=v1,v2, field_like=value, field_ge=value and field_le=value"
+// @Param sort query string false "sort by the fields, such as -id,create_time, \"-\" means descending"
+// @Param limit query int false "max number of the returned environments, 0 means no limit"
+// @Param offset query int false "number of the skipped environments"
+// @Param cursor query int false "id of the last environment of the previous page, could not be used with offset"
+// @Success 200 {string} string "{"code": 200301, "message": "DAS-200301: metadata: get environment all completed", "data": {"Envs": [{"id": 1, "env_name": "online", "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}], "page": {"total": 1, "limit": 0, "offset": 0, "next_cursor": 0}}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router	/api/v1/metadata/env [get]
 func GetEnv(c *gin.Context) {
+	// get params
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewEnvServiceWithDefault()
 	// get entities
-	err := s.GetAll()
+	err = s.GetByQuery(c.Request.Context(), query)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetEnvAll, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetEnvAll, err.Error())
 		return
 	}
 	// marshal service
-	jsonBytes, err := s.Marshal()
+	jsonBytes, err := s.MarshalWithFields(envEnvsStruct, pageStruct)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
@@ -55,8 +79,9 @@ func GetEnv(c *gin.Context) {
 // @Accept	application/json
 // @Produce application/json
 // @Param	id path int true "environment id"
-// @Success	200 {string} string "{"code": 200, "data": [{"id": 1, "env_name": "online", "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
-// @Router	/api/v1/metadata/env/:id [get]
+// @Header 200 {string} ETag "the version of the entity, specify it in the If-Match header when updating or deleting the entity"
+// @Success 200 {string} string "{"code": 200302, "message": "DAS-200302: metadata: get environment by id completed. id: 1", "data": {"Envs": [{"id": 1, "env_name": "online", "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router	/api/v1/metadata/env/get/:id [get]
 func GetEnvByID(c *gin.Context) {
 	// get param
 	idStr := c.Param(idJSON)
@@ -72,11 +97,12 @@ func GetEnvByID(c *gin.Context) {
 	// init service
 	s := metadata.NewEnvServiceWithDefault()
 	// get entity
-	err = s.GetByID(id)
+	err = s.GetByID(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetEnvByID, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetEnvByID, id, err.Error())
 		return
 	}
+	shared.SetETag(c, s.GetEnvs()[constant.ZeroInt].GetLastUpdateTime())
 	// marshal service
 	jsonBytes, err := s.Marshal()
 	if err != nil {
@@ -92,7 +118,7 @@ func GetEnvByID(c *gin.Context) {
 // @Tags environment
 // @Summary get environment by Name
 // @Produce  environment/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "env_name": "online", "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Success 200 {string} string "{"code": 200305, "message": "DAS-200305: metadata: get environment by name completed. id: 1", "data": {"Envs": [{"id": 1, "env_name": "online", "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/env/env-name/:env_name [get]
 func GetEnvByName(c *gin.Context) {
 	// get params
@@ -104,9 +130,9 @@ func GetEnvByName(c *gin.Context) {
 	// init service
 	s := metadata.NewEnvServiceWithDefault()
 	// get entity
-	err := s.GetEnvByName(envName)
+	err := s.GetEnvByName(c.Request.Context(), envName)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetEnvByName, envName, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetEnvByName, envName, err.Error())
 		return
 	}
 	// marshal service
@@ -126,32 +152,20 @@ func GetEnvByName(c *gin.Context) {
 // @Accept	application/json
 // @Produce application/json
 // @Param	env_name body string true "environment name"
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "env_name": "online", "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Success 200 {string} string "{"code": 200303, "message": "DAS-200303: metadata: add new environment completed. env_name: online", "data": {"Envs": [{"id": 1, "env_name": "online", "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router	/api/v1/metadata/env [post]
 func AddEnv(c *gin.Context) {
-	var fields map[string]interface{}
-
-	// get data
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.EnvInfo{}, constant.DefaultMiddlewareTag)
+	// bind request
+	fields, err := request.BindFields(c, &addEnvRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, ok := fields[envNameStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, envNameStruct)
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewEnvServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// insert into middleware
-	err = s.Create(fields)
+	err = s.Create(c.Request.Context(), fields)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataAddEnv, fields[envNameStruct], err.Error())
 		return
@@ -173,11 +187,10 @@ func AddEnv(c *gin.Context) {
 // @Accept	application/json
 // @Produce application/json
 // @Param	id path int true "environment id"
-// @Success	200 {string} string "{"code": 200, "data": [{"id": 1, "env_name": "online", "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 100304, "message": "DAS-100304: metadata: update environment message: 1", "data": {"Envs": [{"id": 1, "env_name": "online", "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router	/api/v1/metadata/env/update/:id [post]
 func UpdateEnvByID(c *gin.Context) {
-	var fields map[string]interface{}
-
 	// get params
 	idStr := c.Param(idJSON)
 	if idStr == constant.EmptyString {
@@ -188,29 +201,20 @@ func UpdateEnvByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.EnvInfo{}, constant.DefaultMiddlewareTag)
+	// bind request
+	fields, err := request.BindFields(c, &updateEnvRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, envNameExists := fields[envNameStruct]
-	_, delFlagExists := fields[delFlagStruct]
-	if !envNameExists && !delFlagExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, fmt.Sprintf("%s and %s", envNameStruct, delFlagStruct))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewEnvServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
 	// update entity
-	err = s.Update(id, fields)
+	err = s.Update(c.Request.Context(), id, fields)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataUpdateEnv, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUpdateEnv, id, err.Error())
 		return
 	}
 
@@ -229,7 +233,9 @@ func UpdateEnvByID(c *gin.Context) {
 // @Tags environment
 // @Summary delete environment by id
 // @Produce  environment/json
-// @Success 200 {string} string "{"code": 200, "data": []}"
+// @Param cascade query bool false "delete the metadata which reference the environment as well, default is false"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200306, "message": "DAS-200306: metadata: delete environment by ID completed. id: 1", "data": {"Envs": []}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/env/delete/:id [post]
 func DeleteEnvByID(c *gin.Context) {
 	var fields map[string]interface{}
@@ -245,12 +251,23 @@ func DeleteEnvByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
+	cascade, err := shared.GetCascade(c)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewEnvServiceWithDefault()
-	// update entities
-	err = s.Delete(id)
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// delete entity
+	if cascade {
+		err = s.DeleteCascade(c.Request.Context(), id)
+	} else {
+		err = s.Delete(c.Request.Context(), id)
+	}
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataDeleteEnvByID, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataDeleteEnvByID, id, err.Error())
 		return
 	}
 	// marshal service
@@ -264,3 +281,42 @@ func DeleteEnvByID(c *gin.Context) {
 	log.Debug(message.NewMessage(msgmeta.DebugMetadataDeleteEnvByID, jsonStr).Error())
 	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataDeleteEnvByID, fields[envNameStruct])
 }
+
+// @Tags environment
+// @Summary undelete environment by id
+// @Produce  application/json
+// @Param id path int true "environment id"
+// @Success 200 {string} string "{"code": 200307, "message": "DAS-200307: metadata: undelete environment completed. id: 1", "data": {"Envs": [{"id": 1, "env_name": "online", "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/env/undelete/:id [post]
+func UndeleteEnvByID(c *gin.Context) {
+	// get params
+	idStr := c.Param(idJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, idJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := metadata.NewEnvServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	// undelete entity
+	err = s.Undelete(c.Request.Context(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUndeleteEnvByID, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataUndeleteEnvByID, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataUndeleteEnvByID, id)
+}
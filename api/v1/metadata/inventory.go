@@ -0,0 +1,193 @@
+package metadata
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/api/shared"
+	"github.com/romberli/das/internal/app/inventory"
+	"github.com/romberli/das/pkg/message"
+	msgmeta "github.com/romberli/das/pkg/message/metadata"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	inventoryFormatJSON = "format"
+	inventoryKindJSON   = "kind"
+	inventoryDryRunJSON = "dry_run"
+	inventoryPruneJSON  = "prune"
+	inventorySinceJSON  = "since"
+
+	inventoryPlansStruct = "Plans"
+)
+
+// getInventoryFormat returns the value of the format query parameter, it is json if the parameter is not specified
+func getInventoryFormat(c *gin.Context) string {
+	return c.DefaultQuery(inventoryFormatJSON, inventory.FormatJSON)
+}
+
+// getInventoryBool returns the bool value of the query parameter, it returns defaultValue if the parameter is not specified
+func getInventoryBool(c *gin.Context, key string, defaultValue bool) (bool, error) {
+	str := c.Query(key)
+	if str == constant.EmptyString {
+		return defaultValue, nil
+	}
+
+	return strconv.ParseBool(str)
+}
+
+// @Tags inventory
+// @Summary import the inventory document which contains envs, users, monitor systems, clusters, servers, databases, apps and the maps of them, the entities reference each other by the natural keys
+// @Accept  application/json
+// @Produce  application/json
+// @Param format query string false "format of the document: json, yaml or csv, default is json"
+// @Param kind query string false "kind of the entities in the csv document, it is required when the format is csv: env, user, monitor_system, middleware_cluster, middleware_server, mysql_cluster, mysql_server, db, app or app_db"
+// @Param dry_run query bool false "only plan the changes without applying them, default is true"
+// @Param body body string true "{"envs": [{"env_name": "online"}], "mysql_clusters": [{"cluster_name": "cluster1", "env_name": "online", ...}], "mysql_servers": [{"cluster_name": "cluster1", "host_ip": "192.168.137.11", "port_num": 3306, ...}], ...}"
+// @Success 200 {string} string "{"code": 201201, "message": "DAS-201201: metadata: import inventory completed. format: json, kind: all, dry run: true", "data": {"plans": [{"dry_run": true, "applied": false, "summary": {"create": 2, "update": 0, "none": 0}, "changes": [{"kind": "env", "action": "create", "id": 0, "key": "online", "fields": {"env_name": "online"}, "diff": {}}, ...]}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/import [post]
+func Import(c *gin.Context) {
+	// get params
+	format := getInventoryFormat(c)
+	kind := c.Query(inventoryKindJSON)
+	dryRun, err := getInventoryBool(c, inventoryDryRunJSON, true)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// get data
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	// init service
+	s := inventory.NewServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	// import
+	err = s.Import(c.Request.Context(), data, format, kind, dryRun)
+	if err != nil {
+		responseInventoryNOK(c, err, msgmeta.ErrMetadataImport, format, kind, dryRun, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalWithFields(inventoryPlansStruct)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataImport, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataImport, format, kind, dryRun)
+}
+
+// @Tags inventory
+// @Summary reconcile the registered metadata to the desired state in the inventory document, the registered entities of the kinds in the document which are not in the document are deleted if prune is true
+// @Accept  application/json
+// @Produce  application/json
+// @Param format query string false "format of the document: json, yaml or csv, default is json"
+// @Param kind query string false "kind of the entities in the csv document, it is required when the format is csv: env, user, monitor_system, middleware_cluster, middleware_server, mysql_cluster, mysql_server, db, app or app_db"
+// @Param dry_run query bool false "only plan the changes without applying them, default is true"
+// @Param prune query bool false "delete the registered entities which are not in the document, the kinds which are missing or null in the document are never pruned, default is true"
+// @Param since query string false "the create time of the reviewed plan in RFC3339 format, the entities which were updated after it are conflicts"
+// @Param body body string true "{"envs": [{"env_name": "online"}], "mysql_servers": [{"cluster_name": "cluster1", "host_ip": "192.168.137.11", "port_num": 3306, ...}], ...}"
+// @Success 200 {string} string "{"code": 201203, "message": "DAS-201203: metadata: reconcile inventory completed. format: json, kind: all, prune: true, dry run: true", "data": {"plans": [{"dry_run": true, "applied": false, "prune": true, "create_time": "2021-06-01T12:00:00+08:00", "summary": {"create": 0, "update": 0, "delete": 1, "none": 1}, "changes": [{"kind": "mysql_server", "action": "delete", "id": 2, "key": "192.168.137.12:3306", "fields": {...}, "diff": {}, "last_update_time": "2021-05-01T12:00:00+08:00"}, ...]}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/reconcile [post]
+func Reconcile(c *gin.Context) {
+	// get params
+	format := getInventoryFormat(c)
+	kind := c.Query(inventoryKindJSON)
+	dryRun, err := getInventoryBool(c, inventoryDryRunJSON, true)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	prune, err := getInventoryBool(c, inventoryPruneJSON, true)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	var since time.Time
+	sinceStr := c.Query(inventorySinceJSON)
+	if sinceStr != constant.EmptyString {
+		since, err = time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+			return
+		}
+	}
+	// get data
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	// init service
+	s := inventory.NewServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	// reconcile
+	err = s.Reconcile(c.Request.Context(), data, format, kind, prune, since, dryRun)
+	if err != nil {
+		responseInventoryNOK(c, err, msgmeta.ErrMetadataReconcile, format, kind, prune, dryRun, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalWithFields(inventoryPlansStruct)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataReconcile, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataReconcile, format, kind, prune, dryRun)
+}
+
+// @Tags inventory
+// @Summary export the registered metadata as the inventory document, the entities reference each other by the natural keys
+// @Description the document is responded as it is without the response envelope, so that it could be imported again
+// @Produce  application/json,application/x-yaml,text/csv
+// @Param format query string false "format of the document: json, yaml or csv, default is json"
+// @Param kind query string false "only export the entities of the kind, it is required when the format is csv: env, user, monitor_system, middleware_cluster, middleware_server, mysql_cluster, mysql_server, db, app or app_db"
+// @Success 200 {string} string "{"envs": [{"env_name": "online"}], "users": [...], "monitor_systems": [...], "middleware_clusters": [...], "middleware_servers": [...], "mysql_clusters": [...], "mysql_servers": [...], "dbs": [...], "apps": [...], "app_dbs": [...]}"
+// @Router /api/v1/metadata/export [get]
+func Export(c *gin.Context) {
+	// get params
+	format := getInventoryFormat(c)
+	kind := c.Query(inventoryKindJSON)
+	// init service
+	s := inventory.NewServiceWithDefault()
+	// export
+	err := s.Export(c.Request.Context(), kind)
+	if err != nil {
+		resp.ResponseNOK(c, msgmeta.ErrMetadataExport, format, kind, err.Error())
+		return
+	}
+	// encode document
+	data, err := s.Encode(format, kind)
+	if err != nil {
+		resp.ResponseNOK(c, msgmeta.ErrMetadataExport, format, kind, err.Error())
+		return
+	}
+	// response
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataExport, string(data)).Error())
+	resp.ResponseRaw(c, inventory.GetContentType(format), data, msgmeta.InfoMetadataExport, format, kind)
+}
+
+// responseInventoryNOK responses with 409 if the entities were changed by others after planning,
+// otherwise, with the http status of the code
+func responseInventoryNOK(c *gin.Context, err error, code int, values ...interface{}) {
+	var ce *inventory.ConflictError
+	if errors.As(err, &ce) {
+		resp.ResponseNOK(c, message.ErrDataConflict, message.NewMessage(code, values...).Error())
+		return
+	}
+
+	shared.ResponseNOK(c, err, code, values...)
+}
@@ -0,0 +1,6 @@
+package metadata
+
+const (
+	// pageStruct is the pagination information of the metadata services
+	pageStruct = "Page"
+)
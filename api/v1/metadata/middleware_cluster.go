@@ -2,17 +2,18 @@ package metadata
 
 import (
 	"encoding/json"
-	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/log"
 
+	"github.com/romberli/das/api/shared"
 	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 	msgmeta "github.com/romberli/das/pkg/message/metadata"
+	"github.com/romberli/das/pkg/request"
 	"github.com/romberli/das/pkg/resp"
 )
 
@@ -24,24 +25,51 @@ const (
 	middlewareClusterNameStruct    = "ClusterName"
 	middlewareClusterOwnerIDStruct = "OwnerID"
 	middlewareClusterEnvIDStruct   = "EnvID"
+	middlewareClustersStruct       = "MiddlewareClusters"
 )
 
+// addMiddlewareClusterRequest is the request body of adding a middleware cluster
+type addMiddlewareClusterRequest struct {
+	ClusterName *string `json:"cluster_name" validate:"required,min=1,max=100"`
+	OwnerID     *int    `json:"owner_id" validate:"omitempty,gte=0"`
+	EnvID       *int    `json:"env_id" validate:"required,gt=0"`
+}
+
+// updateMiddlewareClusterRequest is the request body of updating a middleware cluster, only the specified fields are updated
+type updateMiddlewareClusterRequest struct {
+	ClusterName *string `json:"cluster_name" validate:"omitempty,min=1,max=100"`
+	OwnerID     *int    `json:"owner_id" validate:"omitempty,gte=0"`
+	EnvID       *int    `json:"env_id" validate:"omitempty,gt=0"`
+	DelFlag     *int    `json:"del_flag" validate:"omitempty,oneof=0 1"`
+}
+
 // @Tags middleware cluster
-// @Summary get all middleware clusters
+// @Summary get middleware clusters which match the filters, sorted and paginated
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id":13,"cluster_name":"test001","owner_id":1,"env_id":1,"del_flag":0,"create_time":"2021-04-09T10:55:43.920406+08:00","last_update_time":"2021-04-09T10:55:43.920406+08:00"}]}"
+// @Param field query string false "filter by any field, such as field=value, field=v1,v2, field_like=value, field_ge=value and field_le=value"
+// @Param sort query string false "sort by the fields, such as -id,create_time, \"-\" means descending"
+// @Param limit query int false "max number of the returned middleware clusters, 0 means no limit"
+// @Param offset query int false "number of the skipped middleware clusters"
+// @Param cursor query int false "id of the last middleware cluster of the previous page, could not be used with offset"
+// @Success 200 {string} string "{"code": 200401, "message": "DAS-200401: metadata: get middleware clusters all completed. id: 1", "data": {"middleware_clusters": [{"id": 1, "cluster_name": "middleware1", "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}], "page": {"total": 1, "limit": 0, "offset": 0, "next_cursor": 0}}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-cluster [get]
 func GetMiddlewareCluster(c *gin.Context) {
+	// get params
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewMiddlewareClusterServiceWithDefault()
 	// get entities
-	err := s.GetAll()
+	err = s.GetByQuery(c.Request.Context(), query)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMiddlewareClusterAll, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMiddlewareClusterAll, err.Error())
 		return
 	}
 	// marshal service
-	jsonBytes, err := s.Marshal()
+	jsonBytes, err := s.MarshalWithFields(middlewareClustersStruct, pageStruct)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
@@ -55,7 +83,7 @@ func GetMiddlewareCluster(c *gin.Context) {
 // @Tags middleware cluster
 // @Summary get middleware cluster by env
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"del_flag":0,"create_time":"2021-04-09T10:55:43.920406+08:00","last_update_time":"2021-04-09T10:55:43.920406+08:00","id":13,"cluster_name":"test001","owner_id":1,"env_id":1}]}"
+// @Success 200 {string} string "{"code": 200402, "message": "DAS-200402: metadata: get middleware clusters by environment completed. env_id: 1", "data": {"middleware_clusters": [{"id": 1, "cluster_name": "middleware1", "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-cluster/env/:env_id [get]
 func GetMiddlewareClusterByEnv(c *gin.Context) {
 	// get param
@@ -72,9 +100,9 @@ func GetMiddlewareClusterByEnv(c *gin.Context) {
 	// init service
 	s := metadata.NewMiddlewareClusterServiceWithDefault()
 	// get entity
-	err = s.GetByEnv(envID)
+	err = s.GetByEnv(c.Request.Context(), envID)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMiddlewareClusterByEnv, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMiddlewareClusterByEnv, err.Error())
 		return
 	}
 	// marshal service
@@ -92,7 +120,8 @@ func GetMiddlewareClusterByEnv(c *gin.Context) {
 // @Tags middleware cluster
 // @Summary get middleware cluster by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id":13,"cluster_name":"test001","owner_id":1,"env_id":1,"del_flag":0,"create_time":"2021-04-09T10:55:43.920406+08:00","last_update_time":"2021-04-09T10:55:43.920406+08:00"}]}"
+// @Header 200 {string} ETag "the version of the entity, specify it in the If-Match header when updating or deleting the entity"
+// @Success 200 {string} string "{"code": 200403, "message": "DAS-200403: metadata: get middleware cluster by id completed. id: 1", "data": {"middleware_clusters": [{"id": 1, "cluster_name": "middleware1", "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-cluster/get/:id [get]
 func GetMiddlewareClusterByID(c *gin.Context) {
 	// get param
@@ -109,11 +138,12 @@ func GetMiddlewareClusterByID(c *gin.Context) {
 	// init service
 	s := metadata.NewMiddlewareClusterServiceWithDefault()
 	// get entity
-	err = s.GetByID(id)
+	err = s.GetByID(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMiddlewareClusterByID, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMiddlewareClusterByID, id, err.Error())
 		return
 	}
+	shared.SetETag(c, s.GetMiddlewareClusters()[constant.ZeroInt].GetLastUpdateTime())
 	// marshal service
 	jsonBytes, err := s.Marshal()
 	if err != nil {
@@ -129,7 +159,7 @@ func GetMiddlewareClusterByID(c *gin.Context) {
 // @Tags middleware cluster
 // @Summary get middleware cluster by name
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id":13,"cluster_name":"test001","owner_id":1,"env_id":1,"del_flag":0,"create_time":"2021-04-09T10:55:43.920406+08:00","last_update_time":"2021-04-09T10:55:43.920406+08:00"}]}"
+// @Success 200 {string} string "{"code": 200404, "message": "DAS-200404: metadata: get middleware cluster by name completed. id: 1", "data": {"middleware_clusters": [{"id": 1, "cluster_name": "middleware1", "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-cluster/cluster-name/:cluster_name [get]
 func GetMiddlewareClusterByName(c *gin.Context) {
 	// get params
@@ -141,9 +171,9 @@ func GetMiddlewareClusterByName(c *gin.Context) {
 	// init service
 	s := metadata.NewMiddlewareClusterServiceWithDefault()
 	// get entity
-	err := s.GetByName(middlewareClusterName)
+	err := s.GetByName(c.Request.Context(), middlewareClusterName)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMiddlewareClusterByName, middlewareClusterName, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMiddlewareClusterByName, middlewareClusterName, err.Error())
 		return
 	}
 	// marshal service
@@ -161,8 +191,8 @@ func GetMiddlewareClusterByName(c *gin.Context) {
 // @Tags application
 // @Summary get middleware server id list by cluster id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [1,2]}"
-// @Router /api/vi/metadata/middleware-server/:id [get]
+// @Success 200 {string} string "{"code": 200405, "message": "DAS-200405: metadata: get middleware server id list completed. id: 1", "data": [1, 2], "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/middleware-cluster/middleware-server/:id [get]
 func GetMiddlewareServerIDList(c *gin.Context) {
 	// get params
 	idStr := c.Param(middlewareClusterIDJSON)
@@ -177,9 +207,9 @@ func GetMiddlewareServerIDList(c *gin.Context) {
 	// init service
 	s := metadata.NewMiddlewareClusterServiceWithDefault()
 	// get entity
-	_, err = s.GetMiddlewareServerIDList(id)
+	_, err = s.GetMiddlewareServerIDList(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMiddlewareServerIDList, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMiddlewareServerIDList, id, err.Error())
 		return
 	}
 	// marshal service
@@ -198,37 +228,20 @@ func GetMiddlewareServerIDList(c *gin.Context) {
 // @Tags middleware cluster
 // @Summary add a new middleware cluster
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"del_flag":0,"create_time":"2021-04-09T16:02:25.541701+08:00","last_update_time":"2021-04-09T16:02:25.541701+08:00","id":14,"cluster_name":"rest_test","owner_id":1,"env_id":1}]}"
+// @Success 200 {string} string "{"code": 200406, "message": "DAS-200406: metadata: add new middleware cluster completed. cluster_name: middleware1", "data": {"middleware_clusters": [{"id": 1, "cluster_name": "middleware1", "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-cluster [post]
 func AddMiddlewareCluster(c *gin.Context) {
-	var fields map[string]interface{}
-
-	// get data
-	data, err := c.GetRawData()
+	// bind request
+	fields, err := request.BindFields(c, &addMiddlewareClusterRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.MiddlewareClusterInfo{}, constant.DefaultMiddlewareTag)
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, ok := fields[middlewareClusterNameStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, middlewareClusterNameStruct)
-		return
-	}
-	_, ok = fields[middlewareClusterEnvIDStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, middlewareClusterEnvIDStruct)
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewMiddlewareClusterServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// insert into middleware
-	err = s.Create(fields)
+	err = s.Create(c.Request.Context(), fields)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataAddMiddlewareCluster, fields[middlewareClusterNameStruct], err.Error())
 		return
@@ -248,11 +261,10 @@ func AddMiddlewareCluster(c *gin.Context) {
 // @Tags middleware cluster
 // @Summary update middleware cluster by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id":13,"cluster_name":"new_test","owner_id":1,"env_id":1,"del_flag":1,"create_time":"2021-04-09T10:55:43.920406+08:00","last_update_time":"2021-04-09T10:55:43.920406+08:00"}]}"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200407, "message": "DAS-200407: metadata: update middleware cluster completed. id: 1", "data": {"middleware_clusters": [{"id": 1, "cluster_name": "middleware1", "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-cluster/update/:id [post]
 func UpdateMiddlewareClusterByID(c *gin.Context) {
-	var fields map[string]interface{}
-
 	// get params
 	idStr := c.Param(middlewareClusterIDJSON)
 	if idStr == constant.EmptyString {
@@ -264,31 +276,20 @@ func UpdateMiddlewareClusterByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.MiddlewareClusterInfo{}, constant.DefaultMiddlewareTag)
+	// bind request
+	fields, err := request.BindFields(c, &updateMiddlewareClusterRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, middlewareClusterNameExists := fields[middlewareClusterNameStruct]
-	_, middlewareClusterOwnerIDExists := fields[middlewareClusterOwnerIDStruct]
-	_, middlewareClusterEnvIDExists := fields[middlewareClusterEnvIDStruct]
-	_, delFlagExists := fields[delFlagStruct]
-	if !middlewareClusterNameExists && !middlewareClusterEnvIDExists && !middlewareClusterOwnerIDExists && !delFlagExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, fmt.Sprintf("%s %s %s and %s", middlewareClusterNameStruct, middlewareClusterOwnerIDStruct, middlewareClusterEnvIDStruct, delFlagStruct))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewMiddlewareClusterServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
 	// update entity
-	err = s.Update(id, fields)
+	err = s.Update(c.Request.Context(), id, fields)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataUpdateMiddlewareCluster, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUpdateMiddlewareCluster, err.Error())
 		return
 	}
 	// marshal service
@@ -306,8 +307,10 @@ func UpdateMiddlewareClusterByID(c *gin.Context) {
 // @Tags application
 // @Summary delete middleware cluster by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": []}"
-// @Router /api/v1/metadata/app/delete/:id [post]
+// @Param cascade query bool false "delete the metadata which reference the middleware cluster as well, default is false"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200408, "message": "DAS-200408: metadata: delete middleware cluster completed. cluster_name: middleware1", "data": {"middleware_clusters": []}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/middleware-cluster/delete/:id [post]
 func DeleteMiddlewareClusterByID(c *gin.Context) {
 	var fields map[string]interface{}
 
@@ -322,12 +325,23 @@ func DeleteMiddlewareClusterByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
+	cascade, err := shared.GetCascade(c)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewMiddlewareClusterServiceWithDefault()
-	// update entities
-	err = s.Delete(id)
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// delete entity
+	if cascade {
+		err = s.DeleteCascade(c.Request.Context(), id)
+	} else {
+		err = s.Delete(c.Request.Context(), id)
+	}
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataDeleteMiddlewareCluster, fields[middlewareClusterNameStruct], err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataDeleteMiddlewareCluster, fields[middlewareClusterNameStruct], err.Error())
 		return
 	}
 	// marshal service
@@ -341,3 +355,42 @@ func DeleteMiddlewareClusterByID(c *gin.Context) {
 	log.Debug(message.NewMessage(msgmeta.DebugMetadataDeleteMiddlewareCluster, jsonStr).Error())
 	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataDeleteMiddlewareCluster, fields[middlewareClusterNameStruct])
 }
+
+// @Tags middleware cluster
+// @Summary undelete middleware cluster by id
+// @Produce  application/json
+// @Param id path int true "middleware cluster id"
+// @Success 200 {string} string "{"code": 200409, "message": "DAS-200409: metadata: undelete middleware cluster completed. id: 1", "data": {"middleware_clusters": [{"id": 1, "cluster_name": "middleware1", "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/middleware-cluster/undelete/:id [post]
+func UndeleteMiddlewareClusterByID(c *gin.Context) {
+	// get params
+	idStr := c.Param(middlewareClusterIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, middlewareClusterIDJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := metadata.NewMiddlewareClusterServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	// undelete entity
+	err = s.Undelete(c.Request.Context(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUndeleteMiddlewareCluster, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataUndeleteMiddlewareCluster, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataUndeleteMiddlewareCluster, id)
+}
@@ -1,17 +1,18 @@
 package metadata
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/log"
 
+	"github.com/romberli/das/api/shared"
 	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 	msgmeta "github.com/romberli/das/pkg/message/metadata"
+	"github.com/romberli/das/pkg/request"
 	"github.com/romberli/das/pkg/resp"
 )
 
@@ -26,24 +27,55 @@ const (
 	middlewareServerMiddlewareRoleStruct = "MiddlewareRole"
 	middlewareServerHostIPStruct         = "HostIP"
 	middlewareServerPortNumStruct        = "PortNum"
+	middlewareServersStruct              = "MiddlewareServers"
 )
 
+// addMiddlewareServerRequest is the request body of adding a middleware server, the middleware role is one of 1(rw), 2(ro) and 3(das)
+type addMiddlewareServerRequest struct {
+	ClusterID      *int    `json:"cluster_id" validate:"required,gt=0"`
+	ServerName     *string `json:"server_name" validate:"required,min=1,max=100"`
+	MiddlewareRole *int    `json:"middleware_role" validate:"required,oneof=1 2 3"`
+	HostIP         *string `json:"host_ip" validate:"required,ip"`
+	PortNum        *int    `json:"port_num" validate:"required,min=1,max=65535"`
+}
+
+// updateMiddlewareServerRequest is the request body of updating a middleware server, only the specified fields are updated
+type updateMiddlewareServerRequest struct {
+	ClusterID      *int    `json:"cluster_id" validate:"omitempty,gt=0"`
+	ServerName     *string `json:"server_name" validate:"omitempty,min=1,max=100"`
+	MiddlewareRole *int    `json:"middleware_role" validate:"omitempty,oneof=1 2 3"`
+	HostIP         *string `json:"host_ip" validate:"omitempty,ip"`
+	PortNum        *int    `json:"port_num" validate:"omitempty,min=1,max=65535"`
+	DelFlag        *int    `json:"del_flag" validate:"omitempty,oneof=0 1"`
+}
+
 // @Tags middleware server
-// @Summary get all middleware servers
+// @Summary get middleware servers which match the filters, sorted and paginated
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"port_num":1,"last_update_time":"2021-04-11T23:16:10.281222+08:00","server_name":"test001","middleware_role":1,"host_ip":"3","del_flag":0,"create_time":"2021-04-07T17:51:00.270268+08:00","id":1,"cluster_id":13},{"last_update_time":"2021-04-09T16:20:03.063295+08:00","id":2,"cluster_id":13,"server_name":"test002","del_flag":0,"create_time":"2021-04-09T16:20:03.063295+08:00","middleware_role":2,"host_ip":"2","port_num":2}]}"
+// @Param field query string false "filter by any field, such as field=value, field=v1,v2, field_like=value, field_ge=value and field_le=value"
+// @Param sort query string false "sort by the fields, such as -id,create_time, \"-\" means descending"
+// @Param limit query int false "max number of the returned middleware servers, 0 means no limit"
+// @Param offset query int false "number of the skipped middleware servers"
+// @Param cursor query int false "id of the last middleware server of the previous page, could not be used with offset"
+// @Success 200 {string} string "{"code": 200501, "message": "DAS-200501: metadata: get middleware server all completed. id: 1", "data": {"middleware_servers": [{"id": 1, "cluster_id": 1, "server_name": "middleware_server1", "middleware_role": 1, "host_ip": "192.168.137.11", "port_num": 33061, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}], "page": {"total": 1, "limit": 0, "offset": 0, "next_cursor": 0}}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-server [get]
 func GetMiddlewareServer(c *gin.Context) {
+	// get params
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewMiddlewareServerServiceWithDefault()
 	// get entities
-	err := s.GetAll()
+	err = s.GetByQuery(c.Request.Context(), query)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMiddlewareServerAll, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMiddlewareServerAll, err.Error())
 		return
 	}
 	// marshal service
-	jsonBytes, err := s.Marshal()
+	jsonBytes, err := s.MarshalWithFields(middlewareServersStruct, pageStruct)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
@@ -57,7 +89,7 @@ func GetMiddlewareServer(c *gin.Context) {
 // @Tags middleware server
 // @Summary get middleware servers by cluster id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"server_name":"test001","middleware_role":1,"host_ip":"3","del_flag":0,"create_time":"2021-04-07T17:51:00.270268+08:00","last_update_time":"2021-04-11T23:16:10.281222+08:00","id":1,"cluster_id":13,"port_num":1},{"host_ip":"2","port_num":2,"del_flag":0,"last_update_time":"2021-04-09T16:20:03.063295+08:00","id":2,"cluster_id":13,"server_name":"test002","middleware_role":2,"create_time":"2021-04-09T16:20:03.063295+08:00"}]}"
+// @Success 200 {string} string "{"code": 200502, "message": "DAS-200502: metadata: get middleware clusters by cluster completed. cluster_id: 1", "data": {"middleware_servers": [{"id": 1, "cluster_id": 1, "server_name": "middleware_server1", "middleware_role": 1, "host_ip": "192.168.137.11", "port_num": 33061, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-server/cluster-id/:cluster_id [get]
 func GetMiddlewareServerByClusterID(c *gin.Context) {
 	// get param
@@ -74,9 +106,9 @@ func GetMiddlewareServerByClusterID(c *gin.Context) {
 	// init service
 	s := metadata.NewMiddlewareServerServiceWithDefault()
 	// get entity
-	err = s.GetByClusterID(clusterID)
+	err = s.GetByClusterID(c.Request.Context(), clusterID)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMiddlewareSeverByClusterID, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMiddlewareSeverByClusterID, err.Error())
 		return
 	}
 	// marshal service
@@ -94,7 +126,8 @@ func GetMiddlewareServerByClusterID(c *gin.Context) {
 // @Tags middleware server
 // @Summary get middleware server by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id":1,"middleware_role":1,"del_flag":0,"last_update_time":"2021-04-11T23:16:10.281222+08:00","cluster_id":13,"server_name":"test001","host_ip":"3","port_num":1,"create_time":"2021-04-07T17:51:00.270268+08:00"}]}"
+// @Header 200 {string} ETag "the version of the entity, specify it in the If-Match header when updating or deleting the entity"
+// @Success 200 {string} string "{"code": 200503, "message": "DAS-200503: metadata: get middleware server by id completed. id: 1", "data": {"middleware_servers": [{"id": 1, "cluster_id": 1, "server_name": "middleware_server1", "middleware_role": 1, "host_ip": "192.168.137.11", "port_num": 33061, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-server/get/:id [get]
 func GetMiddlewareServerByID(c *gin.Context) {
 	// get param
@@ -111,11 +144,12 @@ func GetMiddlewareServerByID(c *gin.Context) {
 	// init service
 	s := metadata.NewMiddlewareServerServiceWithDefault()
 	// get entity
-	err = s.GetByID(id)
+	err = s.GetByID(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMiddlewareServerByID, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMiddlewareServerByID, id, err.Error())
 		return
 	}
+	shared.SetETag(c, s.GetMiddlewareServers()[constant.ZeroInt].GetLastUpdateTime())
 	// marshal service
 	jsonBytes, err := s.Marshal()
 	if err != nil {
@@ -131,7 +165,7 @@ func GetMiddlewareServerByID(c *gin.Context) {
 // @Tags middleware server
 // @Summary get middleware server by host info
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"cluster_id":13,"server_name":"test001","port_num":1,"last_update_time":"2021-04-11T23:16:10.281222+08:00","id":1,"middleware_role":1,"host_ip":"3","del_flag":0,"create_time":"2021-04-07T17:51:00.270268+08:00"}]}"
+// @Success 200 {string} string "{"code": 200504, "message": "DAS-200504: metadata: get middleware cluster by host info completed. host-ip: 192.168.137.11", "data": {"middleware_servers": [{"id": 1, "cluster_id": 1, "server_name": "middleware_server1", "middleware_role": 1, "host_ip": "192.168.137.11", "port_num": 33061, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-server/host-info [get]
 func GetMiddlewareServerByHostInfo(c *gin.Context) {
 	// get params
@@ -154,9 +188,10 @@ func GetMiddlewareServerByHostInfo(c *gin.Context) {
 	// init service
 	s := metadata.NewMiddlewareServerServiceWithDefault()
 	// get entity
-	err = s.GetByHostInfo(middleServerHostIP, middleServerPortNum)
+	err = s.GetByHostInfo(c.Request.Context(), middleServerHostIP, middleServerPortNum)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMiddlewareServerByHostInfo, hostIP)
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMiddlewareServerByHostInfo, hostIP, err.Error())
+		return
 	}
 	// marshal service
 	jsonBytes, err := s.Marshal()
@@ -173,52 +208,20 @@ func GetMiddlewareServerByHostInfo(c *gin.Context) {
 // @Tags middleware server
 // @Summary add a new middleware server
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id":31,"middleware_role":1,"port_num":12,"create_time":"2021-04-12T10:59:11.559227+08:00","last_update_time":"2021-04-12T10:59:11.559227+08:00","cluster_id":13,"server_name":"test003","host_ip":"123.123.123.1","del_flag":0}]}"
+// @Success 200 {string} string "{"code": 200505, "message": "DAS-200505: metadata: add new middleware server completed. server_name: middleware_server1", "data": {"middleware_servers": [{"id": 1, "cluster_id": 1, "server_name": "middleware_server1", "middleware_role": 1, "host_ip": "192.168.137.11", "port_num": 33061, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-server [post]
 func AddMiddlewareServer(c *gin.Context) {
-	var fields map[string]interface{}
-
-	// get data
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.MiddlewareServerInfo{}, constant.DefaultMiddlewareTag)
+	// bind request
+	fields, err := request.BindFields(c, &addMiddlewareServerRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, ok := fields[middlewareServerClusterIDStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, middlewareServerClusterIDStruct)
-		return
-	}
-	_, ok = fields[middlewareServerNameStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, middlewareServerNameStruct)
-		return
-	}
-	_, ok = fields[middlewareServerMiddlewareRoleStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, middlewareServerMiddlewareRoleStruct)
-		return
-	}
-	_, ok = fields[middlewareServerHostIPStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, middlewareServerHostIPStruct)
-		return
-	}
-	_, ok = fields[middlewareServerPortNumStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, middlewareServerPortNumStruct)
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewMiddlewareServerServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// insert into middleware
-	err = s.Create(fields)
+	err = s.Create(c.Request.Context(), fields)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataAddMiddlewareServer, fields[middlewareServerNameStruct], err.Error())
 		return
@@ -238,11 +241,10 @@ func AddMiddlewareServer(c *gin.Context) {
 // @Tags middleware server
 // @Summary update middleware server by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"server_name":"newTest","host_ip":"123.123.123.1","last_update_time":"2021-04-12T10:59:11.559227+08:00","id":31,"cluster_id":13,"port_num":12,"del_flag":1,"create_time":"2021-04-12T10:59:11.559227+08:00","middleware_role":1}]}"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200506, "message": "DAS-200506: metadata: update middleware server completed. id: 1", "data": {"middleware_servers": [{"id": 1, "cluster_id": 1, "server_name": "middleware_server1", "middleware_role": 1, "host_ip": "192.168.137.11", "port_num": 33061, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-server/update/:id [post]
 func UpdateMiddlewareServerByID(c *gin.Context) {
-	var fields map[string]interface{}
-
 	// get params
 	idStr := c.Param(middlewareServerIDJSON)
 	if idStr == constant.EmptyString {
@@ -253,33 +255,20 @@ func UpdateMiddlewareServerByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.MiddlewareServerInfo{}, constant.DefaultMiddlewareTag)
+	// bind request
+	fields, err := request.BindFields(c, &updateMiddlewareServerRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, middlewareServerClusterIDExists := fields[middlewareServerClusterIDStruct]
-	_, middlewareServerNameExists := fields[middlewareServerNameStruct]
-	_, middlewareServerMiddlewareRoleExists := fields[middlewareServerMiddlewareRoleStruct]
-	_, middlewareServerHostIPExists := fields[middlewareServerHostIPStruct]
-	_, middlewareServerPortNumExists := fields[middlewareServerPortNumStruct]
-	_, delFlagExists := fields[delFlagStruct]
-	if !middlewareServerClusterIDExists && !middlewareServerNameExists && !middlewareServerMiddlewareRoleExists && !middlewareServerHostIPExists && !middlewareServerPortNumExists && !delFlagExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, fmt.Sprintf("%s and %s", middlewareServerNameStruct, delFlagStruct))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewMiddlewareServerServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
 	// update entity
-	err = s.Update(id, fields)
+	err = s.Update(c.Request.Context(), id, fields)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataUpdateMiddlewareServer, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUpdateMiddlewareServer, err.Error())
 		return
 	}
 	// marshal service
@@ -297,7 +286,9 @@ func UpdateMiddlewareServerByID(c *gin.Context) {
 // @Tags middleware server
 // @Summary delete middleware server by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": []}"
+// @Param cascade query bool false "delete the metadata which reference the middleware server as well, default is false"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200507, "message": "DAS-200507: metadata: delete middleware server completed. server_name: middleware1", "data": {"middleware_servers": []}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/middleware-server/delete/:id [post]
 func DeleteMiddlewareServerByID(c *gin.Context) {
 	var fields map[string]interface{}
@@ -313,12 +304,23 @@ func DeleteMiddlewareServerByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
+	cascade, err := shared.GetCascade(c)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewMiddlewareServerServiceWithDefault()
-	// update entities
-	err = s.Delete(id)
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// delete entity
+	if cascade {
+		err = s.DeleteCascade(c.Request.Context(), id)
+	} else {
+		err = s.Delete(c.Request.Context(), id)
+	}
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataDeleteMiddlewareServer, fields[middlewareClusterNameStruct], err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataDeleteMiddlewareServer, fields[middlewareClusterNameStruct], err.Error())
 		return
 	}
 	// marshal service
@@ -332,3 +334,42 @@ func DeleteMiddlewareServerByID(c *gin.Context) {
 	log.Debug(message.NewMessage(msgmeta.DebugMetadataDeleteMiddlewareServer, jsonStr).Error())
 	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataDeleteMiddlewareServer, fields[middlewareClusterNameStruct])
 }
+
+// @Tags middleware server
+// @Summary undelete middleware server by id
+// @Produce  application/json
+// @Param id path int true "middleware server id"
+// @Success 200 {string} string "{"code": 200508, "message": "DAS-200508: metadata: undelete middleware server completed. id: 1", "data": {"middleware_servers": [{"id": 1, "cluster_id": 1, "server_name": "middleware_server1", "middleware_role": 1, "host_ip": "192.168.137.11", "port_num": 33061, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/middleware-server/undelete/:id [post]
+func UndeleteMiddlewareServerByID(c *gin.Context) {
+	// get params
+	idStr := c.Param(middlewareServerIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, middlewareServerIDJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := metadata.NewMiddlewareServerServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	// undelete entity
+	err = s.Undelete(c.Request.Context(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUndeleteMiddlewareServer, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataUndeleteMiddlewareServer, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataUndeleteMiddlewareServer, id)
+}
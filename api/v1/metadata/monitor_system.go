@@ -5,14 +5,16 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/log"
 
+	"github.com/romberli/das/api/shared"
 	"github.com/romberli/das/internal/app/metadata"
 	msgmeta "github.com/romberli/das/pkg/message/metadata"
 
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/das/pkg/request"
 	"github.com/romberli/das/pkg/resp"
 )
 
@@ -29,24 +31,59 @@ const (
 	monitorSystemPortNumSlowStruct = "MonitorSystemPortNumSlow"
 	monitorSystemBaseUrlStruct     = "BaseURL"
 	monitorSystemEnvIDStruct       = "EnvID"
+	monitorSystemsStruct           = "MonitorSystems"
 )
 
+// addMonitorSystemRequest is the request body of adding a monitor system, the system type is one of 1(pmm1.x) and 2(pmm2.x)
+type addMonitorSystemRequest struct {
+	MonitorSystemName        *string `json:"system_name" validate:"required,min=1,max=100"`
+	MonitorSystemType        *int    `json:"system_type" validate:"required,oneof=1 2"`
+	MonitorSystemHostIP      *string `json:"host_ip" validate:"required,ip"`
+	MonitorSystemPortNum     *int    `json:"port_num" validate:"required,min=1,max=65535"`
+	MonitorSystemPortNumSlow *int    `json:"port_num_slow" validate:"required,min=1,max=65535"`
+	BaseURL                  *string `json:"base_url" validate:"required,min=1,max=200"`
+	EnvID                    *int    `json:"env_id" validate:"required,gt=0"`
+}
+
+// updateMonitorSystemRequest is the request body of updating a monitor system, only the specified fields are updated
+type updateMonitorSystemRequest struct {
+	MonitorSystemName        *string `json:"system_name" validate:"omitempty,min=1,max=100"`
+	MonitorSystemType        *int    `json:"system_type" validate:"omitempty,oneof=1 2"`
+	MonitorSystemHostIP      *string `json:"host_ip" validate:"omitempty,ip"`
+	MonitorSystemPortNum     *int    `json:"port_num" validate:"omitempty,min=1,max=65535"`
+	MonitorSystemPortNumSlow *int    `json:"port_num_slow" validate:"omitempty,min=1,max=65535"`
+	BaseURL                  *string `json:"base_url" validate:"omitempty,min=1,max=200"`
+	EnvID                    *int    `json:"env_id" validate:"omitempty,gt=0"`
+	DelFlag                  *int    `json:"del_flag" validate:"omitempty,oneof=0 1"`
+}
+
 // @Tags monitor system
-// @Summary get all monitor systems
+// @Summary get monitor systems which match the filters, sorted and paginated
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "127.0.0.1", "port_num": 3306, "port_num_slow": 3307, "base_url": "http://127.0.0.1/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Param field query string false "filter by any field, such as field=value, field=v1,v2, field_like=value, field_ge=value and field_le=value"
+// @Param sort query string false "sort by the fields, such as -id,create_time, \"-\" means descending"
+// @Param limit query int false "max number of the returned monitor systems, 0 means no limit"
+// @Param offset query int false "number of the skipped monitor systems"
+// @Param cursor query int false "id of the last monitor system of the previous page, could not be used with offset"
+// @Success 200 {string} string "{"code": 200601, "message": "DAS-200601: metadata: get all monitor systems completed", "data": {"monitorSystems": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "192.168.137.11", "port_num": 80, "port_num_slow": 3306, "base_url": "/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}], "page": {"total": 1, "limit": 0, "offset": 0, "next_cursor": 0}}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/monitor-system [get]
 func GetMonitorSystem(c *gin.Context) {
+	// get params
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewMonitorSystemServiceWithDefault()
 	// get entities
-	err := s.GetAll()
+	err = s.GetByQuery(c.Request.Context(), query)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMonitorSystemAll, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMonitorSystemAll, err.Error())
 		return
 	}
 	// marshal service
-	jsonBytes, err := s.Marshal()
+	jsonBytes, err := s.MarshalWithFields(monitorSystemsStruct, pageStruct)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
@@ -60,7 +97,7 @@ func GetMonitorSystem(c *gin.Context) {
 // @Tags monitor system
 // @Summary get monitor system by env_id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "127.0.0.1", "port_num": 3306, "port_num_slow": 3307, "base_url": "http://127.0.0.1/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Success 200 {string} string "{"code": 200602, "message": "DAS-200602: metadata: get monitor systems by environment completed. env_id: 1", "data": {"monitorSystems": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "192.168.137.11", "port_num": 80, "port_num_slow": 3306, "base_url": "/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/monitor-system/env/:env_id [get]
 func GetMonitorSystemByEnv(c *gin.Context) {
 	// get param
@@ -78,9 +115,9 @@ func GetMonitorSystemByEnv(c *gin.Context) {
 	// init service
 	s := metadata.NewMonitorSystemServiceWithDefault()
 	// get entity
-	err = s.GetByEnv(envID)
+	err = s.GetByEnv(c.Request.Context(), envID)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMonitorSystemByEnv, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMonitorSystemByEnv, err.Error())
 		return
 	}
 	// marshal service
@@ -99,7 +136,8 @@ func GetMonitorSystemByEnv(c *gin.Context) {
 // @Tags monitor system
 // @Summary get monitor system by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "127.0.0.1", "port_num": 3306, "port_num_slow": 3307, "base_url": "http://127.0.0.1/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Header 200 {string} ETag "the version of the entity, specify it in the If-Match header when updating or deleting the entity"
+// @Success 200 {string} string "{"code": 200603, "message": "DAS-200603: metadata: get monitor system by id completed. id: 1", "data": {"monitorSystems": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "192.168.137.11", "port_num": 80, "port_num_slow": 3306, "base_url": "/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/monitor-system/get/:id [get]
 func GetMonitorSystemByID(c *gin.Context) {
 	// get param
@@ -116,11 +154,12 @@ func GetMonitorSystemByID(c *gin.Context) {
 	// init service
 	s := metadata.NewMonitorSystemServiceWithDefault()
 	// get entity
-	err = s.GetByID(id)
+	err = s.GetByID(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMonitorSystemByID, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMonitorSystemByID, id, err.Error())
 		return
 	}
+	shared.SetETag(c, s.GetMonitorSystems()[constant.ZeroInt].GetLastUpdateTime())
 	// marshal service
 	jsonBytes, err := s.Marshal()
 	if err != nil {
@@ -136,7 +175,7 @@ func GetMonitorSystemByID(c *gin.Context) {
 // @Tags monitor system
 // @Summary get monitor system by host info
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "127.0.0.1", "port_num": 3306, "port_num_slow": 3307, "base_url": "http://127.0.0.1/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Success 200 {string} string "{"code": 200604, "message": "DAS-200604: metadata: get monitor system by host info completed. host_ip: 192.168.137.11, port_num: 3306", "data": {"monitorSystems": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "192.168.137.11", "port_num": 80, "port_num_slow": 3306, "base_url": "/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/monitor-system/host-info [get]
 func GetMonitorSystemByHostInfo(c *gin.Context) {
 	// get param
@@ -155,9 +194,9 @@ func GetMonitorSystemByHostInfo(c *gin.Context) {
 	// init service
 	s := metadata.NewMonitorSystemServiceWithDefault()
 	// get entity
-	err = s.GetByHostInfo(hostIP, portNum)
+	err = s.GetByHostInfo(c.Request.Context(), hostIP, portNum)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMonitorSystemByHostInfo, hostIP, portNum, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMonitorSystemByHostInfo, hostIP, portNum, err.Error())
 		return
 	}
 	// marshal service
@@ -175,40 +214,20 @@ func GetMonitorSystemByHostInfo(c *gin.Context) {
 // @Tags monitor system
 // @Summary add a new monitor system
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "127.0.0.1", "port_num": 3306, "port_num_slow": 3307, "base_url": "http://127.0.0.1/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Success 200 {string} string "{"code": 200605, "message": "DAS-200605: metadata: add new monitor system completed. system_name: pmm, system_type: 1, host_ip: 192.168.137.11, port_num: 80, port_num_slow: 3306, base_url: /prometheus/api/v1/, env_id: 1", "data": {"monitorSystems": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "192.168.137.11", "port_num": 80, "port_num_slow": 3306, "base_url": "/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/monitor-system [post]
 func AddMonitorSystem(c *gin.Context) {
-	var fields map[string]interface{}
-
-	// get data
-	data, err := c.GetRawData()
+	// bind request
+	fields, err := request.BindFields(c, &addMonitorSystemRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.MonitorSystemInfo{}, constant.DefaultMiddlewareTag)
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, systemNameExists := fields[monitorSystemNameStruct]
-	_, systemTypeExists := fields[monitorSystemTypeStruct]
-	_, hostIPExists := fields[monitorSystemHostIPStruct]
-	_, portNumExists := fields[monitorSystemPortNumStruct]
-	_, portNumSlowExists := fields[monitorSystemPortNumSlowStruct]
-	_, baseUrlExists := fields[monitorSystemBaseUrlStruct]
-	_, envIDExists := fields[monitorSystemEnvIDStruct]
-	if !systemNameExists || !systemTypeExists || !hostIPExists || !portNumExists || !portNumSlowExists || !baseUrlExists || !envIDExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, fmt.Sprintf("%s and %s and %s and %s and %s and %s and %s",
-			monitorSystemNameStruct, monitorSystemTypeStruct, monitorSystemHostIPStruct, monitorSystemPortNumStruct, monitorSystemPortNumSlowStruct,
-			monitorSystemBaseUrlStruct, monitorSystemEnvIDStruct))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewMonitorSystemServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// insert into middleware
-	err = s.Create(fields)
+	err = s.Create(c.Request.Context(), fields)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataAddMonitorSystem,
 			fields[monitorSystemNameStruct], fields[monitorSystemTypeStruct], fields[monitorSystemHostIPStruct],
@@ -233,11 +252,10 @@ func AddMonitorSystem(c *gin.Context) {
 // @Tags monitor system
 // @Summary update monitor system by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "127.0.0.1", "port_num": 3306, "port_num_slow": 3307, "base_url": "http://127.0.0.1/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200606, "message": "DAS-200606: metadata: update monitor system completed. id: 1", "data": {"monitorSystems": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "192.168.137.11", "port_num": 80, "port_num_slow": 3306, "base_url": "/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/monitor-system/update/:id [post]
 func UpdateMonitorSystemByID(c *gin.Context) {
-	var fields map[string]interface{}
-
 	// get params
 	idStr := c.Param(monitorSystemIDJSON)
 	if idStr == constant.EmptyString {
@@ -249,37 +267,20 @@ func UpdateMonitorSystemByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
-	data, err := c.GetRawData()
+	// bind request
+	fields, err := request.BindFields(c, &updateMonitorSystemRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.MonitorSystemInfo{}, constant.DefaultMiddlewareTag)
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, systemNameExists := fields[monitorSystemNameStruct]
-	_, systemTypeExists := fields[monitorSystemTypeStruct]
-	_, hostIpExists := fields[monitorSystemHostIPStruct]
-	_, portNumExists := fields[monitorSystemPortNumStruct]
-	_, portNumSlowExists := fields[monitorSystemPortNumSlowStruct]
-	_, baseUrlExists := fields[monitorSystemBaseUrlStruct]
-	_, envIdExists := fields[monitorSystemEnvIDStruct]
-	_, delFlagExists := fields[delFlagStruct]
-	if !systemNameExists && !systemTypeExists && !hostIpExists && !portNumExists && !portNumSlowExists && !baseUrlExists && !envIdExists && !delFlagExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, fmt.Sprintf("%s and %s and %s and %s and %s and %s and %s and %s",
-			monitorSystemNameStruct, monitorSystemTypeStruct, monitorSystemHostIPStruct, monitorSystemPortNumStruct,
-			monitorSystemPortNumSlowStruct, monitorSystemBaseUrlStruct, monitorSystemEnvIDStruct, delFlagStruct))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewMonitorSystemServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
 	// update entity
-	err = s.Update(id, fields)
+	err = s.Update(c.Request.Context(), id, fields)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataUpdateMonitorSystem, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUpdateMonitorSystem, id, err.Error())
 		return
 	}
 	// marshal service
@@ -297,7 +298,9 @@ func UpdateMonitorSystemByID(c *gin.Context) {
 // @Tags monitor system
 // @Summary delete monitor system by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "127.0.0.1", "port_num": 3306, "port_num_slow": 3307, "base_url": "http://127.0.0.1/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}"
+// @Param cascade query bool false "delete the metadata which reference the monitor system as well, default is false"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200607, "message": "DAS-200607: metadata: delete monitor system completed. id: 1", "data": {"monitorSystems": []}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/monitor-system/delete/:id [post]
 func DeleteMonitorSystemByID(c *gin.Context) {
 	// get params
@@ -311,12 +314,23 @@ func DeleteMonitorSystemByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
+	cascade, err := shared.GetCascade(c)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewMonitorSystemServiceWithDefault()
-	// update entity
-	err = s.Delete(id)
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// delete entity
+	if cascade {
+		err = s.DeleteCascade(c.Request.Context(), id)
+	} else {
+		err = s.Delete(c.Request.Context(), id)
+	}
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataDeleteMonitorSystem, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataDeleteMonitorSystem, id, err.Error())
 		return
 	}
 	// marshal service
@@ -330,3 +344,42 @@ func DeleteMonitorSystemByID(c *gin.Context) {
 	log.Debug(message.NewMessage(msgmeta.DebugMetadataDeleteMonitorSystem, jsonStr).Error())
 	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataDeleteMonitorSystem, id)
 }
+
+// @Tags monitor system
+// @Summary undelete monitor system by id
+// @Produce  application/json
+// @Param id path int true "monitor system id"
+// @Success 200 {string} string "{"code": 200608, "message": "DAS-200608: metadata: undelete monitor system completed. id: 1", "data": {"monitorSystems": [{"id": 1, "system_name": "pmm", "system_type": 1, "host_ip": "192.168.137.11", "port_num": 80, "port_num_slow": 3306, "base_url": "/prometheus/api/v1/", "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/monitor-system/undelete/:id [post]
+func UndeleteMonitorSystemByID(c *gin.Context) {
+	// get params
+	idStr := c.Param(monitorSystemIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, monitorSystemIDJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := metadata.NewMonitorSystemServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	// undelete entity
+	err = s.Undelete(c.Request.Context(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUndeleteMonitorSystem, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataUndeleteMonitorSystem, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataUndeleteMonitorSystem, id)
+}
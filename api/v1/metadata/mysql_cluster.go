@@ -1,17 +1,18 @@
 package metadata
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/log"
 
+	"github.com/romberli/das/api/shared"
 	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 	msgmeta "github.com/romberli/das/pkg/message/metadata"
+	"github.com/romberli/das/pkg/request"
 	"github.com/romberli/das/pkg/resp"
 )
 
@@ -26,33 +27,61 @@ const (
 	mcOwnerIDStruct             = "OwnerID"
 	mcEnvIDStruct               = "EnvID"
 	mcMySQLServerIDListStruct   = "MySQLServerIDList"
+	mcMySQLClustersStruct       = "MySQLClusters"
 )
 
+// addMySQLClusterRequest is the request body of adding a mysql cluster
+type addMySQLClusterRequest struct {
+	ClusterName         *string `json:"cluster_name" validate:"required,min=1,max=100"`
+	MiddlewareClusterID *int    `json:"middleware_cluster_id" validate:"omitempty,gte=0"`
+	MonitorSystemID     *int    `json:"monitor_system_id" validate:"omitempty,gte=0"`
+	OwnerID             *int    `json:"owner_id" validate:"omitempty,gte=0"`
+	EnvID               *int    `json:"env_id" validate:"required,gt=0"`
+}
+
+// updateMySQLClusterRequest is the request body of updating a mysql cluster, only the specified fields are updated
+type updateMySQLClusterRequest struct {
+	ClusterName         *string `json:"cluster_name" validate:"omitempty,min=1,max=100"`
+	MiddlewareClusterID *int    `json:"middleware_cluster_id" validate:"omitempty,gte=0"`
+	MonitorSystemID     *int    `json:"monitor_system_id" validate:"omitempty,gte=0"`
+	OwnerID             *int    `json:"owner_id" validate:"omitempty,gte=0"`
+	EnvID               *int    `json:"env_id" validate:"omitempty,gt=0"`
+	DelFlag             *int    `json:"del_flag" validate:"omitempty,oneof=0 1"`
+}
+
 // @Tags mysql cluster
-// @Summary get all mysql clusters
+// @Summary get mysql clusters which match the filters, sorted and paginated
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"middleware_cluster_id":1,"monitor_system_id":1,"env_id":1,"owner_group":"2,3","del_flag":0,"create_time":"2021-02-23T20:57:24.603009+08:00","last_update_time":"2021-02-23T20:57:24.603009+08:00","id":1,"cluster_name":"cluster_name_init","owner_id":1},{"monitor_system_id":1,"owner_id":1,"owner_group":"2,3","env_id":1,"create_time":"2021-02-23T04:14:23.707238+08:00","last_update_time":"2021-02-23T04:14:23.707238+08:00","id":2,"cluster_name":"newTest","middleware_cluster_id":1,"del_flag":0}]}"
+// @Param field query string false "filter by any field, such as field=value, field=v1,v2, field_like=value, field_ge=value and field_le=value"
+// @Param sort query string false "sort by the fields, such as -id,create_time, \"-\" means descending"
+// @Param limit query int false "max number of the returned mysql clusters, 0 means no limit"
+// @Param offset query int false "number of the skipped mysql clusters"
+// @Param cursor query int false "id of the last mysql cluster of the previous page, could not be used with offset"
+// @Success 200 {string} string "{"code": 200701, "message": "DAS-200701: metadata: get mysql cluster all completed", "data": {"mysql_clusters": [{"id": 1, "cluster_name": "cluster1", "middleware_cluster_id": 1, "monitor_system_id": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}], "page": {"total": 1, "limit": 0, "offset": 0, "next_cursor": 0}}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/mysql-cluster [get]
 func GetMySQLCluster(c *gin.Context) {
+	// get params
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewMySQLClusterServiceWithDefault()
 	// get entities
-	err := s.GetAll()
+	err = s.GetByQuery(c.Request.Context(), query)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMySQLClusterAll, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMySQLClusterAll, err.Error())
 		return
 	}
 	// marshal service
-	jsonBytes, err := s.Marshal()
+	jsonBytes, err := s.MarshalWithFields(mcMySQLClustersStruct, pageStruct)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
 	}
-	fmt.Println("ok")
 	// response
-
 	jsonStr := string(jsonBytes)
-	fmt.Println(msgmeta.DebugMetadataGetMySQLClusterAll, jsonStr)
 	log.Debug(message.NewMessage(msgmeta.DebugMetadataGetMySQLClusterAll, jsonStr).Error())
 	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataGetMySQLClusterAll)
 }
@@ -73,9 +102,9 @@ func GetMySQLClusterByEnv(c *gin.Context) {
 	// init service
 	s := metadata.NewMySQLClusterServiceWithDefault()
 	// get entity
-	err = s.GetByEnv(envID)
+	err = s.GetByEnv(c.Request.Context(), envID)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMySQLClusterByEnv, envID, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMySQLClusterByEnv, envID, err.Error())
 		return
 	}
 	// marshal service
@@ -93,8 +122,9 @@ func GetMySQLClusterByEnv(c *gin.Context) {
 // @Tags mysql cluster
 // @Summary get mysql cluster by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"owner_id":1,"owner_group":"2,3","del_flag":0,"create_time":"2021-02-23T20:57:24.603009+08:00","id":1,"monitor_system_id":1,"env_id":1,"last_update_time":"2021-02-23T20:57:24.603009+08:00","cluster_name":"cluster_name_init","middleware_cluster_id":1}]}"
-// @Router /api/v1/metadata/mysql-cluster/:id [get]
+// @Header 200 {string} ETag "the version of the entity, specify it in the If-Match header when updating or deleting the entity"
+// @Success 200 {string} string "{"code": 200703, "message": "DAS-200703: metadata: get mysql cluster by id completed. id: 1", "data": {"mysql_clusters": [{"id": 1, "cluster_name": "cluster1", "middleware_cluster_id": 1, "monitor_system_id": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/mysql-cluster/get/:id [get]
 func GetMySQLClusterByID(c *gin.Context) {
 	// get param
 	idStr := c.Param(mcIDJSON)
@@ -111,11 +141,12 @@ func GetMySQLClusterByID(c *gin.Context) {
 	// init service
 	s := metadata.NewMySQLClusterServiceWithDefault()
 	// get entity
-	err = s.GetByID(id)
+	err = s.GetByID(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMySQLClusterByID, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMySQLClusterByID, id, err.Error())
 		return
 	}
+	shared.SetETag(c, s.GetMySQLClusters()[constant.ZeroInt].GetLastUpdateTime())
 	// marshal service
 	jsonBytes, err := s.Marshal()
 	if err != nil {
@@ -138,9 +169,9 @@ func GetMySQLClusterByName(c *gin.Context) {
 	// init service
 	s := metadata.NewMySQLClusterServiceWithDefault()
 	// get entity
-	err := s.GetByName(clusterName)
+	err := s.GetByName(c.Request.Context(), clusterName)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMySQLClusterByName, clusterName, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMySQLClusterByName, clusterName, err.Error())
 		return
 	}
 	// marshal service
@@ -169,9 +200,9 @@ func GetMySQLServerIDList(c *gin.Context) {
 	// init service
 	s := metadata.NewMySQLClusterServiceWithDefault()
 	// get entity
-	err = s.GetMySQLServerIDList(id)
+	err = s.GetMySQLServerIDList(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMySQLServerIDList, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMySQLServerIDList, id, err.Error())
 		return
 	}
 	// marshal service
@@ -190,36 +221,20 @@ func GetMySQLServerIDList(c *gin.Context) {
 // @Tags mysql cluster
 // @Summary add a new mysql cluster
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"cluster_name":"api_test","monitor_system_id":0,"owner_group":"","del_flag":0,"create_time":"2021-02-24T02:33:50.936279+08:00","last_update_time":"2021-02-24T02:33:50.936279+08:00","middleware_cluster_id":0,"owner_id":0,"env_id":0,"id":154}]}"
+// @Success 200 {string} string "{"code": 200706, "message": "DAS-200706: metadata: add new mysql cluster completed. cluster_name: cluster1, env_id: 1", "data": {"mysql_clusters": [{"id": 1, "cluster_name": "cluster1", "middleware_cluster_id": 1, "monitor_system_id": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/mysql-cluster [post]
 func AddMySQLCluster(c *gin.Context) {
-	var fields map[string]interface{}
-
-	// get data
-	data, err := c.GetRawData()
+	// bind request
+	fields, err := request.BindFields(c, &addMySQLClusterRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		request.ResponseNOK(c, err)
 		return
 	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.MySQLClusterInfo{}, constant.DefaultMiddlewareTag)
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	if _, ok := fields[mcClusterNameStruct]; !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, mcClusterNameStruct)
-		return
-	}
-	if _, ok := fields[mcEnvIDStruct]; !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, mcEnvIDStruct)
-		return
-	}
-
 	// init service
 	s := metadata.NewMySQLClusterServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// insert into middleware
-	err = s.Create(fields)
+	err = s.Create(c.Request.Context(), fields)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataAddMySQLCluster,
 			fields[mcClusterNameStruct],
@@ -245,11 +260,10 @@ func AddMySQLCluster(c *gin.Context) {
 // @Tags mysql cluster
 // @Summary update mysql cluster by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"id":154,"middleware_cluster_id":0,"owner_id":0,"env_id":0,"create_time":"2021-02-24T02:33:50.936279+08:00","cluster_name":"api_test","monitor_system_id":0,"owner_group":"","del_flag":1,"last_update_time":"2021-02-24T02:33:50.936279+08:00"}]}"
-// @Router /api/v1/metadata/mysql-cluster/:id [post]
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 100707, "message": "DAS-100707: metadata: update mysql cluster message: cluster1", "data": {"mysql_clusters": [{"id": 1, "cluster_name": "cluster1", "middleware_cluster_id": 1, "monitor_system_id": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/mysql-cluster/update/:id [post]
 func UpdateMySQLClusterByID(c *gin.Context) {
-	var fields map[string]interface{}
-
 	// get params
 	idStr := c.Param(mcIDJSON)
 	if idStr == constant.EmptyString {
@@ -262,46 +276,20 @@ func UpdateMySQLClusterByID(c *gin.Context) {
 		return
 
 	}
-	data, err := c.GetRawData()
+	// bind request
+	fields, err := request.BindFields(c, &updateMySQLClusterRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.MySQLClusterInfo{}, constant.DefaultMiddlewareTag)
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, clusterNameExists := fields[mcClusterNameStruct]
-	_, middlewareClusterIDExists := fields[mcMiddlewareClusterIDStruct]
-	_, monitorSystemIDExists := fields[mcMonitorSystemIDStruct]
-	_, ownerIDExists := fields[mcOwnerIDStruct]
-	_, envIDExists := fields[mcEnvIDStruct]
-	_, delFlagExists := fields[delFlagStruct]
-	if !clusterNameExists &&
-		!middlewareClusterIDExists &&
-		!monitorSystemIDExists &&
-		!ownerIDExists &&
-		!envIDExists &&
-		!delFlagExists {
-		resp.ResponseNOK(
-			c, message.ErrFieldNotExists,
-			fmt.Sprintf("%s, %s, %s, %s, %s and %s",
-				mcClusterNameStruct,
-				mcMiddlewareClusterIDStruct,
-				mcMonitorSystemIDStruct,
-				mcOwnerIDStruct,
-				mcEnvIDStruct,
-				delFlagStruct))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewMySQLClusterServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
 	// update entity
-	err = s.Update(id, fields)
+	err = s.Update(c.Request.Context(), id, fields)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataUpdateMySQLCluster, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUpdateMySQLCluster, id, err.Error())
 		return
 	}
 	// marshal service
@@ -316,6 +304,13 @@ func UpdateMySQLClusterByID(c *gin.Context) {
 	resp.ResponseOK(c, jsonStr, msgmeta.DebugMetadataUpdateMySQLCluster, fields[mcClusterNameStruct])
 }
 
+// @Tags mysql cluster
+// @Summary delete mysql cluster by id
+// @Produce  application/json
+// @Param cascade query bool false "delete the metadata which reference the mysql cluster as well, default is false"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200708, "message": "DAS-200708: metadata: delete mysql cluster completed. id: cluster1", "data": {"mysql_clusters": []}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/mysql-cluster/delete/:id [post]
 func DeleteMySQLClusterByID(c *gin.Context) {
 	var fields map[string]interface{}
 
@@ -331,12 +326,23 @@ func DeleteMySQLClusterByID(c *gin.Context) {
 		return
 
 	}
+	cascade, err := shared.GetCascade(c)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewMySQLClusterServiceWithDefault()
-	// insert into middleware
-	err = s.Delete(id)
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// delete entity
+	if cascade {
+		err = s.DeleteCascade(c.Request.Context(), id)
+	} else {
+		err = s.Delete(c.Request.Context(), id)
+	}
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataDeleteMySQLCluster,
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataDeleteMySQLCluster,
 			id, err.Error())
 		return
 	}
@@ -351,3 +357,42 @@ func DeleteMySQLClusterByID(c *gin.Context) {
 	log.Debug(message.NewMessage(msgmeta.DebugMetadataDeleteMySQLCluster, jsonStr).Error())
 	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataDeleteMySQLCluster, fields[mcClusterNameStruct])
 }
+
+// @Tags mysql cluster
+// @Summary undelete mysql cluster by id
+// @Produce  application/json
+// @Param id path int true "mysql cluster id"
+// @Success 200 {string} string "{"code": 200709, "message": "DAS-200709: metadata: undelete mysql cluster completed. id: 1", "data": {"mysql_clusters": [{"id": 1, "cluster_name": "cluster1", "middleware_cluster_id": 1, "monitor_system_id": 1, "owner_id": 1, "env_id": 1, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/mysql-cluster/undelete/:id [post]
+func UndeleteMySQLClusterByID(c *gin.Context) {
+	// get params
+	idStr := c.Param(mcIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, mcIDJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := metadata.NewMySQLClusterServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	// undelete entity
+	err = s.Undelete(c.Request.Context(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUndeleteMySQLCluster, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataUndeleteMySQLCluster, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataUndeleteMySQLCluster, id)
+}
@@ -1,17 +1,18 @@
 package metadata
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/log"
 
+	"github.com/romberli/das/api/shared"
 	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 	msgmeta "github.com/romberli/das/pkg/message/metadata"
+	"github.com/romberli/das/pkg/request"
 	"github.com/romberli/das/pkg/resp"
 )
 
@@ -26,25 +27,88 @@ const (
 	msHostIPStruct         = "HostIP"
 	msPortNumStruct        = "PortNum"
 	msDeploymentTypeStruct = "DeploymentType"
+	msServiceNameStruct    = "ServiceName"
+	msServerRoleStruct     = "ServerRole"
+	msReadWeightStruct     = "ReadWeight"
+	msReadOnlyStruct       = "ReadOnly"
+	msCPUCoresStruct       = "CPUCores"
+	msMemorySizeStruct     = "MemorySize"
+	msDiskSizeStruct       = "DiskSize"
+	msDataDirStruct        = "DataDir"
 	msVersionStruct        = "Version"
+	msStateStruct          = "State"
+	msMySQLServersStruct   = "MySQLServers"
 )
 
+// addMySQLServerRequest is the request body of adding a mysql server,
+// the deployment type is one of 1(container), 2(physical machine) and 3(virtual machine),
+// the server role is one of 1(primary), 2(replica) and 3(delayed replica),
+// the state is one of 1(provisioning), 2(online), 3(maintenance) and 4(decommissioned)
+type addMySQLServerRequest struct {
+	ClusterID      *int    `json:"cluster_id" validate:"required,gt=0"`
+	ServerName     *string `json:"server_name" validate:"required,min=1,max=100"`
+	ServiceName    *string `json:"service_name" validate:"omitempty,max=100"`
+	HostIP         *string `json:"host_ip" validate:"required,ip"`
+	PortNum        *int    `json:"port_num" validate:"required,min=1,max=65535"`
+	DeploymentType *int    `json:"deployment_type" validate:"required,oneof=1 2 3"`
+	ServerRole     *int    `json:"server_role" validate:"omitempty,oneof=1 2 3"`
+	ReadWeight     *int    `json:"read_weight" validate:"omitempty,gte=0"`
+	ReadOnly       *int    `json:"read_only" validate:"omitempty,oneof=0 1"`
+	CPUCores       *int    `json:"cpu_cores" validate:"omitempty,gte=0"`
+	MemorySize     *int    `json:"memory_size" validate:"omitempty,gte=0"`
+	DiskSize       *int    `json:"disk_size" validate:"omitempty,gte=0"`
+	DataDir        *string `json:"data_dir" validate:"omitempty,max=200"`
+	Version        *string `json:"version" validate:"omitempty,max=100"`
+	State          *int    `json:"state" validate:"omitempty,oneof=1 2 3 4"`
+}
+
+// updateMySQLServerRequest is the request body of updating a mysql server, only the specified fields are updated
+type updateMySQLServerRequest struct {
+	ClusterID      *int    `json:"cluster_id" validate:"omitempty,gt=0"`
+	ServerName     *string `json:"server_name" validate:"omitempty,min=1,max=100"`
+	ServiceName    *string `json:"service_name" validate:"omitempty,max=100"`
+	HostIP         *string `json:"host_ip" validate:"omitempty,ip"`
+	PortNum        *int    `json:"port_num" validate:"omitempty,min=1,max=65535"`
+	DeploymentType *int    `json:"deployment_type" validate:"omitempty,oneof=1 2 3"`
+	ServerRole     *int    `json:"server_role" validate:"omitempty,oneof=1 2 3"`
+	ReadWeight     *int    `json:"read_weight" validate:"omitempty,gte=0"`
+	ReadOnly       *int    `json:"read_only" validate:"omitempty,oneof=0 1"`
+	CPUCores       *int    `json:"cpu_cores" validate:"omitempty,gte=0"`
+	MemorySize     *int    `json:"memory_size" validate:"omitempty,gte=0"`
+	DiskSize       *int    `json:"disk_size" validate:"omitempty,gte=0"`
+	DataDir        *string `json:"data_dir" validate:"omitempty,max=200"`
+	Version        *string `json:"version" validate:"omitempty,max=100"`
+	State          *int    `json:"state" validate:"omitempty,oneof=1 2 3 4"`
+	DelFlag        *int    `json:"del_flag" validate:"omitempty,oneof=0 1"`
+}
+
 // @Tags mysql server
-// @Summary get all mysql servers
+// @Summary get mysql servers which match the filters, sorted and paginated
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"cluster_id":1,"deployment_type":1,"host_ip":"host_ip_init","port_num":3306,"version":"1.1.1","del_flag":0,"create_time":"2021-02-23T23:43:37.236228+08:00","last_update_time":"2021-02-23T23:43:37.236228+08:00","id":1}]}"
+// @Param field query string false "filter by any field, such as field=value, field=v1,v2, field_like=value, field_ge=value and field_le=value"
+// @Param sort query string false "sort by the fields, such as -id,create_time, \"-\" means descending"
+// @Param limit query int false "max number of the returned mysql servers, 0 means no limit"
+// @Param offset query int false "number of the skipped mysql servers"
+// @Param cursor query int false "id of the last mysql server of the previous page, could not be used with offset"
+// @Success 200 {string} string "{"code": 200801, "message": "DAS-200801: metadata: get mysql server all completed", "data": {"MySQLServers": [{"id": 1, "cluster_id": 1, "server_name": "server1", "service_name": "service1", "host_ip": "192.168.137.11", "port_num": 3306, "deployment_type": 1, "server_role": 1, "read_weight": 1, "read_only": 0, "cpu_cores": 8, "memory_size": 16, "disk_size": 500, "data_dir": "/data/mysql/data", "version": "5.7.35", "state": 2, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}], "page": {"total": 1, "limit": 0, "offset": 0, "next_cursor": 0}}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/mysql-server [get]
 func GetMySQLServer(c *gin.Context) {
+	// get params
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewMySQLServerServiceWithDefault()
 	// get entities
-	err := s.GetAll()
+	err = s.GetByQuery(c.Request.Context(), query)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMySQLServerAll, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMySQLServerAll, err.Error())
 		return
 	}
 	// marshal service
-	jsonBytes, err := s.Marshal()
+	jsonBytes, err := s.MarshalWithFields(msMySQLServersStruct, pageStruct)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
@@ -55,11 +119,10 @@ func GetMySQLServer(c *gin.Context) {
 	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataGetMySQLServerAll)
 }
 
-// TODO: Modify Swagger comment
 // @Tags mysql server
 // @Summary get mysql servers by cluster id
 // @Produce  application/json
-// @Success
+// @Success 200 {string} string "{"code": 200802, "message": "DAS-200802: metadata: get mysql server by cluster id completed. cluster_id: 1", "data": {"MySQLServers": [{"id": 1, "cluster_id": 1, "server_name": "server1", "service_name": "service1", "host_ip": "192.168.137.11", "port_num": 3306, "deployment_type": 1, "server_role": 1, "read_weight": 1, "read_only": 0, "cpu_cores": 8, "memory_size": 16, "disk_size": 500, "data_dir": "/data/mysql/data", "version": "5.7.35", "state": 2, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/mysql-server/cluster-id/:cluster_id [get]
 func GetMySQLServerByClusterID(c *gin.Context) {
 	// get param
@@ -77,9 +140,9 @@ func GetMySQLServerByClusterID(c *gin.Context) {
 	// init service
 	s := metadata.NewMySQLServerServiceWithDefault()
 	// get entity
-	err = s.GetByClusterID(clusterID)
+	err = s.GetByClusterID(c.Request.Context(), clusterID)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMySQLServerByClusterID, clusterID, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMySQLServerByClusterID, clusterID, err.Error())
 		return
 	}
 	// marshal service
@@ -97,7 +160,8 @@ func GetMySQLServerByClusterID(c *gin.Context) {
 // @Tags mysql server
 // @Summary get mysql server by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"port_num":3306,"del_flag":0,"version":"1.1.1","create_time":"2021-02-23T23:43:37.236228+08:00","last_update_time":"2021-02-23T23:43:37.236228+08:00","id":1,"cluster_id":1,"host_ip":"host_ip_init","deployment_type":1}]}"
+// @Header 200 {string} ETag "the version of the entity, specify it in the If-Match header when updating or deleting the entity"
+// @Success 200 {string} string "{"code": 200803, "message": "DAS-200803: metadata: get mysql server by id completed. id: 1", "data": {"MySQLServers": [{"id": 1, "cluster_id": 1, "server_name": "server1", "service_name": "service1", "host_ip": "192.168.137.11", "port_num": 3306, "deployment_type": 1, "server_role": 1, "read_weight": 1, "read_only": 0, "cpu_cores": 8, "memory_size": 16, "disk_size": 500, "data_dir": "/data/mysql/data", "version": "5.7.35", "state": 2, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/mysql-server/get/:id [get]
 func GetMySQLServerByID(c *gin.Context) {
 	// get param
@@ -115,11 +179,12 @@ func GetMySQLServerByID(c *gin.Context) {
 	// init service
 	s := metadata.NewMySQLServerServiceWithDefault()
 	// get entity
-	err = s.GetByID(id)
+	err = s.GetByID(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMySQLServerByID, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMySQLServerByID, id, err.Error())
 		return
 	}
+	shared.SetETag(c, s.GetMySQLServers()[constant.ZeroInt].GetLastUpdateTime())
 	// marshal service
 	jsonBytes, err := s.Marshal()
 	if err != nil {
@@ -132,12 +197,13 @@ func GetMySQLServerByID(c *gin.Context) {
 	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataGetMySQLServerByID, id)
 }
 
-// TODO: Modify Swagger comment
 // @Tags mysql server
-// @Summary get mysql servers by host info
+// @Summary get mysql server by host info
 // @Produce  application/json
-// @Success
-// @Router /api/v1/metadata/mysql-server/:id [get]
+// @Param host_ip query string true "host ip"
+// @Param port_num query int true "port num"
+// @Success 200 {string} string "{"code": 200804, "message": "DAS-200804: metadata: get mysql server by host info completed. id: 1", "data": {"MySQLServers": [{"id": 1, "cluster_id": 1, "server_name": "server1", "service_name": "service1", "host_ip": "192.168.137.11", "port_num": 3306, "deployment_type": 1, "server_role": 1, "read_weight": 1, "read_only": 0, "cpu_cores": 8, "memory_size": 16, "disk_size": 500, "data_dir": "/data/mysql/data", "version": "5.7.35", "state": 2, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/mysql-server/host-info [get]
 func GetMySQLServerByHostInfo(c *gin.Context) {
 	// get param
 	hostIP := c.Query(msHostIPJSON)
@@ -159,9 +225,9 @@ func GetMySQLServerByHostInfo(c *gin.Context) {
 	// init service
 	s := metadata.NewMySQLServerServiceWithDefault()
 	// get entity
-	err = s.GetByHostInfo(hostIP, portNum)
+	err = s.GetByHostInfo(c.Request.Context(), hostIP, portNum)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMySQLServerByHostInfo, hostIP, portNum, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMySQLServerByHostInfo, hostIP, portNum, err.Error())
 		return
 	}
 	// marshal service
@@ -179,47 +245,22 @@ func GetMySQLServerByHostInfo(c *gin.Context) {
 // @Tags mysql server
 // @Summary add a new mysql server
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"create_time":"2021-02-24T02:47:19.589172+08:00","del_flag":0,"last_update_time":"2021-02-24T02:47:19.589172+08:00","id":93,"cluster_id":0,"host_ip":"192.168.1.1","port_num":3306,"deployment_type":0,"version":""}]}"
+// @Success 200 {string} string "{"code": 200805, "message": "DAS-200805: metadata: add new mysql server completed. server_name: server1, cluster_id: 1, host_ip: 192.168.137.11, port_num: 3306, deployment_type: 1", "data": {"MySQLServers": [{"id": 1, "cluster_id": 1, "server_name": "server1", "service_name": "service1", "host_ip": "192.168.137.11", "port_num": 3306, "deployment_type": 1, "server_role": 1, "read_weight": 1, "read_only": 0, "cpu_cores": 8, "memory_size": 16, "disk_size": 500, "data_dir": "/data/mysql/data", "version": "5.7.35", "state": 2, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/mysql-server [post]
 func AddMySQLServer(c *gin.Context) {
-	var fields map[string]interface{}
-
-	// get data
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.MySQLServerInfo{}, constant.DefaultMiddlewareTag)
+	// bind request
+	fields, err := request.BindFields(c, &addMySQLServerRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, clusterIDExists := fields[msClusterIDStruct]
-	_, serverNameExists := fields[msServerNameStruct]
-	_, hostIPExists := fields[msHostIPStruct]
-	_, portNumExists := fields[msPortNumStruct]
-	_, deploymentTypeExists := fields[msDeploymentTypeStruct]
-	if !clusterIDExists || !serverNameExists || !hostIPExists || !portNumExists || !deploymentTypeExists {
-		fmt.Println("wtf")
-		resp.ResponseNOK(
-			c, message.ErrFieldNotExists,
-			fmt.Sprintf(
-				"%s and %s and %s and %s and %s",
-				msClusterIDStruct,
-				msServerNameStruct,
-				msHostIPStruct,
-				msPortNumStruct,
-				msDeploymentTypeStruct))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewMySQLServerServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// insert into middleware
-	err = s.Create(fields)
+	err = s.Create(c.Request.Context(), fields)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataAddMySQLServer,
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataAddMySQLServer,
 			fields[msServerNameStruct],
 			fields[msClusterIDStruct],
 			fields[msHostIPStruct],
@@ -247,12 +288,12 @@ func AddMySQLServer(c *gin.Context) {
 }
 
 // @Tags mysql server
-// @Summary update mysql server by id
+// @Summary update mysql server by id, the state could only transit from provisioning(1) to online(2) or decommissioned(4), from online(2) to maintenance(3) or decommissioned(4), and from maintenance(3) to online(2) or decommissioned(4)
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"last_update_time":"2021-02-24T02:47:19.589172+08:00","id":93,"cluster_id":0,"host_ip":"192.168.1.1","version":"","del_flag":1,"create_time":"2021-02-24T02:47:19.589172+08:00","port_num":3306,"deployment_type":0}]}"
-// @Router /api/v1/metadata/mysql-server/:id [post]
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 100806, "message": "DAS-100806: metadata: update mysql server message: server1", "data": {"MySQLServers": [{"id": 1, "cluster_id": 1, "server_name": "server1", "service_name": "service1", "host_ip": "192.168.137.11", "port_num": 3306, "deployment_type": 1, "server_role": 1, "read_weight": 1, "read_only": 0, "cpu_cores": 8, "memory_size": 16, "disk_size": 500, "data_dir": "/data/mysql/data", "version": "5.7.35", "state": 2, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/mysql-server/update/:id [post]
 func UpdateMySQLServerByID(c *gin.Context) {
-	var fields map[string]interface{}
 	// get param
 	idStr := c.Param(msIDJSON)
 	if idStr == constant.EmptyString {
@@ -265,49 +306,20 @@ func UpdateMySQLServerByID(c *gin.Context) {
 		return
 
 	}
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.MySQLServerInfo{}, constant.DefaultMiddlewareTag)
+	// bind request
+	fields, err := request.BindFields(c, &updateMySQLServerRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, clusterIDExists := fields[msClusterIDStruct]
-	_, serverNameExists := fields[msServerNameStruct]
-	_, hostIPExists := fields[msHostIPStruct]
-	_, portNumExists := fields[msPortNumStruct]
-	_, deploymentTypeExists := fields[msDeploymentTypeStruct]
-	_, versionExists := fields[msVersionStruct]
-	_, delFlagExists := fields[delFlagStruct]
-	if !clusterIDExists &&
-		!serverNameExists &&
-		!hostIPExists &&
-		!portNumExists &&
-		!deploymentTypeExists &&
-		!versionExists &&
-		!delFlagExists {
-		resp.ResponseNOK(
-			c, message.ErrFieldNotExists,
-			fmt.Sprintf("%s, %s, %s, %s, %s, %s and %s",
-				fields[msClusterIDStruct],
-				fields[msServerNameStruct],
-				fields[msHostIPStruct],
-				fields[msPortNumStruct],
-				fields[msDeploymentTypeStruct],
-				fields[msVersionStruct],
-				fields[delFlagStruct]))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewMySQLServerServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
 	// update entity
-	err = s.Update(id, fields)
+	err = s.Update(c.Request.Context(), id, fields)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataUpdateMySQLServer, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUpdateMySQLServer, id, err.Error())
 		return
 	}
 	// marshal service
@@ -322,12 +334,13 @@ func UpdateMySQLServerByID(c *gin.Context) {
 	resp.ResponseOK(c, jsonStr, msgmeta.DebugMetadataUpdateMySQLServer, fields[msServerNameStruct])
 }
 
-// TODO: Modify Swagger comment
 // @Tags mysql server
-// @Summary get mysql servers by host info
+// @Summary delete mysql server by id
 // @Produce  application/json
-// @Success
-// @Router /api/v1/metadata/mysql-server/:id [get]
+// @Param cascade query bool false "delete the metadata which reference the mysql server as well, default is false"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200807, "message": "DAS-200807: metadata: delete mysql server completed. id: 1", "data": {"MySQLServers": []}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/mysql-server/delete/:id [post]
 func DeleteMySQLServerByID(c *gin.Context) {
 	var fields map[string]interface{}
 
@@ -343,12 +356,23 @@ func DeleteMySQLServerByID(c *gin.Context) {
 		return
 
 	}
+	cascade, err := shared.GetCascade(c)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewMySQLServerServiceWithDefault()
-	// insert into middleware
-	err = s.Delete(id)
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// delete entity
+	if cascade {
+		err = s.DeleteCascade(c.Request.Context(), id)
+	} else {
+		err = s.Delete(c.Request.Context(), id)
+	}
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataDeleteMySQLServer,
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataDeleteMySQLServer,
 			id, err.Error())
 		return
 	}
@@ -363,3 +387,42 @@ func DeleteMySQLServerByID(c *gin.Context) {
 	log.Debug(message.NewMessage(msgmeta.DebugMetadataDeleteMySQLServer, jsonStr).Error())
 	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataDeleteMySQLServer, fields[msServerNameStruct])
 }
+
+// @Tags mysql server
+// @Summary undelete mysql server by id
+// @Produce  application/json
+// @Param id path int true "mysql server id"
+// @Success 200 {string} string "{"code": 200808, "message": "DAS-200808: metadata: undelete mysql server completed. id: 1", "data": {"MySQLServers": [{"id": 1, "cluster_id": 1, "server_name": "server1", "service_name": "service1", "host_ip": "192.168.137.11", "port_num": 3306, "deployment_type": 1, "server_role": 1, "read_weight": 1, "read_only": 0, "cpu_cores": 8, "memory_size": 16, "disk_size": 500, "data_dir": "/data/mysql/data", "version": "5.7.35", "state": 2, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/mysql-server/undelete/:id [post]
+func UndeleteMySQLServerByID(c *gin.Context) {
+	// get params
+	idStr := c.Param(msIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, msIDJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := metadata.NewMySQLServerServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	// undelete entity
+	err = s.Undelete(c.Request.Context(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUndeleteMySQLServer, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataUndeleteMySQLServer, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataUndeleteMySQLServer, id)
+}
@@ -1,15 +1,16 @@
 package metadata
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/api/shared"
 	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 	msgmeta "github.com/romberli/das/pkg/message/metadata"
+	"github.com/romberli/das/pkg/request"
 	"github.com/romberli/das/pkg/resp"
-	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/log"
 )
@@ -30,25 +31,62 @@ const (
 	telephoneStruct      = "Telephone"
 	roleStruct           = "Role"
 	mobileStruct         = "Mobile"
+	usersStruct          = "Users"
 )
 
+// addUserRequest is the request body of adding a user
+type addUserRequest struct {
+	UserName       *string `json:"user_name" validate:"required,min=1,max=100"`
+	DepartmentName *string `json:"department_name" validate:"required,min=1,max=100"`
+	EmployeeID     *string `json:"employee_id" validate:"omitempty,max=100"`
+	AccountName    *string `json:"account_name" validate:"required,min=1,max=100"`
+	Email          *string `json:"email" validate:"required,email,max=100"`
+	Telephone      *string `json:"telephone" validate:"omitempty,max=100"`
+	Mobile         *string `json:"mobile" validate:"omitempty,max=100"`
+	Role           *int    `json:"role" validate:"required,oneof=1 2 3"`
+}
+
+// updateUserRequest is the request body of updating a user, only the specified fields are updated
+type updateUserRequest struct {
+	UserName       *string `json:"user_name" validate:"omitempty,min=1,max=100"`
+	DepartmentName *string `json:"department_name" validate:"omitempty,min=1,max=100"`
+	EmployeeID     *string `json:"employee_id" validate:"omitempty,max=100"`
+	AccountName    *string `json:"account_name" validate:"omitempty,min=1,max=100"`
+	Email          *string `json:"email" validate:"omitempty,email,max=100"`
+	Telephone      *string `json:"telephone" validate:"omitempty,max=100"`
+	Mobile         *string `json:"mobile" validate:"omitempty,max=100"`
+	Role           *int    `json:"role" validate:"omitempty,oneof=1 2 3"`
+	DelFlag        *int    `json:"del_flag" validate:"omitempty,oneof=0 1"`
+}
+
 // @Tags user
-// @Summary get all users
+// @Summary get users which match the filters, sorted and paginated
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"department_name": "dn","accountNameStruct = "AccountName"": "da", "mobile": "m", "del_flag": 0,"last_update_time": "2021-01-21T13:00:00+08:00","user_name": "un","create_time": "2021-01-21T13:00:00+08:00","employee_id": 1,"email": "e","telephone": "t","role": 1, "id": 1}]}"
+// @Param field query string false "filter by any field, such as field=value, field=v1,v2, field_like=value, field_ge=value and field_le=value"
+// @Param sort query string false "sort by the fields, such as -id,create_time, \"-\" means descending"
+// @Param limit query int false "max number of the returned users, 0 means no limit"
+// @Param offset query int false "number of the skipped users"
+// @Param cursor query int false "id of the last user of the previous page, could not be used with offset"
+// @Success 200 {string} string "{"code": 200901, "message": "DAS-200901: metadata: get user all completed", "data": {"users": [{"id": 1, "user_name": "zhangs", "department_name": "dba", "employee_id": "100001", "account_name": "zhangs", "email": "zhangs@example.com", "telephone": "01012345678", "mobile": "13012345678", "role": 3, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}], "page": {"total": 1, "limit": 0, "offset": 0, "next_cursor": 0}}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/user [get]
 
 func GetUser(c *gin.Context) {
+	// get params
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewUserServiceWithDefault()
 	// get entities
-	err := s.GetAll()
+	err = s.GetByQuery(c.Request.Context(), query)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetUserAll, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetUserAll, err.Error())
 		return
 	}
 	// marshal service
-	jsonBytes, err := s.Marshal()
+	jsonBytes, err := s.MarshalWithFields(usersStruct, pageStruct)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
@@ -62,7 +100,7 @@ func GetUser(c *gin.Context) {
 // @Tags user
 // @Summary get user by Name
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"department_name": "dn","accountNameStruct = "AccountName"": "da", "mobile": "m", "del_flag": 0,"last_update_time": "2021-01-21T13:00:00+08:00","user_name": "un","create_time": "2021-01-21T13:00:00+08:00","employee_id": 1,"email": "e","telephone": "t","role": 1, "id": 1}]}"
+// @Success 200 {string} string "{"code": 200905, "message": "DAS-200905: metadata: get user by username completed.Name: zhangs", "data": {"users": [{"id": 1, "user_name": "zhangs", "department_name": "dba", "employee_id": "100001", "account_name": "zhangs", "email": "zhangs@example.com", "telephone": "01012345678", "mobile": "13012345678", "role": 3, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/user/user-name/:user_name [get]
 func GetUserByName(c *gin.Context) {
 	// get param
@@ -74,9 +112,9 @@ func GetUserByName(c *gin.Context) {
 	// init service
 	s := metadata.NewUserServiceWithDefault()
 	// get UserRepo
-	err := s.GetByName(userName)
+	err := s.GetByName(c.Request.Context(), userName)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetUserByName, userName, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetUserByName, userName, err.Error())
 		return
 	}
 	// marshal service
@@ -94,7 +132,8 @@ func GetUserByName(c *gin.Context) {
 // @Tags user
 // @Summary get user by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"department_name": "dn","accountNameStruct = "AccountName"": "da", "mobile": "m", "del_flag": 0,"last_update_time": "2021-01-21T13:00:00+08:00","user_name": "un","create_time": "2021-01-21T13:00:00+08:00","employee_id": 1,"email": "e","telephone": "t","role": 1, "id": 1}]}"
+// @Header 200 {string} ETag "the version of the entity, specify it in the If-Match header when updating or deleting the entity"
+// @Success 200 {string} string "{"code": 200902, "message": "DAS-200902: metadata: get user by id completed. id: 1", "data": {"users": [{"id": 1, "user_name": "zhangs", "department_name": "dba", "employee_id": "100001", "account_name": "zhangs", "email": "zhangs@example.com", "telephone": "01012345678", "mobile": "13012345678", "role": 3, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/user/get/:id [get]
 func GetUserByID(c *gin.Context) {
 	// get param
@@ -107,11 +146,12 @@ func GetUserByID(c *gin.Context) {
 	// init service
 	s := metadata.NewUserServiceWithDefault()
 	// get UserRepo
-	err = s.GetByID(id)
+	err = s.GetByID(c.Request.Context(), id)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetUserByID, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetUserByID, id, err.Error())
 		return
 	}
+	shared.SetETag(c, s.GetUsers()[constant.ZeroInt].GetLastUpdateTime())
 	// marshal service
 	jsonBytes, err := s.Marshal()
 	if err != nil {
@@ -127,7 +167,7 @@ func GetUserByID(c *gin.Context) {
 // @Tags user
 // @Summary get user by EmployeeID
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"department_name": "dn","accountNameStruct = "AccountName"": "da", "mobile": "m", "del_flag": 0,"last_update_time": "2021-01-21T13:00:00+08:00","user_name": "un","create_time": "2021-01-21T13:00:00+08:00","employee_id": 1,"email": "e","telephone": "t","role": 1, "id": 1}]}"
+// @Success 200 {string} string "{"code": 200906, "message": "DAS-200906: metadata: get user by employeeid completed.employID: 1", "data": {"users": [{"id": 1, "user_name": "zhangs", "department_name": "dba", "employee_id": "100001", "account_name": "zhangs", "email": "zhangs@example.com", "telephone": "01012345678", "mobile": "13012345678", "role": 3, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/user/employee-id/:employee_id [get]
 func GetUserByEmployeeID(c *gin.Context) {
 	// get param
@@ -139,9 +179,9 @@ func GetUserByEmployeeID(c *gin.Context) {
 	// init service
 	s := metadata.NewUserServiceWithDefault()
 	// get UserRepo
-	err := s.GetByEmployeeID(employeeID)
+	err := s.GetByEmployeeID(c.Request.Context(), employeeID)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetEmployeeID, employeeID, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetEmployeeID, employeeID, err.Error())
 		return
 	}
 	// marshal service
@@ -159,7 +199,7 @@ func GetUserByEmployeeID(c *gin.Context) {
 // @Tags user
 // @Summary get user by AccountName
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"department_name": "dn","accountNameStruct = "AccountName"": "da", "mobile": "m", "del_flag": 0,"last_update_time": "2021-01-21T13:00:00+08:00","user_name": "un","create_time": "2021-01-21T13:00:00+08:00","employee_id": 1,"email": "e","telephone": "t","role": 1, "id": 1}]}"
+// @Success 200 {string} string "{"code": 200907, "message": "DAS-200907: metadata: get user by accountname completed.accountName: zhangs", "data": {"users": [{"id": 1, "user_name": "zhangs", "department_name": "dba", "employee_id": "100001", "account_name": "zhangs", "email": "zhangs@example.com", "telephone": "01012345678", "mobile": "13012345678", "role": 3, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/user/account-name/:account_name [get]
 func GetUserByAccountName(c *gin.Context) {
 	// get param
@@ -171,9 +211,9 @@ func GetUserByAccountName(c *gin.Context) {
 	// init service
 	s := metadata.NewUserServiceWithDefault()
 	// get UserRepo
-	err := s.GetByAccountName(accountName)
+	err := s.GetByAccountName(c.Request.Context(), accountName)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetAccountName, accountName, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetAccountName, accountName, err.Error())
 		return
 	}
 	// marshal service
@@ -191,8 +231,8 @@ func GetUserByAccountName(c *gin.Context) {
 // @Tags user
 // @Summary get user by Email
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"department_name": "dn","accountNameStruct = "AccountName"": "da", "mobile": "m", "del_flag": 0,"last_update_time": "2021-01-21T13:00:00+08:00","user_name": "un","create_time": "2021-01-21T13:00:00+08:00","employee_id": 1,"email": "e","telephone": "t","role": 1, "id": 1}]}"
-// @Router /api/v1/metadata"/user/email/:email [get]
+// @Success 200 {string} string "{"code": 200908, "message": "DAS-200908: metadata: get user by email completed.email: zhangs@example.com", "data": {"users": [{"id": 1, "user_name": "zhangs", "department_name": "dba", "employee_id": "100001", "account_name": "zhangs", "email": "zhangs@example.com", "telephone": "01012345678", "mobile": "13012345678", "role": 3, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/user/email/:email [get]
 func GetUserByEmail(c *gin.Context) {
 	// get param
 	email := c.Param(emailJSON)
@@ -203,9 +243,9 @@ func GetUserByEmail(c *gin.Context) {
 	// init service
 	s := metadata.NewUserServiceWithDefault()
 	// get UserRepo
-	err := s.GetByEmail(email)
+	err := s.GetByEmail(c.Request.Context(), email)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetEmail, email, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetEmail, email, err.Error())
 		return
 	}
 	// marshal service
@@ -223,7 +263,7 @@ func GetUserByEmail(c *gin.Context) {
 // @Tags user
 // @Summary get user by Telephone
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"department_name": "dn","accountNameStruct = "AccountName"": "da", "mobile": "m", "del_flag": 0,"last_update_time": "2021-01-21T13:00:00+08:00","user_name": "un","create_time": "2021-01-21T13:00:00+08:00","employee_id": 1,"email": "e","telephone": "t","role": 1, "id": 1}]}"
+// @Success 200 {string} string "{"code": 200909, "message": "DAS-200909: metadata: get user by telephone completed.telephone: 01012345678", "data": {"users": [{"id": 1, "user_name": "zhangs", "department_name": "dba", "employee_id": "100001", "account_name": "zhangs", "email": "zhangs@example.com", "telephone": "01012345678", "mobile": "13012345678", "role": 3, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/user/telephone/:telephone [get]
 func GetUserByTelephone(c *gin.Context) {
 	// get param
@@ -235,9 +275,9 @@ func GetUserByTelephone(c *gin.Context) {
 	// init service
 	s := metadata.NewUserServiceWithDefault()
 	// get UserRepo
-	err := s.GetByTelephone(telephone)
+	err := s.GetByTelephone(c.Request.Context(), telephone)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetTelephone, telephone, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetTelephone, telephone, err.Error())
 		return
 	}
 	// marshal service
@@ -255,7 +295,7 @@ func GetUserByTelephone(c *gin.Context) {
 // @Tags user
 // @Summary get user by Mobile
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"department_name": "dn","accountNameStruct = "AccountName"": "da", "mobile": "m", "del_flag": 0,"last_update_time": "2021-01-21T13:00:00+08:00","user_name": "un","create_time": "2021-01-21T13:00:00+08:00","employee_id": 1,"email": "e","telephone": "t","role": 1, "id": 1}]}"
+// @Success 200 {string} string "{"code": 200910, "message": "DAS-200910: metadata: get user by mobile completed.mobile: 13012345678", "data": {"users": [{"id": 1, "user_name": "zhangs", "department_name": "dba", "employee_id": "100001", "account_name": "zhangs", "email": "zhangs@example.com", "telephone": "01012345678", "mobile": "13012345678", "role": 3, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/user/mobile/:mobile [get]
 func GetUserByMobile(c *gin.Context) {
 	// get param
@@ -267,9 +307,9 @@ func GetUserByMobile(c *gin.Context) {
 	// init service
 	s := metadata.NewUserServiceWithDefault()
 	// get UserRepo
-	err := s.GetByMobile(mobile)
+	err := s.GetByMobile(c.Request.Context(), mobile)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataGetMobile, mobile, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetMobile, mobile, err.Error())
 		return
 	}
 	// marshal service
@@ -287,57 +327,20 @@ func GetUserByMobile(c *gin.Context) {
 // @Tags user
 // @Summary add a new user
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"department_name": "dn","accountNameStruct = "AccountName"": "da", "mobile": "m", "del_flag": 0,"last_update_time": "2021-01-21T13:00:00+08:00","user_name": "un","create_time": "2021-01-21T13:00:00+08:00","employee_id": 1,"email": "e","telephone": "t","role": 1, "id": 1}]}"
+// @Success 200 {string} string "{"code": 200903, "message": "DAS-200903: metadata: add new user completed. user_name: zhangs", "data": {"users": [{"id": 1, "user_name": "zhangs", "department_name": "dba", "employee_id": "100001", "account_name": "zhangs", "email": "zhangs@example.com", "telephone": "01012345678", "mobile": "13012345678", "role": 3, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/user [post]
 func AddUser(c *gin.Context) {
-	var fields map[string]interface{}
-
-	// get data
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.UserInfo{}, constant.DefaultMiddlewareTag)
+	// bind request
+	fields, err := request.BindFields(c, &addUserRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, ok := fields[userNameStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, userNameStruct)
-		return
-	}
-	_, ok = fields[emailStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, emailStruct)
-		return
-	}
-	_, ok = fields[departmentNameStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, departmentNameStruct)
-		return
-	}
-	// _, ok = fields[employeeIDStruct]
-	// if !ok {
-	// 	resp.ResponseNOK(c, message.ErrFieldNotExists, employeeIDStruct)
-	// 	return
-	// }
-	_, ok = fields[accountNameStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, accountNameStruct)
-		return
-	}
-	_, ok = fields[roleStruct]
-	if !ok {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, roleStruct)
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewUserServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
 	// insert into middleware
-	err = s.Create(fields)
+	err = s.Create(c.Request.Context(), fields)
 	if err != nil {
 		resp.ResponseNOK(c, msgmeta.ErrMetadataAddUser, fields[userNameStruct], err.Error())
 		return
@@ -357,47 +360,30 @@ func AddUser(c *gin.Context) {
 // @Tags user
 // @Summary update user by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"department_name": "dn","accountNameStruct = "AccountName"": "da", "mobile": "m", "del_flag": 0,"last_update_time": "2021-01-21T13:00:00+08:00","user_name": "un","create_time": "2021-01-21T13:00:00+08:00","employee_id": 1,"email": "e","telephone": "t","role": 1, "id": 1}]}"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 100904, "message": "DAS-100904: metadata: update user message: 1", "data": {"users": [{"id": 1, "user_name": "zhangs", "department_name": "dba", "employee_id": "100001", "account_name": "zhangs", "email": "zhangs@example.com", "telephone": "01012345678", "mobile": "13012345678", "role": 3, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
 // @Router /api/v1/metadata/user/update/:id [post]
 func UpdateUserByID(c *gin.Context) {
-	var fields map[string]interface{}
-
 	// get params
 	idStr := c.Param(idJSON)
 	if idStr == constant.EmptyString {
 		resp.ResponseNOK(c, message.ErrFieldNotExists, idJSON)
 	}
 	id, err := strconv.Atoi(idStr)
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-	// unmarshal data
-	fields, err = common.UnmarshalToMapWithStructTag(data, &metadata.UserInfo{}, constant.DefaultMiddlewareTag)
+	// bind request
+	fields, err := request.BindFields(c, &updateUserRequest{})
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-	_, userNameExists := fields[userNameStruct]
-	_, departmentNameExists := fields[departmentNameStruct]
-	_, employeeIDExists := fields[employeeIDStruct]
-	_, accountNameExists := fields[accountNameStruct]
-	_, emailExists := fields[emailStruct]
-	_, mobileExists := fields[mobileStruct]
-	_, telephoneExists := fields[telephoneStruct]
-	_, roleExists := fields[roleStruct]
-	_, delFlagExists := fields[delFlagStruct]
-	if !userNameExists && !departmentNameExists && !employeeIDExists && !accountNameExists && !emailExists && !telephoneExists && !roleExists && !delFlagExists && !mobileExists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, fmt.Sprintf("%s and %s", userNameStruct, delFlagStruct))
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	s := metadata.NewUserServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
 	// update UserRepo
-	err = s.Update(id, fields)
+	err = s.Update(c.Request.Context(), id, fields)
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataUpdateUser, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUpdateUser, err.Error())
 		return
 	}
 	// marshal service
@@ -415,8 +401,10 @@ func UpdateUserByID(c *gin.Context) {
 // @Tags user
 // @Summary delete user by id
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": []}"
-// @Router /api/v1/metadata/user/delete/:id [get]
+// @Param cascade query bool false "delete the metadata which reference the user as well, default is false"
+// @Param If-Match header string false "the etag which the entity must match, it responses 412 if the entity was modified after the etag was got"
+// @Success 200 {string} string "{"code": 200911, "message": "DAS-200911: metadata: delete user by ID completed. id: 1", "data": {"users": []}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/user/delete/:id [post]
 func DeleteUserByID(c *gin.Context) {
 	var fields map[string]interface{}
 
@@ -431,12 +419,23 @@ func DeleteUserByID(c *gin.Context) {
 		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
 		return
 	}
+	cascade, err := shared.GetCascade(c)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
 	// init service
 	s := metadata.NewUserServiceWithDefault()
-	// update entities
-	err = s.Delete(id)
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// delete entity
+	if cascade {
+		err = s.DeleteCascade(c.Request.Context(), id)
+	} else {
+		err = s.Delete(c.Request.Context(), id)
+	}
 	if err != nil {
-		resp.ResponseNOK(c, msgmeta.ErrMetadataDeleteUserByID, id, err.Error())
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataDeleteUserByID, id, err.Error())
 		return
 	}
 	// marshal service
@@ -450,3 +449,42 @@ func DeleteUserByID(c *gin.Context) {
 	log.Debug(message.NewMessage(msgmeta.DebugMetadataDeleteUserByID, jsonStr).Error())
 	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataDeleteUserByID, fields[userNameStruct])
 }
+
+// @Tags user
+// @Summary undelete user by id
+// @Produce  application/json
+// @Param id path int true "user id"
+// @Success 200 {string} string "{"code": 200912, "message": "DAS-200912: metadata: undelete user completed. id: 1", "data": {"users": [{"id": 1, "user_name": "zhangs", "department_name": "dba", "employee_id": "100001", "account_name": "zhangs", "email": "zhangs@example.com", "telephone": "01012345678", "mobile": "13012345678", "role": 3, "del_flag": 0, "create_time": "2021-01-22T09:59:21.379851+08:00", "last_update_time": "2021-01-22T09:59:21.379851+08:00"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/metadata/user/undelete/:id [post]
+func UndeleteUserByID(c *gin.Context) {
+	// get params
+	idStr := c.Param(idJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, idJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := metadata.NewUserServiceWithDefault()
+	s.SetAuditor(shared.NewAuditor(c))
+	// undelete entity
+	err = s.Undelete(c.Request.Context(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUndeleteUserByID, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataUndeleteUserByID, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataUndeleteUserByID, id)
+}
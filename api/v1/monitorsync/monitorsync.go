@@ -0,0 +1,72 @@
+package monitorsync
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/internal/app/monitorsync"
+	"github.com/romberli/das/pkg/message"
+	msgmonitorsync "github.com/romberli/das/pkg/message/monitorsync"
+	"github.com/romberli/das/pkg/request"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	monitorSystemIDJSON = "monitor_system_id"
+)
+
+// syncRequest is the request body of syncing by monitor system, the missing mysql servers are not created by default
+type syncRequest struct {
+	Create    bool `json:"create"`
+	ClusterID int  `json:"cluster_id" validate:"gte=0"`
+}
+
+// @Tags monitorsync
+// @Summary sync the monitored mysql services of the monitor system with the registered mysql servers
+// @Accept  application/json
+// @Produce  application/json
+// @Param monitor_system_id path int true "monitor system id"
+// @Param body body string false "{"create": false, "cluster_id": 1}"
+// @Success 200 {string} string "{"code": 206001, "message": "DAS-206001: monitorsync: sync by monitor system completed. monitor system id: 1, create: false", "data": {"reports": [{"monitor_system_id": 1, "system_type": 2, "matched": [...], "monitored_not_registered": [...], "registered_not_monitored": [...], "created": [], "warnings": []}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/monitorsync/sync/:monitor_system_id [post]
+func SyncByMonitorSystem(c *gin.Context) {
+	// get params
+	idStr := c.Param(monitorSystemIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, monitorSystemIDJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// bind request, the body is optional
+	req := &syncRequest{}
+	err = request.Bind(c, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	// init service
+	s := monitorsync.NewServiceWithDefault()
+	// sync
+	err = s.SyncByMonitorSystem(c.Request.Context(), id, req.Create, req.ClusterID)
+	if err != nil {
+		resp.ResponseNOK(c, msgmonitorsync.ErrMonitorSyncSyncByMonitorSystem, id, req.Create, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmonitorsync.DebugMonitorSyncSyncByMonitorSystem, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmonitorsync.InfoMonitorSyncSyncByMonitorSystem, id, req.Create)
+}
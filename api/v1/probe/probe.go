@@ -0,0 +1,71 @@
+package probe
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/internal/app/probe"
+	"github.com/romberli/das/pkg/message"
+	msgprobe "github.com/romberli/das/pkg/message/probe"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	monitorSystemsJSON = "monitor_systems"
+
+	liveResponse        = `{"status": "alive"}`
+	dependencySeparator = ","
+)
+
+// @Tags probe
+// @Summary check if das is alive, it does not check any dependency
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 210001, "message": "DAS-210001: probe: das is alive. pid: 12345", "data": {"status": "alive"}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /healthz [get]
+func Live(c *gin.Context) {
+	resp.ResponseOK(c, liveResponse, msgprobe.InfoProbeLive, os.Getpid())
+}
+
+// @Tags probe
+// @Summary check if das is ready to serve, it checks the metadata database, the soar binary and config file, and the monitor systems if monitor_systems is true
+// @Produce  application/json
+// @Param monitor_systems query bool false "check if the monitor systems are reachable, default is false"
+// @Success 200 {string} string "{"code": 210002, "message": "DAS-210002: probe: das is ready", "data": {"status": "ready", "dependencies": [{"name": "das_mysql", "status": "ok", "latency_ms": 0.5}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Failure 503 {string} string "{"code": 410001, "data": {"status": "not_ready", "dependencies": [{"name": "soar_bin", "status": "failed", "message": "...", "latency_ms": 0.1}]}}"
+// @Router /readyz [get]
+func Ready(c *gin.Context) {
+	// get params
+	checkMonitorSystems := false
+	monitorSystemsStr := c.Query(monitorSystemsJSON)
+	if monitorSystemsStr != constant.EmptyString {
+		var err error
+		checkMonitorSystems, err = strconv.ParseBool(monitorSystemsStr)
+		if err != nil {
+			resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+			return
+		}
+	}
+	// init service
+	s := probe.NewServiceWithDefault()
+	// check dependencies
+	s.Check(c.Request.Context(), checkMonitorSystems)
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgprobe.DebugProbeReady, jsonStr).Error())
+	if !s.IsReady() {
+		resp.ResponseNOKWithData(c, jsonStr, msgprobe.ErrProbeNotReady, strings.Join(s.GetFailedDependencies(), dependencySeparator))
+		return
+	}
+	resp.ResponseOK(c, jsonStr, msgprobe.InfoProbeReady)
+}
@@ -0,0 +1,164 @@
+package query
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/internal/app/query"
+	"github.com/romberli/das/pkg/message"
+	msgquery "github.com/romberli/das/pkg/message/query"
+	"github.com/romberli/das/pkg/request"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	defaultLimit     = 10
+	defaultThreshold = 1.5
+)
+
+type getTopRequest struct {
+	ServerID  int       `json:"server_id" validate:"required,gt=0"`
+	DBName    string    `json:"db_name"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required"`
+	OrderBy   string    `json:"order_by" validate:"oneof=total_exec_time avg_exec_time rows_examined_max exec_count"`
+	Limit     int       `json:"limit" validate:"gt=0"`
+}
+
+type getTrendRequest struct {
+	ServerID  int       `json:"server_id" validate:"required,gt=0"`
+	SQLID     string    `json:"sql_id" validate:"required,notblank"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required"`
+}
+
+type getRegressionRequest struct {
+	ServerID      int       `json:"server_id" validate:"required,gt=0"`
+	DBName        string    `json:"db_name"`
+	BaseStartTime time.Time `json:"base_start_time" validate:"required"`
+	BaseEndTime   time.Time `json:"base_end_time" validate:"required"`
+	StartTime     time.Time `json:"start_time" validate:"required"`
+	EndTime       time.Time `json:"end_time" validate:"required"`
+	Threshold     float64   `json:"threshold" validate:"gt=0"`
+	Limit         int       `json:"limit" validate:"gt=0"`
+}
+
+// @Tags query
+// @Summary get top n slow queries of the mysql server in the time window
+// @Produce  application/json
+// @Param server_id query int true "mysql server id"
+// @Param db_name query string false "db name"
+// @Param start_time query string true "start time, format: yyyy-MM-dd HH:mm:ss"
+// @Param end_time query string true "end time, format: yyyy-MM-dd HH:mm:ss"
+// @Param order_by query string false "total_exec_time(default), avg_exec_time, rows_examined_max or exec_count"
+// @Param limit query int false "top n, default: 10"
+// @Success 200 {string} string "{"code": 203001, "message": "DAS-203001: query: get top slow queries completed. server_id: 1", "data": {"slow_queries": [{"sql_id": "EE56B94E867DC9D5", "fingerprint": "select * from t01 where id = ?", ...}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/query/slow/top [get]
+func GetTop(c *gin.Context) {
+	// bind request
+	req := &getTopRequest{OrderBy: query.OrderByTotalExecTime, Limit: defaultLimit}
+	err := request.BindQuery(c, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	// init service
+	s := query.NewService()
+	// get entities
+	err = s.GetTopByMySQLServerID(c.Request.Context(), req.ServerID, req.DBName, req.StartTime, req.EndTime, req.OrderBy, req.Limit)
+	if err != nil {
+		resp.ResponseNOK(c, msgquery.ErrQueryGetTop, req.ServerID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalWithFields(query.SlowQueriesStruct)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgquery.DebugQueryGetTop, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgquery.InfoQueryGetTop, req.ServerID)
+}
+
+// @Tags query
+// @Summary get trend of the slow query of the mysql server in the time window
+// @Produce  application/json
+// @Param server_id query int true "mysql server id"
+// @Param sql_id query string true "sql id"
+// @Param start_time query string true "start time, format: yyyy-MM-dd HH:mm:ss"
+// @Param end_time query string true "end time, format: yyyy-MM-dd HH:mm:ss"
+// @Success 200 {string} string "{"code": 203002, "message": "DAS-203002: query: get slow query trend completed. server_id: 1, sql_id: EE56B94E867DC9D5", "data": {"trend": [{"period_start": "2021-01-21T10:00:00+08:00", "exec_count": 10, ...}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/query/slow/trend [get]
+func GetTrend(c *gin.Context) {
+	// bind request
+	req := &getTrendRequest{}
+	err := request.BindQuery(c, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	// init service
+	s := query.NewService()
+	// get entities
+	err = s.GetTrendByMySQLServerID(c.Request.Context(), req.ServerID, req.SQLID, req.StartTime, req.EndTime)
+	if err != nil {
+		resp.ResponseNOK(c, msgquery.ErrQueryGetTrend, req.ServerID, req.SQLID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalWithFields(query.TrendStruct)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgquery.DebugQueryGetTrend, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgquery.InfoQueryGetTrend, req.ServerID, req.SQLID)
+}
+
+// @Tags query
+// @Summary get regressed slow queries of the mysql server by comparing the current time window with the base time window
+// @Produce  application/json
+// @Param server_id query int true "mysql server id"
+// @Param db_name query string false "db name"
+// @Param base_start_time query string true "start time of the base window, format: yyyy-MM-dd HH:mm:ss"
+// @Param base_end_time query string true "end time of the base window, format: yyyy-MM-dd HH:mm:ss"
+// @Param start_time query string true "start time of the current window, format: yyyy-MM-dd HH:mm:ss"
+// @Param end_time query string true "end time of the current window, format: yyyy-MM-dd HH:mm:ss"
+// @Param threshold query number false "min growing ratio of the average execution time, default: 1.5"
+// @Param limit query int false "top n slow queries of the current window to compare, default: 10"
+// @Success 200 {string} string "{"code": 203003, "message": "DAS-203003: query: get slow query regression completed. server_id: 1", "data": {"regressions": [{"sql_id": "EE56B94E867DC9D5", "avg_exec_time_ratio": 2.5, "is_new": false, ...}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/query/slow/regression [get]
+func GetRegression(c *gin.Context) {
+	// bind request
+	req := &getRegressionRequest{Threshold: defaultThreshold, Limit: defaultLimit}
+	err := request.BindQuery(c, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	// init service
+	s := query.NewService()
+	// get entities
+	err = s.GetRegressionByMySQLServerID(c.Request.Context(), req.ServerID, req.DBName,
+		req.BaseStartTime, req.BaseEndTime, req.StartTime, req.EndTime, req.Threshold, req.Limit)
+	if err != nil {
+		resp.ResponseNOK(c, msgquery.ErrQueryGetRegression, req.ServerID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalWithFields(query.RegressionsStruct)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgquery.DebugQueryGetRegression, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgquery.InfoQueryGetRegression, req.ServerID)
+}
@@ -0,0 +1,169 @@
+package review
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/api/v1/auth"
+	"github.com/romberli/das/internal/app/review"
+	"github.com/romberli/das/pkg/message"
+	msgauth "github.com/romberli/das/pkg/message/auth"
+	msgreview "github.com/romberli/das/pkg/message/review"
+	"github.com/romberli/das/pkg/request"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	idJSON   = "id"
+	dbIDJSON = "db_id"
+)
+
+// reviewRequest is the request body of reviewing the sql text
+type reviewRequest struct {
+	SQLText string `json:"sql_text" validate:"required,notblank"`
+}
+
+// @Tags review
+// @Summary review the sql text of the database
+// @Produce  application/json
+// @Param db_id path int true "db id"
+// @Param sql_text body string true "sql text"
+// @Success 200 {string} string "{"code": 204001, "message": "DAS-204001: review: review sql text completed. db id: 1, verdict: 3", "data": {"reviews": [{"id": 1, "db_id": 1, "sql_text": "create table t01(id int primary key);", "verdict": 3, "result": "[{\"sql_text\": \"create table t01(id int primary key);\", \"verdict\": 3, \"violations\": [...]}]", "approval_status": 1, ...}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/review/submit/:db_id [post]
+func Review(c *gin.Context) {
+	// get params
+	dbIDStr := c.Param(dbIDJSON)
+	if dbIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, dbIDJSON)
+		return
+	}
+	dbID, err := strconv.Atoi(dbIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// bind request
+	req := &reviewRequest{}
+	err = request.Bind(c, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	sqlText := req.SQLText
+	// init service
+	s := review.NewServiceWithDefault()
+	// review
+	err = s.Review(c.Request.Context(), dbID, sqlText)
+	if err != nil {
+		resp.ResponseNOK(c, msgreview.ErrReviewReview, dbID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgreview.DebugReviewReview, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgreview.InfoReviewReview, dbID, s.GetReviews()[constant.ZeroInt].GetVerdict())
+}
+
+// @Tags review
+// @Summary get review record by id
+// @Produce  application/json
+// @Param id path int true "review id"
+// @Success 200 {string} string "{"code": 204002, "message": "DAS-204002: review: get review record by id completed. id: 1", "data": {"reviews": [{"id": 1, "db_id": 1, "sql_text": "create table t01(id int primary key);", "verdict": 3, "approval_status": 1, ...}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/review/get/:id [get]
+func GetByID(c *gin.Context) {
+	// get params
+	idStr := c.Param(idJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, idJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := review.NewServiceWithDefault()
+	// get entity
+	err = s.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, review.ErrDataNotExists) {
+			resp.ResponseNOK(c, message.ErrDataNotExists, message.NewMessage(msgreview.ErrReviewGetByID, id, err.Error()).Error())
+			return
+		}
+		resp.ResponseNOK(c, msgreview.ErrReviewGetByID, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgreview.DebugReviewGetByID, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgreview.InfoReviewGetByID, id)
+}
+
+// @Tags review
+// @Summary approve the pending review record, the authenticated user is the approver and must be an admin or a dba
+// @Produce  application/json
+// @Param id path int true "review id"
+// @Success 200 {string} string "{"code": 204003, "message": "DAS-204003: review: approve review record completed. id: 1, account name: admin", "data": {"reviews": [{"id": 1, "approval_status": 2, "approver_id": 1, "approve_time": "2021-01-22T09:59:21.379851+08:00", ...}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/review/approve/:id [post]
+func Approve(c *gin.Context) {
+	// get params
+	idStr := c.Param(idJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, idJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// the approver is the authenticated user
+	accountName, ok := auth.RequireAccountName(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := review.NewServiceWithDefault()
+	// approve
+	err = s.Approve(c.Request.Context(), id, accountName)
+	if err != nil {
+		var fe *review.ForbiddenError
+		if errors.As(err, &fe) {
+			resp.ResponseNOK(c, msgauth.ErrAuthForbidden, message.NewMessage(msgreview.ErrReviewApprove, id, accountName, err.Error()).Error())
+			return
+		}
+		if errors.Is(err, review.ErrDataNotExists) {
+			resp.ResponseNOK(c, message.ErrDataNotExists, message.NewMessage(msgreview.ErrReviewApprove, id, accountName, err.Error()).Error())
+			return
+		}
+		resp.ResponseNOK(c, msgreview.ErrReviewApprove, id, accountName, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgreview.DebugReviewApprove, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgreview.InfoReviewApprove, id, accountName)
+}
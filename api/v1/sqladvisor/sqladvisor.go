@@ -3,105 +3,149 @@ package sqladvisor
 import (
 	"encoding/json"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/romberli/das/internal/app/sqladvisor"
 	"github.com/romberli/das/pkg/message"
 	msgadvisor "github.com/romberli/das/pkg/message/sqladvisor"
+	"github.com/romberli/das/pkg/request"
 	"github.com/romberli/das/pkg/resp"
 	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
 )
 
 const (
 	sqlTextJSON     = "sql_text"
+	sqlTextsJSON    = "sql_texts"
 	fingerprintJSON = "fingerprint"
 	sqlIDJSON       = "sql_id"
 	dbIDJSON        = "db_id"
 )
 
+// sqlTextRequest is the request body which contains a sql text
+type sqlTextRequest struct {
+	SQLText string `json:"sql_text" validate:"required,notblank"`
+}
+
+// sqlTextsRequest is the request body which contains multiple sql texts, at most 1000 sql texts are allowed
+type sqlTextsRequest struct {
+	SQLTexts []string `json:"sql_texts" validate:"required,min=1,max=1000,dive,required,notblank"`
+}
+
 // @Tags sqladvisor
 // @Summary get sql fingerprint
 // @Produce  application/json
-// @Success 200 {string} string "{"fingerprint": "select * from a","sql_text": "select * from a;"}"
-// @Router /api/v1/sqladvisor/fingerprint/ [get]
+// @Param sql_text query string true "sql text"
+// @Success 200 {string} string "{"code": 202001, "message": "DAS-202001: sqladvisor: get fingerprint completed. sql text: select * from a;, fingerprint: select * from a", "data": {"fingerprint": "select * from a", "sql_text": "select * from a;"}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/sqladvisor/fingerprint [get]
 func GetFingerprint(c *gin.Context) {
-	// get data
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
+	responseSQLTextValue(c, fingerprintJSON, (*sqladvisor.Service).GetFingerprint, msgadvisor.InfoSQLAdvisorGetFingerprint)
+}
+
+// @Tags sqladvisor
+// @Summary get sql id
+// @Produce  application/json
+// @Param sql_text query string true "sql text"
+// @Success 200 {string} string "{"code": 202002, "message": "DAS-202002: sqladvisor: get sql id completed. sql text: select * from a;, sql id: EE56B94E867DC9D5", "data": {"sql_id": "EE56B94E867DC9D5", "sql_text": "select * from a;"}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/sqladvisor/sql-id [get]
+func GetSQLID(c *gin.Context) {
+	responseSQLTextValue(c, sqlIDJSON, (*sqladvisor.Service).GetSQLID, msgadvisor.InfoSQLAdvisorGetSQLID)
+}
+
+// @Tags sqladvisor
+// @Summary get fingerprints of multiple sql texts
+// @Accept  application/json
+// @Produce  application/json
+// @Param sql_texts body []string true "sql texts"
+// @Success 200 {string} string "{"code": 202010, "message": "DAS-202010: sqladvisor: get sql infos completed. sql num: 1", "data": {"sql_infos": [{"sql_text": "select * from a;", "fingerprint": "select * from a", "sql_id": "EE56B94E867DC9D5", "statement_type": "select"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/sqladvisor/fingerprint [post]
+func BatchGetFingerprint(c *gin.Context) {
+	batchGetSQLInfos(c)
+}
+
+// @Tags sqladvisor
+// @Summary get sql ids of multiple sql texts
+// @Accept  application/json
+// @Produce  application/json
+// @Param sql_texts body []string true "sql texts"
+// @Success 200 {string} string "{"code": 202010, "message": "DAS-202010: sqladvisor: get sql infos completed. sql num: 1", "data": {"sql_infos": [{"sql_text": "select * from a;", "fingerprint": "select * from a", "sql_id": "EE56B94E867DC9D5", "statement_type": "select"}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/sqladvisor/sql-id [post]
+func BatchGetSQLID(c *gin.Context) {
+	batchGetSQLInfos(c)
+}
+
+// getSQLText gets the sql text from the query parameter,
+// for compatibility, it falls back to the json body if the query parameter is not specified
+func getSQLText(c *gin.Context) (string, error) {
+	sqlText, exists := c.GetQuery(sqlTextJSON)
+	if exists && strings.TrimSpace(sqlText) != constant.EmptyString {
+		return sqlText, nil
 	}
 
-	dataMap := make(map[string]string)
-	err = json.Unmarshal(data, &dataMap)
+	req := &sqlTextRequest{}
+	err := request.Bind(c, req)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
+		return constant.EmptyString, err
 	}
 
-	sqlText, exists := dataMap[sqlTextJSON]
-	if !exists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, sqlTextJSON)
+	return req.SQLText, nil
+}
+
+// responseSQLTextValue responses the sql text and the value of the key, such as the fingerprint or the sql id,
+// the value is got from the sql text with getValue, the response is the same as the old versions
+func responseSQLTextValue(c *gin.Context, key string, getValue func(s *sqladvisor.Service, sqlText string) string, code int) {
+	// get params
+	sqlText, err := getSQLText(c)
+	if err != nil {
+		request.ResponseNOK(c, err)
 		return
 	}
 	// init service
 	service := sqladvisor.NewServiceWithDefault()
-	// get fingerprint
-	fingerprint := service.GetFingerprint(sqlText)
-	respData := map[string]string{sqlTextJSON: sqlText, fingerprintJSON: fingerprint}
+	// get value
+	value := getValue(service, sqlText)
+	respData := map[string]string{sqlTextJSON: sqlText, key: value}
 	respMessage, err := json.Marshal(respData)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
 	}
 
-	resp.ResponseOK(c, string(respMessage), msgadvisor.InfoSQLAdvisorGetFingerprint, sqlTextJSON, fingerprint)
+	resp.ResponseOK(c, string(respMessage), code, sqlText, value)
 }
 
-// @Tags sqladvisor
-// @Summary get sql id
-// @Produce  application/json
-// @Success 200 {string} string "{"sql_id": "EE56B94E867DC9D5","sql_text": "select * from a;"}"
-// @Router /api/v1/sqladvisor/sql-id/ [get]
-func GetSQLID(c *gin.Context) {
-	// get data
-	data, err := c.GetRawData()
-	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
-		return
-	}
-
-	dataMap := make(map[string]string)
-	err = json.Unmarshal(data, &dataMap)
+// batchGetSQLInfos responses the sql infos of the sql texts in the json body
+func batchGetSQLInfos(c *gin.Context) {
+	// bind request
+	req := &sqlTextsRequest{}
+	err := request.Bind(c, req)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
-		return
-	}
-
-	sqlText, exists := dataMap[sqlTextJSON]
-	if !exists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, sqlTextJSON)
+		request.ResponseNOK(c, err)
 		return
 	}
+	sqlTexts := req.SQLTexts
 	// init service
 	service := sqladvisor.NewServiceWithDefault()
-	// get sql id
-	sqlID := service.GetSQLID(sqlText)
-	respData := map[string]string{sqlTextJSON: sqlText, sqlIDJSON: sqlID}
-	respMessage, err := json.Marshal(respData)
+	// get sql infos
+	service.ParseSQLInfos(sqlTexts)
+	// marshal service
+	jsonBytes, err := service.MarshalWithFields(sqladvisor.SQLInfosStruct)
 	if err != nil {
 		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
 		return
 	}
-
-	resp.ResponseOK(c, string(respMessage), msgadvisor.InfoSQLAdvisorGetSQLID, sqlTextJSON, sqlID)
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgadvisor.DebugSQLAdvisorGetSQLInfos, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgadvisor.InfoSQLAdvisorGetSQLInfos, len(sqlTexts))
 }
 
 // @Tags sqladvisor
 // @Summary get advice
 // @Produce  application/json
-// @Success 200 {string} string "{"code": 200, "data": [{"sql_text": "select * from t01", "advice": "xxx"}"
-// @Router /api/v1/sqladvisor/advise [post]
+// @Success 200 {string} string "{"code": 202003, "message": "DAS-202003: sqladvisor: advice completed. db id: 1, sql text: select * from t01 where id = 1, advice: xxx", "data": "xxx", "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/sqladvisor/advise/:db_id [post]
 func Advise(c *gin.Context) {
 	// get data
 	dbIDStr := c.Param(dbIDJSON)
@@ -115,31 +159,54 @@ func Advise(c *gin.Context) {
 		return
 	}
 
-	data, err := c.GetRawData()
+	// bind request
+	req := &sqlTextRequest{}
+	err = request.Bind(c, req)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		request.ResponseNOK(c, err)
 		return
 	}
-
-	dataMap := make(map[string]string)
-	err = json.Unmarshal(data, &dataMap)
+	sqlText := req.SQLText
+	// init service
+	service := sqladvisor.NewServiceWithDefault()
+	advice, err := service.Advise(c.Request.Context(), dbID, sqlText)
 	if err != nil {
-		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		resp.ResponseNOK(c, msgadvisor.ErrSQLAdvisorAdvice, dbID, sqlText, err.Error())
 		return
 	}
 
-	sqlText, exists := dataMap[sqlTextJSON]
-	if !exists {
-		resp.ResponseNOK(c, message.ErrFieldNotExists, sqlTextJSON)
+	resp.ResponseOK(c, advice, msgadvisor.InfoSQLAdvisorAdvice, dbID, sqlText, advice)
+}
+
+// @Tags sqladvisor
+// @Summary get slow query advices generated by the auto advisor
+// @Produce  application/json
+// @Param sql_id path string true "sql id"
+// @Success 200 {string} string "{"code": 202004, "message": "DAS-202004: sqladvisor: get slow query advice by sql id completed. sql id: EE56B94E867DC9D5", "data": {"slow_query_advices": [{"id": 1, "mysql_cluster_id": 1, "db_id": 1, "sql_id": "EE56B94E867DC9D5", "fingerprint": "select * from t01 where id = ?", "advice": "xxx", ...}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/sqladvisor/advice/:sql_id [get]
+func GetAdviceBySQLID(c *gin.Context) {
+	// get params
+	sqlID := c.Param(sqlIDJSON)
+	if sqlID == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, sqlIDJSON)
 		return
 	}
 	// init service
 	service := sqladvisor.NewServiceWithDefault()
-	advice, err := service.Advise(dbID, sqlText)
+	// get entities
+	err := service.GetAdviceBySQLID(c.Request.Context(), sqlID)
 	if err != nil {
-		resp.ResponseNOK(c, msgadvisor.ErrSQLAdvisorAdvice, dbID, sqlText, err.Error())
+		resp.ResponseNOK(c, msgadvisor.ErrSQLAdvisorGetAdviceBySQLID, sqlID, err.Error())
 		return
 	}
-
-	resp.ResponseOK(c, advice, msgadvisor.InfoSQLAdvisorAdvice, dbID, sqlText, advice)
+	// marshal service
+	jsonBytes, err := service.MarshalWithFields(sqladvisor.SlowQueryAdvicesStruct)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgadvisor.DebugSQLAdvisorGetAdviceBySQLID, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgadvisor.InfoSQLAdvisorGetAdviceBySQLID, sqlID)
 }
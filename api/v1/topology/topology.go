@@ -0,0 +1,126 @@
+package topology
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/internal/app/topology"
+	"github.com/romberli/das/pkg/message"
+	msgtopology "github.com/romberli/das/pkg/message/topology"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	idJSON       = "id"
+	nodeTypeJSON = "node_type"
+	depthJSON    = "depth"
+)
+
+// @Tags topology
+// @Summary get the graph around the app, database, cluster, server or monitor system
+// @Produce  application/json
+// @Param node_type path string true "node type: app, db, mysql_cluster, mysql_server, middleware_cluster, middleware_server or monitor_system"
+// @Param id path int true "entity id"
+// @Param depth query int false "max number of the edges between the entity and the other nodes, default is 2"
+// @Success 200 {string} string "{"code": 207001, "message": "DAS-207001: topology: get graph completed. node type: mysql_server, id: 1, depth: 2", "data": {"graphs": [{"nodes": [{"id": "mysql_server:1", "type": "mysql_server", "entity_id": 1, "name": "192.168.137.11:3306"}, ...], "edges": [{"source": "mysql_server:1", "target": "mysql_cluster:1", "type": "member_of"}, ...]}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/topology/graph/:node_type/:id [get]
+func GetGraph(c *gin.Context) {
+	// get params
+	nodeType := c.Param(nodeTypeJSON)
+	if nodeType == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, nodeTypeJSON)
+		return
+	}
+	idStr := c.Param(idJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, idJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	depth := topology.DefaultDepth
+	depthStr := c.Query(depthJSON)
+	if depthStr != constant.EmptyString {
+		depth, err = strconv.Atoi(depthStr)
+		if err != nil {
+			resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+			return
+		}
+	}
+	// init service
+	s := topology.NewServiceWithDefault()
+	// get graph
+	err = s.GetGraph(c.Request.Context(), nodeType, id, depth)
+	if err != nil {
+		responseNOK(c, err, msgtopology.ErrTopologyGetGraph, nodeType, id, depth, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalWithFields(topology.GraphsStruct)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgtopology.DebugTopologyGetGraph, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgtopology.InfoTopologyGetGraph, nodeType, id, depth)
+}
+
+// @Tags topology
+// @Summary get the apps, databases and clusters which are affected if the mysql server goes down
+// @Produce  application/json
+// @Param id path int true "mysql server id"
+// @Success 200 {string} string "{"code": 207002, "message": "DAS-207002: topology: get impact completed. mysql server id: 1", "data": {"impacts": [{"mysql_server": {"id": "mysql_server:1", ...}, "mysql_cluster": {...}, "middleware_cluster": null, "severity": "write_unavailable", "remaining_mysql_servers": [...], "dbs": [...], "apps": [...]}]}, "request_id": "0af7651916cd43dd8448eb211c80319c"}"
+// @Router /api/v1/topology/impact/mysql-server/:id [get]
+func GetImpact(c *gin.Context) {
+	// get params
+	idStr := c.Param(idJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, idJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := topology.NewServiceWithDefault()
+	// get impact
+	err = s.GetImpact(c.Request.Context(), id)
+	if err != nil {
+		responseNOK(c, err, msgtopology.ErrTopologyGetImpact, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalWithFields(topology.ImpactsStruct)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgtopology.DebugTopologyGetImpact, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgtopology.InfoTopologyGetImpact, id)
+}
+
+// responseNOK responds the not valid parameter error with 400 and the not existing entity error with 404,
+// the other errors are responded with the given code
+func responseNOK(c *gin.Context, err error, code int, values ...interface{}) {
+	switch {
+	case errors.Is(err, topology.ErrNotValidParameter):
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, message.NewMessage(code, values...).Error())
+	case errors.Is(err, topology.ErrDataNotExists):
+		resp.ResponseNOK(c, message.ErrDataNotExists, message.NewMessage(code, values...).Error())
+	default:
+		resp.ResponseNOK(c, code, values...)
+	}
+}
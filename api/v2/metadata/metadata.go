@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+
+	depaudit "github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	// BasePath is the path of the resource oriented metadata api
+	BasePath = "/api/v2/metadata"
+
+	idJSON        = "id"
+	relatedIDJSON = "related_id"
+	// pageStruct is the pagination information of the metadata services
+	pageStruct = "Page"
+
+	locationHeader = "Location"
+)
+
+// service is the common part of the metadata services which the resource oriented api uses
+type service interface {
+	// GetByQuery gets the entities which match the query and the pagination information
+	GetByQuery(ctx context.Context, query *filter.Query) error
+	// GetByID gets the entity of the given id
+	GetByID(ctx context.Context, id int) error
+	// Create creates an entity with the fields
+	Create(ctx context.Context, fields map[string]interface{}) error
+	// Update updates the fields of the entity of the given id
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
+	// Delete deletes the entity of the given id
+	Delete(ctx context.Context, id int) error
+	// DeleteCascade deletes the entity of the given id and the metadata which reference it
+	DeleteCascade(ctx context.Context, id int) error
+	// Undelete restores the deleted entity of the given id
+	Undelete(ctx context.Context, id int) error
+	// SetAuditor sets the auditor which records the changes made by the service
+	SetAuditor(auditor depaudit.Auditor)
+	// SetIfMatch sets the entity tags which the entity to update or delete must match
+	SetIfMatch(ifMatch string)
+}
+
+// entity is the common part of the metadata entities
+type entity interface {
+	// Identity returns the identity
+	Identity() int
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+	// MarshalJSON marshals the entity to json bytes
+	MarshalJSON() ([]byte, error)
+}
+
+// listResponse is the data of the responses which contain a list of the entities
+type listResponse struct {
+	Items []entity     `json:"items"`
+	Page  *filter.Page `json:"page,omitempty"`
+}
+
+// getID returns the integer value of the path parameter
+func getID(c *gin.Context, param string) (int, bool) {
+	idStr := c.Param(param)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, param)
+		return constant.ZeroInt, false
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return constant.ZeroInt, false
+	}
+
+	return id, true
+}
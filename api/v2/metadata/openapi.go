@@ -0,0 +1,292 @@
+package metadata
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/api/shared"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/message"
+	msgmeta "github.com/romberli/das/pkg/message/metadata"
+	"github.com/romberli/das/pkg/openapi"
+	"github.com/romberli/das/pkg/request"
+	"github.com/romberli/das/pkg/resp"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+const (
+	openAPITitle       = "das metadata api"
+	openAPIVersion     = "2.0.0"
+	openAPIDescription = "the resource oriented api of the metadata, it shares the services with the v1 api"
+
+	jsonContentType = "application/json"
+
+	pageSchema            = "Page"
+	validationErrorSchema = "ValidationError"
+	entityTypeSuffix      = "Info"
+	requestSchemaSuffix   = "Request"
+	patchSchemaSuffix     = "Patch"
+	pathSeparator         = "/"
+	nameSeparator         = "-"
+)
+
+// operationVerbs are the verbs of the operation ids
+var operationVerbs = map[operation]string{
+	operationList:          "list",
+	operationGet:           "get",
+	operationCreate:        "create",
+	operationReplace:       "replace",
+	operationPatch:         "patch",
+	operationDelete:        "delete",
+	operationUndelete:      "undelete",
+	operationListRelated:   "list",
+	operationAddRelated:    "add",
+	operationDeleteRelated: "remove",
+}
+
+// schemaRefs are the references of the schemas of a resource
+type schemaRefs struct {
+	entity  *openapi.Schema
+	request *openapi.Schema
+	patch   *openapi.Schema
+}
+
+// @Tags metadata
+// @Summary get the openapi 3 specification of the v2 metadata api
+// @Produce  application/json
+// @Success 200 {string} string "{"openapi": "3.0.3", "info": {...}, "paths": {...}, "components": {...}}"
+// @Router /api/v2/openapi.json [get]
+func GetOpenAPI(c *gin.Context) {
+	jsonBytes, err := json.Marshal(NewOpenAPI())
+	if err != nil {
+		resp.ResponseNOK(c, msgmeta.ErrMetadataGetOpenAPI, err.Error())
+		return
+	}
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataGetOpenAPI, string(jsonBytes)).Error())
+	resp.ResponseRaw(c, jsonContentType, jsonBytes, msgmeta.InfoMetadataGetOpenAPI)
+}
+
+// NewOpenAPI returns the openapi 3 specification of the v2 metadata api, it is generated from the routes,
+// the schemas of the requests and the entities
+func NewOpenAPI() *openapi.Document {
+	doc := openapi.NewDocument(openAPITitle, openAPIVersion)
+	doc.Info.Description = openAPIDescription
+
+	page := doc.AddSchema(pageSchema, filter.Page{})
+	validationError := doc.AddSchema(validationErrorSchema, request.ValidationError{})
+	refs := make(map[*Resource]*schemaRefs, len(Resources))
+	for _, r := range Resources {
+		name := strings.TrimSuffix(reflect.Indirect(reflect.ValueOf(r.entity)).Type().Name(), entityTypeSuffix)
+		refs[r] = &schemaRefs{
+			entity:  doc.AddSchema(name, r.entity),
+			request: doc.AddSchema(name+requestSchemaSuffix, r.newRequest()),
+			patch:   doc.AddSchema(name+patchSchemaSuffix, r.newRequest()),
+		}
+		// all the fields of the patch are optional
+		doc.Components.Schemas[name+patchSchemaSuffix].Required = nil
+	}
+
+	for _, route := range GetRoutes() {
+		doc.AddOperation(route.Method, toOpenAPIPath(BasePath+route.Path), newOperation(route, refs[route.resource], page, validationError))
+	}
+
+	return doc
+}
+
+// newOperation returns the openapi operation of the route
+func newOperation(route *Route, refs *schemaRefs, page, validationError *openapi.Schema) *openapi.Operation {
+	op := &openapi.Operation{
+		Tags:        []string{route.resource.Tag},
+		Summary:     route.Summary,
+		OperationID: getOperationID(route),
+		Responses:   make(map[string]*openapi.Response),
+	}
+	if route.relation != nil {
+		op.Tags = []string{route.relation.Resource.Tag}
+	}
+	// parameters
+	for _, segment := range strings.Split(route.Path, pathSeparator) {
+		if strings.HasPrefix(segment, ":") {
+			op.Parameters = append(op.Parameters, &openapi.Parameter{
+				Name:     strings.TrimPrefix(segment, ":"),
+				In:       openapi.InPath,
+				Required: true,
+				Schema:   &openapi.Schema{Type: openapi.TypeInteger},
+			})
+		}
+	}
+	switch route.operation {
+	case operationList, operationListRelated:
+		op.Parameters = append(op.Parameters, getQueryParameters()...)
+	case operationReplace, operationPatch:
+		op.Parameters = append(op.Parameters, getIfMatchParameter())
+	case operationDelete:
+		op.Parameters = append(op.Parameters,
+			&openapi.Parameter{
+				Name:        shared.CascadeJSON,
+				In:          openapi.InQuery,
+				Description: "delete the metadata which reference the resource as well, default is false",
+				Schema:      &openapi.Schema{Type: openapi.TypeBoolean},
+			},
+			getIfMatchParameter(),
+		)
+	}
+	// request body
+	switch route.operation {
+	case operationCreate:
+		op.RequestBody = newRequestBody(jsonContentType, refs.request, "the optional fields could be omitted")
+	case operationReplace:
+		op.RequestBody = newRequestBody(jsonContentType, refs.request, "all the fields must be specified, including the optional ones")
+	case operationPatch:
+		op.RequestBody = newRequestBody(request.MergePatchContentType, refs.patch, "json merge patch, see RFC 7396, null resets the optional fields to the default values")
+		op.RequestBody.Content[jsonContentType] = op.RequestBody.Content[request.MergePatchContentType]
+	}
+	// responses
+	list := openapi.NewObjectSchema(map[string]*openapi.Schema{"items": openapi.NewArraySchema(refs.entity), "page": page})
+	switch route.operation {
+	case operationList, operationListRelated, operationAddRelated, operationDeleteRelated:
+		op.Responses[strconv.Itoa(http.StatusOK)] = newResponse("the resources", list)
+	case operationCreate:
+		created := newResponse("the created resource", refs.entity)
+		created.Headers = map[string]*openapi.Header{
+			locationHeader:    {Description: "the path of the created resource", Schema: &openapi.Schema{Type: openapi.TypeString}},
+			shared.ETagHeader: getETagHeader(),
+		}
+		op.Responses[strconv.Itoa(http.StatusCreated)] = created
+	default:
+		ok := newResponse("the resource", refs.entity)
+		ok.Headers = map[string]*openapi.Header{shared.ETagHeader: getETagHeader()}
+		op.Responses[strconv.Itoa(http.StatusOK)] = ok
+	}
+	op.Responses[strconv.Itoa(http.StatusBadRequest)] = newResponse("the request is not valid, the data contains the field errors if any", validationError)
+	op.Responses[strconv.Itoa(http.StatusUnauthorized)] = newResponse("the request is not authenticated", nil)
+	op.Responses[strconv.Itoa(http.StatusForbidden)] = newResponse("the user is not allowed to access the resource", nil)
+	if route.operation != operationList && route.operation != operationCreate {
+		op.Responses[strconv.Itoa(http.StatusNotFound)] = newResponse("the resource does not exist", nil)
+	}
+	switch route.operation {
+	case operationCreate:
+		op.Responses[strconv.Itoa(http.StatusConflict)] = newResponse("the resource already exists", nil)
+	case operationReplace, operationPatch:
+		op.Responses[strconv.Itoa(http.StatusConflict)] = newResponse("the resource was modified by others, or the unique key already exists", nil)
+		op.Responses[strconv.Itoa(http.StatusPreconditionFailed)] = newResponse("the resource does not match the If-Match header", nil)
+	case operationDelete:
+		op.Responses[strconv.Itoa(http.StatusConflict)] = newResponse("the resource was modified by others, or it is still referenced, the data contains the referencing resources", nil)
+		op.Responses[strconv.Itoa(http.StatusPreconditionFailed)] = newResponse("the resource does not match the If-Match header", nil)
+	case operationUndelete:
+		op.Responses[strconv.Itoa(http.StatusConflict)] = newResponse("the resource references the deleted resources, the data contains them, or the unique key already exists", nil)
+	}
+	op.Responses[strconv.Itoa(http.StatusInternalServerError)] = newResponse("internal error", nil)
+
+	return op
+}
+
+// newRequestBody returns a required request body of given content type and schema
+func newRequestBody(contentType string, schema *openapi.Schema, description string) *openapi.RequestBody {
+	return &openapi.RequestBody{
+		Description: description,
+		Required:    true,
+		Content:     map[string]*openapi.MediaType{contentType: {Schema: schema}},
+	}
+}
+
+// newResponse returns a response of which the data of the json envelope is of given schema, nil means the data is null
+func newResponse(description string, data *openapi.Schema) *openapi.Response {
+	if data == nil {
+		data = &openapi.Schema{Type: openapi.TypeObject}
+	}
+	envelope := openapi.NewObjectSchema(map[string]*openapi.Schema{
+		"code":       {Type: openapi.TypeInteger, Description: "the code of the message"},
+		"message":    {Type: openapi.TypeString},
+		"data":       data,
+		"request_id": {Type: openapi.TypeString, Description: "the value of the " + tracing.RequestIDHeader + " header, it is generated by the server if the header is not specified"},
+	})
+
+	return &openapi.Response{
+		Description: description,
+		Content:     map[string]*openapi.MediaType{jsonContentType: {Schema: envelope}},
+	}
+}
+
+// getQueryParameters returns the query parameters which filter, sort and paginate the resources
+func getQueryParameters() []*openapi.Parameter {
+	return []*openapi.Parameter{
+		{
+			Name:        filter.SortParam,
+			In:          openapi.InQuery,
+			Description: "sort by the fields, such as -id,create_time, \"-\" means descending, the other query parameters filter by the fields, such as field=value, field=v1,v2, field_like=value, field_ge=value and field_le=value",
+			Schema:      &openapi.Schema{Type: openapi.TypeString},
+		},
+		{
+			Name:        filter.LimitParam,
+			In:          openapi.InQuery,
+			Description: "max number of the returned resources, 0 means no limit",
+			Schema:      &openapi.Schema{Type: openapi.TypeInteger},
+		},
+		{
+			Name:        filter.OffsetParam,
+			In:          openapi.InQuery,
+			Description: "number of the skipped resources",
+			Schema:      &openapi.Schema{Type: openapi.TypeInteger},
+		},
+		{
+			Name:        filter.CursorParam,
+			In:          openapi.InQuery,
+			Description: "id of the last resource of the previous page, could not be used with offset",
+			Schema:      &openapi.Schema{Type: openapi.TypeInteger},
+		},
+	}
+}
+
+// getIfMatchParameter returns the If-Match header parameter
+func getIfMatchParameter() *openapi.Parameter {
+	return &openapi.Parameter{
+		Name:        shared.IfMatchHeader,
+		In:          openapi.InHeader,
+		Description: "the etag which the resource must match, it responses 412 if the resource was modified after the etag was got",
+		Schema:      &openapi.Schema{Type: openapi.TypeString},
+	}
+}
+
+// getETagHeader returns the ETag response header
+func getETagHeader() *openapi.Header {
+	return &openapi.Header{
+		Description: "the version of the resource, specify it in the If-Match header when updating or deleting the resource",
+		Schema:      &openapi.Schema{Type: openapi.TypeString},
+	}
+}
+
+// getOperationID returns the unique operation id of the route, such as listApps and addAppsDbs
+func getOperationID(route *Route) string {
+	names := []string{route.resource.Name}
+	if route.relation != nil {
+		names = []string{route.relation.Resource.Name, route.relation.Related.Name}
+	}
+
+	id := operationVerbs[route.operation]
+	for _, name := range names {
+		for _, word := range strings.Split(name, nameSeparator) {
+			id += strings.Title(word)
+		}
+	}
+
+	return id
+}
+
+// toOpenAPIPath converts the path parameters of gin, such as :id, to the openapi ones, such as {id}
+func toOpenAPIPath(path string) string {
+	segments := strings.Split(path, pathSeparator)
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+
+	return strings.Join(segments, pathSeparator)
+}
@@ -0,0 +1,234 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/api/shared"
+	"github.com/romberli/das/internal/app/metadata"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/message"
+	msgmeta "github.com/romberli/das/pkg/message/metadata"
+	"github.com/romberli/das/pkg/resp"
+)
+
+// Relation is the resources which are related to a resource, such as the dbs of an app
+type Relation struct {
+	// Resource is the resource which the related resources belong to
+	Resource *Resource
+	// Related is the related resources
+	Related *Resource
+	// foreignKey is the field of the related resources which references the resource, such as cluster_id,
+	// it is empty if the relation is stored separately
+	foreignKey string
+	// getIDList returns the identities of the related resources, it is used when there is no foreign key
+	getIDList func(ctx context.Context, id int) ([]int, error)
+	// add adds the related resource to the resource, nil means the relation could not be changed by the api
+	add func(ctx context.Context, auditor depaudit.Auditor, id, relatedID int) error
+	// delete deletes the related resource from the resource
+	delete func(ctx context.Context, auditor depaudit.Auditor, id, relatedID int) error
+}
+
+// Path returns the path of the related resources
+func (rel *Relation) Path() string {
+	return fmt.Sprintf("%s/%s", rel.Resource.ItemPath(), rel.Related.Name)
+}
+
+// ItemPath returns the path of a related resource
+func (rel *Relation) ItemPath() string {
+	return fmt.Sprintf("%s/:%s", rel.Path(), relatedIDJSON)
+}
+
+// List responses the related resources of the resource of the id, they could be filtered, sorted and paginated as well
+func (rel *Relation) List(c *gin.Context) {
+	// get params
+	id, ok := getID(c, idJSON)
+	if !ok {
+		return
+	}
+	rel.responseRelated(c, id, msgmeta.DebugMetadataGetRelatedResources, msgmeta.InfoMetadataGetRelatedResources)
+}
+
+// Add adds the related resource to the resource of the id and responses the related resources
+func (rel *Relation) Add(c *gin.Context) {
+	id, relatedID, ok := getIDs(c)
+	if !ok {
+		return
+	}
+	err := rel.add(c.Request.Context(), shared.NewAuditor(c), id, relatedID)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataAddRelatedResource, rel.Related.Name, rel.Resource.Name, id, relatedID, err.Error())
+		return
+	}
+	log.Info(message.NewMessage(msgmeta.InfoMetadataAddRelatedResource, rel.Related.Name, rel.Resource.Name, id, relatedID).Error())
+	rel.responseRelated(c, id, msgmeta.DebugMetadataAddRelatedResource, msgmeta.InfoMetadataGetRelatedResources)
+}
+
+// Delete deletes the related resource from the resource of the id and responses the related resources
+func (rel *Relation) Delete(c *gin.Context) {
+	id, relatedID, ok := getIDs(c)
+	if !ok {
+		return
+	}
+	err := rel.delete(c.Request.Context(), shared.NewAuditor(c), id, relatedID)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataDeleteRelatedResource, rel.Related.Name, rel.Resource.Name, id, relatedID, err.Error())
+		return
+	}
+	log.Info(message.NewMessage(msgmeta.InfoMetadataDeleteRelatedResource, rel.Related.Name, rel.Resource.Name, id, relatedID).Error())
+	rel.responseRelated(c, id, msgmeta.DebugMetadataDeleteRelatedResource, msgmeta.InfoMetadataGetRelatedResources)
+}
+
+// responseRelated responses the related resources of the resource of the id
+func (rel *Relation) responseRelated(c *gin.Context, id, debugCode, infoCode int) {
+	// the resource must exist
+	err := rel.Resource.newService().GetByID(c.Request.Context(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetRelatedResources, rel.Related.Name, rel.Resource.Name, id, err.Error())
+		return
+	}
+	// get the related resources
+	data := &listResponse{Items: []entity{}}
+	values, err := rel.getQueryValues(c.Request.Context(), c.Request.URL.Query(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetRelatedResources, rel.Related.Name, rel.Resource.Name, id, err.Error())
+		return
+	}
+	if values != nil {
+		query, err := filter.NewQueryWithValues(values)
+		if err != nil {
+			resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+			return
+		}
+		s := rel.Related.newService()
+		err = s.GetByQuery(c.Request.Context(), query)
+		if err != nil {
+			shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetRelatedResources, rel.Related.Name, rel.Resource.Name, id, err.Error())
+			return
+		}
+		data.Items = rel.Related.getEntities(s)
+		data.Page = rel.Related.getPage(s)
+	}
+	// marshal entities
+	jsonBytes, err := json.Marshal(data)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(debugCode, rel.Related.Name, rel.Resource.Name, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, infoCode, rel.Related.Name, rel.Resource.Name, id)
+}
+
+// getQueryValues returns the query values which filter the related resources of the resource of the id,
+// it returns nil if there is no related resource
+func (rel *Relation) getQueryValues(ctx context.Context, values url.Values, id int) (url.Values, error) {
+	if rel.foreignKey != constant.EmptyString {
+		values.Set(rel.foreignKey, strconv.Itoa(id))
+		return values, nil
+	}
+
+	idList, err := rel.getIDList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(idList) == constant.ZeroInt {
+		return nil, nil
+	}
+	ids := make([]string, len(idList))
+	for i, relatedID := range idList {
+		ids[i] = strconv.Itoa(relatedID)
+	}
+	values.Set(idJSON, strings.Join(ids, constant.CommaString))
+
+	return values, nil
+}
+
+// getIDs returns the id and the related id of the path parameters
+func getIDs(c *gin.Context) (int, int, bool) {
+	id, ok := getID(c, idJSON)
+	if !ok {
+		return constant.ZeroInt, constant.ZeroInt, false
+	}
+	relatedID, ok := getID(c, relatedIDJSON)
+	if !ok {
+		return constant.ZeroInt, constant.ZeroInt, false
+	}
+
+	return id, relatedID, true
+}
+
+var (
+	AppDBs = &Relation{
+		Resource: Apps,
+		Related:  DBs,
+		getIDList: func(ctx context.Context, id int) ([]int, error) {
+			s := metadata.NewAppServiceWithDefault()
+			err := s.GetDBIDList(ctx, id)
+
+			return s.DBIDList, err
+		},
+		add: func(ctx context.Context, auditor depaudit.Auditor, id, relatedID int) error {
+			s := metadata.NewAppServiceWithDefault()
+			s.SetAuditor(auditor)
+
+			return s.AddDB(ctx, id, relatedID)
+		},
+		delete: func(ctx context.Context, auditor depaudit.Auditor, id, relatedID int) error {
+			s := metadata.NewAppServiceWithDefault()
+			s.SetAuditor(auditor)
+
+			return s.DeleteDB(ctx, id, relatedID)
+		},
+	}
+	DBApps = &Relation{
+		Resource: DBs,
+		Related:  Apps,
+		getIDList: func(ctx context.Context, id int) ([]int, error) {
+			s := metadata.NewDBServiceWithDefault()
+			err := s.GetAppIDList(ctx, id)
+
+			return s.AppIDList, err
+		},
+		add: func(ctx context.Context, auditor depaudit.Auditor, id, relatedID int) error {
+			s := metadata.NewDBServiceWithDefault()
+			s.SetAuditor(auditor)
+
+			return s.AddApp(ctx, id, relatedID)
+		},
+		delete: func(ctx context.Context, auditor depaudit.Auditor, id, relatedID int) error {
+			s := metadata.NewDBServiceWithDefault()
+			s.SetAuditor(auditor)
+
+			return s.DeleteApp(ctx, id, relatedID)
+		},
+	}
+	EnvDBs = &Relation{
+		Resource:   Envs,
+		Related:    DBs,
+		foreignKey: "env_id",
+	}
+	MiddlewareClusterServers = &Relation{
+		Resource:   MiddlewareClusters,
+		Related:    MiddlewareServers,
+		foreignKey: "cluster_id",
+	}
+	MySQLClusterServers = &Relation{
+		Resource:   MySQLClusters,
+		Related:    MySQLServers,
+		foreignKey: "cluster_id",
+	}
+
+	// Relations are all the relations of the metadata
+	Relations = []*Relation{AppDBs, DBApps, EnvDBs, MiddlewareClusterServers, MySQLClusterServers}
+)
@@ -0,0 +1,356 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/api/shared"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/message"
+	msgmeta "github.com/romberli/das/pkg/message/metadata"
+	"github.com/romberli/das/pkg/request"
+	"github.com/romberli/das/pkg/resp"
+)
+
+// Resource is a kind of the metadata which is accessed by the resource oriented api,
+// all the resources share the services with the v1 api
+type Resource struct {
+	// Name is the plural name of the resource in the path, such as apps
+	Name string
+	// Tag groups the operations of the resource in the openapi specification
+	Tag string
+	// entitiesStruct is the field of the service which contains the entities
+	entitiesStruct string
+	// entity is used to generate the schema of the resource
+	entity interface{}
+	// newRequest returns a new request which creates or replaces the resource,
+	// the fields of the request must be pointers and the names of them must be the same as the fields of the entity
+	newRequest func() interface{}
+	// newService returns a new service of the resource
+	newService func() service
+}
+
+// Path returns the path of the resource collection
+func (r *Resource) Path() string {
+	return "/" + r.Name
+}
+
+// ItemPath returns the path of a resource
+func (r *Resource) ItemPath() string {
+	return fmt.Sprintf("/%s/:%s", r.Name, idJSON)
+}
+
+// List responses the resources which match the filters, sorted and paginated
+func (r *Resource) List(c *gin.Context) {
+	// get params
+	query, err := filter.NewQueryWithValues(c.Request.URL.Query())
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidQueryParameter, err.Error())
+		return
+	}
+	// init service
+	s := r.newService()
+	// get entities
+	err = s.GetByQuery(c.Request.Context(), query)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetResources, r.Name, err.Error())
+		return
+	}
+	// marshal entities
+	jsonBytes, err := json.Marshal(&listResponse{Items: r.getEntities(s), Page: r.getPage(s)})
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataGetResources, r.Name, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgmeta.InfoMetadataGetResources, r.Name)
+}
+
+// Get responses the resource of the id with the ETag header
+func (r *Resource) Get(c *gin.Context) {
+	// get params
+	id, ok := getID(c, idJSON)
+	if !ok {
+		return
+	}
+	// init service
+	s := r.newService()
+	// get entity
+	err := s.GetByID(c.Request.Context(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataGetResource, r.Name, id, err.Error())
+		return
+	}
+	// response
+	r.responseEntity(c, s, http.StatusOK, msgmeta.DebugMetadataGetResource, msgmeta.InfoMetadataGetResource, id)
+}
+
+// Create creates a resource and responses it with 201 and the Location header
+func (r *Resource) Create(c *gin.Context) {
+	// bind request
+	fields, err := request.BindFields(c, r.newRequest())
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	// init service
+	s := r.newService()
+	s.SetAuditor(shared.NewAuditor(c))
+	// create entity
+	err = s.Create(c.Request.Context(), fields)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataCreateResource, r.Name, err.Error())
+		return
+	}
+	entities := r.getEntities(s)
+	if len(entities) > constant.ZeroInt {
+		c.Header(locationHeader, fmt.Sprintf("%s/%s/%d", BasePath, r.Name, entities[constant.ZeroInt].Identity()))
+	}
+	// response
+	jsonBytes, err := r.marshalEntity(c, s)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgmeta.DebugMetadataCreateResource, r.Name, jsonStr).Error())
+	resp.ResponseOKWithStatus(c, http.StatusCreated, jsonStr, msgmeta.InfoMetadataCreateResource, r.Name)
+}
+
+// Replace replaces all the fields of the resource of the id, the optional fields must be specified as well
+func (r *Resource) Replace(c *gin.Context) {
+	// get params
+	id, ok := getID(c, idJSON)
+	if !ok {
+		return
+	}
+	// bind request
+	req := r.newRequest()
+	err := request.Bind(c, req)
+	if err == nil {
+		err = request.RequireAllFields(req)
+	}
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	// init service
+	s := r.newService()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// update entity
+	err = s.Update(c.Request.Context(), id, request.GetFields(req))
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataReplaceResource, r.Name, id, err.Error())
+		return
+	}
+	// response
+	r.responseEntity(c, s, http.StatusOK, msgmeta.DebugMetadataReplaceResource, msgmeta.InfoMetadataReplaceResource, id)
+}
+
+// Patch applies the json merge patch to the resource of the id, see RFC 7396,
+// the patched resource must be valid as the request which creates the resource, so that the required fields could not be removed by null,
+// the optional fields which are removed by null are reset to the zero values, and only the changed fields are updated
+func (r *Resource) Patch(c *gin.Context) {
+	// get params
+	id, ok := getID(c, idJSON)
+	if !ok {
+		return
+	}
+	patch, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	// init service
+	s := r.newService()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// get entity
+	err = s.GetByID(c.Request.Context(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataPatchResource, r.Name, id, err.Error())
+		return
+	}
+	current, err := r.getRequestDocument(r.getEntities(s)[constant.ZeroInt])
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// apply patch
+	patched, err := request.MergePatch(current, patch)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	req := r.newRequest()
+	err = request.Unmarshal(patched, req)
+	if err != nil {
+		request.ResponseNOK(c, err)
+		return
+	}
+	currentReq := r.newRequest()
+	err = json.Unmarshal(current, currentReq)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+	// update the changed fields
+	fields := getPatchedFields(currentReq, req)
+	if len(fields) > constant.ZeroInt {
+		err = s.Update(c.Request.Context(), id, fields)
+		if err != nil {
+			shared.ResponseNOK(c, err, msgmeta.ErrMetadataPatchResource, r.Name, id, err.Error())
+			return
+		}
+	}
+	// response
+	r.responseEntity(c, s, http.StatusOK, msgmeta.DebugMetadataPatchResource, msgmeta.InfoMetadataPatchResource, id)
+}
+
+// Delete deletes the resource of the id, the metadata which reference it are deleted as well if cascade is true
+func (r *Resource) Delete(c *gin.Context) {
+	// get params
+	id, ok := getID(c, idJSON)
+	if !ok {
+		return
+	}
+	cascade, err := shared.GetCascade(c)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := r.newService()
+	s.SetAuditor(shared.NewAuditor(c))
+	s.SetIfMatch(c.GetHeader(shared.IfMatchHeader))
+	// delete entity
+	if cascade {
+		err = s.DeleteCascade(c.Request.Context(), id)
+	} else {
+		err = s.Delete(c.Request.Context(), id)
+	}
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataDeleteResource, r.Name, id, err.Error())
+		return
+	}
+	// response
+	r.responseEntity(c, s, http.StatusOK, msgmeta.DebugMetadataDeleteResource, msgmeta.InfoMetadataDeleteResource, id)
+}
+
+// Undelete restores the deleted resource of the id
+func (r *Resource) Undelete(c *gin.Context) {
+	// get params
+	id, ok := getID(c, idJSON)
+	if !ok {
+		return
+	}
+	// init service
+	s := r.newService()
+	s.SetAuditor(shared.NewAuditor(c))
+	// undelete entity
+	err := s.Undelete(c.Request.Context(), id)
+	if err != nil {
+		shared.ResponseNOK(c, err, msgmeta.ErrMetadataUndeleteResource, r.Name, id, err.Error())
+		return
+	}
+	// response
+	r.responseEntity(c, s, http.StatusOK, msgmeta.DebugMetadataUndeleteResource, msgmeta.InfoMetadataUndeleteResource, id)
+}
+
+// getEntities returns the entities of the service
+func (r *Resource) getEntities(s service) []entity {
+	val := reflect.Indirect(reflect.ValueOf(s)).FieldByName(r.entitiesStruct)
+	entities := make([]entity, val.Len())
+	for i := range entities {
+		entities[i] = val.Index(i).Interface().(entity)
+	}
+
+	return entities
+}
+
+// getPage returns the pagination information of the service
+func (r *Resource) getPage(s service) *filter.Page {
+	page, _ := reflect.Indirect(reflect.ValueOf(s)).FieldByName(pageStruct).Interface().(*filter.Page)
+
+	return page
+}
+
+// marshalEntity marshals the first entity of the service and sets the ETag header, it marshals null if there is no entity
+func (r *Resource) marshalEntity(c *gin.Context, s service) ([]byte, error) {
+	entities := r.getEntities(s)
+	if len(entities) == constant.ZeroInt {
+		return []byte("null"), nil
+	}
+	shared.SetETag(c, entities[constant.ZeroInt].GetLastUpdateTime())
+
+	return entities[constant.ZeroInt].MarshalJSON()
+}
+
+// responseEntity responses the first entity of the service with given status
+func (r *Resource) responseEntity(c *gin.Context, s service, status, debugCode, infoCode, id int) {
+	jsonBytes, err := r.marshalEntity(c, s)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(debugCode, r.Name, jsonStr).Error())
+	resp.ResponseOKWithStatus(c, status, jsonStr, infoCode, r.Name, id)
+}
+
+// getRequestDocument returns the json document of the entity which only contains the fields of the request
+func (r *Resource) getRequestDocument(e entity) ([]byte, error) {
+	jsonBytes, err := e.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	doc := make(map[string]json.RawMessage)
+	err = json.Unmarshal(jsonBytes, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	for _, name := range request.GetJSONFields(r.newRequest()) {
+		value, ok := doc[name]
+		if ok {
+			fields[name] = value
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// getPatchedFields returns the fields of which the values are changed by the patch,
+// the fields which are removed by the patch are reset to the zero values
+func getPatchedFields(current, patched interface{}) map[string]interface{} {
+	currentVal := reflect.Indirect(reflect.ValueOf(current))
+	patchedVal := reflect.Indirect(reflect.ValueOf(patched))
+	fields := make(map[string]interface{})
+	for i := 0; i < patchedVal.NumField(); i++ {
+		currentField, patchedField := currentVal.Field(i), patchedVal.Field(i)
+		if currentField.Kind() != reflect.Ptr {
+			continue
+		}
+		name := patchedVal.Type().Field(i).Name
+		switch {
+		case patchedField.IsNil():
+			if !currentField.IsNil() && !currentField.Elem().IsZero() {
+				fields[name] = reflect.Zero(currentField.Type().Elem()).Interface()
+			}
+		case currentField.IsNil() || !reflect.DeepEqual(currentField.Elem().Interface(), patchedField.Elem().Interface()):
+			fields[name] = patchedField.Elem().Interface()
+		}
+	}
+
+	return fields
+}
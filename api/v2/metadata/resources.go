@@ -0,0 +1,176 @@
+package metadata
+
+import (
+	"github.com/romberli/das/internal/app/metadata"
+)
+
+// envRequest is the request body of creating or replacing an environment
+type envRequest struct {
+	EnvName *string `json:"env_name" validate:"required,min=1,max=100"`
+}
+
+// userRequest is the request body of creating or replacing a user, the role is one of 1(admin), 2(dba) and 3(developer)
+type userRequest struct {
+	UserName       *string `json:"user_name" validate:"required,min=1,max=100"`
+	DepartmentName *string `json:"department_name" validate:"required,min=1,max=100"`
+	EmployeeID     *string `json:"employee_id" validate:"omitempty,max=100"`
+	AccountName    *string `json:"account_name" validate:"required,min=1,max=100"`
+	Email          *string `json:"email" validate:"required,email,max=100"`
+	Telephone      *string `json:"telephone" validate:"omitempty,max=100"`
+	Mobile         *string `json:"mobile" validate:"omitempty,max=100"`
+	Role           *int    `json:"role" validate:"required,oneof=1 2 3"`
+}
+
+// appRequest is the request body of creating or replacing an app, the level is one of 1(A), 2(B) and 3(C)
+type appRequest struct {
+	AppName *string `json:"app_name" validate:"required,min=1,max=100"`
+	Level   *int    `json:"level" validate:"required,oneof=1 2 3"`
+	OwnerID *int    `json:"owner_id" validate:"omitempty,gte=0"`
+}
+
+// dbRequest is the request body of creating or replacing a db, the cluster type is one of 1(single) and 2(sharding)
+type dbRequest struct {
+	DBName      *string `json:"db_name" validate:"required,min=1,max=100"`
+	ClusterID   *int    `json:"cluster_id" validate:"required,gt=0"`
+	ClusterType *int    `json:"cluster_type" validate:"required,oneof=1 2"`
+	OwnerID     *int    `json:"owner_id" validate:"omitempty,gte=0"`
+	EnvID       *int    `json:"env_id" validate:"required,gt=0"`
+}
+
+// monitorSystemRequest is the request body of creating or replacing a monitor system,
+// the system type is one of 1(pmm1.x) and 2(pmm2.x)
+type monitorSystemRequest struct {
+	MonitorSystemName        *string `json:"system_name" validate:"required,min=1,max=100"`
+	MonitorSystemType        *int    `json:"system_type" validate:"required,oneof=1 2"`
+	MonitorSystemHostIP      *string `json:"host_ip" validate:"required,ip"`
+	MonitorSystemPortNum     *int    `json:"port_num" validate:"required,min=1,max=65535"`
+	MonitorSystemPortNumSlow *int    `json:"port_num_slow" validate:"required,min=1,max=65535"`
+	BaseURL                  *string `json:"base_url" validate:"required,min=1,max=200"`
+	EnvID                    *int    `json:"env_id" validate:"required,gt=0"`
+}
+
+// middlewareClusterRequest is the request body of creating or replacing a middleware cluster
+type middlewareClusterRequest struct {
+	ClusterName *string `json:"cluster_name" validate:"required,min=1,max=100"`
+	OwnerID     *int    `json:"owner_id" validate:"omitempty,gte=0"`
+	EnvID       *int    `json:"env_id" validate:"required,gt=0"`
+}
+
+// middlewareServerRequest is the request body of creating or replacing a middleware server,
+// the middleware role is one of 1(rw), 2(ro) and 3(das)
+type middlewareServerRequest struct {
+	ClusterID      *int    `json:"cluster_id" validate:"required,gt=0"`
+	ServerName     *string `json:"server_name" validate:"required,min=1,max=100"`
+	MiddlewareRole *int    `json:"middleware_role" validate:"required,oneof=1 2 3"`
+	HostIP         *string `json:"host_ip" validate:"required,ip"`
+	PortNum        *int    `json:"port_num" validate:"required,min=1,max=65535"`
+}
+
+// mysqlClusterRequest is the request body of creating or replacing a mysql cluster
+type mysqlClusterRequest struct {
+	ClusterName         *string `json:"cluster_name" validate:"required,min=1,max=100"`
+	MiddlewareClusterID *int    `json:"middleware_cluster_id" validate:"omitempty,gte=0"`
+	MonitorSystemID     *int    `json:"monitor_system_id" validate:"omitempty,gte=0"`
+	OwnerID             *int    `json:"owner_id" validate:"omitempty,gte=0"`
+	EnvID               *int    `json:"env_id" validate:"required,gt=0"`
+}
+
+// mysqlServerRequest is the request body of creating or replacing a mysql server,
+// the deployment type is one of 1(container), 2(physical machine) and 3(virtual machine),
+// the server role is one of 1(primary), 2(replica) and 3(delayed replica),
+// the state is one of 1(provisioning), 2(online), 3(maintenance) and 4(decommissioned)
+type mysqlServerRequest struct {
+	ClusterID      *int    `json:"cluster_id" validate:"required,gt=0"`
+	ServerName     *string `json:"server_name" validate:"required,min=1,max=100"`
+	ServiceName    *string `json:"service_name" validate:"omitempty,max=100"`
+	HostIP         *string `json:"host_ip" validate:"required,ip"`
+	PortNum        *int    `json:"port_num" validate:"required,min=1,max=65535"`
+	DeploymentType *int    `json:"deployment_type" validate:"required,oneof=1 2 3"`
+	ServerRole     *int    `json:"server_role" validate:"omitempty,oneof=1 2 3"`
+	ReadWeight     *int    `json:"read_weight" validate:"omitempty,gte=0"`
+	ReadOnly       *int    `json:"read_only" validate:"omitempty,oneof=0 1"`
+	CPUCores       *int    `json:"cpu_cores" validate:"omitempty,gte=0"`
+	MemorySize     *int    `json:"memory_size" validate:"omitempty,gte=0"`
+	DiskSize       *int    `json:"disk_size" validate:"omitempty,gte=0"`
+	DataDir        *string `json:"data_dir" validate:"omitempty,max=200"`
+	Version        *string `json:"version" validate:"omitempty,max=100"`
+	State          *int    `json:"state" validate:"omitempty,oneof=1 2 3 4"`
+}
+
+var (
+	Envs = &Resource{
+		Name:           "envs",
+		Tag:            "environment",
+		entitiesStruct: "Envs",
+		entity:         &metadata.EnvInfo{},
+		newRequest:     func() interface{} { return &envRequest{} },
+		newService:     func() service { return metadata.NewEnvServiceWithDefault() },
+	}
+	Users = &Resource{
+		Name:           "users",
+		Tag:            "user",
+		entitiesStruct: "Users",
+		entity:         &metadata.UserInfo{},
+		newRequest:     func() interface{} { return &userRequest{} },
+		newService:     func() service { return metadata.NewUserServiceWithDefault() },
+	}
+	Apps = &Resource{
+		Name:           "apps",
+		Tag:            "application",
+		entitiesStruct: "Apps",
+		entity:         &metadata.AppInfo{},
+		newRequest:     func() interface{} { return &appRequest{} },
+		newService:     func() service { return metadata.NewAppServiceWithDefault() },
+	}
+	DBs = &Resource{
+		Name:           "dbs",
+		Tag:            "database",
+		entitiesStruct: "DBs",
+		entity:         &metadata.DBInfo{},
+		newRequest:     func() interface{} { return &dbRequest{} },
+		newService:     func() service { return metadata.NewDBServiceWithDefault() },
+	}
+	MonitorSystems = &Resource{
+		Name:           "monitor-systems",
+		Tag:            "monitor system",
+		entitiesStruct: "MonitorSystems",
+		entity:         &metadata.MonitorSystemInfo{},
+		newRequest:     func() interface{} { return &monitorSystemRequest{} },
+		newService:     func() service { return metadata.NewMonitorSystemServiceWithDefault() },
+	}
+	MiddlewareClusters = &Resource{
+		Name:           "middleware-clusters",
+		Tag:            "middleware cluster",
+		entitiesStruct: "MiddlewareClusters",
+		entity:         &metadata.MiddlewareClusterInfo{},
+		newRequest:     func() interface{} { return &middlewareClusterRequest{} },
+		newService:     func() service { return metadata.NewMiddlewareClusterServiceWithDefault() },
+	}
+	MiddlewareServers = &Resource{
+		Name:           "middleware-servers",
+		Tag:            "middleware server",
+		entitiesStruct: "MiddlewareServers",
+		entity:         &metadata.MiddlewareServerInfo{},
+		newRequest:     func() interface{} { return &middlewareServerRequest{} },
+		newService:     func() service { return metadata.NewMiddlewareServerServiceWithDefault() },
+	}
+	MySQLClusters = &Resource{
+		Name:           "mysql-clusters",
+		Tag:            "mysql cluster",
+		entitiesStruct: "MySQLClusters",
+		entity:         &metadata.MySQLClusterInfo{},
+		newRequest:     func() interface{} { return &mysqlClusterRequest{} },
+		newService:     func() service { return metadata.NewMySQLClusterServiceWithDefault() },
+	}
+	MySQLServers = &Resource{
+		Name:           "mysql-servers",
+		Tag:            "mysql server",
+		entitiesStruct: "MySQLServers",
+		entity:         &metadata.MySQLServerInfo{},
+		newRequest:     func() interface{} { return &mysqlServerRequest{} },
+		newService:     func() service { return metadata.NewMySQLServerServiceWithDefault() },
+	}
+
+	// Resources are all the resources of the metadata
+	Resources = []*Resource{Envs, Users, Apps, DBs, MonitorSystems, MiddlewareClusters, MiddlewareServers, MySQLClusters, MySQLServers}
+)
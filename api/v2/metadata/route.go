@@ -0,0 +1,64 @@
+package metadata
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// operation is the kind of the operation of a route, it decides the parameters and the responses in the openapi specification
+type operation int
+
+const (
+	operationList operation = iota
+	operationGet
+	operationCreate
+	operationReplace
+	operationPatch
+	operationDelete
+	operationUndelete
+	operationListRelated
+	operationAddRelated
+	operationDeleteRelated
+)
+
+// Route is a route of the resource oriented api, the routes are used to register the handlers
+// and to generate the openapi specification, so that they are always consistent
+type Route struct {
+	Method    string
+	Path      string
+	Handler   gin.HandlerFunc
+	Summary   string
+	operation operation
+	resource  *Resource
+	relation  *Relation
+}
+
+// GetRoutes returns the routes of all the resources and the relations
+func GetRoutes() []*Route {
+	var routes []*Route
+	for _, r := range Resources {
+		routes = append(routes,
+			&Route{http.MethodGet, r.Path(), r.List, "get " + r.Name + " which match the filters, sorted and paginated", operationList, r, nil},
+			&Route{http.MethodPost, r.Path(), r.Create, "create a new resource of " + r.Name, operationCreate, r, nil},
+			&Route{http.MethodGet, r.ItemPath(), r.Get, "get a resource of " + r.Name + " by id", operationGet, r, nil},
+			&Route{http.MethodPut, r.ItemPath(), r.Replace, "replace all the fields of a resource of " + r.Name, operationReplace, r, nil},
+			&Route{http.MethodPatch, r.ItemPath(), r.Patch, "update a resource of " + r.Name + " with json merge patch", operationPatch, r, nil},
+			&Route{http.MethodDelete, r.ItemPath(), r.Delete, "delete a resource of " + r.Name, operationDelete, r, nil},
+			&Route{http.MethodPost, r.ItemPath() + "/undelete", r.Undelete, "restore a deleted resource of " + r.Name, operationUndelete, r, nil},
+		)
+	}
+	for _, rel := range Relations {
+		routes = append(routes,
+			&Route{http.MethodGet, rel.Path(), rel.List, "get " + rel.Related.Name + " of a resource of " + rel.Resource.Name, operationListRelated, rel.Related, rel},
+		)
+		if rel.add != nil {
+			routes = append(routes,
+				&Route{http.MethodPut, rel.ItemPath(), rel.Add, "add a resource of " + rel.Related.Name + " to a resource of " + rel.Resource.Name, operationAddRelated, rel.Related, rel},
+				&Route{http.MethodDelete, rel.ItemPath(), rel.Delete, "delete a resource of " + rel.Related.Name + " from a resource of " + rel.Resource.Name, operationDeleteRelated, rel.Related, rel},
+			)
+		}
+	}
+
+	return routes
+}
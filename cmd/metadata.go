@@ -0,0 +1,221 @@
+/*
+Copyright © 2020 Romber Li <romber2001@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/spf13/cobra"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/app/inventory"
+	"github.com/romberli/das/pkg/message"
+	msgmeta "github.com/romberli/das/pkg/message/metadata"
+)
+
+var (
+	// inventory
+	inventoryFile   string
+	inventoryFormat string
+	inventoryKind   string
+	inventoryDryRun bool
+	inventoryPrune  bool
+	inventorySince  string
+)
+
+// metadataCmd represents the metadata command
+var metadataCmd = &cobra.Command{
+	Use:   "metadata",
+	Short: "metadata command",
+	Long:  `import, export or reconcile the metadata inventory.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := cmd.Help()
+		if err != nil {
+			fmt.Println(fmt.Sprintf("%s\n%s", message.NewMessage(message.ErrPrintHelpInfo).Error(), err.Error()))
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+
+		os.Exit(constant.DefaultNormalExitCode)
+	},
+}
+
+// metadataImportCmd represents the metadata import command
+var metadataImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "import command",
+	Long:  `import the inventory document which contains envs, users, monitor systems, clusters, servers, databases, apps and the maps of them, only print the planned changes unless --dry-run=false is specified.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		initInventory()
+
+		if inventoryFile == constant.EmptyString {
+			fmt.Println(message.NewMessage(message.ErrFieldNotExists, "file").Error())
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+		format := getInventoryFormat()
+		data, err := ioutil.ReadFile(inventoryFile)
+		if err != nil {
+			fmt.Println(message.NewMessage(msgmeta.ErrMetadataImport, format, inventoryKind, inventoryDryRun, err.Error()).Error())
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+
+		s := inventory.NewServiceWithDefault()
+		err = s.Import(context.Background(), data, format, inventoryKind, inventoryDryRun)
+		if err != nil {
+			fmt.Println(message.NewMessage(msgmeta.ErrMetadataImport, format, inventoryKind, inventoryDryRun, err.Error()).Error())
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+		jsonBytes, err := s.MarshalWithFields(inventory.PlansStruct)
+		if err != nil {
+			fmt.Println(message.NewMessage(message.ErrMarshalData, err.Error()).Error())
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+
+		fmt.Println(string(jsonBytes))
+		fmt.Println(message.NewMessage(msgmeta.InfoMetadataImport, format, inventoryKind, inventoryDryRun).Error())
+		os.Exit(constant.DefaultNormalExitCode)
+	},
+}
+
+// metadataReconcileCmd represents the metadata reconcile command
+var metadataReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "reconcile command",
+	Long: `reconcile the registered metadata to the desired state in the inventory document,
+the registered entities of the kinds in the document which are not in the document are deleted unless --prune=false is specified,
+the kinds which are missing or null in the document are never pruned,
+only print the planned changes unless --dry-run=false is specified,
+specify --since with the create time of the reviewed plan to fail if any entity was updated after the plan was reviewed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		initInventory()
+
+		if inventoryFile == constant.EmptyString {
+			fmt.Println(message.NewMessage(message.ErrFieldNotExists, "file").Error())
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+		format := getInventoryFormat()
+		var since time.Time
+		if inventorySince != constant.EmptyString {
+			var err error
+			since, err = time.Parse(time.RFC3339, inventorySince)
+			if err != nil {
+				fmt.Println(message.NewMessage(msgmeta.ErrMetadataReconcile, format, inventoryKind, inventoryPrune, inventoryDryRun, err.Error()).Error())
+				os.Exit(constant.DefaultAbnormalExitCode)
+			}
+		}
+		data, err := ioutil.ReadFile(inventoryFile)
+		if err != nil {
+			fmt.Println(message.NewMessage(msgmeta.ErrMetadataReconcile, format, inventoryKind, inventoryPrune, inventoryDryRun, err.Error()).Error())
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+
+		s := inventory.NewServiceWithDefault()
+		err = s.Reconcile(context.Background(), data, format, inventoryKind, inventoryPrune, since, inventoryDryRun)
+		if err != nil {
+			fmt.Println(message.NewMessage(msgmeta.ErrMetadataReconcile, format, inventoryKind, inventoryPrune, inventoryDryRun, err.Error()).Error())
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+		jsonBytes, err := s.MarshalWithFields(inventory.PlansStruct)
+		if err != nil {
+			fmt.Println(message.NewMessage(message.ErrMarshalData, err.Error()).Error())
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+
+		fmt.Println(string(jsonBytes))
+		fmt.Println(message.NewMessage(msgmeta.InfoMetadataReconcile, format, inventoryKind, inventoryPrune, inventoryDryRun).Error())
+		os.Exit(constant.DefaultNormalExitCode)
+	},
+}
+
+// metadataExportCmd represents the metadata export command
+var metadataExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export command",
+	Long:  `export the registered metadata as the inventory document, print it to the standard output if the file is not specified.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		initInventory()
+
+		format := getInventoryFormat()
+		s := inventory.NewServiceWithDefault()
+		err := s.Export(context.Background(), inventoryKind)
+		if err != nil {
+			fmt.Println(message.NewMessage(msgmeta.ErrMetadataExport, format, inventoryKind, err.Error()).Error())
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+		data, err := s.Encode(format, inventoryKind)
+		if err != nil {
+			fmt.Println(message.NewMessage(msgmeta.ErrMetadataExport, format, inventoryKind, err.Error()).Error())
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+
+		if inventoryFile == constant.EmptyString {
+			fmt.Print(string(data))
+			os.Exit(constant.DefaultNormalExitCode)
+		}
+		err = ioutil.WriteFile(inventoryFile, data, constant.DefaultFileMode)
+		if err != nil {
+			fmt.Println(message.NewMessage(msgmeta.ErrMetadataExport, format, inventoryKind, err.Error()).Error())
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+
+		fmt.Println(message.NewMessage(msgmeta.InfoMetadataExport, format, inventoryKind).Error())
+		os.Exit(constant.DefaultNormalExitCode)
+	},
+}
+
+// initInventory initiates the config and the connection pool of the das database
+func initInventory() {
+	err := initConfig()
+	if err != nil {
+		fmt.Println(fmt.Sprintf("%s\n%s", message.NewMessage(message.ErrInitConfig).Error(), err.Error()))
+		os.Exit(constant.DefaultAbnormalExitCode)
+	}
+
+	err = global.InitDASMySQLPool()
+	if err != nil {
+		fmt.Println(fmt.Sprintf("%s\n%s", message.NewMessage(message.ErrInitConnectionPool).Error(), err.Error()))
+		os.Exit(constant.DefaultAbnormalExitCode)
+	}
+}
+
+// getInventoryFormat returns the format of the inventory document, it is determined by the file extension if it is not specified
+func getInventoryFormat() string {
+	if inventoryFormat != constant.EmptyString {
+		return inventoryFormat
+	}
+
+	return inventory.GetFormatByFileName(inventoryFile)
+}
+
+func init() {
+	rootCmd.AddCommand(metadataCmd)
+	metadataCmd.AddCommand(metadataImportCmd)
+	metadataCmd.AddCommand(metadataExportCmd)
+	metadataCmd.AddCommand(metadataReconcileCmd)
+
+	// inventory
+	metadataCmd.PersistentFlags().StringVar(&inventoryFile, "file", constant.EmptyString, "specify the inventory document file")
+	metadataCmd.PersistentFlags().StringVar(&inventoryFormat, "format", constant.EmptyString, fmt.Sprintf("specify the format of the inventory document: json, yaml or csv(default: determined by the file extension, %s if unknown)", inventory.DefaultFormat))
+	metadataCmd.PersistentFlags().StringVar(&inventoryKind, "kind", constant.EmptyString, "specify the kind of the entities, it is required when the format is csv")
+	metadataImportCmd.Flags().BoolVar(&inventoryDryRun, "dry-run", true, "only print the planned changes without applying them")
+	metadataReconcileCmd.Flags().BoolVar(&inventoryDryRun, "dry-run", true, "only print the planned changes without applying them")
+	metadataReconcileCmd.Flags().BoolVar(&inventoryPrune, "prune", true, "delete the registered entities of the kinds in the document which are not in the document")
+	metadataReconcileCmd.Flags().StringVar(&inventorySince, "since", constant.EmptyString, "specify the create time of the reviewed plan in RFC3339 format, the entities which were updated after it are conflicts")
+}
@@ -46,33 +46,41 @@ var (
 	logMaxDays    int
 	logMaxBackups int
 	// server
-	serverAddr         string
-	serverPid          int
-	serverPidFile      string
-	serverReadTimeout  int
-	serverWriteTimeout int
+	serverAddr               string
+	serverPid                int
+	serverPidFile            string
+	serverReadTimeout        int
+	serverWriteTimeout       int
+	serverLegacyResponseStr  string
+	serverShutdownTimeout    int
+	serverRequestTimeout     int
+	serverLongRequestTimeout int
+	// status
+	statusMonitorSystems bool
 	// database
-	dbDASMySQLAddr           string
-	dbDASMySQLName           string
-	dbDASMySQLUser           string
-	dbDASMySQLPass           string
-	dbPoolMaxConnections     int
-	dbPoolInitConnections    int
-	dbPoolMaxIdleConnections int
-	dbPoolMaxIdleTime        int
-	dbPoolKeepAliveInterval  int
-	dbMonitorPrometheusUser  string
-	dbMonitorPrometheusPass  string
-	dbMonitorClickhouseUser  string
-	dbMonitorClickhousePass  string
-	dbMonitorMySQLUser       string
-	dbMonitorMySQLPass       string
-	dbApplicationMySQLUser   string
-	dbApplicationMySQLPass   string
-	dbSoarMySQLAddr          string
-	dbSoarMySQLName          string
-	dbSoarMySQLUser          string
-	dbSoarMySQLPass          string
+	dbDASMySQLAddr                        string
+	dbDASMySQLName                        string
+	dbDASMySQLUser                        string
+	dbDASMySQLPass                        string
+	dbPoolMaxConnections                  int
+	dbPoolInitConnections                 int
+	dbPoolMaxIdleConnections              int
+	dbPoolMaxIdleTime                     int
+	dbPoolKeepAliveInterval               int
+	dbMonitorPrometheusUser               string
+	dbMonitorPrometheusPass               string
+	dbMonitorClickhouseUser               string
+	dbMonitorClickhousePass               string
+	dbMonitorMySQLUser                    string
+	dbMonitorMySQLPass                    string
+	dbApplicationMySQLUser                string
+	dbApplicationMySQLPass                string
+	dbApplicationMySQLAllowPrimaryStr     string
+	dbApplicationMySQLMaxReplicationDelay int
+	dbSoarMySQLAddr                       string
+	dbSoarMySQLName                       string
+	dbSoarMySQLUser                       string
+	dbSoarMySQLPass                       string
 	// sqladvisor
 	sqladvisorSoarBin          string
 	sqladvisorSoarConfig       string
@@ -80,6 +88,23 @@ var (
 	sqladvisorSoarProfilingStr string
 	sqladvisorSoarTraceStr     string
 	sqladvisorSoarExplainStr   string
+	// sqladvisor auto advice
+	sqladvisorAutoAdviceEnabledStr string
+	sqladvisorAutoAdviceInterval   int
+	sqladvisorAutoAdviceTopNum     int
+	// sqladvisor review
+	sqladvisorReviewOnlineEnvName string
+	// notify
+	notifySMTPAddr string
+	notifySMTPUser string
+	notifySMTPPass string
+	notifySMTPFrom string
+	// auth
+	authEnabledStr string
+	// trace
+	traceEnabledStr   string
+	traceExporter     string
+	traceOTLPEndpoint string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -128,6 +153,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", constant.DefaultRandomString, "config file path")
 	// daemon
 	rootCmd.PersistentFlags().StringVar(&daemonStr, "daemon", constant.DefaultRandomString, fmt.Sprintf("whether run in background as a daemon(default: %s)", constant.FalseString))
+	rootCmd.PersistentFlags().IntVar(&serverShutdownTimeout, "server-shutdown-timeout", constant.DefaultRandomInt, fmt.Sprintf("specify the timeout in seconds of waiting for the running requests and operations when the server shuts down(default: %d)", config.DefaultServerShutdownTimeout))
 	// log
 	rootCmd.PersistentFlags().StringVar(&logFileName, "log-file", constant.DefaultRandomString, fmt.Sprintf("specify the log file name(default: %s)", filepath.Join(config.DefaultLogDir, log.DefaultLogFileName)))
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", constant.DefaultRandomString, fmt.Sprintf("specify the log level(default: %s)", log.DefaultLogLevel))
@@ -139,7 +165,10 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&serverAddr, "server-addr", constant.DefaultRandomString, fmt.Sprintf("specify the server port(default: %s)", config.DefaultServerAddr))
 	rootCmd.PersistentFlags().StringVar(&serverPidFile, "server-pid-file", constant.DefaultRandomString, fmt.Sprintf("specify the server pid file path(default: %s)", filepath.Join(config.DefaultBaseDir, fmt.Sprintf("%s.pid", config.DefaultCommandName))))
 	rootCmd.PersistentFlags().IntVar(&serverReadTimeout, "server-read-timeout", constant.DefaultRandomInt, fmt.Sprintf("specify the read timeout in seconds of http request(default: %d)", config.DefaultServerReadTimeout))
-	rootCmd.PersistentFlags().IntVar(&serverWriteTimeout, "server-write-timeout", constant.DefaultRandomInt, fmt.Sprintf("specify the read timeout in seconds of http request(default: %d)", config.DefaultServerReadTimeout))
+	rootCmd.PersistentFlags().IntVar(&serverWriteTimeout, "server-write-timeout", constant.DefaultRandomInt, fmt.Sprintf("specify the write timeout in seconds of http response, 0 means no timeout(default: %d)", config.DefaultServerWriteTimeout))
+	rootCmd.PersistentFlags().IntVar(&serverRequestTimeout, "server-request-timeout", constant.DefaultRandomInt, fmt.Sprintf("specify the timeout in seconds of handling http request(default: %d)", config.DefaultServerRequestTimeout))
+	rootCmd.PersistentFlags().IntVar(&serverLongRequestTimeout, "server-long-request-timeout", constant.DefaultRandomInt, fmt.Sprintf("specify the timeout in seconds of handling the http requests which may take long(default: %d)", config.DefaultServerLongRequestTimeout))
+	rootCmd.PersistentFlags().StringVar(&serverLegacyResponseStr, "server-legacy-response", constant.DefaultRandomString, fmt.Sprintf("specify if the http api responses as the old versions for the old clients(default: %s)", constant.FalseString))
 	// database
 	rootCmd.PersistentFlags().StringVar(&dbDASMySQLAddr, "db-das-mysql-addr", constant.DefaultRandomString, fmt.Sprintf("specify das database address(format: host:port)(default: %s)", fmt.Sprintf("%s:%d", constant.DefaultLocalHostIP, constant.DefaultMySQLPort)))
 	rootCmd.PersistentFlags().StringVar(&dbDASMySQLName, "db-das-mysql-name", constant.DefaultRandomString, fmt.Sprintf("specify das database name(default: %s)", config.DefaultDBDASMySQLName))
@@ -152,6 +181,8 @@ func init() {
 	rootCmd.PersistentFlags().IntVar(&dbPoolKeepAliveInterval, "db-pool-keep-alive-interval", constant.DefaultRandomInt, fmt.Sprintf("specify keep alive interval of connections of the connection pool(default: %d, unit: seconds)", mysql.DefaultKeepAliveInterval))
 	rootCmd.PersistentFlags().StringVar(&dbApplicationMySQLUser, "db-application-mysql-user", constant.DefaultRandomString, fmt.Sprintf("specify mysql user name of application(default: %s)", config.DefaultDBUser))
 	rootCmd.PersistentFlags().StringVar(&dbApplicationMySQLPass, "db-application-mysql-pass", constant.DefaultRandomString, fmt.Sprintf("specify mysql user password of application(default: %s)", config.DefaultDBPass))
+	rootCmd.PersistentFlags().StringVar(&dbApplicationMySQLAllowPrimaryStr, "db-application-mysql-allow-primary", constant.DefaultRandomString, fmt.Sprintf("specify if the primary could be read when there is no healthy replica(default: %s)", constant.FalseString))
+	rootCmd.PersistentFlags().IntVar(&dbApplicationMySQLMaxReplicationDelay, "db-application-mysql-max-replication-delay", constant.DefaultRandomInt, fmt.Sprintf("specify max replication delay of the replica which could be read(default: %d, unit: seconds)", config.DefaultDBApplicationMySQLMaxReplicationDelay))
 	rootCmd.PersistentFlags().StringVar(&dbMonitorPrometheusUser, "db-monitor-prometheus-user", constant.DefaultRandomString, fmt.Sprintf("specify prometheus user name of monitor system(default: %s)", config.DefaultDBUser))
 	rootCmd.PersistentFlags().StringVar(&dbMonitorPrometheusPass, "db-monitor-prometheus-pass", constant.DefaultRandomString, fmt.Sprintf("specify prometheus user password of monitor system(default: %s)", config.DefaultDBPass))
 	rootCmd.PersistentFlags().StringVar(&dbMonitorClickhouseUser, "db-monitor-clickhouse-user", constant.DefaultRandomString, fmt.Sprintf("specify clickhouse user name of monitor system(default: %s)", config.DefaultDBUser))
@@ -169,6 +200,21 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&sqladvisorSoarProfilingStr, "sqladvisor-soar-profiling", constant.DefaultRandomString, fmt.Sprintf("specify if enabling profiling for soar(default: %s)", constant.FalseString))
 	rootCmd.PersistentFlags().StringVar(&sqladvisorSoarTraceStr, "sqladvisor-soar-trace", constant.DefaultRandomString, fmt.Sprintf("specify if enabling trace for soar(default: %s)", constant.FalseString))
 	rootCmd.PersistentFlags().StringVar(&sqladvisorSoarExplainStr, "sqladvisor-soar-explain", constant.DefaultRandomString, fmt.Sprintf("specify if enabling explain for soar(default: %s)", constant.FalseString))
+	rootCmd.PersistentFlags().StringVar(&sqladvisorAutoAdviceEnabledStr, "sqladvisor-auto-advice-enabled", constant.DefaultRandomString, fmt.Sprintf("specify if advising top slow queries automatically in background(default: %s)", constant.FalseString))
+	rootCmd.PersistentFlags().IntVar(&sqladvisorAutoAdviceInterval, "sqladvisor-auto-advice-interval", constant.DefaultRandomInt, fmt.Sprintf("specify interval of advising top slow queries automatically(default: %d, unit: seconds)", config.DefaultSQLAdvisorAutoAdviceInterval))
+	rootCmd.PersistentFlags().IntVar(&sqladvisorAutoAdviceTopNum, "sqladvisor-auto-advice-top-num", constant.DefaultRandomInt, fmt.Sprintf("specify how many top slow queries of each mysql server will be advised automatically(default: %d)", config.DefaultSQLAdvisorAutoAdviceTopNum))
+	rootCmd.PersistentFlags().StringVar(&sqladvisorReviewOnlineEnvName, "sqladvisor-review-online-env-name", constant.DefaultRandomString, fmt.Sprintf("specify env name of the online environment, the drop rule of the review only applies to it(default: %s)", config.DefaultSQLAdvisorReviewOnlineEnvName))
+	// notify
+	rootCmd.PersistentFlags().StringVar(&notifySMTPAddr, "notify-smtp-addr", constant.DefaultRandomString, "specify smtp server address(format: host:port), empty means sending email is disabled(default: )")
+	rootCmd.PersistentFlags().StringVar(&notifySMTPUser, "notify-smtp-user", constant.DefaultRandomString, "specify smtp user name(default: )")
+	rootCmd.PersistentFlags().StringVar(&notifySMTPPass, "notify-smtp-pass", constant.DefaultRandomString, "specify smtp user password(default: )")
+	rootCmd.PersistentFlags().StringVar(&notifySMTPFrom, "notify-smtp-from", constant.DefaultRandomString, "specify email address of the sender(default: )")
+	// auth
+	rootCmd.PersistentFlags().StringVar(&authEnabledStr, "auth-enabled", constant.DefaultRandomString, fmt.Sprintf("specify if the http api requires authentication(default: %s)", constant.FalseString))
+	// trace
+	rootCmd.PersistentFlags().StringVar(&traceEnabledStr, "trace-enabled", constant.DefaultRandomString, fmt.Sprintf("specify if exporting the tracing spans(default: %s)", constant.FalseString))
+	rootCmd.PersistentFlags().StringVar(&traceExporter, "trace-exporter", constant.DefaultRandomString, fmt.Sprintf("specify the exporter of the tracing spans, stdout or otlp(default: %s)", config.DefaultTraceExporter))
+	rootCmd.PersistentFlags().StringVar(&traceOTLPEndpoint, "trace-otlp-endpoint", constant.DefaultRandomString, fmt.Sprintf("specify the url of the otlp http traces endpoint(default: %s)", config.DefaultTraceOTLPEndpoint))
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -308,6 +354,20 @@ func OverrideConfig() (err error) {
 	if serverWriteTimeout != constant.DefaultRandomInt {
 		viper.Set(config.ServerWriteTimeoutKey, serverWriteTimeout)
 	}
+	if serverLegacyResponseStr == constant.TrueString {
+		viper.Set(config.ServerLegacyResponseKey, true)
+	} else if serverLegacyResponseStr == constant.FalseString {
+		viper.Set(config.ServerLegacyResponseKey, false)
+	}
+	if serverShutdownTimeout != constant.DefaultRandomInt {
+		viper.Set(config.ServerShutdownTimeoutKey, serverShutdownTimeout)
+	}
+	if serverRequestTimeout != constant.DefaultRandomInt {
+		viper.Set(config.ServerRequestTimeoutKey, serverRequestTimeout)
+	}
+	if serverLongRequestTimeout != constant.DefaultRandomInt {
+		viper.Set(config.ServerLongRequestTimeoutKey, serverLongRequestTimeout)
+	}
 
 	// override database
 	if dbDASMySQLAddr != constant.DefaultRandomString {
@@ -361,6 +421,14 @@ func OverrideConfig() (err error) {
 	if dbApplicationMySQLPass != constant.DefaultRandomString {
 		viper.Set(config.DBApplicationMySQLPassKey, dbApplicationMySQLPass)
 	}
+	if dbApplicationMySQLAllowPrimaryStr == constant.TrueString {
+		viper.Set(config.DBApplicationMySQLAllowPrimaryKey, true)
+	} else if dbApplicationMySQLAllowPrimaryStr == constant.FalseString {
+		viper.Set(config.DBApplicationMySQLAllowPrimaryKey, false)
+	}
+	if dbApplicationMySQLMaxReplicationDelay != constant.DefaultRandomInt {
+		viper.Set(config.DBApplicationMySQLMaxReplicationDelayKey, dbApplicationMySQLMaxReplicationDelay)
+	}
 	if dbSoarMySQLAddr != constant.DefaultRandomString {
 		viper.Set(config.DBDASMySQLAddrKey, dbSoarMySQLAddr)
 	}
@@ -401,6 +469,54 @@ func OverrideConfig() (err error) {
 	} else {
 		viper.Set(config.SQLAdvisorSoarExplainKey, false)
 	}
+	if sqladvisorAutoAdviceEnabledStr == constant.TrueString {
+		viper.Set(config.SQLAdvisorAutoAdviceEnabledKey, true)
+	} else if sqladvisorAutoAdviceEnabledStr == constant.FalseString {
+		viper.Set(config.SQLAdvisorAutoAdviceEnabledKey, false)
+	}
+	if sqladvisorAutoAdviceInterval != constant.DefaultRandomInt {
+		viper.Set(config.SQLAdvisorAutoAdviceIntervalKey, sqladvisorAutoAdviceInterval)
+	}
+	if sqladvisorAutoAdviceTopNum != constant.DefaultRandomInt {
+		viper.Set(config.SQLAdvisorAutoAdviceTopNumKey, sqladvisorAutoAdviceTopNum)
+	}
+	if sqladvisorReviewOnlineEnvName != constant.DefaultRandomString {
+		viper.Set(config.SQLAdvisorReviewOnlineEnvNameKey, sqladvisorReviewOnlineEnvName)
+	}
+
+	// override notify
+	if notifySMTPAddr != constant.DefaultRandomString {
+		viper.Set(config.NotifySMTPAddrKey, notifySMTPAddr)
+	}
+	if notifySMTPUser != constant.DefaultRandomString {
+		viper.Set(config.NotifySMTPUserKey, notifySMTPUser)
+	}
+	if notifySMTPPass != constant.DefaultRandomString {
+		viper.Set(config.NotifySMTPPassKey, notifySMTPPass)
+	}
+	if notifySMTPFrom != constant.DefaultRandomString {
+		viper.Set(config.NotifySMTPFromKey, notifySMTPFrom)
+	}
+
+	// override auth
+	if authEnabledStr == constant.TrueString {
+		viper.Set(config.AuthEnabledKey, true)
+	} else if authEnabledStr == constant.FalseString {
+		viper.Set(config.AuthEnabledKey, false)
+	}
+
+	// override trace
+	if traceEnabledStr == constant.TrueString {
+		viper.Set(config.TraceEnabledKey, true)
+	} else if traceEnabledStr == constant.FalseString {
+		viper.Set(config.TraceEnabledKey, false)
+	}
+	if traceExporter != constant.DefaultRandomString {
+		viper.Set(config.TraceExporterKey, traceExporter)
+	}
+	if traceOTLPEndpoint != constant.DefaultRandomString {
+		viper.Set(config.TraceOTLPEndpointKey, traceOTLPEndpoint)
+	}
 
 	// validate configuration
 	err = config.ValidateConfig()
@@ -29,7 +29,9 @@ import (
 
 	"github.com/romberli/das/config"
 	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/app/sqladvisor"
 	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/das/pkg/tracing"
 	"github.com/romberli/das/server"
 )
 
@@ -112,17 +114,38 @@ var startCmd = &cobra.Command{
 				os.Exit(constant.DefaultAbnormalExitCode)
 			}
 
+			// init tracer
+			err = tracing.Init()
+			if err != nil {
+				log.Error(message.NewMessage(message.ErrInitTracer, err.Error()).Error())
+				os.Exit(constant.DefaultAbnormalExitCode)
+			}
+			// start auto advisor
+			var autoAdvisor *sqladvisor.AutoAdvisor
+			if viper.GetBool(config.SQLAdvisorAutoAdviceEnabledKey) {
+				autoAdvisor = sqladvisor.NewAutoAdvisorWithDefault()
+				go autoAdvisor.Start()
+			}
+
 			// start server
 			serverAddr = viper.GetString(config.ServerAddrKey)
 			serverPidFile = viper.GetString(config.ServerPidFileKey)
 			serverReadTimeout = viper.GetInt(config.ServerReadTimeoutKey)
 			serverWriteTimeout = viper.GetInt(config.ServerWriteTimeoutKey)
-			s := server.NewServerWithDefaultRouter(serverAddr, serverPidFile, serverReadTimeout, serverWriteTimeout)
+			serverShutdownTimeout = viper.GetInt(config.ServerShutdownTimeoutKey)
+			s := server.NewServerWithDefaultRouter(serverAddr, serverPidFile, serverReadTimeout, serverWriteTimeout, serverShutdownTimeout)
 			s.Register()
 			go s.Run()
 
 			// handle signal
-			linux.HandleSignalsWithPidFile(serverPidFile)
+			sig := server.WaitForSignal()
+			log.Info(message.NewMessage(message.InfoServerShutdown, sig.String(), s.ShutdownTimeout().String()).Error())
+			if autoAdvisor != nil {
+				autoAdvisor.Stop()
+			}
+			s.Stop()
+			log.CloneStdoutLogger().Info(message.NewMessage(message.InfoServerStop, serverPid, serverPidFile).Error())
+			os.Exit(constant.DefaultNormalExitCode)
 		}
 	},
 }
@@ -16,8 +16,15 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/linux"
@@ -26,13 +33,21 @@ import (
 
 	"github.com/romberli/das/config"
 	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	readinessPath    = "/readyz"
+	readinessTimeout = 5 * time.Second
+	// the server listens on all the interfaces if the host is unspecified, the readiness is requested via the loopback address then
+	loopbackHost = "127.0.0.1"
 )
 
 // statusCmd represents the status command
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "status command",
-	Long:  `print server status.`,
+	Long:  `print server status, it prints the readiness of the dependencies as well if the server is running.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
 			err       error
@@ -74,16 +89,68 @@ var statusCmd = &cobra.Command{
 			fmt.Println(fmt.Sprintf("%s\n%s", message.NewMessage(message.ErrCheckServerRunningStatus).Error(), err.Error()))
 			os.Exit(constant.DefaultAbnormalExitCode)
 		}
-		if isRunning {
-			fmt.Println(message.NewMessage(message.InfoServerIsRunning, serverPid).Error())
-		} else {
+		if !isRunning {
 			fmt.Println(message.NewMessage(message.InfoServerNotRunning, serverPid).Error())
+			os.Exit(constant.DefaultNormalExitCode)
+		}
+		fmt.Println(message.NewMessage(message.InfoServerIsRunning, serverPid).Error())
+
+		// get readiness
+		url := getReadinessURL(viper.GetString(config.ServerAddrKey), statusMonitorSystems)
+		isReady, report, err := getReadiness(url)
+		if err != nil {
+			fmt.Println(fmt.Sprintf("%s\n%s", message.NewMessage(message.ErrGetServerReadiness, url).Error(), err.Error()))
+			os.Exit(constant.DefaultAbnormalExitCode)
+		}
+		fmt.Println(report)
+		if !isReady {
+			os.Exit(constant.DefaultAbnormalExitCode)
 		}
 
 		os.Exit(constant.DefaultNormalExitCode)
 	},
 }
 
+// getReadinessURL returns the url of the readiness endpoint of the server which listens on given address
+func getReadinessURL(addr string, checkMonitorSystems bool) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err == nil && (host == constant.EmptyString || net.ParseIP(host).IsUnspecified()) {
+		addr = net.JoinHostPort(loopbackHost, port)
+	}
+
+	return fmt.Sprintf("http://%s%s?monitor_systems=%s", addr, readinessPath, strconv.FormatBool(checkMonitorSystems))
+}
+
+// getReadiness requests the readiness endpoint, it returns if the server is ready and the indented dependency report
+func getReadiness(url string) (bool, string, error) {
+	client := &http.Client{Timeout: readinessTimeout}
+	r, err := client.Get(url)
+	if err != nil {
+		return false, constant.EmptyString, err
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return false, constant.EmptyString, err
+	}
+	if r.StatusCode != http.StatusOK && r.StatusCode != http.StatusServiceUnavailable {
+		return false, constant.EmptyString, fmt.Errorf("unexpected http status: %d, body: %s", r.StatusCode, string(body))
+	}
+	response := &resp.Response{}
+	err = json.Unmarshal(body, response)
+	if err != nil {
+		return false, constant.EmptyString, err
+	}
+	report := &bytes.Buffer{}
+	err = json.Indent(report, response.Data, constant.EmptyString, "  ")
+	if err != nil {
+		return false, constant.EmptyString, err
+	}
+
+	return r.StatusCode == http.StatusOK, report.String(), nil
+}
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
 
@@ -93,6 +160,7 @@ func init() {
 	// and all subcommands, e.g.:
 	// statusCmd.PersistentFlags().String("foo", "", "A help for foo")
 	statusCmd.PersistentFlags().IntVar(&serverPid, "server-pid", constant.DefaultRandomInt, fmt.Sprintf("specify the server pid"))
+	statusCmd.PersistentFlags().BoolVar(&statusMonitorSystems, "monitor-systems", false, fmt.Sprintf("specify if the readiness checks the monitor systems as well"))
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
@@ -68,8 +68,8 @@ var stopCmd = &cobra.Command{
 			os.Exit(constant.DefaultAbnormalExitCode)
 		}
 
-		// kill server with pid and pid file
-		err = linux.KillServer(serverPid, serverPidFile)
+		// shutdown server with pid, the server removes the pid file after it stops gracefully
+		err = linux.ShutdownServer(serverPid)
 		if err != nil {
 			log.CloneStdoutLogger().Errorf(fmt.Sprintf("%s\n%s",
 				message.NewMessage(message.ErrKillServerWithPidFile, serverPid, serverPidFile).Error(), err.Error()))
@@ -33,8 +33,10 @@ import (
 )
 
 var (
-	ValidLogLevels  = []string{"debug", "info", "warn", "warning", "error", "fatal"}
-	ValidLogFormats = []string{"text", "json"}
+	ValidLogLevels         = []string{"debug", "info", "warn", "warning", "error", "fatal"}
+	ValidLogFormats        = []string{"text", "json"}
+	ValidAuthJWTAlgorithms = []string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512"}
+	ValidTraceExporters    = []string{TraceExporterStdout, TraceExporterOTLP}
 )
 
 // SetDefaultConfig set default configuration, it is the lowest priority
@@ -55,6 +57,10 @@ func SetDefaultConfig(baseDir string) {
 	viper.SetDefault(ServerPidFileKey, defaultPidFile)
 	viper.SetDefault(ServerReadTimeoutKey, DefaultServerReadTimeout)
 	viper.SetDefault(ServerWriteTimeoutKey, DefaultServerWriteTimeout)
+	viper.SetDefault(ServerLegacyResponseKey, DefaultServerLegacyResponse)
+	viper.SetDefault(ServerShutdownTimeoutKey, DefaultServerShutdownTimeout)
+	viper.SetDefault(ServerRequestTimeoutKey, DefaultServerRequestTimeout)
+	viper.SetDefault(ServerLongRequestTimeoutKey, DefaultServerLongRequestTimeout)
 	// database
 	viper.SetDefault(DBDASMySQLAddrKey, fmt.Sprintf("%s:%d", constant.DefaultLocalHostIP, constant.DefaultMySQLPort))
 	viper.SetDefault(DBDASMySQLNameKey, DefaultDBDASMySQLName)
@@ -73,6 +79,8 @@ func SetDefaultConfig(baseDir string) {
 	viper.SetDefault(DBMonitorMySQLPassKey, DefaultDBMonitorMySQLPass)
 	viper.SetDefault(DBApplicationMySQLUserKey, DefaultDBApplicationMySQLUser)
 	viper.SetDefault(DBApplicationMySQLPassKey, DefaultDBApplicationMySQLPass)
+	viper.SetDefault(DBApplicationMySQLAllowPrimaryKey, DefaultDBApplicationMySQLAllowPrimary)
+	viper.SetDefault(DBApplicationMySQLMaxReplicationDelayKey, DefaultDBApplicationMySQLMaxReplicationDelay)
 	viper.SetDefault(DBSoarMySQLAddrKey, fmt.Sprintf("%s:%d", constant.DefaultLocalHostIP, constant.DefaultMySQLPort))
 	viper.SetDefault(DBSoarMySQLNameKey, DefaultDBDASMySQLName)
 	viper.SetDefault(DBSoarMySQLUserKey, DefaultDBUser)
@@ -84,6 +92,32 @@ func SetDefaultConfig(baseDir string) {
 	viper.SetDefault(SQLAdvisorSoarProfilingKey, false)
 	viper.SetDefault(SQLAdvisorSoarTraceKey, false)
 	viper.SetDefault(SQLAdvisorSoarExplainKey, false)
+	viper.SetDefault(SQLAdvisorAutoAdviceEnabledKey, DefaultSQLAdvisorAutoAdviceEnabled)
+	viper.SetDefault(SQLAdvisorAutoAdviceIntervalKey, DefaultSQLAdvisorAutoAdviceInterval)
+	viper.SetDefault(SQLAdvisorAutoAdviceTopNumKey, DefaultSQLAdvisorAutoAdviceTopNum)
+	viper.SetDefault(SQLAdvisorReviewOnlineEnvNameKey, DefaultSQLAdvisorReviewOnlineEnvName)
+	// notify
+	viper.SetDefault(NotifySMTPAddrKey, constant.EmptyString)
+	viper.SetDefault(NotifySMTPUserKey, constant.EmptyString)
+	viper.SetDefault(NotifySMTPPassKey, constant.EmptyString)
+	viper.SetDefault(NotifySMTPFromKey, constant.EmptyString)
+	// auth
+	viper.SetDefault(AuthEnabledKey, DefaultAuthEnabled)
+	viper.SetDefault(AuthTokensKey, []string{})
+	viper.SetDefault(AuthJWTAlgorithmKey, DefaultAuthJWTAlgorithm)
+	viper.SetDefault(AuthJWTSecretKey, constant.EmptyString)
+	viper.SetDefault(AuthJWTPrivateKeyKey, constant.EmptyString)
+	viper.SetDefault(AuthJWTPublicKeyKey, constant.EmptyString)
+	viper.SetDefault(AuthJWTExpirationKey, DefaultAuthJWTExpiration)
+	viper.SetDefault(AuthLDAPAddrKey, constant.EmptyString)
+	viper.SetDefault(AuthLDAPTLSKey, DefaultAuthLDAPTLS)
+	viper.SetDefault(AuthLDAPUserDNKey, constant.EmptyString)
+	viper.SetDefault(AuthLDAPTimeoutKey, DefaultAuthLDAPTimeout)
+	// trace
+	viper.SetDefault(TraceEnabledKey, DefaultTraceEnabled)
+	viper.SetDefault(TraceExporterKey, DefaultTraceExporter)
+	viper.SetDefault(TraceOTLPEndpointKey, DefaultTraceOTLPEndpoint)
+	viper.SetDefault(TraceSampleRatioKey, DefaultTraceSampleRatio)
 }
 
 // ValidateConfig validates if the configuration is valid
@@ -120,6 +154,24 @@ func ValidateConfig() (err error) {
 		merr = multierror.Append(merr, err)
 	}
 
+	// validate notify section
+	err = ValidateNotify()
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+
+	// validate auth section
+	err = ValidateAuth()
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+
+	// validate trace section
+	err = ValidateTrace()
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+
 	return merr.ErrorOrNil()
 }
 
@@ -266,7 +318,52 @@ func ValidateServer() error {
 		merr = multierror.Append(merr, err)
 	}
 	if serverWriteTimeout < MinServerWriteTimeout || serverWriteTimeout > MaxServerWriteTimeout {
-		merr = multierror.Append(merr, message.Messages[message.ErrNotValidServerPort].Renew(MinServerReadTimeout, MaxServerWriteTimeout, serverWriteTimeout))
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidServerWriteTimeout].Renew(MinServerWriteTimeout, MaxServerWriteTimeout, serverWriteTimeout))
+	}
+
+	// validate server.legacyResponse
+	_, err = cast.ToBoolE(viper.Get(ServerLegacyResponseKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+
+	// validate server.shutdownTimeout
+	serverShutdownTimeout, err := cast.ToIntE(viper.Get(ServerShutdownTimeoutKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if serverShutdownTimeout < MinServerShutdownTimeout || serverShutdownTimeout > MaxServerShutdownTimeout {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidServerShutdownTimeout].Renew(MinServerShutdownTimeout, MaxServerShutdownTimeout, serverShutdownTimeout))
+	}
+
+	// validate server.requestTimeout
+	serverRequestTimeout, err := cast.ToIntE(viper.Get(ServerRequestTimeoutKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if serverRequestTimeout < MinServerRequestTimeout || serverRequestTimeout > MaxServerRequestTimeout {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidServerRequestTimeout].Renew(MinServerRequestTimeout, MaxServerRequestTimeout, serverRequestTimeout))
+	}
+
+	// validate server.longRequestTimeout
+	serverLongRequestTimeout, err := cast.ToIntE(viper.Get(ServerLongRequestTimeoutKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if serverLongRequestTimeout < MinServerLongRequestTimeout || serverLongRequestTimeout > MaxServerLongRequestTimeout {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidServerLongRequestTimeout].Renew(MinServerLongRequestTimeout, MaxServerLongRequestTimeout, serverLongRequestTimeout))
+	}
+
+	// the responses could not be written after server.writeTimeout is exceeded,
+	// so it must be 0 or not less than the timeouts of handling the requests
+	if serverWriteTimeout != constant.ZeroInt {
+		handlerTimeout := serverRequestTimeout
+		if serverLongRequestTimeout > handlerTimeout {
+			handlerTimeout = serverLongRequestTimeout
+		}
+		if serverRequestTimeout == constant.ZeroInt || serverLongRequestTimeout == constant.ZeroInt || serverWriteTimeout < handlerTimeout {
+			merr = multierror.Append(merr, message.Messages[message.ErrNotValidServerWriteTimeoutWithRequestTimeout].Renew(serverRequestTimeout, serverLongRequestTimeout, serverWriteTimeout))
+		}
 	}
 
 	return merr.ErrorOrNil()
@@ -357,6 +454,20 @@ func ValidateDatabase() error {
 	if err != nil {
 		merr = multierror.Append(merr, err)
 	}
+	// validate db.application.mysql.allowPrimary
+	_, err = cast.ToBoolE(viper.Get(DBApplicationMySQLAllowPrimaryKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	// validate db.application.mysql.maxReplicationDelay
+	maxReplicationDelay, err := cast.ToIntE(viper.Get(DBApplicationMySQLMaxReplicationDelayKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if maxReplicationDelay < MinDBApplicationMySQLMaxReplicationDelay || maxReplicationDelay > MaxDBApplicationMySQLMaxReplicationDelay {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidDBApplicationMySQLMaxReplicationDelay].Renew(
+			MinDBApplicationMySQLMaxReplicationDelay, MaxDBApplicationMySQLMaxReplicationDelay, maxReplicationDelay))
+	}
 	// validate db.monitor.prometheus.user
 	_, err = cast.ToStringE(viper.Get(DBMonitorPrometheusUserKey))
 	if err != nil {
@@ -488,10 +599,225 @@ func ValidateSQLAdvisor() error {
 	if err != nil {
 		merr = multierror.Append(merr, err)
 	}
+	// validate sqladvisor.autoAdvice.enabled
+	_, err = cast.ToBoolE(viper.Get(SQLAdvisorAutoAdviceEnabledKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	// validate sqladvisor.autoAdvice.interval
+	interval, err := cast.ToIntE(viper.Get(SQLAdvisorAutoAdviceIntervalKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if interval < MinSQLAdvisorAutoAdviceInterval || interval > MaxSQLAdvisorAutoAdviceInterval {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidSQLAdvisorAutoAdviceInterval].Renew(
+			MinSQLAdvisorAutoAdviceInterval, MaxSQLAdvisorAutoAdviceInterval, interval))
+	}
+	// validate sqladvisor.autoAdvice.topNum
+	topNum, err := cast.ToIntE(viper.Get(SQLAdvisorAutoAdviceTopNumKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if topNum < MinSQLAdvisorAutoAdviceTopNum || topNum > MaxSQLAdvisorAutoAdviceTopNum {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidSQLAdvisorAutoAdviceTopNum].Renew(
+			MinSQLAdvisorAutoAdviceTopNum, MaxSQLAdvisorAutoAdviceTopNum, topNum))
+	}
+	// validate sqladvisor.review.onlineEnvName
+	onlineEnvName, err := cast.ToStringE(viper.Get(SQLAdvisorReviewOnlineEnvNameKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if strings.TrimSpace(onlineEnvName) == constant.EmptyString {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidSQLAdvisorReviewOnlineEnvName].Renew())
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// ValidateNotify validates if notify section is valid
+func ValidateNotify() error {
+	merr := &multierror.Error{}
+
+	// validate notify.smtp.addr
+	smtpAddr, err := cast.ToStringE(viper.Get(NotifySMTPAddrKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	// empty smtp address means sending email is disabled
+	if smtpAddr != constant.EmptyString && !govalidator.IsDialString(smtpAddr) {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidNotifySMTPAddr].Renew(smtpAddr))
+	}
+	// validate notify.smtp.user
+	_, err = cast.ToStringE(viper.Get(NotifySMTPUserKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	// validate notify.smtp.pass
+	_, err = cast.ToStringE(viper.Get(NotifySMTPPassKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	// validate notify.smtp.from
+	smtpFrom, err := cast.ToStringE(viper.Get(NotifySMTPFromKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if smtpAddr != constant.EmptyString && !govalidator.IsEmail(smtpFrom) {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidNotifySMTPFrom].Renew(smtpFrom))
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// ValidateAuth validates if auth section is valid
+func ValidateAuth() error {
+	merr := &multierror.Error{}
+
+	// validate auth.enabled
+	_, err := cast.ToBoolE(viper.Get(AuthEnabledKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	// validate auth.tokens, each token is formatted as account_name:token
+	tokens, err := cast.ToStringSliceE(viper.Get(AuthTokensKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	for _, token := range tokens {
+		accountName, secret, ok := SplitAuthToken(token)
+		if !ok || accountName == constant.EmptyString || len(secret) < MinAuthSecretLength {
+			merr = multierror.Append(merr, message.Messages[message.ErrNotValidAuthToken].Renew(MinAuthSecretLength, accountName))
+		}
+	}
+	// validate auth.jwt.algorithm
+	algorithm, err := cast.ToStringE(viper.Get(AuthJWTAlgorithmKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	valid, err := common.ElementInSlice(ValidAuthJWTAlgorithms, algorithm)
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if !valid {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidAuthJWTAlgorithm].Renew(ValidAuthJWTAlgorithms, algorithm))
+	}
+	// validate auth.jwt.secret, empty secret means jwt is disabled
+	secret, err := cast.ToStringE(viper.Get(AuthJWTSecretKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if secret != constant.EmptyString && len(secret) < MinAuthSecretLength {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidAuthJWTSecret].Renew(MinAuthSecretLength))
+	}
+	// validate auth.jwt.privateKey and auth.jwt.publicKey, empty public key means jwt is disabled
+	for _, key := range []string{AuthJWTPrivateKeyKey, AuthJWTPublicKeyKey} {
+		keyFile, err := cast.ToStringE(viper.Get(key))
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+		if keyFile != constant.EmptyString {
+			valid, _ := govalidator.IsFilePath(keyFile)
+			if !valid {
+				merr = multierror.Append(merr, message.Messages[message.ErrNotValidAuthJWTKeyFile].Renew(keyFile))
+			}
+		}
+	}
+	// validate auth.jwt.expiration
+	expiration, err := cast.ToIntE(viper.Get(AuthJWTExpirationKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if expiration < MinAuthJWTExpiration || expiration > MaxAuthJWTExpiration {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidAuthJWTExpiration].Renew(
+			MinAuthJWTExpiration, MaxAuthJWTExpiration, expiration))
+	}
+	// validate auth.ldap.addr, empty ldap address means login is disabled
+	ldapAddr, err := cast.ToStringE(viper.Get(AuthLDAPAddrKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if ldapAddr != constant.EmptyString && !govalidator.IsDialString(ldapAddr) {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidAuthLDAPAddr].Renew(ldapAddr))
+	}
+	// validate auth.ldap.tls
+	_, err = cast.ToBoolE(viper.Get(AuthLDAPTLSKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	// validate auth.ldap.userDN, it must contain exactly one %s which will be replaced with the account name
+	userDN, err := cast.ToStringE(viper.Get(AuthLDAPUserDNKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if ldapAddr != constant.EmptyString && strings.Count(userDN, "%s") != 1 {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidAuthLDAPUserDN].Renew(userDN))
+	}
+	// validate auth.ldap.timeout
+	timeout, err := cast.ToIntE(viper.Get(AuthLDAPTimeoutKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if timeout < MinAuthLDAPTimeout || timeout > MaxAuthLDAPTimeout {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidAuthLDAPTimeout].Renew(
+			MinAuthLDAPTimeout, MaxAuthLDAPTimeout, timeout))
+	}
 
 	return merr.ErrorOrNil()
 }
 
+// ValidateTrace validates if trace section is valid
+func ValidateTrace() error {
+	merr := &multierror.Error{}
+
+	// validate trace.enabled
+	_, err := cast.ToBoolE(viper.Get(TraceEnabledKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	// validate trace.exporter
+	exporter, err := cast.ToStringE(viper.Get(TraceExporterKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	valid, err := common.ElementInSlice(ValidTraceExporters, exporter)
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if !valid {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidTraceExporter].Renew(ValidTraceExporters, exporter))
+	}
+	// validate trace.otlp.endpoint, it is used only if the exporter is otlp
+	endpoint, err := cast.ToStringE(viper.Get(TraceOTLPEndpointKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if exporter == TraceExporterOTLP && !govalidator.IsURL(endpoint) {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidTraceOTLPEndpoint].Renew(endpoint))
+	}
+	// validate trace.sampleRatio
+	sampleRatio, err := cast.ToFloat64E(viper.Get(TraceSampleRatioKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if sampleRatio < MinTraceSampleRatio || sampleRatio > MaxTraceSampleRatio {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidTraceSampleRatio].Renew(
+			MinTraceSampleRatio, MaxTraceSampleRatio, sampleRatio))
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// SplitAuthToken splits the static token which is formatted as account_name:token,
+// it returns false if the token does not contain the separator
+func SplitAuthToken(token string) (string, string, bool) {
+	i := strings.Index(token, ":")
+	if i < constant.ZeroInt {
+		return constant.EmptyString, constant.EmptyString, false
+	}
+
+	return strings.TrimSpace(token[:i]), strings.TrimSpace(token[i+1:]), true
+}
+
 // TrimSpaceOfArg trims spaces of given argument
 func TrimSpaceOfArg(arg string) string {
 	args := strings.SplitN(arg, "=", 2)
@@ -21,52 +21,92 @@ import (
 
 // global constant
 const (
-	DefaultCommandName             = "das"
-	DefaultDaemon                  = false
-	DefaultBaseDir                 = constant.CurrentDir
-	DefaultLogDir                  = "./log"
-	MinLogMaxSize                  = 1
-	MaxLogMaxSize                  = constant.MaxInt
-	MinLogMaxDays                  = 1
-	MaxLogMaxDays                  = constant.MaxInt
-	MinLogMaxBackups               = 1
-	MaxLogMaxBackups               = constant.MaxInt
-	DefaultServerAddr              = "0.0.0.0:6090"
-	DefaultServerReadTimeout       = 5
-	DefaultServerWriteTimeout      = 10
-	MinServerReadTimeout           = 0
-	MaxServerReadTimeout           = 60
-	MinServerWriteTimeout          = 1
-	MaxServerWriteTimeout          = 60
-	DaemonArgTrue                  = "--daemon=true"
-	DaemonArgFalse                 = "--daemon=false"
-	DefaultDBDASMySQLName          = "das"
-	DefaultDBSoarMySQLName         = "soar"
-	DefaultDBUser                  = "root"
-	DefaultDBPass                  = "root"
-	MinDBPoolMaxConnections        = 1
-	MaxDBPoolMaxConnections        = constant.MaxInt
-	MinDBPoolInitConnections       = 1
-	MaxDBPoolInitConnections       = constant.MaxInt
-	MinDBPoolMaxIdleConnections    = 1
-	MaxDBPoolMaxIdleConnections    = constant.MaxInt
-	MinDBPoolMaxIdleTime           = 1
-	MaxDBPoolMaxIdleTime           = constant.MaxInt
-	MinDBPoolKeepAliveInterval     = 1
-	MaxDBPoolKeepAliveInterval     = constant.MaxInt
-	DefaultDBMonitorPrometheusUser = "root"
-	DefaultDBMonitorPrometheusPass = "root"
-	DefaultDBMonitorClickhouseUser = "root"
-	DefaultDBMonitorClickhousePass = "root"
-	DefaultDBMonitorMySQLUser      = "root"
-	DefaultDBMonitorMySQLPass      = "root"
-	DefaultDBApplicationMySQLUser  = "root"
-	DefaultDBApplicationMySQLPass  = "root"
-	DefaultDBSoarMySQLUser         = "root"
-	DefaultDBSoarMySQLPass         = "root"
-	DefaultSQLAdvisorSoarBin       = "./soar"
-	DefaultSQLAdvisorSoarConfig    = "./soar.yaml"
-	DefaultSQLAdvisorSoarBlacklist = "./soar.blacklist"
+	DefaultCommandName                           = "das"
+	DefaultDaemon                                = false
+	DefaultBaseDir                               = constant.CurrentDir
+	DefaultLogDir                                = "./log"
+	MinLogMaxSize                                = 1
+	MaxLogMaxSize                                = constant.MaxInt
+	MinLogMaxDays                                = 1
+	MaxLogMaxDays                                = constant.MaxInt
+	MinLogMaxBackups                             = 1
+	MaxLogMaxBackups                             = constant.MaxInt
+	DefaultServerAddr                            = "0.0.0.0:6090"
+	DefaultServerReadTimeout                     = 5
+	DefaultServerWriteTimeout                    = 0
+	MinServerReadTimeout                         = 0
+	MaxServerReadTimeout                         = 60
+	MinServerWriteTimeout                        = 0
+	MaxServerWriteTimeout                        = 3600
+	DefaultServerLegacyResponse                  = false
+	DefaultServerShutdownTimeout                 = 30
+	MinServerShutdownTimeout                     = 0
+	MaxServerShutdownTimeout                     = 3600
+	DefaultServerRequestTimeout                  = 10
+	MinServerRequestTimeout                      = 0
+	MaxServerRequestTimeout                      = 3600
+	DefaultServerLongRequestTimeout              = 60
+	MinServerLongRequestTimeout                  = 0
+	MaxServerLongRequestTimeout                  = 3600
+	DaemonArgTrue                                = "--daemon=true"
+	DaemonArgFalse                               = "--daemon=false"
+	DefaultDBDASMySQLName                        = "das"
+	DefaultDBSoarMySQLName                       = "soar"
+	DefaultDBUser                                = "root"
+	DefaultDBPass                                = "root"
+	MinDBPoolMaxConnections                      = 1
+	MaxDBPoolMaxConnections                      = constant.MaxInt
+	MinDBPoolInitConnections                     = 1
+	MaxDBPoolInitConnections                     = constant.MaxInt
+	MinDBPoolMaxIdleConnections                  = 1
+	MaxDBPoolMaxIdleConnections                  = constant.MaxInt
+	MinDBPoolMaxIdleTime                         = 1
+	MaxDBPoolMaxIdleTime                         = constant.MaxInt
+	MinDBPoolKeepAliveInterval                   = 1
+	MaxDBPoolKeepAliveInterval                   = constant.MaxInt
+	DefaultDBMonitorPrometheusUser               = "root"
+	DefaultDBMonitorPrometheusPass               = "root"
+	DefaultDBMonitorClickhouseUser               = "root"
+	DefaultDBMonitorClickhousePass               = "root"
+	DefaultDBMonitorMySQLUser                    = "root"
+	DefaultDBMonitorMySQLPass                    = "root"
+	DefaultDBApplicationMySQLUser                = "root"
+	DefaultDBApplicationMySQLPass                = "root"
+	DefaultDBApplicationMySQLAllowPrimary        = false
+	DefaultDBApplicationMySQLMaxReplicationDelay = 60
+	MinDBApplicationMySQLMaxReplicationDelay     = 0
+	MaxDBApplicationMySQLMaxReplicationDelay     = constant.MaxInt
+	DefaultDBSoarMySQLUser                       = "root"
+	DefaultDBSoarMySQLPass                       = "root"
+	DefaultSQLAdvisorSoarBin                     = "./soar"
+	DefaultSQLAdvisorSoarConfig                  = "./soar.yaml"
+	DefaultSQLAdvisorSoarBlacklist               = "./soar.blacklist"
+	DefaultSQLAdvisorAutoAdviceEnabled           = false
+	DefaultSQLAdvisorAutoAdviceInterval          = 3600
+	MinSQLAdvisorAutoAdviceInterval              = 60
+	MaxSQLAdvisorAutoAdviceInterval              = constant.MaxInt
+	DefaultSQLAdvisorAutoAdviceTopNum            = 10
+	MinSQLAdvisorAutoAdviceTopNum                = 1
+	MaxSQLAdvisorAutoAdviceTopNum                = 100
+	DefaultSQLAdvisorReviewOnlineEnvName         = "online"
+	DefaultAuthEnabled                           = false
+	DefaultAuthJWTAlgorithm                      = "HS256"
+	DefaultAuthJWTExpiration                     = 43200
+	MinAuthJWTExpiration                         = 60
+	MaxAuthJWTExpiration                         = 2592000
+	MinAuthSecretLength                          = 32
+	DefaultAuthLDAPTLS                           = false
+	DefaultAuthLDAPTimeout                       = 5
+	MinAuthLDAPTimeout                           = 1
+	MaxAuthLDAPTimeout                           = 60
+	DefaultTraceEnabled                          = false
+	TraceExporterStdout                          = "stdout"
+	TraceExporterOTLP                            = "otlp"
+	DefaultTraceExporter                         = TraceExporterStdout
+	DefaultTraceOTLPEndpoint                     = "http://127.0.0.1:4318/v1/traces"
+	DefaultTraceSampleRatio                      = 1.0
+	MinTraceSampleRatio                          = 0.0
+	MaxTraceSampleRatio                          = 1.0
 )
 
 // configuration constant
@@ -83,32 +123,38 @@ const (
 	LogMaxDaysKey    = "log.maxDays"
 	LogMaxBackupsKey = "log.maxBackups"
 	// server
-	ServerAddrKey         = "server.addr"
-	ServerPidFileKey      = "server.pidFile"
-	ServerReadTimeoutKey  = "server.readTimeout"
-	ServerWriteTimeoutKey = "server.writeTimeout"
+	ServerAddrKey               = "server.addr"
+	ServerPidFileKey            = "server.pidFile"
+	ServerReadTimeoutKey        = "server.readTimeout"
+	ServerWriteTimeoutKey       = "server.writeTimeout"
+	ServerLegacyResponseKey     = "server.legacyResponse"
+	ServerShutdownTimeoutKey    = "server.shutdownTimeout"
+	ServerRequestTimeoutKey     = "server.requestTimeout"
+	ServerLongRequestTimeoutKey = "server.longRequestTimeout"
 	// database
-	DBDASMySQLAddrKey           = "db.das.mysql.addr"
-	DBDASMySQLNameKey           = "db.das.mysql.name"
-	DBDASMySQLUserKey           = "db.das.mysql.user"
-	DBDASMySQLPassKey           = "db.das.mysql.pass"
-	DBPoolMaxConnectionsKey     = "db.pool.maxConnections"
-	DBPoolInitConnectionsKey    = "db.pool.initConnections"
-	DBPoolMaxIdleConnectionsKey = "db.pool.maxIdleConnections"
-	DBPoolMaxIdleTimeKey        = "db.pool.maxIdleTime"
-	DBPoolKeepAliveIntervalKey  = "db.pool.keepAliveInterval"
-	DBApplicationMySQLUserKey   = "db.application.mysql.user"
-	DBApplicationMySQLPassKey   = "db.application.mysql.pass"
-	DBMonitorPrometheusUserKey  = "db.monitor.prometheus.user"
-	DBMonitorPrometheusPassKey  = "db.monitor.prometheus.pass"
-	DBMonitorClickhouseUserKey  = "db.monitor.clickhouse.user"
-	DBMonitorClickhousePassKey  = "db.monitor.clickhouse.pass"
-	DBMonitorMySQLUserKey       = "db.monitor.mysql.user"
-	DBMonitorMySQLPassKey       = "db.monitor.mysql.pass"
-	DBSoarMySQLAddrKey          = "db.soar.mysql.addr"
-	DBSoarMySQLNameKey          = "db.soar.mysql.name"
-	DBSoarMySQLUserKey          = "db.soar.mysql.user"
-	DBSoarMySQLPassKey          = "db.soar.mysql.pass"
+	DBDASMySQLAddrKey                        = "db.das.mysql.addr"
+	DBDASMySQLNameKey                        = "db.das.mysql.name"
+	DBDASMySQLUserKey                        = "db.das.mysql.user"
+	DBDASMySQLPassKey                        = "db.das.mysql.pass"
+	DBPoolMaxConnectionsKey                  = "db.pool.maxConnections"
+	DBPoolInitConnectionsKey                 = "db.pool.initConnections"
+	DBPoolMaxIdleConnectionsKey              = "db.pool.maxIdleConnections"
+	DBPoolMaxIdleTimeKey                     = "db.pool.maxIdleTime"
+	DBPoolKeepAliveIntervalKey               = "db.pool.keepAliveInterval"
+	DBApplicationMySQLUserKey                = "db.application.mysql.user"
+	DBApplicationMySQLPassKey                = "db.application.mysql.pass"
+	DBApplicationMySQLAllowPrimaryKey        = "db.application.mysql.allowPrimary"
+	DBApplicationMySQLMaxReplicationDelayKey = "db.application.mysql.maxReplicationDelay"
+	DBMonitorPrometheusUserKey               = "db.monitor.prometheus.user"
+	DBMonitorPrometheusPassKey               = "db.monitor.prometheus.pass"
+	DBMonitorClickhouseUserKey               = "db.monitor.clickhouse.user"
+	DBMonitorClickhousePassKey               = "db.monitor.clickhouse.pass"
+	DBMonitorMySQLUserKey                    = "db.monitor.mysql.user"
+	DBMonitorMySQLPassKey                    = "db.monitor.mysql.pass"
+	DBSoarMySQLAddrKey                       = "db.soar.mysql.addr"
+	DBSoarMySQLNameKey                       = "db.soar.mysql.name"
+	DBSoarMySQLUserKey                       = "db.soar.mysql.user"
+	DBSoarMySQLPassKey                       = "db.soar.mysql.pass"
 	// sqladvisor
 	SQLAdvisorSoarBin          = "sqladvisor.soar.Bin"
 	SQLAdvisorSoarConfig       = "sqladvisor.soar.Config"
@@ -116,4 +162,32 @@ const (
 	SQLAdvisorSoarProfilingKey = "sqladvisor.soar.profiling"
 	SQLAdvisorSoarTraceKey     = "sqladvisor.soar.trace"
 	SQLAdvisorSoarExplainKey   = "sqladvisor.soar.explain"
+	// sqladvisor auto advice
+	SQLAdvisorAutoAdviceEnabledKey  = "sqladvisor.autoAdvice.enabled"
+	SQLAdvisorAutoAdviceIntervalKey = "sqladvisor.autoAdvice.interval"
+	SQLAdvisorAutoAdviceTopNumKey   = "sqladvisor.autoAdvice.topNum"
+	// sqladvisor review
+	SQLAdvisorReviewOnlineEnvNameKey = "sqladvisor.review.onlineEnvName"
+	// notify
+	NotifySMTPAddrKey = "notify.smtp.addr"
+	NotifySMTPUserKey = "notify.smtp.user"
+	NotifySMTPPassKey = "notify.smtp.pass"
+	NotifySMTPFromKey = "notify.smtp.from"
+	// auth
+	AuthEnabledKey       = "auth.enabled"
+	AuthTokensKey        = "auth.tokens"
+	AuthJWTAlgorithmKey  = "auth.jwt.algorithm"
+	AuthJWTSecretKey     = "auth.jwt.secret"
+	AuthJWTPrivateKeyKey = "auth.jwt.privateKey"
+	AuthJWTPublicKeyKey  = "auth.jwt.publicKey"
+	AuthJWTExpirationKey = "auth.jwt.expiration"
+	AuthLDAPAddrKey      = "auth.ldap.addr"
+	AuthLDAPTLSKey       = "auth.ldap.tls"
+	AuthLDAPUserDNKey    = "auth.ldap.userDN"
+	AuthLDAPTimeoutKey   = "auth.ldap.timeout"
+	// trace
+	TraceEnabledKey      = "trace.enabled"
+	TraceExporterKey     = "trace.exporter"
+	TraceOTLPEndpointKey = "trace.otlp.endpoint"
+	TraceSampleRatioKey  = "trace.sampleRatio"
 )
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/internal/dependency/audit"
+)
+
+const (
+	// DefaultActor is the actor of the changes which are not made by the requests, such as discovery and synchronization
+	DefaultActor = "das"
+	// AnonymousActor is the actor of the changes made by the requests which are not authenticated
+	AnonymousActor = "anonymous"
+)
+
+var _ audit.Auditor = (*Auditor)(nil)
+
+// Auditor records the changes made by the actor in the request
+type Auditor struct {
+	audit.Repository
+	Actor     string
+	RequestID string
+}
+
+// NewAuditor returns a new *Auditor
+func NewAuditor(repo audit.Repository, actor, requestID string) *Auditor {
+	if actor == constant.EmptyString {
+		actor = DefaultActor
+	}
+
+	return &Auditor{
+		Repository: repo,
+		Actor:      actor,
+		RequestID:  requestID,
+	}
+}
+
+// NewAuditorWithGlobal returns a new *Auditor with global repository
+func NewAuditorWithGlobal(actor, requestID string) *Auditor {
+	return NewAuditor(NewRepositoryWithGlobal(), actor, requestID)
+}
+
+// Record records the change of the entity, before and after are the entity before and after the change,
+// nil means the entity does not exist at that time,
+// the change had been made when it is recorded, so the failure is logged instead of being returned
+func (a *Auditor) Record(ctx context.Context, entityType string, entityID int, action string, before, after interface{}) {
+	beforeJSON, err := marshalSnapshot(before)
+	if err != nil {
+		log.Errorf("audit Auditor.Record(): marshal snapshot before the change failed. entity type: %s, entity id: %d, action: %s\n%s",
+			entityType, entityID, action, err.Error())
+	}
+	afterJSON, err := marshalSnapshot(after)
+	if err != nil {
+		log.Errorf("audit Auditor.Record(): marshal snapshot after the change failed. entity type: %s, entity id: %d, action: %s\n%s",
+			entityType, entityID, action, err.Error())
+	}
+
+	err = a.Repository.Create(ctx, NewInfo(entityType, entityID, action, a.Actor, a.RequestID, beforeJSON, afterJSON))
+	if err != nil {
+		log.Errorf("audit Auditor.Record(): create audit record failed. entity type: %s, entity id: %d, action: %s, actor: %s, request id: %s\n%s",
+			entityType, entityID, action, a.Actor, a.RequestID, err.Error())
+	}
+}
+
+// marshalSnapshot marshals the snapshot of the entity to json, it returns an empty string if the snapshot is nil
+func marshalSnapshot(snapshot interface{}) (string, error) {
+	if snapshot == nil {
+		return constant.EmptyString, nil
+	}
+
+	jsonBytes, err := json.Marshal(snapshot)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return string(jsonBytes), nil
+}
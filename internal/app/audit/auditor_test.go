@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
+)
+
+// testRepository keeps the audit records in memory
+type testRepository struct {
+	records []audit.Record
+}
+
+func (tr *testRepository) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	return nil, nil
+}
+
+func (tr *testRepository) Create(ctx context.Context, record audit.Record) error {
+	tr.records = append(tr.records, record)
+
+	return nil
+}
+
+func (tr *testRepository) GetByQuery(ctx context.Context, query *filter.Query) ([]audit.Record, int, error) {
+	return tr.records, len(tr.records), nil
+}
+
+type testEntity struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestAuditorAll(t *testing.T) {
+	TestAuditor_Record(t)
+	TestInfo_MarshalJSON(t)
+}
+
+func TestAuditor_Record(t *testing.T) {
+	asst := assert.New(t)
+
+	repo := &testRepository{}
+	auditor := NewAuditor(repo, constant.EmptyString, "req-1")
+	asst.Equal(DefaultActor, auditor.Actor, "test Record() failed")
+
+	auditor.Record(context.Background(), EntityTypeApp, 1, ActionUpdate, &testEntity{ID: 1, Name: "app1"}, &testEntity{ID: 1, Name: "app2"})
+	auditor.Record(context.Background(), EntityTypeApp, 1, ActionDelete, &testEntity{ID: 1, Name: "app2"}, nil)
+	asst.Equal(2, len(repo.records), "test Record() failed")
+
+	record := repo.records[0]
+	asst.Equal(EntityTypeApp, record.GetEntityType(), "test Record() failed")
+	asst.Equal(ActionUpdate, record.GetAction(), "test Record() failed")
+	asst.Equal(DefaultActor, record.GetActor(), "test Record() failed")
+	asst.Equal("req-1", record.GetRequestID(), "test Record() failed")
+	asst.Equal(`{"id":1,"name":"app1"}`, record.GetBefore(), "test Record() failed")
+	asst.Equal(`{"id":1,"name":"app2"}`, record.GetAfter(), "test Record() failed")
+	asst.Equal(constant.EmptyString, repo.records[1].GetAfter(), "test Record() failed")
+}
+
+func TestInfo_MarshalJSON(t *testing.T) {
+	asst := assert.New(t)
+
+	info := NewInfo(EntityTypeMySQLServer, 1, ActionCreate, "admin", constant.EmptyString, constant.EmptyString, `{"id":1}`)
+	jsonBytes, err := info.MarshalJSON()
+	asst.Nil(err, common.CombineMessageWithError("test MarshalJSON() failed", err))
+
+	var result map[string]interface{}
+	err = json.Unmarshal(jsonBytes, &result)
+	asst.Nil(err, common.CombineMessageWithError("test MarshalJSON() failed", err))
+	asst.Nil(result["before"], "test MarshalJSON() failed")
+	asst.Equal(map[string]interface{}{"id": float64(1)}, result["after"], "test MarshalJSON() failed")
+	asst.Equal("admin", result["actor"], "test MarshalJSON() failed")
+}
@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+
+	"github.com/romberli/das/internal/dependency/audit"
+)
+
+const (
+	EntityTypeApp               = "app"
+	EntityTypeDB                = "db"
+	EntityTypeEnv               = "env"
+	EntityTypeMiddlewareCluster = "middleware_cluster"
+	EntityTypeMiddlewareServer  = "middleware_server"
+	EntityTypeMonitorSystem     = "monitor_system"
+	EntityTypeMySQLCluster      = "mysql_cluster"
+	EntityTypeMySQLServer       = "mysql_server"
+	EntityTypeUser              = "user"
+
+	ActionCreate        = "create"
+	ActionUpdate        = "update"
+	ActionDelete        = "delete"
+	ActionDeleteCascade = "delete_cascade"
+	ActionUndelete      = "undelete"
+	// ActionAddRelation and ActionDeleteRelation are the changes of the map of the app and database
+	ActionAddRelation    = "add_relation"
+	ActionDeleteRelation = "delete_relation"
+)
+
+var entityTypes = map[string]bool{
+	EntityTypeApp:               true,
+	EntityTypeDB:                true,
+	EntityTypeEnv:               true,
+	EntityTypeMiddlewareCluster: true,
+	EntityTypeMiddlewareServer:  true,
+	EntityTypeMonitorSystem:     true,
+	EntityTypeMySQLCluster:      true,
+	EntityTypeMySQLServer:       true,
+	EntityTypeUser:              true,
+}
+
+// IsValidEntityType returns if the entity type is audited
+func IsValidEntityType(entityType string) bool {
+	return entityTypes[entityType]
+}
+
+var _ audit.Record = (*Info)(nil)
+
+// Info is an audit record of a metadata change
+type Info struct {
+	ID         int       `middleware:"id" json:"id"`
+	EntityType string    `middleware:"entity_type" json:"entity_type"`
+	EntityID   int       `middleware:"entity_id" json:"entity_id"`
+	Action     string    `middleware:"action" json:"action"`
+	Actor      string    `middleware:"actor" json:"actor"`
+	RequestID  string    `middleware:"request_id" json:"request_id"`
+	Before     string    `middleware:"before_data" json:"before"`
+	After      string    `middleware:"after_data" json:"after"`
+	CreateTime time.Time `middleware:"create_time" json:"create_time"`
+}
+
+// NewInfo returns a new *Info, before and after are the json of the entity, empty string means the entity does not exist
+func NewInfo(entityType string, entityID int, action, actor, requestID, before, after string) *Info {
+	return &Info{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Actor:      actor,
+		RequestID:  requestID,
+		Before:     before,
+		After:      after,
+	}
+}
+
+// NewEmptyInfo returns an empty *Info
+func NewEmptyInfo() *Info {
+	return &Info{}
+}
+
+// Identity returns the identity
+func (i *Info) Identity() int {
+	return i.ID
+}
+
+// GetEntityType returns the type of the changed entity
+func (i *Info) GetEntityType() string {
+	return i.EntityType
+}
+
+// GetEntityID returns the identity of the changed entity
+func (i *Info) GetEntityID() int {
+	return i.EntityID
+}
+
+// GetAction returns the action of the change
+func (i *Info) GetAction() string {
+	return i.Action
+}
+
+// GetActor returns the actor who made the change
+func (i *Info) GetActor() string {
+	return i.Actor
+}
+
+// GetRequestID returns the identity of the request which made the change
+func (i *Info) GetRequestID() string {
+	return i.RequestID
+}
+
+// GetBefore returns the json of the entity before the change
+func (i *Info) GetBefore() string {
+	return i.Before
+}
+
+// GetAfter returns the json of the entity after the change
+func (i *Info) GetAfter() string {
+	return i.After
+}
+
+// GetCreateTime returns the create time
+func (i *Info) GetCreateTime() time.Time {
+	return i.CreateTime
+}
+
+// MarshalJSON marshals Info to json string, the snapshots are embedded as json objects instead of strings
+func (i *Info) MarshalJSON() ([]byte, error) {
+	type info Info
+	return json.Marshal(&struct {
+		*info
+		Before json.RawMessage `json:"before"`
+		After  json.RawMessage `json:"after"`
+	}{
+		info:   (*info)(i),
+		Before: getRawMessage(i.Before),
+		After:  getRawMessage(i.After),
+	})
+}
+
+// getRawMessage returns the raw json message of the snapshot, it returns nil which is marshaled to null if the snapshot is empty
+func getRawMessage(snapshot string) json.RawMessage {
+	if snapshot == constant.EmptyString {
+		return nil
+	}
+
+	return json.RawMessage(snapshot)
+}
@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+// columns are the columns of the audit records, they are also the fields which could be filtered and sorted
+var columns = []string{"id", "entity_type", "entity_id", "action", "actor", "request_id", "before_data", "after_data", "create_time"}
+
+var _ audit.Repository = (*Repository)(nil)
+
+type Repository struct {
+	Database middleware.Pool
+}
+
+// NewRepository returns *Repository with given middleware.Pool
+func NewRepository(db middleware.Pool) *Repository {
+	return &Repository{Database: db}
+}
+
+// NewRepositoryWithGlobal returns *Repository with global mysql pool
+func NewRepositoryWithGlobal() *Repository {
+	return NewRepository(global.DASMySQLPool)
+}
+
+// Execute executes given command and placeholders on the middleware
+func (r *Repository) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
+	conn, err := r.Database.Get()
+	if err != nil {
+		tracing.EndSpan(span, err)
+		return nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("audit Repository.Execute(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
+}
+
+// Create creates an audit record in the middleware
+func (r *Repository) Create(ctx context.Context, record audit.Record) error {
+	sql := `
+		insert into t_meta_audit_log(entity_type, entity_id, action, actor, request_id, before_data, after_data)
+		values(?, ?, ?, ?, ?, ?, ?);
+	`
+	tracing.Logger(ctx).Debugf("audit Repository.Create() insert sql: \n%s\nplaceholders: %s, %d, %s, %s, %s",
+		sql, record.GetEntityType(), record.GetEntityID(), record.GetAction(), record.GetActor(), record.GetRequestID())
+	_, err := r.Execute(ctx, sql, record.GetEntityType(), record.GetEntityID(), record.GetAction(),
+		record.GetActor(), record.GetRequestID(), record.GetBefore(), record.GetAfter())
+
+	return err
+}
+
+// GetByQuery gets the audit records which match the query from the middleware,
+// it also returns the total number of the matched records without pagination
+func (r *Repository) GetByQuery(ctx context.Context, query *filter.Query) ([]audit.Record, int, error) {
+	err := query.Validate(columns...)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+
+	where, args := query.GetWhereClause()
+	cursor, cursorArgs := query.GetCursorClause()
+
+	countSQL := fmt.Sprintf(`select count(*) from t_meta_audit_log where 1 = 1%s;`, where)
+	tracing.Logger(ctx).Debugf("audit Repository.GetByQuery() count sql: \n%s\nplaceholders: %v", countSQL, args)
+	result, err := r.Execute(ctx, countSQL, args...)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	total, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+
+	sql := fmt.Sprintf(`select %s from t_meta_audit_log where 1 = 1%s%s%s%s;`,
+		strings.Join(columns, ", "), where, cursor, query.GetOrderByClause(), query.GetLimitClause())
+	args = append(args, cursorArgs...)
+	tracing.Logger(ctx).Debugf("audit Repository.GetByQuery() sql: \n%s\nplaceholders: %v", sql, args)
+	result, err = r.Execute(ctx, sql, args...)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []*Info
+	infoList := make([]*Info, result.RowNumber())
+	for i := range infoList {
+		infoList[i] = NewEmptyInfo()
+	}
+	// map to struct
+	err = result.MapToStructSlice(infoList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []audit.Record
+	records := make([]audit.Record, result.RowNumber())
+	for i := range records {
+		records[i] = infoList[i]
+	}
+
+	return records, total, nil
+}
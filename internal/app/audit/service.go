@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
+)
+
+const (
+	RecordsStruct = "Records"
+	PageStruct    = "Page"
+
+	entityTypeColumn = "entity_type"
+	entityIDColumn   = "entity_id"
+	idColumn         = "id"
+)
+
+var _ audit.Service = (*Service)(nil)
+
+type Service struct {
+	audit.Repository
+	Records []audit.Record `json:"records"`
+	Page    *filter.Page   `json:"page"`
+}
+
+// NewService returns a new *Service
+func NewService(repo audit.Repository) *Service {
+	return &Service{
+		Repository: repo,
+		Records:    []audit.Record{},
+	}
+}
+
+// NewServiceWithDefault returns a new *Service with default repository
+func NewServiceWithDefault() *Service {
+	return NewService(NewRepositoryWithGlobal())
+}
+
+// GetRecords returns the audit records of the service
+func (s *Service) GetRecords() []audit.Record {
+	return s.Records
+}
+
+// GetByQuery gets the audit records which match the query, the latest records come first if the query is not sorted
+func (s *Service) GetByQuery(ctx context.Context, query *filter.Query) error {
+	if len(query.Sorts) == constant.ZeroInt {
+		query.Sorts = []*filter.Sort{{Column: idColumn, Desc: true}}
+	}
+
+	records, total, err := s.Repository.GetByQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	s.Records = records
+	ids := make([]int, len(records))
+	for i, record := range records {
+		ids[i] = record.Identity()
+	}
+	s.Page = query.GetPage(total, ids)
+
+	return nil
+}
+
+// GetByEntity gets the audit records of the entity which match the query
+func (s *Service) GetByEntity(ctx context.Context, entityType string, entityID int, query *filter.Query) error {
+	if !IsValidEntityType(entityType) {
+		return fmt.Errorf("entity type %s is not valid", entityType)
+	}
+
+	query.Conditions = append(query.Conditions,
+		&filter.Condition{Column: entityTypeColumn, Operator: filter.OperatorEqual, Values: []string{entityType}},
+		&filter.Condition{Column: entityIDColumn, Operator: filter.OperatorEqual, Values: []string{strconv.Itoa(entityID)}},
+	)
+
+	return s.GetByQuery(ctx, query)
+}
+
+// Marshal marshals Service to json bytes
+func (s *Service) Marshal() ([]byte, error) {
+	return s.MarshalWithFields(RecordsStruct, PageStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the Service to json bytes
+func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(s, fields...)
+}
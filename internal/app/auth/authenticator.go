@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/romberli/go-util/constant"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/dependency/auth"
+)
+
+// ErrMissingToken is returned when the request does not contain a token
+var ErrMissingToken = errors.New("token is missing, it must be sent by the Authorization header with Bearer scheme")
+
+var (
+	_ auth.Authenticator = (Chain)(nil)
+	_ auth.Authenticator = (*StaticAuthenticator)(nil)
+	_ auth.Authenticator = (*APITokenAuthenticator)(nil)
+	_ auth.Authenticator = (*JWT)(nil)
+)
+
+// Chain authenticates the token with the first authenticator which supports it
+type Chain []auth.Authenticator
+
+// NewChainWithGlobal returns a new Chain with the authenticators enabled by the global configuration,
+// the api tokens are always supported, the json web tokens and static tokens are supported only if they are configured
+func NewChainWithGlobal() (Chain, error) {
+	repo := NewRepositoryWithGlobal()
+	chain := Chain{NewAPITokenAuthenticator(repo)}
+	if IsJWTEnabled() {
+		j, err := NewJWTWithGlobal(repo)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, j)
+	}
+	static, err := NewStaticAuthenticatorWithGlobal()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(chain, static), nil
+}
+
+// Supports returns if any authenticator of the chain supports the token
+func (c Chain) Supports(token string) bool {
+	for _, authenticator := range c {
+		if authenticator.Supports(token) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authenticate authenticates the token with the first authenticator which supports it
+func (c Chain) Authenticate(ctx context.Context, token string) (string, error) {
+	if token == constant.EmptyString {
+		return constant.EmptyString, ErrMissingToken
+	}
+
+	for _, authenticator := range c {
+		if authenticator.Supports(token) {
+			return authenticator.Authenticate(ctx, token)
+		}
+	}
+
+	return constant.EmptyString, ErrNotValidToken
+}
+
+// StaticAuthenticator authenticates the static tokens of the configuration, static tokens never expire
+type StaticAuthenticator struct {
+	// tokens maps the tokens to the account names
+	tokens map[string]string
+}
+
+// NewStaticAuthenticator returns a new *StaticAuthenticator, tokens maps the tokens to the account names
+func NewStaticAuthenticator(tokens map[string]string) *StaticAuthenticator {
+	return &StaticAuthenticator{tokens: tokens}
+}
+
+// NewStaticAuthenticatorWithGlobal returns a new *StaticAuthenticator with the static tokens of the global configuration
+func NewStaticAuthenticatorWithGlobal() (*StaticAuthenticator, error) {
+	list, err := cast.ToStringSliceE(viper.Get(config.AuthTokensKey))
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make(map[string]string, len(list))
+	for _, token := range list {
+		accountName, secret, ok := config.SplitAuthToken(token)
+		if !ok {
+			return nil, errors.New("static token must be formatted as account_name:token")
+		}
+		tokens[secret] = accountName
+	}
+
+	return NewStaticAuthenticator(tokens), nil
+}
+
+// Supports returns true if there are static tokens and the token is not an api token or a json web token,
+// so that the tokens of the other authenticators are never compared with the static tokens
+func (sa *StaticAuthenticator) Supports(token string) bool {
+	return len(sa.tokens) > constant.ZeroInt && !isAPIToken(token) && !isJWT(token)
+}
+
+// Authenticate compares the token with all the static tokens in constant time
+func (sa *StaticAuthenticator) Authenticate(ctx context.Context, token string) (string, error) {
+	accountName := constant.EmptyString
+	for secret, name := range sa.tokens {
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1 {
+			accountName = name
+		}
+	}
+	if accountName == constant.EmptyString {
+		return constant.EmptyString, ErrNotValidToken
+	}
+
+	return accountName, nil
+}
+
+// APITokenAuthenticator authenticates the api tokens issued by the api
+type APITokenAuthenticator struct {
+	repo auth.Repository
+}
+
+// NewAPITokenAuthenticator returns a new *APITokenAuthenticator
+func NewAPITokenAuthenticator(repo auth.Repository) *APITokenAuthenticator {
+	return &APITokenAuthenticator{repo: repo}
+}
+
+// Supports returns if the token has the prefix of the api tokens
+func (aa *APITokenAuthenticator) Supports(token string) bool {
+	return isAPIToken(token)
+}
+
+// Authenticate authenticates the token with the token key which is saved in the middleware
+func (aa *APITokenAuthenticator) Authenticate(ctx context.Context, token string) (string, error) {
+	return aa.repo.GetAccountName(ctx, TokenTypeAPI, getAPITokenKey(token))
+}
+
+// IsJWTEnabled returns if the key to verify the json web tokens is configured
+func IsJWTEnabled() bool {
+	if strings.HasPrefix(viper.GetString(config.AuthJWTAlgorithmKey), "HS") {
+		return viper.GetString(config.AuthJWTSecretKey) != constant.EmptyString
+	}
+
+	return viper.GetString(config.AuthJWTPublicKeyKey) != constant.EmptyString
+}
+
+// JWT signs and verifies the json web tokens, the ids of the tokens are saved in the middleware so that they could be revoked
+type JWT struct {
+	method     jwt.SigningMethod
+	signKey    interface{}
+	verifyKey  interface{}
+	expiration time.Duration
+	repo       auth.Repository
+}
+
+// NewJWT returns a new *JWT, signKey could be nil if the tokens are only verified
+func NewJWT(method jwt.SigningMethod, signKey, verifyKey interface{}, expiration time.Duration, repo auth.Repository) *JWT {
+	return &JWT{
+		method:     method,
+		signKey:    signKey,
+		verifyKey:  verifyKey,
+		expiration: expiration,
+		repo:       repo,
+	}
+}
+
+// NewJWTWithGlobal returns a new *JWT with the keys of the global configuration
+func NewJWTWithGlobal(repo auth.Repository) (*JWT, error) {
+	algorithm := viper.GetString(config.AuthJWTAlgorithmKey)
+	method := jwt.GetSigningMethod(algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("jwt algorithm %s is not supported", algorithm)
+	}
+	expiration := time.Duration(viper.GetInt(config.AuthJWTExpirationKey)) * time.Second
+
+	if strings.HasPrefix(algorithm, "HS") {
+		secret := viper.GetString(config.AuthJWTSecretKey)
+		if secret == constant.EmptyString {
+			return nil, errors.New("jwt secret is not configured")
+		}
+
+		return NewJWT(method, []byte(secret), []byte(secret), expiration, repo), nil
+	}
+
+	publicKeyFile := viper.GetString(config.AuthJWTPublicKeyKey)
+	if publicKeyFile == constant.EmptyString {
+		return nil, errors.New("jwt public key is not configured")
+	}
+	data, err := ioutil.ReadFile(publicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	verifyKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	j := NewJWT(method, nil, verifyKey, expiration, repo)
+
+	privateKeyFile := viper.GetString(config.AuthJWTPrivateKeyKey)
+	if privateKeyFile != constant.EmptyString {
+		data, err = ioutil.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		j.signKey, err = jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return j, nil
+}
+
+// Sign issues a json web token to the user, it returns the token, the token id and the expire time
+func (j *JWT) Sign(accountName string) (string, string, time.Time, error) {
+	if j.signKey == nil {
+		return constant.EmptyString, constant.EmptyString, time.Time{}, errors.New("jwt private key is not configured, could not issue json web tokens")
+	}
+
+	id, err := newJWTID()
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, time.Time{}, err
+	}
+	now := time.Now()
+	expireTime := now.Add(j.expiration)
+	token, err := jwt.NewWithClaims(j.method, &jwt.RegisteredClaims{
+		ID:        id,
+		Issuer:    config.DefaultCommandName,
+		Subject:   accountName,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expireTime),
+	}).SignedString(j.signKey)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, time.Time{}, err
+	}
+
+	return token, id, expireTime, nil
+}
+
+// Supports returns if the token consists of 3 parts like json web tokens
+func (j *JWT) Supports(token string) bool {
+	return isJWT(token)
+}
+
+// Authenticate verifies the signature and expire time of the token, and checks if the token was revoked,
+// only the ids of the tokens issued by das are saved, so the tokens issued by the others could not be revoked
+func (j *JWT) Authenticate(ctx context.Context, token string) (string, error) {
+	claims, err := j.verify(token)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	if !isIssuedByDAS(claims) {
+		return claims.Subject, nil
+	}
+
+	accountName, err := j.repo.GetAccountName(ctx, TokenTypeJWT, claims.ID)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	if accountName != claims.Subject {
+		return constant.EmptyString, ErrNotValidToken
+	}
+
+	return accountName, nil
+}
+
+// verify verifies the signature and expire time of the token and returns the claims
+func (j *JWT) verify(token string) (*jwt.RegisteredClaims, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		// the algorithm must be checked, otherwise the token could be signed with the public key as a hmac secret
+		if t.Method.Alg() != j.method.Alg() {
+			return nil, fmt.Errorf("jwt algorithm %s is not expected", t.Method.Alg())
+		}
+
+		return j.verifyKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == constant.EmptyString || (isIssuedByDAS(claims) && claims.ID == constant.EmptyString) {
+		return nil, ErrNotValidToken
+	}
+
+	return claims, nil
+}
+
+// isIssuedByDAS returns if the token was issued by das
+func isIssuedByDAS(claims *jwt.RegisteredClaims) bool {
+	return claims.Issuer == config.DefaultCommandName
+}
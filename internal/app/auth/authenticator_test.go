@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/dependency/auth"
+)
+
+const (
+	testAccountName = "admin"
+	testJWTSecret   = "0123456789abcdef0123456789abcdef"
+	testStaticToken = "static-token-0123456789abcdef0123"
+)
+
+// testRepository keeps the tokens in memory, it maps the token keys to the account names
+type testRepository struct {
+	accountNames map[string]string
+}
+
+func (tr *testRepository) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	return nil, nil
+}
+
+func (tr *testRepository) Create(ctx context.Context, token auth.Token) (auth.Token, error) {
+	tr.accountNames[token.GetTokenKey()] = testAccountName
+	return token, nil
+}
+
+func (tr *testRepository) GetByKey(ctx context.Context, tokenKey string) (auth.Token, error) {
+	return nil, ErrNotValidToken
+}
+
+func (tr *testRepository) GetByUserID(ctx context.Context, userID int) ([]auth.Token, error) {
+	return nil, nil
+}
+
+func (tr *testRepository) GetAccountName(ctx context.Context, tokenType int, tokenKey string) (string, error) {
+	accountName, ok := tr.accountNames[tokenKey]
+	if !ok {
+		return constant.EmptyString, ErrNotValidToken
+	}
+
+	return accountName, nil
+}
+
+func (tr *testRepository) Revoke(ctx context.Context, userID, id int) error {
+	return nil
+}
+
+// IsOwner returns true if the identity of the user and resource are the same
+func (tr *testRepository) IsOwner(ctx context.Context, resource string, userID int, resourceID string) (bool, error) {
+	return strconv.Itoa(userID) == resourceID, nil
+}
+
+func newTestRepository() *testRepository {
+	return &testRepository{accountNames: make(map[string]string)}
+}
+
+func TestAuthenticatorAll(t *testing.T) {
+	TestAuthenticator_Static(t)
+	TestAuthenticator_APIToken(t)
+	TestAuthenticator_JWT(t)
+	TestAuthenticator_JWTIssuer(t)
+	TestAuthenticator_Chain(t)
+}
+
+func TestAuthenticator_Static(t *testing.T) {
+	asst := assert.New(t)
+
+	sa := NewStaticAuthenticator(map[string]string{testStaticToken: testAccountName})
+	accountName, err := sa.Authenticate(context.Background(), testStaticToken)
+	asst.Nil(err, common.CombineMessageWithError("test Authenticate() failed", err))
+	asst.Equal(testAccountName, accountName, "test Authenticate() failed")
+	_, err = sa.Authenticate(context.Background(), "wrong")
+	asst.Equal(ErrNotValidToken, err, "test Authenticate() failed")
+	asst.True(sa.Supports(testStaticToken), "test Supports() failed")
+	asst.False(NewStaticAuthenticator(map[string]string{}).Supports(testStaticToken), "test Supports() failed")
+	// the tokens of the other authenticators are not supported
+	asst.False(sa.Supports(apiTokenPrefix+testStaticToken), "test Supports() failed")
+	asst.False(sa.Supports("header.payload.signature"), "test Supports() failed")
+}
+
+func TestAuthenticator_APIToken(t *testing.T) {
+	asst := assert.New(t)
+
+	repo := newTestRepository()
+	token, key, err := newAPIToken()
+	asst.Nil(err, common.CombineMessageWithError("test newAPIToken() failed", err))
+	asst.True(strings.HasPrefix(token, apiTokenPrefix), "test newAPIToken() failed")
+	_, err = repo.Create(context.Background(), NewTokenInfo(1, TokenTypeAPI, key, constant.EmptyString, time.Now().Add(time.Hour)))
+	asst.Nil(err, common.CombineMessageWithError("test Create() failed", err))
+
+	aa := NewAPITokenAuthenticator(repo)
+	asst.True(aa.Supports(token), "test Supports() failed")
+	accountName, err := aa.Authenticate(context.Background(), token)
+	asst.Nil(err, common.CombineMessageWithError("test Authenticate() failed", err))
+	asst.Equal(testAccountName, accountName, "test Authenticate() failed")
+	_, err = aa.Authenticate(context.Background(), apiTokenPrefix+"wrong")
+	asst.Equal(ErrNotValidToken, err, "test Authenticate() failed")
+}
+
+func TestAuthenticator_JWT(t *testing.T) {
+	asst := assert.New(t)
+
+	repo := newTestRepository()
+	j := NewJWT(jwt.SigningMethodHS256, []byte(testJWTSecret), []byte(testJWTSecret), time.Hour, repo)
+	token, id, expireTime, err := j.Sign(testAccountName)
+	asst.Nil(err, common.CombineMessageWithError("test Sign() failed", err))
+	asst.True(expireTime.After(time.Now()), "test Sign() failed")
+	asst.True(j.Supports(token), "test Supports() failed")
+
+	// the token is not valid until it is saved
+	_, err = j.Authenticate(context.Background(), token)
+	asst.Equal(ErrNotValidToken, err, "test Authenticate() failed")
+	_, err = repo.Create(context.Background(), NewTokenInfo(1, TokenTypeJWT, id, loginDescription, expireTime))
+	asst.Nil(err, common.CombineMessageWithError("test Create() failed", err))
+	accountName, err := j.Authenticate(context.Background(), token)
+	asst.Nil(err, common.CombineMessageWithError("test Authenticate() failed", err))
+	asst.Equal(testAccountName, accountName, "test Authenticate() failed")
+
+	// tokens signed with other secrets or algorithms are not valid
+	other := NewJWT(jwt.SigningMethodHS512, []byte(testJWTSecret), []byte(testJWTSecret), time.Hour, repo)
+	token, _, _, err = other.Sign(testAccountName)
+	asst.Nil(err, common.CombineMessageWithError("test Sign() failed", err))
+	_, err = j.Authenticate(context.Background(), token)
+	asst.NotNil(err, "test Authenticate() failed")
+	// expired tokens are not valid
+	expired := NewJWT(jwt.SigningMethodHS256, []byte(testJWTSecret), []byte(testJWTSecret), -time.Hour, repo)
+	token, id, expireTime, err = expired.Sign(testAccountName)
+	asst.Nil(err, common.CombineMessageWithError("test Sign() failed", err))
+	_, _ = repo.Create(context.Background(), NewTokenInfo(1, TokenTypeJWT, id, loginDescription, expireTime))
+	_, err = j.Authenticate(context.Background(), token)
+	asst.NotNil(err, "test Authenticate() failed")
+	// tokens could not be issued without the private key
+	_, _, _, err = NewJWT(jwt.SigningMethodRS256, nil, nil, time.Hour, repo).Sign(testAccountName)
+	asst.NotNil(err, "test Sign() failed")
+}
+
+func TestAuthenticator_JWTIssuer(t *testing.T) {
+	asst := assert.New(t)
+
+	repo := newTestRepository()
+	j := NewJWT(jwt.SigningMethodHS256, []byte(testJWTSecret), []byte(testJWTSecret), time.Hour, repo)
+	expireTime := jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+	// the tokens issued by the others are not looked up in the middleware
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.RegisteredClaims{
+		Issuer:    "sso",
+		Subject:   testAccountName,
+		ExpiresAt: expireTime,
+	}).SignedString([]byte(testJWTSecret))
+	asst.Nil(err, common.CombineMessageWithError("test Authenticate() failed", err))
+	accountName, err := j.Authenticate(context.Background(), token)
+	asst.Nil(err, common.CombineMessageWithError("test Authenticate() failed", err))
+	asst.Equal(testAccountName, accountName, "test Authenticate() failed")
+	// the tokens issued by das must have the ids which are saved in the middleware
+	token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.RegisteredClaims{
+		Issuer:    config.DefaultCommandName,
+		Subject:   testAccountName,
+		ExpiresAt: expireTime,
+	}).SignedString([]byte(testJWTSecret))
+	asst.Nil(err, common.CombineMessageWithError("test Authenticate() failed", err))
+	_, err = j.Authenticate(context.Background(), token)
+	asst.Equal(ErrNotValidToken, err, "test Authenticate() failed")
+	token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.RegisteredClaims{
+		ID:        "revoked",
+		Issuer:    config.DefaultCommandName,
+		Subject:   testAccountName,
+		ExpiresAt: expireTime,
+	}).SignedString([]byte(testJWTSecret))
+	asst.Nil(err, common.CombineMessageWithError("test Authenticate() failed", err))
+	_, err = j.Authenticate(context.Background(), token)
+	asst.Equal(ErrNotValidToken, err, "test Authenticate() failed")
+}
+
+func TestAuthenticator_Chain(t *testing.T) {
+	asst := assert.New(t)
+
+	repo := newTestRepository()
+	chain := Chain{
+		NewAPITokenAuthenticator(repo),
+		NewJWT(jwt.SigningMethodHS256, []byte(testJWTSecret), []byte(testJWTSecret), time.Hour, repo),
+		NewStaticAuthenticator(map[string]string{testStaticToken: testAccountName}),
+	}
+	_, err := chain.Authenticate(context.Background(), constant.EmptyString)
+	asst.Equal(ErrMissingToken, err, "test Authenticate() failed")
+	accountName, err := chain.Authenticate(context.Background(), testStaticToken)
+	asst.Nil(err, common.CombineMessageWithError("test Authenticate() failed", err))
+	asst.Equal(testAccountName, accountName, "test Authenticate() failed")
+	// the api token is authenticated by the api token authenticator only
+	_, err = chain.Authenticate(context.Background(), apiTokenPrefix+testStaticToken)
+	asst.Equal(ErrNotValidToken, err, "test Authenticate() failed")
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/romberli/go-util/constant"
+
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/auth"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+)
+
+var _ auth.Authorizer = (*Authorizer)(nil)
+
+// Authorizer authorizes the users by their roles in the user metadata and the ownership of the apps and dbs
+type Authorizer struct {
+	policy   Policy
+	repo     auth.Repository
+	userRepo depmeta.UserRepo
+}
+
+// NewAuthorizer returns a new *Authorizer
+func NewAuthorizer(policy Policy, repo auth.Repository, userRepo depmeta.UserRepo) *Authorizer {
+	return &Authorizer{
+		policy:   policy,
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// NewAuthorizerWithDefault returns a new *Authorizer with the default policy and repositories
+func NewAuthorizerWithDefault() *Authorizer {
+	return NewAuthorizer(DefaultPolicy, NewRepositoryWithGlobal(), metadata.NewUserRepoWithGlobal())
+}
+
+// Authorize returns a *ForbiddenError if the user is not allowed to access the route,
+// developers must also own the resource if the rule has an ownership rule,
+// path is the full path of the route as it is registered, params are the path parameters of the request
+func (a *Authorizer) Authorize(ctx context.Context, accountName, method, path string, params map[string]string) error {
+	forbidden := func(reason string) error {
+		return &ForbiddenError{AccountName: accountName, Method: method, Path: path, Reason: reason}
+	}
+
+	user, err := a.userRepo.GetByAccountName(ctx, accountName)
+	if err != nil {
+		return forbidden("the user does not exist in the user metadata")
+	}
+
+	rule := a.policy.GetRule(method, path)
+	if !rule.Allow(user.GetRole()) {
+		return forbidden(fmt.Sprintf("role %s is not allowed", getRoleName(user.GetRole())))
+	}
+	if user.GetRole() != RoleDeveloper || rule.Resource == constant.EmptyString {
+		return nil
+	}
+
+	resourceID := params[rule.Param]
+	if resourceID == constant.EmptyString {
+		return forbidden(fmt.Sprintf("%s id is not valid", rule.Resource))
+	}
+	owned, err := a.repo.IsOwner(ctx, rule.Resource, user.Identity(), resourceID)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return forbidden(fmt.Sprintf("developers could only access the %s they own. %s id: %s", rule.Resource, rule.Resource, resourceID))
+	}
+
+	return nil
+}
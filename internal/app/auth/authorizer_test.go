@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/internal/app/metadata"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+)
+
+// testUserRepo keeps the users in memory, only GetByAccountName is implemented
+type testUserRepo struct {
+	depmeta.UserRepo
+	users map[string]depmeta.User
+}
+
+func (tur *testUserRepo) GetByAccountName(ctx context.Context, accountName string) (depmeta.User, error) {
+	user, ok := tur.users[accountName]
+	if !ok {
+		return nil, errors.New("user does not exist")
+	}
+
+	return user, nil
+}
+
+func newTestAuthorizer() *Authorizer {
+	userRepo := &testUserRepo{users: map[string]depmeta.User{
+		"admin":     &metadata.UserInfo{ID: 1, AccountName: "admin", Role: RoleAdmin},
+		"dba":       &metadata.UserInfo{ID: 2, AccountName: "dba", Role: RoleDBA},
+		"developer": &metadata.UserInfo{ID: 3, AccountName: "developer", Role: RoleDeveloper},
+	}}
+
+	return NewAuthorizer(DefaultPolicy, newTestRepository(), userRepo)
+}
+
+func TestAuthorizerAll(t *testing.T) {
+	TestPolicy_GetRule(t)
+	TestAuthorizer_Authorize(t)
+}
+
+func TestPolicy_GetRule(t *testing.T) {
+	asst := assert.New(t)
+
+	rule := DefaultPolicy.GetRule(http.MethodPost, "/api/v1/metadata/user/update/:id")
+	asst.Equal(adminOnly, rule.Roles, "test GetRule() failed")
+	rule = DefaultPolicy.GetRule(http.MethodPost, "/api/v1/metadata/app/update/:id")
+	asst.Equal(adminDBA, rule.Roles, "test GetRule() failed")
+	rule = DefaultPolicy.GetRule(http.MethodPost, "/api/v1/review/submit/:db_id")
+	asst.Equal(ResourceDB, rule.Resource, "test GetRule() failed")
+	rule = DefaultPolicy.GetRule(http.MethodGet, "/api/v1/review/get/:id")
+	asst.Equal(ResourceReview, rule.Resource, "test GetRule() failed")
+	rule = DefaultPolicy.GetRule(http.MethodGet, "/api/v1/sqladvisor/advice/:sql_id")
+	asst.Equal(ResourceAdvice, rule.Resource, "test GetRule() failed")
+	rule = DefaultPolicy.GetRule(http.MethodGet, "/api/v1/sqladvisor/fingerprint")
+	asst.Equal(allRoles, rule.Roles, "test GetRule() failed")
+	asst.Empty(rule.Resource, "test GetRule() failed")
+	// the routes which are not in the policy are restricted
+	rule = DefaultPolicy.GetRule(http.MethodGet, "/api/v1/not-exists")
+	asst.Equal(adminDBA, rule.Roles, "test GetRule() failed")
+	rule = DefaultPolicy.GetRule(http.MethodPost, "/api/v1/not-exists")
+	asst.Equal(adminOnly, rule.Roles, "test GetRule() failed")
+	// v2
+	rule = DefaultPolicy.GetRule(http.MethodPatch, "/api/v2/metadata/users/:id")
+	asst.Equal(adminOnly, rule.Roles, "test GetRule() failed")
+	rule = DefaultPolicy.GetRule(http.MethodDelete, "/api/v2/metadata/apps/:id")
+	asst.Equal(adminDBA, rule.Roles, "test GetRule() failed")
+	rule = DefaultPolicy.GetRule(http.MethodGet, "/api/v2/metadata/apps/:id/dbs")
+	asst.Equal(ResourceApp, rule.Resource, "test GetRule() failed")
+	rule = DefaultPolicy.GetRule(http.MethodGet, "/api/v2/metadata/users")
+	asst.Equal(adminDBA, rule.Roles, "test GetRule() failed")
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	asst := assert.New(t)
+
+	a := newTestAuthorizer()
+	// roles
+	err := a.Authorize(context.Background(), "admin", http.MethodPost, "/api/v1/metadata/user", nil)
+	asst.Nil(err, common.CombineMessageWithError("test Authorize() failed", err))
+	err = a.Authorize(context.Background(), "dba", http.MethodPost, "/api/v1/metadata/user", nil)
+	asst.IsType(&ForbiddenError{}, err, "test Authorize() failed")
+	err = a.Authorize(context.Background(), "dba", http.MethodPost, "/api/v1/healthcheck/check", nil)
+	asst.Nil(err, common.CombineMessageWithError("test Authorize() failed", err))
+	err = a.Authorize(context.Background(), "developer", http.MethodPost, "/api/v1/healthcheck/check", nil)
+	asst.IsType(&ForbiddenError{}, err, "test Authorize() failed")
+	err = a.Authorize(context.Background(), "nobody", http.MethodGet, "/api/v1/metadata/env", nil)
+	asst.IsType(&ForbiddenError{}, err, "test Authorize() failed")
+	// ownership, the test repository treats the resources which have the same identity as the user as owned
+	err = a.Authorize(context.Background(), "developer", http.MethodPost, "/api/v1/sqladvisor/advise/:db_id", map[string]string{"db_id": "3"})
+	asst.Nil(err, common.CombineMessageWithError("test Authorize() failed", err))
+	err = a.Authorize(context.Background(), "developer", http.MethodPost, "/api/v1/sqladvisor/advise/:db_id", map[string]string{"db_id": "4"})
+	asst.IsType(&ForbiddenError{}, err, "test Authorize() failed")
+	err = a.Authorize(context.Background(), "dba", http.MethodPost, "/api/v1/sqladvisor/advise/:db_id", map[string]string{"db_id": "4"})
+	asst.Nil(err, common.CombineMessageWithError("test Authorize() failed", err))
+	err = a.Authorize(context.Background(), "developer", http.MethodGet, "/api/v1/review/get/:id", map[string]string{"id": "3"})
+	asst.Nil(err, common.CombineMessageWithError("test Authorize() failed", err))
+	err = a.Authorize(context.Background(), "developer", http.MethodGet, "/api/v1/review/get/:id", map[string]string{"id": "4"})
+	asst.IsType(&ForbiddenError{}, err, "test Authorize() failed")
+	err = a.Authorize(context.Background(), "developer", http.MethodGet, "/api/v1/sqladvisor/advice/:sql_id", map[string]string{"sql_id": "ABCDEF0123456789"})
+	asst.IsType(&ForbiddenError{}, err, "test Authorize() failed")
+	err = a.Authorize(context.Background(), "developer", http.MethodGet, "/api/v1/sqladvisor/advice/:sql_id", map[string]string{})
+	asst.IsType(&ForbiddenError{}, err, "test Authorize() failed")
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/romberli/go-util/constant"
+	"github.com/spf13/viper"
+
+	"github.com/romberli/das/config"
+)
+
+const (
+	ldapScheme  = "ldap://"
+	ldapsScheme = "ldaps://"
+	// ldapSpecialChars are the characters which must be escaped in the attribute values of the dn, see rfc 4514
+	ldapSpecialChars = `,+"\<>;=`
+)
+
+// ErrInvalidCredentials is returned when the account name or password is wrong
+var ErrInvalidCredentials = errors.New("account name or password is not valid")
+
+// LDAP verifies the passwords of the users by binding to the ldap server
+type LDAP struct {
+	addr    string
+	useTLS  bool
+	userDN  string
+	timeout time.Duration
+}
+
+// NewLDAP returns a new *LDAP, %s in the user dn will be replaced with the account name
+func NewLDAP(addr string, useTLS bool, userDN string, timeout time.Duration) *LDAP {
+	return &LDAP{
+		addr:    addr,
+		useTLS:  useTLS,
+		userDN:  userDN,
+		timeout: timeout,
+	}
+}
+
+// NewLDAPWithGlobal returns a new *LDAP with the global configuration
+func NewLDAPWithGlobal() *LDAP {
+	return NewLDAP(
+		viper.GetString(config.AuthLDAPAddrKey),
+		viper.GetBool(config.AuthLDAPTLSKey),
+		viper.GetString(config.AuthLDAPUserDNKey),
+		time.Duration(viper.GetInt(config.AuthLDAPTimeoutKey))*time.Second,
+	)
+}
+
+// IsEnabled returns if the ldap server is configured
+func (l *LDAP) IsEnabled() bool {
+	return l.addr != constant.EmptyString
+}
+
+// Bind binds to the ldap server as the user, it returns ErrInvalidCredentials if the password is wrong
+func (l *LDAP) Bind(accountName, password string) error {
+	if !l.IsEnabled() {
+		return errors.New("ldap is not configured")
+	}
+	// ldap servers treat the simple bind with empty password as an anonymous bind which always succeeds
+	if accountName == constant.EmptyString || password == constant.EmptyString {
+		return ErrInvalidCredentials
+	}
+
+	conn, err := l.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	conn.SetTimeout(l.timeout)
+	err = conn.Bind(fmt.Sprintf(l.userDN, escapeDN(accountName)), password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return ErrInvalidCredentials
+	}
+
+	return err
+}
+
+// dial connects to the ldap server
+func (l *LDAP) dial() (*ldap.Conn, error) {
+	dialer := &net.Dialer{Timeout: l.timeout}
+	if !l.useTLS {
+		return ldap.DialURL(ldapScheme+l.addr, ldap.DialWithDialer(dialer))
+	}
+
+	return ldap.DialURL(ldapsScheme+l.addr, ldap.DialWithDialer(dialer))
+}
+
+// escapeDN escapes the special characters of the attribute value of the dn
+func escapeDN(value string) string {
+	var sb strings.Builder
+	for i, r := range value {
+		if strings.ContainsRune(ldapSpecialChars, r) ||
+			(i == 0 && (r == ' ' || r == '#')) ||
+			(i == len(value)-1 && r == ' ') {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
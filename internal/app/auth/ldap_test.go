@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testLDAPUserDN   = "uid=%s,ou=people,dc=example,dc=com"
+	testLDAPAccount  = "admin"
+	testLDAPPassword = "secret"
+)
+
+// startTestLDAPServer starts a fake ldap server which accepts only the test account and password
+func startTestLDAPServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("start test ldap server failed. %s", err.Error())
+	}
+
+	go func() {
+		defer func() { _ = listener.Close() }()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestLDAPConn(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// serveTestLDAPConn responds the bind requests of the connection until it is closed
+func serveTestLDAPConn(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	for {
+		request, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		if len(request.Children) < 2 || request.Children[1].Tag != ldap.ApplicationBindRequest {
+			// the unbind request is sent before the client closes the connection
+			continue
+		}
+
+		bindRequest := request.Children[1]
+		resultCode := ldap.LDAPResultInvalidCredentials
+		if bindRequest.Children[1].Value == "uid=admin,ou=people,dc=example,dc=com" &&
+			bindRequest.Children[2].Data.String() == testLDAPPassword {
+			resultCode = ldap.LDAPResultSuccess
+		}
+
+		response := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+		response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, request.Children[0].Value, "MessageID"))
+		bindResponse := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationBindResponse, nil, "Bind Response")
+		bindResponse.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, resultCode, "Result Code"))
+		bindResponse.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+		bindResponse.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+		response.AppendChild(bindResponse)
+		_, err = conn.Write(response.Bytes())
+		if err != nil {
+			return
+		}
+	}
+}
+
+func TestLDAPAll(t *testing.T) {
+	TestLDAP_Bind(t)
+	TestLDAP_escapeDN(t)
+}
+
+func TestLDAP_Bind(t *testing.T) {
+	asst := assert.New(t)
+
+	l := NewLDAP(startTestLDAPServer(t), false, testLDAPUserDN, time.Second)
+	err := l.Bind(testLDAPAccount, testLDAPPassword)
+	asst.Nil(err, common.CombineMessageWithError("test Bind() failed", err))
+	err = l.Bind(testLDAPAccount, "wrong")
+	asst.Equal(ErrInvalidCredentials, err, "test Bind() failed")
+	// empty password must not be sent to the ldap server, it is an anonymous bind
+	err = l.Bind(testLDAPAccount, "")
+	asst.Equal(ErrInvalidCredentials, err, "test Bind() failed")
+	err = NewLDAP("", false, testLDAPUserDN, time.Second).Bind(testLDAPAccount, testLDAPPassword)
+	asst.NotNil(err, "test Bind() failed")
+}
+
+func TestLDAP_escapeDN(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Equal("admin", escapeDN("admin"), "test escapeDN() failed")
+	asst.Equal(`admin\,ou\=admins`, escapeDN("admin,ou=admins"), "test escapeDN() failed")
+	asst.Equal(`\#admin\ `, escapeDN("#admin "), "test escapeDN() failed")
+}
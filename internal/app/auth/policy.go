@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	RoleAdmin     = 1
+	RoleDBA       = 2
+	RoleDeveloper = 3
+
+	ResourceApp    = "app"
+	ResourceDB     = "db"
+	ResourceReview = "review"
+	// ResourceAdvice is the slow query advices of a sql id, they are owned by the owners of their dbs
+	ResourceAdvice = "advice"
+
+	// wildcard at the end of the path of the rule matches any path with the prefix
+	wildcard   = "*"
+	apiV1Group = "/api/v1"
+	apiV2Group = "/api/v2"
+)
+
+var (
+	allRoles  = []int{RoleAdmin, RoleDBA, RoleDeveloper}
+	adminDBA  = []int{RoleAdmin, RoleDBA}
+	adminOnly = []int{RoleAdmin}
+	roleNames = map[int]string{RoleAdmin: "admin", RoleDBA: "dba", RoleDeveloper: "developer"}
+)
+
+// DefaultPolicy is the authorization policy of the http api, the rules are matched in order.
+// the routes which are not matched by any rule could be read by admins and dbas, and could be changed by admins only,
+// so the new apis, such as config changes, are restricted by default
+var DefaultPolicy = Policy{
+	// users and bulk imports could grant roles, so only admins could change them
+	{Method: http.MethodPost, Path: apiV1Group + "/metadata/user*", Roles: adminOnly},
+	{Method: http.MethodPost, Path: apiV1Group + "/metadata/import", Roles: adminOnly},
+	{Method: http.MethodPost, Path: apiV1Group + "/metadata/reconcile", Roles: adminOnly},
+	{Method: http.MethodPost, Path: apiV1Group + "/metadata/*", Roles: adminDBA},
+	// developers could only view the apps and dbs they own
+	{Method: http.MethodGet, Path: apiV1Group + "/metadata/app/get/:id", Roles: allRoles, Resource: ResourceApp, Param: "id"},
+	{Method: http.MethodGet, Path: apiV1Group + "/metadata/app/dbs/:id", Roles: allRoles, Resource: ResourceApp, Param: "id"},
+	{Method: http.MethodGet, Path: apiV1Group + "/metadata/db/get/:id", Roles: allRoles, Resource: ResourceDB, Param: "id"},
+	{Method: http.MethodGet, Path: apiV1Group + "/metadata/db/apps/:id", Roles: allRoles, Resource: ResourceDB, Param: "id"},
+	{Method: http.MethodGet, Path: apiV1Group + "/metadata/env*", Roles: allRoles},
+	// only admins and dbas could trigger the health checks
+	{Method: http.MethodPost, Path: apiV1Group + "/healthcheck/*", Roles: adminDBA},
+	// developers could only advise and review the sqls of the dbs they own, and view the advices and reviews of them
+	{Method: http.MethodPost, Path: apiV1Group + "/sqladvisor/advise/:db_id", Roles: allRoles, Resource: ResourceDB, Param: "db_id"},
+	{Method: http.MethodPost, Path: apiV1Group + "/sqladvisor/fingerprint", Roles: allRoles},
+	{Method: http.MethodPost, Path: apiV1Group + "/sqladvisor/sql-id", Roles: allRoles},
+	{Method: http.MethodGet, Path: apiV1Group + "/sqladvisor/fingerprint", Roles: allRoles},
+	{Method: http.MethodGet, Path: apiV1Group + "/sqladvisor/sql-id", Roles: allRoles},
+	{Method: http.MethodGet, Path: apiV1Group + "/sqladvisor/advice/:sql_id", Roles: allRoles, Resource: ResourceAdvice, Param: "sql_id"},
+	{Method: http.MethodPost, Path: apiV1Group + "/review/submit/:db_id", Roles: allRoles, Resource: ResourceDB, Param: "db_id"},
+	{Method: http.MethodGet, Path: apiV1Group + "/review/get/:id", Roles: allRoles, Resource: ResourceReview, Param: "id"},
+	{Method: http.MethodPost, Path: apiV1Group + "/review/approve/:id", Roles: adminDBA},
+	// discovery and monitor sync change the metadata
+	{Method: http.MethodPost, Path: apiV1Group + "/discovery/*", Roles: adminDBA},
+	{Method: http.MethodPost, Path: apiV1Group + "/monitorsync/*", Roles: adminDBA},
+	// the v2 metadata api follows the same rules as v1, the methods of the changes are post, put, patch and delete
+	{Method: http.MethodGet, Path: apiV2Group + "/metadata/apps/:id", Roles: allRoles, Resource: ResourceApp, Param: "id"},
+	{Method: http.MethodGet, Path: apiV2Group + "/metadata/apps/:id/dbs", Roles: allRoles, Resource: ResourceApp, Param: "id"},
+	{Method: http.MethodGet, Path: apiV2Group + "/metadata/dbs/:id", Roles: allRoles, Resource: ResourceDB, Param: "id"},
+	{Method: http.MethodGet, Path: apiV2Group + "/metadata/dbs/:id/apps", Roles: allRoles, Resource: ResourceDB, Param: "id"},
+	{Method: http.MethodGet, Path: apiV2Group + "/metadata/envs*", Roles: allRoles},
+	{Method: http.MethodGet, Path: apiV2Group + "/metadata/*", Roles: adminDBA},
+	{Path: apiV2Group + "/metadata/users*", Roles: adminOnly},
+	{Path: apiV2Group + "/metadata/*", Roles: adminDBA},
+}
+
+// Rule is the authorization rule of the routes
+type Rule struct {
+	// Method is the http method of the routes, empty means any method
+	Method string
+	// Path is the full path of the routes as they are registered, it could end with a wildcard
+	Path string
+	// Roles are the roles which could access the routes
+	Roles []int
+	// Resource is the type of the resource which developers must own, empty means there is no ownership rule
+	Resource string
+	// Param is the path parameter which contains the identity of the resource
+	Param string
+}
+
+// Match returns if the rule matches the route
+func (r *Rule) Match(method, path string) bool {
+	if r.Method != constant.EmptyString && r.Method != method {
+		return false
+	}
+	if strings.HasSuffix(r.Path, wildcard) {
+		return strings.HasPrefix(path, strings.TrimSuffix(r.Path, wildcard))
+	}
+
+	return r.Path == path
+}
+
+// Allow returns if the role could access the routes of the rule
+func (r *Rule) Allow(role int) bool {
+	for _, allowed := range r.Roles {
+		if allowed == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Policy is an ordered list of the rules
+type Policy []*Rule
+
+// GetRule returns the first rule which matches the route,
+// it returns a rule which allows admins and dbas to read and admins to write if no rule matches
+func (p Policy) GetRule(method, path string) *Rule {
+	for _, rule := range p {
+		if rule.Match(method, path) {
+			return rule
+		}
+	}
+
+	if method == http.MethodGet {
+		return &Rule{Method: method, Path: path, Roles: adminDBA}
+	}
+
+	return &Rule{Method: method, Path: path, Roles: adminOnly}
+}
+
+// ForbiddenError is returned when the user is not allowed to access the route
+type ForbiddenError struct {
+	AccountName string
+	Method      string
+	Path        string
+	Reason      string
+}
+
+// Error implements error interface
+func (fe *ForbiddenError) Error() string {
+	return fmt.Sprintf("user %s is not allowed to access %s %s, %s", fe.AccountName, fe.Method, fe.Path, fe.Reason)
+}
+
+// getRoleName returns the name of the role
+func getRoleName(role int) string {
+	name, ok := roleNames[role]
+	if !ok {
+		return fmt.Sprintf("unknown(%d)", role)
+	}
+
+	return name
+}
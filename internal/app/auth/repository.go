@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/dependency/auth"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+// ErrNotValidToken is returned when the token does not exist, was revoked or expired
+var ErrNotValidToken = errors.New("token does not exist, was revoked or expired")
+
+var _ auth.Repository = (*Repository)(nil)
+
+type Repository struct {
+	Database middleware.Pool
+}
+
+// NewRepository returns *Repository with given middleware.Pool
+func NewRepository(db middleware.Pool) *Repository {
+	return &Repository{Database: db}
+}
+
+// NewRepositoryWithGlobal returns *Repository with global mysql pool
+func NewRepositoryWithGlobal() *Repository {
+	return NewRepository(global.DASMySQLPool)
+}
+
+// Execute executes given command and placeholders on the middleware
+func (r *Repository) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
+	conn, err := r.Database.Get()
+	if err != nil {
+		tracing.EndSpan(span, err)
+		return nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("auth Repository.Execute(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
+}
+
+// Create creates a token in the middleware
+func (r *Repository) Create(ctx context.Context, token auth.Token) (auth.Token, error) {
+	sql := `
+		insert into t_auth_token_info(user_id, token_type, token_key, description, expire_time)
+		values(?, ?, ?, ?, ?);
+	`
+	tracing.Logger(ctx).Debugf("auth Repository.Create() insert sql: \n%s\nplaceholders: %d, %d, %s, %s",
+		sql, token.GetUserID(), token.GetTokenType(), token.GetDescription(), token.GetExpireTime())
+	_, err := r.Execute(ctx, sql, token.GetUserID(), token.GetTokenType(), token.GetTokenKey(), token.GetDescription(), token.GetExpireTime())
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByKey(ctx, token.GetTokenKey())
+}
+
+// GetByKey gets the token of given key from the middleware
+func (r *Repository) GetByKey(ctx context.Context, tokenKey string) (auth.Token, error) {
+	sql := `
+		select id, user_id, token_type, token_key, description, expire_time, del_flag, create_time, last_update_time
+		from t_auth_token_info
+		where token_key = ?;
+	`
+	tracing.Logger(ctx).Debugf("auth Repository.GetByKey() sql: \n%s", sql)
+	result, err := r.Execute(ctx, sql, tokenKey)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowNumber() == constant.ZeroInt {
+		return nil, ErrNotValidToken
+	}
+
+	tokenInfo := NewEmptyTokenInfo()
+	err = result.MapToStructByRowIndex(tokenInfo, constant.ZeroInt, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenInfo, nil
+}
+
+// GetByUserID gets the tokens of the user from the middleware, the revoked and expired tokens are also returned
+func (r *Repository) GetByUserID(ctx context.Context, userID int) ([]auth.Token, error) {
+	sql := `
+		select id, user_id, token_type, token_key, description, expire_time, del_flag, create_time, last_update_time
+		from t_auth_token_info
+		where user_id = ?
+		order by id desc;
+	`
+	tracing.Logger(ctx).Debugf("auth Repository.GetByUserID() sql: \n%s\nplaceholders: %d", sql, userID)
+	result, err := r.Execute(ctx, sql, userID)
+	if err != nil {
+		return nil, err
+	}
+	// init []*TokenInfo
+	tokenInfoList := make([]*TokenInfo, result.RowNumber())
+	for i := range tokenInfoList {
+		tokenInfoList[i] = NewEmptyTokenInfo()
+	}
+	// map to struct
+	err = result.MapToStructSlice(tokenInfoList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []auth.Token
+	tokens := make([]auth.Token, result.RowNumber())
+	for i := range tokens {
+		tokens[i] = tokenInfoList[i]
+	}
+
+	return tokens, nil
+}
+
+// GetAccountName returns the account name of the user who owns the token of given type and key,
+// it returns error if the token does not exist, was revoked or expired, or the user was deleted
+func (r *Repository) GetAccountName(ctx context.Context, tokenType int, tokenKey string) (string, error) {
+	sql := `
+		select ui.account_name
+		from t_auth_token_info ti
+			inner join t_meta_user_info ui on ti.user_id = ui.id
+		where ti.del_flag = 0
+		and ui.del_flag = 0
+		and ti.token_type = ?
+		and ti.token_key = ?
+		and ti.expire_time > now(6);
+	`
+	tracing.Logger(ctx).Debugf("auth Repository.GetAccountName() sql: \n%s\nplaceholders: %d", sql, tokenType)
+	result, err := r.Execute(ctx, sql, tokenType, tokenKey)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	if result.RowNumber() == constant.ZeroInt {
+		return constant.EmptyString, ErrNotValidToken
+	}
+
+	return result.GetString(constant.ZeroInt, constant.ZeroInt)
+}
+
+// Revoke revokes the token of the user in the middleware, revoking a revoked token does nothing
+func (r *Repository) Revoke(ctx context.Context, userID, id int) error {
+	sql := `select count(*) from t_auth_token_info where id = ? and user_id = ?;`
+	tracing.Logger(ctx).Debugf("auth Repository.Revoke() select sql: %s\nplaceholders: %d, %d", sql, id, userID)
+	result, err := r.Execute(ctx, sql, id, userID)
+	if err != nil {
+		return err
+	}
+	count, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return err
+	}
+	if count == constant.ZeroInt {
+		return fmt.Errorf("token does not exist or does not belong to the user. id: %d", id)
+	}
+
+	sql = `update t_auth_token_info set del_flag = 1 where id = ? and user_id = ?;`
+	tracing.Logger(ctx).Debugf("auth Repository.Revoke() update sql: %s\nplaceholders: %d, %d", sql, id, userID)
+	_, err = r.Execute(ctx, sql, id, userID)
+
+	return err
+}
+
+// IsOwner returns if the user owns the resource, the user owns an app or a db if the user is the owner of it,
+// or the user is the owner of the db or app which is mapped to it,
+// the user owns a review if the user owns the db of it, and owns the advices of a sql id if the user owns all the dbs of them
+func (r *Repository) IsOwner(ctx context.Context, resource string, userID int, resourceID string) (bool, error) {
+	// dbOwnerCondition matches the dbs which are owned by the user, the placeholders are the user id
+	dbOwnerCondition := `
+		(di.owner_id = ? or exists (
+			select 1
+			from t_meta_app_db_map adm
+				inner join t_meta_app_info ai on adm.app_id = ai.id
+			where adm.del_flag = 0
+			and ai.del_flag = 0
+			and adm.db_id = di.id
+			and ai.owner_id = ?))
+	`
+
+	var sql string
+	switch resource {
+	case ResourceApp:
+		sql = `
+			select count(*)
+			from t_meta_app_info ai
+			where ai.del_flag = 0
+			and ai.id = ?
+			and (ai.owner_id = ? or exists (
+				select 1
+				from t_meta_app_db_map adm
+					inner join t_meta_db_info di on adm.db_id = di.id
+				where adm.del_flag = 0
+				and di.del_flag = 0
+				and adm.app_id = ai.id
+				and di.owner_id = ?));
+		`
+	case ResourceDB:
+		sql = `
+			select count(*)
+			from t_meta_db_info di
+			where di.del_flag = 0
+			and di.id = ?
+			and ` + dbOwnerCondition + `;`
+	case ResourceReview:
+		sql = `
+			select count(*)
+			from t_sr_review_info ri
+				inner join t_meta_db_info di on ri.db_id = di.id
+			where ri.del_flag = 0
+			and di.del_flag = 0
+			and ri.id = ?
+			and ` + dbOwnerCondition + `;`
+	case ResourceAdvice:
+		// the advices of the sql id are owned if none of them belongs to the db which is not owned by the user
+		sql = `
+			select count(*)
+			from dual
+			where not exists (
+				select 1
+				from t_sa_slow_query_advice sqa
+				where sqa.del_flag = 0
+				and sqa.sql_id = ?
+				and not exists (
+					select 1
+					from t_meta_db_info di
+					where di.del_flag = 0
+					and di.id = sqa.db_id
+					and ` + dbOwnerCondition + `));`
+	default:
+		return false, fmt.Errorf("resource type %s is not valid", resource)
+	}
+
+	tracing.Logger(ctx).Debugf("auth Repository.IsOwner() sql: \n%s\nplaceholders: %s, %d, %d", sql, resourceID, userID, userID)
+	result, err := r.Execute(ctx, sql, resourceID, userID, userID)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return false, err
+	}
+
+	return count > constant.ZeroInt, nil
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/auth"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+)
+
+const (
+	TokensStruct = "Tokens"
+	TokenStruct  = "Token"
+
+	// DefaultAPITokenExpiration is the default validity of the api tokens, it is 90 days
+	DefaultAPITokenExpiration = 90 * 24 * 3600
+	// loginDescription is the description of the json web tokens issued by logging in
+	loginDescription = "login"
+)
+
+var _ auth.Service = (*Service)(nil)
+
+type Service struct {
+	auth.Repository
+	userRepo depmeta.UserRepo
+	Tokens   []auth.Token `json:"tokens"`
+	Token    string       `json:"token"`
+}
+
+// NewService returns a new *Service
+func NewService(repo auth.Repository, userRepo depmeta.UserRepo) *Service {
+	return &Service{
+		Repository: repo,
+		userRepo:   userRepo,
+		Tokens:     []auth.Token{},
+	}
+}
+
+// NewServiceWithDefault returns a new *Service with default repositories
+func NewServiceWithDefault() *Service {
+	return NewService(NewRepositoryWithGlobal(), metadata.NewUserRepoWithGlobal())
+}
+
+// GetTokens returns the tokens of the service
+func (s *Service) GetTokens() []auth.Token {
+	return s.Tokens
+}
+
+// GetToken returns the issued token, it could be only got right after it was issued
+func (s *Service) GetToken() string {
+	return s.Token
+}
+
+// Login binds to the ldap server as the user and issues a json web token to the user,
+// the user must also exist in the user metadata with the same account name
+func (s *Service) Login(ctx context.Context, accountName, password string) error {
+	err := NewLDAPWithGlobal().Bind(accountName, password)
+	if err != nil {
+		return err
+	}
+	user, err := s.getUser(ctx, accountName)
+	if err != nil {
+		return err
+	}
+
+	j, err := NewJWTWithGlobal(s.Repository)
+	if err != nil {
+		return err
+	}
+	token, id, expireTime, err := j.Sign(accountName)
+	if err != nil {
+		return err
+	}
+
+	return s.create(ctx, token, NewTokenInfo(user.Identity(), TokenTypeJWT, id, loginDescription, expireTime))
+}
+
+// CreateToken issues an api token to the user, the token expires after given seconds
+func (s *Service) CreateToken(ctx context.Context, accountName, description string, expiration int) error {
+	if expiration <= constant.ZeroInt {
+		return fmt.Errorf("expiration must be larger than 0, %d is not valid", expiration)
+	}
+	user, err := s.getUser(ctx, accountName)
+	if err != nil {
+		return err
+	}
+
+	token, key, err := newAPIToken()
+	if err != nil {
+		return err
+	}
+	expireTime := time.Now().Add(time.Duration(expiration) * time.Second)
+
+	return s.create(ctx, token, NewTokenInfo(user.Identity(), TokenTypeAPI, key, description, expireTime))
+}
+
+// GetByAccountName gets the tokens of the user, the revoked and expired tokens are also returned
+func (s *Service) GetByAccountName(ctx context.Context, accountName string) error {
+	user, err := s.getUser(ctx, accountName)
+	if err != nil {
+		return err
+	}
+
+	s.Tokens, err = s.Repository.GetByUserID(ctx, user.Identity())
+
+	return err
+}
+
+// RevokeToken revokes the token of the user
+func (s *Service) RevokeToken(ctx context.Context, accountName string, id int) error {
+	user, err := s.getUser(ctx, accountName)
+	if err != nil {
+		return err
+	}
+
+	return s.Repository.Revoke(ctx, user.Identity(), id)
+}
+
+// Marshal marshals Service.Tokens to json bytes
+func (s *Service) Marshal() ([]byte, error) {
+	return s.MarshalWithFields(TokensStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the Service to json bytes
+func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(s, fields...)
+}
+
+// getUser gets the user of given account name from the user metadata
+func (s *Service) getUser(ctx context.Context, accountName string) (depmeta.User, error) {
+	if accountName == constant.EmptyString {
+		return nil, errors.New("account name could not be empty")
+	}
+	user, err := s.userRepo.GetByAccountName(ctx, accountName)
+	if err != nil {
+		return nil, fmt.Errorf("user %s does not exist in the user metadata.\n%s", accountName, err.Error())
+	}
+
+	return user, nil
+}
+
+// create saves the key of the issued token, and keeps the token so that it could be returned to the user
+func (s *Service) create(ctx context.Context, token string, tokenInfo auth.Token) error {
+	created, err := s.Repository.Create(ctx, tokenInfo)
+	if err != nil {
+		return err
+	}
+
+	s.Token = token
+	s.Tokens = []auth.Token{created}
+
+	return nil
+}
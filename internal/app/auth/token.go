@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+
+	"github.com/romberli/das/internal/dependency/auth"
+)
+
+const (
+	TokenTypeAPI = 1
+	TokenTypeJWT = 2
+
+	// apiTokenPrefix distinguishes the api tokens from the other kinds of tokens
+	apiTokenPrefix = "das_"
+	// apiTokenLength is the number of the random bytes of the api tokens
+	apiTokenLength = 32
+	// jwtIDLength is the number of the random bytes of the json web token ids
+	jwtIDLength = 16
+)
+
+var _ auth.Token = (*TokenInfo)(nil)
+
+// TokenInfo is an issued token, the token itself is never saved, only the key which identifies it is saved
+type TokenInfo struct {
+	ID             int       `middleware:"id" json:"id"`
+	UserID         int       `middleware:"user_id" json:"user_id"`
+	TokenType      int       `middleware:"token_type" json:"token_type"`
+	TokenKey       string    `middleware:"token_key" json:"-"`
+	Description    string    `middleware:"description" json:"description"`
+	ExpireTime     time.Time `middleware:"expire_time" json:"expire_time"`
+	DelFlag        int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime     time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewTokenInfo returns a new *TokenInfo
+func NewTokenInfo(userID, tokenType int, tokenKey, description string, expireTime time.Time) *TokenInfo {
+	return &TokenInfo{
+		UserID:      userID,
+		TokenType:   tokenType,
+		TokenKey:    tokenKey,
+		Description: description,
+		ExpireTime:  expireTime,
+	}
+}
+
+// NewEmptyTokenInfo returns an empty *TokenInfo
+func NewEmptyTokenInfo() *TokenInfo {
+	return &TokenInfo{}
+}
+
+// Identity returns the identity
+func (ti *TokenInfo) Identity() int {
+	return ti.ID
+}
+
+// GetUserID returns the identity of the user who owns the token
+func (ti *TokenInfo) GetUserID() int {
+	return ti.UserID
+}
+
+// GetTokenType returns the token type
+func (ti *TokenInfo) GetTokenType() int {
+	return ti.TokenType
+}
+
+// GetTokenKey returns the key which identifies the token
+func (ti *TokenInfo) GetTokenKey() string {
+	return ti.TokenKey
+}
+
+// GetDescription returns the description
+func (ti *TokenInfo) GetDescription() string {
+	return ti.Description
+}
+
+// GetExpireTime returns the expire time
+func (ti *TokenInfo) GetExpireTime() time.Time {
+	return ti.ExpireTime
+}
+
+// GetDelFlag returns the delete flag, 1 means the token was revoked
+func (ti *TokenInfo) GetDelFlag() int {
+	return ti.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (ti *TokenInfo) GetCreateTime() time.Time {
+	return ti.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (ti *TokenInfo) GetLastUpdateTime() time.Time {
+	return ti.LastUpdateTime
+}
+
+// MarshalJSON marshals TokenInfo to json string
+func (ti *TokenInfo) MarshalJSON() ([]byte, error) {
+	type tokenInfo TokenInfo
+	return json.Marshal((*tokenInfo)(ti))
+}
+
+// isAPIToken returns if the token has the prefix of the api tokens
+func isAPIToken(token string) bool {
+	return strings.HasPrefix(token, apiTokenPrefix)
+}
+
+// isJWT returns if the token consists of 3 parts like json web tokens
+func isJWT(token string) bool {
+	return strings.Count(token, constant.DotString) == 2
+}
+
+// newAPIToken returns a new random api token and its key
+func newAPIToken() (string, string, error) {
+	b := make([]byte, apiTokenLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", "", err
+	}
+	token := apiTokenPrefix + hex.EncodeToString(b)
+
+	return token, getAPITokenKey(token), nil
+}
+
+// getAPITokenKey returns the key of the api token, it is the sha256 of the token
+func getAPITokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// newJWTID returns a new random json web token id
+func newJWTID() (string, error) {
+	b := make([]byte, jwtIDLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,380 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/app/audit"
+	"github.com/romberli/das/internal/app/metadata"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+const (
+	ActionNone   = "none"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+
+	// DefaultDeploymentType is the physical machine
+	DefaultDeploymentType = 2
+	defaultClusterType    = 1
+	defaultReadWeight     = 1
+
+	clusterNameStruct         = "ClusterName"
+	middlewareClusterIDStruct = "MiddlewareClusterID"
+	monitorSystemIDStruct     = "MonitorSystemID"
+	ownerIDStruct             = "OwnerID"
+	envIDStruct               = "EnvID"
+	clusterIDStruct           = "ClusterID"
+	serverNameStruct          = "ServerName"
+	serviceNameStruct         = "ServiceName"
+	hostIPStruct              = "HostIP"
+	portNumStruct             = "PortNum"
+	deploymentTypeStruct      = "DeploymentType"
+	serverRoleStruct          = "ServerRole"
+	readWeightStruct          = "ReadWeight"
+	versionStruct             = "Version"
+	dbNameStruct              = "DBName"
+	clusterTypeStruct         = "ClusterType"
+)
+
+// Options is used when the discovered mysql cluster or mysql servers have not been registered yet,
+// service names are the service names of the mysql servers in the monitor system, the keys are the addresses(host_ip:port_num)
+type Options struct {
+	ClusterName     string            `json:"cluster_name"`
+	EnvID           int               `json:"env_id"`
+	MonitorSystemID int               `json:"monitor_system_id"`
+	DeploymentType  int               `json:"deployment_type"`
+	ServiceNames    map[string]string `json:"service_names"`
+}
+
+// FieldDiff is the old and new value of a changed field
+type FieldDiff struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Change is the proposed change of a metadata entity,
+// fields are the values to create or update the entity, the keys are the struct field names
+type Change struct {
+	Action string                 `json:"action"`
+	ID     int                    `json:"id"`
+	Name   string                 `json:"name"`
+	Fields map[string]interface{} `json:"fields"`
+	Diff   map[string]*FieldDiff  `json:"diff"`
+}
+
+// newChange returns a new *Change
+func newChange(action string, id int, name string, fields map[string]interface{}) *Change {
+	return &Change{
+		Action: action,
+		ID:     id,
+		Name:   name,
+		Fields: fields,
+		Diff:   make(map[string]*FieldDiff),
+	}
+}
+
+// Plan is the proposed changes of the metadata of a discovered mysql cluster
+type Plan struct {
+	Seed         string    `json:"seed"`
+	DryRun       bool      `json:"dry_run"`
+	Applied      bool      `json:"applied"`
+	Topology     *Topology `json:"topology"`
+	MySQLCluster *Change   `json:"mysql_cluster"`
+	MySQLServers []*Change `json:"mysql_servers"`
+	DBs          []*Change `json:"dbs"`
+	Warnings     []string  `json:"warnings"`
+	// monitorSystemID is the monitor system of the mysql cluster, the service names which are not specified are got from it
+	monitorSystemID int
+}
+
+// NewPlan compares the discovered topology with the registered metadata and returns the proposed changes,
+// mysqlServers should contain all registered mysql servers, so that the cluster could be found by any discovered instance,
+// clusters are used to look up the registered cluster, dbs should contain all registered databases,
+// registered entities which are not discovered will not be deleted, they are reported as warnings
+func NewPlan(seed string, topology *Topology, clusters []depmeta.MySQLCluster, mysqlServers []depmeta.MySQLServer,
+	dbs []depmeta.DB, options *Options) (*Plan, error) {
+	plan := &Plan{
+		Seed:         seed,
+		DryRun:       true,
+		Topology:     topology,
+		MySQLServers: []*Change{},
+		DBs:          []*Change{},
+		Warnings:     append([]string{}, topology.Warnings...),
+	}
+
+	serverMap := make(map[string]depmeta.MySQLServer)
+	for _, mysqlServer := range mysqlServers {
+		serverMap[getAddr(mysqlServer.GetHostIP(), mysqlServer.GetPortNum())] = mysqlServer
+	}
+	// find the registered cluster by the discovered instances
+	clusterIDMap := make(map[int]bool)
+	for _, instance := range topology.Instances {
+		mysqlServer, ok := serverMap[instance.GetAddr()]
+		if ok {
+			clusterIDMap[mysqlServer.GetClusterID()] = true
+		}
+	}
+	if len(clusterIDMap) > 1 {
+		var clusterIDList []int
+		for clusterID := range clusterIDMap {
+			clusterIDList = append(clusterIDList, clusterID)
+		}
+		sort.Ints(clusterIDList)
+		return nil, fmt.Errorf("discovered mysql servers are registered in different mysql clusters. mysql cluster ids: %v", clusterIDList)
+	}
+
+	var cluster depmeta.MySQLCluster
+	for clusterID := range clusterIDMap {
+		for _, c := range clusters {
+			if c.Identity() == clusterID {
+				cluster = c
+			}
+		}
+		if cluster == nil {
+			return nil, fmt.Errorf("mysql cluster of the registered mysql servers does not exist. mysql cluster id: %d", clusterID)
+		}
+	}
+
+	clusterID := constant.ZeroInt
+	envID := options.EnvID
+	plan.monitorSystemID = options.MonitorSystemID
+	if cluster != nil {
+		clusterID = cluster.Identity()
+		envID = cluster.GetEnvID()
+		plan.monitorSystemID = cluster.GetMonitorSystemID()
+		plan.MySQLCluster = newChange(ActionNone, clusterID, cluster.GetClusterName(), map[string]interface{}{})
+	} else {
+		change, err := newClusterCreateChange(topology, options)
+		if err != nil {
+			return nil, err
+		}
+		plan.MySQLCluster = change
+	}
+
+	deploymentType := options.DeploymentType
+	if deploymentType == constant.ZeroInt {
+		deploymentType = DefaultDeploymentType
+	}
+	// mysql servers
+	discoveredAddrs := make(map[string]bool)
+	for _, instance := range topology.Instances {
+		discoveredAddrs[instance.GetAddr()] = true
+		mysqlServer, ok := serverMap[instance.GetAddr()]
+		if !ok {
+			plan.MySQLServers = append(plan.MySQLServers, newChange(ActionCreate, constant.ZeroInt, instance.GetAddr(), map[string]interface{}{
+				clusterIDStruct:      clusterID,
+				serverNameStruct:     instance.HostName,
+				serviceNameStruct:    options.ServiceNames[instance.GetAddr()],
+				hostIPStruct:         instance.HostIP,
+				portNumStruct:        instance.PortNum,
+				deploymentTypeStruct: deploymentType,
+				serverRoleStruct:     instance.ServerRole,
+				readWeightStruct:     defaultReadWeight,
+				versionStruct:        instance.Version,
+			}))
+			continue
+		}
+
+		change := newChange(ActionNone, mysqlServer.Identity(), instance.GetAddr(), map[string]interface{}{})
+		change.addDiff(serverRoleStruct, mysqlServer.GetServerRole(), instance.ServerRole)
+		change.addDiff(versionStruct, mysqlServer.GetVersion(), instance.Version)
+		plan.MySQLServers = append(plan.MySQLServers, change)
+	}
+	for _, mysqlServer := range mysqlServers {
+		addr := getAddr(mysqlServer.GetHostIP(), mysqlServer.GetPortNum())
+		if cluster != nil && mysqlServer.GetClusterID() == clusterID && !discoveredAddrs[addr] {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("mysql server %s is registered in the mysql cluster but not discovered", addr))
+		}
+	}
+	// databases
+	dbMap := make(map[string]depmeta.DB)
+	for _, db := range dbs {
+		if cluster != nil && db.GetClusterID() == clusterID && db.GetClusterType() == defaultClusterType {
+			dbMap[db.GetDBName()] = db
+		}
+	}
+	discoveredDBNames := make(map[string]bool)
+	for _, dbName := range topology.DBNames {
+		discoveredDBNames[dbName] = true
+		db, ok := dbMap[dbName]
+		if ok {
+			plan.DBs = append(plan.DBs, newChange(ActionNone, db.Identity(), dbName, map[string]interface{}{}))
+			continue
+		}
+		plan.DBs = append(plan.DBs, newChange(ActionCreate, constant.ZeroInt, dbName, map[string]interface{}{
+			dbNameStruct:      dbName,
+			clusterIDStruct:   clusterID,
+			clusterTypeStruct: defaultClusterType,
+			ownerIDStruct:     constant.ZeroInt,
+			envIDStruct:       envID,
+		}))
+	}
+	for dbName := range dbMap {
+		if !discoveredDBNames[dbName] {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("database %s is registered in the mysql cluster but not discovered", dbName))
+		}
+	}
+	sort.Strings(plan.Warnings)
+
+	return plan, nil
+}
+
+// newClusterCreateChange returns the change to create the mysql cluster,
+// the cluster name will be the address of the primary if it is not specified
+func newClusterCreateChange(topology *Topology, options *Options) (*Change, error) {
+	if options.EnvID <= constant.ZeroInt {
+		return nil, fmt.Errorf("env id must be specified to create the mysql cluster")
+	}
+
+	clusterName := options.ClusterName
+	if clusterName == constant.EmptyString {
+		primary := topology.GetPrimary()
+		if primary == nil {
+			return nil, fmt.Errorf("cluster name must be specified when the primary could not be determined")
+		}
+		clusterName = fmt.Sprintf("%s_%d", primary.HostIP, primary.PortNum)
+	}
+
+	return newChange(ActionCreate, constant.ZeroInt, clusterName, map[string]interface{}{
+		clusterNameStruct:         clusterName,
+		middlewareClusterIDStruct: constant.ZeroInt,
+		monitorSystemIDStruct:     options.MonitorSystemID,
+		ownerIDStruct:             constant.ZeroInt,
+		envIDStruct:               options.EnvID,
+	}), nil
+}
+
+// addDiff adds the field to the change if the value is changed, and marks the change as an update
+func (c *Change) addDiff(field string, oldValue, newValue interface{}) {
+	if oldValue == newValue {
+		return
+	}
+
+	c.Action = ActionUpdate
+	c.Fields[field] = newValue
+	c.Diff[field] = &FieldDiff{Old: oldValue, New: newValue}
+}
+
+// SetServiceNames sets the service names of the mysql servers to create which are not specified yet,
+// the keys of serviceNames are the addresses(host_ip:port_num),
+// the mysql servers of which the service names are still unknown will not be created, they are reported as warnings
+func (p *Plan) SetServiceNames(serviceNames map[string]string) {
+	var mysqlServers []*Change
+	for _, change := range p.MySQLServers {
+		if change.Action == ActionCreate && change.Fields[serviceNameStruct] == constant.EmptyString {
+			serviceName, ok := serviceNames[change.Name]
+			if !ok || serviceName == constant.EmptyString {
+				p.Warnings = append(p.Warnings, fmt.Sprintf(
+					"mysql server %s will not be created, its service name is neither specified nor found in the monitor system", change.Name))
+				continue
+			}
+			change.Fields[serviceNameStruct] = serviceName
+		}
+		mysqlServers = append(mysqlServers, change)
+	}
+	p.MySQLServers = append([]*Change{}, mysqlServers...)
+	sort.Strings(p.Warnings)
+}
+
+// NeedServiceNames returns true if the service name of any mysql server to create is not specified
+func (p *Plan) NeedServiceNames() bool {
+	for _, change := range p.MySQLServers {
+		if change.Action == ActionCreate && change.Fields[serviceNameStruct] == constant.EmptyString {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetMonitorSystemID returns the identity of the monitor system of the mysql cluster, it returns 0 if it is unknown
+func (p *Plan) GetMonitorSystemID() int {
+	return p.monitorSystemID
+}
+
+// Apply applies the changes to the metadata in a transaction, the mysql cluster is created first,
+// so that the identity could be filled into the created mysql servers and databases,
+// none of the changes are applied if it fails in the middle
+func (p *Plan) Apply(ctx context.Context) error {
+	p.DryRun = false
+
+	err := metadata.ExecuteInTransaction(global.DASMySQLPool, func(pool middleware.Pool) error {
+		return p.apply(ctx, pool)
+	})
+	if err != nil {
+		// the identities of the rolled back entities are not valid
+		p.resetCreatedIDs()
+		return err
+	}
+
+	p.Applied = true
+
+	return nil
+}
+
+// apply applies the changes to the metadata with the services of which the repositories are created with given pool
+func (p *Plan) apply(ctx context.Context, pool middleware.Pool) error {
+	auditor := audit.NewAuditor(audit.NewRepository(pool), audit.DefaultActor, tracing.GetRequestID(ctx))
+
+	if p.MySQLCluster.Action == ActionCreate {
+		mysqlClusterService := metadata.NewMySQLClusterService(metadata.NewMySQLClusterRepo(pool))
+		mysqlClusterService.SetAuditor(auditor)
+		err := mysqlClusterService.Create(ctx, p.MySQLCluster.Fields)
+		if err != nil {
+			return err
+		}
+		p.MySQLCluster.ID = mysqlClusterService.GetMySQLClusters()[constant.ZeroInt].Identity()
+	}
+
+	for _, change := range p.MySQLServers {
+		mysqlServerService := metadata.NewMySQLServerService(metadata.NewMySQLServerRepo(pool))
+		mysqlServerService.SetAuditor(auditor)
+		switch change.Action {
+		case ActionCreate:
+			change.Fields[clusterIDStruct] = p.MySQLCluster.ID
+			err := mysqlServerService.Create(ctx, change.Fields)
+			if err != nil {
+				return err
+			}
+			change.ID = mysqlServerService.GetMySQLServers()[constant.ZeroInt].Identity()
+		case ActionUpdate:
+			err := mysqlServerService.Update(ctx, change.ID, change.Fields)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, change := range p.DBs {
+		if change.Action != ActionCreate {
+			continue
+		}
+		dbService := metadata.NewDBService(metadata.NewDBRepo(pool))
+		dbService.SetAuditor(auditor)
+		change.Fields[clusterIDStruct] = p.MySQLCluster.ID
+		err := dbService.Create(ctx, change.Fields)
+		if err != nil {
+			return err
+		}
+		change.ID = dbService.GetDBs()[constant.ZeroInt].Identity()
+	}
+
+	return nil
+}
+
+// resetCreatedIDs resets the identities of the entities to create
+func (p *Plan) resetCreatedIDs() {
+	changes := append(append([]*Change{p.MySQLCluster}, p.MySQLServers...), p.DBs...)
+	for _, change := range changes {
+		if change.Action == ActionCreate {
+			change.ID = constant.ZeroInt
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/internal/app/metadata"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+)
+
+const (
+	defaultSeed        = "192.168.137.11:3306"
+	defaultClusterID   = 1
+	defaultClusterName = "cluster1"
+	defaultEnvID       = 1
+	defaultVersion     = "5.7.30"
+	newVersion         = "5.7.35"
+)
+
+func newTestTopology() *Topology {
+	return &Topology{
+		Instances: []*Instance{
+			{HostIP: "192.168.137.11", PortNum: 3306, HostName: "host11", ServerUUID: "uuid11", Version: newVersion, ServerRole: metadata.ServerRolePrimary},
+			{HostIP: "192.168.137.12", PortNum: 3306, HostName: "host12", ServerUUID: "uuid12", Version: defaultVersion,
+				ServerRole: metadata.ServerRoleReplica, SourceAddr: defaultSeed},
+		},
+		DBNames:  []string{"db1", "db2"},
+		Warnings: []string{},
+	}
+}
+
+func TestPlanAll(t *testing.T) {
+	TestNewPlan_Create(t)
+	TestNewPlan_Update(t)
+	TestNewPlan_Conflict(t)
+	TestPlan_SetServiceNames(t)
+}
+
+func TestNewPlan_Create(t *testing.T) {
+	asst := assert.New(t)
+
+	// env id is required to create the cluster
+	_, err := NewPlan(defaultSeed, newTestTopology(), nil, nil, nil, &Options{})
+	asst.NotNil(err, "test NewPlan() failed")
+
+	plan, err := NewPlan(defaultSeed, newTestTopology(), nil, nil, nil,
+		&Options{EnvID: defaultEnvID, ServiceNames: map[string]string{defaultSeed: "mysql-11"}})
+	asst.Nil(err, common.CombineMessageWithError("test NewPlan() failed", err))
+	asst.Equal(ActionCreate, plan.MySQLCluster.Action, "test NewPlan() failed")
+	asst.Equal("192.168.137.11_3306", plan.MySQLCluster.Name, "test NewPlan() failed")
+	asst.Equal(2, len(plan.MySQLServers), "test NewPlan() failed")
+	asst.Equal(ActionCreate, plan.MySQLServers[0].Action, "test NewPlan() failed")
+	asst.Equal("mysql-11", plan.MySQLServers[0].Fields[serviceNameStruct], "test NewPlan() failed")
+	// the service name of the replica is not specified
+	asst.True(plan.NeedServiceNames(), "test NewPlan() failed")
+	asst.Equal(DefaultDeploymentType, plan.MySQLServers[0].Fields[deploymentTypeStruct], "test NewPlan() failed")
+	asst.Equal(2, len(plan.DBs), "test NewPlan() failed")
+	asst.Equal(defaultEnvID, plan.DBs[0].Fields[envIDStruct], "test NewPlan() failed")
+}
+
+func TestNewPlan_Update(t *testing.T) {
+	asst := assert.New(t)
+
+	clusters := []depmeta.MySQLCluster{
+		&metadata.MySQLClusterInfo{ID: defaultClusterID, ClusterName: defaultClusterName, EnvID: defaultEnvID},
+	}
+	mysqlServers := []depmeta.MySQLServer{
+		&metadata.MySQLServerInfo{ID: 1, ClusterID: defaultClusterID, HostIP: "192.168.137.11", PortNum: 3306,
+			ServerRole: metadata.ServerRolePrimary, Version: defaultVersion},
+		&metadata.MySQLServerInfo{ID: 3, ClusterID: defaultClusterID, HostIP: "192.168.137.13", PortNum: 3306,
+			ServerRole: metadata.ServerRoleReplica, Version: defaultVersion},
+	}
+	dbs := []depmeta.DB{
+		&metadata.DBInfo{ID: 1, DBName: "db1", ClusterID: defaultClusterID, ClusterType: defaultClusterType, EnvID: defaultEnvID},
+		&metadata.DBInfo{ID: 3, DBName: "db3", ClusterID: defaultClusterID, ClusterType: defaultClusterType, EnvID: defaultEnvID},
+	}
+
+	plan, err := NewPlan(defaultSeed, newTestTopology(), clusters, mysqlServers, dbs, &Options{})
+	asst.Nil(err, common.CombineMessageWithError("test NewPlan() failed", err))
+	asst.Equal(ActionNone, plan.MySQLCluster.Action, "test NewPlan() failed")
+	asst.Equal(defaultClusterID, plan.MySQLCluster.ID, "test NewPlan() failed")
+	// the version of the primary is changed
+	asst.Equal(ActionUpdate, plan.MySQLServers[0].Action, "test NewPlan() failed")
+	asst.Equal(defaultVersion, plan.MySQLServers[0].Diff[versionStruct].Old, "test NewPlan() failed")
+	asst.Equal(newVersion, plan.MySQLServers[0].Diff[versionStruct].New, "test NewPlan() failed")
+	// the replica is not registered
+	asst.Equal(ActionCreate, plan.MySQLServers[1].Action, "test NewPlan() failed")
+	asst.Equal(defaultClusterID, plan.MySQLServers[1].Fields[clusterIDStruct], "test NewPlan() failed")
+	asst.Equal(ActionNone, plan.DBs[0].Action, "test NewPlan() failed")
+	asst.Equal(ActionCreate, plan.DBs[1].Action, "test NewPlan() failed")
+	// the registered but not discovered ones are only reported
+	asst.Equal(2, len(plan.Warnings), "test NewPlan() failed")
+}
+
+func TestNewPlan_Conflict(t *testing.T) {
+	asst := assert.New(t)
+
+	clusters := []depmeta.MySQLCluster{
+		&metadata.MySQLClusterInfo{ID: 1, ClusterName: "cluster1", EnvID: defaultEnvID},
+		&metadata.MySQLClusterInfo{ID: 2, ClusterName: "cluster2", EnvID: defaultEnvID},
+	}
+	mysqlServers := []depmeta.MySQLServer{
+		&metadata.MySQLServerInfo{ID: 1, ClusterID: 1, HostIP: "192.168.137.11", PortNum: 3306},
+		&metadata.MySQLServerInfo{ID: 2, ClusterID: 2, HostIP: "192.168.137.12", PortNum: 3306},
+	}
+
+	_, err := NewPlan(defaultSeed, newTestTopology(), clusters, mysqlServers, nil, &Options{})
+	asst.NotNil(err, "test NewPlan() failed")
+}
+
+func TestPlan_SetServiceNames(t *testing.T) {
+	asst := assert.New(t)
+
+	plan, err := NewPlan(defaultSeed, newTestTopology(), nil, nil, nil,
+		&Options{EnvID: defaultEnvID, MonitorSystemID: 1, ServiceNames: map[string]string{defaultSeed: "mysql-11"}})
+	asst.Nil(err, common.CombineMessageWithError("test SetServiceNames() failed", err))
+	asst.Equal(1, plan.GetMonitorSystemID(), "test SetServiceNames() failed")
+	// the specified service name is not overwritten by the monitor system
+	plan.SetServiceNames(map[string]string{defaultSeed: "pmm-11", "192.168.137.12:3306": "pmm-12"})
+	asst.False(plan.NeedServiceNames(), "test SetServiceNames() failed")
+	asst.Equal("mysql-11", plan.MySQLServers[0].Fields[serviceNameStruct], "test SetServiceNames() failed")
+	asst.Equal("pmm-12", plan.MySQLServers[1].Fields[serviceNameStruct], "test SetServiceNames() failed")
+
+	// the mysql server of which the service name is unknown is not created
+	plan, err = NewPlan(defaultSeed, newTestTopology(), nil, nil, nil, &Options{EnvID: defaultEnvID})
+	asst.Nil(err, common.CombineMessageWithError("test SetServiceNames() failed", err))
+	plan.SetServiceNames(map[string]string{defaultSeed: "pmm-11"})
+	asst.Equal(1, len(plan.MySQLServers), "test SetServiceNames() failed")
+	asst.Equal(1, len(plan.Warnings), "test SetServiceNames() failed")
+}
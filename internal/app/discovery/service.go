@@ -0,0 +1,210 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+	"github.com/spf13/viper"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/app/monitorsync"
+	"github.com/romberli/das/internal/dependency/discovery"
+)
+
+const (
+	PlansStruct = "Plans"
+)
+
+var _ discovery.Service = (*Service)(nil)
+
+type Service struct {
+	Discoverer *Discoverer
+	Plans      []*Plan `json:"plans"`
+}
+
+// NewService returns a new *Service
+func NewService(discoverer *Discoverer) *Service {
+	return &Service{
+		Discoverer: discoverer,
+		Plans:      []*Plan{},
+	}
+}
+
+// NewServiceWithDefault returns a new *Service which connects to the mysql servers with the application user
+func NewServiceWithDefault() *Service {
+	return NewService(NewDiscoverer(viper.GetString(config.DBApplicationMySQLUserKey), viper.GetString(config.DBApplicationMySQLPassKey)))
+}
+
+// GetPlans returns the plans of the service
+func (s *Service) GetPlans() []*Plan {
+	return s.Plans
+}
+
+// DiscoverBySeed discovers the replication topology and databases from the seed mysql server,
+// and proposes the changes of the metadata, the changes will be applied only if dryRun is false,
+// cluster name, env id, monitor system id, deployment type and service names are only used to create
+// the mysql cluster and mysql servers which are not registered, the keys of service names are the addresses(host_ip:port_num),
+// the service names which are not specified are got from the monitor system of the mysql cluster
+func (s *Service) DiscoverBySeed(ctx context.Context, hostIP string, portNum int, clusterName string, envID, monitorSystemID, deploymentType int,
+	serviceNames map[string]string, dryRun bool) error {
+	plan, err := s.discover(ctx, hostIP, portNum, &Options{
+		ClusterName:     clusterName,
+		EnvID:           envID,
+		MonitorSystemID: monitorSystemID,
+		DeploymentType:  deploymentType,
+		ServiceNames:    serviceNames,
+	}, dryRun)
+	if plan != nil {
+		// the plan is kept even if applying failed, so that the applied changes could be checked
+		s.Plans = []*Plan{plan}
+	}
+
+	return err
+}
+
+// DiscoverByMonitorSystem discovers all the registered mysql clusters which are monitored by the given monitor system,
+// each cluster is discovered from the first of its registered mysql servers which could be connected,
+// failure of one mysql cluster does not stop discovering the others, it is reported as a plan warning,
+// the mysql clusters which are not registered are not discovered, they should be discovered by seed,
+// or their mysql servers could be registered by synchronizing with the monitor system first
+func (s *Service) DiscoverByMonitorSystem(ctx context.Context, monitorSystemID int, dryRun bool) error {
+	monitorSystemService := metadata.NewMonitorSystemServiceWithDefault()
+	err := monitorSystemService.GetByID(ctx, monitorSystemID)
+	if err != nil {
+		return err
+	}
+
+	mysqlClusterService := metadata.NewMySQLClusterServiceWithDefault()
+	err = mysqlClusterService.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.Plans = []*Plan{}
+	for _, mysqlCluster := range mysqlClusterService.GetMySQLClusters() {
+		if mysqlCluster.GetMonitorSystemID() != monitorSystemID {
+			continue
+		}
+
+		plan, err := s.discoverCluster(ctx, mysqlCluster.Identity(), dryRun)
+		if err != nil {
+			log.Errorf("discovery Service.DiscoverByMonitorSystem(): discover mysql cluster failed. mysql cluster id: %d\n%s",
+				mysqlCluster.Identity(), err.Error())
+			if plan == nil {
+				plan = &Plan{
+					DryRun:       dryRun,
+					MySQLCluster: newChange(ActionNone, mysqlCluster.Identity(), mysqlCluster.GetClusterName(), map[string]interface{}{}),
+					MySQLServers: []*Change{},
+					DBs:          []*Change{},
+					Warnings:     []string{},
+				}
+			}
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("discover mysql cluster failed. error: %s", err.Error()))
+		}
+		s.Plans = append(s.Plans, plan)
+	}
+
+	return nil
+}
+
+// discoverCluster discovers the registered mysql cluster from the first of its mysql servers which could be connected
+func (s *Service) discoverCluster(ctx context.Context, mysqlClusterID int, dryRun bool) (*Plan, error) {
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err := mysqlServerService.GetByClusterID(ctx, mysqlClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mysqlServer := range mysqlServerService.GetMySQLServers() {
+		topology, err := s.Discoverer.Discover(ctx, mysqlServer.GetHostIP(), mysqlServer.GetPortNum())
+		if err != nil {
+			log.Warnf("discovery Service.discoverCluster(): discover from mysql server failed, try the next one. host ip: %s, port num: %d\n%s",
+				mysqlServer.GetHostIP(), mysqlServer.GetPortNum(), err.Error())
+			continue
+		}
+		// the cluster is registered, so the options will not be used
+		return s.plan(ctx, getAddr(mysqlServer.GetHostIP(), mysqlServer.GetPortNum()), topology, &Options{}, dryRun)
+	}
+
+	return nil, fmt.Errorf("could not discover from any mysql server of the mysql cluster. mysql cluster id: %d", mysqlClusterID)
+}
+
+// discover discovers the topology from the seed mysql server, and plans the changes of the metadata
+func (s *Service) discover(ctx context.Context, hostIP string, portNum int, options *Options, dryRun bool) (*Plan, error) {
+	topology, err := s.Discoverer.Discover(ctx, hostIP, portNum)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.plan(ctx, getAddr(hostIP, portNum), topology, options, dryRun)
+}
+
+// plan compares the topology with the registered metadata, and applies the changes if dryRun is false,
+// the plan is returned even if applying failed
+func (s *Service) plan(ctx context.Context, seed string, topology *Topology, options *Options, dryRun bool) (*Plan, error) {
+	mysqlClusterService := metadata.NewMySQLClusterServiceWithDefault()
+	err := mysqlClusterService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err = mysqlServerService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dbService := metadata.NewDBServiceWithDefault()
+	err = dbService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := NewPlan(seed, topology, mysqlClusterService.GetMySQLClusters(),
+		mysqlServerService.GetMySQLServers(), dbService.GetDBs(), options)
+	if err != nil {
+		return nil, err
+	}
+	plan.SetServiceNames(s.getServiceNames(ctx, plan))
+	if dryRun {
+		return plan, nil
+	}
+
+	return plan, plan.Apply(ctx)
+}
+
+// getServiceNames returns the service names of the mysql services which are monitored by the monitor system of the plan,
+// the keys are the addresses(host_ip:port_num), failing to get them is reported as a plan warning
+func (s *Service) getServiceNames(ctx context.Context, plan *Plan) map[string]string {
+	serviceNames := make(map[string]string)
+	if !plan.NeedServiceNames() || plan.GetMonitorSystemID() == constant.ZeroInt {
+		return serviceNames
+	}
+
+	services, err := monitorsync.GetMonitoredServices(ctx, plan.GetMonitorSystemID())
+	if err != nil {
+		log.Errorf("discovery Service.getServiceNames(): get monitored services failed. monitor system id: %d\n%s",
+			plan.GetMonitorSystemID(), err.Error())
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("get service names from the monitor system failed. error: %s", err.Error()))
+		return serviceNames
+	}
+	for _, service := range services {
+		if service.HasAddr() {
+			serviceNames[getAddr(service.HostIP, service.PortNum)] = service.ServiceName
+		}
+	}
+
+	return serviceNames
+}
+
+// Marshal marshals Service to json bytes
+func (s *Service) Marshal() ([]byte, error) {
+	return s.MarshalWithFields(PlansStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the Service to json bytes
+func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(s, fields...)
+}
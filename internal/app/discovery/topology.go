@@ -0,0 +1,276 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware/mysql"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/internal/app/metadata"
+)
+
+const (
+	// maxInstanceNum limits the instances of a topology, it prevents walking through a misconfigured topology endlessly
+	maxInstanceNum = 64
+
+	masterHostColumn = "Master_Host"
+	masterPortColumn = "Master_Port"
+	sqlDelayColumn   = "SQL_Delay"
+)
+
+var systemDBNames = map[string]bool{
+	"information_schema": true,
+	"mysql":              true,
+	"performance_schema": true,
+	"sys":                true,
+}
+
+// Instance is a mysql server discovered from the live topology
+type Instance struct {
+	HostIP     string `json:"host_ip"`
+	PortNum    int    `json:"port_num"`
+	HostName   string `json:"host_name"`
+	ServerUUID string `json:"server_uuid"`
+	Version    string `json:"version"`
+	ServerRole int    `json:"server_role"`
+	// SourceAddr is the address of the replication source, it is empty if the instance is the primary
+	SourceAddr string `json:"source_addr"`
+}
+
+// GetAddr returns the address of the instance
+func (i *Instance) GetAddr() string {
+	return getAddr(i.HostIP, i.PortNum)
+}
+
+// Topology is the replication topology and the databases discovered from a seed mysql server
+type Topology struct {
+	Instances []*Instance `json:"instances"`
+	DBNames   []string    `json:"db_names"`
+	Warnings  []string    `json:"warnings"`
+}
+
+// GetPrimary returns the primary instance of the topology, it returns nil if there is no primary or more than one primary
+func (t *Topology) GetPrimary() *Instance {
+	var primary *Instance
+	for _, instance := range t.Instances {
+		if instance.ServerRole == metadata.ServerRolePrimary {
+			if primary != nil {
+				return nil
+			}
+			primary = instance
+		}
+	}
+
+	return primary
+}
+
+// Discoverer connects to the live mysql servers and walks through the replication topology
+type Discoverer struct {
+	user string
+	pass string
+}
+
+// NewDiscoverer returns a new *Discoverer, the user must have the replication client privilege
+func NewDiscoverer(user, pass string) *Discoverer {
+	return &Discoverer{
+		user: user,
+		pass: pass,
+	}
+}
+
+// Discover walks through the replication topology from the seed mysql server in both directions,
+// the sources are found by "show slave status" and the replicas are found by "show slave hosts",
+// so the replicas must set report_host to be discovered, the databases are read from the primary
+func (d *Discoverer) Discover(ctx context.Context, hostIP string, portNum int) (*Topology, error) {
+	topology := &Topology{Instances: []*Instance{}, DBNames: []string{}, Warnings: []string{}}
+
+	seedAddr := getAddr(hostIP, portNum)
+	queue := []string{seedAddr}
+	visitedAddrs := map[string]bool{seedAddr: true}
+	visitedUUIDs := make(map[string]bool)
+
+	for len(queue) > constant.ZeroInt {
+		addr := queue[constant.ZeroInt]
+		queue = queue[1:]
+
+		if len(topology.Instances) >= maxInstanceNum {
+			topology.Warnings = append(topology.Warnings,
+				fmt.Sprintf("instance number exceeds %d, the remaining instances are ignored", maxInstanceNum))
+			break
+		}
+
+		instance, neighbors, err := d.discoverInstance(ctx, addr)
+		if err != nil {
+			if addr == seedAddr {
+				return nil, err
+			}
+			topology.Warnings = append(topology.Warnings, fmt.Sprintf("could not discover %s, it is ignored. error: %s", addr, err.Error()))
+			continue
+		}
+		if visitedUUIDs[instance.ServerUUID] {
+			// the same instance could be reached by different addresses
+			continue
+		}
+		visitedUUIDs[instance.ServerUUID] = true
+		topology.Instances = append(topology.Instances, instance)
+
+		for _, neighbor := range neighbors {
+			neighborHost, _, err := net.SplitHostPort(neighbor)
+			if err != nil || neighborHost == constant.EmptyString {
+				topology.Warnings = append(topology.Warnings,
+					fmt.Sprintf("a replica of %s does not report its host, set report_host to discover it", addr))
+				continue
+			}
+			if !visitedAddrs[neighbor] {
+				visitedAddrs[neighbor] = true
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+
+	primary := topology.GetPrimary()
+	dbAddr := seedAddr
+	if primary == nil {
+		topology.Warnings = append(topology.Warnings, "could not determine the only primary, databases are read from the seed")
+	} else {
+		dbAddr = primary.GetAddr()
+	}
+	dbNames, err := d.getDBNames(ctx, dbAddr)
+	if err != nil {
+		return nil, err
+	}
+	topology.DBNames = dbNames
+
+	return topology, nil
+}
+
+// discoverInstance reads the instance information, and returns the addresses of its source and replicas
+func (d *Discoverer) discoverInstance(ctx context.Context, addr string) (*Instance, []string, error) {
+	conn, err := mysql.NewConn(addr, constant.EmptyString, d.user, d.pass)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("discovery Discoverer.discoverInstance(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	hostIP, portNum, err := splitAddr(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	instance := &Instance{HostIP: hostIP, PortNum: portNum, ServerRole: metadata.ServerRolePrimary}
+
+	version, err := conn.GetVersion()
+	if err != nil {
+		return nil, nil, err
+	}
+	instance.Version = version.String()
+
+	result, err := conn.ExecuteContext(ctx, `select @@hostname, @@server_uuid;`)
+	if err != nil {
+		return nil, nil, err
+	}
+	instance.HostName, err = result.GetString(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return nil, nil, err
+	}
+	instance.ServerUUID, err = result.GetString(constant.ZeroInt, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var neighbors []string
+	// source
+	result, err = conn.GetReplicationSlavesStatus()
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.RowNumber() > constant.ZeroInt {
+		sourceHost, err := result.GetStringByName(constant.ZeroInt, masterHostColumn)
+		if err != nil {
+			return nil, nil, err
+		}
+		sourcePort, err := result.GetIntByName(constant.ZeroInt, masterPortColumn)
+		if err != nil {
+			return nil, nil, err
+		}
+		sqlDelay, err := result.GetIntByName(constant.ZeroInt, sqlDelayColumn)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		instance.ServerRole = metadata.ServerRoleReplica
+		if sqlDelay > constant.ZeroInt {
+			instance.ServerRole = metadata.ServerRoleDelayedReplica
+		}
+		instance.SourceAddr = getAddr(sourceHost, sourcePort)
+		neighbors = append(neighbors, instance.SourceAddr)
+	}
+	// replicas
+	replicaAddrs, err := conn.GetReplicationSlaveList()
+	if err != nil {
+		return nil, nil, err
+	}
+	neighbors = append(neighbors, replicaAddrs...)
+
+	return instance, neighbors, nil
+}
+
+// getDBNames returns the user database names of the mysql server
+func (d *Discoverer) getDBNames(ctx context.Context, addr string) ([]string, error) {
+	conn, err := mysql.NewConn(addr, constant.EmptyString, d.user, d.pass)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("discovery Discoverer.getDBNames(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	result, err := conn.ExecuteContext(ctx, `show databases;`)
+	if err != nil {
+		return nil, err
+	}
+
+	dbNames := []string{}
+	for i := 0; i < result.RowNumber(); i++ {
+		dbName, err := result.GetString(i, constant.ZeroInt)
+		if err != nil {
+			return nil, err
+		}
+		if !systemDBNames[strings.ToLower(dbName)] {
+			dbNames = append(dbNames, dbName)
+		}
+	}
+
+	return dbNames, nil
+}
+
+// getAddr returns the address of the host and port
+func getAddr(hostIP string, portNum int) string {
+	return net.JoinHostPort(hostIP, strconv.Itoa(portNum))
+}
+
+// splitAddr splits the address into host and port
+func splitAddr(addr string) (string, int, error) {
+	hostIP, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return constant.EmptyString, constant.ZeroInt, err
+	}
+	portNum, err := strconv.Atoi(portStr)
+	if err != nil {
+		return constant.EmptyString, constant.ZeroInt, err
+	}
+
+	return hostIP, portNum, nil
+}
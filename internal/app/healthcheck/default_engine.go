@@ -1,10 +1,10 @@
 package healthcheck
 
 import (
+	"context"
 	"database/sql/driver"
 	"encoding/binary"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"math"
 	"strconv"
@@ -13,12 +13,14 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/app/query"
 	"github.com/romberli/das/internal/app/sqladvisor"
 	"github.com/romberli/das/internal/dependency/healthcheck"
 	"github.com/romberli/das/pkg/message"
 	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/das/pkg/metrics"
+	"github.com/romberli/das/pkg/tracing"
 	"github.com/romberli/go-util/constant"
-	"github.com/romberli/go-util/middleware"
 	"github.com/romberli/go-util/middleware/clickhouse"
 	"github.com/romberli/go-util/middleware/mysql"
 	"github.com/romberli/go-util/middleware/prometheus"
@@ -40,8 +42,10 @@ const (
 	defaultTableRowsItemName               = "table_rows"
 	defaultTableSizeItemName               = "table_size"
 	defaultSlowQueryRowsExaminedItemName   = "slow_query_rows_examined"
+	defaultSlowQueryTopNum                 = 1000
 	defaultSlowQueryTopSQLNum              = 3
 	defaultClusterType                     = 1
+	defaultTableRowsColumnIndex            = 2
 
 	dbConfigMaxUserConnection         = "max_user_connection"
 	dbConfigLogBin                    = "log_bin"
@@ -192,22 +196,12 @@ func (dec DefaultEngineConfig) Validate() error {
 	return nil
 }
 
-type SlowQuery struct {
-	SQLID           string  `middleware:"sql_id" json:"sql_id"`
-	Fingerprint     string  `middleware:"fingerprint" json:"fingerprint"`
-	Example         string  `middleware:"example" json:"example"`
-	DBName          string  `middleware:"db_name" json:"db_name"`
-	ExecCount       int     `middleware:"exec_count" json:"exec_count"`
-	TotalExecTime   float64 `middleware:"total_exec_time" json:"total_exec_time"`
-	AvgExecTime     float64 `middleware:"avg_exec_time" json:"avg_exec_time"`
-	RowsExaminedMax int     `middleware:"rows_examined_max" json:"rows_examined_max"`
-}
-
 // DefaultEngine work for health check module
 type DefaultEngine struct {
 	healthcheck.Repository
 	operationInfo         *OperationInfo
 	applicationMySQLConn  *mysql.Conn
+	readMySQLConn         *mysql.Conn
 	monitorPrometheusConn *prometheus.Conn
 	monitorClickhouseConn *clickhouse.Conn
 	monitorMySQLConn      *mysql.Conn
@@ -215,13 +209,15 @@ type DefaultEngine struct {
 	result                *Result
 }
 
-// NewDefaultEngine returns a new *DefaultEngine
-func NewDefaultEngine(repo healthcheck.Repository, operationInfo *OperationInfo, applicationMySQLConn *mysql.Conn,
+// NewDefaultEngine returns a new *DefaultEngine,
+// readMySQLConn connects to a readable server of the same cluster, it is used to run the heavy queries such as checking table size
+func NewDefaultEngine(repo healthcheck.Repository, operationInfo *OperationInfo, applicationMySQLConn *mysql.Conn, readMySQLConn *mysql.Conn,
 	monitorPrometheusConn *prometheus.Conn, monitorClickhouseConn *clickhouse.Conn, monitorMySQLConn *mysql.Conn) *DefaultEngine {
 	return &DefaultEngine{
 		Repository:            repo,
 		operationInfo:         operationInfo,
 		applicationMySQLConn:  applicationMySQLConn,
+		readMySQLConn:         readMySQLConn,
 		monitorPrometheusConn: monitorPrometheusConn,
 		monitorClickhouseConn: monitorClickhouseConn,
 		monitorMySQLConn:      monitorMySQLConn,
@@ -265,89 +261,104 @@ func (de *DefaultEngine) getMySQLVersion() float64 {
 }
 
 // Run runs healthcheck
-func (de *DefaultEngine) Run() {
+func (de *DefaultEngine) Run(ctx context.Context) {
 	defer func() {
 		err := de.closeConnections()
 		if err != nil {
-			log.Error(message.NewMessage(msghc.ErrHealthcheckCloseConnection, err.Error()).Error())
+			tracing.Logger(ctx).Error(message.NewMessage(msghc.ErrHealthcheckCloseConnection, err.Error()).Error())
 		}
 	}()
 
 	// run
-	err := de.run()
+	err := de.run(ctx)
 	if err != nil {
-		log.Error(message.NewMessage(msghc.ErrHealthcheckDefaultEngineRun, err.Error()).Error())
+		tracing.Logger(ctx).Error(message.NewMessage(msghc.ErrHealthcheckDefaultEngineRun, err.Error()).Error())
 		// update status
-		updateErr := de.Repository.UpdateOperationStatus(de.operationInfo.OperationID, defaultFailedStatus, err.Error())
+		updateErr := de.Repository.UpdateOperationStatus(ctx, de.operationInfo.OperationID, defaultFailedStatus, err.Error())
 		if updateErr != nil {
-			log.Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
+			tracing.Logger(ctx).Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
 		}
+		metrics.ObserveHealthcheckOperation(metrics.HealthcheckStatusFailed)
+
+		return
 	}
 
 	// update operation status
 	msg := fmt.Sprintf("healthcheck completed successfully. engine: default, operation_id: %d", de.operationInfo.OperationID)
-	updateErr := de.Repository.UpdateOperationStatus(de.operationInfo.OperationID, defaultSuccessStatus, msg)
+	if de.operationInfo.MySQLServer.GetState() == metadata.StateMaintenance {
+		msg += ", note: the mysql server was in maintenance, the result may not reflect its normal status"
+	}
+	updateErr := de.Repository.UpdateOperationStatus(ctx, de.operationInfo.OperationID, defaultSuccessStatus, msg)
 	if updateErr != nil {
-		log.Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
+		tracing.Logger(ctx).Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
 	}
+	metrics.ObserveHealthcheckOperation(metrics.HealthcheckStatusCompleted)
 }
 
 // run runs healthcheck
-func (de *DefaultEngine) run() error {
+func (de *DefaultEngine) run(ctx context.Context) error {
 	// pre run
-	err := de.preRun()
+	err := de.preRun(ctx)
 	if err != nil {
 		return err
 	}
 	// check db config
-	err = de.checkDBConfig()
+	err = de.checkItem(ctx, defaultDBConfigItemName, de.checkDBConfig)
 	if err != nil {
 		return err
 	}
 	// check cpu usage
-	err = de.checkCPUUsage()
+	err = de.checkItem(ctx, defaultCPUUsageItemName, de.checkCPUUsage)
 	if err != nil {
 		return err
 	}
 	// check io util
-	err = de.checkIOUtil()
+	err = de.checkItem(ctx, defaultIOUtilItemName, de.checkIOUtil)
 	if err != nil {
 		return err
 	}
 	// check disk capacity usage
-	err = de.checkDiskCapacityUsage()
+	err = de.checkItem(ctx, defaultDiskCapacityUsageItemName, de.checkDiskCapacityUsage)
 	if err != nil {
 		return err
 	}
 	// check connection usage
-	err = de.checkConnectionUsage()
+	err = de.checkItem(ctx, defaultConnectionUsageItemName, de.checkConnectionUsage)
 	if err != nil {
 		return err
 	}
 	// check active session number
-	err = de.checkActiveSessionNum()
+	err = de.checkItem(ctx, defaultAverageActiveSessionNumItemName, de.checkActiveSessionNum)
 	if err != nil {
 		return err
 	}
 	// check cache miss ratio
-	err = de.checkCacheMissRatio()
+	err = de.checkItem(ctx, defaultCacheMissRatioItemName, de.checkCacheMissRatio)
 	if err != nil {
 		return err
 	}
 	// check table size
-	err = de.checkTableSize()
+	err = de.checkItem(ctx, defaultTableSizeItemName, de.checkTableSize)
 	if err != nil {
 		return err
 	}
 	// check slow query
-	err = de.checkSlowQuery()
+	err = de.checkItem(ctx, defaultSlowQueryRowsExaminedItemName, de.checkSlowQuery)
 	if err != nil {
 		return err
 	}
 	// summarize
 	de.summarize()
 	// post run
-	return de.postRun()
+	return de.postRun(ctx)
+}
+
+// checkItem checks the item and observes the duration
+func (de *DefaultEngine) checkItem(ctx context.Context, item string, check func(ctx context.Context) error) error {
+	startTime := time.Now()
+	defer metrics.ObserveHealthcheckItem(item, startTime)
+
+	return check(ctx)
 }
 
 func (de *DefaultEngine) closeConnections() error {
@@ -357,6 +368,12 @@ func (de *DefaultEngine) closeConnections() error {
 	if err != nil {
 		merr = multierror.Append(merr, err)
 	}
+	if de.readMySQLConn != de.applicationMySQLConn {
+		err = de.readMySQLConn.Close()
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
 
 	switch de.getPMMVersion() {
 	case 1:
@@ -375,12 +392,12 @@ func (de *DefaultEngine) closeConnections() error {
 }
 
 // preRun performs pre-run actions, for now, it only loads engine config
-func (de *DefaultEngine) preRun() error {
-	return de.loadEngineConfig()
+func (de *DefaultEngine) preRun(ctx context.Context) error {
+	return de.loadEngineConfig(ctx)
 }
 
 // loadEngineConfig loads engine config
-func (de *DefaultEngine) loadEngineConfig() error {
+func (de *DefaultEngine) loadEngineConfig(ctx context.Context) error {
 	// load config
 	sql := `
 		select id, item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high,
@@ -388,8 +405,8 @@ func (de *DefaultEngine) loadEngineConfig() error {
 		from t_hc_default_engine_config
 		where del_flag = 0;
 	`
-	log.Debugf("healthcheck Repository.loadEngineConfig() sql: \n%s\n", sql)
-	result, err := de.Repository.Execute(sql)
+	tracing.Logger(ctx).Debugf("healthcheck Repository.loadEngineConfig() sql: \n%s\n", sql)
+	result, err := de.Repository.Execute(ctx, sql)
 	if err != nil {
 		return nil
 	}
@@ -417,7 +434,7 @@ func (de *DefaultEngine) loadEngineConfig() error {
 }
 
 // checkDBConfig checks database configuration
-func (de *DefaultEngine) checkDBConfig() error {
+func (de *DefaultEngine) checkDBConfig(ctx context.Context) error {
 	// load database config
 	var sql string
 	mysqlVersion := de.getMySQLVersion()
@@ -428,9 +445,9 @@ func (de *DefaultEngine) checkDBConfig() error {
 		sql = `select variable_name, variable_value
 		from performance_schema.global_variables;`
 	}
-	log.Debugf("healthcheck Repository.checkDBConfig() sql: \n%s\n", sql)
+	tracing.Logger(ctx).Debugf("healthcheck Repository.checkDBConfig() sql: \n%s\n", sql)
 
-	result, err := de.result.Execute(sql)
+	result, err := de.result.Execute(ctx, sql)
 	if err != nil {
 		return err
 	}
@@ -620,7 +637,7 @@ func (de *DefaultEngine) checkDBConfig() error {
 }
 
 // checkCPUUsage checks cpu usage
-func (de *DefaultEngine) checkCPUUsage() error {
+func (de *DefaultEngine) checkCPUUsage(ctx context.Context) error {
 	// get data
 	serviceName := de.operationInfo.MySQLServer.GetServiceName()
 
@@ -643,8 +660,12 @@ func (de *DefaultEngine) checkCPUUsage() error {
 		avg_over_time(node_cpu_average{node_name=~"%s", mode!="total", mode!="idle"}[5m]))),100)))
 	`, serviceName, serviceName, serviceName, serviceName)
 	}
-	log.Debugf("healthcheck Repository.checkCPUUsage() query: \n%s\n", query)
-	result, err := de.monitorPrometheusConn.Execute(query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	tracing.Logger(ctx).Debugf("healthcheck Repository.checkCPUUsage() query: \n%s\n", query)
+	_, span := tracing.StartBackendSpan(ctx, metrics.BackendPrometheus, query)
+	queryStartTime := time.Now()
+	result, err := de.monitorPrometheusConn.ExecuteContext(ctx, query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	metrics.ObserveBackendQuery(metrics.BackendPrometheus, queryStartTime, err)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return err
 	}
@@ -717,7 +738,7 @@ func (de *DefaultEngine) checkCPUUsage() error {
 }
 
 // checkIOUtil check io util
-func (de *DefaultEngine) checkIOUtil() error {
+func (de *DefaultEngine) checkIOUtil(ctx context.Context) error {
 	// get data
 	serviceName := de.operationInfo.MySQLServer.GetServiceName()
 	var query string
@@ -736,8 +757,12 @@ func (de *DefaultEngine) checkIOUtil() error {
 		max_over_time(rdsosmetrics_diskIO_util{device=~"(sda|sdb|sdc|sr0)",node_name=~"%s"}[5m]))/100)
 	`, serviceName, serviceName, serviceName, serviceName)
 	}
-	log.Debugf("healthcheck Repository.checkIOUtil() query: \n%s\n", query)
-	result, err := de.monitorPrometheusConn.Execute(query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	tracing.Logger(ctx).Debugf("healthcheck Repository.checkIOUtil() query: \n%s\n", query)
+	_, span := tracing.StartBackendSpan(ctx, metrics.BackendPrometheus, query)
+	queryStartTime := time.Now()
+	result, err := de.monitorPrometheusConn.ExecuteContext(ctx, query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	metrics.ObserveBackendQuery(metrics.BackendPrometheus, queryStartTime, err)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return err
 	}
@@ -810,7 +835,7 @@ func (de *DefaultEngine) checkIOUtil() error {
 }
 
 // checkDiskCapacityUsage checks disk capacity usage
-func (de *DefaultEngine) checkDiskCapacityUsage() error {
+func (de *DefaultEngine) checkDiskCapacityUsage(ctx context.Context) error {
 	// get data
 	serviceName := de.operationInfo.MySQLServer.GetServiceName()
 
@@ -830,8 +855,12 @@ func (de *DefaultEngine) checkDiskCapacityUsage() error {
 		max_over_time(node_filesystem_size_bytes{node_name=~"%s", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[5m]))))
 	`, serviceName, serviceName, serviceName, serviceName)
 	}
-	log.Debugf("healthcheck Repository.checkDiskCapacityUsage() query: \n%s\n", query)
-	result, err := de.monitorPrometheusConn.Execute(query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	tracing.Logger(ctx).Debugf("healthcheck Repository.checkDiskCapacityUsage() query: \n%s\n", query)
+	_, span := tracing.StartBackendSpan(ctx, metrics.BackendPrometheus, query)
+	queryStartTime := time.Now()
+	result, err := de.monitorPrometheusConn.ExecuteContext(ctx, query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	metrics.ObserveBackendQuery(metrics.BackendPrometheus, queryStartTime, err)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return err
 	}
@@ -904,7 +933,7 @@ func (de *DefaultEngine) checkDiskCapacityUsage() error {
 }
 
 // checkConnectionUsage checks connection usage
-func (de *DefaultEngine) checkConnectionUsage() error {
+func (de *DefaultEngine) checkConnectionUsage(ctx context.Context) error {
 	// get data
 	serviceName := de.operationInfo.MySQLServer.GetServiceName()
 
@@ -923,8 +952,12 @@ func (de *DefaultEngine) checkConnectionUsage() error {
 		(mysql_global_variables_max_connections{service_name=~"%s"})),1)
 	`, serviceName, serviceName, serviceName)
 	}
-	log.Debugf("healthcheck Repository.checkConnectionUsage() query: \n%s\n", query)
-	result, err := de.monitorPrometheusConn.Execute(query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	tracing.Logger(ctx).Debugf("healthcheck Repository.checkConnectionUsage() query: \n%s\n", query)
+	_, span := tracing.StartBackendSpan(ctx, metrics.BackendPrometheus, query)
+	queryStartTime := time.Now()
+	result, err := de.monitorPrometheusConn.ExecuteContext(ctx, query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	metrics.ObserveBackendQuery(metrics.BackendPrometheus, queryStartTime, err)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return err
 	}
@@ -997,7 +1030,7 @@ func (de *DefaultEngine) checkConnectionUsage() error {
 }
 
 // checkActiveSessionNum check active session number
-func (de *DefaultEngine) checkActiveSessionNum() error {
+func (de *DefaultEngine) checkActiveSessionNum(ctx context.Context) error {
 	// get data
 	serviceName := de.operationInfo.MySQLServer.GetServiceName()
 
@@ -1015,8 +1048,12 @@ func (de *DefaultEngine) checkActiveSessionNum() error {
 		avg_over_time(mysql_global_status_threads_running{service_name=~"%s"}[5m]))
 	`, serviceName, serviceName)
 	}
-	log.Debugf("healthcheck Repository.checkActiveSessionNum() query: \n%s\n", query)
-	result, err := de.monitorPrometheusConn.Execute(query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	tracing.Logger(ctx).Debugf("healthcheck Repository.checkActiveSessionNum() query: \n%s\n", query)
+	_, span := tracing.StartBackendSpan(ctx, metrics.BackendPrometheus, query)
+	queryStartTime := time.Now()
+	result, err := de.monitorPrometheusConn.ExecuteContext(ctx, query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	metrics.ObserveBackendQuery(metrics.BackendPrometheus, queryStartTime, err)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return err
 	}
@@ -1089,7 +1126,7 @@ func (de *DefaultEngine) checkActiveSessionNum() error {
 }
 
 // checkCacheMissRatio checks cache miss ratio
-func (de *DefaultEngine) checkCacheMissRatio() error {
+func (de *DefaultEngine) checkCacheMissRatio(ctx context.Context) error {
 	// get data
 	serviceName := de.operationInfo.MySQLServer.GetServiceName()
 
@@ -1115,8 +1152,12 @@ func (de *DefaultEngine) checkCacheMissRatio() error {
 		irate(mysql_global_status_table_open_cache_misses{service_name=~"%s"}[5m])))),1)
 	`, serviceName, serviceName, serviceName, serviceName, serviceName, serviceName)
 	}
-	log.Debugf("healthcheck Repository.checkCacheMissRatio() query: \n%s\n", query)
-	result, err := de.monitorPrometheusConn.Execute(query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	tracing.Logger(ctx).Debugf("healthcheck Repository.checkCacheMissRatio() query: \n%s\n", query)
+	_, span := tracing.StartBackendSpan(ctx, metrics.BackendPrometheus, query)
+	queryStartTime := time.Now()
+	result, err := de.monitorPrometheusConn.ExecuteContext(ctx, query, de.operationInfo.StartTime, de.operationInfo.EndTime, de.operationInfo.Step)
+	metrics.ObserveBackendQuery(metrics.BackendPrometheus, queryStartTime, err)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return err
 	}
@@ -1189,16 +1230,16 @@ func (de *DefaultEngine) checkCacheMissRatio() error {
 }
 
 // checkTableSize checks table size by checking rows
-func (de *DefaultEngine) checkTableSize() error {
+func (de *DefaultEngine) checkTableSize(ctx context.Context) error {
 	// check table rows
 	// get data
 	sql := `
 		select TABLE_SCHEMA,TABLE_NAME,TABLE_ROWS,(DATA_LENGTH+INDEX_LENGTH)/1024/1024/1024
-		as TABLE_SIZE from TABLES
+		as TABLE_SIZE from information_schema.TABLES
 		where TABLE_TYPE='BASE TABLE';
 	`
-	log.Debugf("healthcheck Repository.checkTableSize() sql: \n%s\n", sql)
-	result, err := de.monitorMySQLConn.Execute(sql)
+	tracing.Logger(ctx).Debugf("healthcheck Repository.checkTableSize() sql: \n%s\n", sql)
+	result, err := de.readMySQLConn.ExecuteContext(ctx, sql)
 	if err != nil {
 		return err
 	}
@@ -1222,7 +1263,7 @@ func (de *DefaultEngine) checkTableSize() error {
 	)
 
 	for i, rowData := range result.Rows.Values {
-		tableRows, err = result.GetFloat(i, constant.ZeroInt)
+		tableRows, err = result.GetFloat(i, defaultTableRowsColumnIndex)
 		if err != nil {
 			return err
 		}
@@ -1271,88 +1312,35 @@ func (de *DefaultEngine) checkTableSize() error {
 }
 
 // checkSlowQuery checks slow query
-func (de *DefaultEngine) checkSlowQuery() error {
+func (de *DefaultEngine) checkSlowQuery(ctx context.Context) error {
 	// check slow query execution time
-	var (
-		sql    string
-		result middleware.Result
-		err    error
-	)
-
 	serviceName := de.operationInfo.MySQLServer.GetServiceName()
 	slowQueryRowsExaminedConfig := de.getItemConfig(defaultSlowQueryRowsExaminedItemName)
-	pmmVersion := de.getPMMVersion()
+	querier := query.NewQuerier(de.getPMMVersion(), de.monitorMySQLConn, de.monitorClickhouseConn)
 
-	switch pmmVersion {
-	case 1:
-		sql = `
-			select qc.checksum as sql_id,
-				   qc.fingerprint,
-				   qe.query    as example,
-				   qe.db       as db_name,
-				   m.exec_count,
-				   m.total_exec_time,
-				   m.avg_exec_time,
-				   m.rows_examined_max
-			from (
-					 select qcm.query_class_id,
-							sum(qcm.query_count)                                        as exec_count,
-							truncate(sum(qcm.query_time_sum), 2)                        as total_exec_time,
-							truncate(sum(qcm.query_time_sum) / sum(qcm.query_count), 2) as avg_exec_time,
-							qcm.rows_examined_max
-					 from query_class_metrics qcm
-							  inner join instances i on qcm.instance_id = i.instance_id
-					 where i.name = ?
-					   and qcm.start_ts >= ?
-					   and qcm.start_ts < ?
-					   and qcm.rows_examined_max >= ?
-					 group by query_class_id
-					 order by rows_examined_max desc) m
-					 inner join query_examples qe on m.query_class_id = qe.query_class_id
-					 inner join query_classes qc on m.query_class_id = qc.query_class_id
-			;
-		`
-		result, err = de.monitorMySQLConn.Execute(sql, serviceName, de.operationInfo.StartTime, de.operationInfo.EndTime, slowQueryRowsExaminedConfig.LowWatermark)
-	case 2:
-		sql = `
-			select queryid                                                       as sql_id,
-				   fingerprint,
-				   (select example from metrics where queryid = queryid limit 1) as example,
-				   database                                                      as db_name,
-				   sum(num_queries)                                              as exec_count,
-				   truncate(sum(m_query_time_sum), 2)                            as total_exec_time,
-				   truncate(sum(m_query_time_sum) / sum(num_queries), 2)         as avg_exec_time,
-				   max(m_rows_examined_max)                                      as rows_examined_max
-			from metrics
-			where service_type = 'mysql'
-			  and service_name = ?
-			  and period_start >= ?
-			  and period_start < ?
-			  and m_rows_examined_max >= ?
-			group by queryid, fingerprint
-			order by rows_examined_max desc;
-		`
-		result, err = de.monitorClickhouseConn.Execute(sql, serviceName, de.operationInfo.StartTime, de.operationInfo.EndTime, slowQueryRowsExaminedConfig.LowWatermark)
-	default:
-		return errors.New(fmt.Sprintf("pmm version should be 1 or 2, %d is not valid", pmmVersion))
-	}
+	// only the top n slow queries ordered by rows examined are scored, so that a server with massive slow queries would not be fully loaded
+	allSlowQueries, err := querier.GetTop(ctx, serviceName, constant.EmptyString, de.operationInfo.StartTime, de.operationInfo.EndTime,
+		query.OrderByRowsExaminedMax, defaultSlowQueryTopNum)
 	if err != nil {
 		return err
 	}
+	// the slow queries are ordered by rows examined descending, so only the leading ones reach the low watermark
+	slowQueries := make([]*query.SlowQuery, constant.ZeroInt)
+	for _, slowQuery := range allSlowQueries {
+		if float64(slowQuery.RowsExaminedMax) < slowQueryRowsExaminedConfig.LowWatermark {
+			break
+		}
+		slowQueries = append(slowQueries, slowQuery)
+	}
 
 	var (
-		slowQueries                      []*SlowQuery
-		topSQLList                       []*SlowQuery
+		topSQLList                       []*query.SlowQuery
 		slowQueryRowsExaminedHighSum     int
 		slowQueryRowsExaminedHighCount   int
 		slowQueryRowsExaminedMediumSum   int
 		slowQueryRowsExaminedMediumCount int
 	)
 
-	err = result.MapToStructSlice(slowQueries, constant.DefaultMiddlewareTag)
-	if err != nil {
-		return err
-	}
 	// slow query data
 	jsonBytesRowsExamined, err := json.Marshal(slowQueries)
 	if err != nil {
@@ -1393,24 +1381,22 @@ func (de *DefaultEngine) checkSlowQuery() error {
 
 	// sql tuning
 	clusterID := de.operationInfo.MySQLServer.GetClusterID()
-	// init db service
-	dbService := metadata.NewDBServiceWithDefault()
 	for _, sql := range topSQLList {
-		// get db info
-		err = dbService.GetByNameAndClusterInfo(sql.DBName, clusterID, defaultClusterType)
+		// init db service
+		dbService := metadata.NewDBServiceWithDefault()
+		// get db info, the database may not be registered in the metadata, it should not fail the whole healthcheck
+		err = dbService.GetByNameAndClusterInfo(ctx, sql.DBName, clusterID, defaultClusterType)
 		if err != nil {
-			return err
-		}
-		if len(dbService.GetDBs()) == constant.ZeroInt {
-			return errors.New(fmt.Sprintf("could not find db info. db_name: %s, cluster_id: %d, cluster_type: %d",
-				sql.DBName, clusterID, defaultClusterType))
+			log.Warnf("healthcheck DefaultEngine.checkSlowQuery(): could not find db info, the slow query will not be advised. db_name: %s, cluster_id: %d, cluster_type: %d\n%s",
+				sql.DBName, clusterID, defaultClusterType, err.Error())
+			continue
 		}
 		// get db id
 		dbID := dbService.GetDBs()[constant.ZeroInt].Identity()
 		// init sql advisor service
 		advisorService := sqladvisor.NewServiceWithDefault()
 		// get advice
-		advice, err := advisorService.Advise(dbID, sql.Example)
+		advice, err := advisorService.Advise(ctx, dbID, sql.Example)
 		if err != nil {
 			return err
 		}
@@ -1418,7 +1404,7 @@ func (de *DefaultEngine) checkSlowQuery() error {
 		de.result.SlowQueryAdvice += advice + constant.CommaString
 	}
 
-	strings.Trim(de.result.SlowQueryAdvice, constant.CommaString)
+	de.result.SlowQueryAdvice = strings.Trim(de.result.SlowQueryAdvice, constant.CommaString)
 
 	return nil
 }
@@ -1442,7 +1428,7 @@ func (de *DefaultEngine) summarize() {
 }
 
 // postRun performs post-run actions, for now, it ony saves healthcheck result to the middleware
-func (de *DefaultEngine) postRun() error {
+func (de *DefaultEngine) postRun(ctx context.Context) error {
 	// save result
-	return de.Repository.SaveResult(de.result)
+	return de.Repository.SaveResult(ctx, de.result)
 }
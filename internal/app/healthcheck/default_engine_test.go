@@ -1,6 +1,7 @@
 package healthcheck
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -79,7 +80,7 @@ func TestDefaultEngineConfig_Validate(t *testing.T) {
 		from t_hc_default_engine_config
 		where del_flag = 0;
 	`
-	result, err := defaultEngineConfigRepo.Execute(sql)
+	result, err := defaultEngineConfigRepo.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Validate() failed", err))
 	defaultEngineConfigList := make([]*DefaultItemConfig, result.RowNumber())
 	for i := range defaultEngineConfigList {
@@ -103,11 +104,11 @@ func TestDefaultEngine_Run(t *testing.T) {
 	startTime, _ := now.Parse(serviceStartTime)
 	endTime, _ := now.Parse(serviceEndTime)
 
-	id, err := defaultEngineConfigRepo.InitOperation(serviceID, startTime, endTime, serviceStep)
+	id, err := defaultEngineConfigRepo.InitOperation(context.Background(), serviceID, startTime, endTime, serviceStep)
 	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
 
 	mysqlServerService := metadata.NewMySQLServerService(mysqlServerRepo)
-	err = mysqlServerService.GetByID(1)
+	err = mysqlServerService.GetByID(context.Background(), 1)
 	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
 	mysqlServer := mysqlServerService.GetMySQLServers()[constant.ZeroInt]
 	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
@@ -115,7 +116,7 @@ func TestDefaultEngine_Run(t *testing.T) {
 	applicationMySQLConn, err := mysql.NewConn(applicationMysqlAddr, applicationMysqlDBName, applicationMysqlDBUser, applicationMysqlDBPass)
 	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
 
-	monitorSystem, err := mysqlServer.GetMonitorSystem()
+	monitorSystem, err := mysqlServer.GetMonitorSystem(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
 	var (
 		monitorPrometheusConn *prometheus.Conn
@@ -150,8 +151,8 @@ func TestDefaultEngine_Run(t *testing.T) {
 		asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
 
 		operationInfo := NewOperationInfo(id, mysqlServer, monitorSystem, startTime, endTime, serviceStep)
-		defaultEngine := NewDefaultEngine(defaultEngineConfigRepo, operationInfo, applicationMySQLConn, monitorPrometheusConn, monitorClickhouseConn, monitorMySQLConn)
-		err = defaultEngine.run()
+		defaultEngine := NewDefaultEngine(defaultEngineConfigRepo, operationInfo, applicationMySQLConn, applicationMySQLConn, monitorPrometheusConn, monitorClickhouseConn, monitorMySQLConn)
+		err = defaultEngine.run(context.Background())
 		asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
 	}
 }
@@ -1,16 +1,22 @@
 package healthcheck
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/romberli/das/global"
 	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/tracing"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
 	"github.com/romberli/log"
 )
 
+// ErrDataNotExists is returned when the health check result to get does not exist
+var ErrDataNotExists = errors.New("data does not exists")
+
 var _ healthcheck.Repository = (*Repository)(nil)
 
 // Repository for health check
@@ -29,9 +35,11 @@ func NewRepositoryWithGlobal() *Repository {
 }
 
 // Execute executes given command and placeholders on the middleware
-func (r *Repository) Execute(command string, args ...interface{}) (middleware.Result, error) {
+func (r *Repository) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
 	conn, err := r.Database.Get()
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
 	defer func() {
@@ -41,7 +49,10 @@ func (r *Repository) Execute(command string, args ...interface{}) (middleware.Re
 		}
 	}()
 
-	return conn.Execute(command, args...)
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
 }
 
 // Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
@@ -50,7 +61,7 @@ func (r *Repository) Transaction() (middleware.Transaction, error) {
 }
 
 // GetResultByOperationID gets a Result by the operationID from the middleware
-func (r *Repository) GetResultByOperationID(operationID int) (healthcheck.Result, error) {
+func (r *Repository) GetResultByOperationID(ctx context.Context, operationID int) (healthcheck.Result, error) {
 	sql := `
 		select id, operation_id, weighted_average_score, db_config_score, db_config_data, 
 		db_config_advice, cpu_usage_score, cpu_usage_data, cpu_usage_high, io_util_score,
@@ -65,15 +76,15 @@ func (r *Repository) GetResultByOperationID(operationID int) (healthcheck.Result
 		and operation_id = ? 
 		order by id;
 	`
-	log.Debugf("healthCheck Repository.GetResultByOperationID select sql: \n%s\nplaceholders: %s", sql, operationID)
+	tracing.Logger(ctx).Debugf("healthCheck Repository.GetResultByOperationID() select sql: \n%s\nplaceholders: %d", sql, operationID)
 
-	result, err := r.Execute(sql, operationID)
+	result, err := r.Execute(ctx, sql, operationID)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, fmt.Errorf("healthCheck Repository.GetResultByOperationID(): data does not exists, operation_id: %d", operationID)
+		return nil, fmt.Errorf("healthCheck Repository.GetResultByOperationID(): %w, operation_id: %d", ErrDataNotExists, operationID)
 	case 1:
 		hcInfo := NewEmptyResultWithRepo(r)
 		// map to struct
@@ -89,11 +100,11 @@ func (r *Repository) GetResultByOperationID(operationID int) (healthcheck.Result
 }
 
 // IsRunning gets status by the mysqlServerID from the middleware
-func (r *Repository) IsRunning(mysqlServerID int) (bool, error) {
+func (r *Repository) IsRunning(ctx context.Context, mysqlServerID int) (bool, error) {
 	sql := `select count(1) from t_hc_operation_info where del_flag = 0 and mysql_server_id = ? and status = 1;`
-	log.Debugf("healthCheck Repository.IsRunning() select sql: \n%s\nplaceholders: %s", sql, mysqlServerID)
+	tracing.Logger(ctx).Debugf("healthCheck Repository.IsRunning() select sql: \n%s\nplaceholders: %d", sql, mysqlServerID)
 
-	result, err := r.Execute(sql, mysqlServerID)
+	result, err := r.Execute(ctx, sql, mysqlServerID)
 	if err != nil {
 		return false, err
 	}
@@ -103,15 +114,15 @@ func (r *Repository) IsRunning(mysqlServerID int) (bool, error) {
 }
 
 // InitOperation creates a operationInfo in the middleware
-func (r *Repository) InitOperation(mysqlServerID int, startTime, endTime time.Time, step time.Duration) (int, error) {
+func (r *Repository) InitOperation(ctx context.Context, mysqlServerID int, startTime, endTime time.Time, step time.Duration) (int, error) {
 	startTimeStr := startTime.Format(constant.TimeLayoutSecond)
 	endTimeStr := endTime.Format(constant.TimeLayoutSecond)
 	stepInt := int(step.Seconds())
 
 	sql := `insert into t_hc_operation_info(mysql_server_id, start_time, end_time, step) values(?, ?, ?, ?);`
-	log.Debugf("healthCheck Repository.InitOperation() insert sql: \n%s\nplaceholders: %s, %s, %s, %s", sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
+	tracing.Logger(ctx).Debugf("healthCheck Repository.InitOperation() insert sql: \n%s\nplaceholders: %d, %s, %s, %d", sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
 
-	_, err := r.Execute(sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
+	_, err := r.Execute(ctx, sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -120,9 +131,9 @@ func (r *Repository) InitOperation(mysqlServerID int, startTime, endTime time.Ti
 		select id from t_hc_operation_info where del_flag = 0 and 
 		mysql_server_id = ? and start_time = ? and end_time = ? and step = ?;
 	`
-	log.Debugf("healthCheck Repository.InitOperation() select sql: \n%s\nplaceholders: %s, %s, %s, %s", sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
+	tracing.Logger(ctx).Debugf("healthCheck Repository.InitOperation() select sql: \n%s\nplaceholders: %d, %s, %s, %d", sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
 
-	result, err := r.Execute(sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
+	result, err := r.Execute(ctx, sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -131,16 +142,16 @@ func (r *Repository) InitOperation(mysqlServerID int, startTime, endTime time.Ti
 }
 
 // UpdateOperationStatus updates the status and message by the operationID in the middleware
-func (r *Repository) UpdateOperationStatus(operationID int, status int, message string) error {
+func (r *Repository) UpdateOperationStatus(ctx context.Context, operationID int, status int, message string) error {
 	sql := `update t_hc_operation_info set status = ?, message = ? where id = ?;`
-	log.Debugf("healthCheck Repository.UpdateOperationStatus() update sql: \n%s\nplaceholders: %s, %s, %s", sql, operationID, status, message)
-	_, err := r.Execute(sql, status, message, operationID)
+	tracing.Logger(ctx).Debugf("healthCheck Repository.UpdateOperationStatus() update sql: \n%s\nplaceholders: %d, %s, %d", sql, status, message, operationID)
+	_, err := r.Execute(ctx, sql, status, message, operationID)
 
 	return err
 }
 
 // SaveResult saves the result in the middleware
-func (r *Repository) SaveResult(result healthcheck.Result) error {
+func (r *Repository) SaveResult(ctx context.Context, result healthcheck.Result) error {
 	sql := `insert into t_hc_result(operation_id, weighted_average_score, db_config_score, db_config_data, 
 		db_config_advice, cpu_usage_score, cpu_usage_data, cpu_usage_high, io_util_score,
 		io_util_data, io_util_high, disk_capacity_usage_score, disk_capacity_usage_data, 
@@ -151,8 +162,8 @@ func (r *Repository) SaveResult(result healthcheck.Result) error {
 		slow_query_data, slow_query_advice, accurate_review) values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 
 		?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 	`
-	log.Debugf("healthCheck Repository.SaveResult() insert sql: \n%s\nplaceholders: %s, %s, %s, %s, %s, "+
-		"%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s",
+	tracing.Logger(ctx).Debugf("healthCheck Repository.SaveResult() insert sql: \n%s\nplaceholders: %v, %v, %v, %v, %v, "+
+		"%v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v, %v",
 		sql, result.GetOperationID(), result.GetWeightedAverageScore(), result.GetDBConfigScore(), result.GetDBConfigData(),
 		result.GetDBConfigAdvice(), result.GetCPUUsageScore(), result.GetCPUUsageData(), result.GetCPUUsageHigh(),
 		result.GetIOUtilScore(), result.GetIOUtilData(), result.GetIOUtilHigh(), result.GetDiskCapacityUsageScore(),
@@ -164,7 +175,7 @@ func (r *Repository) SaveResult(result healthcheck.Result) error {
 		result.GetAccurateReview())
 
 	// execute
-	_, err := r.Execute(sql, result.GetOperationID(), result.GetWeightedAverageScore(), result.GetDBConfigScore(),
+	_, err := r.Execute(ctx, sql, result.GetOperationID(), result.GetWeightedAverageScore(), result.GetDBConfigScore(),
 		result.GetDBConfigData(), result.GetDBConfigAdvice(), result.GetCPUUsageScore(), result.GetCPUUsageData(),
 		result.GetCPUUsageHigh(), result.GetIOUtilScore(), result.GetIOUtilData(), result.GetIOUtilHigh(),
 		result.GetDiskCapacityUsageScore(), result.GetDiskCapacityUsageData(), result.GetDiskCapacityUsageHigh(),
@@ -178,10 +189,10 @@ func (r *Repository) SaveResult(result healthcheck.Result) error {
 }
 
 // UpdateAccurateReviewByOperationID updates the accurateReview by the operationID in the middleware
-func (r *Repository) UpdateAccurateReviewByOperationID(operationID int, review int) error {
+func (r *Repository) UpdateAccurateReviewByOperationID(ctx context.Context, operationID int, review int) error {
 	sql := `update t_hc_result set accurate_review = ? where operation_id = ?;`
-	log.Debugf("healthCheck Repository.UpdateAccurateReviewByOperationID() update sql: \n%s\nplaceholders: %s, %s", sql, operationID, review)
+	tracing.Logger(ctx).Debugf("healthCheck Repository.UpdateAccurateReviewByOperationID() update sql: \n%s\nplaceholders: %d, %d", sql, review, operationID)
 
-	_, err := r.Execute(sql, review, operationID)
+	_, err := r.Execute(ctx, sql, review, operationID)
 	return err
 }
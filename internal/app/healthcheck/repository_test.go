@@ -1,6 +1,7 @@
 package healthcheck
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -75,20 +76,20 @@ func createResult() error {
 	hcInfo := NewResultWithDefault(defaultResultOperationID, defaultResultWeightedAverageScore, defaultResultDBConfigScore,
 		defaultResultCPUUsageScore, defaultResultIOUtilScore, defaultResultDiskCapacityUsageScore, defaultResultConnectionUsageScore,
 		defaultResultAverageActiveSessionNumScore, defaultResultCacheMissRatioScore, defaultResultTableSizeScore, defaultResultSlowQueryScore, defaultResultAccurateReview)
-	err := repository.SaveResult(hcInfo)
+	err := repository.SaveResult(context.Background(), hcInfo)
 
 	return err
 }
 
 func deleteResultByID(id int) error {
 	sql := `delete from t_hc_result where id = ?`
-	_, err := repository.Execute(sql, id)
+	_, err := repository.Execute(context.Background(), sql, id)
 	return err
 }
 
 func deleteOperationInfoByID(id int) error {
 	sql := `delete from t_hc_operation_info where id = ?`
-	_, err := repository.Execute(sql, id)
+	_, err := repository.Execute(context.Background(), sql, id)
 	return err
 }
 
@@ -106,7 +107,7 @@ func TestRepository_Execute(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := "select 1;"
-	result, err := repository.Execute(sql)
+	result, err := repository.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
 	r, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
@@ -130,7 +131,7 @@ func TestRepository_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	err = tx.Begin()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
-	_, err = tx.Execute(sql, defaultResultOperationID, defaultResultWeightedAverageScore, defaultResultDBConfigScore,
+	_, err = tx.ExecuteContext(context.Background(), sql, defaultResultOperationID, defaultResultWeightedAverageScore, defaultResultDBConfigScore,
 		defaultResultDBConfigData, defaultResultDBConfigAdvice, defaultResultCPUUsageScore, defaultResultCPUUsageData,
 		defaultResultCPUUsageHigh, defaultResultIOUtilScore, defaultResultIOUtilData, defaultResultIOUtilHigh,
 		defaultResultDiskCapacityUsageScore, defaultResultDiskCapacityUsageData, defaultResultDiskCapacityUsageHigh,
@@ -142,7 +143,7 @@ func TestRepository_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if inserted
 	sql = `select operation_id from t_hc_result where operation_id = ?`
-	result, err := tx.Execute(sql, defaultResultOperationID)
+	result, err := tx.ExecuteContext(context.Background(), sql, defaultResultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	operationID, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
@@ -152,7 +153,7 @@ func TestRepository_Transaction(t *testing.T) {
 	err = tx.Rollback()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if rollbacked
-	entity, err := repository.GetResultByOperationID(defaultResultOperationID)
+	entity, err := repository.GetResultByOperationID(context.Background(), defaultResultOperationID)
 	if entity != nil {
 		asst.Fail("test Transaction() failed")
 	}
@@ -163,7 +164,7 @@ func TestRepository_GetResultByOperationID(t *testing.T) {
 
 	err := createResult()
 	asst.Nil(err, common.CombineMessageWithError("test GetResultByOperationID() failed", err))
-	result, err := repository.GetResultByOperationID(defaultResultOperationID)
+	result, err := repository.GetResultByOperationID(context.Background(), defaultResultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetResultByOperationID() failed", err))
 	operationID := result.GetOperationID()
 	asst.Nil(err, common.CombineMessageWithError("test GetResultByOperationID() failed", err))
@@ -177,14 +178,14 @@ func TestRepository_IsRunning(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := `insert into t_hc_operation_info(mysql_server_id, start_time, end_time, step) values(?, ?, ?, ?);`
-	_, err := repository.Execute(sql, defaultResultMysqlServerID, defaultResultStartTime, defaultResultEndTime, defaultResultStep)
+	_, err := repository.Execute(context.Background(), sql, defaultResultMysqlServerID, defaultResultStartTime, defaultResultEndTime, defaultResultStep)
 	asst.Nil(err, common.CombineMessageWithError("test IsRunning() failed", err))
-	result, err := repository.IsRunning(defaultResultMysqlServerID)
+	result, err := repository.IsRunning(context.Background(), defaultResultMysqlServerID)
 	asst.Nil(err, common.CombineMessageWithError("test IsRunning() failed", err))
 	asst.False(result, "test IsRunning() failed")
 	// delete
 	sql = `select id from t_hc_operation_info order by id desc limit 0,1`
-	resultID, err := repository.Execute(sql)
+	resultID, err := repository.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test IsRunning() failed", err))
 	id, err := resultID.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test IsRunning() failed", err))
@@ -199,10 +200,10 @@ func TestRepository_InitOperation(t *testing.T) {
 	endTime, _ := time.ParseInLocation(constant.TimeLayoutSecond, defaultResultEndTime, time.Local)
 	step := time.Duration(int64(defaultResultStep))
 
-	id, err := repository.InitOperation(defaultResultMysqlServerID, startTime, endTime, step)
+	id, err := repository.InitOperation(context.Background(), defaultResultMysqlServerID, startTime, endTime, step)
 	asst.Nil(err, common.CombineMessageWithError("test InitOperation() failed", err))
 	sql := `select mysql_server_id from t_hc_operation_info where id = ?;`
-	result, err := repository.Execute(sql, id)
+	result, err := repository.Execute(context.Background(), sql, id)
 	asst.Nil(err, common.CombineMessageWithError("test InitOperation() failed", err))
 	mysqlServerID, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test InitOperation() failed", err))
@@ -219,12 +220,12 @@ func TestRepository_UpdateOperationStatus(t *testing.T) {
 	endTime, _ := time.ParseInLocation(constant.TimeLayoutSecond, defaultResultEndTime, time.Local)
 	step := time.Duration(int64(defaultResultStep))
 
-	id, err := repository.InitOperation(defaultResultMysqlServerID, startTime, endTime, step)
+	id, err := repository.InitOperation(context.Background(), defaultResultMysqlServerID, startTime, endTime, step)
 	asst.Nil(err, common.CombineMessageWithError("test UpdateOperationStatus() failed", err))
-	err = repository.UpdateOperationStatus(id, newResultStatus, "")
+	err = repository.UpdateOperationStatus(context.Background(), id, newResultStatus, "")
 	asst.Nil(err, common.CombineMessageWithError("test UpdateOperationStatus() failed", err))
 	sql := `select status from t_hc_operation_info where id = ?;`
-	result, err := repository.Execute(sql, id)
+	result, err := repository.Execute(context.Background(), sql, id)
 	asst.Nil(err, common.CombineMessageWithError("test UpdateOperationStatus() failed", err))
 	status, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test UpdateOperationStatus() failed", err))
@@ -239,7 +240,7 @@ func TestRepository_SaveResult(t *testing.T) {
 
 	err := createResult()
 	asst.Nil(err, common.CombineMessageWithError("test SaveResult() failed", err))
-	result, err := repository.GetResultByOperationID(defaultResultOperationID)
+	result, err := repository.GetResultByOperationID(context.Background(), defaultResultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test SaveResult() failed", err))
 	asst.Equal(defaultResultOperationID, result.GetOperationID(), "test SaveResult() failed")
 	// delete
@@ -252,11 +253,11 @@ func TestRepository_UpdateAccurateReviewByOperationID(t *testing.T) {
 
 	err := createResult()
 	asst.Nil(err, common.CombineMessageWithError("test UpdateAccurateReviewByOperationID() failed", err))
-	result, err := repository.GetResultByOperationID(defaultResultOperationID)
+	result, err := repository.GetResultByOperationID(context.Background(), defaultResultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test UpdateAccurateReviewByOperationID() failed", err))
 	err = result.Set(map[string]interface{}{AccurateReviewStruct: newResultAccurateReview})
 	asst.Nil(err, common.CombineMessageWithError("test UpdateAccurateReviewByOperationID() failed", err))
-	err = repository.UpdateAccurateReviewByOperationID(result.GetOperationID(), newResultAccurateReview)
+	err = repository.UpdateAccurateReviewByOperationID(context.Background(), result.GetOperationID(), newResultAccurateReview)
 	asst.Nil(err, common.CombineMessageWithError("test UpdateAccurateReviewByOperationID() failed", err))
 	asst.Equal(newResultAccurateReview, result.GetAccurateReview(), "test UpdateAccurateReviewByOperationID() failed")
 	// delete
@@ -1,6 +1,7 @@
 package healthcheck
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -65,7 +66,7 @@ var rRepo = rInitRepository()
 
 func rCreateService() (*Service, error) {
 	var result = NewResult(rRepo, resultOperationID, resultWeightedAverageScore, resultDBConfigScore, resultDBConfigData, resultDBConfigAdvice, resultCPUUsageScore, resultCPUUsageData, resultCPUUsageHigh, resultIOUtilScore, resultIOUtilData, resultIOUtilHigh, resultDiskCapacityUsageScore, resultDiskCapacityUsageData, resultDiskCapacityUsageHigh, resultConnectionUsageScore, resultConnectionUsageData, resultConnectionUsageHigh, resultAverageActiveSessionNumScore, resultAverageActiveSessionNumData, resultAverageActiveSessionNumHigh, resultCacheMissRatioScore, resultCacheMissRatioData, resultCacheMissRatioHigh, resultTableSizeScore, resultTableSizeData, resultTableSizeHigh, resultSlowQueryScore, resultSlowQueryData, resultSlowQueryAdvice)
-	err := rRepo.SaveResult(result)
+	err := rRepo.SaveResult(context.Background(), result)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +78,7 @@ func rCreateService() (*Service, error) {
 
 func rDeleteHCResultByOperationID(operationID int) error {
 	sql := `delete from t_hc_result where operation_id = ?`
-	_, err := rRepo.Execute(sql, operationID)
+	_, err := rRepo.Execute(context.Background(), sql, operationID)
 	return err
 }
 
@@ -126,7 +127,7 @@ func TestResult_Identity(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test Identity() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test Identity() failed", err))
 	result := service.GetResult()
 	id := result.Identity()
@@ -141,7 +142,7 @@ func TestResult_GetOperationID(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetOperationID() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetOperationID() failed", err))
 	result := service.GetResult()
 	operationID := result.GetOperationID()
@@ -156,7 +157,7 @@ func TestResult_GetWeightedAverageScore(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetWeightedAverageScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetWeightedAverageScore() failed", err))
 	result := service.GetResult()
 	weightedAverageScore := result.GetWeightedAverageScore()
@@ -171,7 +172,7 @@ func TestResult_GetDBConfigScore(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetDBConfigScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetDBConfigScore() failed", err))
 	result := service.GetResult()
 	dbConfigScore := result.GetDBConfigScore()
@@ -186,7 +187,7 @@ func TestResult_GetDBConfigData(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetDBConfigData() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetDBConfigData() failed", err))
 	result := service.GetResult()
 	dbConfigData := result.GetDBConfigData()
@@ -201,7 +202,7 @@ func TestResult_GetDBConfigAdvice(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetDBConfigAdvice() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetDBConfigAdvice() failed", err))
 	result := service.GetResult()
 	dbConfigAdvice := result.GetDBConfigAdvice()
@@ -216,7 +217,7 @@ func TestResult_GetCPUUsageScore(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetCPUUsageScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetCPUUsageScore() failed", err))
 	result := service.GetResult()
 	cpuUsageScore := result.GetCPUUsageScore()
@@ -231,7 +232,7 @@ func TestResult_GetCPUUsageData(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetCPUUsageData() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetCPUUsageData() failed", err))
 	result := service.GetResult()
 	cpuUsageData := result.GetCPUUsageData()
@@ -246,7 +247,7 @@ func TestResult_GetCPUUsageHigh(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetCPUUsageHigh() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetCPUUsageHigh() failed", err))
 	result := service.GetResult()
 	cpuUsageHigh := result.GetCPUUsageHigh()
@@ -261,7 +262,7 @@ func TestResult_GetIOUtilScore(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetIOUtilScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetIOUtilScore() failed", err))
 	result := service.GetResult()
 	ioUtilScore := result.GetIOUtilScore()
@@ -276,7 +277,7 @@ func TestResult_GetIOUtilData(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetIOUtilScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetIOUtilScore() failed", err))
 	result := service.GetResult()
 	ioUtilData := result.GetIOUtilData()
@@ -291,7 +292,7 @@ func TestResult_GetIOUtilHigh(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetIOUtilData() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetIOUtilData() failed", err))
 	result := service.GetResult()
 	ioUtilHigh := result.GetIOUtilHigh()
@@ -306,7 +307,7 @@ func TestResult_GetDiskCapacityUsageScore(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetDiskCapacityUsageScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetDiskCapacityUsageScore() failed", err))
 	result := service.GetResult()
 	diskCapacityUsageScore := result.GetDiskCapacityUsageScore()
@@ -321,7 +322,7 @@ func TestResult_GetDiskCapacityUsageData(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetDiskCapacityUsageScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetDiskCapacityUsageScore() failed", err))
 	result := service.GetResult()
 	diskCapacityUsageData := result.GetDiskCapacityUsageData()
@@ -336,7 +337,7 @@ func TestResult_GetDiskCapacityUsageHigh(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetDiskCapacityUsageHigh() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetDiskCapacityUsageHigh() failed", err))
 	result := service.GetResult()
 	diskCapacityUsageHigh := result.GetDiskCapacityUsageHigh()
@@ -351,7 +352,7 @@ func TestResult_GetConnectionUsageScore(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetConnectionUsageScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetConnectionUsageScore() failed", err))
 	result := service.GetResult()
 	connectionUsageScore := result.GetConnectionUsageScore()
@@ -366,7 +367,7 @@ func TestResult_GetConnectionUsageData(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetConnectionUsageData() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetConnectionUsageData() failed", err))
 	result := service.GetResult()
 	connectionUsageData := result.GetConnectionUsageData()
@@ -381,7 +382,7 @@ func TestResult_GetConnectionUsageHigh(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetConnectionUsageHigh() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetConnectionUsageHigh() failed", err))
 	result := service.GetResult()
 	connectionUsageHigh := result.GetConnectionUsageHigh()
@@ -396,7 +397,7 @@ func TestResult_GetAverageActiveSessionNumScore(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetAverageActiveSessionNumScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetAverageActiveSessionNumScore() failed", err))
 	result := service.GetResult()
 	averageActiveSessionNumScore := result.GetAverageActiveSessionNumScore()
@@ -411,7 +412,7 @@ func TestResult_GetAverageActiveSessionNumData(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetAverageActiveSessionNumData() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetAverageActiveSessionNumData() failed", err))
 	result := service.GetResult()
 	averageActiveSessionNumData := result.GetAverageActiveSessionNumData()
@@ -426,7 +427,7 @@ func TestResult_GetAverageActiveSessionNumHigh(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetAverageActiveSessionNumHigh() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetAverageActiveSessionNumHigh() failed", err))
 	result := service.GetResult()
 	averageActiveSessionNumHigh := result.GetAverageActiveSessionNumHigh()
@@ -441,7 +442,7 @@ func TestResult_GetCacheMissRatioScore(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetCacheMissRatioScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetCacheMissRatioScore() failed", err))
 	result := service.GetResult()
 	cacheMissRatioScore := result.GetCacheMissRatioScore()
@@ -456,7 +457,7 @@ func TestResult_GetCacheMissRatioData(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetCacheMissRatioData() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetCacheMissRatioData() failed", err))
 	result := service.GetResult()
 	cacheMissRatioData := result.GetCacheMissRatioData()
@@ -471,7 +472,7 @@ func TestResult_GetCacheMissRatioHigh(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetCacheMissRatioHigh() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetCacheMissRatioHigh() failed", err))
 	result := service.GetResult()
 	cacheMissRatioHigh := result.GetCacheMissRatioHigh()
@@ -486,7 +487,7 @@ func TestResult_GetTableSizeScore(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetTableSizeScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetTableSizeScore() failed", err))
 	result := service.GetResult()
 	tableSizeScore := result.GetTableSizeScore()
@@ -501,7 +502,7 @@ func TestResult_GetTableSizeData(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetTableSizeData() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetTableSizeData() failed", err))
 	result := service.GetResult()
 	tableSizeData := result.GetTableSizeData()
@@ -516,7 +517,7 @@ func TestResult_GetTableSizeHigh(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetTableSizeHigh() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetTableSizeHigh() failed", err))
 	result := service.GetResult()
 	tableSizeHigh := result.GetTableSizeHigh()
@@ -531,7 +532,7 @@ func TestResult_GetSlowQueryScore(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetSlowQueryScore() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetSlowQueryScore() failed", err))
 	result := service.GetResult()
 	slowQueryScore := result.GetSlowQueryScore()
@@ -546,7 +547,7 @@ func TestResult_GetSlowQueryData(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetSlowQueryData() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetSlowQueryData() failed", err))
 	result := service.GetResult()
 	slowQueryData := result.GetSlowQueryData()
@@ -561,7 +562,7 @@ func TestResult_GetSlowQueryAdvice(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetSlowQueryAdvice() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetSlowQueryAdvice() failed", err))
 	result := service.GetResult()
 	slowQueryAdvice := result.GetSlowQueryAdvice()
@@ -576,7 +577,7 @@ func TestResult_GetAccurateReview(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetAccurateReview() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetAccurateReview() failed", err))
 	result := service.GetResult()
 	accurateReview := result.GetAccurateReview()
@@ -591,7 +592,7 @@ func TestResult_GetDelFlag(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetDelFlag() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetDelFlag() failed", err))
 	result := service.GetResult()
 	delFlag := result.GetDelFlag()
@@ -606,7 +607,7 @@ func TestResult_GetCreateTime(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetCreateTime() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetCreateTime() failed", err))
 	result := service.GetResult()
 	createTime := result.GetCreateTime()
@@ -621,7 +622,7 @@ func TestResult_GetLastUpdateTime(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test GetLastUpdateTime() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetLastUpdateTime() failed", err))
 	result := service.GetResult()
 	lastUpdateTime := result.GetLastUpdateTime()
@@ -636,7 +637,7 @@ func TestResult_Set(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test Set() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test Set() failed", err))
 	result := service.GetResult()
 
@@ -662,7 +663,7 @@ func TestResult_MarshalJSON(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test MarshalJSON() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test MarshalJSON() failed", err))
 	result := service.GetResult()
 	_, err = result.MarshalJSON()
@@ -677,7 +678,7 @@ func TestResult_MarshalJSONWithFields(t *testing.T) {
 
 	service, err := rCreateService()
 	asst.Nil(err, common.CombineMessageWithError("test MarshalJSONWithFields() failed", err))
-	err = service.GetResultByOperationID(resultOperationID)
+	err = service.GetResultByOperationID(context.Background(), resultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test MarshalJSONWithFields() failed", err))
 	result := service.GetResult()
 	_, err = result.MarshalJSONWithFields("ID", "OperationID", "WeightedAverageScore", "DBConfigScore", "DBConfigData", "DBConfigAdvice", "CPUUsageScore", "CPUUsageData", "CPUUsageHigh", "IOUtilScore", "IOUtilData", "IOUtilHigh", "DiskCapacityUsageScore", "DiskCapacityUsageData", "DiskCapacityUsageHigh", "ConnectionUsageScore", "ConnectionUsageData", "ConnectionUsageHigh", "AverageActiveSessionNumScore", "AverageActiveSessionNumData", "AverageActiveSessionNumHigh", "CacheMissRatioScore", "CacheMissRatioData", "CacheMissRatioHigh", "TableSizeScore", "TableSizeData", "TableSizeHigh", "SlowQueryScore", "SlowQueryData", "SlowQueryAdvice")
@@ -0,0 +1,128 @@
+package healthcheck
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/das/pkg/metrics"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+const interruptedMessage = "healthcheck was interrupted because the server shut down before it completed"
+
+// runningOperations are the operations of which the engines are running in this process
+var runningOperations = newOperationTracker()
+
+// operationTracker tracks the running engines, so that they could be waited when the server shuts down
+type operationTracker struct {
+	mutex        sync.Mutex
+	wg           sync.WaitGroup
+	operationIDs map[int]struct{}
+	// ctx is the parent context of the running engines, it is canceled when the engines could not be waited any longer
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// newOperationTracker returns a new *operationTracker
+func newOperationTracker() *operationTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &operationTracker{
+		operationIDs: make(map[int]struct{}),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// run runs the engine of the operation asynchronously and tracks it until the engine returns,
+// the engine outlives the request, so it is not canceled with the context of the request,
+// but it still carries the request id and the span of the context
+func (ot *operationTracker) run(ctx context.Context, operationID int, engine healthcheck.Engine) {
+	ctx = tracing.Detach(ot.ctx, ctx)
+
+	ot.mutex.Lock()
+	ot.operationIDs[operationID] = struct{}{}
+	ot.wg.Add(1)
+	ot.mutex.Unlock()
+
+	go func() {
+		defer func() {
+			ot.mutex.Lock()
+			delete(ot.operationIDs, operationID)
+			ot.mutex.Unlock()
+			ot.wg.Done()
+		}()
+
+		engine.Run(ctx)
+	}()
+}
+
+// getOperationIDs returns the sorted ids of the running operations
+func (ot *operationTracker) getOperationIDs() []int {
+	ot.mutex.Lock()
+	defer ot.mutex.Unlock()
+
+	operationIDs := make([]int, 0, len(ot.operationIDs))
+	for operationID := range ot.operationIDs {
+		operationIDs = append(operationIDs, operationID)
+	}
+	sort.Ints(operationIDs)
+
+	return operationIDs
+}
+
+// wait waits for all the running engines, it returns the error of the context if it is done before the engines return,
+// the engines which are still running are canceled in that case, and their operation ids are returned,
+// the ids are got before canceling, because the canceled engines are no longer tracked once they return
+func (ot *operationTracker) wait(ctx context.Context) ([]int, error) {
+	done := make(chan struct{})
+	go func() {
+		ot.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil, nil
+	case <-ctx.Done():
+		operationIDs := ot.getOperationIDs()
+		ot.cancel()
+		return operationIDs, ctx.Err()
+	}
+}
+
+// WaitRunningOperations waits for the healthcheck operations which are running in this process until they complete or the context is done,
+// the operations which are still running after the context is done are canceled and marked as interrupted,
+// it should be called before the global connection pool is closed
+func WaitRunningOperations(ctx context.Context) error {
+	operationIDs, err := runningOperations.wait(ctx)
+	if err == nil {
+		return nil
+	}
+
+	// the context is already done, so the operations are marked with a new one
+	return interruptOperations(context.Background(), NewRepositoryWithGlobal(), operationIDs)
+}
+
+// interruptOperations marks the operations as interrupted
+func interruptOperations(ctx context.Context, repo healthcheck.Repository, operationIDs []int) error {
+	merr := &multierror.Error{}
+	for _, operationID := range operationIDs {
+		err := repo.UpdateOperationStatus(ctx, operationID, defaultInterruptedStatus, interruptedMessage)
+		if err != nil {
+			merr = multierror.Append(merr, message.NewMessage(msghc.ErrHealthcheckInterruptOperation, operationID, err.Error()))
+			continue
+		}
+		metrics.ObserveHealthcheckOperation(metrics.HealthcheckStatusInterrupted)
+		log.Warn(message.NewMessage(msghc.InfoHealthcheckInterruptOperation, operationID).Error())
+	}
+
+	return merr.ErrorOrNil()
+}
@@ -0,0 +1,77 @@
+package healthcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+)
+
+type testBlockingEngine struct {
+	release chan struct{}
+}
+
+func (tbe *testBlockingEngine) Run(ctx context.Context) {
+	select {
+	case <-tbe.release:
+	case <-ctx.Done():
+	}
+}
+
+type testStatusRepo struct {
+	healthcheck.Repository
+	statuses map[int]int
+}
+
+func (tsr *testStatusRepo) UpdateOperationStatus(ctx context.Context, operationID int, status int, message string) error {
+	tsr.statuses[operationID] = status
+
+	return nil
+}
+
+func TestRunningAll(t *testing.T) {
+	TestOperationTracker(t)
+	TestInterruptOperations(t)
+}
+
+func TestOperationTracker(t *testing.T) {
+	asst := assert.New(t)
+
+	ot := newOperationTracker()
+	engine1 := &testBlockingEngine{release: make(chan struct{})}
+	engine2 := &testBlockingEngine{release: make(chan struct{})}
+	reqCtx, reqCancel := context.WithCancel(context.Background())
+	ot.run(reqCtx, 2, engine2)
+	ot.run(reqCtx, 1, engine1)
+	asst.Equal([]int{1, 2}, ot.getOperationIDs(), "test getOperationIDs() failed")
+	// the engines are not canceled with the context of the request
+	reqCancel()
+	close(engine1.release)
+	asst.Eventually(func() bool { return len(ot.getOperationIDs()) == 1 }, time.Second, time.Millisecond, "test run() failed")
+	asst.Equal([]int{2}, ot.getOperationIDs(), "test getOperationIDs() failed")
+	// the engines are still running when the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	operationIDs, err := ot.wait(ctx)
+	asst.Equal(context.DeadlineExceeded, err, "test wait() failed")
+	// the operations which were running when waiting timed out are returned, though the canceled engines may have returned
+	asst.Equal([]int{2}, operationIDs, "test wait() failed")
+	// the running engines are canceled after waiting timed out
+	operationIDs, err = ot.wait(context.Background())
+	asst.Nil(err, common.CombineMessageWithError("test wait() failed", err))
+	asst.Nil(operationIDs, "test wait() failed")
+	asst.Empty(ot.getOperationIDs(), "test getOperationIDs() failed")
+}
+
+func TestInterruptOperations(t *testing.T) {
+	asst := assert.New(t)
+
+	repo := &testStatusRepo{statuses: make(map[int]int)}
+	err := interruptOperations(context.Background(), repo, []int{1, 2})
+	asst.Nil(err, common.CombineMessageWithError("test interruptOperations() failed", err))
+	asst.Equal(map[int]int{1: defaultInterruptedStatus, 2: defaultInterruptedStatus}, repo.statuses, "test interruptOperations() failed")
+}
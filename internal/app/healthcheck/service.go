@@ -1,6 +1,7 @@
 package healthcheck
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	depmeta "github.com/romberli/das/internal/dependency/metadata"
 	"github.com/romberli/das/pkg/message"
 	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/das/pkg/metrics"
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware/clickhouse"
@@ -26,6 +28,7 @@ const (
 	defaultMonitorMySQLDBName      = "pmm"
 	defaultSuccessStatus           = 2
 	defaultFailedStatus            = 3
+	defaultInterruptedStatus       = 4
 )
 
 var _ healthcheck.Service = (*Service)(nil)
@@ -85,10 +88,10 @@ func (s *Service) GetResult() healthcheck.Result {
 }
 
 // GetResultByOperationID gets the result of given operation id
-func (s *Service) GetResultByOperationID(id int) error {
+func (s *Service) GetResultByOperationID(ctx context.Context, id int) error {
 	var err error
 
-	s.Result, err = s.Repository.GetResultByOperationID(id)
+	s.Result, err = s.Repository.GetResultByOperationID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -98,47 +101,71 @@ func (s *Service) GetResultByOperationID(id int) error {
 
 // Check performs healthcheck on the mysql server with given mysql server id,
 // initiating is synchronous, actual running is asynchronous
-func (s *Service) Check(mysqlServerID int, startTime, endTime time.Time, step time.Duration) error {
-	return s.check(mysqlServerID, startTime, endTime, step)
+func (s *Service) Check(ctx context.Context, mysqlServerID int, startTime, endTime time.Time, step time.Duration) error {
+	return s.check(ctx, mysqlServerID, startTime, endTime, step)
 }
 
 // CheckByHostInfo performs healthcheck on the mysql server with given mysql server id,
 // initiating is synchronous, actual running is asynchronous
-func (s *Service) CheckByHostInfo(hostIP string, portNum int, startTime, endTime time.Time, step time.Duration) error {
+func (s *Service) CheckByHostInfo(ctx context.Context, hostIP string, portNum int, startTime, endTime time.Time, step time.Duration) error {
 	// init mysql server service
 	mss := metadata.NewMySQLServerServiceWithDefault()
 	// get entities
-	err := mss.GetByHostInfo(hostIP, portNum)
+	err := mss.GetByHostInfo(ctx, hostIP, portNum)
 	if err != nil {
 		return err
 	}
 	mysqlServerID := mss.MySQLServers[0].Identity()
-	return s.check(mysqlServerID, startTime, endTime, step)
+	return s.check(ctx, mysqlServerID, startTime, endTime, step)
 }
 
 // check performs healthcheck on the mysql server with given mysql server id,
 // initiating is synchronous, actual running is asynchronous
-func (s *Service) check(mysqlServerID int, startTime, endTime time.Time, step time.Duration) error {
+func (s *Service) check(ctx context.Context, mysqlServerID int, startTime, endTime time.Time, step time.Duration) error {
+	// check the lifecycle state of the mysql server before initiating the operation
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err := mysqlServerService.GetByID(ctx, mysqlServerID)
+	if err != nil {
+		return err
+	}
+	err = checkState(mysqlServerService.GetMySQLServers()[constant.ZeroInt])
+	if err != nil {
+		return err
+	}
 	// init
-	err := s.init(mysqlServerID, startTime, endTime, step)
+	err = s.init(ctx, mysqlServerID, startTime, endTime, step)
 	if err != nil {
-		updateErr := s.Repository.UpdateOperationStatus(s.OperationInfo.OperationID, defaultFailedStatus, err.Error())
+		updateErr := s.Repository.UpdateOperationStatus(ctx, s.OperationInfo.OperationID, defaultFailedStatus, err.Error())
 		if updateErr != nil {
 			log.Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
 		}
+		metrics.ObserveHealthcheckOperation(metrics.HealthcheckStatusFailed)
 
 		return err
 	}
-	// run asynchronously
-	go s.Engine.Run()
+	// run asynchronously, the running engines are waited when the server shuts down
+	runningOperations.run(ctx, s.OperationInfo.OperationID, s.Engine)
+
+	return nil
+}
+
+// checkState refuses to check the mysql server which is being deployed or had been decommissioned,
+// the mysql server in maintenance is checked, but the result will be annotated
+func checkState(mysqlServer depmeta.MySQLServer) error {
+	switch mysqlServer.GetState() {
+	case metadata.StateProvisioning:
+		return fmt.Errorf("healthcheck of mysql server is refused, it is still being provisioned. mysql server id: %d", mysqlServer.Identity())
+	case metadata.StateDecommissioned:
+		return fmt.Errorf("healthcheck of mysql server is refused, it had been decommissioned. mysql server id: %d", mysqlServer.Identity())
+	}
 
 	return nil
 }
 
 // init initiates healthcheck operation and engine
-func (s *Service) init(mysqlServerID int, startTime, endTime time.Time, step time.Duration) error {
+func (s *Service) init(ctx context.Context, mysqlServerID int, startTime, endTime time.Time, step time.Duration) error {
 	// check if operation with the same mysql server id is still running
-	isRunning, err := s.Repository.IsRunning(mysqlServerID)
+	isRunning, err := s.Repository.IsRunning(ctx, mysqlServerID)
 	if err != nil {
 		return err
 	}
@@ -146,14 +173,14 @@ func (s *Service) init(mysqlServerID int, startTime, endTime time.Time, step tim
 		return fmt.Errorf("healthcheck of mysql server is still running. mysql server id: %d", mysqlServerID)
 	}
 	// insert operation message
-	id, err := s.Repository.InitOperation(mysqlServerID, startTime, endTime, step)
+	id, err := s.Repository.InitOperation(ctx, mysqlServerID, startTime, endTime, step)
 	if err != nil {
 		return err
 	}
 	// get operation info
 	// init application mysql connection
 	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
-	err = mysqlServerService.GetByID(mysqlServerID)
+	err = mysqlServerService.GetByID(ctx, mysqlServerID)
 	if err != nil {
 		return err
 	}
@@ -163,8 +190,13 @@ func (s *Service) init(mysqlServerID int, startTime, endTime time.Time, step tim
 	if err != nil {
 		return err
 	}
+	// init read mysql connection, it prefers a healthy replica of the same cluster
+	readMySQLConn, err := s.getReadMySQLConn(ctx, mysqlServer, applicationMySQLConn)
+	if err != nil {
+		return err
+	}
 	// get monitor system info
-	monitorSystem, err := mysqlServer.GetMonitorSystem()
+	monitorSystem, err := mysqlServer.GetMonitorSystem(ctx)
 	if err != nil {
 		return err
 	}
@@ -212,11 +244,36 @@ func (s *Service) init(mysqlServerID int, startTime, endTime time.Time, step tim
 	}
 
 	s.OperationInfo = NewOperationInfo(id, mysqlServer, monitorSystem, startTime, endTime, step)
-	s.Engine = NewDefaultEngine(s.Repository, s.OperationInfo, applicationMySQLConn, monitorPrometheusConn, monitorClickhouseConn, monitorMySQLConn)
+	s.Engine = NewDefaultEngine(s.Repository, s.OperationInfo, applicationMySQLConn, readMySQLConn, monitorPrometheusConn, monitorClickhouseConn, monitorMySQLConn)
 
 	return nil
 }
 
+// getReadMySQLConn returns a connection to a healthy readable mysql server of the same cluster,
+// if the chosen server is the checking server itself, the application mysql connection will be reused
+func (s *Service) getReadMySQLConn(ctx context.Context, mysqlServer depmeta.MySQLServer, applicationMySQLConn *mysql.Conn) (*mysql.Conn, error) {
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err := mysqlServerService.GetHealthyReadableByClusterID(
+		ctx,
+		mysqlServer.GetClusterID(),
+		s.getApplicationMySQLUser(),
+		s.getApplicationMySQLPass(),
+		viper.GetInt(config.DBApplicationMySQLMaxReplicationDelayKey),
+		viper.GetBool(config.DBApplicationMySQLAllowPrimaryKey),
+	)
+	if err != nil {
+		return nil, err
+	}
+	readServer := mysqlServerService.GetMySQLServers()[constant.ZeroInt]
+	if readServer.Identity() == mysqlServer.Identity() {
+		return applicationMySQLConn, nil
+	}
+
+	readServerAddr := fmt.Sprintf("%s:%d", readServer.GetHostIP(), readServer.GetPortNum())
+
+	return mysql.NewConn(readServerAddr, constant.EmptyString, s.getApplicationMySQLUser(), s.getApplicationMySQLPass())
+}
+
 // getApplicationMySQLUser returns application mysql user name
 func (s *Service) getApplicationMySQLUser() string {
 	return viper.GetString(config.DBApplicationMySQLUserKey)
@@ -258,8 +315,8 @@ func (s *Service) getMonitorMySQLPass() string {
 }
 
 // ReviewAccurate updates accurate review with given operation id
-func (s *Service) ReviewAccurate(id, review int) error {
-	return s.Repository.UpdateAccurateReviewByOperationID(id, review)
+func (s *Service) ReviewAccurate(ctx context.Context, id, review int) error {
+	return s.Repository.UpdateAccurateReviewByOperationID(ctx, id, review)
 }
 
 // MarshalJSON marshals Service to json bytes
@@ -1,6 +1,7 @@
 package healthcheck
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -80,7 +81,7 @@ func createService() (*Service, error) {
 		defaultResultSlowQueryScore,
 		defaultResultSlowQueryData,
 		defaultResultSlowQueryAdvice)
-	err := repository.SaveResult(result)
+	err := repository.SaveResult(context.Background(), result)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +93,7 @@ func createService() (*Service, error) {
 
 func deleteHCResultByOperationID(operationID int) error {
 	sql := `delete from t_hc_result where operation_id = ?`
-	_, err := repository.Execute(sql, operationID)
+	_, err := repository.Execute(context.Background(), sql, operationID)
 	return err
 }
 
@@ -123,7 +124,7 @@ func TestService_GetResultByOperationID(t *testing.T) {
 
 	service, err := createService()
 	asst.Nil(err, common.CombineMessageWithError("test GetResultByOperationID() failed", err))
-	err = service.GetResultByOperationID(defaultResultOperationID)
+	err = service.GetResultByOperationID(context.Background(), defaultResultOperationID)
 	asst.Nil(err, common.CombineMessageWithError("test GetResultByOperationID() failed", err))
 	result := service.GetResult()
 	asst.Equal(defaultResultOperationID, result.GetOperationID(), common.CombineMessageWithError("test GetResultByOperationID() failed", err))
@@ -158,7 +159,7 @@ func TestService_Check(t *testing.T) {
 	endTime, _ := now.Parse(defaultResultEndTime)
 	step := time.Duration(defaultResultStep) * time.Second
 
-	err = service.Check(defaultResultMysqlServerID, startTime, endTime, step)
+	err = service.Check(context.Background(), defaultResultMysqlServerID, startTime, endTime, step)
 	asst.Nil(err, common.CombineMessageWithError("test Check(mysqlServerID int, startTime, endTime time.Time, step time.Duration) failed", err))
 
 	// delete
@@ -191,9 +192,9 @@ func TestService_ReviewAccurate(t *testing.T) {
 	service, err := createService()
 	asst.Nil(err, common.CombineMessageWithError("test ReviewAccurate(id, review int) failed", err))
 	review := 2
-	err = service.ReviewAccurate(defaultResultOperationID, review)
+	err = service.ReviewAccurate(context.Background(), defaultResultOperationID, review)
 	asst.Nil(err, common.CombineMessageWithError("test ReviewAccurate(id, review int) failed", err))
-	err = service.GetResultByOperationID(defaultResultOperationID)
+	err = service.GetResultByOperationID(context.Background(), defaultResultOperationID)
 	result := service.GetResult()
 	reviewed := result.GetAccurateReview()
 	asst.Equal(review, reviewed, common.CombineMessageWithError("test ReviewAccurate(id, review int) failed", err))
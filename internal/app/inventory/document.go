@@ -0,0 +1,547 @@
+package inventory
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	KindEnv               = "env"
+	KindUser              = "user"
+	KindMonitorSystem     = "monitor_system"
+	KindMiddlewareCluster = "middleware_cluster"
+	KindMiddlewareServer  = "middleware_server"
+	KindMySQLCluster      = "mysql_cluster"
+	KindMySQLServer       = "mysql_server"
+	KindDB                = "db"
+	KindApp               = "app"
+	KindAppDB             = "app_db"
+
+	clusterTypeMySQL      = 1
+	clusterTypeMiddleware = 2
+	// keySeparator separates the parts of the composite natural keys
+	keySeparator = "/"
+)
+
+// Kinds are the kinds of the entities in the inventory document,
+// they are sorted in the order of the dependencies, so the referenced entities always come first
+var Kinds = []string{
+	KindEnv,
+	KindUser,
+	KindMonitorSystem,
+	KindMiddlewareCluster,
+	KindMiddlewareServer,
+	KindMySQLCluster,
+	KindMySQLServer,
+	KindDB,
+	KindApp,
+	KindAppDB,
+}
+
+// sectionNames are the names of the sections of each kind in the document
+var sectionNames = map[string]string{
+	KindEnv:               "envs",
+	KindUser:              "users",
+	KindMonitorSystem:     "monitor_systems",
+	KindMiddlewareCluster: "middleware_clusters",
+	KindMiddlewareServer:  "middleware_servers",
+	KindMySQLCluster:      "mysql_clusters",
+	KindMySQLServer:       "mysql_servers",
+	KindDB:                "dbs",
+	KindApp:               "apps",
+	KindAppDB:             "app_dbs",
+}
+
+// IsValidKind returns if the kind is one of the kinds in the inventory document
+func IsValidKind(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// field is a field of an entity in the inventory document
+type field struct {
+	// Name is the name of the field in the document
+	Name string
+	// Struct is the name of the field in the metadata entity, the metadata services are called with it
+	Struct string
+	// Value is the value of the field, it is the natural key of the referenced entity if Ref is not empty
+	Value interface{}
+	// Ref is the kind of the referenced entity
+	Ref string
+	// Required means the referenced entity must be specified
+	Required bool
+	// Key means the field is a part of the natural key, it must not be empty
+	Key bool
+}
+
+// item is an entity in the inventory document, it is identified by the natural key instead of the id
+type item interface {
+	// key returns the natural key of the entity
+	key() string
+	// fields returns the fields of the entity
+	fields() []*field
+}
+
+// Env is the environment in the inventory document
+type Env struct {
+	EnvName string `json:"env_name" yaml:"env_name"`
+}
+
+func (e *Env) key() string {
+	return e.EnvName
+}
+
+func (e *Env) fields() []*field {
+	return []*field{
+		{Name: "env_name", Struct: "EnvName", Value: e.EnvName, Key: true},
+	}
+}
+
+// User is the user in the inventory document, it is identified by the account name
+type User struct {
+	UserName       string `json:"user_name" yaml:"user_name"`
+	DepartmentName string `json:"department_name" yaml:"department_name"`
+	EmployeeID     string `json:"employee_id" yaml:"employee_id"`
+	AccountName    string `json:"account_name" yaml:"account_name"`
+	Email          string `json:"email" yaml:"email"`
+	Telephone      string `json:"telephone" yaml:"telephone"`
+	Mobile         string `json:"mobile" yaml:"mobile"`
+	Role           int    `json:"role" yaml:"role"`
+}
+
+func (u *User) key() string {
+	return u.AccountName
+}
+
+func (u *User) fields() []*field {
+	return []*field{
+		{Name: "user_name", Struct: "UserName", Value: u.UserName},
+		{Name: "department_name", Struct: "DepartmentName", Value: u.DepartmentName},
+		{Name: "employee_id", Struct: "EmployeeID", Value: u.EmployeeID},
+		{Name: "account_name", Struct: "AccountName", Value: u.AccountName, Key: true},
+		{Name: "email", Struct: "Email", Value: u.Email},
+		{Name: "telephone", Struct: "Telephone", Value: u.Telephone},
+		{Name: "mobile", Struct: "Mobile", Value: u.Mobile},
+		{Name: "role", Struct: "Role", Value: u.Role},
+	}
+}
+
+// MonitorSystem is the monitor system in the inventory document
+type MonitorSystem struct {
+	SystemName  string `json:"system_name" yaml:"system_name"`
+	SystemType  int    `json:"system_type" yaml:"system_type"`
+	HostIP      string `json:"host_ip" yaml:"host_ip"`
+	PortNum     int    `json:"port_num" yaml:"port_num"`
+	PortNumSlow int    `json:"port_num_slow" yaml:"port_num_slow"`
+	BaseURL     string `json:"base_url" yaml:"base_url"`
+	EnvName     string `json:"env_name" yaml:"env_name"`
+}
+
+func (ms *MonitorSystem) key() string {
+	return ms.SystemName
+}
+
+func (ms *MonitorSystem) fields() []*field {
+	return []*field{
+		{Name: "system_name", Struct: "MonitorSystemName", Value: ms.SystemName, Key: true},
+		{Name: "system_type", Struct: "MonitorSystemType", Value: ms.SystemType},
+		{Name: "host_ip", Struct: "MonitorSystemHostIP", Value: ms.HostIP, Key: true},
+		{Name: "port_num", Struct: "MonitorSystemPortNum", Value: ms.PortNum, Key: true},
+		{Name: "port_num_slow", Struct: "MonitorSystemPortNumSlow", Value: ms.PortNumSlow},
+		{Name: "base_url", Struct: "BaseURL", Value: ms.BaseURL},
+		{Name: "env_name", Struct: "EnvID", Value: ms.EnvName, Ref: KindEnv, Required: true},
+	}
+}
+
+// MiddlewareCluster is the middleware cluster in the inventory document, the owner is the account name of the user
+type MiddlewareCluster struct {
+	ClusterName string `json:"cluster_name" yaml:"cluster_name"`
+	Owner       string `json:"owner" yaml:"owner"`
+	EnvName     string `json:"env_name" yaml:"env_name"`
+}
+
+func (mc *MiddlewareCluster) key() string {
+	return mc.ClusterName
+}
+
+func (mc *MiddlewareCluster) fields() []*field {
+	return []*field{
+		{Name: "cluster_name", Struct: "ClusterName", Value: mc.ClusterName, Key: true},
+		{Name: "owner", Struct: "OwnerID", Value: mc.Owner, Ref: KindUser},
+		{Name: "env_name", Struct: "EnvID", Value: mc.EnvName, Ref: KindEnv, Required: true},
+	}
+}
+
+// MiddlewareServer is the middleware server in the inventory document, it is identified by the address
+type MiddlewareServer struct {
+	ClusterName    string `json:"cluster_name" yaml:"cluster_name"`
+	ServerName     string `json:"server_name" yaml:"server_name"`
+	MiddlewareRole int    `json:"middleware_role" yaml:"middleware_role"`
+	HostIP         string `json:"host_ip" yaml:"host_ip"`
+	PortNum        int    `json:"port_num" yaml:"port_num"`
+}
+
+func (ms *MiddlewareServer) key() string {
+	return getAddr(ms.HostIP, ms.PortNum)
+}
+
+func (ms *MiddlewareServer) fields() []*field {
+	return []*field{
+		{Name: "cluster_name", Struct: "ClusterID", Value: ms.ClusterName, Ref: KindMiddlewareCluster, Required: true},
+		{Name: "server_name", Struct: "ServerName", Value: ms.ServerName},
+		{Name: "middleware_role", Struct: "MiddlewareRole", Value: ms.MiddlewareRole},
+		{Name: "host_ip", Struct: "HostIP", Value: ms.HostIP, Key: true},
+		{Name: "port_num", Struct: "PortNum", Value: ms.PortNum, Key: true},
+	}
+}
+
+// MySQLCluster is the mysql cluster in the inventory document, the owner is the account name of the user
+type MySQLCluster struct {
+	ClusterName           string `json:"cluster_name" yaml:"cluster_name"`
+	MiddlewareClusterName string `json:"middleware_cluster_name" yaml:"middleware_cluster_name"`
+	MonitorSystemName     string `json:"monitor_system_name" yaml:"monitor_system_name"`
+	Owner                 string `json:"owner" yaml:"owner"`
+	EnvName               string `json:"env_name" yaml:"env_name"`
+}
+
+func (mc *MySQLCluster) key() string {
+	return mc.ClusterName
+}
+
+func (mc *MySQLCluster) fields() []*field {
+	return []*field{
+		{Name: "cluster_name", Struct: "ClusterName", Value: mc.ClusterName, Key: true},
+		{Name: "middleware_cluster_name", Struct: "MiddlewareClusterID", Value: mc.MiddlewareClusterName, Ref: KindMiddlewareCluster},
+		{Name: "monitor_system_name", Struct: "MonitorSystemID", Value: mc.MonitorSystemName, Ref: KindMonitorSystem},
+		{Name: "owner", Struct: "OwnerID", Value: mc.Owner, Ref: KindUser},
+		{Name: "env_name", Struct: "EnvID", Value: mc.EnvName, Ref: KindEnv, Required: true},
+	}
+}
+
+// MySQLServer is the mysql server in the inventory document, it is identified by the address,
+// the lifecycle state is not in the document, because the state transitions must be validated
+type MySQLServer struct {
+	ClusterName    string `json:"cluster_name" yaml:"cluster_name"`
+	ServerName     string `json:"server_name" yaml:"server_name"`
+	ServiceName    string `json:"service_name" yaml:"service_name"`
+	HostIP         string `json:"host_ip" yaml:"host_ip"`
+	PortNum        int    `json:"port_num" yaml:"port_num"`
+	DeploymentType int    `json:"deployment_type" yaml:"deployment_type"`
+	ServerRole     int    `json:"server_role" yaml:"server_role"`
+	ReadWeight     int    `json:"read_weight" yaml:"read_weight"`
+	ReadOnly       int    `json:"read_only" yaml:"read_only"`
+	CPUCores       int    `json:"cpu_cores" yaml:"cpu_cores"`
+	MemorySize     int    `json:"memory_size" yaml:"memory_size"`
+	DiskSize       int    `json:"disk_size" yaml:"disk_size"`
+	DataDir        string `json:"data_dir" yaml:"data_dir"`
+	Version        string `json:"version" yaml:"version"`
+}
+
+func (ms *MySQLServer) key() string {
+	return getAddr(ms.HostIP, ms.PortNum)
+}
+
+func (ms *MySQLServer) fields() []*field {
+	return []*field{
+		{Name: "cluster_name", Struct: "ClusterID", Value: ms.ClusterName, Ref: KindMySQLCluster, Required: true},
+		{Name: "server_name", Struct: "ServerName", Value: ms.ServerName},
+		{Name: "service_name", Struct: "ServiceName", Value: ms.ServiceName},
+		{Name: "host_ip", Struct: "HostIP", Value: ms.HostIP, Key: true},
+		{Name: "port_num", Struct: "PortNum", Value: ms.PortNum, Key: true},
+		{Name: "deployment_type", Struct: "DeploymentType", Value: ms.DeploymentType},
+		{Name: "server_role", Struct: "ServerRole", Value: ms.ServerRole},
+		{Name: "read_weight", Struct: "ReadWeight", Value: ms.ReadWeight},
+		{Name: "read_only", Struct: "ReadOnly", Value: ms.ReadOnly},
+		{Name: "cpu_cores", Struct: "CPUCores", Value: ms.CPUCores},
+		{Name: "memory_size", Struct: "MemorySize", Value: ms.MemorySize},
+		{Name: "disk_size", Struct: "DiskSize", Value: ms.DiskSize},
+		{Name: "data_dir", Struct: "DataDir", Value: ms.DataDir},
+		{Name: "version", Struct: "Version", Value: ms.Version},
+	}
+}
+
+// DB is the database in the inventory document, it is identified by the database name and the cluster,
+// cluster type 1 means the cluster is a mysql cluster, 2 means the cluster is a middleware cluster
+type DB struct {
+	DBName      string `json:"db_name" yaml:"db_name"`
+	ClusterName string `json:"cluster_name" yaml:"cluster_name"`
+	ClusterType int    `json:"cluster_type" yaml:"cluster_type"`
+	Owner       string `json:"owner" yaml:"owner"`
+	EnvName     string `json:"env_name" yaml:"env_name"`
+}
+
+func (d *DB) key() string {
+	return getDBKey(d.DBName, d.ClusterName, d.ClusterType)
+}
+
+func (d *DB) fields() []*field {
+	return []*field{
+		{Name: "db_name", Struct: "DBName", Value: d.DBName, Key: true},
+		{Name: "cluster_name", Struct: "ClusterID", Value: d.ClusterName, Ref: getClusterKind(d.ClusterType), Required: true},
+		{Name: "cluster_type", Struct: "ClusterType", Value: d.ClusterType},
+		{Name: "owner", Struct: "OwnerID", Value: d.Owner, Ref: KindUser},
+		{Name: "env_name", Struct: "EnvID", Value: d.EnvName, Ref: KindEnv, Required: true},
+	}
+}
+
+// App is the app in the inventory document, the owner is the account name of the user
+type App struct {
+	AppName string `json:"app_name" yaml:"app_name"`
+	Level   int    `json:"level" yaml:"level"`
+	Owner   string `json:"owner" yaml:"owner"`
+}
+
+func (a *App) key() string {
+	return a.AppName
+}
+
+func (a *App) fields() []*field {
+	return []*field{
+		{Name: "app_name", Struct: "AppName", Value: a.AppName, Key: true},
+		{Name: "level", Struct: "Level", Value: a.Level},
+		{Name: "owner", Struct: "OwnerID", Value: a.Owner, Ref: KindUser},
+	}
+}
+
+// AppDB is the map of the app and database in the inventory document
+type AppDB struct {
+	AppName     string `json:"app_name" yaml:"app_name"`
+	DBName      string `json:"db_name" yaml:"db_name"`
+	ClusterName string `json:"cluster_name" yaml:"cluster_name"`
+	ClusterType int    `json:"cluster_type" yaml:"cluster_type"`
+}
+
+func (ad *AppDB) key() string {
+	return ad.AppName + keySeparator + ad.dbKey()
+}
+
+func (ad *AppDB) fields() []*field {
+	return []*field{
+		{Name: "app_name", Struct: appIDStruct, Value: ad.AppName, Ref: KindApp, Required: true},
+		{Name: "db", Struct: dbIDStruct, Value: ad.dbKey(), Ref: KindDB, Required: true},
+	}
+}
+
+// dbKey returns the natural key of the database
+func (ad *AppDB) dbKey() string {
+	return getDBKey(ad.DBName, ad.ClusterName, ad.ClusterType)
+}
+
+// Document is the inventory of the metadata, the entities reference each other by the natural keys,
+// such as names and addresses, so that it could be moved between the das instances
+type Document struct {
+	Envs               []*Env               `json:"envs" yaml:"envs"`
+	Users              []*User              `json:"users" yaml:"users"`
+	MonitorSystems     []*MonitorSystem     `json:"monitor_systems" yaml:"monitor_systems"`
+	MiddlewareClusters []*MiddlewareCluster `json:"middleware_clusters" yaml:"middleware_clusters"`
+	MiddlewareServers  []*MiddlewareServer  `json:"middleware_servers" yaml:"middleware_servers"`
+	MySQLClusters      []*MySQLCluster      `json:"mysql_clusters" yaml:"mysql_clusters"`
+	MySQLServers       []*MySQLServer       `json:"mysql_servers" yaml:"mysql_servers"`
+	DBs                []*DB                `json:"dbs" yaml:"dbs"`
+	Apps               []*App               `json:"apps" yaml:"apps"`
+	AppDBs             []*AppDB             `json:"app_dbs" yaml:"app_dbs"`
+
+	// specified are the names of the fields which are specified in each entity of the decoded document,
+	// the keys are the kinds, the entities of the kinds which are not in it are regarded as fully specified
+	specified map[string][]map[string]bool
+}
+
+// NewEmptyDocument returns an empty *Document
+func NewEmptyDocument() *Document {
+	return &Document{
+		Envs:               []*Env{},
+		Users:              []*User{},
+		MonitorSystems:     []*MonitorSystem{},
+		MiddlewareClusters: []*MiddlewareCluster{},
+		MiddlewareServers:  []*MiddlewareServer{},
+		MySQLClusters:      []*MySQLCluster{},
+		MySQLServers:       []*MySQLServer{},
+		DBs:                []*DB{},
+		Apps:               []*App{},
+		AppDBs:             []*AppDB{},
+	}
+}
+
+// section returns the pointer to the entity slice of the kind
+func (d *Document) section(kind string) (interface{}, error) {
+	switch kind {
+	case KindEnv:
+		return &d.Envs, nil
+	case KindUser:
+		return &d.Users, nil
+	case KindMonitorSystem:
+		return &d.MonitorSystems, nil
+	case KindMiddlewareCluster:
+		return &d.MiddlewareClusters, nil
+	case KindMiddlewareServer:
+		return &d.MiddlewareServers, nil
+	case KindMySQLCluster:
+		return &d.MySQLClusters, nil
+	case KindMySQLServer:
+		return &d.MySQLServers, nil
+	case KindDB:
+		return &d.DBs, nil
+	case KindApp:
+		return &d.Apps, nil
+	case KindAppDB:
+		return &d.AppDBs, nil
+	default:
+		return nil, fmt.Errorf("kind %s is not valid", kind)
+	}
+}
+
+// hasSection returns if the section of the kind exists in the document, a section which is null or missing in the decoded document does not exist
+func (d *Document) hasSection(kind string) bool {
+	section, err := d.section(kind)
+	if err != nil {
+		return false
+	}
+
+	return !reflect.ValueOf(section).Elem().IsNil()
+}
+
+// isSpecified returns if the field of the entity of the kind at the index is specified in the document,
+// the natural keys and the required references are always regarded as specified
+func (d *Document) isSpecified(kind string, index int, f *field) bool {
+	if f.Key || f.Required {
+		return true
+	}
+	specified, ok := d.specified[kind]
+	if !ok || index >= len(specified) {
+		return true
+	}
+
+	return specified[index][f.Name]
+}
+
+// items returns the entities of the kind
+func (d *Document) items(kind string) []item {
+	var items []item
+
+	switch kind {
+	case KindEnv:
+		for _, i := range d.Envs {
+			items = append(items, i)
+		}
+	case KindUser:
+		for _, i := range d.Users {
+			items = append(items, i)
+		}
+	case KindMonitorSystem:
+		for _, i := range d.MonitorSystems {
+			items = append(items, i)
+		}
+	case KindMiddlewareCluster:
+		for _, i := range d.MiddlewareClusters {
+			items = append(items, i)
+		}
+	case KindMiddlewareServer:
+		for _, i := range d.MiddlewareServers {
+			items = append(items, i)
+		}
+	case KindMySQLCluster:
+		for _, i := range d.MySQLClusters {
+			items = append(items, i)
+		}
+	case KindMySQLServer:
+		for _, i := range d.MySQLServers {
+			items = append(items, i)
+		}
+	case KindDB:
+		for _, i := range d.DBs {
+			items = append(items, i)
+		}
+	case KindApp:
+		for _, i := range d.Apps {
+			items = append(items, i)
+		}
+	case KindAppDB:
+		for _, i := range d.AppDBs {
+			items = append(items, i)
+		}
+	}
+
+	return items
+}
+
+// Filter returns a new *Document which only contains the section of the kind, the other sections are null,
+// it returns the document itself if the kind is empty
+func (d *Document) Filter(kind string) (*Document, error) {
+	if kind == constant.EmptyString {
+		return d, nil
+	}
+
+	filtered := &Document{}
+	src, err := d.section(kind)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := filtered.section(kind)
+	if err != nil {
+		return nil, err
+	}
+	copySection(dst, src)
+
+	return filtered, nil
+}
+
+// copySection copies the entity slice of the same kind from src to dst, both of them are the pointers to the slices
+func copySection(dst, src interface{}) {
+	switch s := src.(type) {
+	case *[]*Env:
+		*dst.(*[]*Env) = *s
+	case *[]*User:
+		*dst.(*[]*User) = *s
+	case *[]*MonitorSystem:
+		*dst.(*[]*MonitorSystem) = *s
+	case *[]*MiddlewareCluster:
+		*dst.(*[]*MiddlewareCluster) = *s
+	case *[]*MiddlewareServer:
+		*dst.(*[]*MiddlewareServer) = *s
+	case *[]*MySQLCluster:
+		*dst.(*[]*MySQLCluster) = *s
+	case *[]*MySQLServer:
+		*dst.(*[]*MySQLServer) = *s
+	case *[]*DB:
+		*dst.(*[]*DB) = *s
+	case *[]*App:
+		*dst.(*[]*App) = *s
+	case *[]*AppDB:
+		*dst.(*[]*AppDB) = *s
+	}
+}
+
+// getAddr returns the address of the server
+func getAddr(hostIP string, portNum int) string {
+	return fmt.Sprintf("%s:%d", hostIP, portNum)
+}
+
+// getClusterKind returns the kind of the cluster by the cluster type, it returns an empty string if the cluster type is not valid
+func getClusterKind(clusterType int) string {
+	switch clusterType {
+	case clusterTypeMySQL:
+		return KindMySQLCluster
+	case clusterTypeMiddleware:
+		return KindMiddlewareCluster
+	default:
+		return constant.EmptyString
+	}
+}
+
+// getDBKey returns the natural key of the database, such as mysql_cluster/cluster1/db1
+func getDBKey(dbName, clusterName string, clusterType int) string {
+	clusterKind := getClusterKind(clusterType)
+	if clusterKind == constant.EmptyString {
+		clusterKind = strconv.Itoa(clusterType)
+	}
+
+	return clusterKind + keySeparator + clusterName + keySeparator + dbName
+}
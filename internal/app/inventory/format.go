@@ -0,0 +1,259 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/romberli/go-util/constant"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+
+	// DefaultFormat is used when the format is not specified
+	DefaultFormat = FormatYAML
+
+	ymlExtension = ".yml"
+)
+
+// contentTypes maps the formats to the content types of the http responses
+var contentTypes = map[string]string{
+	FormatJSON: "application/json; charset=utf-8",
+	FormatYAML: "application/x-yaml; charset=utf-8",
+	FormatCSV:  "text/csv; charset=utf-8",
+}
+
+// IsValidFormat returns if the format is supported
+func IsValidFormat(format string) bool {
+	return format == FormatJSON || format == FormatYAML || format == FormatCSV
+}
+
+// GetFormatByFileName returns the format of the file by the extension, it returns the default format if the extension is unknown
+func GetFormatByFileName(fileName string) string {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if ext == ymlExtension {
+		return FormatYAML
+	}
+
+	format := strings.TrimPrefix(ext, constant.DotString)
+	if IsValidFormat(format) {
+		return format
+	}
+
+	return DefaultFormat
+}
+
+// GetContentType returns the content type of the format, it returns the content type of the default format if the format is unknown
+func GetContentType(format string) string {
+	contentType, ok := contentTypes[format]
+	if !ok {
+		return contentTypes[DefaultFormat]
+	}
+
+	return contentType
+}
+
+// Decode decodes the data of the format to *Document, the sections which are missing or null in the data are nil,
+// the fields which are missing in the data are recorded, so that they are not changed when applying the document,
+// csv data contains only one kind of the entities, so the kind must be specified when the format is csv
+func Decode(data []byte, format, kind string) (*Document, error) {
+	doc := &Document{}
+	// sections are the decoded entities of each section, they are used to find out the specified fields
+	var sections map[string][]map[string]interface{}
+
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(doc)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(data, &sections)
+		if err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		err := yaml.UnmarshalStrict(data, doc)
+		if err != nil {
+			return nil, err
+		}
+		err = yaml.Unmarshal(data, &sections)
+		if err != nil {
+			return nil, err
+		}
+	case FormatCSV:
+		section, err := doc.section(kind)
+		if err != nil {
+			return nil, err
+		}
+		specified, err := decodeCSV(data, section)
+		if err != nil {
+			return nil, err
+		}
+		doc.specified = map[string][]map[string]bool{kind: specified}
+
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("format %s is not valid", format)
+	}
+
+	doc.specified = getSpecifiedFields(sections)
+
+	return doc, nil
+}
+
+// getSpecifiedFields returns the names of the fields which are specified in each entity of the decoded sections, the keys are the kinds
+func getSpecifiedFields(sections map[string][]map[string]interface{}) map[string][]map[string]bool {
+	specified := make(map[string][]map[string]bool)
+	for kind, name := range sectionNames {
+		entities, ok := sections[name]
+		if !ok {
+			continue
+		}
+		specified[kind] = make([]map[string]bool, len(entities))
+		for i, entity := range entities {
+			specified[kind][i] = make(map[string]bool, len(entity))
+			for fieldName := range entity {
+				specified[kind][i][fieldName] = true
+			}
+		}
+	}
+
+	return specified
+}
+
+// Encode encodes the document to the data of the format,
+// csv data contains only one kind of the entities, so the kind must be specified when the format is csv
+func (d *Document) Encode(format, kind string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(d, constant.EmptyString, "  ")
+	case FormatYAML:
+		return yaml.Marshal(d)
+	case FormatCSV:
+		section, err := d.section(kind)
+		if err != nil {
+			return nil, err
+		}
+		return encodeCSV(section)
+	default:
+		return nil, fmt.Errorf("format %s is not valid", format)
+	}
+}
+
+// encodeCSV encodes the entity slice to csv, the header is the json tags of the fields,
+// section is the pointer to the entity slice
+func encodeCSV(section interface{}) ([]byte, error) {
+	slice := reflect.ValueOf(section).Elem()
+	typ := slice.Type().Elem().Elem()
+
+	buffer := &bytes.Buffer{}
+	writer := csv.NewWriter(buffer)
+
+	header := make([]string, typ.NumField())
+	for i := range header {
+		header[i] = getCSVName(typ.Field(i))
+	}
+	err := writer.Write(header)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < slice.Len(); i++ {
+		value := slice.Index(i).Elem()
+		record := make([]string, typ.NumField())
+		for j := range record {
+			record[j] = fmt.Sprint(value.Field(j).Interface())
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+
+	return buffer.Bytes(), writer.Error()
+}
+
+// decodeCSV decodes the csv data to the entity slice, the first line must be the header,
+// the columns which are not in the header keep the zero values, section is the pointer to the entity slice,
+// it returns the names of the fields which are specified in each entity, they are the columns in the header
+func decodeCSV(data []byte, section interface{}) ([]map[string]bool, error) {
+	slice := reflect.ValueOf(section).Elem()
+	typ := slice.Type().Elem().Elem()
+	// the section exists even if there is no entity in the data
+	slice.Set(reflect.MakeSlice(slice.Type(), constant.ZeroInt, constant.ZeroInt))
+
+	fieldIndexes := make(map[string]int)
+	for i := 0; i < typ.NumField(); i++ {
+		fieldIndexes[getCSVName(typ.Field(i))] = i
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, len(header))
+	columns := make(map[string]bool, len(header))
+	for i, name := range header {
+		index, ok := fieldIndexes[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("csv column %s is not valid", name)
+		}
+		indexes[i] = index
+		columns[strings.TrimSpace(name)] = true
+	}
+
+	var specified []map[string]bool
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return specified, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value := reflect.New(typ)
+		for i, str := range record {
+			f := value.Elem().Field(indexes[i])
+			switch f.Kind() {
+			case reflect.Int:
+				if str == constant.EmptyString {
+					continue
+				}
+				num, err := strconv.Atoi(str)
+				if err != nil {
+					return nil, fmt.Errorf("csv line %d, column %s must be an integer. value: %s", line, header[i], str)
+				}
+				f.SetInt(int64(num))
+			default:
+				f.SetString(str)
+			}
+		}
+		slice.Set(reflect.Append(slice, value))
+		specified = append(specified, columns)
+	}
+}
+
+// getCSVName returns the csv column name of the struct field, it is the same as the json tag
+func getCSVName(f reflect.StructField) string {
+	return strings.Split(f.Tag.Get(constant.DefaultJSONTag), constant.CommaString)[constant.ZeroInt]
+}
@@ -0,0 +1,84 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAll(t *testing.T) {
+	TestGetFormatByFileName(t)
+	TestDocument_Encode(t)
+	TestDecode(t)
+}
+
+func TestGetFormatByFileName(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Equal(FormatYAML, GetFormatByFileName("inventory.yml"), "test GetFormatByFileName() failed")
+	asst.Equal(FormatJSON, GetFormatByFileName("inventory.JSON"), "test GetFormatByFileName() failed")
+	asst.Equal(FormatCSV, GetFormatByFileName("/tmp/servers.csv"), "test GetFormatByFileName() failed")
+	asst.Equal(DefaultFormat, GetFormatByFileName("inventory"), "test GetFormatByFileName() failed")
+}
+
+func TestDocument_Encode(t *testing.T) {
+	asst := assert.New(t)
+
+	doc, _ := newTestSnapshot().ToDocument()
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		data, err := doc.Encode(format, "")
+		asst.Nil(err, common.CombineMessageWithError("test Encode() failed", err))
+		decoded, err := Decode(data, format, "")
+		asst.Nil(err, common.CombineMessageWithError("test Encode() failed", err))
+		// all the fields of the encoded document are specified
+		for _, kind := range Kinds {
+			for i, item := range decoded.items(kind) {
+				for _, f := range item.fields() {
+					asst.True(decoded.isSpecified(kind, i, f), "test Encode() failed. kind: %s, field: %s", kind, f.Name)
+				}
+			}
+		}
+		decoded.specified = nil
+		asst.Equal(doc, decoded, "test Encode() failed")
+	}
+
+	data, err := doc.Encode(FormatCSV, KindMySQLServer)
+	asst.Nil(err, common.CombineMessageWithError("test Encode() failed", err))
+	asst.Equal("cluster_name,server_name,service_name,host_ip,port_num,deployment_type,server_role,read_weight,"+
+		"read_only,cpu_cores,memory_size,disk_size,data_dir,version\n"+
+		"cluster1,server1,,192.168.137.11,3306,0,1,0,0,0,0,0,,\n", string(data), "test Encode() failed")
+	_, err = doc.Encode(FormatCSV, "")
+	asst.NotNil(err, "test Encode() failed")
+}
+
+func TestDecode(t *testing.T) {
+	asst := assert.New(t)
+
+	data := []byte("host_ip, port_num, cluster_name\n192.168.137.11,3306,cluster1\n192.168.137.12,,cluster1\n")
+	doc, err := Decode(data, FormatCSV, KindMySQLServer)
+	asst.Nil(err, common.CombineMessageWithError("test Decode() failed", err))
+	asst.Equal([]*MySQLServer{
+		{ClusterName: "cluster1", HostIP: "192.168.137.11", PortNum: 3306},
+		{ClusterName: "cluster1", HostIP: "192.168.137.12"},
+	}, doc.MySQLServers, "test Decode() failed")
+	asst.Equal(0, len(doc.Envs), "test Decode() failed")
+
+	_, err = Decode([]byte("host_ip,port\n"), FormatCSV, KindMySQLServer)
+	asst.NotNil(err, "test Decode() failed")
+	_, err = Decode([]byte("host_ip,port_num\n192.168.137.11,abc\n"), FormatCSV, KindMySQLServer)
+	asst.EqualError(err, "csv line 2, column port_num must be an integer. value: abc", "test Decode() failed")
+	_, err = Decode([]byte("envs:\n- env_name: online\n  unknown: 1\n"), FormatYAML, "")
+	asst.NotNil(err, "test Decode() failed")
+
+	// the missing and null sections are not managed when reconciling, the empty sections are
+	doc, err = Decode([]byte("envs: []\nusers: null\n"), FormatYAML, "")
+	asst.Nil(err, common.CombineMessageWithError("test Decode() failed", err))
+	asst.True(doc.hasSection(KindEnv), "test Decode() failed")
+	asst.False(doc.hasSection(KindUser), "test Decode() failed")
+	asst.False(doc.hasSection(KindApp), "test Decode() failed")
+	doc, err = Decode([]byte(`{"dbs": [], "apps": null}`), FormatJSON, "")
+	asst.Nil(err, common.CombineMessageWithError("test Decode() failed", err))
+	asst.True(doc.hasSection(KindDB), "test Decode() failed")
+	asst.False(doc.hasSection(KindApp), "test Decode() failed")
+}
@@ -0,0 +1,492 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/internal/app/metadata"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
+)
+
+const (
+	ActionNone   = "none"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	// lastUpdateTimeLayout is the layout of the last update time in the conflicts
+	lastUpdateTimeLayout = "2006-01-02 15:04:05.000000"
+
+	appIDStruct = "AppID"
+	dbIDStruct  = "DBID"
+)
+
+// Options are the options of planning the changes
+type Options struct {
+	// Prune means the registered entities which are not in the document are deleted,
+	// only the kinds whose sections exist in the document are pruned, otherwise, the registered entities are kept as they are
+	Prune bool
+	// Since is the time when the document was reviewed, such as the create time of the dry run plan,
+	// the entities which are updated after it are conflicts, it is not checked if it is zero
+	Since time.Time
+}
+
+// ConflictError is returned when the entities to update or delete were changed by others after planning
+type ConflictError struct {
+	Conflicts []string
+}
+
+// Error implements error interface
+func (ce *ConflictError) Error() string {
+	return fmt.Sprintf("the entities were changed after planning, review the plan again. conflicts: %s",
+		strings.Join(ce.Conflicts, constant.CommaString+constant.SpaceString))
+}
+
+// FieldDiff is the old and new value of a changed field
+type FieldDiff struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Change is the proposed change of an entity in the inventory document,
+// the keys of the fields and diff are the field names in the document, the referenced entities are the natural keys,
+// last update time is the time when the registered entity was last updated, it is used to detect the conflicts
+type Change struct {
+	Kind           string                 `json:"kind"`
+	Action         string                 `json:"action"`
+	ID             int                    `json:"id"`
+	Key            string                 `json:"key"`
+	Fields         map[string]interface{} `json:"fields"`
+	Diff           map[string]*FieldDiff  `json:"diff"`
+	LastUpdateTime *time.Time             `json:"last_update_time,omitempty"`
+
+	fields []*field
+}
+
+// newChange returns a new *Change
+func newChange(kind, action string, id int, key string) *Change {
+	return &Change{
+		Kind:   kind,
+		Action: action,
+		ID:     id,
+		Key:    key,
+		Fields: make(map[string]interface{}),
+		Diff:   make(map[string]*FieldDiff),
+	}
+}
+
+// addField adds the field which will be written with the metadata service
+func (c *Change) addField(f *field) {
+	c.Fields[f.Name] = f.Value
+	c.fields = append(c.fields, f)
+}
+
+// Plan is the proposed changes of the metadata to make it the same as the inventory document,
+// the registered entities which are not in the document are deleted only if prune is true
+type Plan struct {
+	DryRun     bool           `json:"dry_run"`
+	Applied    bool           `json:"applied"`
+	Prune      bool           `json:"prune"`
+	CreateTime time.Time      `json:"create_time"`
+	Summary    map[string]int `json:"summary"`
+	Changes    []*Change      `json:"changes"`
+
+	ids map[string]map[string]int
+}
+
+// NewPlan compares the inventory document with the registered metadata and returns the proposed changes,
+// registered is the registered metadata, registry is the identities and last update times of the registered entities,
+// all the problems of the document are returned together, so that they could be fixed at once
+func NewPlan(doc, registered *Document, registry *Registry, options *Options) (*Plan, error) {
+	plan := &Plan{
+		DryRun:     true,
+		Prune:      options.Prune,
+		CreateTime: time.Now(),
+		Summary: map[string]int{
+			ActionNone:   constant.ZeroInt,
+			ActionCreate: constant.ZeroInt,
+			ActionUpdate: constant.ZeroInt,
+			ActionDelete: constant.ZeroInt,
+		},
+		Changes: []*Change{},
+		ids:     make(map[string]map[string]int),
+	}
+
+	merr := &multierror.Error{}
+	var deletes []*Change
+	// declared are the natural keys of the entities which exist after applying the plan
+	declared := make(map[string]map[string]bool)
+	// managed are the kinds which are pruned
+	managed := make(map[string]bool)
+	for _, kind := range Kinds {
+		plan.ids[kind] = make(map[string]int)
+		for k, id := range registry.ids[kind] {
+			plan.ids[kind][k] = id
+		}
+
+		managed[kind] = options.Prune && doc.hasSection(kind)
+		registeredItems := make(map[string]item)
+		declared[kind] = make(map[string]bool)
+		for _, i := range registered.items(kind) {
+			registeredItems[i.key()] = i
+			if !managed[kind] {
+				declared[kind][i.key()] = true
+			}
+		}
+
+		keys := make(map[string]bool)
+		for n, i := range doc.items(kind) {
+			k := i.key()
+			if keys[k] {
+				merr = multierror.Append(merr, fmt.Errorf("%s[%d]: %s is duplicated", kind, n, k))
+				continue
+			}
+			keys[k] = true
+
+			err := validateItem(i, declared)
+			if err != nil {
+				merr = multierror.Append(merr, fmt.Errorf("%s[%d]: %s", kind, n, err.Error()))
+				continue
+			}
+			declared[kind][k] = true
+
+			index := n
+			change := plan.newItemChange(kind, i, registeredItems[k], registry, func(f *field) bool {
+				return doc.isSpecified(kind, index, f)
+			})
+			plan.Summary[change.Action]++
+			plan.Changes = append(plan.Changes, change)
+		}
+
+		if !managed[kind] {
+			continue
+		}
+		for _, i := range registered.items(kind) {
+			if keys[i.key()] {
+				continue
+			}
+			change := newChange(kind, ActionDelete, plan.ids[kind][i.key()], i.key())
+			change.fields = i.fields()
+			change.LastUpdateTime = registry.getLastUpdateTime(kind, i.key())
+			plan.Summary[ActionDelete]++
+			// the referencing entities are deleted before the referenced ones
+			deletes = append([]*Change{change}, deletes...)
+		}
+	}
+	// the registered entities which are kept must not reference the deleted ones
+	for _, kind := range Kinds {
+		if managed[kind] {
+			continue
+		}
+		for _, i := range registered.items(kind) {
+			for _, f := range i.fields() {
+				if f.Ref == constant.EmptyString || f.Value == constant.EmptyString || declared[f.Ref][f.Value.(string)] {
+					continue
+				}
+				merr = multierror.Append(merr, fmt.Errorf("%s %s could not be deleted, it is still referenced by %s %s", f.Ref, f.Value, kind, i.key()))
+			}
+		}
+	}
+	// the entities are deleted after creating and updating, so the referencing entities which are kept,
+	// such as the servers of a renamed cluster, have been moved to the new ones before the old ones are deleted
+	plan.Changes = append(plan.Changes, deletes...)
+
+	return plan, merr.ErrorOrNil()
+}
+
+// validateItem validates the natural key and the references of the entity,
+// declared are the natural keys of the entities which could be referenced
+func validateItem(i item, declared map[string]map[string]bool) error {
+	for _, f := range i.fields() {
+		if f.Key && (f.Value == constant.EmptyString || f.Value == constant.ZeroInt) {
+			return fmt.Errorf("%s must not be empty", f.Name)
+		}
+		if f.Ref == constant.EmptyString {
+			continue
+		}
+
+		k := f.Value.(string)
+		if k == constant.EmptyString {
+			if f.Required {
+				return fmt.Errorf("%s must not be empty", f.Name)
+			}
+			continue
+		}
+		if !declared[f.Ref][k] {
+			return fmt.Errorf("%s %s does not exist in the document or is going to be deleted", f.Ref, k)
+		}
+	}
+
+	if db, ok := i.(*DB); ok && getClusterKind(db.ClusterType) == constant.EmptyString {
+		return fmt.Errorf("cluster type must be %d or %d, %d is not valid", clusterTypeMySQL, clusterTypeMiddleware, db.ClusterType)
+	}
+	if appDB, ok := i.(*AppDB); ok && getClusterKind(appDB.ClusterType) == constant.EmptyString {
+		return fmt.Errorf("cluster type must be %d or %d, %d is not valid", clusterTypeMySQL, clusterTypeMiddleware, appDB.ClusterType)
+	}
+
+	return nil
+}
+
+// newItemChange returns the change which makes the registered entity the same as the entity in the document,
+// registered is nil if the entity has not been registered yet,
+// the fields which are not specified in the document are not changed, or take the default values of the metadata services when creating
+func (p *Plan) newItemChange(kind string, i, registered item, registry *Registry, specified func(f *field) bool) *Change {
+	if registered == nil {
+		change := newChange(kind, ActionCreate, constant.ZeroInt, i.key())
+		for _, f := range i.fields() {
+			if specified(f) {
+				change.addField(f)
+			}
+		}
+
+		return change
+	}
+
+	change := newChange(kind, ActionNone, p.ids[kind][i.key()], i.key())
+	change.LastUpdateTime = registry.getLastUpdateTime(kind, i.key())
+	oldFields := registered.fields()
+	for n, f := range i.fields() {
+		if !specified(f) || oldFields[n].Value == f.Value {
+			continue
+		}
+		change.Action = ActionUpdate
+		change.addField(f)
+		change.Diff[f.Name] = &FieldDiff{Old: oldFields[n].Value, New: f.Value}
+	}
+
+	return change
+}
+
+// CheckSince returns a *ConflictError if any entity to update or delete was updated after the given time
+func (p *Plan) CheckSince(since time.Time) error {
+	if since.IsZero() {
+		return nil
+	}
+
+	var conflicts []string
+	for _, change := range p.Changes {
+		if (change.Action == ActionUpdate || change.Action == ActionDelete) &&
+			change.LastUpdateTime != nil && change.LastUpdateTime.After(since) {
+			conflicts = append(conflicts, fmt.Sprintf("%s %s(last update time: %s)",
+				change.Kind, change.Key, change.LastUpdateTime.Format(lastUpdateTimeLayout)))
+		}
+	}
+	if len(conflicts) > constant.ZeroInt {
+		return &ConflictError{Conflicts: conflicts}
+	}
+
+	return nil
+}
+
+// writer is the common part of the metadata services which apply the changes
+type writer interface {
+	// Create creates an entity with the fields
+	Create(ctx context.Context, fields map[string]interface{}) error
+	// Update updates the fields of the entity of the given id
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
+	// Delete deletes the entity of the given id
+	Delete(ctx context.Context, id int) error
+	// SetAuditor sets the auditor which records the changes made by the service
+	SetAuditor(auditor depaudit.Auditor)
+	// SetIfMatch sets the entity tags which the entity to update or delete must match
+	SetIfMatch(ifMatch string)
+}
+
+// newWriter returns the metadata service of the kind of which the repository is created with given pool,
+// it also returns the function which returns the identity of the entity created by the service,
+// it returns nil if the kind does not have a metadata service, such as the map of the app and database
+func newWriter(kind string, pool middleware.Pool) (writer, func() int) {
+	switch kind {
+	case KindEnv:
+		s := metadata.NewEnvService(metadata.NewEnvRepo(pool))
+		return s, func() int { return s.GetEnvs()[constant.ZeroInt].Identity() }
+	case KindUser:
+		s := metadata.NewUserService(metadata.NewUserRepo(pool))
+		return s, func() int { return s.GetUsers()[constant.ZeroInt].Identity() }
+	case KindMonitorSystem:
+		s := metadata.NewMonitorSystemService(metadata.NewMonitorSystemRepo(pool))
+		return s, func() int { return s.GetMonitorSystems()[constant.ZeroInt].Identity() }
+	case KindMiddlewareCluster:
+		s := metadata.NewMiddlewareClusterService(metadata.NewMiddlewareClusterRepo(pool))
+		return s, func() int { return s.GetMiddlewareClusters()[constant.ZeroInt].Identity() }
+	case KindMiddlewareServer:
+		s := metadata.NewMiddlewareServerService(metadata.NewMiddlewareServerRepo(pool))
+		return s, func() int { return s.GetMiddlewareServers()[constant.ZeroInt].Identity() }
+	case KindMySQLCluster:
+		s := metadata.NewMySQLClusterService(metadata.NewMySQLClusterRepo(pool))
+		return s, func() int { return s.GetMySQLClusters()[constant.ZeroInt].Identity() }
+	case KindMySQLServer:
+		s := metadata.NewMySQLServerService(metadata.NewMySQLServerRepo(pool))
+		return s, func() int { return s.GetMySQLServers()[constant.ZeroInt].Identity() }
+	case KindDB:
+		s := metadata.NewDBService(metadata.NewDBRepo(pool))
+		return s, func() int { return s.GetDBs()[constant.ZeroInt].Identity() }
+	case KindApp:
+		s := metadata.NewAppService(metadata.NewAppRepo(pool))
+		return s, func() int { return s.GetApps()[constant.ZeroInt].Identity() }
+	default:
+		return nil, nil
+	}
+}
+
+// apply applies the changes with the metadata services of which the repositories are created with given pool,
+// so the changes are validated the same as the ones made by the metadata api,
+// the referenced entities are always created and updated before the referencing ones, so the natural keys could be resolved to the identities,
+// and then the entities are deleted in the reverse order, so the deleted entities are no longer referenced
+func (p *Plan) apply(ctx context.Context, pool middleware.Pool, auditor depaudit.Auditor) error {
+	for _, change := range p.Changes {
+		if change.Action == ActionNone {
+			continue
+		}
+
+		var err error
+		if change.Kind == KindAppDB {
+			err = p.applyAppDB(ctx, pool, auditor, change)
+		} else {
+			err = p.applyChange(ctx, pool, auditor, change)
+		}
+		if err != nil {
+			if _, ok := err.(*ConflictError); ok {
+				return err
+			}
+			return fmt.Errorf("%s %s %s failed. error: %s", change.Action, change.Kind, change.Key, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// applyChange creates, updates or deletes the entity with the metadata service of the kind,
+// the entity to update or delete must not be changed after planning
+func (p *Plan) applyChange(ctx context.Context, pool middleware.Pool, auditor depaudit.Auditor, change *Change) error {
+	s, getCreatedID := newWriter(change.Kind, pool)
+	if s == nil {
+		return fmt.Errorf("kind %s is not valid", change.Kind)
+	}
+	s.SetAuditor(auditor)
+	if change.LastUpdateTime != nil {
+		s.SetIfMatch(metadata.GetETag(*change.LastUpdateTime))
+	}
+
+	if change.Action == ActionDelete {
+		return toConflictError(change, s.Delete(ctx, change.ID))
+	}
+
+	fields, err := p.resolve(change.fields)
+	if err != nil {
+		return err
+	}
+	if change.Action == ActionUpdate {
+		return toConflictError(change, s.Update(ctx, change.ID, fields))
+	}
+
+	err = s.Create(ctx, fields)
+	if err != nil {
+		return err
+	}
+	change.ID = getCreatedID()
+	p.ids[change.Kind][change.Key] = change.ID
+
+	return nil
+}
+
+// applyAppDB adds or deletes the map of the app and database with the app service,
+// the deleted map is restored if it is added again
+func (p *Plan) applyAppDB(ctx context.Context, pool middleware.Pool, auditor depaudit.Auditor, change *Change) error {
+	fields, err := p.resolve(change.fields)
+	if err != nil {
+		return err
+	}
+	appID, dbID := fields[appIDStruct].(int), fields[dbIDStruct].(int)
+
+	s := metadata.NewAppService(metadata.NewAppRepo(pool))
+	s.SetAuditor(auditor)
+	if change.Action == ActionDelete {
+		return s.DeleteDB(ctx, appID, dbID)
+	}
+
+	return s.AddDB(ctx, appID, dbID)
+}
+
+// toConflictError returns a *ConflictError if the entity failed to be updated or deleted
+// because it was deleted or updated after planning, otherwise, it returns the error as it is
+func toConflictError(change *Change, err error) error {
+	if errors.Is(err, metadata.ErrDataNotExists) {
+		return &ConflictError{Conflicts: []string{fmt.Sprintf("%s %s(deleted)", change.Kind, change.Key)}}
+	}
+	var ce *metadata.ConflictError
+	if errors.As(err, &ce) {
+		return &ConflictError{Conflicts: []string{fmt.Sprintf("%s %s(%s)", change.Kind, change.Key, ce.Error())}}
+	}
+
+	return err
+}
+
+// resolve returns the fields which are passed to the metadata services, the keys are the names of the fields in the metadata entities,
+// the natural keys of the referenced entities are resolved to the identities, empty optional reference is resolved to 0
+func (p *Plan) resolve(fields []*field) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		resolved[f.Struct] = f.Value
+		if f.Ref == constant.EmptyString {
+			continue
+		}
+
+		k := f.Value.(string)
+		if k == constant.EmptyString {
+			resolved[f.Struct] = constant.ZeroInt
+			continue
+		}
+		id, ok := p.ids[f.Ref][k]
+		if !ok {
+			return nil, fmt.Errorf("%s %s could not be resolved", f.Ref, k)
+		}
+		resolved[f.Struct] = id
+	}
+
+	return resolved, nil
+}
+
+// pendingRecord is a change recorded by the metadata service in the transaction
+type pendingRecord struct {
+	entityType string
+	entityID   int
+	action     string
+	before     interface{}
+	after      interface{}
+}
+
+// pendingAuditor keeps the changes recorded by the metadata services in the transaction,
+// they are recorded by the auditor of the inventory service only after the transaction is committed
+type pendingAuditor struct {
+	records []*pendingRecord
+}
+
+// Record keeps the change until it is flushed
+func (pa *pendingAuditor) Record(ctx context.Context, entityType string, entityID int, action string, before, after interface{}) {
+	pa.records = append(pa.records, &pendingRecord{
+		entityType: entityType,
+		entityID:   entityID,
+		action:     action,
+		before:     before,
+		after:      after,
+	})
+}
+
+// flush records the kept changes with the auditor, it does nothing if the auditor is not set
+func (pa *pendingAuditor) flush(ctx context.Context, auditor depaudit.Auditor) {
+	if auditor == nil {
+		return
+	}
+
+	for _, r := range pa.records {
+		auditor.Record(ctx, r.entityType, r.entityID, r.action, r.before, r.after)
+	}
+}
@@ -0,0 +1,289 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/internal/app/metadata"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+)
+
+// newTestSnapshot returns a snapshot which contains env online, user alice,
+// mysql cluster cluster1 with mysql server 192.168.137.11:3306, database db1 in cluster1 and app app1 which uses db1
+func newTestSnapshot() *Snapshot {
+	return &Snapshot{
+		Envs:  []depmeta.Env{&metadata.EnvInfo{ID: 1, EnvName: "online"}},
+		Users: []depmeta.User{&metadata.UserInfo{ID: 1, UserName: "alice", AccountName: "alice", Role: 2}},
+		MySQLClusters: []depmeta.MySQLCluster{
+			&metadata.MySQLClusterInfo{ID: 1, ClusterName: "cluster1", OwnerID: 1, EnvID: 1},
+		},
+		MySQLServers: []depmeta.MySQLServer{
+			&metadata.MySQLServerInfo{ID: 1, ClusterID: 1, ServerName: "server1", HostIP: "192.168.137.11", PortNum: 3306, ServerRole: 1},
+		},
+		DBs:      []depmeta.DB{&metadata.DBInfo{ID: 1, DBName: "db1", ClusterID: 1, ClusterType: 1, EnvID: 1}},
+		Apps:     []depmeta.App{&metadata.AppInfo{ID: 1, AppName: "app1", Level: 1, OwnerID: 1}},
+		AppDBMap: map[int][]int{1: {1}},
+	}
+}
+
+func TestPlanAll(t *testing.T) {
+	TestSnapshot_ToDocument(t)
+	TestNewPlan(t)
+	TestNewPlan_Invalid(t)
+	TestNewPlan_Prune(t)
+	TestNewPlan_Referenced(t)
+	TestNewPlan_Rename(t)
+	TestPlan_CheckSince(t)
+	TestPlan_resolve(t)
+	TestNewPlan_Unspecified(t)
+	TestToConflictError(t)
+	TestPendingAuditor_flush(t)
+}
+
+func TestSnapshot_ToDocument(t *testing.T) {
+	asst := assert.New(t)
+
+	doc, registry := newTestSnapshot().ToDocument()
+	asst.Equal("alice", doc.MySQLClusters[0].Owner, "test ToDocument() failed")
+	asst.Equal("online", doc.MySQLClusters[0].EnvName, "test ToDocument() failed")
+	asst.Equal("cluster1", doc.MySQLServers[0].ClusterName, "test ToDocument() failed")
+	asst.Equal(1, len(doc.AppDBs), "test ToDocument() failed")
+	asst.Equal("app1/mysql_cluster/cluster1/db1", doc.AppDBs[0].key(), "test ToDocument() failed")
+	asst.Equal(1, registry.ids[KindMySQLServer]["192.168.137.11:3306"], "test ToDocument() failed")
+}
+
+func TestNewPlan(t *testing.T) {
+	asst := assert.New(t)
+
+	registered, registry := newTestSnapshot().ToDocument()
+	doc := NewEmptyDocument()
+	doc.Envs = []*Env{{EnvName: "online"}}
+	doc.MySQLClusters = []*MySQLCluster{{ClusterName: "cluster2", Owner: "alice", EnvName: "online"}}
+	doc.MySQLServers = []*MySQLServer{
+		{ClusterName: "cluster1", ServerName: "server1", HostIP: "192.168.137.11", PortNum: 3306, ServerRole: 2},
+		{ClusterName: "cluster2", ServerName: "server2", HostIP: "192.168.137.12", PortNum: 3306, ServerRole: 1},
+	}
+	doc.DBs = []*DB{{DBName: "db2", ClusterName: "cluster2", ClusterType: 1, EnvName: "online"}}
+	doc.AppDBs = []*AppDB{
+		{AppName: "app1", DBName: "db1", ClusterName: "cluster1", ClusterType: 1},
+		{AppName: "app1", DBName: "db2", ClusterName: "cluster2", ClusterType: 1},
+	}
+
+	plan, err := NewPlan(doc, registered, registry, &Options{})
+	asst.Nil(err, common.CombineMessageWithError("test NewPlan() failed", err))
+	asst.Equal(map[string]int{ActionNone: 2, ActionCreate: 4, ActionUpdate: 1, ActionDelete: 0}, plan.Summary, "test NewPlan() failed")
+	// the referenced entities come first
+	asst.Equal(KindEnv, plan.Changes[0].Kind, "test NewPlan() failed")
+	asst.Equal(KindMySQLCluster, plan.Changes[1].Kind, "test NewPlan() failed")
+
+	update := plan.Changes[2]
+	asst.Equal(ActionUpdate, update.Action, "test NewPlan() failed")
+	asst.Equal(1, update.ID, "test NewPlan() failed")
+	asst.Equal(&FieldDiff{Old: 1, New: 2}, update.Diff["server_role"], "test NewPlan() failed")
+	asst.Equal(1, len(update.Fields), "test NewPlan() failed")
+	asst.Equal(ActionNone, plan.Changes[5].Action, "test NewPlan() failed")
+}
+
+func TestNewPlan_Invalid(t *testing.T) {
+	asst := assert.New(t)
+
+	registered, registry := newTestSnapshot().ToDocument()
+	doc := NewEmptyDocument()
+	doc.MySQLClusters = []*MySQLCluster{{ClusterName: "cluster2", EnvName: "offline"}}
+	doc.MySQLServers = []*MySQLServer{
+		{ClusterName: "cluster1", HostIP: "192.168.137.12", PortNum: 3306},
+		{ClusterName: "cluster1", HostIP: "192.168.137.12", PortNum: 3306},
+		{ClusterName: "cluster3", HostIP: "192.168.137.13", PortNum: 3306},
+	}
+	doc.DBs = []*DB{{DBName: "db2", ClusterName: "cluster1", ClusterType: 3, EnvName: "online"}}
+	doc.Apps = []*App{{Level: 1}}
+
+	_, err := NewPlan(doc, registered, registry, &Options{})
+	asst.NotNil(err, "test NewPlan() failed")
+	asst.Contains(err.Error(), "env offline does not exist", "test NewPlan() failed")
+	asst.Contains(err.Error(), "mysql_server[1]: 192.168.137.12:3306 is duplicated", "test NewPlan() failed")
+	asst.Contains(err.Error(), "mysql_cluster cluster3 does not exist", "test NewPlan() failed")
+	asst.Contains(err.Error(), "cluster type must be 1 or 2, 3 is not valid", "test NewPlan() failed")
+	asst.Contains(err.Error(), "app[0]: app_name must not be empty", "test NewPlan() failed")
+}
+
+func TestNewPlan_Prune(t *testing.T) {
+	asst := assert.New(t)
+
+	registered, registry := newTestSnapshot().ToDocument()
+	// the sections of the mysql servers, databases and app databases are empty, the other sections are not managed
+	doc := &Document{
+		MySQLServers: []*MySQLServer{},
+		DBs:          []*DB{},
+		AppDBs:       []*AppDB{},
+	}
+	plan, err := NewPlan(doc, registered, registry, &Options{Prune: true})
+	asst.Nil(err, common.CombineMessageWithError("test NewPlan() failed", err))
+	asst.Equal(3, plan.Summary[ActionDelete], "test NewPlan() failed")
+	asst.Equal(3, len(plan.Changes), "test NewPlan() failed")
+	// the referencing entities are deleted first
+	asst.Equal(KindAppDB, plan.Changes[0].Kind, "test NewPlan() failed")
+	asst.Equal(KindDB, plan.Changes[1].Kind, "test NewPlan() failed")
+	asst.Equal(KindMySQLServer, plan.Changes[2].Kind, "test NewPlan() failed")
+	asst.Equal(1, plan.Changes[2].ID, "test NewPlan() failed")
+	asst.NotNil(plan.Changes[2].LastUpdateTime, "test NewPlan() failed")
+
+	// nothing is deleted if prune is false
+	plan, err = NewPlan(doc, registered, registry, &Options{})
+	asst.Nil(err, common.CombineMessageWithError("test NewPlan() failed", err))
+	asst.Equal(0, len(plan.Changes), "test NewPlan() failed")
+}
+
+func TestNewPlan_Referenced(t *testing.T) {
+	asst := assert.New(t)
+
+	registered, registry := newTestSnapshot().ToDocument()
+	// db1 is still used by app1, the app databases are not managed
+	doc := &Document{DBs: []*DB{}}
+	_, err := NewPlan(doc, registered, registry, &Options{Prune: true})
+	asst.NotNil(err, "test NewPlan() failed")
+	asst.Contains(err.Error(), "db mysql_cluster/cluster1/db1 could not be deleted, it is still referenced by app_db", "test NewPlan() failed")
+}
+
+func TestNewPlan_Rename(t *testing.T) {
+	asst := assert.New(t)
+
+	registered, registry := newTestSnapshot().ToDocument()
+	// cluster1 is renamed to cluster2, its server is moved to cluster2, and its database and app database are recreated in cluster2
+	doc := &Document{
+		MySQLClusters: []*MySQLCluster{{ClusterName: "cluster2", Owner: "alice", EnvName: "online"}},
+		MySQLServers:  []*MySQLServer{{ClusterName: "cluster2", ServerName: "server1", HostIP: "192.168.137.11", PortNum: 3306, ServerRole: 1}},
+		DBs:           []*DB{{DBName: "db1", ClusterName: "cluster2", ClusterType: 1, EnvName: "online"}},
+		AppDBs:        []*AppDB{{AppName: "app1", DBName: "db1", ClusterName: "cluster2", ClusterType: 1}},
+	}
+	plan, err := NewPlan(doc, registered, registry, &Options{Prune: true})
+	asst.Nil(err, common.CombineMessageWithError("test NewPlan() failed", err))
+	asst.Equal(map[string]int{ActionNone: 0, ActionCreate: 3, ActionUpdate: 1, ActionDelete: 3}, plan.Summary, "test NewPlan() failed")
+	asst.Equal(7, len(plan.Changes), "test NewPlan() failed")
+	// the server is moved to the new cluster before the old cluster is deleted
+	asst.Equal(KindMySQLCluster, plan.Changes[0].Kind, "test NewPlan() failed")
+	asst.Equal(ActionCreate, plan.Changes[0].Action, "test NewPlan() failed")
+	asst.Equal(KindMySQLServer, plan.Changes[1].Kind, "test NewPlan() failed")
+	asst.Equal(ActionUpdate, plan.Changes[1].Action, "test NewPlan() failed")
+	asst.Equal(&FieldDiff{Old: "cluster1", New: "cluster2"}, plan.Changes[1].Diff["cluster_name"], "test NewPlan() failed")
+	// the deletes come last, and the referencing entities are deleted before the referenced ones
+	for i, kind := range []string{KindAppDB, KindDB, KindMySQLCluster} {
+		change := plan.Changes[4+i]
+		asst.Equal(ActionDelete, change.Action, "test NewPlan() failed")
+		asst.Equal(kind, change.Kind, "test NewPlan() failed")
+	}
+	asst.Equal("cluster1", plan.Changes[6].Key, "test NewPlan() failed")
+}
+
+func TestPlan_CheckSince(t *testing.T) {
+	asst := assert.New(t)
+
+	snapshot := newTestSnapshot()
+	lastUpdateTime := time.Date(2021, 6, 1, 12, 0, 0, 0, time.Local)
+	snapshot.MySQLServers[0].(*metadata.MySQLServerInfo).LastUpdateTime = lastUpdateTime
+	registered, registry := snapshot.ToDocument()
+	doc := &Document{MySQLServers: []*MySQLServer{
+		{ClusterName: "cluster1", ServerName: "server1", HostIP: "192.168.137.11", PortNum: 3306, ServerRole: 2},
+	}}
+	plan, err := NewPlan(doc, registered, registry, &Options{})
+	asst.Nil(err, common.CombineMessageWithError("test CheckSince() failed", err))
+
+	err = plan.CheckSince(time.Time{})
+	asst.Nil(err, common.CombineMessageWithError("test CheckSince() failed", err))
+	err = plan.CheckSince(lastUpdateTime)
+	asst.Nil(err, common.CombineMessageWithError("test CheckSince() failed", err))
+	err = plan.CheckSince(lastUpdateTime.Add(-time.Minute))
+	asst.NotNil(err, "test CheckSince() failed")
+	_, ok := err.(*ConflictError)
+	asst.True(ok, "test CheckSince() failed")
+	asst.Contains(err.Error(), "192.168.137.11:3306", "test CheckSince() failed")
+}
+
+func TestPlan_resolve(t *testing.T) {
+	asst := assert.New(t)
+
+	registered, registry := newTestSnapshot().ToDocument()
+	doc := NewEmptyDocument()
+	doc.Apps = []*App{{AppName: "app2", Level: 1}}
+	plan, err := NewPlan(doc, registered, registry, &Options{})
+	asst.Nil(err, common.CombineMessageWithError("test resolve() failed", err))
+
+	fields, err := plan.resolve(plan.Changes[0].fields)
+	asst.Nil(err, common.CombineMessageWithError("test resolve() failed", err))
+	// empty owner is resolved to 0
+	asst.Equal(map[string]interface{}{"AppName": "app2", "Level": 1, "OwnerID": 0}, fields, "test resolve() failed")
+
+	fields, err = plan.resolve((&MySQLServer{ClusterName: "cluster1"}).fields()[:1])
+	asst.Nil(err, common.CombineMessageWithError("test resolve() failed", err))
+	asst.Equal(map[string]interface{}{"ClusterID": 1}, fields, "test resolve() failed")
+}
+
+func TestNewPlan_Unspecified(t *testing.T) {
+	asst := assert.New(t)
+
+	registered, registry := newTestSnapshot().ToDocument()
+	// the server name, server role and the other fields of the mysql server are not specified
+	data := []byte(`{"mysql_servers": [{"cluster_name": "cluster1", "host_ip": "192.168.137.11", "port_num": 3306, "read_weight": 5}, ` +
+		`{"cluster_name": "cluster1", "host_ip": "192.168.137.12", "port_num": 3306}]}`)
+	doc, err := Decode(data, FormatJSON, "")
+	asst.Nil(err, common.CombineMessageWithError("test NewPlan() failed", err))
+	plan, err := NewPlan(doc, registered, registry, &Options{})
+	asst.Nil(err, common.CombineMessageWithError("test NewPlan() failed", err))
+
+	update := plan.Changes[0]
+	asst.Equal(ActionUpdate, update.Action, "test NewPlan() failed")
+	asst.Equal(map[string]interface{}{"read_weight": 5}, update.Fields, "test NewPlan() failed")
+	create := plan.Changes[1]
+	asst.Equal(ActionCreate, create.Action, "test NewPlan() failed")
+	// the metadata service fills the default values of the fields which are not specified
+	fields, err := plan.resolve(create.fields)
+	asst.Nil(err, common.CombineMessageWithError("test NewPlan() failed", err))
+	asst.Equal(map[string]interface{}{"ClusterID": 1, "HostIP": "192.168.137.12", "PortNum": 3306}, fields, "test NewPlan() failed")
+
+	// the columns which are not in the csv header are not specified
+	data = []byte("cluster_name,host_ip,port_num,read_weight\ncluster1,192.168.137.11,3306,5\n")
+	doc, err = Decode(data, FormatCSV, KindMySQLServer)
+	asst.Nil(err, common.CombineMessageWithError("test NewPlan() failed", err))
+	plan, err = NewPlan(doc, registered, registry, &Options{})
+	asst.Nil(err, common.CombineMessageWithError("test NewPlan() failed", err))
+	asst.Equal(map[string]interface{}{"read_weight": 5}, plan.Changes[0].Fields, "test NewPlan() failed")
+}
+
+func TestToConflictError(t *testing.T) {
+	asst := assert.New(t)
+
+	change := newChange(KindMySQLServer, ActionUpdate, 1, "192.168.137.11:3306")
+	err := toConflictError(change, fmt.Errorf("metadata MySQLServerInfo.GetByID(): %w, id: %d", metadata.ErrDataNotExists, 1))
+	_, ok := err.(*ConflictError)
+	asst.True(ok, "test toConflictError() failed")
+	asst.Contains(err.Error(), "mysql_server 192.168.137.11:3306(deleted)", "test toConflictError() failed")
+
+	err = toConflictError(change, &metadata.ConflictError{Table: "t_meta_mysql_server_info", ID: 1})
+	_, ok = err.(*ConflictError)
+	asst.True(ok, "test toConflictError() failed")
+
+	err = toConflictError(change, errors.New("test error"))
+	_, ok = err.(*ConflictError)
+	asst.False(ok, "test toConflictError() failed")
+	asst.Nil(toConflictError(change, nil), "test toConflictError() failed")
+}
+
+func TestPendingAuditor_flush(t *testing.T) {
+	asst := assert.New(t)
+
+	auditor := &pendingAuditor{}
+	auditor.Record(context.Background(), "app", 1, "create", nil, &App{AppName: "app1"})
+	auditor.Record(context.Background(), "app", 1, "add_relation", nil, map[string]int{"app_id": 1, "db_id": 1})
+	// nothing happens if the auditor is not set
+	auditor.flush(context.Background(), nil)
+
+	flushed := &pendingAuditor{}
+	auditor.flush(context.Background(), flushed)
+	asst.Equal(auditor.records, flushed.records, "test flush() failed")
+}
@@ -0,0 +1,49 @@
+package inventory
+
+import (
+	"context"
+
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/dependency/inventory"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+var _ inventory.Repository = (*Repository)(nil)
+
+type Repository struct {
+	Database middleware.Pool
+}
+
+// NewRepository returns *Repository with given middleware.Pool
+func NewRepository(db middleware.Pool) *Repository {
+	return &Repository{Database: db}
+}
+
+// NewRepositoryWithGlobal returns *Repository with global mysql pool
+func NewRepositoryWithGlobal() *Repository {
+	return NewRepository(global.DASMySQLPool)
+}
+
+// Execute executes given command and placeholders on the middleware
+func (r *Repository) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
+	conn, err := r.Database.Get()
+	if err != nil {
+		tracing.EndSpan(span, err)
+		return nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("inventory Repository.Execute(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
+}
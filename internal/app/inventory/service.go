@@ -0,0 +1,187 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/app/audit"
+	"github.com/romberli/das/internal/app/metadata"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/internal/dependency/inventory"
+)
+
+const (
+	PlansStruct    = "Plans"
+	DocumentStruct = "Document"
+)
+
+var _ inventory.Service = (*Service)(nil)
+
+type Service struct {
+	inventory.Repository
+	Plans    []*Plan   `json:"plans"`
+	Document *Document `json:"document"`
+
+	auditor depaudit.Auditor
+}
+
+// NewService returns a new *Service
+func NewService(repo inventory.Repository) *Service {
+	return &Service{
+		Repository: repo,
+		Plans:      []*Plan{},
+		Document:   NewEmptyDocument(),
+	}
+}
+
+// NewServiceWithDefault returns a new *Service with default repository
+func NewServiceWithDefault() *Service {
+	s := NewService(NewRepositoryWithGlobal())
+	s.SetAuditor(audit.NewAuditorWithGlobal(audit.DefaultActor, constant.EmptyString))
+
+	return s
+}
+
+// SetAuditor sets the auditor which records the applied changes
+func (s *Service) SetAuditor(auditor depaudit.Auditor) {
+	s.auditor = auditor
+}
+
+// GetPlans returns the plans of the service
+func (s *Service) GetPlans() []*Plan {
+	return s.Plans
+}
+
+// GetDocument returns the exported inventory document of the service
+func (s *Service) GetDocument() *Document {
+	return s.Document
+}
+
+// Import decodes the inventory document of the format, and plans the changes of the metadata,
+// the registered entities which are not in the document are kept as they are,
+// the changes are applied in a transaction only if dryRun is false,
+// kind must be specified if the format is csv, because the csv data contains only one kind of the entities
+func (s *Service) Import(ctx context.Context, data []byte, format, kind string, dryRun bool) error {
+	return s.reconcile(ctx, data, format, kind, &Options{}, dryRun)
+}
+
+// Reconcile decodes the inventory document of the format as the desired state of the metadata, and plans the changes,
+// the registered entities of the kinds in the document which are not in the document are deleted if prune is true,
+// the entities which were updated after since are conflicts, since is not checked if it is zero,
+// the changes are applied in a transaction only if dryRun is false, nothing is changed if any conflict is detected,
+// kind must be specified if the format is csv, because the csv data contains only one kind of the entities
+func (s *Service) Reconcile(ctx context.Context, data []byte, format, kind string, prune bool, since time.Time, dryRun bool) error {
+	return s.reconcile(ctx, data, format, kind, &Options{Prune: prune, Since: since}, dryRun)
+}
+
+// reconcile plans the changes of the metadata with the options, and applies them if dryRun is false
+func (s *Service) reconcile(ctx context.Context, data []byte, format, kind string, options *Options, dryRun bool) error {
+	if format == FormatCSV && !IsValidKind(kind) {
+		return fmt.Errorf("kind must be one of %v when the format is csv, %s is not valid", Kinds, kind)
+	}
+	doc, err := Decode(data, format, kind)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := s.getSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	registered, registry := snapshot.ToDocument()
+	plan, err := NewPlan(doc, registered, registry, options)
+	if err != nil {
+		return err
+	}
+	s.Plans = []*Plan{plan}
+	err = plan.CheckSince(options.Since)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+
+	return s.apply(ctx, plan)
+}
+
+// apply applies the plan in a transaction, nothing is changed if it fails,
+// the changes are audited only after the transaction is committed
+func (s *Service) apply(ctx context.Context, plan *Plan) error {
+	plan.DryRun = false
+
+	auditor := &pendingAuditor{}
+	err := metadata.ExecuteInTransaction(global.DASMySQLPool, func(pool middleware.Pool) error {
+		return plan.apply(ctx, pool, auditor)
+	})
+	if err != nil {
+		return err
+	}
+
+	plan.Applied = true
+	auditor.flush(ctx, s.auditor)
+
+	return nil
+}
+
+// Export exports the registered metadata as the inventory document, all kinds are exported if kind is empty
+func (s *Service) Export(ctx context.Context, kind string) error {
+	if kind != constant.EmptyString && !IsValidKind(kind) {
+		return fmt.Errorf("kind must be one of %v, %s is not valid", Kinds, kind)
+	}
+
+	snapshot, err := s.getSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	doc, _ := snapshot.ToDocument()
+	s.Document, err = doc.Filter(kind)
+
+	return err
+}
+
+// Encode encodes the exported inventory document to the data of the format
+func (s *Service) Encode(format, kind string) ([]byte, error) {
+	if format == FormatCSV && !IsValidKind(kind) {
+		return nil, fmt.Errorf("kind must be one of %v when the format is csv, %s is not valid", Kinds, kind)
+	}
+
+	return s.Document.Encode(format, kind)
+}
+
+// getSnapshot loads all the registered metadata entities and relationships from the middleware
+func (s *Service) getSnapshot(ctx context.Context) (*Snapshot, error) {
+	snapshot, err := metadata.GetSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		Envs:               snapshot.Envs,
+		Users:              snapshot.Users,
+		MonitorSystems:     snapshot.MonitorSystems,
+		MiddlewareClusters: snapshot.MiddlewareClusters,
+		MiddlewareServers:  snapshot.MiddlewareServers,
+		MySQLClusters:      snapshot.MySQLClusters,
+		MySQLServers:       snapshot.MySQLServers,
+		DBs:                snapshot.DBs,
+		Apps:               snapshot.Apps,
+		AppDBMap:           snapshot.AppDBMap,
+	}, nil
+}
+
+// Marshal marshals Service to json bytes
+func (s *Service) Marshal() ([]byte, error) {
+	return s.MarshalWithFields(PlansStruct, DocumentStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the Service to json bytes
+func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(s, fields...)
+}
@@ -0,0 +1,209 @@
+package inventory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+)
+
+// Snapshot is all the registered metadata entities and relationships
+type Snapshot struct {
+	Envs               []depmeta.Env
+	Users              []depmeta.User
+	MonitorSystems     []depmeta.MonitorSystem
+	MiddlewareClusters []depmeta.MiddlewareCluster
+	MiddlewareServers  []depmeta.MiddlewareServer
+	MySQLClusters      []depmeta.MySQLCluster
+	MySQLServers       []depmeta.MySQLServer
+	DBs                []depmeta.DB
+	Apps               []depmeta.App
+	// AppDBMap is the database identities of each app, the key is the app id
+	AppDBMap map[int][]int
+}
+
+// Registry is the identities and last update times of the registered entities, the keys are the kinds and natural keys
+type Registry struct {
+	ids             map[string]map[string]int
+	lastUpdateTimes map[string]map[string]time.Time
+}
+
+// newRegistry returns an empty *Registry
+func newRegistry() *Registry {
+	r := &Registry{
+		ids:             make(map[string]map[string]int),
+		lastUpdateTimes: make(map[string]map[string]time.Time),
+	}
+	for _, kind := range Kinds {
+		r.ids[kind] = make(map[string]int)
+		r.lastUpdateTimes[kind] = make(map[string]time.Time)
+	}
+
+	return r
+}
+
+// getLastUpdateTime returns the last update time of the registered entity, it returns nil if it is unknown
+func (r *Registry) getLastUpdateTime(kind, key string) *time.Time {
+	lastUpdateTime, ok := r.lastUpdateTimes[kind][key]
+	if !ok {
+		return nil
+	}
+
+	return &lastUpdateTime
+}
+
+// ToDocument converts the snapshot to the inventory document, the identities are replaced by the natural keys,
+// it also returns the registry of the entities, the reference to the entity which does not exist is exported as an empty string
+func (s *Snapshot) ToDocument() (*Document, *Registry) {
+	doc := NewEmptyDocument()
+	registry := newRegistry()
+	// names are the natural keys of the entities, the keys are the kinds and identities
+	names := make(map[string]map[int]string)
+	for _, kind := range Kinds {
+		names[kind] = make(map[int]string)
+	}
+	add := func(kind string, id int, lastUpdateTime time.Time, i item) {
+		registry.ids[kind][i.key()] = id
+		registry.lastUpdateTimes[kind][i.key()] = lastUpdateTime
+		names[kind][id] = i.key()
+	}
+
+	for _, env := range s.Envs {
+		e := &Env{EnvName: env.GetEnvName()}
+		doc.Envs = append(doc.Envs, e)
+		add(KindEnv, env.Identity(), env.GetLastUpdateTime(), e)
+	}
+	for _, user := range s.Users {
+		u := &User{
+			UserName:       user.GetUserName(),
+			DepartmentName: user.GetDepartmentName(),
+			EmployeeID:     user.GetEmployeeID(),
+			AccountName:    user.GetAccountName(),
+			Email:          user.GetEmail(),
+			Telephone:      user.GetTelephone(),
+			Mobile:         user.GetMobile(),
+			Role:           user.GetRole(),
+		}
+		doc.Users = append(doc.Users, u)
+		add(KindUser, user.Identity(), user.GetLastUpdateTime(), u)
+	}
+	for _, monitorSystem := range s.MonitorSystems {
+		ms := &MonitorSystem{
+			SystemName:  monitorSystem.GetSystemName(),
+			SystemType:  monitorSystem.GetSystemType(),
+			HostIP:      monitorSystem.GetHostIP(),
+			PortNum:     monitorSystem.GetPortNum(),
+			PortNumSlow: monitorSystem.GetPortNumSlow(),
+			BaseURL:     monitorSystem.GetBaseURL(),
+			EnvName:     names[KindEnv][monitorSystem.GetEnvID()],
+		}
+		doc.MonitorSystems = append(doc.MonitorSystems, ms)
+		add(KindMonitorSystem, monitorSystem.Identity(), monitorSystem.GetLastUpdateTime(), ms)
+	}
+	for _, middlewareCluster := range s.MiddlewareClusters {
+		mc := &MiddlewareCluster{
+			ClusterName: middlewareCluster.GetClusterName(),
+			Owner:       names[KindUser][middlewareCluster.GetOwnerID()],
+			EnvName:     names[KindEnv][middlewareCluster.GetEnvID()],
+		}
+		doc.MiddlewareClusters = append(doc.MiddlewareClusters, mc)
+		add(KindMiddlewareCluster, middlewareCluster.Identity(), middlewareCluster.GetLastUpdateTime(), mc)
+	}
+	for _, middlewareServer := range s.MiddlewareServers {
+		ms := &MiddlewareServer{
+			ClusterName:    names[KindMiddlewareCluster][middlewareServer.GetClusterID()],
+			ServerName:     middlewareServer.GetServerName(),
+			MiddlewareRole: middlewareServer.GetMiddlewareRole(),
+			HostIP:         middlewareServer.GetHostIP(),
+			PortNum:        middlewareServer.GetPortNum(),
+		}
+		doc.MiddlewareServers = append(doc.MiddlewareServers, ms)
+		add(KindMiddlewareServer, middlewareServer.Identity(), middlewareServer.GetLastUpdateTime(), ms)
+	}
+	for _, mysqlCluster := range s.MySQLClusters {
+		mc := &MySQLCluster{
+			ClusterName:           mysqlCluster.GetClusterName(),
+			MiddlewareClusterName: names[KindMiddlewareCluster][mysqlCluster.GetMiddlewareClusterID()],
+			MonitorSystemName:     names[KindMonitorSystem][mysqlCluster.GetMonitorSystemID()],
+			Owner:                 names[KindUser][mysqlCluster.GetOwnerID()],
+			EnvName:               names[KindEnv][mysqlCluster.GetEnvID()],
+		}
+		doc.MySQLClusters = append(doc.MySQLClusters, mc)
+		add(KindMySQLCluster, mysqlCluster.Identity(), mysqlCluster.GetLastUpdateTime(), mc)
+	}
+	for _, mysqlServer := range s.MySQLServers {
+		ms := &MySQLServer{
+			ClusterName:    names[KindMySQLCluster][mysqlServer.GetClusterID()],
+			ServerName:     mysqlServer.GetServerName(),
+			ServiceName:    mysqlServer.GetServiceName(),
+			HostIP:         mysqlServer.GetHostIP(),
+			PortNum:        mysqlServer.GetPortNum(),
+			DeploymentType: mysqlServer.GetDeploymentType(),
+			ServerRole:     mysqlServer.GetServerRole(),
+			ReadWeight:     mysqlServer.GetReadWeight(),
+			ReadOnly:       mysqlServer.GetReadOnly(),
+			CPUCores:       mysqlServer.GetCPUCores(),
+			MemorySize:     mysqlServer.GetMemorySize(),
+			DiskSize:       mysqlServer.GetDiskSize(),
+			DataDir:        mysqlServer.GetDataDir(),
+			Version:        mysqlServer.GetVersion(),
+		}
+		doc.MySQLServers = append(doc.MySQLServers, ms)
+		add(KindMySQLServer, mysqlServer.Identity(), mysqlServer.GetLastUpdateTime(), ms)
+	}
+	dbs := make(map[int]*DB)
+	for _, db := range s.DBs {
+		clusterName := constant.EmptyString
+		clusterKind := getClusterKind(db.GetClusterType())
+		if clusterKind != constant.EmptyString {
+			clusterName = names[clusterKind][db.GetClusterID()]
+		}
+		d := &DB{
+			DBName:      db.GetDBName(),
+			ClusterName: clusterName,
+			ClusterType: db.GetClusterType(),
+			Owner:       names[KindUser][db.GetOwnerID()],
+			EnvName:     names[KindEnv][db.GetEnvID()],
+		}
+		doc.DBs = append(doc.DBs, d)
+		dbs[db.Identity()] = d
+		add(KindDB, db.Identity(), db.GetLastUpdateTime(), d)
+	}
+	for _, app := range s.Apps {
+		a := &App{
+			AppName: app.GetAppName(),
+			Level:   app.GetLevel(),
+			Owner:   names[KindUser][app.GetOwnerID()],
+		}
+		doc.Apps = append(doc.Apps, a)
+		add(KindApp, app.Identity(), app.GetLastUpdateTime(), a)
+	}
+
+	appIDList := make([]int, 0, len(s.AppDBMap))
+	for appID := range s.AppDBMap {
+		appIDList = append(appIDList, appID)
+	}
+	sort.Ints(appIDList)
+	for _, appID := range appIDList {
+		appName, ok := names[KindApp][appID]
+		if !ok {
+			continue
+		}
+		for _, dbID := range s.AppDBMap[appID] {
+			d, ok := dbs[dbID]
+			if !ok {
+				continue
+			}
+			doc.AppDBs = append(doc.AppDBs, &AppDB{
+				AppName:     appName,
+				DBName:      d.DBName,
+				ClusterName: d.ClusterName,
+				ClusterType: d.ClusterType,
+			})
+		}
+	}
+
+	return doc, registry
+}
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/common"
@@ -126,8 +127,8 @@ func (ai *AppInfo) GetLastUpdateTime() time.Time {
 }
 
 // GetDBIDList gets database identity list that the app uses
-func (ai *AppInfo) GetDBIDList() ([]int, error) {
-	return ai.AppRepo.GetDBIDList(ai.Identity())
+func (ai *AppInfo) GetDBIDList(ctx context.Context) ([]int, error) {
+	return ai.AppRepo.GetDBIDList(ctx, ai.Identity())
 }
 
 // Set sets App with given fields, key is the field name and value is the relevant value of the key
@@ -148,13 +149,13 @@ func (ai *AppInfo) Delete() {
 }
 
 // AddDB adds a new map of the app and database in the middleware
-func (ai *AppInfo) AddDB(dbID int) error {
-	return ai.AppRepo.AddDB(ai.Identity(), dbID)
+func (ai *AppInfo) AddDB(ctx context.Context, dbID int) error {
+	return ai.AppRepo.AddDB(ctx, ai.Identity(), dbID)
 }
 
 // DeleteDB deletes the map of the app and database in the middleware
-func (ai *AppInfo) DeleteDB(dbID int) error {
-	return ai.AppRepo.DeleteDB(ai.Identity(), dbID)
+func (ai *AppInfo) DeleteDB(ctx context.Context, dbID int) error {
+	return ai.AppRepo.DeleteDB(ctx, ai.Identity(), dbID)
 }
 
 // MarshalJSON marshals App to json bytes
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -157,7 +158,7 @@ func TestAppInfo_GetDBIDList(t *testing.T) {
 	asst := assert.New(t)
 
 	appSystemInfo := initNewAppInfo()
-	dbIDList, err := appSystemInfo.GetDBIDList()
+	dbIDList, err := appSystemInfo.GetDBIDList(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test GetDBIDList() failed", err))
 	defaultDBIDList := []int{1, 2}
 	for i := 0; i < len(dbIDList); i++ {
@@ -171,12 +172,12 @@ func TestAppInfo_AddAppDB(t *testing.T) {
 	asst := assert.New(t)
 
 	appSystemInfo := initNewAppInfo()
-	err := appSystemInfo.AddDB(3)
-	dbIDList, err = appSystemInfo.GetDBIDList()
+	err := appSystemInfo.AddDB(context.Background(), 3)
+	dbIDList, err = appSystemInfo.GetDBIDList(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test AddDB() failed", err))
 	asst.Equal(0, len(dbIDList))
 	// delete
-	err = appSystemInfo.DeleteDB(3)
+	err = appSystemInfo.DeleteDB(context.Background(), 3)
 	asst.Nil(err, common.CombineMessageWithError("test AddDB() failed", err))
 }
 
@@ -186,11 +187,11 @@ func TestAppInfo_DeleteAppDB(t *testing.T) {
 	asst := assert.New(t)
 
 	appSystemInfo := initNewAppInfo()
-	err := appSystemInfo.DeleteDB(2)
-	dbIDList, err = appSystemInfo.GetDBIDList()
+	err := appSystemInfo.DeleteDB(context.Background(), 2)
+	dbIDList, err = appSystemInfo.GetDBIDList(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test DeleteDB() failed", err))
 	asst.Equal(0, len(dbIDList))
 	// add
-	err = appSystemInfo.AddDB(2)
+	err = appSystemInfo.AddDB(context.Background(), 2)
 	asst.Nil(err, common.CombineMessageWithError("test DeleteDB() failed", err))
 }
@@ -1,8 +1,10 @@
 package metadata
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
@@ -11,6 +13,8 @@ import (
 
 	"github.com/romberli/das/global"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/tracing"
 )
 
 var _ metadata.AppRepo = (*AppRepo)(nil)
@@ -30,9 +34,11 @@ func NewAppRepoWithGlobal() *AppRepo {
 }
 
 // Execute executes command with arguments on the middleware
-func (ar *AppRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+func (ar *AppRepo) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
 	conn, err := ar.Database.Get()
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
 	defer func() {
@@ -42,7 +48,10 @@ func (ar *AppRepo) Execute(command string, args ...interface{}) (middleware.Resu
 		}
 	}()
 
-	return conn.Execute(command, args...)
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
 }
 
 // Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
@@ -51,16 +60,16 @@ func (ar *AppRepo) Transaction() (middleware.Transaction, error) {
 }
 
 // GetAll gets all apps from the middleware
-func (ar *AppRepo) GetAll() ([]metadata.App, error) {
+func (ar *AppRepo) GetAll(ctx context.Context) ([]metadata.App, error) {
 	sql := `
 		select id, app_name, level, owner_id, del_flag, create_time, last_update_time
 		from t_meta_app_info
 		where del_flag = 0
 		order by id;
 	`
-	log.Debugf("metadata AppRepo.GetAll() sql: \n%s", sql)
+	tracing.Logger(ctx).Debugf("metadata AppRepo.GetAll() sql: \n%s", sql)
 
-	result, err := ar.Execute(sql)
+	result, err := ar.Execute(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -83,23 +92,49 @@ func (ar *AppRepo) GetAll() ([]metadata.App, error) {
 	return appList, nil
 }
 
+// GetByQuery gets the apps which match the query from the middleware,
+// it also returns the total number of the matched apps without pagination
+func (ar *AppRepo) GetByQuery(ctx context.Context, query *filter.Query) ([]metadata.App, int, error) {
+	result, total, err := getByQuery(ctx, ar, "AppRepo", appTable, appColumns, query)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []*AppInfo
+	appInfoList := make([]*AppInfo, result.RowNumber())
+	for i := range appInfoList {
+		appInfoList[i] = NewEmptyAppInfoWithGlobal()
+	}
+	// map to struct
+	err = result.MapToStructSlice(appInfoList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []metadata.App
+	entityList := make([]metadata.App, result.RowNumber())
+	for i := range entityList {
+		entityList[i] = appInfoList[i]
+	}
+
+	return entityList, total, nil
+}
+
 // GetByID gets an app by the identity from the middleware
-func (ar *AppRepo) GetByID(id int) (metadata.App, error) {
+func (ar *AppRepo) GetByID(ctx context.Context, id int) (metadata.App, error) {
 	sql := `
 		select id, app_name, level,owner_id, del_flag, create_time, last_update_time
 		from t_meta_app_info
 		where del_flag = 0
 		and id = ?;
 	`
-	log.Debugf("metadata AppRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, id)
+	tracing.Logger(ctx).Debugf("metadata AppRepo.GetByID() sql: \n%s\nplaceholders: %d", sql, id)
 
-	result, err := ar.Execute(sql, id)
+	result, err := ar.Execute(ctx, sql, id)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata AppInfo.GetByID(): data does not exists, id: %d", id))
+		return nil, fmt.Errorf("metadata AppInfo.GetByID(): %w, id: %d", ErrDataNotExists, id)
 	case 1:
 		appInfo := NewEmptyAppInfoWithGlobal()
 		// map to struct
@@ -115,10 +150,10 @@ func (ar *AppRepo) GetByID(id int) (metadata.App, error) {
 }
 
 // GetID gets the identity with given app name from the middleware
-func (ar *AppRepo) GetID(systemName string) (int, error) {
+func (ar *AppRepo) GetID(ctx context.Context, systemName string) (int, error) {
 	sql := `select id from t_meta_app_info where del_flag = 0 and app_name = ?;`
-	log.Debugf("metadata AppRepo.GetID() select sql: %s", sql)
-	result, err := ar.Execute(sql, systemName)
+	tracing.Logger(ctx).Debugf("metadata AppRepo.GetID() select sql: %s", sql)
+	result, err := ar.Execute(ctx, sql, systemName)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -127,10 +162,10 @@ func (ar *AppRepo) GetID(systemName string) (int, error) {
 }
 
 // GetAppSystemByName gets the app by name from the middleware
-func (ar *AppRepo) GetAppByName(appName string) (metadata.App, error) {
+func (ar *AppRepo) GetAppByName(ctx context.Context, appName string) (metadata.App, error) {
 	sql := `select id from t_meta_app_info where del_flag = 0 and app_name = ?;`
-	log.Debugf("metadata AppRepo.GetAppByName() select sql: %s", sql)
-	result, err := ar.Execute(sql, appName)
+	tracing.Logger(ctx).Debugf("metadata AppRepo.GetAppByName() select sql: %s", sql)
+	result, err := ar.Execute(ctx, sql, appName)
 	if err != nil {
 		return nil, err
 	}
@@ -140,11 +175,11 @@ func (ar *AppRepo) GetAppByName(appName string) (metadata.App, error) {
 		return nil, err
 	}
 
-	return ar.GetByID(id)
+	return ar.GetByID(ctx, id)
 }
 
 // GetDBIDList gets a database identity list that app uses
-func (ar *AppRepo) GetDBIDList(id int) ([]int, error) {
+func (ar *AppRepo) GetDBIDList(ctx context.Context, id int) ([]int, error) {
 	sql := `
 		select db_id
 		from t_meta_app_info ai
@@ -153,8 +188,8 @@ func (ar *AppRepo) GetDBIDList(id int) ([]int, error) {
 		  and adm.del_flag = 0
 		  and ai.id = ?;
 	`
-	log.Debugf("metadata AppRepo.GetDBIDList() select sql: %s", sql)
-	result, err := ar.Execute(sql, id)
+	tracing.Logger(ctx).Debugf("metadata AppRepo.GetDBIDList() select sql: %s", sql)
+	result, err := ar.Execute(ctx, sql, id)
 	if err != nil {
 		return nil, err
 	}
@@ -175,80 +210,101 @@ func (ar *AppRepo) GetDBIDList(id int) ([]int, error) {
 
 }
 
+// GetAppDBMap returns the database identities of each app, the key is the app id
+func (ar *AppRepo) GetAppDBMap(ctx context.Context) (map[int][]int, error) {
+	sql := `
+		select app_id, db_id
+		from t_meta_app_db_map
+		where del_flag = 0
+		order by app_id, db_id;
+	`
+	tracing.Logger(ctx).Debugf("metadata AppRepo.GetAppDBMap() select sql: %s", sql)
+	result, err := ar.Execute(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	appDBMap := make(map[int][]int)
+	for row := 0; row < result.RowNumber(); row++ {
+		appID, err := result.GetInt(row, constant.ZeroInt)
+		if err != nil {
+			return nil, err
+		}
+		dbID, err := result.GetInt(row, 1)
+		if err != nil {
+			return nil, err
+		}
+		appDBMap[appID] = append(appDBMap[appID], dbID)
+	}
+
+	return appDBMap, nil
+}
+
 // Create creates an app in the middleware
-func (ar *AppRepo) Create(app metadata.App) (metadata.App, error) {
+func (ar *AppRepo) Create(ctx context.Context, app metadata.App) (metadata.App, error) {
 	sql := `insert into t_meta_app_info(app_name, level, owner_id) values(?, ?, ?);`
-	log.Debugf("metadata AppRepo.Create() insert sql: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata AppRepo.Create() insert sql: %s", sql)
 	// execute
-	_, err := ar.Execute(sql, app.GetAppName(), app.GetLevel(), app.GetOwnerID())
+	_, err := ar.Execute(ctx, sql, app.GetAppName(), app.GetLevel(), app.GetOwnerID())
 	if err != nil {
 		return nil, err
 	}
 	// get id
-	id, err := ar.GetID(app.GetAppName())
+	id, err := ar.GetID(ctx, app.GetAppName())
 	if err != nil {
 		return nil, err
 	}
 	// get entity
-	return ar.GetByID(id)
+	return ar.GetByID(ctx, id)
 }
 
 // Update updates the app in the middleware
-func (ar *AppRepo) Update(app metadata.App) error {
-	sql := `update t_meta_app_info set app_name = ?, level = ?, owner_id = ?, del_flag = ? where id = ?;`
-	log.Debugf("metadata AppRepo.Update() update sql: %s", sql)
-	_, err := ar.Execute(sql, app.GetAppName(), app.GetLevel(), app.GetOwnerID(), app.GetDelFlag(), app.Identity())
-
-	return err
-}
-
-// Delete deletes the app in the middleware
-func (ar *AppRepo) Delete(id int) error {
-	tx, err := ar.Transaction()
+// it returns a *ConflictError if the row was modified or deleted after the entity had been read
+func (ar *AppRepo) Update(ctx context.Context, app metadata.App) error {
+	sql := `update t_meta_app_info set app_name = ?, level = ?, owner_id = ?, del_flag = ? where id = ? and date_format(last_update_time, '%Y-%m-%d %H:%i:%s.%f') = ?;`
+	tracing.Logger(ctx).Debugf("metadata AppRepo.Update() update sql: %s", sql)
+	result, err := ar.Execute(ctx, sql, app.GetAppName(), app.GetLevel(), app.GetOwnerID(), app.GetDelFlag(), app.Identity(), getVersion(app.GetLastUpdateTime()))
 	if err != nil {
 		return err
 	}
-	defer func() {
-		err = tx.Close()
-		if err != nil {
-			log.Errorf("metadata AppRepo.Delete(): close database connection failed.\n%s", err.Error())
-		}
-	}()
 
-	err = tx.Begin()
-	if err != nil {
-		return err
-	}
-	sql := `delete from t_meta_app_info where id = ?;`
-	log.Debugf("metadata AppRepo.Delete() delete sql(t_meta_app_info): %s", sql)
-	_, err = tx.Execute(sql, id)
-	if err != nil {
-		return err
-	}
-	sql = `delete from t_meta_app_db_map where app_id = ?;`
-	log.Debugf("metadata AppRepo.Delete() delete sql(t_meta_app_db_map): %s", sql)
-	_, err = tx.Execute(sql, id)
-	if err != nil {
-		return err
-	}
+	return checkUpdated(ctx, ar, "AppRepo", appTable, app.Identity(), app.GetLastUpdateTime(), result)
+}
+
+// Delete soft deletes the app in the middleware,
+// it returns a *BlockedError if the app is still referenced by the other metadata,
+// and a *ConflictError if the row was modified after it had been read with given last update time
+func (ar *AppRepo) Delete(ctx context.Context, id int, lastUpdateTime time.Time) error {
+	_, err := deleteByID(ctx, ar, "AppRepo", appTable, id, lastUpdateTime, false)
+
+	return err
+}
+
+// DeleteCascade soft deletes the app and the metadata which reference it in the middleware,
+// it returns the rows which are deleted or detached along with the app
+func (ar *AppRepo) DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]metadata.CascadedRow, error) {
+	return deleteByID(ctx, ar, "AppRepo", appTable, id, lastUpdateTime, true)
+}
 
-	return tx.Commit()
+// Undelete restores the soft deleted app in the middleware
+func (ar *AppRepo) Undelete(ctx context.Context, id int) error {
+	return undeleteByID(ctx, ar, "AppRepo", appTable, id)
 }
 
 // AddDB adds a new map of app and database in the middleware
-func (ar *AppRepo) AddDB(appID, dbID int) error {
-	sql := `insert into t_meta_app_db_map(app_id, db_id) values(?, ?);`
-	log.Debugf("metadata AppRepo.AddDB() insert sql: %s", sql)
-	_, err := ar.Execute(sql, appID, dbID)
+func (ar *AppRepo) AddDB(ctx context.Context, appID, dbID int) error {
+	sql := `insert into t_meta_app_db_map(app_id, db_id) values(?, ?) on duplicate key update del_flag = 0;`
+	tracing.Logger(ctx).Debugf("metadata AppRepo.AddDB() insert sql: %s", sql)
+	_, err := ar.Execute(ctx, sql, appID, dbID)
 
 	return err
 }
 
 // DeleteDB delete the map of app and database in the middleware
-func (ar *AppRepo) DeleteDB(appID, dbID int) error {
-	sql := `delete from t_meta_app_db_map where app_id = ? and db_id = ?;`
-	log.Debugf("metadata AppRepo.DeleteDB() delete sql: %s", sql)
-	_, err := ar.Execute(sql, appID, dbID)
+func (ar *AppRepo) DeleteDB(ctx context.Context, appID, dbID int) error {
+	sql := `update t_meta_app_db_map set del_flag = 1 where app_id = ? and db_id = ?;`
+	tracing.Logger(ctx).Debugf("metadata AppRepo.DeleteDB() update sql: %s", sql)
+	_, err := ar.Execute(ctx, sql, appID, dbID)
 
 	return err
 }
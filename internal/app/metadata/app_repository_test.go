@@ -1,6 +1,8 @@
 package metadata
 
 import (
+	"context"
+	"net/url"
 	"testing"
 
 	"github.com/romberli/go-util/common"
@@ -9,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
 )
 
 const (
@@ -37,7 +40,7 @@ func createApp() (metadata.App, error) {
 		defaultAppInfoAppName,
 		defaultAppInfoLevel,
 	)
-	entity, err := appRepo.Create(appSystemInfo)
+	entity, err := appRepo.Create(context.Background(), appSystemInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -47,19 +50,23 @@ func createApp() (metadata.App, error) {
 
 func deleteAppByID(id int) error {
 	sql := `delete from t_meta_app_system_info where id = ?`
-	_, err := appRepo.Execute(sql, id)
+	_, err := appRepo.Execute(context.Background(), sql, id)
 	return err
 }
 
 func TestAppRepoAll(t *testing.T) {
 	TestAppRepo_Execute(t)
 	TestAppRepo_GetAll(t)
+	TestAppRepo_GetByQuery(t)
 	TestAppRepo_GetByID(t)
 	TestAppRepo_GetAppByName(t)
 	TestAppRepo_GetDBIDList(t)
+	TestAppRepo_GetAppDBMap(t)
 	TestAppRepo_Create(t)
 	TestAppRepo_Update(t)
 	TestAppRepo_Delete(t)
+	TestAppRepo_Undelete(t)
+	TestAppRepo_Recreate(t)
 	TestAppRepo_AddAppDB(t)
 	TestAppRepo_DeleteAppDB(t)
 
@@ -69,7 +76,7 @@ func TestAppRepo_Execute(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := `select 1;`
-	result, err := appRepo.Execute(sql)
+	result, err := appRepo.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
 	r, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
@@ -84,11 +91,11 @@ func TestAppRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	err = tx.Begin()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
-	_, err = tx.Execute(sql, defaultAppInfoAppName, defaultAppInfoLevel, defaultAppInfoOwnerID, defaultAppInfoOwnerGroup)
+	_, err = tx.ExecuteContext(context.Background(), sql, defaultAppInfoAppName, defaultAppInfoLevel, defaultAppInfoOwnerID, defaultAppInfoOwnerGroup)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if inserted
 	sql = `select system_name from t_meta_app_system_info where system_name=?`
-	result, err := tx.Execute(sql,
+	result, err := tx.ExecuteContext(context.Background(), sql,
 		defaultAppInfoAppName,
 	)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
@@ -100,7 +107,7 @@ func TestAppRepo_Transaction(t *testing.T) {
 	err = tx.Rollback()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if rollbacked
-	entities, err := appRepo.GetAll()
+	entities, err := appRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	for _, entity := range entities {
 		systemName := entity.GetAppName()
@@ -115,17 +122,34 @@ func TestAppRepo_Transaction(t *testing.T) {
 func TestAppRepo_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
-	entities, err := appRepo.GetAll()
+	entities, err := appRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	systemName := entities[0].GetAppName()
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	asst.Equal(onlineAppName, systemName, "test GetAll() failed")
 }
 
+func TestAppRepo_GetByQuery(t *testing.T) {
+	asst := assert.New(t)
+
+	query, err := filter.NewQueryWithValues(url.Values{"app_name_like": {onlineAppName}, "sort": {"-id"}, "limit": {"1"}})
+	asst.Nil(err, common.CombineMessageWithError("test GetByQuery() failed", err))
+	entities, total, err := appRepo.GetByQuery(context.Background(), query)
+	asst.Nil(err, common.CombineMessageWithError("test GetByQuery() failed", err))
+	asst.Equal(1, len(entities), "test GetByQuery() failed")
+	asst.GreaterOrEqual(total, len(entities), "test GetByQuery() failed")
+	asst.Contains(entities[0].GetAppName(), onlineAppName, "test GetByQuery() failed")
+	// unknown field could not be filtered
+	query, err = filter.NewQueryWithValues(url.Values{"not_exists": {"1"}})
+	asst.Nil(err, common.CombineMessageWithError("test GetByQuery() failed", err))
+	_, _, err = appRepo.GetByQuery(context.Background(), query)
+	asst.NotNil(err, "test GetByQuery() failed")
+}
+
 func TestAppRepo_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := appRepo.GetByID(2)
+	entity, err := appRepo.GetByID(context.Background(), 2)
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	systemName := entity.GetAppName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
@@ -150,9 +174,9 @@ func TestAppRepo_Update(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	err = entity.Set(map[string]interface{}{appAppNameStruct: newAppName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = appRepo.Update(entity)
+	err = appRepo.Update(context.Background(), entity)
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	entity, err = appRepo.GetByID(entity.Identity())
+	entity, err = appRepo.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	systemName := entity.GetAppName()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
@@ -172,12 +196,30 @@ func TestAppRepo_Delete(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 }
 
+func TestAppRepo_Undelete(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createApp()
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	err = appRepo.Delete(context.Background(), entity.Identity(), entity.GetLastUpdateTime())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = appRepo.GetByID(context.Background(), entity.Identity())
+	asst.NotNil(err, "test Undelete() failed")
+	err = appRepo.Undelete(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = appRepo.GetByID(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	// delete
+	err = deleteAppByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+}
+
 func TestAppRepo_GetAppByName(t *testing.T) {
 	asst := assert.New(t)
 
 	_, err := createApp()
 	asst.Nil(err, common.CombineMessageWithError("test GetAppByName() failed", err))
-	entity, err := appRepo.GetAppByName(defaultAppInfoAppName)
+	entity, err := appRepo.GetAppByName(context.Background(), defaultAppInfoAppName)
 	asst.Nil(err, common.CombineMessageWithError("test GetAppByName() failed", err))
 	asst.Equal(defaultAppInfoAppName, entity.GetAppName(), common.CombineMessageWithError("test GetAppByName() failed", err))
 	// delete
@@ -190,7 +232,7 @@ func TestAppRepo_GetDBIDList(t *testing.T) {
 
 	entity, err := createApp()
 	asst.Nil(err, common.CombineMessageWithError("test GetDBIDList() failed", err))
-	dbIDList, err := appRepo.GetDBIDList(entity.Identity())
+	dbIDList, err := appRepo.GetDBIDList(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test GetDBIDList() failed", err))
 	asst.Equal(2, len(dbIDList), "test GetDBIDList() failed")
 	// delete
@@ -198,14 +240,50 @@ func TestAppRepo_GetDBIDList(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test GetAppByName() failed", err))
 }
 
+func TestAppRepo_GetAppDBMap(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createApp()
+	asst.Nil(err, common.CombineMessageWithError("test GetAppDBMap() failed", err))
+	dbIDList, err := appRepo.GetDBIDList(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test GetAppDBMap() failed", err))
+	appDBMap, err := appRepo.GetAppDBMap(context.Background())
+	asst.Nil(err, common.CombineMessageWithError("test GetAppDBMap() failed", err))
+	asst.ElementsMatch(dbIDList, appDBMap[entity.Identity()], "test GetAppDBMap() failed")
+	// delete
+	err = deleteAppByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test GetAppDBMap() failed", err))
+}
+
+func TestAppRepo_Recreate(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createApp()
+	asst.Nil(err, common.CombineMessageWithError("test Recreate() failed", err))
+	err = appRepo.Delete(context.Background(), entity.Identity(), entity.GetLastUpdateTime())
+	asst.Nil(err, common.CombineMessageWithError("test Recreate() failed", err))
+	// the deleted app does not prevent creating the app with the same name
+	newEntity, err := createApp()
+	asst.Nil(err, common.CombineMessageWithError("test Recreate() failed", err))
+	asst.NotEqual(entity.Identity(), newEntity.Identity(), "test Recreate() failed")
+	// the deleted app could not be restored while the app with the same name exists
+	err = appRepo.Undelete(context.Background(), entity.Identity())
+	asst.NotNil(err, "test Recreate() failed")
+	// delete
+	err = deleteAppByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Recreate() failed", err))
+	err = deleteAppByID(newEntity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Recreate() failed", err))
+}
+
 func TestAppRepo_AddAppDB(t *testing.T) {
 	asst := assert.New(t)
 
 	entity, err := createApp()
 	asst.Nil(err, common.CombineMessageWithError("test AddDB() failed", err))
-	err = appRepo.AddDB(entity.Identity(), 3)
+	err = appRepo.AddDB(context.Background(), entity.Identity(), 3)
 	asst.Nil(err, common.CombineMessageWithError("test AddDB() failed", err))
-	dbIDList, err := appRepo.GetDBIDList(entity.Identity())
+	dbIDList, err := appRepo.GetDBIDList(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test AddDB() failed", err))
 	asst.Equal(3, len(dbIDList), "test AddDB() failed")
 	// delete
@@ -218,9 +296,9 @@ func TestAppRepo_DeleteAppDB(t *testing.T) {
 
 	entity, err := createApp()
 	asst.Nil(err, common.CombineMessageWithError("test DeleteDB() failed", err))
-	err = appRepo.DeleteDB(entity.Identity(), 3)
+	err = appRepo.DeleteDB(context.Background(), entity.Identity(), 3)
 	asst.Nil(err, common.CombineMessageWithError("test DeleteDB() failed", err))
-	dbIDList, err := appRepo.GetDBIDList(entity.Identity())
+	dbIDList, err := appRepo.GetDBIDList(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test DeleteDB() failed", err))
 	asst.Equal(2, len(dbIDList), "test DeleteDB() failed")
 	// delete
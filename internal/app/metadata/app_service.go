@@ -1,10 +1,16 @@
 package metadata
 
 import (
+	"context"
+	"encoding/json"
+
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 
+	"github.com/romberli/das/internal/app/audit"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 )
 
@@ -16,16 +22,33 @@ type AppService struct {
 	metadata.AppRepo
 	Apps     []metadata.App `json:"apps"`
 	DBIDList []int          `json:"db_id_list"`
+	Page     *filter.Page   `json:"page"`
+
+	auditor depaudit.Auditor
+	ifMatch string
 }
 
 // NewAppService returns a new *AppService
 func NewAppService(repo metadata.AppRepo) *AppService {
-	return &AppService{repo, []metadata.App{}, []int{}}
+	return &AppService{repo, []metadata.App{}, []int{}, nil, nil, constant.EmptyString}
 }
 
 // NewAppServiceWithDefault returns a new *AppService with default repository
 func NewAppServiceWithDefault() *AppService {
-	return NewAppService(NewAppRepoWithGlobal())
+	s := NewAppService(NewAppRepoWithGlobal())
+	s.SetAuditor(audit.NewAuditorWithGlobal(audit.DefaultActor, constant.EmptyString))
+
+	return s
+}
+
+// SetAuditor sets the auditor which records the changes of the apps
+func (as *AppService) SetAuditor(auditor depaudit.Auditor) {
+	as.auditor = auditor
+}
+
+// SetIfMatch sets the entity tags which the app to update or delete must match, it is not checked if ifMatch is empty
+func (as *AppService) SetIfMatch(ifMatch string) {
+	as.ifMatch = ifMatch
 }
 
 // GetApps returns apps of the service
@@ -34,17 +57,34 @@ func (as *AppService) GetApps() []metadata.App {
 }
 
 // GetAll gets all apps from the middleware
-func (as *AppService) GetAll() error {
+func (as *AppService) GetAll(ctx context.Context) error {
 	var err error
 
-	as.Apps, err = as.AppRepo.GetAll()
+	as.Apps, err = as.AppRepo.GetAll(ctx)
 
 	return err
 }
 
+// GetByQuery gets the apps which match the query from the middleware, and the pagination information
+func (as *AppService) GetByQuery(ctx context.Context, query *filter.Query) error {
+	apps, total, err := as.AppRepo.GetByQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	as.Apps = apps
+	ids := make([]int, len(apps))
+	for i, app := range apps {
+		ids[i] = app.Identity()
+	}
+	as.Page = query.GetPage(total, ids)
+
+	return nil
+}
+
 // GetByID gets an app of the given id from the middleware
-func (as *AppService) GetByID(id int) error {
-	entity, err := as.AppRepo.GetByID(id)
+func (as *AppService) GetByID(ctx context.Context, id int) error {
+	entity, err := as.AppRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -55,8 +95,8 @@ func (as *AppService) GetByID(id int) error {
 }
 
 // GetAppByName gets App from the middleware by name
-func (as *AppService) GetAppByName(appName string) error {
-	app, err := as.AppRepo.GetAppByName(appName)
+func (as *AppService) GetAppByName(ctx context.Context, appName string) error {
+	app, err := as.AppRepo.GetAppByName(ctx, appName)
 	if err != nil {
 		return err
 	}
@@ -67,19 +107,19 @@ func (as *AppService) GetAppByName(appName string) error {
 }
 
 // GetDBIDList gets a database identity list that the app uses
-func (as *AppService) GetDBIDList(id int) error {
-	app, err := as.AppRepo.GetByID(id)
+func (as *AppService) GetDBIDList(ctx context.Context, id int) error {
+	app, err := as.AppRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	as.DBIDList, err = app.GetDBIDList()
+	as.DBIDList, err = app.GetDBIDList(ctx)
 
 	return err
 }
 
 // Create creates an app in the middleware
-func (as *AppService) Create(fields map[string]interface{}) error {
+func (as *AppService) Create(ctx context.Context, fields map[string]interface{}) error {
 	// generate new map
 	_, ok := fields[appAppNameStruct]
 	if !ok {
@@ -97,12 +137,14 @@ func (as *AppService) Create(fields map[string]interface{}) error {
 	}
 
 	// insert into middleware
-	app, err := as.AppRepo.Create(appInfo)
+	app, err := as.AppRepo.Create(ctx, appInfo)
 	if err != nil {
 		return err
 	}
 
 	as.Apps = append(as.Apps, app)
+	record(ctx, as.auditor, audit.EntityTypeApp, app.Identity(), audit.ActionCreate, nil, app)
+
 	return nil
 }
 
@@ -110,8 +152,16 @@ func (as *AppService) Create(fields map[string]interface{}) error {
 // and then updates its fields that was specified in fields argument,
 // key is the filed name and value is the new field value,
 // it saves the changes to the middleware
-func (as *AppService) Update(id int, fields map[string]interface{}) error {
-	err := as.GetByID(id)
+func (as *AppService) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	err := as.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(as.ifMatch, appTable, id, as.Apps[constant.ZeroInt].GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	before, err := as.Apps[constant.ZeroInt].MarshalJSON()
 	if err != nil {
 		return err
 	}
@@ -119,33 +169,90 @@ func (as *AppService) Update(id int, fields map[string]interface{}) error {
 	if err != nil {
 		return err
 	}
+	err = as.AppRepo.Update(ctx, as.Apps[constant.ZeroInt])
+	if err != nil {
+		return err
+	}
+	record(ctx, as.auditor, audit.EntityTypeApp, id, audit.ActionUpdate, json.RawMessage(before), as.Apps[constant.ZeroInt])
 
-	return as.AppRepo.Update(as.Apps[constant.ZeroInt])
+	return nil
 }
 
 // Delete deletes the app of given id in the middleware
-func (as *AppService) Delete(id int) error {
-	return as.AppRepo.Delete(id)
+func (as *AppService) Delete(ctx context.Context, id int) error {
+	before, err := as.AppRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(as.ifMatch, appTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	err = as.AppRepo.Delete(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, as.auditor, audit.EntityTypeApp, id, audit.ActionDelete, before, nil)
+
+	return nil
+}
+
+// DeleteCascade deletes the app of given id and the metadata which reference it in the middleware
+func (as *AppService) DeleteCascade(ctx context.Context, id int) error {
+	before, err := as.AppRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(as.ifMatch, appTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	rows, err := as.AppRepo.DeleteCascade(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, as.auditor, audit.EntityTypeApp, id, audit.ActionDeleteCascade, before, nil)
+	recordCascaded(ctx, as.auditor, rows)
+
+	return nil
+}
+
+// Undelete restores the deleted app of given id in the middleware, and then gets it
+func (as *AppService) Undelete(ctx context.Context, id int) error {
+	err := as.AppRepo.Undelete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = as.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	record(ctx, as.auditor, audit.EntityTypeApp, id, audit.ActionUndelete, nil, as.Apps[constant.ZeroInt])
+
+	return nil
 }
 
 // AddDB adds a new map of app and database in the middleware
-func (as *AppService) AddDB(appID, dbID int) error {
-	err := as.AppRepo.AddDB(appID, dbID)
+func (as *AppService) AddDB(ctx context.Context, appID, dbID int) error {
+	err := as.AppRepo.AddDB(ctx, appID, dbID)
 	if err != nil {
 		return err
 	}
+	record(ctx, as.auditor, audit.EntityTypeApp, appID, audit.ActionAddRelation, nil, &appDBRelation{AppID: appID, DBID: dbID})
 
-	return as.GetDBIDList(appID)
+	return as.GetDBIDList(ctx, appID)
 }
 
 // DeleteDB deletes the map of app and database in the middleware
-func (as *AppService) DeleteDB(appID, dbID int) error {
-	err := as.AppRepo.DeleteDB(appID, dbID)
+func (as *AppService) DeleteDB(ctx context.Context, appID, dbID int) error {
+	err := as.AppRepo.DeleteDB(ctx, appID, dbID)
 	if err != nil {
 		return err
 	}
+	record(ctx, as.auditor, audit.EntityTypeApp, appID, audit.ActionDeleteRelation, &appDBRelation{AppID: appID, DBID: dbID}, nil)
 
-	return as.GetDBIDList(appID)
+	return as.GetDBIDList(ctx, appID)
 }
 
 // Marshal marshals AppService.Apps to json bytes
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -28,7 +29,7 @@ func TestAppService_GetEntities(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewAppService(appRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetEnvs() failed")
 	entities := s.GetApps()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetEnvs() failed")
@@ -38,7 +39,7 @@ func TestAppService_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewAppService(appRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetEnvs() failed")
 	entities := s.GetApps()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetEnvs() failed")
@@ -48,7 +49,7 @@ func TestAppService_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewAppService(appRepo)
-	err := s.GetByID(2)
+	err := s.GetByID(context.Background(), 2)
 	asst.Nil(err, "test GetByID() failed")
 	id := s.Apps[constant.ZeroInt].Identity()
 	asst.Equal("2", id, "test GetByID() failed")
@@ -58,7 +59,7 @@ func TestAppService_Create(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewAppService(appRepo)
-	err := s.Create(map[string]interface{}{appAppNameStruct: defaultAppInfoAppName, appLevelStruct: defaultAppInfoLevel})
+	err := s.Create(context.Background(), map[string]interface{}{appAppNameStruct: defaultAppInfoAppName, appLevelStruct: defaultAppInfoLevel})
 	asst.Nil(err, common.CombineMessageWithError("test Create() failed", err))
 	// delete
 	err = deleteAppByID(s.Apps[0].Identity())
@@ -71,9 +72,9 @@ func TestAppService_Update(t *testing.T) {
 	entity, err := createApp()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewAppService(appRepo)
-	err = s.Update(entity.Identity(), map[string]interface{}{appAppNameStruct: newAppName})
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{appAppNameStruct: newAppName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	appName := s.Apps[constant.ZeroInt].GetAppName()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
@@ -89,7 +90,7 @@ func TestAppService_Delete(t *testing.T) {
 	entity, err := createApp()
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	s := NewAppService(appRepo)
-	err = s.Delete(entity.Identity())
+	err = s.Delete(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	// delete
 	err = deleteAppByID(entity.Identity())
@@ -102,7 +103,7 @@ func TestAppService_Marshal(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewAppService(appRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
 	data, err := s.Marshal()
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
@@ -122,7 +123,7 @@ func TestAppService_MarshalWithFields(t *testing.T) {
 	entity, err := createApp()
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	s := NewAppService(appRepo)
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	dataService, err := s.MarshalWithFields(appAppNameStruct)
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	dataEntity, err := entity.MarshalJSONWithFields(appAppNameStruct)
@@ -138,9 +139,9 @@ func TestAppService_AddDB(t *testing.T) {
 	entity, err := createApp()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewAppService(appRepo)
-	dbID, err := entity.GetDBIDList()
+	dbID, err := entity.GetDBIDList(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("entity.GetDBIDList() failed", err))
-	err = s.AddDB(entity.Identity(), dbID[0])
+	err = s.AddDB(context.Background(), entity.Identity(), dbID[0])
 	asst.Nil(err, common.CombineMessageWithError("test AddDB() failed", err))
 }
 
@@ -149,9 +150,9 @@ func TestAppService_DeleteDB(t *testing.T) {
 	entity, err := createApp()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewAppService(appRepo)
-	dbID, err := entity.GetDBIDList()
+	dbID, err := entity.GetDBIDList(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("entity.GetDBIDList() failed", err))
-	err = s.DeleteDB(entity.Identity(), dbID[0])
+	err = s.DeleteDB(context.Background(), entity.Identity(), dbID[0])
 	asst.Nil(err, common.CombineMessageWithError("test DeleteDB() failed", err))
 }
 
@@ -160,6 +161,6 @@ func TestAppService_GetDBIDList(t *testing.T) {
 	entity, err := createApp()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewAppService(appRepo)
-	err = s.GetDBIDList(entity.Identity())
+	err = s.GetDBIDList(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test GetDBIDList() failed", err))
 }
@@ -0,0 +1,55 @@
+package metadata
+
+import (
+	"context"
+
+	"github.com/romberli/das/internal/app/audit"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/internal/dependency/metadata"
+)
+
+// entityTypes are the audited entity types of the tables
+var entityTypes = map[string]string{
+	appTable:               audit.EntityTypeApp,
+	dbTable:                audit.EntityTypeDB,
+	envTable:               audit.EntityTypeEnv,
+	middlewareClusterTable: audit.EntityTypeMiddlewareCluster,
+	middlewareServerTable:  audit.EntityTypeMiddlewareServer,
+	monitorSystemTable:     audit.EntityTypeMonitorSystem,
+	mysqlClusterTable:      audit.EntityTypeMySQLCluster,
+	mysqlServerTable:       audit.EntityTypeMySQLServer,
+	userTable:              audit.EntityTypeUser,
+}
+
+// appDBRelation is the snapshot of the map of the app and database in the audit records
+type appDBRelation struct {
+	AppID int `json:"app_id"`
+	DBID  int `json:"db_id"`
+}
+
+// record records the change with the auditor, it does nothing if the auditor is not set
+func record(ctx context.Context, auditor depaudit.Auditor, entityType string, entityID int, action string, before, after interface{}) {
+	if auditor == nil {
+		return
+	}
+
+	auditor.Record(ctx, entityType, entityID, action, before, after)
+}
+
+// recordCascaded records the rows which are deleted or detached along with the deleted entity in cascade mode,
+// the deleted maps of the app and database are recorded as the deleted relations of the referenced app or database
+func recordCascaded(ctx context.Context, auditor depaudit.Auditor, rows []metadata.CascadedRow) {
+	for _, row := range rows {
+		if row.GetTable() == appDBMapTable {
+			record(ctx, auditor, entityTypes[row.GetReferencedTable()], row.GetReferencedID(), audit.ActionDeleteRelation, row, nil)
+			continue
+		}
+		if row.IsDetached() {
+			before := map[string]interface{}{"id": row.Identity(), "name": row.GetName(), row.GetColumn(): row.GetReferencedID()}
+			after := map[string]interface{}{"id": row.Identity(), "name": row.GetName(), row.GetColumn(): 0}
+			record(ctx, auditor, entityTypes[row.GetTable()], row.Identity(), audit.ActionUpdate, before, after)
+			continue
+		}
+		record(ctx, auditor, entityTypes[row.GetTable()], row.Identity(), audit.ActionDelete, row, nil)
+	}
+}
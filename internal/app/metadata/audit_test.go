@@ -0,0 +1,80 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/internal/app/audit"
+	"github.com/romberli/das/internal/dependency/metadata"
+)
+
+// testRecord is a change recorded by testAuditor
+type testRecord struct {
+	entityType string
+	entityID   int
+	action     string
+	before     interface{}
+	after      interface{}
+}
+
+// testAuditor keeps the recorded changes in memory
+type testAuditor struct {
+	records []testRecord
+}
+
+func (ta *testAuditor) Record(ctx context.Context, entityType string, entityID int, action string, before, after interface{}) {
+	ta.records = append(ta.records, testRecord{entityType, entityID, action, before, after})
+}
+
+func TestAuditAll(t *testing.T) {
+	TestAudit_EntityTypes(t)
+	TestRecordCascaded(t)
+}
+
+func TestAudit_EntityTypes(t *testing.T) {
+	asst := assert.New(t)
+
+	for table := range nameColumns {
+		if table == appDBMapTable {
+			continue
+		}
+		asst.True(audit.IsValidEntityType(entityTypes[table]), "test entityTypes failed. table: %s", table)
+	}
+}
+
+func TestRecordCascaded(t *testing.T) {
+	asst := assert.New(t)
+
+	rows := []metadata.CascadedRow{
+		&Blocker{Table: mysqlServerTable, ID: 1, Name: "192-168-137-11", reference: references[mysqlClusterTable][0],
+			referencedTable: mysqlClusterTable, referencedID: 1},
+		&Blocker{Table: dbTable, ID: 2, Name: "db1", reference: references[mysqlClusterTable][1],
+			referencedTable: mysqlClusterTable, referencedID: 1},
+		&Blocker{Table: appDBMapTable, ID: 3, Name: "app_id: 4, db_id: 2", reference: references[dbTable][0],
+			referencedTable: dbTable, referencedID: 2},
+		&Blocker{Table: mysqlClusterTable, ID: 5, Name: "cluster1", reference: references[monitorSystemTable][0],
+			referencedTable: monitorSystemTable, referencedID: 6},
+	}
+	auditor := &testAuditor{}
+	recordCascaded(context.Background(), auditor, rows)
+	asst.Equal(4, len(auditor.records), "test recordCascaded() failed")
+
+	asst.Equal(audit.EntityTypeMySQLServer, auditor.records[0].entityType, "test recordCascaded() failed")
+	asst.Equal(1, auditor.records[0].entityID, "test recordCascaded() failed")
+	asst.Equal(audit.ActionDelete, auditor.records[0].action, "test recordCascaded() failed")
+	asst.Nil(auditor.records[0].after, "test recordCascaded() failed")
+	asst.Equal(audit.EntityTypeDB, auditor.records[1].entityType, "test recordCascaded() failed")
+	asst.Equal(audit.ActionDelete, auditor.records[1].action, "test recordCascaded() failed")
+	// the map is recorded as the deleted relation of the database
+	asst.Equal(audit.EntityTypeDB, auditor.records[2].entityType, "test recordCascaded() failed")
+	asst.Equal(2, auditor.records[2].entityID, "test recordCascaded() failed")
+	asst.Equal(audit.ActionDeleteRelation, auditor.records[2].action, "test recordCascaded() failed")
+	// the detached cluster is recorded as updated
+	asst.Equal(audit.EntityTypeMySQLCluster, auditor.records[3].entityType, "test recordCascaded() failed")
+	asst.Equal(5, auditor.records[3].entityID, "test recordCascaded() failed")
+	asst.Equal(audit.ActionUpdate, auditor.records[3].action, "test recordCascaded() failed")
+	asst.Equal(6, auditor.records[3].before.(map[string]interface{})["monitor_system_id"], "test recordCascaded() failed")
+	asst.Equal(0, auditor.records[3].after.(map[string]interface{})["monitor_system_id"], "test recordCascaded() failed")
+}
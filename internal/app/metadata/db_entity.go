@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/common"
@@ -145,8 +146,8 @@ func (di *DBInfo) GetLastUpdateTime() time.Time {
 }
 
 // GetAppIDList gets app identity list that uses this db
-func (di *DBInfo) GetAppIDList() ([]int, error) {
-	return di.DBRepo.GetAppIDList(di.ID)
+func (di *DBInfo) GetAppIDList(ctx context.Context) ([]int, error) {
+	return di.DBRepo.GetAppIDList(ctx, di.ID)
 }
 
 // Set sets DB with given fields, key is the field name and value is the relevant value of the key
@@ -167,13 +168,13 @@ func (di *DBInfo) Delete() {
 }
 
 // AddApp adds a new map of application system and database in the middleware
-func (di *DBInfo) AddApp(appID int) error {
-	return di.DBRepo.AddApp(di.ID, appID)
+func (di *DBInfo) AddApp(ctx context.Context, appID int) error {
+	return di.DBRepo.AddApp(ctx, di.ID, appID)
 }
 
 // DeleteApp delete the map of application system and database in the middleware
-func (di *DBInfo) DeleteApp(appID int) error {
-	return di.DBRepo.DeleteApp(di.ID, appID)
+func (di *DBInfo) DeleteApp(ctx context.Context, appID int) error {
+	return di.DBRepo.DeleteApp(ctx, di.ID, appID)
 }
 
 // MarshalJSON marshals DB to json string
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -126,7 +127,7 @@ func TestDBInfo_GetAppIDList(t *testing.T) {
 	asst := assert.New(t)
 
 	dbInfo := initNewDBInfo()
-	appIDList, err := dbInfo.GetAppIDList()
+	appIDList, err := dbInfo.GetAppIDList(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test GetAppIDList() failed", err))
 	defaultAppIDList := []int{1, 2}
 	for i := 0; i < len(appIDList); i++ {
@@ -158,12 +159,12 @@ func TestDBInfo_AddDBApp(t *testing.T) {
 	asst := assert.New(t)
 
 	dbInfo := initNewDBInfo()
-	err := dbInfo.AddApp(3)
-	appIDList, err = dbInfo.GetAppIDList()
+	err := dbInfo.AddApp(context.Background(), 3)
+	appIDList, err = dbInfo.GetAppIDList(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test AddApp() failed", err))
 	asst.Equal(3, len(appIDList), "test AddApp() failed")
 	// delete
-	err = dbInfo.DeleteApp(3)
+	err = dbInfo.DeleteApp(context.Background(), 3)
 	asst.Nil(err, common.CombineMessageWithError("test AddApp() failed", err))
 }
 
@@ -173,12 +174,12 @@ func TestDBInfo_DeleteDBApp(t *testing.T) {
 	asst := assert.New(t)
 
 	dbInfo := initNewDBInfo()
-	err := dbInfo.DeleteApp(2)
-	appIDList, err = dbInfo.GetAppIDList()
+	err := dbInfo.DeleteApp(context.Background(), 2)
+	appIDList, err = dbInfo.GetAppIDList(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test DeleteApp() failed", err))
 	asst.Equal(1, len(appIDList), "test DeleteApp() failed")
 	// add
-	err = dbInfo.AddApp(2)
+	err = dbInfo.AddApp(context.Background(), 2)
 	asst.Nil(err, common.CombineMessageWithError("test DeleteApp() failed", err))
 }
 
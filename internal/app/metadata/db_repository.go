@@ -1,8 +1,10 @@
 package metadata
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
@@ -11,6 +13,8 @@ import (
 
 	"github.com/romberli/das/global"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/tracing"
 )
 
 var _ metadata.DBRepo = (*DBRepo)(nil)
@@ -30,9 +34,11 @@ func NewDBRepoWithGlobal() *DBRepo {
 }
 
 // Execute executes given command and placeholders on the middleware
-func (dr *DBRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+func (dr *DBRepo) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
 	conn, err := dr.Database.Get()
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
 	defer func() {
@@ -42,7 +48,10 @@ func (dr *DBRepo) Execute(command string, args ...interface{}) (middleware.Resul
 		}
 	}()
 
-	return conn.Execute(command, args...)
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
 }
 
 // Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
@@ -51,16 +60,16 @@ func (dr *DBRepo) Transaction() (middleware.Transaction, error) {
 }
 
 // GetAll gets all databases from the middleware
-func (dr *DBRepo) GetAll() ([]metadata.DB, error) {
+func (dr *DBRepo) GetAll(ctx context.Context) ([]metadata.DB, error) {
 	sql := `
 		select id, db_name, cluster_id, cluster_type, owner_id, env_id, del_flag, create_time, last_update_time
 		from t_meta_db_info
 		where del_flag = 0
 		order by id;
 	`
-	log.Debugf("metadata DBRepo.GetAll() sql: \n%s", sql)
+	tracing.Logger(ctx).Debugf("metadata DBRepo.GetAll() sql: \n%s", sql)
 
-	result, err := dr.Execute(sql)
+	result, err := dr.Execute(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -83,8 +92,34 @@ func (dr *DBRepo) GetAll() ([]metadata.DB, error) {
 	return dbList, nil
 }
 
+// GetByQuery gets the dbs which match the query from the middleware,
+// it also returns the total number of the matched dbs without pagination
+func (dr *DBRepo) GetByQuery(ctx context.Context, query *filter.Query) ([]metadata.DB, int, error) {
+	result, total, err := getByQuery(ctx, dr, "DBRepo", dbTable, dbColumns, query)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []*DBInfo
+	dbInfoList := make([]*DBInfo, result.RowNumber())
+	for i := range dbInfoList {
+		dbInfoList[i] = NewEmptyDBInfoWithGlobal()
+	}
+	// map to struct
+	err = result.MapToStructSlice(dbInfoList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []metadata.DB
+	entityList := make([]metadata.DB, result.RowNumber())
+	for i := range entityList {
+		entityList[i] = dbInfoList[i]
+	}
+
+	return entityList, total, nil
+}
+
 // GetByEnv gets databases of given env id from the middleware
-func (dr *DBRepo) GetByEnv(envID int) ([]metadata.DB, error) {
+func (dr *DBRepo) GetByEnv(ctx context.Context, envID int) ([]metadata.DB, error) {
 	sql := `
 		select id, db_name, cluster_id, cluster_type, owner_id, env_id, del_flag, create_time, last_update_time
 		from t_meta_db_info
@@ -92,9 +127,9 @@ func (dr *DBRepo) GetByEnv(envID int) ([]metadata.DB, error) {
 		and env_id = ? 
 		order by id;
 	`
-	log.Debugf("metadata DBRepo.GetByEnv sql: \n%s", sql)
+	tracing.Logger(ctx).Debugf("metadata DBRepo.GetByEnv sql: \n%s", sql)
 
-	result, err := dr.Execute(sql, envID)
+	result, err := dr.Execute(ctx, sql, envID)
 	if err != nil {
 		return nil, err
 	}
@@ -118,22 +153,22 @@ func (dr *DBRepo) GetByEnv(envID int) ([]metadata.DB, error) {
 }
 
 // GetByID gets a database by the identity from the middleware
-func (dr *DBRepo) GetByID(id int) (metadata.DB, error) {
+func (dr *DBRepo) GetByID(ctx context.Context, id int) (metadata.DB, error) {
 	sql := `
 		select id, db_name, cluster_id, cluster_type, owner_id, env_id, del_flag, create_time, last_update_time
 		from t_meta_db_info
 		where del_flag = 0
 		and id = ?;
 	`
-	log.Debugf("metadata DBRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, id)
+	tracing.Logger(ctx).Debugf("metadata DBRepo.GetByID() sql: \n%s\nplaceholders: %d", sql, id)
 
-	result, err := dr.Execute(sql, id)
+	result, err := dr.Execute(ctx, sql, id)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata DBInfo.GetByID(): data does not exists, id: %d", id))
+		return nil, fmt.Errorf("metadata DBInfo.GetByID(): %w, id: %d", ErrDataNotExists, id)
 	case 1:
 		dbInfo := NewEmptyDBInfoWithRepo(dr)
 		// map to struct
@@ -149,7 +184,7 @@ func (dr *DBRepo) GetByID(id int) (metadata.DB, error) {
 }
 
 // GetByNameAndClusterInfo gets a database by the db name and cluster info from the middleware
-func (dr *DBRepo) GetByNameAndClusterInfo(name string, clusterID, clusterType int) (metadata.DB, error) {
+func (dr *DBRepo) GetByNameAndClusterInfo(ctx context.Context, name string, clusterID, clusterType int) (metadata.DB, error) {
 	sql := `
 		select id, db_name, cluster_id, cluster_type, owner_id, env_id, del_flag, create_time, last_update_time
 		from t_meta_db_info
@@ -158,14 +193,14 @@ func (dr *DBRepo) GetByNameAndClusterInfo(name string, clusterID, clusterType in
 		and cluster_id = ?
 		and cluster_type = ?;
 	`
-	log.Debugf("metadata DBRepo.GetByNameAndClusterInfo() sql: \n%s\nplaceholders: %s, %d, %d", sql, name, clusterID, clusterType)
-	result, err := dr.Execute(sql, name, clusterID, clusterType)
+	tracing.Logger(ctx).Debugf("metadata DBRepo.GetByNameAndClusterInfo() sql: \n%s\nplaceholders: %s, %d, %d", sql, name, clusterID, clusterType)
+	result, err := dr.Execute(ctx, sql, name, clusterID, clusterType)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata DBInfo.GetByNameAndClusterInfo(): data does not exists, db name: %s, cluster id: %d, cluster type: %d", name, clusterID, clusterType))
+		return nil, fmt.Errorf("metadata DBInfo.GetByNameAndClusterInfo(): %w, db name: %s, cluster id: %d, cluster type: %d", ErrDataNotExists, name, clusterID, clusterType)
 	case 1:
 		dbInfo := NewEmptyDBInfoWithRepo(dr)
 		// map to struct
@@ -181,10 +216,10 @@ func (dr *DBRepo) GetByNameAndClusterInfo(name string, clusterID, clusterType in
 }
 
 // GetID gets the identity with given database name, cluster id and cluster type from the middleware
-func (dr *DBRepo) GetID(dbName string, clusterID int, clusterType int) (int, error) {
+func (dr *DBRepo) GetID(ctx context.Context, dbName string, clusterID int, clusterType int) (int, error) {
 	sql := `select id from t_meta_db_info where del_flag = 0 and db_name = ? and cluster_id = ? and cluster_type = ?;`
-	log.Debugf("metadata DBRepo.GetID() select sql: %s", sql)
-	result, err := dr.Execute(sql, dbName, clusterID, clusterType)
+	tracing.Logger(ctx).Debugf("metadata DBRepo.GetID() select sql: %s", sql)
+	result, err := dr.Execute(ctx, sql, dbName, clusterID, clusterType)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -193,7 +228,7 @@ func (dr *DBRepo) GetID(dbName string, clusterID int, clusterType int) (int, err
 }
 
 // GetAppIDList gets an app identity list that uses this db
-func (dr *DBRepo) GetAppIDList(dbID int) ([]int, error) {
+func (dr *DBRepo) GetAppIDList(ctx context.Context, dbID int) ([]int, error) {
 	sql := `
 		select app_id
 		from t_meta_db_info di
@@ -202,9 +237,9 @@ func (dr *DBRepo) GetAppIDList(dbID int) ([]int, error) {
 		and adm.del_flag = 0
 		and di.id = ?;
 	`
-	log.Debugf("metadata DBRepo.GetAppIDList() select sql: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata DBRepo.GetAppIDList() select sql: %s", sql)
 
-	result, err := dr.Execute(sql, dbID)
+	result, err := dr.Execute(ctx, sql, dbID)
 	if err != nil {
 		return nil, err
 	}
@@ -225,80 +260,71 @@ func (dr *DBRepo) GetAppIDList(dbID int) ([]int, error) {
 }
 
 // Create creates a database in the middleware
-func (dr *DBRepo) Create(db metadata.DB) (metadata.DB, error) {
+func (dr *DBRepo) Create(ctx context.Context, db metadata.DB) (metadata.DB, error) {
 	sql := `insert into t_meta_db_info(db_name, cluster_id, cluster_type, owner_id, env_id) values(?, ?, ?, ?, ?);`
-	log.Debugf("metadata DBRepo.Create() insert sql: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata DBRepo.Create() insert sql: %s", sql)
 
 	// execute
-	_, err := dr.Execute(sql, db.GetDBName(), db.GetClusterID(), db.GetClusterType(), db.GetOwnerID(), db.GetEnvID())
+	_, err := dr.Execute(ctx, sql, db.GetDBName(), db.GetClusterID(), db.GetClusterType(), db.GetOwnerID(), db.GetEnvID())
 	if err != nil {
 		return nil, err
 	}
 	// get id
-	id, err := dr.GetID(db.GetDBName(), db.GetClusterID(), db.GetClusterType())
+	id, err := dr.GetID(ctx, db.GetDBName(), db.GetClusterID(), db.GetClusterType())
 	if err != nil {
 		return nil, err
 	}
 	// get entity
-	return dr.GetByID(id)
+	return dr.GetByID(ctx, id)
 }
 
 // Update updates the database in the middleware
-func (dr *DBRepo) Update(db metadata.DB) error {
-	sql := `update t_meta_db_info set db_name = ?, cluster_id = ?, cluster_type = ?, owner_id = ?, env_id = ?, del_flag = ? where id = ?;`
-	log.Debugf("metadata DBRepo.Update() update sql: %s", sql)
-	_, err := dr.Execute(sql, db.GetDBName(), db.GetClusterID(), db.GetClusterType(), db.GetOwnerID(), db.GetEnvID(), db.GetDelFlag(), db.Identity())
-
-	return err
-}
-
-// Delete deletes the database in the middleware
-func (dr *DBRepo) Delete(id int) error {
-	tx, err := dr.Transaction()
+// it returns a *ConflictError if the row was modified or deleted after the entity had been read
+func (dr *DBRepo) Update(ctx context.Context, db metadata.DB) error {
+	sql := `update t_meta_db_info set db_name = ?, cluster_id = ?, cluster_type = ?, owner_id = ?, env_id = ?, del_flag = ? where id = ? and date_format(last_update_time, '%Y-%m-%d %H:%i:%s.%f') = ?;`
+	tracing.Logger(ctx).Debugf("metadata DBRepo.Update() update sql: %s", sql)
+	result, err := dr.Execute(ctx, sql, db.GetDBName(), db.GetClusterID(), db.GetClusterType(), db.GetOwnerID(), db.GetEnvID(), db.GetDelFlag(), db.Identity(), getVersion(db.GetLastUpdateTime()))
 	if err != nil {
 		return err
 	}
-	defer func() {
-		err = tx.Close()
-		if err != nil {
-			log.Errorf("metadata DBRepo.Delete(): close database connection failed.\n%s", err.Error())
-		}
-	}()
 
-	err = tx.Begin()
-	if err != nil {
-		return err
-	}
-	sql := `delete from t_meta_db_info where id = ?;`
-	log.Debugf("metadata DBRepo.Delete() delete sql(t_meta_db_info): %s", sql)
-	_, err = dr.Execute(sql, id)
-	if err != nil {
-		return err
-	}
-	sql = `delete from t_meta_app_db_map where db_id = ?;`
-	log.Debugf("metadata DBRepo.Delete() delete sql(t_meta_app_db_map): %s", sql)
-	_, err = dr.Execute(sql, id)
-	if err != nil {
-		return err
-	}
+	return checkUpdated(ctx, dr, "DBRepo", dbTable, db.Identity(), db.GetLastUpdateTime(), result)
+}
+
+// Delete soft deletes the database in the middleware,
+// it returns a *BlockedError if the database is still referenced by the other metadata,
+// and a *ConflictError if the row was modified after it had been read with given last update time
+func (dr *DBRepo) Delete(ctx context.Context, id int, lastUpdateTime time.Time) error {
+	_, err := deleteByID(ctx, dr, "DBRepo", dbTable, id, lastUpdateTime, false)
+
+	return err
+}
+
+// DeleteCascade soft deletes the database and the metadata which reference it in the middleware,
+// it returns the rows which are deleted or detached along with the database
+func (dr *DBRepo) DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]metadata.CascadedRow, error) {
+	return deleteByID(ctx, dr, "DBRepo", dbTable, id, lastUpdateTime, true)
+}
 
-	return tx.Commit()
+// Undelete restores the soft deleted database in the middleware
+func (dr *DBRepo) Undelete(ctx context.Context, id int) error {
+	return undeleteByID(ctx, dr, "DBRepo", dbTable, id)
 }
 
 // AddApp adds a new map of the app and database in the middleware
-func (dr *DBRepo) AddApp(dbID, appID int) error {
-	sql := `insert into t_meta_app_db_map(app_id, db_id) values(?, ?);`
-	log.Debugf("metadata DBRepo.AddApp() insert sql: %s", sql)
-	_, err := dr.Execute(sql, appID, dbID)
+func (dr *DBRepo) AddApp(ctx context.Context, dbID, appID int) error {
+	sql := `insert into t_meta_app_db_map(app_id, db_id) values(?, ?) on duplicate key update del_flag = 0;`
+	tracing.Logger(ctx).Debugf("metadata DBRepo.AddApp() insert sql: %s", sql)
+	_, err := dr.Execute(ctx, sql, appID, dbID)
 
 	return err
 }
 
 // DeleteApp deletes a map of the app and database in the middleware
-func (dr *DBRepo) DeleteApp(dbID, appID int) error {
-	sql := `delete from t_meta_app_db_map where app_id = ? and db_id = ?;`
-	log.Debugf("metadata DBRepo.DeleteApp() delete sql: %s", sql)
-	_, err := dr.Execute(sql, appID, dbID)
+func (dr *DBRepo) DeleteApp(ctx context.Context, dbID, appID int) error {
+	sql := `update t_meta_app_db_map set del_flag = 1 where app_id = ? and db_id = ?;`
+	tracing.Logger(ctx).Debugf("metadata DBRepo.DeleteApp() update sql: %s", sql)
+	_, err := dr.Execute(ctx, sql, appID, dbID)
 
 	return err
 }
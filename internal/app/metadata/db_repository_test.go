@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"testing"
 
 	"github.com/romberli/go-util/common"
@@ -35,7 +36,7 @@ func initDBRepo() *DBRepo {
 
 func createDB() (metadata.DB, error) {
 	dbInfo := NewDBInfoWithDefault(defaultDBInfoDBName, defaultDBInfoClusterID, defaultDBInfoClusterType, defaultDBInfoEnvID)
-	entity, err := dbRepo.Create(dbInfo)
+	entity, err := dbRepo.Create(context.Background(), dbInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +46,7 @@ func createDB() (metadata.DB, error) {
 
 func deleteDBByID(id int) error {
 	sql := `delete from t_meta_db_info where id = ?`
-	_, err := dbRepo.Execute(sql, id)
+	_, err := dbRepo.Execute(context.Background(), sql, id)
 	return err
 }
 
@@ -59,6 +60,7 @@ func TestDBRepoAll(t *testing.T) {
 	TestDBRepo_Create(t)
 	TestDBRepo_Update(t)
 	TestDBRepo_Delete(t)
+	TestDBRepo_Undelete(t)
 	TestDBRepo_AddDBApp(t)
 	TestDBRepo_DeleteDBApp(t)
 }
@@ -67,7 +69,7 @@ func TestDBRepo_Execute(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := `select 1;`
-	result, err := dbRepo.Execute(sql)
+	result, err := dbRepo.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
 	r, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
@@ -82,11 +84,11 @@ func TestDBRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	err = tx.Begin()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
-	_, err = tx.Execute(sql, newDBName, defaultDBInfoClusterID, defaultDBInfoClusterType, defaultDBInfoOwnerID, defaultDBInfoEnvID)
+	_, err = tx.ExecuteContext(context.Background(), sql, newDBName, defaultDBInfoClusterID, defaultDBInfoClusterType, defaultDBInfoOwnerID, defaultDBInfoEnvID)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if inserted
 	sql = `select db_name from t_meta_db_info where db_name = ?`
-	result, err := tx.Execute(sql, newDBName)
+	result, err := tx.ExecuteContext(context.Background(), sql, newDBName)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	dbName, err := result.GetString(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
@@ -96,7 +98,7 @@ func TestDBRepo_Transaction(t *testing.T) {
 	err = tx.Rollback()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if rollbacked
-	entities, err := dbRepo.GetAll()
+	entities, err := dbRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	for _, entity := range entities {
 		dbName := entity.GetDBName()
@@ -113,7 +115,7 @@ func TestDBRepo_GetAll(t *testing.T) {
 
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
-	entities, err := dbRepo.GetAll()
+	entities, err := dbRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	dbName := entities[0].GetDBName()
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
@@ -128,7 +130,7 @@ func TestDBRepo_GetByEnv(t *testing.T) {
 
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test GetByEnv() failed", err))
-	entities, err := dbRepo.GetByEnv(defaultDBInfoEnvID)
+	entities, err := dbRepo.GetByEnv(context.Background(), defaultDBInfoEnvID)
 	asst.Nil(err, common.CombineMessageWithError("test GetByEnv() failed", err))
 	asst.Equal(defaultDBInfoEnvID, entities[0].GetEnvID(), common.CombineMessageWithError("test GetByEnv() failed", err))
 	// delete
@@ -141,7 +143,7 @@ func TestDBRepo_GetByID(t *testing.T) {
 
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
-	db, err := dbRepo.GetByID(entity.Identity())
+	db, err := dbRepo.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	dbName := db.GetDBName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
@@ -156,7 +158,7 @@ func TestDBRepo_GetByNameAndClusterInfo(t *testing.T) {
 
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
-	db, err := dbRepo.GetByNameAndClusterInfo(entity.GetDBName(), entity.GetClusterID(), entity.GetClusterType())
+	db, err := dbRepo.GetByNameAndClusterInfo(context.Background(), entity.GetDBName(), entity.GetClusterID(), entity.GetClusterType())
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	dbName := db.GetDBName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
@@ -169,7 +171,7 @@ func TestDBRepo_GetByNameAndClusterInfo(t *testing.T) {
 func TestDBRepo_GetAppIDList(t *testing.T) {
 	asst := assert.New(t)
 
-	appIDList, err := dbRepo.GetAppIDList(1)
+	appIDList, err := dbRepo.GetAppIDList(context.Background(), 1)
 	asst.Nil(err, common.CombineMessageWithError("test GetAppIDList() failed", err))
 	asst.Equal(2, len(appIDList), "test GetAppIDList() failed")
 }
@@ -191,9 +193,9 @@ func TestDBRepo_Update(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	err = entity.Set(map[string]interface{}{dbDBNameStruct: newDBName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = dbRepo.Update(entity)
+	err = dbRepo.Update(context.Background(), entity)
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	entity, err = dbRepo.GetByID(entity.Identity())
+	entity, err = dbRepo.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	dbName := entity.GetDBName()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
@@ -213,12 +215,30 @@ func TestDBRepo_Delete(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 }
 
+func TestDBRepo_Undelete(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createDB()
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	err = dbRepo.Delete(context.Background(), entity.Identity(), entity.GetLastUpdateTime())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = dbRepo.GetByID(context.Background(), entity.Identity())
+	asst.NotNil(err, "test Undelete() failed")
+	err = dbRepo.Undelete(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = dbRepo.GetByID(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	// delete
+	err = deleteDBByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+}
+
 func TestDBRepo_AddDBApp(t *testing.T) {
 	asst := assert.New(t)
 
-	err := dbRepo.AddApp(1, 3)
+	err := dbRepo.AddApp(context.Background(), 1, 3)
 	asst.Nil(err, common.CombineMessageWithError("test AddApp() failed", err))
-	appIDList, err := dbRepo.GetAppIDList(1)
+	appIDList, err := dbRepo.GetAppIDList(context.Background(), 1)
 	asst.Nil(err, common.CombineMessageWithError("test AddApp() failed", err))
 	asst.Equal(3, len(appIDList), "test AddApp() failed")
 }
@@ -226,9 +246,9 @@ func TestDBRepo_AddDBApp(t *testing.T) {
 func TestDBRepo_DeleteDBApp(t *testing.T) {
 	asst := assert.New(t)
 
-	err := dbRepo.DeleteApp(1, 3)
+	err := dbRepo.DeleteApp(context.Background(), 1, 3)
 	asst.Nil(err, common.CombineMessageWithError("test DeleteApp() failed", err))
-	appIDList, err := dbRepo.GetAppIDList(1)
+	appIDList, err := dbRepo.GetAppIDList(context.Background(), 1)
 	asst.Nil(err, common.CombineMessageWithError("test DeleteApp() failed", err))
 	asst.Equal(2, len(appIDList), "test DeleteApp() failed")
 }
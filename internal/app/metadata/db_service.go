@@ -1,12 +1,18 @@
 package metadata
 
 import (
+	"context"
+	"encoding/json"
+
 	"fmt"
 
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 
+	"github.com/romberli/das/internal/app/audit"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 )
 
@@ -18,16 +24,33 @@ type DBService struct {
 	metadata.DBRepo
 	DBs       []metadata.DB `json:"dbs"`
 	AppIDList []int         `json:"app_id_list"`
+	Page      *filter.Page  `json:"page"`
+
+	auditor depaudit.Auditor
+	ifMatch string
 }
 
 // NewDBService returns a new *DBService
 func NewDBService(repo metadata.DBRepo) *DBService {
-	return &DBService{repo, []metadata.DB{}, []int{}}
+	return &DBService{repo, []metadata.DB{}, []int{}, nil, nil, constant.EmptyString}
 }
 
 // NewDBServiceWithDefault returns a new *DBService with default repository
 func NewDBServiceWithDefault() *DBService {
-	return NewDBService(NewDBRepoWithGlobal())
+	s := NewDBService(NewDBRepoWithGlobal())
+	s.SetAuditor(audit.NewAuditorWithGlobal(audit.DefaultActor, constant.EmptyString))
+
+	return s
+}
+
+// SetAuditor sets the auditor which records the changes of the databases
+func (ds *DBService) SetAuditor(auditor depaudit.Auditor) {
+	ds.auditor = auditor
+}
+
+// SetIfMatch sets the entity tags which the database to update or delete must match, it is not checked if ifMatch is empty
+func (ds *DBService) SetIfMatch(ifMatch string) {
+	ds.ifMatch = ifMatch
 }
 
 // GetDBs returns databases of the service
@@ -36,26 +59,43 @@ func (ds *DBService) GetDBs() []metadata.DB {
 }
 
 // GetAll gets all databases from the middleware
-func (ds *DBService) GetAll() error {
+func (ds *DBService) GetAll(ctx context.Context) error {
 	var err error
 
-	ds.DBs, err = ds.DBRepo.GetAll()
+	ds.DBs, err = ds.DBRepo.GetAll(ctx)
 
 	return err
 }
 
+// GetByQuery gets the databases which match the query from the middleware, and the pagination information
+func (ds *DBService) GetByQuery(ctx context.Context, query *filter.Query) error {
+	dbs, total, err := ds.DBRepo.GetByQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	ds.DBs = dbs
+	ids := make([]int, len(dbs))
+	for i, db := range dbs {
+		ids[i] = db.Identity()
+	}
+	ds.Page = query.GetPage(total, ids)
+
+	return nil
+}
+
 // GetByEnv gets all databases of given env_id
-func (ds *DBService) GetByEnv(envID int) error {
+func (ds *DBService) GetByEnv(ctx context.Context, envID int) error {
 	var err error
 
-	ds.DBs, err = ds.DBRepo.GetByEnv(envID)
+	ds.DBs, err = ds.DBRepo.GetByEnv(ctx, envID)
 
 	return err
 }
 
 // GetByID gets an database of the given id from the middleware
-func (ds *DBService) GetByID(id int) error {
-	db, err := ds.DBRepo.GetByID(id)
+func (ds *DBService) GetByID(ctx context.Context, id int) error {
+	db, err := ds.DBRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -66,8 +106,8 @@ func (ds *DBService) GetByID(id int) error {
 }
 
 // GetByNameAndClusterInfo gets an database of the given db name and cluster info from the middleware
-func (ds *DBService) GetByNameAndClusterInfo(name string, clusterID, clusterType int) error {
-	db, err := ds.DBRepo.GetByNameAndClusterInfo(name, clusterID, clusterType)
+func (ds *DBService) GetByNameAndClusterInfo(ctx context.Context, name string, clusterID, clusterType int) error {
+	db, err := ds.DBRepo.GetByNameAndClusterInfo(ctx, name, clusterID, clusterType)
 	if err != nil {
 		return err
 	}
@@ -78,19 +118,19 @@ func (ds *DBService) GetByNameAndClusterInfo(name string, clusterID, clusterType
 }
 
 // GetAppIDList gets an app identity list that uses this db
-func (ds *DBService) GetAppIDList(dbID int) error {
-	db, err := ds.DBRepo.GetByID(dbID)
+func (ds *DBService) GetAppIDList(ctx context.Context, dbID int) error {
+	db, err := ds.DBRepo.GetByID(ctx, dbID)
 	if err != nil {
 		return err
 	}
 
-	ds.AppIDList, err = db.GetAppIDList()
+	ds.AppIDList, err = db.GetAppIDList(ctx)
 
 	return err
 }
 
 // Create creates an new database in the middleware
-func (ds *DBService) Create(fields map[string]interface{}) error {
+func (ds *DBService) Create(ctx context.Context, fields map[string]interface{}) error {
 	// generate new map
 	_, dbNameExists := fields[dbDBNameStruct]
 	_, clusterIDExists := fields[dbClusterIDStruct]
@@ -106,12 +146,13 @@ func (ds *DBService) Create(fields map[string]interface{}) error {
 		return err
 	}
 	// insert into middleware
-	db, err := ds.DBRepo.Create(dbInfo)
+	db, err := ds.DBRepo.Create(ctx, dbInfo)
 	if err != nil {
 		return err
 	}
 
 	ds.DBs = append(ds.DBs, db)
+	record(ctx, ds.auditor, audit.EntityTypeDB, db.Identity(), audit.ActionCreate, nil, db)
 
 	return nil
 }
@@ -120,8 +161,16 @@ func (ds *DBService) Create(fields map[string]interface{}) error {
 // and then updates its fields that was specified in fields argument,
 // key is the filed name and value is the new field value,
 // it saves the changes to the middleware
-func (ds *DBService) Update(id int, fields map[string]interface{}) error {
-	err := ds.GetByID(id)
+func (ds *DBService) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	err := ds.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(ds.ifMatch, dbTable, id, ds.DBs[constant.ZeroInt].GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	before, err := ds.DBs[constant.ZeroInt].MarshalJSON()
 	if err != nil {
 		return err
 	}
@@ -129,33 +178,90 @@ func (ds *DBService) Update(id int, fields map[string]interface{}) error {
 	if err != nil {
 		return err
 	}
+	err = ds.DBRepo.Update(ctx, ds.DBs[constant.ZeroInt])
+	if err != nil {
+		return err
+	}
+	record(ctx, ds.auditor, audit.EntityTypeDB, id, audit.ActionUpdate, json.RawMessage(before), ds.DBs[constant.ZeroInt])
 
-	return ds.DBRepo.Update(ds.DBs[constant.ZeroInt])
+	return nil
 }
 
 // Delete deletes the database of given id in the middleware
-func (ds *DBService) Delete(id int) error {
-	return ds.DBRepo.Delete(id)
+func (ds *DBService) Delete(ctx context.Context, id int) error {
+	before, err := ds.DBRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(ds.ifMatch, dbTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	err = ds.DBRepo.Delete(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, ds.auditor, audit.EntityTypeDB, id, audit.ActionDelete, before, nil)
+
+	return nil
+}
+
+// DeleteCascade deletes the database of given id and the metadata which reference it in the middleware
+func (ds *DBService) DeleteCascade(ctx context.Context, id int) error {
+	before, err := ds.DBRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(ds.ifMatch, dbTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	rows, err := ds.DBRepo.DeleteCascade(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, ds.auditor, audit.EntityTypeDB, id, audit.ActionDeleteCascade, before, nil)
+	recordCascaded(ctx, ds.auditor, rows)
+
+	return nil
+}
+
+// Undelete restores the deleted database of given id in the middleware, and then gets it
+func (ds *DBService) Undelete(ctx context.Context, id int) error {
+	err := ds.DBRepo.Undelete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = ds.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	record(ctx, ds.auditor, audit.EntityTypeDB, id, audit.ActionUndelete, nil, ds.DBs[constant.ZeroInt])
+
+	return nil
 }
 
 // AddApp adds a new map of app and database in the middleware
-func (ds *DBService) AddApp(dbID, appID int) error {
-	err := ds.DBRepo.AddApp(dbID, appID)
+func (ds *DBService) AddApp(ctx context.Context, dbID, appID int) error {
+	err := ds.DBRepo.AddApp(ctx, dbID, appID)
 	if err != nil {
 		return err
 	}
+	record(ctx, ds.auditor, audit.EntityTypeDB, dbID, audit.ActionAddRelation, nil, &appDBRelation{AppID: appID, DBID: dbID})
 
-	return ds.GetAppIDList(dbID)
+	return ds.GetAppIDList(ctx, dbID)
 }
 
 // DeleteApp deletes the map of app and database in the middleware
-func (ds *DBService) DeleteApp(dbID, appID int) error {
-	err := ds.DBRepo.DeleteApp(dbID, appID)
+func (ds *DBService) DeleteApp(ctx context.Context, dbID, appID int) error {
+	err := ds.DBRepo.DeleteApp(ctx, dbID, appID)
 	if err != nil {
 		return err
 	}
+	record(ctx, ds.auditor, audit.EntityTypeDB, dbID, audit.ActionDeleteRelation, &appDBRelation{AppID: appID, DBID: dbID}, nil)
 
-	return ds.GetAppIDList(dbID)
+	return ds.GetAppIDList(ctx, dbID)
 }
 
 // Marshal marshals DBService.DBs to json bytes
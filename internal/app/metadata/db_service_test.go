@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -32,7 +33,7 @@ func TestDBService_GetDBs(t *testing.T) {
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test GetDBs() failed", err))
 	s := NewDBService(dbRepo)
-	err = s.GetAll()
+	err = s.GetAll(context.Background())
 	asst.Nil(err, "test GetDBs() failed")
 	entities := s.GetDBs()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetDBs() failed")
@@ -47,7 +48,7 @@ func TestDBService_GetAll(t *testing.T) {
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	s := NewDBService(dbRepo)
-	err = s.GetAll()
+	err = s.GetAll(context.Background())
 	asst.Nil(err, "test GetAll() failed")
 	entities := s.GetDBs()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetAll() failed")
@@ -62,7 +63,7 @@ func TestDBService_GetByEnv(t *testing.T) {
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test GetByEnv() failed", err))
 	s := NewDBService(dbRepo)
-	err = s.GetByEnv(defaultDBInfoEnvID)
+	err = s.GetByEnv(context.Background(), defaultDBInfoEnvID)
 	asst.Nil(err, "test GetByEnv() failed")
 	envId := s.DBs[constant.ZeroInt].GetEnvID()
 	asst.Equal(defaultDBInfoEnvID, envId, "test GetByEnv() failed")
@@ -77,7 +78,7 @@ func TestDBService_GetByID(t *testing.T) {
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	s := NewDBService(dbRepo)
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, "test GetByID() failed")
 	dbName := s.DBs[constant.ZeroInt].GetDBName()
 	asst.Equal(defaultDBInfoDBName, dbName, "test GetByID() failed")
@@ -92,7 +93,7 @@ func TestDBService_GetByNameAndClusterInfo(t *testing.T) {
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	s := NewDBService(dbRepo)
-	err = s.GetByNameAndClusterInfo(entity.GetDBName(), entity.GetClusterID(), entity.GetClusterType())
+	err = s.GetByNameAndClusterInfo(context.Background(), entity.GetDBName(), entity.GetClusterID(), entity.GetClusterType())
 	asst.Nil(err, "test GetByID() failed")
 	dbName := s.DBs[constant.ZeroInt].GetDBName()
 	asst.Equal(defaultDBInfoDBName, dbName, "test GetByID() failed")
@@ -105,7 +106,7 @@ func TestDBService_GetAppIDList(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewDBService(dbRepo)
-	err := s.GetAppIDList(1)
+	err := s.GetAppIDList(context.Background(), 1)
 	asst.Nil(err, "test GetAppIDList() failed")
 	appIDList := s.AppIDList
 	asst.Equal(2, len(appIDList), "test GetAppIDList() failed")
@@ -115,7 +116,7 @@ func TestDBService_Create(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewDBService(dbRepo)
-	err := s.Create(map[string]interface{}{dbDBNameStruct: defaultDBInfoDBName,
+	err := s.Create(context.Background(), map[string]interface{}{dbDBNameStruct: defaultDBInfoDBName,
 		dbClusterIDStruct: defaultDBInfoClusterID, dbEnvIDStruct: defaultDBInfoEnvID})
 	asst.Nil(err, common.CombineMessageWithError("test Create() failed", err))
 	// delete
@@ -129,9 +130,9 @@ func TestDBService_Update(t *testing.T) {
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewDBService(dbRepo)
-	err = s.Update(entity.Identity(), map[string]interface{}{dbDBNameStruct: newDBName})
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{dbDBNameStruct: newDBName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	dbName := s.GetDBs()[constant.ZeroInt].GetDBName()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
@@ -147,7 +148,7 @@ func TestDBService_Delete(t *testing.T) {
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	s := NewDBService(dbRepo)
-	err = s.Delete(entity.Identity())
+	err = s.Delete(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	// delete
 	err = deleteDBByID(entity.Identity())
@@ -159,7 +160,7 @@ func TestDBService_AddDBApp(t *testing.T) {
 
 	s := NewDBService(dbRepo)
 
-	err := s.AddApp(1, 3)
+	err := s.AddApp(context.Background(), 1, 3)
 	asst.Nil(err, common.CombineMessageWithError("test AddApp() failed", err))
 	appIDList := s.AppIDList
 	asst.Equal(3, len(appIDList), "test AddApp() failed")
@@ -169,7 +170,7 @@ func TestDBService_DeleteDBApp(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewDBService(dbRepo)
-	err := s.DeleteApp(1, 3)
+	err := s.DeleteApp(context.Background(), 1, 3)
 	asst.Nil(err, common.CombineMessageWithError("test DeleteApp() failed", err))
 	appIDList := s.AppIDList
 	asst.Equal(2, len(appIDList), "test DeleteApp() failed")
@@ -181,7 +182,7 @@ func TestDBService_Marshal(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewDBService(dbRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
 	data, err := s.Marshal()
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
@@ -201,7 +202,7 @@ func TestDBService_MarshalWithFields(t *testing.T) {
 	entity, err := createDB()
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	s := NewDBService(dbRepo)
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	dataService, err := s.MarshalWithFields(dbDBNameStruct)
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	dataEntity, err := entity.MarshalJSONWithFields(dbDBNameStruct)
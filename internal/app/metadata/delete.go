@@ -0,0 +1,460 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+const (
+	appTable               = "t_meta_app_info"
+	appDBMapTable          = "t_meta_app_db_map"
+	dbTable                = "t_meta_db_info"
+	envTable               = "t_meta_env_info"
+	middlewareClusterTable = "t_meta_middleware_cluster_info"
+	middlewareServerTable  = "t_meta_middleware_server_info"
+	monitorSystemTable     = "t_meta_monitor_system_info"
+	mysqlClusterTable      = "t_meta_mysql_cluster_info"
+	mysqlServerTable       = "t_meta_mysql_server_info"
+	userTable              = "t_meta_user_info"
+)
+
+const (
+	// policyRestrict means the referenced row could not be deleted while the referencing rows exist, even in cascade mode
+	policyRestrict = iota
+	// policyCascade means the referencing rows are deleted along with the referenced row in cascade mode
+	policyCascade
+	// policyDetach means the referencing column is reset to 0 in cascade mode, the referencing rows are kept
+	policyDetach
+)
+
+// reference is a column of a table which holds the identity of the referenced table
+type reference struct {
+	Table  string
+	Column string
+	// Condition filters the referencing rows, it is used when the column may reference more than one table
+	Condition string
+	Policy    int
+}
+
+// references are the references of each table, the key is the referenced table
+var references = map[string][]reference{
+	appTable: {
+		{Table: appDBMapTable, Column: "app_id", Policy: policyCascade},
+	},
+	dbTable: {
+		{Table: appDBMapTable, Column: "db_id", Policy: policyCascade},
+	},
+	envTable: {
+		{Table: dbTable, Column: "env_id", Policy: policyRestrict},
+		{Table: middlewareClusterTable, Column: "env_id", Policy: policyRestrict},
+		{Table: monitorSystemTable, Column: "env_id", Policy: policyRestrict},
+		{Table: mysqlClusterTable, Column: "env_id", Policy: policyRestrict},
+	},
+	middlewareClusterTable: {
+		{Table: middlewareServerTable, Column: "cluster_id", Policy: policyCascade},
+		{Table: dbTable, Column: "cluster_id", Condition: "cluster_type <> 1", Policy: policyCascade},
+		{Table: mysqlClusterTable, Column: "middleware_cluster_id", Policy: policyDetach},
+	},
+	monitorSystemTable: {
+		{Table: mysqlClusterTable, Column: "monitor_system_id", Policy: policyDetach},
+	},
+	mysqlClusterTable: {
+		{Table: mysqlServerTable, Column: "cluster_id", Policy: policyCascade},
+		{Table: dbTable, Column: "cluster_id", Condition: "cluster_type = 1", Policy: policyCascade},
+	},
+	userTable: {
+		{Table: appTable, Column: "owner_id", Policy: policyDetach},
+		{Table: dbTable, Column: "owner_id", Policy: policyDetach},
+		{Table: middlewareClusterTable, Column: "owner_id", Policy: policyDetach},
+		{Table: mysqlClusterTable, Column: "owner_id", Policy: policyDetach},
+	},
+}
+
+// referencedTables are the tables which have references, it keeps the order of the undelete checks stable
+var referencedTables = []string{
+	appTable, dbTable, envTable, middlewareClusterTable, monitorSystemTable, mysqlClusterTable, userTable,
+}
+
+// nameColumns are the columns or expressions which describe the rows of each table
+var nameColumns = map[string]string{
+	appTable:               "app_name",
+	appDBMapTable:          "concat('app_id: ', app_id, ', db_id: ', db_id)",
+	dbTable:                "db_name",
+	envTable:               "env_name",
+	middlewareClusterTable: "cluster_name",
+	middlewareServerTable:  "server_name",
+	monitorSystemTable:     "system_name",
+	mysqlClusterTable:      "cluster_name",
+	mysqlServerTable:       "server_name",
+	userTable:              "user_name",
+}
+
+var _ metadata.CascadedRow = (*Blocker)(nil)
+
+// Blocker is a row which prevents another row from being deleted or undeleted,
+// it is also the row which is deleted or detached along with the referenced row in cascade mode
+type Blocker struct {
+	Table string `json:"table"`
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+
+	reference       reference
+	referencedTable string
+	referencedID    int
+}
+
+// GetTable returns the table of the row
+func (b *Blocker) GetTable() string {
+	return b.Table
+}
+
+// Identity returns the identity of the row
+func (b *Blocker) Identity() int {
+	return b.ID
+}
+
+// GetName returns the name which describes the row
+func (b *Blocker) GetName() string {
+	return b.Name
+}
+
+// GetColumn returns the column of the row which references the referenced row
+func (b *Blocker) GetColumn() string {
+	return b.reference.Column
+}
+
+// GetReferencedTable returns the table of the row which the row references
+func (b *Blocker) GetReferencedTable() string {
+	return b.referencedTable
+}
+
+// GetReferencedID returns the identity of the row which the row references
+func (b *Blocker) GetReferencedID() int {
+	return b.referencedID
+}
+
+// IsDetached returns if the row is detached from the referenced row instead of being deleted
+func (b *Blocker) IsDetached() bool {
+	return b.reference.Policy == policyDetach
+}
+
+// String returns the description of the blocker
+func (b *Blocker) String() string {
+	return fmt.Sprintf("%s(id: %d, name: %s)", b.Table, b.ID, b.Name)
+}
+
+// BlockedError is returned when a row could not be deleted or undeleted because of the blockers
+type BlockedError struct {
+	Table    string
+	ID       int
+	Undelete bool
+	Blockers []*Blocker
+}
+
+// Error implements error interface
+func (be *BlockedError) Error() string {
+	blockers := make([]string, len(be.Blockers))
+	for i, blocker := range be.Blockers {
+		blockers[i] = blocker.String()
+	}
+
+	if be.Undelete {
+		return fmt.Sprintf("%s(id: %d) references the deleted rows, undelete them first. blockers: %s",
+			be.Table, be.ID, strings.Join(blockers, constant.CommaString+constant.SpaceString))
+	}
+
+	return fmt.Sprintf("%s(id: %d) is still referenced, delete the referencing rows first or delete in cascade mode. blockers: %s",
+		be.Table, be.ID, strings.Join(blockers, constant.CommaString+constant.SpaceString))
+}
+
+// transactor returns the transaction, all the repositories implement it
+type transactor interface {
+	Transaction() (middleware.Transaction, error)
+}
+
+// deleteByID soft deletes the row of the table in a transaction,
+// if cascade is false, it returns a *BlockedError when the row is still referenced by the rows which are not deleted,
+// otherwise, the referencing rows are deleted or detached according to the policies of the references,
+// the unique keys only apply to the rows which are not deleted, so the deleted row could be created again,
+// it returns a *ConflictError if the row was modified after it had been read with given last update time,
+// it returns the rows which are deleted or detached along with the row in cascade mode
+func deleteByID(ctx context.Context, repo transactor, caller, table string, id int, lastUpdateTime time.Time, cascade bool) ([]metadata.CascadedRow, error) {
+	var cascaded []*Blocker
+	err := executeInTransaction(repo, caller, func(tx middleware.Transaction) error {
+		var err error
+		cascaded, err = softDelete(ctx, tx, caller, table, id, getVersion(lastUpdateTime), cascade)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]metadata.CascadedRow, len(cascaded))
+	for i, row := range cascaded {
+		rows[i] = row
+	}
+
+	return rows, nil
+}
+
+// undeleteByID restores the soft deleted row of the table in a transaction,
+// it returns a *BlockedError when the row references the rows which are deleted,
+// the rows which were deleted or detached in cascade mode are not restored
+func undeleteByID(ctx context.Context, repo transactor, caller, table string, id int) error {
+	return executeInTransaction(repo, caller, func(tx middleware.Transaction) error {
+		exists, err := rowExists(ctx, tx, caller, table, id, true)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%s(id: %d) does not exist or is not deleted: %w", table, id, ErrDataNotExists)
+		}
+
+		blockers, err := getDeletedReferencedRows(ctx, tx, caller, table, id)
+		if err != nil {
+			return err
+		}
+		if len(blockers) > constant.ZeroInt {
+			return &BlockedError{Table: table, ID: id, Undelete: true, Blockers: blockers}
+		}
+
+		sql := fmt.Sprintf(`update %s set del_flag = 0 where id = ?;`, table)
+		tracing.Logger(ctx).Debugf("metadata %s.Undelete() update sql: %s\nplaceholders: %d", caller, sql, id)
+		_, err = tx.ExecuteContext(ctx, sql, id)
+
+		return err
+	})
+}
+
+// executeInTransaction executes the function in a transaction, it rolls back the transaction if the function fails
+func executeInTransaction(repo transactor, caller string, f func(tx middleware.Transaction) error) error {
+	tx, err := repo.Transaction()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = tx.Close()
+		if err != nil {
+			log.Errorf("metadata %s: close database connection failed.\n%s", caller, err.Error())
+		}
+	}()
+
+	err = tx.Begin()
+	if err != nil {
+		return err
+	}
+	err = f(tx)
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			log.Errorf("metadata %s: rollback transaction failed.\n%s", caller, rollbackErr.Error())
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// softDelete sets the del_flag of the row to 1, the referencing rows are handled recursively in cascade mode,
+// if version is not empty, the row is deleted only if it matches the version, otherwise, it returns a *ConflictError,
+// it returns the rows which are deleted or detached along with the row
+func softDelete(ctx context.Context, tx middleware.Transaction, caller, table string, id int, version string, cascade bool) ([]*Blocker, error) {
+	exists, err := rowExists(ctx, tx, caller, table, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s(id: %d) does not exist or had been deleted: %w", table, id, ErrDataNotExists)
+	}
+
+	blockers, err := getReferencingRows(ctx, tx, caller, table, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(blockers) > constant.ZeroInt {
+		if !cascade {
+			return nil, &BlockedError{Table: table, ID: id, Blockers: blockers}
+		}
+		var restricted []*Blocker
+		for _, blocker := range blockers {
+			if blocker.reference.Policy == policyRestrict {
+				restricted = append(restricted, blocker)
+			}
+		}
+		if len(restricted) > constant.ZeroInt {
+			return nil, &BlockedError{Table: table, ID: id, Blockers: restricted}
+		}
+	}
+
+	var cascaded []*Blocker
+	for _, blocker := range blockers {
+		cascaded = append(cascaded, blocker)
+		switch blocker.reference.Policy {
+		case policyCascade:
+			var rows []*Blocker
+			rows, err = softDelete(ctx, tx, caller, blocker.Table, blocker.ID, constant.EmptyString, cascade)
+			cascaded = append(cascaded, rows...)
+		case policyDetach:
+			sql := fmt.Sprintf(`update %s set %s = 0 where id = ?;`, blocker.Table, blocker.reference.Column)
+			tracing.Logger(ctx).Debugf("metadata %s.Delete() detach sql: %s\nplaceholders: %d", caller, sql, blocker.ID)
+			_, err = tx.ExecuteContext(ctx, sql, blocker.ID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if version == constant.EmptyString {
+		sql := fmt.Sprintf(`update %s set del_flag = 1 where id = ?;`, table)
+		tracing.Logger(ctx).Debugf("metadata %s.Delete() update sql: %s\nplaceholders: %d", caller, sql, id)
+		_, err = tx.ExecuteContext(ctx, sql, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return cascaded, nil
+	}
+
+	sql := fmt.Sprintf(`update %s set del_flag = 1 where id = ? and %s = ?;`, table, versionColumn)
+	tracing.Logger(ctx).Debugf("metadata %s.Delete() update sql: %s\nplaceholders: %d, %s", caller, sql, id, version)
+	result, err := tx.ExecuteContext(ctx, sql, id, version)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == constant.ZeroInt {
+		return nil, &ConflictError{Table: table, ID: id}
+	}
+
+	return cascaded, nil
+}
+
+// rowExists returns if the row of the table exists, deleted specifies the expected del_flag of the row
+func rowExists(ctx context.Context, tx middleware.Transaction, caller, table string, id int, deleted bool) (bool, error) {
+	delFlag := constant.ZeroInt
+	if deleted {
+		delFlag = 1
+	}
+
+	sql := fmt.Sprintf(`select count(*) from %s where id = ? and del_flag = ? for update;`, table)
+	tracing.Logger(ctx).Debugf("metadata %s.rowExists() select sql: %s\nplaceholders: %d, %d", caller, sql, id, delFlag)
+	result, err := tx.ExecuteContext(ctx, sql, id, delFlag)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return false, err
+	}
+
+	return count > constant.ZeroInt, nil
+}
+
+// getReferencingRows returns the rows which are not deleted and reference the row of the table
+func getReferencingRows(ctx context.Context, tx middleware.Transaction, caller, table string, id int) ([]*Blocker, error) {
+	var blockers []*Blocker
+
+	for _, ref := range references[table] {
+		condition := constant.EmptyString
+		if ref.Condition != constant.EmptyString {
+			condition = " and " + ref.Condition
+		}
+		sql := fmt.Sprintf(`select id, %s from %s where %s = ? and del_flag = 0%s order by id;`,
+			nameColumns[ref.Table], ref.Table, ref.Column, condition)
+		tracing.Logger(ctx).Debugf("metadata %s.getReferencingRows() select sql: %s\nplaceholders: %d", caller, sql, id)
+		result, err := tx.ExecuteContext(ctx, sql, id)
+		if err != nil {
+			return nil, err
+		}
+		for row := constant.ZeroInt; row < result.RowNumber(); row++ {
+			blocker, err := newBlocker(result, row, ref)
+			if err != nil {
+				return nil, err
+			}
+			blocker.referencedTable = table
+			blocker.referencedID = id
+			blockers = append(blockers, blocker)
+		}
+	}
+
+	return blockers, nil
+}
+
+// getDeletedReferencedRows returns the deleted rows which are referenced by the row of the table
+func getDeletedReferencedRows(ctx context.Context, tx middleware.Transaction, caller, table string, id int) ([]*Blocker, error) {
+	var blockers []*Blocker
+
+	for _, referencedTable := range referencedTables {
+		for _, ref := range references[referencedTable] {
+			if ref.Table != table {
+				continue
+			}
+			condition := constant.EmptyString
+			if ref.Condition != constant.EmptyString {
+				condition = " and " + ref.Condition
+			}
+			sql := fmt.Sprintf(`select %s from %s where id = ?%s;`, ref.Column, table, condition)
+			tracing.Logger(ctx).Debugf("metadata %s.getDeletedReferencedRows() select sql: %s\nplaceholders: %d", caller, sql, id)
+			result, err := tx.ExecuteContext(ctx, sql, id)
+			if err != nil {
+				return nil, err
+			}
+			if result.RowNumber() == constant.ZeroInt {
+				continue
+			}
+			referencedID, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+			if err != nil {
+				return nil, err
+			}
+			if referencedID == constant.ZeroInt {
+				continue
+			}
+
+			sql = fmt.Sprintf(`select id, %s from %s where id = ? and del_flag = 1;`, nameColumns[referencedTable], referencedTable)
+			tracing.Logger(ctx).Debugf("metadata %s.getDeletedReferencedRows() select sql: %s\nplaceholders: %d", caller, sql, referencedID)
+			result, err = tx.ExecuteContext(ctx, sql, referencedID)
+			if err != nil {
+				return nil, err
+			}
+			if result.RowNumber() == constant.ZeroInt {
+				continue
+			}
+			blocker, err := newBlocker(result, constant.ZeroInt, ref)
+			if err != nil {
+				return nil, err
+			}
+			blocker.Table = referencedTable
+			blockers = append(blockers, blocker)
+		}
+	}
+
+	return blockers, nil
+}
+
+// newBlocker returns a new *Blocker of the table of the reference with the id and name in the row of the result
+func newBlocker(result middleware.Result, row int, ref reference) (*Blocker, error) {
+	id, err := result.GetInt(row, constant.ZeroInt)
+	if err != nil {
+		return nil, err
+	}
+	name, err := result.GetString(row, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Blocker{Table: ref.Table, ID: id, Name: name, reference: ref}, nil
+}
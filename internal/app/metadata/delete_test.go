@@ -0,0 +1,46 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteAll(t *testing.T) {
+	TestDelete_References(t)
+	TestBlockedError_Error(t)
+}
+
+func TestDelete_References(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Equal(len(references), len(referencedTables), "test references failed")
+	for _, table := range referencedTables {
+		refs, ok := references[table]
+		asst.True(ok, "test references failed. table: %s", table)
+		asst.NotEqual(constant.EmptyString, nameColumns[table], "test references failed. table: %s", table)
+		for _, ref := range refs {
+			asst.NotEqual(constant.EmptyString, nameColumns[ref.Table], "test references failed. table: %s", ref.Table)
+		}
+	}
+}
+
+func TestBlockedError_Error(t *testing.T) {
+	asst := assert.New(t)
+
+	be := &BlockedError{
+		Table: mysqlClusterTable,
+		ID:    1,
+		Blockers: []*Blocker{
+			{Table: mysqlServerTable, ID: 1, Name: "192-168-137-11"},
+			{Table: dbTable, ID: 2, Name: "db1"},
+		},
+	}
+	asst.Equal("t_meta_mysql_cluster_info(id: 1) is still referenced, delete the referencing rows first or delete in cascade mode. "+
+		"blockers: t_meta_mysql_server_info(id: 1, name: 192-168-137-11), t_meta_db_info(id: 2, name: db1)", be.Error(), "test Error() failed")
+
+	be.Undelete = true
+	asst.Equal("t_meta_mysql_cluster_info(id: 1) references the deleted rows, undelete them first. "+
+		"blockers: t_meta_mysql_server_info(id: 1, name: 192-168-137-11), t_meta_db_info(id: 2, name: db1)", be.Error(), "test Error() failed")
+}
@@ -1,8 +1,10 @@
 package metadata
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
@@ -11,6 +13,8 @@ import (
 
 	"github.com/romberli/das/global"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/tracing"
 )
 
 var _ metadata.EnvRepo = (*EnvRepo)(nil)
@@ -30,9 +34,11 @@ func NewEnvRepoWithGlobal() *EnvRepo {
 }
 
 // Execute executes given command and placeholders on the middleware
-func (er *EnvRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+func (er *EnvRepo) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
 	conn, err := er.Database.Get()
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
 	defer func() {
@@ -42,7 +48,10 @@ func (er *EnvRepo) Execute(command string, args ...interface{}) (middleware.Resu
 		}
 	}()
 
-	return conn.Execute(command, args...)
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
 }
 
 // Transaction returns middleware.PoolConn, so it can run multiple statements in the same transaction
@@ -51,16 +60,16 @@ func (er *EnvRepo) Transaction() (middleware.Transaction, error) {
 }
 
 // GetAll gets all environments from the middleware
-func (er *EnvRepo) GetAll() ([]metadata.Env, error) {
+func (er *EnvRepo) GetAll(ctx context.Context) ([]metadata.Env, error) {
 	sql := `
 		select id, env_name, del_flag, create_time, last_update_time
 		from t_meta_env_info
 		where del_flag = 0
 		order by id;
 	`
-	log.Debugf("metadata EnvRepo.GetAll() sql: \n%s", sql)
+	tracing.Logger(ctx).Debugf("metadata EnvRepo.GetAll() sql: \n%s", sql)
 
-	result, err := er.Execute(sql)
+	result, err := er.Execute(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -83,23 +92,49 @@ func (er *EnvRepo) GetAll() ([]metadata.Env, error) {
 	return entityList, nil
 }
 
+// GetByQuery gets the envs which match the query from the middleware,
+// it also returns the total number of the matched envs without pagination
+func (er *EnvRepo) GetByQuery(ctx context.Context, query *filter.Query) ([]metadata.Env, int, error) {
+	result, total, err := getByQuery(ctx, er, "EnvRepo", envTable, envColumns, query)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []*EnvInfo
+	envInfoList := make([]*EnvInfo, result.RowNumber())
+	for i := range envInfoList {
+		envInfoList[i] = NewEmptyEnvInfoWithGlobal()
+	}
+	// map to struct
+	err = result.MapToStructSlice(envInfoList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []metadata.Env
+	entityList := make([]metadata.Env, result.RowNumber())
+	for i := range entityList {
+		entityList[i] = envInfoList[i]
+	}
+
+	return entityList, total, nil
+}
+
 // GetByID gets an environment by the identity from the middleware
-func (er *EnvRepo) GetByID(id int) (metadata.Env, error) {
+func (er *EnvRepo) GetByID(ctx context.Context, id int) (metadata.Env, error) {
 	sql := `
 		select id, env_name, del_flag, create_time, last_update_time
 		from t_meta_env_info
 		where del_flag = 0
 		and id = ?;
 	`
-	log.Debugf("metadata EnvRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, id)
+	tracing.Logger(ctx).Debugf("metadata EnvRepo.GetByID() sql: \n%s\nplaceholders: %d", sql, id)
 
-	result, err := er.Execute(sql, id)
+	result, err := er.Execute(ctx, sql, id)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata EnvInfo.GetByID(): data does not exists, id: %d", id))
+		return nil, fmt.Errorf("metadata EnvInfo.GetByID(): %w, id: %d", ErrDataNotExists, id)
 	case 1:
 		envInfo := NewEmptyEnvInfoWithGlobal()
 		// map to struct
@@ -115,10 +150,10 @@ func (er *EnvRepo) GetByID(id int) (metadata.Env, error) {
 }
 
 // GetID gets the identity with given environment name from the middleware
-func (er *EnvRepo) GetID(envName string) (int, error) {
+func (er *EnvRepo) GetID(ctx context.Context, envName string) (int, error) {
 	sql := `select id from t_meta_env_info where del_flag = 0 and env_name = ?;`
-	log.Debugf("metadata EnvRepo.GetID() select sql: %s", sql)
-	result, err := er.Execute(sql, envName)
+	tracing.Logger(ctx).Debugf("metadata EnvRepo.GetID() select sql: %s", sql)
+	result, err := er.Execute(ctx, sql, envName)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -127,77 +162,73 @@ func (er *EnvRepo) GetID(envName string) (int, error) {
 }
 
 // Create creates an environment in the middleware
-func (er *EnvRepo) Create(env metadata.Env) (metadata.Env, error) {
+func (er *EnvRepo) Create(ctx context.Context, env metadata.Env) (metadata.Env, error) {
 	sql := `insert into t_meta_env_info(env_name) values(?);`
-	log.Debugf("metadata EnvRepo.Create() insert sql: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata EnvRepo.Create() insert sql: %s", sql)
 	// execute
-	_, err := er.Execute(sql, env.GetEnvName())
+	_, err := er.Execute(ctx, sql, env.GetEnvName())
 	if err != nil {
 		return nil, err
 	}
 	// get id
-	id, err := er.GetID(env.GetEnvName())
+	id, err := er.GetID(ctx, env.GetEnvName())
 	if err != nil {
 		return nil, err
 	}
 	// get env
-	return er.GetByID(id)
+	return er.GetByID(ctx, id)
 }
 
 // Update updates the environment in the middleware
-func (er *EnvRepo) Update(env metadata.Env) error {
-	sql := `update t_meta_env_info set env_name = ?, del_flag = ? where id = ?;`
-	log.Debugf("metadata EnvRepo.Update() update sql: %s", sql)
-	_, err := er.Execute(sql, env.GetEnvName(), env.GetDelFlag(), env.Identity())
-
-	return err
-}
-
-// Delete deletes the environment in the middleware
-func (er *EnvRepo) Delete(id int) error {
-	tx, err := er.Transaction()
+// it returns a *ConflictError if the row was modified or deleted after the entity had been read
+func (er *EnvRepo) Update(ctx context.Context, env metadata.Env) error {
+	sql := `update t_meta_env_info set env_name = ?, del_flag = ? where id = ? and date_format(last_update_time, '%Y-%m-%d %H:%i:%s.%f') = ?;`
+	tracing.Logger(ctx).Debugf("metadata EnvRepo.Update() update sql: %s", sql)
+	result, err := er.Execute(ctx, sql, env.GetEnvName(), env.GetDelFlag(), env.Identity(), getVersion(env.GetLastUpdateTime()))
 	if err != nil {
 		return err
 	}
-	defer func() {
-		err = tx.Close()
-		if err != nil {
-			log.Errorf("metadata EnvRepo.Delete(): close database connection failed.\n%s", err.Error())
-		}
-	}()
 
-	err = tx.Begin()
-	if err != nil {
-		return err
-	}
-	//delete from t_meta_env_info where id =
-	sql := `delete from t_meta_env_info where id = ?;`
-	log.Debugf("metadata EnvRepo.Delete() delete sql(t_meta_app_info): %s", sql)
-	_, err = tx.Execute(sql, id)
-	if err != nil {
-		return err
-	}
+	return checkUpdated(ctx, er, "EnvRepo", envTable, env.Identity(), env.GetLastUpdateTime(), result)
+}
+
+// Delete soft deletes the environment in the middleware,
+// it returns a *BlockedError if the environment is still referenced by the other metadata,
+// and a *ConflictError if the row was modified after it had been read with given last update time
+func (er *EnvRepo) Delete(ctx context.Context, id int, lastUpdateTime time.Time) error {
+	_, err := deleteByID(ctx, er, "EnvRepo", envTable, id, lastUpdateTime, false)
+
+	return err
+}
+
+// DeleteCascade soft deletes the environment and the metadata which reference it in the middleware,
+// it returns the rows which are deleted or detached along with the environment
+func (er *EnvRepo) DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]metadata.CascadedRow, error) {
+	return deleteByID(ctx, er, "EnvRepo", envTable, id, lastUpdateTime, true)
+}
 
-	return tx.Commit()
+// Undelete restores the soft deleted environment in the middleware
+func (er *EnvRepo) Undelete(ctx context.Context, id int) error {
+	return undeleteByID(ctx, er, "EnvRepo", envTable, id)
 }
 
 // GetEnvByName gets Env of given environment name
-func (er *EnvRepo) GetEnvByName(envName string) (metadata.Env, error) {
+func (er *EnvRepo) GetEnvByName(ctx context.Context, envName string) (metadata.Env, error) {
 	sql := `
 		select id, env_name, del_flag, create_time, last_update_time
 		from t_meta_env_info
 		where del_flag = 0
 		and env_name = ?;
 	`
-	log.Debugf("metadata EnvRepo.GetEnvByName() sql: \n%s\nplaceholders: %s", sql, envName)
+	tracing.Logger(ctx).Debugf("metadata EnvRepo.GetEnvByName() sql: \n%s\nplaceholders: %s", sql, envName)
 
-	result, err := er.Execute(sql, envName)
+	result, err := er.Execute(ctx, sql, envName)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata EnvInfo.GetEnvByName(): data does not exists, env_name: %s", envName))
+		return nil, fmt.Errorf("metadata EnvInfo.GetEnvByName(): %w, env_name: %s", ErrDataNotExists, envName)
 	case 1:
 		envInfo := NewEmptyEnvInfoWithGlobal()
 		// map to struct
@@ -1,6 +1,8 @@
 package metadata
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/romberli/go-util/common"
@@ -36,7 +38,7 @@ func initEnvRepo() *EnvRepo {
 
 func createEnv() (metadata.Env, error) {
 	envInfo := NewEnvInfoWithDefault(defaultEnvInfoEnvName)
-	entity, err := envRepo.Create(envInfo)
+	entity, err := envRepo.Create(context.Background(), envInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +48,7 @@ func createEnv() (metadata.Env, error) {
 
 func deleteEnvByID(id int) error {
 	sql := `delete from t_meta_env_info where id = ?`
-	_, err := envRepo.Execute(sql, id)
+	_, err := envRepo.Execute(context.Background(), sql, id)
 	return err
 }
 
@@ -56,7 +58,9 @@ func TestEnvRepoAll(t *testing.T) {
 	TestEnvRepo_GetByID(t)
 	TestEnvRepo_Create(t)
 	TestEnvRepo_Update(t)
+	TestEnvRepo_UpdateConflict(t)
 	TestEnvRepo_Delete(t)
+	TestEnvRepo_Undelete(t)
 	TestEnvRepo_GetID(t)
 	TestEnvRepo_GetEnvByName(t)
 
@@ -66,7 +70,7 @@ func TestEnvRepo_Execute(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := `select 1;`
-	result, err := envRepo.Execute(sql)
+	result, err := envRepo.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
 	r, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
@@ -81,11 +85,11 @@ func TestEnvRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	err = tx.Begin()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
-	_, err = tx.Execute(sql, defaultEnvInfoEnvName)
+	_, err = tx.ExecuteContext(context.Background(), sql, defaultEnvInfoEnvName)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if inserted
 	sql = `select env_name from t_meta_env_info where env_name=?`
-	result, err := tx.Execute(sql, defaultEnvInfoEnvName)
+	result, err := tx.ExecuteContext(context.Background(), sql, defaultEnvInfoEnvName)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	envName, err := result.GetString(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
@@ -95,7 +99,7 @@ func TestEnvRepo_Transaction(t *testing.T) {
 	err = tx.Rollback()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if rollbacked
-	envs, err := envRepo.GetAll()
+	envs, err := envRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	for _, env := range envs {
 		envName := env.GetEnvName()
@@ -110,7 +114,7 @@ func TestEnvRepo_Transaction(t *testing.T) {
 func TestEnvRepo_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
-	entities, err := envRepo.GetAll()
+	entities, err := envRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	envName := entities[0].GetEnvName()
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
@@ -120,7 +124,7 @@ func TestEnvRepo_GetAll(t *testing.T) {
 func TestEnvRepo_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := envRepo.GetByID(2)
+	entity, err := envRepo.GetByID(context.Background(), 2)
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	envName := entity.GetEnvName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
@@ -145,9 +149,9 @@ func TestEnvRepo_Update(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	err = env.Set(map[string]interface{}{envNameStruct: newEnvName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = envRepo.Update(env)
+	err = envRepo.Update(context.Background(), env)
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	env, err = envRepo.GetByID(env.Identity())
+	env, err = envRepo.GetByID(context.Background(), env.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	envName := env.GetEnvName()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
@@ -157,24 +161,69 @@ func TestEnvRepo_Update(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 }
 
+func TestEnvRepo_UpdateConflict(t *testing.T) {
+	asst := assert.New(t)
+
+	env, err := createEnv()
+	asst.Nil(err, common.CombineMessageWithError("test UpdateConflict() failed", err))
+	stale, err := envRepo.GetByID(context.Background(), env.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test UpdateConflict() failed", err))
+	// the fresh entity is updated
+	err = env.Set(map[string]interface{}{envNameStruct: newEnvName})
+	asst.Nil(err, common.CombineMessageWithError("test UpdateConflict() failed", err))
+	err = envRepo.Update(context.Background(), env)
+	asst.Nil(err, common.CombineMessageWithError("test UpdateConflict() failed", err))
+	// the stale entity is rejected, as the last update time was changed by the update above
+	err = stale.Set(map[string]interface{}{envNameStruct: onlineEnvName})
+	asst.Nil(err, common.CombineMessageWithError("test UpdateConflict() failed", err))
+	err = envRepo.Update(context.Background(), stale)
+	var conflictErr *ConflictError
+	asst.True(errors.As(err, &conflictErr), common.CombineMessageWithError("test UpdateConflict() failed", err))
+	asst.Equal(&ConflictError{Table: envTable, ID: env.Identity()}, conflictErr, "test UpdateConflict() failed")
+	env, err = envRepo.GetByID(context.Background(), env.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test UpdateConflict() failed", err))
+	asst.Equal(newEnvName, env.GetEnvName(), "test UpdateConflict() failed")
+	// delete
+	err = deleteEnvByID(env.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test UpdateConflict() failed", err))
+}
+
 func TestEnvRepo_Delete(t *testing.T) {
 	asst := assert.New(t)
 
 	env, err := createEnv()
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
-	ID, err := envRepo.GetID(env.GetEnvName())
+	ID, err := envRepo.GetID(context.Background(), env.GetEnvName())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
-	err = envRepo.Delete(ID)
+	err = envRepo.Delete(context.Background(), ID, env.GetLastUpdateTime())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	// delete
 	err = deleteEnvByID(env.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 }
 
+func TestEnvRepo_Undelete(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createEnv()
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	err = envRepo.Delete(context.Background(), entity.Identity(), entity.GetLastUpdateTime())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = envRepo.GetByID(context.Background(), entity.Identity())
+	asst.NotNil(err, "test Undelete() failed")
+	err = envRepo.Undelete(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = envRepo.GetByID(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	// delete
+	err = deleteEnvByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+}
+
 func TestEnvRepo_GetEnvByName(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := envRepo.GetEnvByName("rel")
+	entity, err := envRepo.GetEnvByName(context.Background(), "rel")
 	asst.Nil(err, common.CombineMessageWithError("test GetEnvByName() failed", err))
 	envName := entity.GetEnvName()
 	asst.Nil(err, common.CombineMessageWithError("test GetEnvByName() failed", err))
@@ -184,9 +233,9 @@ func TestEnvRepo_GetEnvByName(t *testing.T) {
 func TestEnvRepo_GetID(t *testing.T) {
 	asst := assert.New(t)
 
-	env, err := envRepo.GetEnvByName("rel")
+	env, err := envRepo.GetEnvByName(context.Background(), "rel")
 	asst.Nil(err, common.CombineMessageWithError("test GetID() failed", err))
-	ID, err := envRepo.GetID(env.GetEnvName())
+	ID, err := envRepo.GetID(context.Background(), env.GetEnvName())
 	asst.Nil(err, common.CombineMessageWithError("test GetID() failed", err))
 	asst.Equal(onlineID, ID, "test GetID() failed")
 }
@@ -1,10 +1,16 @@
 package metadata
 
 import (
+	"context"
+	"encoding/json"
+
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 
+	"github.com/romberli/das/internal/app/audit"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 )
 
@@ -15,16 +21,33 @@ var _ metadata.EnvService = (*EnvService)(nil)
 type EnvService struct {
 	metadata.EnvRepo
 	Envs []metadata.Env `json:"Envs"`
+	Page *filter.Page   `json:"page"`
+
+	auditor depaudit.Auditor
+	ifMatch string
 }
 
 // NewEnvService returns a new *EnvService
 func NewEnvService(repo metadata.EnvRepo) *EnvService {
-	return &EnvService{repo, []metadata.Env{}}
+	return &EnvService{repo, []metadata.Env{}, nil, nil, constant.EmptyString}
 }
 
 // NewEnvServiceWithDefault returns a new *EnvService with default EnvRepo
 func NewEnvServiceWithDefault() *EnvService {
-	return NewEnvService(NewEnvRepoWithGlobal())
+	s := NewEnvService(NewEnvRepoWithGlobal())
+	s.SetAuditor(audit.NewAuditorWithGlobal(audit.DefaultActor, constant.EmptyString))
+
+	return s
+}
+
+// SetAuditor sets the auditor which records the changes of the environments
+func (es *EnvService) SetAuditor(auditor depaudit.Auditor) {
+	es.auditor = auditor
+}
+
+// SetIfMatch sets the entity tags which the environment to update or delete must match, it is not checked if ifMatch is empty
+func (es *EnvService) SetIfMatch(ifMatch string) {
+	es.ifMatch = ifMatch
 }
 
 // GetEnvs returns environments of the service
@@ -33,15 +56,32 @@ func (es *EnvService) GetEnvs() []metadata.Env {
 }
 
 // GetAll gets all environments from the middleware
-func (es *EnvService) GetAll() error {
+func (es *EnvService) GetAll(ctx context.Context) error {
 	var err error
-	es.Envs, err = es.EnvRepo.GetAll()
+	es.Envs, err = es.EnvRepo.GetAll(ctx)
 
 	return err
 }
 
+// GetByQuery gets the environments which match the query from the middleware, and the pagination information
+func (es *EnvService) GetByQuery(ctx context.Context, query *filter.Query) error {
+	envs, total, err := es.EnvRepo.GetByQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	es.Envs = envs
+	ids := make([]int, len(envs))
+	for i, env := range envs {
+		ids[i] = env.Identity()
+	}
+	es.Page = query.GetPage(total, ids)
+
+	return nil
+}
+
 // GetID gets identity of an entity with given fields
-func (es *EnvService) GetID(fields map[string]interface{}) (int, error) {
+func (es *EnvService) GetID(ctx context.Context, fields map[string]interface{}) (int, error) {
 	_, ok := fields[envNameStruct]
 	if !ok {
 		return constant.ZeroInt, message.NewMessage(message.ErrFieldNotExists, envNameStruct)
@@ -52,7 +92,7 @@ func (es *EnvService) GetID(fields map[string]interface{}) (int, error) {
 		return constant.ZeroInt, err
 	}
 	// get identity from the middleware
-	id, err := es.EnvRepo.GetID(envInfo.EnvName)
+	id, err := es.EnvRepo.GetID(ctx, envInfo.EnvName)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -61,8 +101,8 @@ func (es *EnvService) GetID(fields map[string]interface{}) (int, error) {
 }
 
 // GetByID gets an environment of the given id from the middleware
-func (es *EnvService) GetByID(id int) error {
-	entity, err := es.EnvRepo.GetByID(id)
+func (es *EnvService) GetByID(ctx context.Context, id int) error {
+	entity, err := es.EnvRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -73,8 +113,8 @@ func (es *EnvService) GetByID(id int) error {
 }
 
 // GetEnvByName returns Env of given env name
-func (es *EnvService) GetEnvByName(envName string) error {
-	env, err := es.EnvRepo.GetEnvByName(envName)
+func (es *EnvService) GetEnvByName(ctx context.Context, envName string) error {
+	env, err := es.EnvRepo.GetEnvByName(ctx, envName)
 	if err != nil {
 		return err
 	}
@@ -85,7 +125,7 @@ func (es *EnvService) GetEnvByName(envName string) error {
 }
 
 // Create creates an environment in the middleware
-func (es *EnvService) Create(fields map[string]interface{}) error {
+func (es *EnvService) Create(ctx context.Context, fields map[string]interface{}) error {
 	// generate new map
 	_, ok := fields[envNameStruct]
 	if !ok {
@@ -97,12 +137,13 @@ func (es *EnvService) Create(fields map[string]interface{}) error {
 		return err
 	}
 	// insert into middleware
-	env, err := es.EnvRepo.Create(envInfo)
+	env, err := es.EnvRepo.Create(ctx, envInfo)
 	if err != nil {
 		return err
 	}
 
 	es.Envs = append(es.Envs, env)
+	record(ctx, es.auditor, audit.EntityTypeEnv, env.Identity(), audit.ActionCreate, nil, env)
 
 	return nil
 }
@@ -111,8 +152,16 @@ func (es *EnvService) Create(fields map[string]interface{}) error {
 // and then updates its fields that was specified in fields argument,
 // key is the filed name and value is the new field value,
 // it saves the changes to the middleware
-func (es *EnvService) Update(id int, fields map[string]interface{}) error {
-	err := es.GetByID(id)
+func (es *EnvService) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	err := es.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(es.ifMatch, envTable, id, es.Envs[constant.ZeroInt].GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	before, err := es.Envs[constant.ZeroInt].MarshalJSON()
 	if err != nil {
 		return err
 	}
@@ -120,13 +169,68 @@ func (es *EnvService) Update(id int, fields map[string]interface{}) error {
 	if err != nil {
 		return err
 	}
+	err = es.EnvRepo.Update(ctx, es.Envs[constant.ZeroInt])
+	if err != nil {
+		return err
+	}
+	record(ctx, es.auditor, audit.EntityTypeEnv, id, audit.ActionUpdate, json.RawMessage(before), es.Envs[constant.ZeroInt])
 
-	return es.EnvRepo.Update(es.Envs[constant.ZeroInt])
+	return nil
 }
 
 // Delete deletes the environment of given id in the middleware
-func (es *EnvService) Delete(id int) error {
-	return es.EnvRepo.Delete(id)
+func (es *EnvService) Delete(ctx context.Context, id int) error {
+	before, err := es.EnvRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(es.ifMatch, envTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	err = es.EnvRepo.Delete(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, es.auditor, audit.EntityTypeEnv, id, audit.ActionDelete, before, nil)
+
+	return nil
+}
+
+// DeleteCascade deletes the environment of given id and the metadata which reference it in the middleware
+func (es *EnvService) DeleteCascade(ctx context.Context, id int) error {
+	before, err := es.EnvRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(es.ifMatch, envTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	rows, err := es.EnvRepo.DeleteCascade(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, es.auditor, audit.EntityTypeEnv, id, audit.ActionDeleteCascade, before, nil)
+	recordCascaded(ctx, es.auditor, rows)
+
+	return nil
+}
+
+// Undelete restores the deleted environment of given id in the middleware, and then gets it
+func (es *EnvService) Undelete(ctx context.Context, id int) error {
+	err := es.EnvRepo.Undelete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = es.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	record(ctx, es.auditor, audit.EntityTypeEnv, id, audit.ActionUndelete, nil, es.Envs[constant.ZeroInt])
+
+	return nil
 }
 
 // Marshal marshals EnvService.Envs to json bytes
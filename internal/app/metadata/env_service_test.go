@@ -1,9 +1,12 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
@@ -16,6 +19,7 @@ func TestEnvServiceAll(t *testing.T) {
 	TestEnvService_GetByID(t)
 	TestEnvService_Create(t)
 	TestEnvService_Update(t)
+	TestEnvService_UpdateIfMatch(t)
 	TestEnvService_Delete(t)
 	TestEnvService_Marshal(t)
 	TestEnvService_MarshalWithFields(t)
@@ -26,7 +30,7 @@ func TestEnvService_GetEnvByName(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewEnvService(envRepo)
-	err := s.GetEnvByName("online")
+	err := s.GetEnvByName(context.Background(), "online")
 	asst.Nil(err, "test GetByID() failed")
 	Name := s.Envs[constant.ZeroInt].GetEnvName()
 	asst.Equal("online", Name, "test GetByID() failed")
@@ -36,7 +40,7 @@ func TestEnvService_GetEnvs(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewEnvService(envRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetEnvs() failed")
 	Envs := s.GetEnvs()
 	asst.Greater(len(Envs), constant.ZeroInt, "test GetEnvs() failed")
@@ -46,7 +50,7 @@ func TestEnvService_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewEnvService(envRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetAll() failed")
 	Envs := s.GetEnvs()
 	asst.Greater(len(Envs), constant.ZeroInt, "test GetAll() failed")
@@ -56,7 +60,7 @@ func TestEnvService_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewEnvService(envRepo)
-	err := s.GetByID(1)
+	err := s.GetByID(context.Background(), 1)
 	asst.Nil(err, "test GetByID() failed")
 	id := s.Envs[constant.ZeroInt].Identity()
 	asst.Equal(1, id, "test GetByID() failed")
@@ -66,7 +70,7 @@ func TestEnvService_Create(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewEnvService(envRepo)
-	err := s.Create(map[string]interface{}{envNameStruct: defaultEnvInfoEnvName})
+	err := s.Create(context.Background(), map[string]interface{}{envNameStruct: defaultEnvInfoEnvName})
 	asst.Nil(err, common.CombineMessageWithError("test Create() failed", err))
 	// delete
 	err = deleteEnvByID(s.Envs[0].Identity())
@@ -79,9 +83,9 @@ func TestEnvService_Update(t *testing.T) {
 	entity, err := createEnv()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewEnvService(envRepo)
-	err = s.Update(entity.Identity(), map[string]interface{}{envNameStruct: newEnvName})
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{envNameStruct: newEnvName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	envName := s.GetEnvs()[constant.ZeroInt].GetEnvName()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
@@ -91,13 +95,44 @@ func TestEnvService_Update(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 }
 
+func TestEnvService_UpdateIfMatch(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createEnv()
+	asst.Nil(err, common.CombineMessageWithError("test UpdateIfMatch() failed", err))
+	etag := GetETag(entity.GetLastUpdateTime())
+	// the stale entity tag is rejected
+	s := NewEnvService(envRepo)
+	s.SetIfMatch(GetETag(entity.GetLastUpdateTime().Add(-time.Second)))
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{envNameStruct: newEnvName})
+	var conflictErr *ConflictError
+	asst.True(errors.As(err, &conflictErr), common.CombineMessageWithError("test UpdateIfMatch() failed", err))
+	asst.Equal(&ConflictError{Table: envTable, ID: entity.Identity(), ETag: etag}, conflictErr, "test UpdateIfMatch() failed")
+	// the fresh entity tag is accepted
+	s = NewEnvService(envRepo)
+	s.SetIfMatch(etag)
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{envNameStruct: newEnvName})
+	asst.Nil(err, common.CombineMessageWithError("test UpdateIfMatch() failed", err))
+	err = s.GetByID(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test UpdateIfMatch() failed", err))
+	asst.Equal(newEnvName, s.GetEnvs()[constant.ZeroInt].GetEnvName(), "test UpdateIfMatch() failed")
+	// the entity tag is stale after the update
+	s = NewEnvService(envRepo)
+	s.SetIfMatch(etag)
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{envNameStruct: onlineEnvName})
+	asst.True(errors.As(err, &conflictErr), common.CombineMessageWithError("test UpdateIfMatch() failed", err))
+	// delete
+	err = deleteEnvByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test UpdateIfMatch() failed", err))
+}
+
 func TestEnvService_Delete(t *testing.T) {
 	asst := assert.New(t)
 
 	entity, err := createEnv()
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	s := NewEnvService(envRepo)
-	err = s.Delete(entity.Identity())
+	err = s.Delete(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	// delete
 	err = deleteEnvByID(entity.Identity())
@@ -110,7 +145,7 @@ func TestEnvService_Marshal(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewEnvService(envRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
 	data, err := s.Marshal()
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
@@ -130,7 +165,7 @@ func TestEnvService_MarshalWithFields(t *testing.T) {
 	entity, err := createEnv()
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	s := NewEnvService(envRepo)
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	dataService, err := s.MarshalWithFields(envNameStruct)
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	dataEntity, err := entity.MarshalJSONWithFields(envNameStruct)
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/das/internal/dependency/metadata"
@@ -118,8 +119,8 @@ func (mci *MiddlewareClusterInfo) GetLastUpdateTime() time.Time {
 }
 
 // GetMiddlewareServerIDList gets the middleware server id list of this cluster
-func (mci *MiddlewareClusterInfo) GetMiddlewareServerIDList() ([]int, error) {
-	return mci.MiddlewareClusterRepo.GetMiddlewareServerIDList(mci.Identity())
+func (mci *MiddlewareClusterInfo) GetMiddlewareServerIDList(ctx context.Context) ([]int, error) {
+	return mci.MiddlewareClusterRepo.GetMiddlewareServerIDList(ctx, mci.Identity())
 }
 
 // Set sets entity with given fields, key is the field name and value is the relevant value of the key
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
@@ -113,7 +114,7 @@ func TestMiddlewareClusterInfo_GetMiddlewareServerIDList(t *testing.T) {
 	asst := assert.New(t)
 
 	middlewareClusterInfo := initNewMiddlewareClusterInfo()
-	list, err := middlewareClusterInfo.GetMiddlewareServerIDList()
+	list, err := middlewareClusterInfo.GetMiddlewareServerIDList(context.Background())
 	count := len(list)
 	asst.Nil(err, common.CombineMessageWithError("test GetMiddlewareServerIDList() failed", err))
 	asst.Equal(middlewareServerIDListCount, count, "test GetMiddlewareServerIDList() failed")
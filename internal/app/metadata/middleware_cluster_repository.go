@@ -1,15 +1,19 @@
 package metadata
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
 	"github.com/romberli/log"
 
 	"github.com/romberli/das/global"
+	"github.com/romberli/das/pkg/tracing"
 )
 
 var _ metadata.MiddlewareClusterRepo = (*MiddlewareClusterRepo)(nil)
@@ -29,9 +33,11 @@ func NewMiddlewareClusterRepoWithGlobal() *MiddlewareClusterRepo {
 }
 
 // Execute executes command with arguments on database
-func (mcr *MiddlewareClusterRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+func (mcr *MiddlewareClusterRepo) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
 	conn, err := mcr.Database.Get()
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
 	defer func() {
@@ -41,7 +47,10 @@ func (mcr *MiddlewareClusterRepo) Execute(command string, args ...interface{}) (
 		}
 	}()
 
-	return conn.Execute(command, args...)
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
 }
 
 // Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
@@ -50,16 +59,16 @@ func (mcr *MiddlewareClusterRepo) Transaction() (middleware.Transaction, error)
 }
 
 // GetAll gets all middleware clusters from the middleware
-func (mcr *MiddlewareClusterRepo) GetAll() ([]metadata.MiddlewareCluster, error) {
+func (mcr *MiddlewareClusterRepo) GetAll(ctx context.Context) ([]metadata.MiddlewareCluster, error) {
 	sql := `
 		select id, cluster_name, owner_id, env_id, del_flag, create_time, last_update_time
 		from t_meta_middleware_cluster_info
 		where del_flag = 0
 		order by id;
 	`
-	log.Debugf("metadata MiddlewareClusterRepo.GetAll() sql: \n%s", sql)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareClusterRepo.GetAll() sql: \n%s", sql)
 
-	result, err := mcr.Execute(sql)
+	result, err := mcr.Execute(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -82,8 +91,34 @@ func (mcr *MiddlewareClusterRepo) GetAll() ([]metadata.MiddlewareCluster, error)
 	return entityList, nil
 }
 
+// GetByQuery gets the middleware clusters which match the query from the middleware,
+// it also returns the total number of the matched middleware clusters without pagination
+func (mcr *MiddlewareClusterRepo) GetByQuery(ctx context.Context, query *filter.Query) ([]metadata.MiddlewareCluster, int, error) {
+	result, total, err := getByQuery(ctx, mcr, "MiddlewareClusterRepo", middlewareClusterTable, middlewareClusterColumns, query)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []*MiddlewareClusterInfo
+	middlewareClusterInfoList := make([]*MiddlewareClusterInfo, result.RowNumber())
+	for i := range middlewareClusterInfoList {
+		middlewareClusterInfoList[i] = NewEmptyMiddlewareClusterInfoWithGlobal()
+	}
+	// map to struct
+	err = result.MapToStructSlice(middlewareClusterInfoList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []metadata.MiddlewareCluster
+	entityList := make([]metadata.MiddlewareCluster, result.RowNumber())
+	for i := range entityList {
+		entityList[i] = middlewareClusterInfoList[i]
+	}
+
+	return entityList, total, nil
+}
+
 // GetByEnv gets middleware clusters of given env id from the middleware
-func (mcr *MiddlewareClusterRepo) GetByEnv(envID int) ([]metadata.MiddlewareCluster, error) {
+func (mcr *MiddlewareClusterRepo) GetByEnv(ctx context.Context, envID int) ([]metadata.MiddlewareCluster, error) {
 	sql := `
 		select id, cluster_name, owner_id, env_id, del_flag, create_time, last_update_time
 		from t_meta_middleware_cluster_info
@@ -91,9 +126,9 @@ func (mcr *MiddlewareClusterRepo) GetByEnv(envID int) ([]metadata.MiddlewareClus
 		and env_id = ?
 		order by id;
 	`
-	log.Debugf("metadata MiddlewareClusterRepo.GetByEnv() sql: \n%s", sql, envID)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareClusterRepo.GetByEnv() sql: \n%s\nplaceholders: %d", sql, envID)
 
-	result, err := mcr.Execute(sql, envID)
+	result, err := mcr.Execute(ctx, sql, envID)
 	if err != nil {
 		return nil, err
 	}
@@ -117,22 +152,22 @@ func (mcr *MiddlewareClusterRepo) GetByEnv(envID int) ([]metadata.MiddlewareClus
 }
 
 // GetByID gets a middleware cluster by the identity from the middleware
-func (mcr *MiddlewareClusterRepo) GetByID(id int) (metadata.MiddlewareCluster, error) {
+func (mcr *MiddlewareClusterRepo) GetByID(ctx context.Context, id int) (metadata.MiddlewareCluster, error) {
 	sql := `
 		select id, cluster_name, owner_id, env_id, del_flag, create_time, last_update_time
 		from t_meta_middleware_cluster_info
 		where del_flag = 0
 		and id = ?;
 	`
-	log.Debugf("metadata MiddlewareClusterRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, id)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareClusterRepo.GetByID() sql: \n%s\nplaceholders: %d", sql, id)
 
-	result, err := mcr.Execute(sql, id)
+	result, err := mcr.Execute(ctx, sql, id)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata MiddlewareClusterInfo.GetByID(): data does not exists, id: %d", id))
+		return nil, fmt.Errorf("metadata MiddlewareClusterInfo.GetByID(): %w, id: %d", ErrDataNotExists, id)
 	case 1:
 		middlewareClusterInfo := NewEmptyMiddlewareClusterInfoWithGlobal()
 		// map to struct
@@ -148,10 +183,10 @@ func (mcr *MiddlewareClusterRepo) GetByID(id int) (metadata.MiddlewareCluster, e
 }
 
 // GetByName gets a middleware cluster of given cluster name from the middle ware
-func (mcr *MiddlewareClusterRepo) GetByName(clusterName string) (metadata.MiddlewareCluster, error) {
+func (mcr *MiddlewareClusterRepo) GetByName(ctx context.Context, clusterName string) (metadata.MiddlewareCluster, error) {
 	sql := `select id from t_meta_middleware_cluster_info where del_flag = 0 and cluster_name = ?;`
-	log.Debugf("metadata MiddlewareClusterRepo.GetByName() select sql: %s", sql)
-	result, err := mcr.Execute(sql, clusterName)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareClusterRepo.GetByName() select sql: %s", sql)
+	result, err := mcr.Execute(ctx, sql, clusterName)
 	if err != nil {
 		return nil, err
 	}
@@ -159,14 +194,14 @@ func (mcr *MiddlewareClusterRepo) GetByName(clusterName string) (metadata.Middle
 	if err != nil {
 		return nil, err
 	}
-	return mcr.GetByID(id)
+	return mcr.GetByID(ctx, id)
 }
 
 // GetID gets the identity with given cluster name and env id from the middleware
-func (mcr *MiddlewareClusterRepo) GetID(clusterName string, envID int) (int, error) {
+func (mcr *MiddlewareClusterRepo) GetID(ctx context.Context, clusterName string, envID int) (int, error) {
 	sql := `select id from t_meta_middleware_cluster_info where del_flag = 0 and cluster_name = ? and env_id = ?;`
-	log.Debugf("metadata MiddlewareClusterRepo.GetID() select sql: %s", sql)
-	result, err := mcr.Execute(sql, clusterName, envID)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareClusterRepo.GetID() select sql: %s", sql)
+	result, err := mcr.Execute(ctx, sql, clusterName, envID)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -175,14 +210,14 @@ func (mcr *MiddlewareClusterRepo) GetID(clusterName string, envID int) (int, err
 }
 
 // GetMiddlewareServerIDList gets the middleware server id list of given cluster id from the middle ware
-func (mcr *MiddlewareClusterRepo) GetMiddlewareServerIDList(clusterID int) ([]int, error) {
+func (mcr *MiddlewareClusterRepo) GetMiddlewareServerIDList(ctx context.Context, clusterID int) ([]int, error) {
 	sql := `select id from t_meta_middleware_server_info
             where del_flag = 0
             and cluster_id = ?
 		    order by id;
 	`
-	log.Debugf("metadata MiddlewareCLusterRepo.GetMiddlewareServerIDList() select sql: %s", sql)
-	result, err := mcr.Execute(sql, clusterID)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareCLusterRepo.GetMiddlewareServerIDList() select sql: %s", sql)
+	result, err := mcr.Execute(ctx, sql, clusterID)
 	if err != nil {
 		return nil, err
 	}
@@ -199,11 +234,11 @@ func (mcr *MiddlewareClusterRepo) GetMiddlewareServerIDList(clusterID int) ([]in
 }
 
 // Create creates data with given entity in the middleware
-func (mcr *MiddlewareClusterRepo) Create(middlewareCluster metadata.MiddlewareCluster) (metadata.MiddlewareCluster, error) {
+func (mcr *MiddlewareClusterRepo) Create(ctx context.Context, middlewareCluster metadata.MiddlewareCluster) (metadata.MiddlewareCluster, error) {
 	sql := `insert into t_meta_middleware_cluster_info(cluster_name, owner_id, env_id) values(?, ?, ?);`
-	log.Debugf("metadata MiddlewareClusterRepo.Create() insert sql: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareClusterRepo.Create() insert sql: %s", sql)
 	// execute
-	_, err := mcr.Execute(sql,
+	_, err := mcr.Execute(ctx, sql,
 		middlewareCluster.(*MiddlewareClusterInfo).ClusterName,
 		middlewareCluster.(*MiddlewareClusterInfo).OwnerID,
 		middlewareCluster.(*MiddlewareClusterInfo).EnvID,
@@ -212,52 +247,50 @@ func (mcr *MiddlewareClusterRepo) Create(middlewareCluster metadata.MiddlewareCl
 		return nil, err
 	}
 	// get id
-	id, err := mcr.GetID(middlewareCluster.GetClusterName(), middlewareCluster.GetEnvID())
+	id, err := mcr.GetID(ctx, middlewareCluster.GetClusterName(), middlewareCluster.GetEnvID())
 	if err != nil {
 		return nil, err
 	}
 	// get entity
-	return mcr.GetByID(id)
+	return mcr.GetByID(ctx, id)
 }
 
 // Update updates data with given entity in the middleware
-func (mcr *MiddlewareClusterRepo) Update(middlewareCluster metadata.MiddlewareCluster) error {
-	sql := `update t_meta_middleware_cluster_info set cluster_name = ?, owner_id = ?, env_id = ?, del_flag = ? where id = ?;`
-	log.Debugf("metadata MiddlewareClusterRepo.Update() update sql: %s", sql)
-	_, err := mcr.Execute(sql,
+// it returns a *ConflictError if the row was modified or deleted after the entity had been read
+func (mcr *MiddlewareClusterRepo) Update(ctx context.Context, middlewareCluster metadata.MiddlewareCluster) error {
+	sql := `update t_meta_middleware_cluster_info set cluster_name = ?, owner_id = ?, env_id = ?, del_flag = ? where id = ? and date_format(last_update_time, '%Y-%m-%d %H:%i:%s.%f') = ?;`
+	tracing.Logger(ctx).Debugf("metadata MiddlewareClusterRepo.Update() update sql: %s", sql)
+	result, err := mcr.Execute(ctx, sql,
 		middlewareCluster.GetClusterName(),
 		middlewareCluster.GetOwnerID(),
 		middlewareCluster.GetEnvID(),
 		middlewareCluster.GetDelFlag(),
 		middlewareCluster.Identity(),
+		getVersion(middlewareCluster.GetLastUpdateTime()),
 	)
-
-	return err
-}
-
-// Delete deletes the middleware cluster in the middleware
-func (mcr *MiddlewareClusterRepo) Delete(id int) error {
-	tx, err := mcr.Transaction()
 	if err != nil {
 		return err
 	}
-	defer func() {
-		err = tx.Close()
-		if err != nil {
-			log.Errorf("metadata MiddlewareClusterRepo.Delete(): close database connection failed.\n%s", err.Error())
-		}
-	}()
 
-	err = tx.Begin()
-	if err != nil {
-		return err
-	}
-	sql := `delete from t_meta_middleware_cluster_info where id = ?;`
-	log.Debugf("metadata MiddlewareClusterRepo.Delete() update sql: %s", sql)
-	_, err = mcr.Execute(sql, id)
-	if err != nil {
-		return err
-	}
+	return checkUpdated(ctx, mcr, "MiddlewareClusterRepo", middlewareClusterTable, middlewareCluster.Identity(), middlewareCluster.GetLastUpdateTime(), result)
+}
+
+// Delete soft deletes the middleware cluster in the middleware,
+// it returns a *BlockedError if the middleware cluster is still referenced by the other metadata,
+// and a *ConflictError if the row was modified after it had been read with given last update time
+func (mcr *MiddlewareClusterRepo) Delete(ctx context.Context, id int, lastUpdateTime time.Time) error {
+	_, err := deleteByID(ctx, mcr, "MiddlewareClusterRepo", middlewareClusterTable, id, lastUpdateTime, false)
+
+	return err
+}
+
+// DeleteCascade soft deletes the middleware cluster and the metadata which reference it in the middleware,
+// it returns the rows which are deleted or detached along with the middleware cluster
+func (mcr *MiddlewareClusterRepo) DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]metadata.CascadedRow, error) {
+	return deleteByID(ctx, mcr, "MiddlewareClusterRepo", middlewareClusterTable, id, lastUpdateTime, true)
+}
 
-	return tx.Commit()
+// Undelete restores the soft deleted middleware cluster in the middleware
+func (mcr *MiddlewareClusterRepo) Undelete(ctx context.Context, id int) error {
+	return undeleteByID(ctx, mcr, "MiddlewareClusterRepo", middlewareClusterTable, id)
 }
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"testing"
 
 	"github.com/romberli/das/internal/dependency/metadata"
@@ -40,7 +41,7 @@ func createMiddlewareCluster() (metadata.MiddlewareCluster, error) {
 		defaultMiddlewareClusterInfoOwnerID,
 		defaultMiddlewareClusterInfoEnvID,
 	)
-	entity, err := middlewareClusterRepo.Create(middlewareClusterInfo)
+	entity, err := middlewareClusterRepo.Create(context.Background(), middlewareClusterInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +51,7 @@ func createMiddlewareCluster() (metadata.MiddlewareCluster, error) {
 
 func deleteMiddlewareClusterByID(id int) error {
 	sql := `delete from t_meta_middleware_cluster_info where id = ?`
-	_, err := middlewareClusterRepo.Execute(sql, id)
+	_, err := middlewareClusterRepo.Execute(context.Background(), sql, id)
 	return err
 }
 
@@ -66,12 +67,13 @@ func TestMiddlewareClusterRepoAll(t *testing.T) {
 	TestMiddlewareClusterRepo_Create(t)
 	TestMiddlewareClusterRepo_Update(t)
 	TestMiddlewareClusterRepo_Delete(t)
+	TestMiddlewareClusterRepo_Undelete(t)
 }
 func TestMiddlewareClusterRepo_Execute(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := `select 1;`
-	result, err := middlewareClusterRepo.Execute(sql)
+	result, err := middlewareClusterRepo.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
 	r, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
@@ -86,11 +88,11 @@ func TestMiddlewareClusterRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	err = tx.Begin()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
-	_, err = tx.Execute(sql, defaultMiddlewareClusterInfoClusterName, defaultMiddlewareClusterInfoOwnerID, defaultMiddlewareClusterInfoEnvID)
+	_, err = tx.ExecuteContext(context.Background(), sql, defaultMiddlewareClusterInfoClusterName, defaultMiddlewareClusterInfoOwnerID, defaultMiddlewareClusterInfoEnvID)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if inserted
 	sql = `select cluster_name from t_meta_middleware_cluster_info where cluster_name=?`
-	result, err := tx.Execute(sql, defaultMiddlewareClusterInfoClusterName)
+	result, err := tx.ExecuteContext(context.Background(), sql, defaultMiddlewareClusterInfoClusterName)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	middlewareClusterName, err := result.GetString(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
@@ -100,7 +102,7 @@ func TestMiddlewareClusterRepo_Transaction(t *testing.T) {
 	err = tx.Rollback()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if rollbacked
-	entities, err := middlewareClusterRepo.GetAll()
+	entities, err := middlewareClusterRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	for _, entity := range entities {
 		middlewareClusterName := entity.GetClusterName()
@@ -114,7 +116,7 @@ func TestMiddlewareClusterRepo_Transaction(t *testing.T) {
 func TestMiddlewareClusterRepo_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
-	entities, err := middlewareClusterRepo.GetAll()
+	entities, err := middlewareClusterRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	middlewareClusterName := entities[0].GetClusterName()
 	asst.Equal(onlineMiddlewareClusterName, middlewareClusterName, "test GetAll() failed")
@@ -123,7 +125,7 @@ func TestMiddlewareClusterRepo_GetAll(t *testing.T) {
 func TestMiddlewareClusterRepo_GetByEnv(t *testing.T) {
 	asst := assert.New(t)
 
-	entities, err := middlewareClusterRepo.GetByEnv(1)
+	entities, err := middlewareClusterRepo.GetByEnv(context.Background(), 1)
 	asst.Nil(err, common.CombineMessageWithError("test GetByEnv() failed", err))
 	middlewareClusterName := entities[0].GetClusterName()
 	asst.Equal(onlineMiddlewareClusterName, middlewareClusterName, "test GetAll() failed")
@@ -132,7 +134,7 @@ func TestMiddlewareClusterRepo_GetByEnv(t *testing.T) {
 func TestMiddlewareClusterRepo_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := middlewareClusterRepo.GetByID(8)
+	entity, err := middlewareClusterRepo.GetByID(context.Background(), 8)
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	middlewareClusterName := entity.GetClusterName()
 	asst.Equal(onlineMiddlewareClusterName, middlewareClusterName, "test GetByID() failed")
@@ -141,7 +143,7 @@ func TestMiddlewareClusterRepo_GetByID(t *testing.T) {
 func TestMiddlewareClusterRepo_GetByName(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := middlewareClusterRepo.GetByName("test")
+	entity, err := middlewareClusterRepo.GetByName(context.Background(), "test")
 	asst.Nil(err, common.CombineMessageWithError("test GetByName() failed", err))
 	middlewareClusterName := entity.GetClusterName()
 	asst.Equal(onlineMiddlewareClusterName, middlewareClusterName, "test GetByID() failed")
@@ -150,7 +152,7 @@ func TestMiddlewareClusterRepo_GetByName(t *testing.T) {
 func TestMiddlewareClusterRepo_GetID(t *testing.T) {
 	asst := assert.New(t)
 
-	id, err := middlewareClusterRepo.GetID("test", 1)
+	id, err := middlewareClusterRepo.GetID(context.Background(), "test", 1)
 	asst.Nil(err, common.CombineMessageWithError("test GetID() failed", err))
 	asst.Equal(onlineMiddlewareClusterID, id, "test GetID() failed")
 }
@@ -160,7 +162,7 @@ func TestMiddlewareClusterRepo_GetMiddlewareServerIDList(t *testing.T) {
 
 	entity, err := createMiddlewareCluster()
 	asst.Nil(err, common.CombineMessageWithError("test GetMiddlewareServerIDList() failed", err))
-	middlewareServerIDList, err := middlewareClusterRepo.GetMiddlewareServerIDList(entity.Identity())
+	middlewareServerIDList, err := middlewareClusterRepo.GetMiddlewareServerIDList(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test GetMiddlewareServerIDList() failed", err))
 	asst.Equal(0, len(middlewareServerIDList), "test GetMiddlewareServerIDList() failed")
 	// delete
@@ -185,9 +187,9 @@ func TestMiddlewareClusterRepo_Update(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	err = entity.Set(map[string]interface{}{middlewareClusterNameStruct: newMiddlewareClusterName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = middlewareClusterRepo.Update(entity)
+	err = middlewareClusterRepo.Update(context.Background(), entity)
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	entity, err = middlewareClusterRepo.GetByID(entity.Identity())
+	entity, err = middlewareClusterRepo.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	middlewareClusterName := entity.GetClusterName()
 	asst.Equal(newMiddlewareClusterName, middlewareClusterName, "test Update() failed")
@@ -205,3 +207,21 @@ func TestMiddlewareClusterRepo_Delete(t *testing.T) {
 	err = deleteMiddlewareClusterByID(entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 }
+
+func TestMiddlewareClusterRepo_Undelete(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createMiddlewareCluster()
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	err = middlewareClusterRepo.Delete(context.Background(), entity.Identity(), entity.GetLastUpdateTime())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = middlewareClusterRepo.GetByID(context.Background(), entity.Identity())
+	asst.NotNil(err, "test Undelete() failed")
+	err = middlewareClusterRepo.Undelete(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = middlewareClusterRepo.GetByID(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	// delete
+	err = deleteMiddlewareClusterByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+}
@@ -1,12 +1,18 @@
 package metadata
 
 import (
+	"context"
+	"encoding/json"
+
 	"fmt"
 
+	"github.com/romberli/das/internal/app/audit"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
 	"github.com/romberli/das/internal/dependency/metadata"
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 )
 
@@ -18,16 +24,33 @@ type MiddlewareClusterService struct {
 	metadata.MiddlewareClusterRepo
 	MiddlewareClusters   []metadata.MiddlewareCluster `json:"middleware_clusters"`
 	MiddlewareServerList []int                        `json:"middleware_server_list"`
+	Page                 *filter.Page                 `json:"page"`
+
+	auditor depaudit.Auditor
+	ifMatch string
 }
 
 // NewMiddlewareClusterService returns a new *MiddlewareClusterService
 func NewMiddlewareClusterService(repo metadata.MiddlewareClusterRepo) *MiddlewareClusterService {
-	return &MiddlewareClusterService{repo, []metadata.MiddlewareCluster{}, []int{}}
+	return &MiddlewareClusterService{repo, []metadata.MiddlewareCluster{}, []int{}, nil, nil, constant.EmptyString}
 }
 
 // NewMiddlewareClusterServiceWithDefault returns a new *MiddlewareClusterService with default repository
 func NewMiddlewareClusterServiceWithDefault() *MiddlewareClusterService {
-	return NewMiddlewareClusterService(NewMiddlewareClusterRepoWithGlobal())
+	s := NewMiddlewareClusterService(NewMiddlewareClusterRepoWithGlobal())
+	s.SetAuditor(audit.NewAuditorWithGlobal(audit.DefaultActor, constant.EmptyString))
+
+	return s
+}
+
+// SetAuditor sets the auditor which records the changes of the middleware clusters
+func (mcs *MiddlewareClusterService) SetAuditor(auditor depaudit.Auditor) {
+	mcs.auditor = auditor
+}
+
+// SetIfMatch sets the entity tags which the middleware cluster to update or delete must match, it is not checked if ifMatch is empty
+func (mcs *MiddlewareClusterService) SetIfMatch(ifMatch string) {
+	mcs.ifMatch = ifMatch
 }
 
 // GetEntities returns entities of the service
@@ -36,23 +59,40 @@ func (mcs *MiddlewareClusterService) GetMiddlewareClusters() []metadata.Middlewa
 }
 
 // GetAll gets all middleware cluster entities from the middleware
-func (mcs *MiddlewareClusterService) GetAll() error {
+func (mcs *MiddlewareClusterService) GetAll(ctx context.Context) error {
 	var err error
-	mcs.MiddlewareClusters, err = mcs.MiddlewareClusterRepo.GetAll()
+	mcs.MiddlewareClusters, err = mcs.MiddlewareClusterRepo.GetAll(ctx)
 
 	return err
 }
 
+// GetByQuery gets the middleware clusters which match the query from the middleware, and the pagination information
+func (mcs *MiddlewareClusterService) GetByQuery(ctx context.Context, query *filter.Query) error {
+	middlewareClusters, total, err := mcs.MiddlewareClusterRepo.GetByQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	mcs.MiddlewareClusters = middlewareClusters
+	ids := make([]int, len(middlewareClusters))
+	for i, middlewareCluster := range middlewareClusters {
+		ids[i] = middlewareCluster.Identity()
+	}
+	mcs.Page = query.GetPage(total, ids)
+
+	return nil
+}
+
 // GetByEnv gets middleware clusters of given env id
-func (mcs *MiddlewareClusterService) GetByEnv(envID int) error {
+func (mcs *MiddlewareClusterService) GetByEnv(ctx context.Context, envID int) error {
 	var err error
-	mcs.MiddlewareClusters, err = mcs.MiddlewareClusterRepo.GetByEnv(envID)
+	mcs.MiddlewareClusters, err = mcs.MiddlewareClusterRepo.GetByEnv(ctx, envID)
 	return err
 }
 
 // GetByID gets an middleware cluster entity that contains the given id from the middleware
-func (mcs *MiddlewareClusterService) GetByID(id int) error {
-	middlewareCluster, err := mcs.MiddlewareClusterRepo.GetByID(id)
+func (mcs *MiddlewareClusterService) GetByID(ctx context.Context, id int) error {
+	middlewareCluster, err := mcs.MiddlewareClusterRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -63,8 +103,8 @@ func (mcs *MiddlewareClusterService) GetByID(id int) error {
 }
 
 // GetByName gets a middleware cluster of given cluster name
-func (mcs *MiddlewareClusterService) GetByName(clusterName string) error {
-	middlewareCluster, err := mcs.MiddlewareClusterRepo.GetByName(clusterName)
+func (mcs *MiddlewareClusterService) GetByName(ctx context.Context, clusterName string) error {
+	middlewareCluster, err := mcs.MiddlewareClusterRepo.GetByName(ctx, clusterName)
 	if err != nil {
 		return err
 	}
@@ -73,17 +113,17 @@ func (mcs *MiddlewareClusterService) GetByName(clusterName string) error {
 }
 
 // GetMiddlewareServerIDList gets the middleware server id list of given cluster id
-func (mcs *MiddlewareClusterService) GetMiddlewareServerIDList(clusterID int) ([]int, error) {
-	middlewareCluster, err := mcs.MiddlewareClusterRepo.GetByID(clusterID)
+func (mcs *MiddlewareClusterService) GetMiddlewareServerIDList(ctx context.Context, clusterID int) ([]int, error) {
+	middlewareCluster, err := mcs.MiddlewareClusterRepo.GetByID(ctx, clusterID)
 	if err != nil {
 		return nil, err
 	}
-	mcs.MiddlewareServerList, err = middlewareCluster.GetMiddlewareServerIDList()
+	mcs.MiddlewareServerList, err = middlewareCluster.GetMiddlewareServerIDList(ctx)
 	return mcs.MiddlewareServerList, err
 }
 
 // Create creates a new middleware cluster entity and insert it into the middleware
-func (mcs *MiddlewareClusterService) Create(fields map[string]interface{}) error {
+func (mcs *MiddlewareClusterService) Create(ctx context.Context, fields map[string]interface{}) error {
 	// generate new map
 	_, clusterNameExists := fields[middlewareClusterNameStruct]
 	_, envIDExists := fields[middlewareClusterEnvIDStruct]
@@ -96,11 +136,13 @@ func (mcs *MiddlewareClusterService) Create(fields map[string]interface{}) error
 		return err
 	}
 	// insert into middleware
-	middlewareCluster, err := mcs.MiddlewareClusterRepo.Create(middlewareClusterInfo)
+	middlewareCluster, err := mcs.MiddlewareClusterRepo.Create(ctx, middlewareClusterInfo)
 	if err != nil {
 		return err
 	}
 	mcs.MiddlewareClusters = append(mcs.MiddlewareClusters, middlewareCluster)
+	record(ctx, mcs.auditor, audit.EntityTypeMiddlewareCluster, middlewareCluster.Identity(), audit.ActionCreate, nil, middlewareCluster)
+
 	return nil
 }
 
@@ -108,8 +150,16 @@ func (mcs *MiddlewareClusterService) Create(fields map[string]interface{}) error
 // and then update its fields that was specified in fields argument,
 // key is the filed name and value is the new field value,
 // it saves the changes to the middleware
-func (mcs *MiddlewareClusterService) Update(id int, fields map[string]interface{}) error {
-	err := mcs.GetByID(id)
+func (mcs *MiddlewareClusterService) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	err := mcs.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mcs.ifMatch, middlewareClusterTable, id, mcs.MiddlewareClusters[constant.ZeroInt].GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	before, err := mcs.MiddlewareClusters[constant.ZeroInt].MarshalJSON()
 	if err != nil {
 		return err
 	}
@@ -117,13 +167,68 @@ func (mcs *MiddlewareClusterService) Update(id int, fields map[string]interface{
 	if err != nil {
 		return err
 	}
+	err = mcs.MiddlewareClusterRepo.Update(ctx, mcs.MiddlewareClusters[constant.ZeroInt])
+	if err != nil {
+		return err
+	}
+	record(ctx, mcs.auditor, audit.EntityTypeMiddlewareCluster, id, audit.ActionUpdate, json.RawMessage(before), mcs.MiddlewareClusters[constant.ZeroInt])
 
-	return mcs.MiddlewareClusterRepo.Update(mcs.MiddlewareClusters[constant.ZeroInt])
+	return nil
 }
 
 // Delete deletes the middleware cluster entity that contains the given id in the middleware
-func (mcs *MiddlewareClusterService) Delete(id int) error {
-	return mcs.MiddlewareClusterRepo.Delete(id)
+func (mcs *MiddlewareClusterService) Delete(ctx context.Context, id int) error {
+	before, err := mcs.MiddlewareClusterRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mcs.ifMatch, middlewareClusterTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	err = mcs.MiddlewareClusterRepo.Delete(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, mcs.auditor, audit.EntityTypeMiddlewareCluster, id, audit.ActionDelete, before, nil)
+
+	return nil
+}
+
+// DeleteCascade deletes the middleware cluster of given id and the metadata which reference it in the middleware
+func (mcs *MiddlewareClusterService) DeleteCascade(ctx context.Context, id int) error {
+	before, err := mcs.MiddlewareClusterRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mcs.ifMatch, middlewareClusterTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	rows, err := mcs.MiddlewareClusterRepo.DeleteCascade(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, mcs.auditor, audit.EntityTypeMiddlewareCluster, id, audit.ActionDeleteCascade, before, nil)
+	recordCascaded(ctx, mcs.auditor, rows)
+
+	return nil
+}
+
+// Undelete restores the deleted middleware cluster of given id in the middleware, and then gets it
+func (mcs *MiddlewareClusterService) Undelete(ctx context.Context, id int) error {
+	err := mcs.MiddlewareClusterRepo.Undelete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = mcs.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	record(ctx, mcs.auditor, audit.EntityTypeMiddlewareCluster, id, audit.ActionUndelete, nil, mcs.MiddlewareClusters[constant.ZeroInt])
+
+	return nil
 }
 
 // Marshal marshals service.Envs
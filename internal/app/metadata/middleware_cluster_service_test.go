@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -28,7 +29,7 @@ func TestMiddlewareClusterService_GetMiddlewareClusters(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareClusterService(middlewareClusterRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetMiddlewareClusters() failed")
 	entities := s.GetMiddlewareClusters()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetMiddlewareClusters() failed")
@@ -38,7 +39,7 @@ func TestMiddlewareClusterService_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareClusterService(middlewareClusterRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetAll() failed")
 	entities := s.GetMiddlewareClusters()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetAll() failed")
@@ -48,7 +49,7 @@ func TestMiddlewareClusterService_GetByEnv(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareClusterService(middlewareClusterRepo)
-	err := s.GetByEnv(1)
+	err := s.GetByEnv(context.Background(), 1)
 	asst.Nil(err, "test GetByEnv() failed")
 	envID := s.MiddlewareClusters[constant.ZeroInt].GetEnvID()
 	asst.Equal(1, envID, "test GetByEnvID() failed")
@@ -58,7 +59,7 @@ func TestMiddlewareClusterService_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareClusterService(middlewareClusterRepo)
-	err := s.GetByID(8)
+	err := s.GetByID(context.Background(), 8)
 	asst.Nil(err, "test GetByID() failed")
 	id := s.MiddlewareClusters[constant.ZeroInt].Identity()
 	asst.Equal(8, id, "test GetByID() failed")
@@ -68,7 +69,7 @@ func TestMiddlewareClusterService_GetByName(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareClusterService(middlewareClusterRepo)
-	err := s.GetByName("test")
+	err := s.GetByName(context.Background(), "test")
 	asst.Nil(err, "test GetByName() failed")
 	clusterName := s.MiddlewareClusters[constant.ZeroInt].GetClusterName()
 	asst.Equal("test", clusterName, "test GetByName() failed")
@@ -78,7 +79,7 @@ func TestMiddlewareClusterService_GetMiddlewareServerIDList(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareClusterService(middlewareClusterRepo)
-	_, err := s.GetMiddlewareServerIDList(13)
+	_, err := s.GetMiddlewareServerIDList(context.Background(), 13)
 	asst.Nil(err, "test GetMiddlewareServerIDList failed")
 	middlewareServerList := s.MiddlewareServerList
 	asst.Equal(2, len(middlewareServerList), "test GetMiddlewareServerIDList failed")
@@ -88,7 +89,7 @@ func TestMiddlewareClusterService_Create(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareClusterService(middlewareClusterRepo)
-	err := s.Create(map[string]interface{}{
+	err := s.Create(context.Background(), map[string]interface{}{
 		middlewareClusterNameStruct:    defaultMiddlewareClusterInfoClusterName,
 		middlewareClusterOwnerIDStruct: defaultMiddlewareClusterInfoOwnerID,
 		middlewareClusterEnvIDStruct:   defaultMiddlewareClusterInfoEnvID,
@@ -105,11 +106,11 @@ func TestMiddlewareClusterService_Update(t *testing.T) {
 	entity, err := createMiddlewareCluster()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewMiddlewareClusterService(middlewareClusterRepo)
-	err = s.Update(entity.Identity(), map[string]interface{}{
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{
 		middlewareClusterNameStruct: newMiddlewareClusterName,
 	})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	middlewareClusterName := s.GetMiddlewareClusters()[constant.ZeroInt].GetClusterName()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
@@ -125,7 +126,7 @@ func TestMiddlewareClusterService_Delete(t *testing.T) {
 	entity, err := createMiddlewareCluster()
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	s := NewMiddlewareClusterService(middlewareClusterRepo)
-	err = s.Delete(entity.Identity())
+	err = s.Delete(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	// delete
 	err = deleteMiddlewareClusterByID(entity.Identity())
@@ -138,7 +139,7 @@ func TestMiddlewareClusterService_Marshal(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareClusterService(middlewareClusterRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
 	data, err := s.Marshal()
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
@@ -158,7 +159,7 @@ func TestMiddlewareClusterService_MarshalWithFields(t *testing.T) {
 	entity, err := createMiddlewareCluster()
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	s := NewMiddlewareClusterService(middlewareClusterRepo)
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	dataService, err := s.MarshalWithFields(middlewareClusterNameStruct)
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	dataEntity, err := entity.MarshalJSONWithFields(middlewareClusterNameStruct)
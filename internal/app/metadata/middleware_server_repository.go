@@ -1,15 +1,19 @@
 package metadata
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
 	"github.com/romberli/log"
 
 	"github.com/romberli/das/global"
+	"github.com/romberli/das/pkg/tracing"
 )
 
 var _ metadata.MiddlewareServerRepo = (*MiddlewareServerRepo)(nil)
@@ -30,9 +34,11 @@ func NewMiddlewareServerRepoWithGlobal() *MiddlewareServerRepo {
 
 // Execute implements dependency.Repository interface,
 // it executes command with arguments on database
-func (msr *MiddlewareServerRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+func (msr *MiddlewareServerRepo) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
 	conn, err := msr.Database.Get()
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
 	defer func() {
@@ -42,7 +48,10 @@ func (msr *MiddlewareServerRepo) Execute(command string, args ...interface{}) (m
 		}
 	}()
 
-	return conn.Execute(command, args...)
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
 }
 
 // Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
@@ -51,16 +60,16 @@ func (msr *MiddlewareServerRepo) Transaction() (middleware.Transaction, error) {
 }
 
 // GetAll returns all available entities
-func (msr *MiddlewareServerRepo) GetAll() ([]metadata.MiddlewareServer, error) {
+func (msr *MiddlewareServerRepo) GetAll(ctx context.Context) ([]metadata.MiddlewareServer, error) {
 	sql := `
       select id, cluster_id, server_name, middleware_role, host_ip, port_num, del_flag, create_time, last_update_time
       from t_meta_middleware_server_info
       where del_flag = 0
       order by id;
    `
-	log.Debugf("metadata MiddlewareServerRepo.GetAll() sql: \n%s", sql)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareServerRepo.GetAll() sql: \n%s", sql)
 
-	result, err := msr.Execute(sql)
+	result, err := msr.Execute(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -83,8 +92,34 @@ func (msr *MiddlewareServerRepo) GetAll() ([]metadata.MiddlewareServer, error) {
 	return entityList, nil
 }
 
+// GetByQuery gets the middleware servers which match the query from the middleware,
+// it also returns the total number of the matched middleware servers without pagination
+func (msr *MiddlewareServerRepo) GetByQuery(ctx context.Context, query *filter.Query) ([]metadata.MiddlewareServer, int, error) {
+	result, total, err := getByQuery(ctx, msr, "MiddlewareServerRepo", middlewareServerTable, middlewareServerColumns, query)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []*MiddlewareServerInfo
+	middlewareServerInfoList := make([]*MiddlewareServerInfo, result.RowNumber())
+	for i := range middlewareServerInfoList {
+		middlewareServerInfoList[i] = NewEmptyMiddlewareServerInfoWithGlobal()
+	}
+	// map to struct
+	err = result.MapToStructSlice(middlewareServerInfoList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []metadata.MiddlewareServer
+	entityList := make([]metadata.MiddlewareServer, result.RowNumber())
+	for i := range entityList {
+		entityList[i] = middlewareServerInfoList[i]
+	}
+
+	return entityList, total, nil
+}
+
 // GetByClusterID gets middleware servers with given cluster id
-func (msr *MiddlewareServerRepo) GetByClusterID(clusterID int) ([]metadata.MiddlewareServer, error) {
+func (msr *MiddlewareServerRepo) GetByClusterID(ctx context.Context, clusterID int) ([]metadata.MiddlewareServer, error) {
 	sql := `
 		select id, cluster_id, server_name, middleware_role, host_ip, port_num, del_flag, create_time, last_update_time
 		from t_meta_middleware_server_info
@@ -92,9 +127,9 @@ func (msr *MiddlewareServerRepo) GetByClusterID(clusterID int) ([]metadata.Middl
 		and cluster_id = ?
 		order by id;
 	`
-	log.Debugf("metadata MiddlewareServerRepo.GetByClusterID() sql: \n%s", sql, clusterID)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareServerRepo.GetByClusterID() sql: \n%s\nplaceholders: %d", sql, clusterID)
 
-	result, err := msr.Execute(sql, clusterID)
+	result, err := msr.Execute(ctx, sql, clusterID)
 	if err != nil {
 		return nil, err
 	}
@@ -117,22 +152,22 @@ func (msr *MiddlewareServerRepo) GetByClusterID(clusterID int) ([]metadata.Middl
 	return middlewareServerList, nil
 }
 
-func (msr *MiddlewareServerRepo) GetByID(id int) (metadata.MiddlewareServer, error) {
+func (msr *MiddlewareServerRepo) GetByID(ctx context.Context, id int) (metadata.MiddlewareServer, error) {
 	sql := `
       select id, cluster_id, server_name, middleware_role, host_ip, port_num, del_flag, create_time, last_update_time
       from t_meta_middleware_server_info
       where del_flag = 0
       and id = ?;
    `
-	log.Debugf("metadata MiddlewareServerRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, id)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareServerRepo.GetByID() sql: \n%s\nplaceholders: %d", sql, id)
 
-	result, err := msr.Execute(sql, id)
+	result, err := msr.Execute(ctx, sql, id)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata MiddlewareServerInfo.GetByID(): data does not exists, id: %d", id))
+		return nil, fmt.Errorf("metadata MiddlewareServerInfo.GetByID(): %w, id: %d", ErrDataNotExists, id)
 	case 1:
 		middlewareServerInfo := NewEmptyMiddlewareServerInfoWithGlobal()
 		// map to struct
@@ -148,15 +183,15 @@ func (msr *MiddlewareServerRepo) GetByID(id int) (metadata.MiddlewareServer, err
 }
 
 // GetByHostInfo gets a middleware server with given host ip and port number
-func (msr *MiddlewareServerRepo) GetByHostInfo(hostIP string, portNum int) (metadata.MiddlewareServer, error) {
+func (msr *MiddlewareServerRepo) GetByHostInfo(ctx context.Context, hostIP string, portNum int) (metadata.MiddlewareServer, error) {
 	sql := `
       select id from t_meta_middleware_server_info
       where del_flag = 0
       and host_ip = ? and port_num = ?;
    `
-	log.Debugf("metadata MiddlewareServerRepo.GetByHostInfo() sql: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareServerRepo.GetByHostInfo() sql: %s", sql)
 
-	result, err := msr.Execute(sql, hostIP, portNum)
+	result, err := msr.Execute(ctx, sql, hostIP, portNum)
 	if err != nil {
 		return nil, err
 	}
@@ -164,14 +199,14 @@ func (msr *MiddlewareServerRepo) GetByHostInfo(hostIP string, portNum int) (meta
 	if err != nil {
 		return nil, err
 	}
-	return msr.GetByID(id)
+	return msr.GetByID(ctx, id)
 }
 
 // GetID checks identity of given entity from the middleware
-func (msr *MiddlewareServerRepo) GetID(hostIP string, portNum int) (int, error) {
+func (msr *MiddlewareServerRepo) GetID(ctx context.Context, hostIP string, portNum int) (int, error) {
 	sql := `select id from t_meta_middleware_server_info where del_flag = 0 and host_ip = ? and port_num = ?;`
-	log.Debugf("metadata MiddlewareServerRepo.GetID() select sql: %s", sql)
-	result, err := msr.Execute(sql, hostIP, portNum)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareServerRepo.GetID() select sql: %s", sql)
+	result, err := msr.Execute(ctx, sql, hostIP, portNum)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -180,11 +215,11 @@ func (msr *MiddlewareServerRepo) GetID(hostIP string, portNum int) (int, error)
 }
 
 // Create creates data with given entity in the middleware
-func (msr *MiddlewareServerRepo) Create(middlewareServer metadata.MiddlewareServer) (metadata.MiddlewareServer, error) {
+func (msr *MiddlewareServerRepo) Create(ctx context.Context, middlewareServer metadata.MiddlewareServer) (metadata.MiddlewareServer, error) {
 	sql := `insert into t_meta_middleware_server_info(cluster_id, server_name, middleware_role, host_ip, port_num) values(?, ?, ?, ?, ?);`
-	log.Debugf("metadata MiddlewareServerRepo.Create() insert sql: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata MiddlewareServerRepo.Create() insert sql: %s", sql)
 	// execute
-	_, err := msr.Execute(sql,
+	_, err := msr.Execute(ctx, sql,
 		middlewareServer.(*MiddlewareServerInfo).ClusterID,
 		middlewareServer.(*MiddlewareServerInfo).ServerName,
 		middlewareServer.(*MiddlewareServerInfo).MiddlewareRole,
@@ -195,19 +230,20 @@ func (msr *MiddlewareServerRepo) Create(middlewareServer metadata.MiddlewareServ
 		return nil, err
 	}
 	// get id
-	id, err := msr.GetID(middlewareServer.GetHostIP(), middlewareServer.GetPortNum())
+	id, err := msr.GetID(ctx, middlewareServer.GetHostIP(), middlewareServer.GetPortNum())
 	if err != nil {
 		return nil, err
 	}
 	// get entity
-	return msr.GetByID(id)
+	return msr.GetByID(ctx, id)
 }
 
 // Update updates data with given entity in the middleware
-func (msr *MiddlewareServerRepo) Update(middlewareServer metadata.MiddlewareServer) error {
-	sql := `update t_meta_middleware_server_info set cluster_id = ?, server_name = ?, middleware_role = ?, host_ip = ?, port_num = ?, del_flag = ? where id = ?;`
-	log.Debugf("metadata MiddlewareServerRepo.Update() update sql: %s", sql)
-	_, err := msr.Execute(sql,
+// it returns a *ConflictError if the row was modified or deleted after the entity had been read
+func (msr *MiddlewareServerRepo) Update(ctx context.Context, middlewareServer metadata.MiddlewareServer) error {
+	sql := `update t_meta_middleware_server_info set cluster_id = ?, server_name = ?, middleware_role = ?, host_ip = ?, port_num = ?, del_flag = ? where id = ? and date_format(last_update_time, '%Y-%m-%d %H:%i:%s.%f') = ?;`
+	tracing.Logger(ctx).Debugf("metadata MiddlewareServerRepo.Update() update sql: %s", sql)
+	result, err := msr.Execute(ctx, sql,
 		middlewareServer.GetClusterID(),
 		middlewareServer.GetServerName(),
 		middlewareServer.GetMiddlewareRole(),
@@ -215,34 +251,31 @@ func (msr *MiddlewareServerRepo) Update(middlewareServer metadata.MiddlewareServ
 		middlewareServer.GetPortNum(),
 		middlewareServer.GetDelFlag(),
 		middlewareServer.Identity(),
+		getVersion(middlewareServer.GetLastUpdateTime()),
 	)
-
-	return err
-}
-
-// Delete deletes the middleware server in the middleware
-func (msr *MiddlewareServerRepo) Delete(id int) error {
-	tx, err := msr.Transaction()
 	if err != nil {
 		return err
 	}
-	defer func() {
-		err = tx.Close()
-		if err != nil {
-			log.Errorf("metadata MiddlewareServerRepo.Delete(): close database connection failed.\n%s", err.Error())
-		}
-	}()
 
-	err = tx.Begin()
-	if err != nil {
-		return err
-	}
-	sql := `delete from t_meta_middleware_server_info where id = ?;`
-	log.Debugf("metadata MiddlewareServerRepo.Delete() update sql: %s", sql)
-	_, err = msr.Execute(sql, id)
-	if err != nil {
-		return err
-	}
+	return checkUpdated(ctx, msr, "MiddlewareServerRepo", middlewareServerTable, middlewareServer.Identity(), middlewareServer.GetLastUpdateTime(), result)
+}
+
+// Delete soft deletes the middleware server in the middleware,
+// it returns a *BlockedError if the middleware server is still referenced by the other metadata,
+// and a *ConflictError if the row was modified after it had been read with given last update time
+func (msr *MiddlewareServerRepo) Delete(ctx context.Context, id int, lastUpdateTime time.Time) error {
+	_, err := deleteByID(ctx, msr, "MiddlewareServerRepo", middlewareServerTable, id, lastUpdateTime, false)
+
+	return err
+}
+
+// DeleteCascade soft deletes the middleware server and the metadata which reference it in the middleware,
+// it returns the rows which are deleted or detached along with the middleware server
+func (msr *MiddlewareServerRepo) DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]metadata.CascadedRow, error) {
+	return deleteByID(ctx, msr, "MiddlewareServerRepo", middlewareServerTable, id, lastUpdateTime, true)
+}
 
-	return tx.Commit()
+// Undelete restores the soft deleted middleware server in the middleware
+func (msr *MiddlewareServerRepo) Undelete(ctx context.Context, id int) error {
+	return undeleteByID(ctx, msr, "MiddlewareServerRepo", middlewareServerTable, id)
 }
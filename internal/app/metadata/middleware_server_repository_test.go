@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"testing"
 
 	"github.com/romberli/das/internal/dependency/metadata"
@@ -42,7 +43,7 @@ func createMiddlewareServer() (metadata.MiddlewareServer, error) {
 		defaultMiddlewareServerInfoHostIP,
 		defaultMiddlewareServerInfoPortNum,
 	)
-	entity, err := middlewareServerRepo.Create(middlewareServerInfo)
+	entity, err := middlewareServerRepo.Create(context.Background(), middlewareServerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +53,7 @@ func createMiddlewareServer() (metadata.MiddlewareServer, error) {
 
 func deleteMiddlewareServerByID(id int) error {
 	sql := `delete from t_meta_middleware_server_info where id = ?`
-	_, err := middlewareServerRepo.Execute(sql, id)
+	_, err := middlewareServerRepo.Execute(context.Background(), sql, id)
 	return err
 }
 
@@ -67,12 +68,13 @@ func TestMiddlewareServerRepoAll(t *testing.T) {
 	TestMiddlewareServerRepo_Create(t)
 	TestMiddlewareServerRepo_Update(t)
 	TestMiddlewareServerRepo_Delete(t)
+	TestMiddlewareServerRepo_Undelete(t)
 }
 func TestMiddlewareServerRepo_Execute(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := `select 1;`
-	result, err := middlewareServerRepo.Execute(sql)
+	result, err := middlewareServerRepo.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
 	r, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
@@ -87,11 +89,11 @@ func TestMiddlewareServerRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	err = tx.Begin()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
-	_, err = tx.Execute(sql, defaultMiddlewareServerInfoClusterID, defaultMiddlewareServerInfoServerName, defaultMiddlewareServerInfoMiddlewareRole, defaultMiddlewareServerInfoHostIP, defaultMiddlewareServerInfoPortNum)
+	_, err = tx.ExecuteContext(context.Background(), sql, defaultMiddlewareServerInfoClusterID, defaultMiddlewareServerInfoServerName, defaultMiddlewareServerInfoMiddlewareRole, defaultMiddlewareServerInfoHostIP, defaultMiddlewareServerInfoPortNum)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if inserted
 	sql = `select server_name from t_meta_middleware_server_info where server_name=?`
-	result, err := tx.Execute(sql, defaultMiddlewareServerInfoServerName)
+	result, err := tx.ExecuteContext(context.Background(), sql, defaultMiddlewareServerInfoServerName)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	middlewareServerName, err := result.GetString(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
@@ -101,7 +103,7 @@ func TestMiddlewareServerRepo_Transaction(t *testing.T) {
 	err = tx.Rollback()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if rollbacked
-	entities, err := middlewareServerRepo.GetAll()
+	entities, err := middlewareServerRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	for _, entity := range entities {
 		middlewareServerName := entity.GetServerName()
@@ -116,7 +118,7 @@ func TestMiddlewareServerRepo_Transaction(t *testing.T) {
 func TestMiddlewareServerRepo_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
-	entities, err := middlewareServerRepo.GetAll()
+	entities, err := middlewareServerRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	middlewareServerName := entities[0].GetServerName()
 	asst.Equal(onlineMiddlewareServerName, middlewareServerName, "test GetAll() failed")
@@ -125,7 +127,7 @@ func TestMiddlewareServerRepo_GetAll(t *testing.T) {
 func TestMiddlewareServerRepo_GetByClusterID(t *testing.T) {
 	asst := assert.New(t)
 
-	entities, err := middlewareServerRepo.GetByClusterID(13)
+	entities, err := middlewareServerRepo.GetByClusterID(context.Background(), 13)
 	asst.Nil(err, common.CombineMessageWithError("test GetByClusterID failed", err))
 	middlewareServerName := entities[0].GetServerName()
 	asst.Equal(onlineMiddlewareServerName, middlewareServerName, "test GetByClusterID failed")
@@ -134,7 +136,7 @@ func TestMiddlewareServerRepo_GetByClusterID(t *testing.T) {
 func TestMiddlewareServerRepo_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := middlewareServerRepo.GetByID(1)
+	entity, err := middlewareServerRepo.GetByID(context.Background(), 1)
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	middlewareServerName := entity.GetServerName()
 	asst.Equal(onlineMiddlewareServerName, middlewareServerName, "test GetByID() failed")
@@ -143,7 +145,7 @@ func TestMiddlewareServerRepo_GetByID(t *testing.T) {
 func TestMiddlewareServerRepo_GetByHostInfo(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := middlewareServerRepo.GetByHostInfo("1", 1)
+	entity, err := middlewareServerRepo.GetByHostInfo(context.Background(), "1", 1)
 	asst.Nil(err, common.CombineMessageWithError("test GetByHostInfo() failed", err))
 	middlewareServerName := entity.GetServerName()
 	asst.Equal(onlineMiddlewareServerName, middlewareServerName, "test GetByHostInfo() failed")
@@ -152,7 +154,7 @@ func TestMiddlewareServerRepo_GetByHostInfo(t *testing.T) {
 func TestMiddlewareServerRepo_GetID(t *testing.T) {
 	asst := assert.New(t)
 
-	id, err := middlewareServerRepo.GetID("1", 1)
+	id, err := middlewareServerRepo.GetID(context.Background(), "1", 1)
 	asst.Nil(err, common.CombineMessageWithError("test GetID() failed", err))
 	asst.Equal(onlineMiddlewareServerID, id, "")
 }
@@ -174,9 +176,9 @@ func TestMiddlewareServerRepo_Update(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	err = entity.Set(map[string]interface{}{middlewareServerNameStruct: newMiddlewareServerName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = middlewareServerRepo.Update(entity)
+	err = middlewareServerRepo.Update(context.Background(), entity)
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	entity, err = middlewareServerRepo.GetByID(entity.Identity())
+	entity, err = middlewareServerRepo.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	middlewareServerName := entity.GetServerName()
 
@@ -195,3 +197,21 @@ func TestMiddlewareServerRepo_Delete(t *testing.T) {
 	err = deleteMiddlewareServerByID(entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 }
+
+func TestMiddlewareServerRepo_Undelete(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createMiddlewareServer()
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	err = middlewareServerRepo.Delete(context.Background(), entity.Identity(), entity.GetLastUpdateTime())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = middlewareServerRepo.GetByID(context.Background(), entity.Identity())
+	asst.NotNil(err, "test Undelete() failed")
+	err = middlewareServerRepo.Undelete(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = middlewareServerRepo.GetByID(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	// delete
+	err = deleteMiddlewareServerByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+}
@@ -1,12 +1,18 @@
 package metadata
 
 import (
+	"context"
+	"encoding/json"
+
 	"fmt"
 
+	"github.com/romberli/das/internal/app/audit"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
 	"github.com/romberli/das/internal/dependency/metadata"
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 )
 
@@ -17,16 +23,33 @@ var _ metadata.MiddlewareServerService = (*MiddlewareServerService)(nil)
 type MiddlewareServerService struct {
 	metadata.MiddlewareServerRepo
 	MiddlewareServers []metadata.MiddlewareServer `json:"middleware_servers"`
+	Page              *filter.Page                `json:"page"`
+
+	auditor depaudit.Auditor
+	ifMatch string
 }
 
 // NewMiddlewareServerService returns a new *MiddlewareServerService
 func NewMiddlewareServerService(repo metadata.MiddlewareServerRepo) *MiddlewareServerService {
-	return &MiddlewareServerService{repo, []metadata.MiddlewareServer{}}
+	return &MiddlewareServerService{repo, []metadata.MiddlewareServer{}, nil, nil, constant.EmptyString}
 }
 
 // NewMiddlewareServerServiceWithDefault returns a new *MiddlewareServerService with default repository
 func NewMiddlewareServerServiceWithDefault() *MiddlewareServerService {
-	return NewMiddlewareServerService(NewMiddlewareServerRepoWithGlobal())
+	s := NewMiddlewareServerService(NewMiddlewareServerRepoWithGlobal())
+	s.SetAuditor(audit.NewAuditorWithGlobal(audit.DefaultActor, constant.EmptyString))
+
+	return s
+}
+
+// SetAuditor sets the auditor which records the changes of the middleware servers
+func (mss *MiddlewareServerService) SetAuditor(auditor depaudit.Auditor) {
+	mss.auditor = auditor
+}
+
+// SetIfMatch sets the entity tags which the middleware server to update or delete must match, it is not checked if ifMatch is empty
+func (mss *MiddlewareServerService) SetIfMatch(ifMatch string) {
+	mss.ifMatch = ifMatch
 }
 
 // GetMiddlewareServers returns middleware servers of the service
@@ -35,23 +58,40 @@ func (mss *MiddlewareServerService) GetMiddlewareServers() []metadata.Middleware
 }
 
 // GetAll gets all middleware servers from the middleware
-func (mss *MiddlewareServerService) GetAll() error {
+func (mss *MiddlewareServerService) GetAll(ctx context.Context) error {
 	var err error
-	mss.MiddlewareServers, err = mss.MiddlewareServerRepo.GetAll()
+	mss.MiddlewareServers, err = mss.MiddlewareServerRepo.GetAll(ctx)
 
 	return err
 }
 
+// GetByQuery gets the middleware servers which match the query from the middleware, and the pagination information
+func (mss *MiddlewareServerService) GetByQuery(ctx context.Context, query *filter.Query) error {
+	middlewareServers, total, err := mss.MiddlewareServerRepo.GetByQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	mss.MiddlewareServers = middlewareServers
+	ids := make([]int, len(middlewareServers))
+	for i, middlewareServer := range middlewareServers {
+		ids[i] = middlewareServer.Identity()
+	}
+	mss.Page = query.GetPage(total, ids)
+
+	return nil
+}
+
 // GetByClusterID gets middleware servers with given cluster id
-func (mss *MiddlewareServerService) GetByClusterID(clusterID int) error {
+func (mss *MiddlewareServerService) GetByClusterID(ctx context.Context, clusterID int) error {
 	var err error
-	mss.MiddlewareServers, err = mss.MiddlewareServerRepo.GetByClusterID(clusterID)
+	mss.MiddlewareServers, err = mss.MiddlewareServerRepo.GetByClusterID(ctx, clusterID)
 	return err
 }
 
 // GetByID gets a middleware server by the identity from the middleware
-func (mss *MiddlewareServerService) GetByID(id int) error {
-	middlewareServer, err := mss.MiddlewareServerRepo.GetByID(id)
+func (mss *MiddlewareServerService) GetByID(ctx context.Context, id int) error {
+	middlewareServer, err := mss.MiddlewareServerRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -62,8 +102,8 @@ func (mss *MiddlewareServerService) GetByID(id int) error {
 }
 
 // GetByHostInfo gets a middleware server with given host ip and port number
-func (mss *MiddlewareServerService) GetByHostInfo(hostIP string, portNum int) error {
-	middlewareServer, err := mss.MiddlewareServerRepo.GetByHostInfo(hostIP, portNum)
+func (mss *MiddlewareServerService) GetByHostInfo(ctx context.Context, hostIP string, portNum int) error {
+	middlewareServer, err := mss.MiddlewareServerRepo.GetByHostInfo(ctx, hostIP, portNum)
 	if err != nil {
 		return err
 	}
@@ -72,7 +112,7 @@ func (mss *MiddlewareServerService) GetByHostInfo(hostIP string, portNum int) er
 }
 
 // Create creates a middleware server in the middleware
-func (mss *MiddlewareServerService) Create(fields map[string]interface{}) error {
+func (mss *MiddlewareServerService) Create(ctx context.Context, fields map[string]interface{}) error {
 	// generate new map
 	_, clusterIDExists := fields[middlewareServerClusterIDStruct]
 	_, serverNameExists := fields[middlewareServerNameStruct]
@@ -88,12 +128,14 @@ func (mss *MiddlewareServerService) Create(fields map[string]interface{}) error
 		return err
 	}
 	// insert into middleware
-	middlewareServer, err := mss.MiddlewareServerRepo.Create(middlewareServerInfo)
+	middlewareServer, err := mss.MiddlewareServerRepo.Create(ctx, middlewareServerInfo)
 	if err != nil {
 		return err
 	}
 
 	mss.MiddlewareServers = append(mss.MiddlewareServers, middlewareServer)
+	record(ctx, mss.auditor, audit.EntityTypeMiddlewareServer, middlewareServer.Identity(), audit.ActionCreate, nil, middlewareServer)
+
 	return nil
 }
 
@@ -101,8 +143,16 @@ func (mss *MiddlewareServerService) Create(fields map[string]interface{}) error
 // and then updates its fields that was specified in fields argument,
 // key is the filed name and value is the new field value,
 // it saves the changes to the middleware
-func (mss *MiddlewareServerService) Update(id int, fields map[string]interface{}) error {
-	err := mss.GetByID(id)
+func (mss *MiddlewareServerService) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	err := mss.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mss.ifMatch, middlewareServerTable, id, mss.MiddlewareServers[constant.ZeroInt].GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	before, err := mss.MiddlewareServers[constant.ZeroInt].MarshalJSON()
 	if err != nil {
 		return err
 	}
@@ -110,13 +160,68 @@ func (mss *MiddlewareServerService) Update(id int, fields map[string]interface{}
 	if err != nil {
 		return err
 	}
+	err = mss.MiddlewareServerRepo.Update(ctx, mss.MiddlewareServers[constant.ZeroInt])
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMiddlewareServer, id, audit.ActionUpdate, json.RawMessage(before), mss.MiddlewareServers[constant.ZeroInt])
 
-	return mss.MiddlewareServerRepo.Update(mss.MiddlewareServers[constant.ZeroInt])
+	return nil
 }
 
 // Delete deletes the middleware server of given id in the middleware
-func (mss *MiddlewareServerService) Delete(id int) error {
-	return mss.MiddlewareServerRepo.Delete(id)
+func (mss *MiddlewareServerService) Delete(ctx context.Context, id int) error {
+	before, err := mss.MiddlewareServerRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mss.ifMatch, middlewareServerTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	err = mss.MiddlewareServerRepo.Delete(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMiddlewareServer, id, audit.ActionDelete, before, nil)
+
+	return nil
+}
+
+// DeleteCascade deletes the middleware server of given id and the metadata which reference it in the middleware
+func (mss *MiddlewareServerService) DeleteCascade(ctx context.Context, id int) error {
+	before, err := mss.MiddlewareServerRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mss.ifMatch, middlewareServerTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	rows, err := mss.MiddlewareServerRepo.DeleteCascade(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMiddlewareServer, id, audit.ActionDeleteCascade, before, nil)
+	recordCascaded(ctx, mss.auditor, rows)
+
+	return nil
+}
+
+// Undelete restores the deleted middleware server of given id in the middleware, and then gets it
+func (mss *MiddlewareServerService) Undelete(ctx context.Context, id int) error {
+	err := mss.MiddlewareServerRepo.Undelete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = mss.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMiddlewareServer, id, audit.ActionUndelete, nil, mss.MiddlewareServers[constant.ZeroInt])
+
+	return nil
 }
 
 // Marshal marshals MiddlewareServerService.MiddlewareServers to json bytes
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -27,7 +28,7 @@ func TestMiddlewareServerService_GetMiddlewareServers(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareServerService(middlewareServerRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetMiddlewareServers() failed")
 	entities := s.GetMiddlewareServers()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetMiddlewareServers() failed")
@@ -37,7 +38,7 @@ func TestMiddlewareServerService_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareServerService(middlewareServerRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetAll() failed")
 	entities := s.GetMiddlewareServers()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetAll() failed")
@@ -47,7 +48,7 @@ func TestMiddlewareServerService_GetByClusterID(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareServerService(middlewareServerRepo)
-	err := s.GetByClusterID(13)
+	err := s.GetByClusterID(context.Background(), 13)
 	asst.Nil(err, "test GetByClusterID() failed")
 	clusterID := s.MiddlewareServers[constant.ZeroInt].GetClusterID()
 	asst.Equal(13, clusterID, "test GetByClusterID() failed")
@@ -57,7 +58,7 @@ func TestMiddlewareServerService_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareServerService(middlewareServerRepo)
-	err := s.GetByID(1)
+	err := s.GetByID(context.Background(), 1)
 	asst.Nil(err, "test GetByID() failed")
 	id := s.MiddlewareServers[constant.ZeroInt].Identity()
 	asst.Equal(1, id, "test GetByID() failed")
@@ -67,7 +68,7 @@ func TestMiddlewareServerService_GetByHostInfo(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareServerService(middlewareServerRepo)
-	err := s.GetByHostInfo("1", 1)
+	err := s.GetByHostInfo(context.Background(), "1", 1)
 	asst.Nil(err, "test GetByHostInfo() failed")
 	id := s.MiddlewareServers[constant.ZeroInt].Identity()
 	asst.Equal(1, id, "test GetByHostInfo() failed")
@@ -77,7 +78,7 @@ func TestMiddlewareServerService_Create(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareServerService(middlewareServerRepo)
-	err := s.Create(map[string]interface{}{
+	err := s.Create(context.Background(), map[string]interface{}{
 		middlewareServerClusterIDStruct:      defaultMiddlewareServerInfoClusterID,
 		middlewareServerNameStruct:           defaultMiddlewareServerInfoServerName,
 		middlewareServerMiddlewareRoleStruct: defaultMiddlewareServerInfoMiddlewareRole,
@@ -95,11 +96,11 @@ func TestMiddlewareServerService_Update(t *testing.T) {
 	entity, err := createMiddlewareServer()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewMiddlewareServerService(middlewareServerRepo)
-	err = s.Update(entity.Identity(), map[string]interface{}{
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{
 		middlewareServerNameStruct: newMiddlewareServerName,
 	})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	middlewareServerName := s.GetMiddlewareServers()[constant.ZeroInt].GetServerName()
 	asst.Equal(newMiddlewareServerName, middlewareServerName)
@@ -114,7 +115,7 @@ func TestMiddlewareServerService_Delete(t *testing.T) {
 	entity, err := createMiddlewareServer()
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	s := NewMiddlewareServerService(middlewareServerRepo)
-	err = s.Delete(entity.Identity())
+	err = s.Delete(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	// delete
 	err = deleteMiddlewareServerByID(entity.Identity())
@@ -127,7 +128,7 @@ func TestMiddlewareServerService_Marshal(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMiddlewareServerService(middlewareServerRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
 	data, err := s.Marshal()
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
@@ -147,7 +148,7 @@ func TestMiddlewareServerService_MarshalWithFields(t *testing.T) {
 	entity, err := createMiddlewareServer()
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	s := NewMiddlewareServerService(middlewareServerRepo)
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	dataService, err := s.MarshalWithFields(middlewareServerNameStruct)
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	dataEntity, err := entity.MarshalJSONWithFields(middlewareServerNameStruct)
@@ -1,8 +1,10 @@
 package metadata
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
@@ -11,6 +13,8 @@ import (
 
 	"github.com/romberli/das/global"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/tracing"
 )
 
 var _ metadata.MonitorSystemRepo = (*MonitorSystemRepo)(nil)
@@ -30,9 +34,11 @@ func NewMonitorSystemRepoWithGlobal() *MonitorSystemRepo {
 }
 
 // Execute executes given command and placeholders on the middleware
-func (msr *MonitorSystemRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+func (msr *MonitorSystemRepo) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
 	conn, err := msr.Database.Get()
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
 	defer func() {
@@ -42,7 +48,10 @@ func (msr *MonitorSystemRepo) Execute(command string, args ...interface{}) (midd
 		}
 	}()
 
-	return conn.Execute(command, args...)
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
 }
 
 // Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
@@ -51,16 +60,16 @@ func (msr *MonitorSystemRepo) Transaction() (middleware.Transaction, error) {
 }
 
 // GetAll gets all monitor systems from the middleware
-func (msr *MonitorSystemRepo) GetAll() ([]metadata.MonitorSystem, error) {
+func (msr *MonitorSystemRepo) GetAll(ctx context.Context) ([]metadata.MonitorSystem, error) {
 	sql := `
 		select id, system_name, system_type, host_ip, port_num, port_num_slow, base_url, env_id, del_flag, create_time, last_update_time
 		from t_meta_monitor_system_info
 		where del_flag = 0
 		order by id;
 	`
-	log.Debugf("metadata MonitorSystemRepo.GetAll() sql: \n%s", sql)
+	tracing.Logger(ctx).Debugf("metadata MonitorSystemRepo.GetAll() sql: \n%s", sql)
 
-	result, err := msr.Execute(sql)
+	result, err := msr.Execute(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -83,8 +92,34 @@ func (msr *MonitorSystemRepo) GetAll() ([]metadata.MonitorSystem, error) {
 	return monitorSystemList, nil
 }
 
+// GetByQuery gets the monitor systems which match the query from the middleware,
+// it also returns the total number of the matched monitor systems without pagination
+func (msr *MonitorSystemRepo) GetByQuery(ctx context.Context, query *filter.Query) ([]metadata.MonitorSystem, int, error) {
+	result, total, err := getByQuery(ctx, msr, "MonitorSystemRepo", monitorSystemTable, monitorSystemColumns, query)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []*MonitorSystemInfo
+	monitorSystemInfoList := make([]*MonitorSystemInfo, result.RowNumber())
+	for i := range monitorSystemInfoList {
+		monitorSystemInfoList[i] = NewEmptyMonitorSystemInfoWithGlobal()
+	}
+	// map to struct
+	err = result.MapToStructSlice(monitorSystemInfoList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []metadata.MonitorSystem
+	entityList := make([]metadata.MonitorSystem, result.RowNumber())
+	for i := range entityList {
+		entityList[i] = monitorSystemInfoList[i]
+	}
+
+	return entityList, total, nil
+}
+
 // GetByEnv gets monitor systems of given env id from the middleware
-func (msr *MonitorSystemRepo) GetByEnv(envID int) ([]metadata.MonitorSystem, error) {
+func (msr *MonitorSystemRepo) GetByEnv(ctx context.Context, envID int) ([]metadata.MonitorSystem, error) {
 	sql := `
 		select id, system_name, system_type, host_ip, port_num, port_num_slow, base_url, env_id, del_flag, create_time, last_update_time
 		from t_meta_monitor_system_info
@@ -92,9 +127,9 @@ func (msr *MonitorSystemRepo) GetByEnv(envID int) ([]metadata.MonitorSystem, err
 		and env_id = ?
 		order by id;
 	`
-	log.Debugf("metadata MonitorSystemRepo.GetByEnv sql: \n%s", sql)
+	tracing.Logger(ctx).Debugf("metadata MonitorSystemRepo.GetByEnv sql: \n%s", sql)
 
-	result, err := msr.Execute(sql, envID)
+	result, err := msr.Execute(ctx, sql, envID)
 	if err != nil {
 		return nil, err
 	}
@@ -118,22 +153,22 @@ func (msr *MonitorSystemRepo) GetByEnv(envID int) ([]metadata.MonitorSystem, err
 }
 
 // GetByID gets a monitor system by the identity from the middleware
-func (msr *MonitorSystemRepo) GetByID(id int) (metadata.MonitorSystem, error) {
+func (msr *MonitorSystemRepo) GetByID(ctx context.Context, id int) (metadata.MonitorSystem, error) {
 	sql := `
 		select id, system_name, system_type, host_ip, port_num, port_num_slow, base_url, env_id, del_flag, create_time, last_update_time
 		from t_meta_monitor_system_info
 		where del_flag = 0
 		and id = ?;
 	`
-	log.Debugf("metadata MonitorSystemRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, id)
+	tracing.Logger(ctx).Debugf("metadata MonitorSystemRepo.GetByID() sql: \n%s\nplaceholders: %d", sql, id)
 
-	result, err := msr.Execute(sql, id)
+	result, err := msr.Execute(ctx, sql, id)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata MonitorSystemInfo.GetByID(): data does not exists, id: %d", id))
+		return nil, fmt.Errorf("metadata MonitorSystemInfo.GetByID(): %w, id: %d", ErrDataNotExists, id)
 	case 1:
 		monitorSystemInfo := NewEmptyMonitorSystemInfoWithGlobal()
 		// map to struct
@@ -149,11 +184,11 @@ func (msr *MonitorSystemRepo) GetByID(id int) (metadata.MonitorSystem, error) {
 }
 
 // GetByHostInfo gets a monitor system by the hostInfo from the middleware
-func (msr *MonitorSystemRepo) GetByHostInfo(hostIP string, portNum int) (metadata.MonitorSystem, error) {
+func (msr *MonitorSystemRepo) GetByHostInfo(ctx context.Context, hostIP string, portNum int) (metadata.MonitorSystem, error) {
 	sql := `select id from t_meta_monitor_system_info where del_flag = 0 and host_ip = ? and port_num = ?;`
-	log.Debugf("metadata MonitorSystemRepo.GetByHostInfo() sql: \n%s\nplaceholders: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata MonitorSystemRepo.GetByHostInfo() sql: \n%s\nplaceholders: %s, %d", sql, hostIP, portNum)
 
-	result, err := msr.Execute(sql, hostIP, portNum)
+	result, err := msr.Execute(ctx, sql, hostIP, portNum)
 	if err != nil {
 		return nil, err
 	}
@@ -163,14 +198,14 @@ func (msr *MonitorSystemRepo) GetByHostInfo(hostIP string, portNum int) (metadat
 		return nil, err
 	}
 
-	return msr.GetByID(id)
+	return msr.GetByID(ctx, id)
 }
 
 // GetID gets the identity with given host ip and port num from the middleware
-func (msr *MonitorSystemRepo) GetID(monitorSystemHostIP string, monitorSystemPortNum int) (int, error) {
+func (msr *MonitorSystemRepo) GetID(ctx context.Context, monitorSystemHostIP string, monitorSystemPortNum int) (int, error) {
 	sql := `select id from t_meta_monitor_system_info where del_flag = 0 and host_ip = ? and port_num = ?;`
-	log.Debugf("metadata MonitorSystemRepo.GetID() select sql: %s", sql)
-	result, err := msr.Execute(sql, monitorSystemHostIP, monitorSystemPortNum)
+	tracing.Logger(ctx).Debugf("metadata MonitorSystemRepo.GetID() select sql: %s", sql)
+	result, err := msr.Execute(ctx, sql, monitorSystemHostIP, monitorSystemPortNum)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -179,58 +214,55 @@ func (msr *MonitorSystemRepo) GetID(monitorSystemHostIP string, monitorSystemPor
 }
 
 // Create creates a monitor system in the middleware
-func (msr *MonitorSystemRepo) Create(monitorSystem metadata.MonitorSystem) (metadata.MonitorSystem, error) {
+func (msr *MonitorSystemRepo) Create(ctx context.Context, monitorSystem metadata.MonitorSystem) (metadata.MonitorSystem, error) {
 	sql := `insert into t_meta_monitor_system_info(system_name, system_type, host_ip, port_num, port_num_slow, base_url, env_id) values(?, ?, ?, ?, ?, ?, ?);`
-	log.Debugf("metadata MonitorSystemRepo.Create() insert sql: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata MonitorSystemRepo.Create() insert sql: %s", sql)
 	// execute
-	_, err := msr.Execute(sql, monitorSystem.GetSystemName(), monitorSystem.GetSystemType(), monitorSystem.GetHostIP(),
+	_, err := msr.Execute(ctx, sql, monitorSystem.GetSystemName(), monitorSystem.GetSystemType(), monitorSystem.GetHostIP(),
 		monitorSystem.GetPortNum(), monitorSystem.GetPortNumSlow(), monitorSystem.GetBaseURL(), monitorSystem.GetEnvID())
 	if err != nil {
 		return nil, err
 	}
 	// get id
-	id, err := msr.GetID(monitorSystem.GetHostIP(), monitorSystem.GetPortNum())
+	id, err := msr.GetID(ctx, monitorSystem.GetHostIP(), monitorSystem.GetPortNum())
 	if err != nil {
 		return nil, err
 	}
 	// get entity
-	return msr.GetByID(id)
+	return msr.GetByID(ctx, id)
 }
 
 // Update updates the monitor system in the middleware
-func (msr *MonitorSystemRepo) Update(monitorSystem metadata.MonitorSystem) error {
-	sql := `update t_meta_monitor_system_info set system_name = ?, system_type = ?, host_ip = ?, port_num = ?, port_num_slow = ?, base_url = ?, env_id = ?, del_flag = ? where id = ?;`
-	log.Debugf("metadata MonitorSystemRepo.Update() update sql: %s", sql)
-	_, err := msr.Execute(sql, monitorSystem.GetSystemName(), monitorSystem.GetSystemType(), monitorSystem.GetHostIP(),
+// it returns a *ConflictError if the row was modified or deleted after the entity had been read
+func (msr *MonitorSystemRepo) Update(ctx context.Context, monitorSystem metadata.MonitorSystem) error {
+	sql := `update t_meta_monitor_system_info set system_name = ?, system_type = ?, host_ip = ?, port_num = ?, port_num_slow = ?, base_url = ?, env_id = ?, del_flag = ? where id = ? and date_format(last_update_time, '%Y-%m-%d %H:%i:%s.%f') = ?;`
+	tracing.Logger(ctx).Debugf("metadata MonitorSystemRepo.Update() update sql: %s", sql)
+	result, err := msr.Execute(ctx, sql, monitorSystem.GetSystemName(), monitorSystem.GetSystemType(), monitorSystem.GetHostIP(),
 		monitorSystem.GetPortNum(), monitorSystem.GetPortNumSlow(), monitorSystem.GetBaseURL(), monitorSystem.GetEnvID(),
-		monitorSystem.GetDelFlag(), monitorSystem.Identity())
-
-	return err
-}
-
-// Delete deletes the monitor system in the middleware
-func (msr *MonitorSystemRepo) Delete(id int) error {
-	tx, err := msr.Transaction()
+		monitorSystem.GetDelFlag(), monitorSystem.Identity(), getVersion(monitorSystem.GetLastUpdateTime()))
 	if err != nil {
 		return err
 	}
-	defer func() {
-		err = tx.Close()
-		if err != nil {
-			log.Errorf("metadata MonitorSystemRepo.Delete(): close database connection failed.\n%s", err.Error())
-		}
-	}()
 
-	err = tx.Begin()
-	if err != nil {
-		return err
-	}
-	sql := `delete from t_meta_monitor_system_info where id = ?;`
-	log.Debugf("metadata MonitorSystemRepo.Delete() delete sql(t_meta_monitor_system_info): %s", sql)
-	_, err = msr.Execute(sql, id)
-	if err != nil {
-		return err
-	}
+	return checkUpdated(ctx, msr, "MonitorSystemRepo", monitorSystemTable, monitorSystem.Identity(), monitorSystem.GetLastUpdateTime(), result)
+}
+
+// Delete soft deletes the monitor system in the middleware,
+// it returns a *BlockedError if the monitor system is still referenced by the other metadata,
+// and a *ConflictError if the row was modified after it had been read with given last update time
+func (msr *MonitorSystemRepo) Delete(ctx context.Context, id int, lastUpdateTime time.Time) error {
+	_, err := deleteByID(ctx, msr, "MonitorSystemRepo", monitorSystemTable, id, lastUpdateTime, false)
+
+	return err
+}
+
+// DeleteCascade soft deletes the monitor system and the metadata which reference it in the middleware,
+// it returns the rows which are deleted or detached along with the monitor system
+func (msr *MonitorSystemRepo) DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]metadata.CascadedRow, error) {
+	return deleteByID(ctx, msr, "MonitorSystemRepo", monitorSystemTable, id, lastUpdateTime, true)
+}
 
-	return tx.Commit()
+// Undelete restores the soft deleted monitor system in the middleware
+func (msr *MonitorSystemRepo) Undelete(ctx context.Context, id int) error {
+	return undeleteByID(ctx, msr, "MonitorSystemRepo", monitorSystemTable, id)
 }
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"testing"
 
 	"github.com/romberli/go-util/common"
@@ -37,7 +38,7 @@ func createMonitorSystem() (metadata.MonitorSystem, error) {
 	monitorSystemInfo := NewMonitorSystemInfoWithDefault(defaultMonitorSystemInfoSystemName,
 		defaultMonitorSystemInfoSystemType, defaultMonitorSystemInfoHostIP, defaultMonitorSystemInfoPortNum,
 		defaultMonitorSystemInfoPortNumSlow, defaultMonitorSystemInfoBaseUrl, defaultMonitorSystemInfoEnvID)
-	entity, err := monitorSystemRepo.Create(monitorSystemInfo)
+	entity, err := monitorSystemRepo.Create(context.Background(), monitorSystemInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +48,7 @@ func createMonitorSystem() (metadata.MonitorSystem, error) {
 
 func deleteMonitorSystemByID(id int) error {
 	sql := `delete from t_meta_monitor_system_info where id = ?`
-	_, err := monitorSystemRepo.Execute(sql, id)
+	_, err := monitorSystemRepo.Execute(context.Background(), sql, id)
 	return err
 }
 
@@ -59,13 +60,14 @@ func TestMonitorSystemRepoAll(t *testing.T) {
 	TestMonitorSystemRepo_Create(t)
 	TestMonitorSystemRepo_Update(t)
 	TestMonitorSystemRepo_Delete(t)
+	TestMonitorSystemRepo_Undelete(t)
 }
 
 func TestMonitorSystemRepo_Execute(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := `select 1;`
-	result, err := monitorSystemRepo.Execute(sql)
+	result, err := monitorSystemRepo.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
 	r, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
@@ -80,12 +82,12 @@ func TestMonitorSystemRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	err = tx.Begin()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
-	_, err = tx.Execute(sql, defaultMonitorSystemInfoSystemName, defaultMonitorSystemInfoSystemType, defaultMonitorSystemInfoHostIP,
+	_, err = tx.ExecuteContext(context.Background(), sql, defaultMonitorSystemInfoSystemName, defaultMonitorSystemInfoSystemType, defaultMonitorSystemInfoHostIP,
 		defaultMonitorSystemInfoPortNum, defaultMonitorSystemInfoPortNumSlow, defaultMonitorSystemInfoBaseUrl, defaultMonitorSystemInfoEnvID)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if inserted
 	sql = `select system_name from t_meta_monitor_system_info where system_name = ?`
-	result, err := tx.Execute(sql, defaultMonitorSystemInfoSystemName)
+	result, err := tx.ExecuteContext(context.Background(), sql, defaultMonitorSystemInfoSystemName)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	monitorSystemName, err := result.GetString(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
@@ -95,7 +97,7 @@ func TestMonitorSystemRepo_Transaction(t *testing.T) {
 	err = tx.Rollback()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if rollbacked
-	entities, err := monitorSystemRepo.GetAll()
+	entities, err := monitorSystemRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	for _, entity := range entities {
 		monitorSystemName := entity.GetSystemName()
@@ -112,7 +114,7 @@ func TestMonitorSystemRepo_GetAll(t *testing.T) {
 
 	entity, err := createMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
-	entities, err := monitorSystemRepo.GetAll()
+	entities, err := monitorSystemRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	monitorSystemName := entities[0].GetSystemName()
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
@@ -127,7 +129,7 @@ func TestMonitorSystemRepo_GetByEnv(t *testing.T) {
 
 	entity, err := createMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test GetByEnv() failed", err))
-	entities, err := monitorSystemRepo.GetByEnv(defaultMonitorSystemInfoEnvID)
+	entities, err := monitorSystemRepo.GetByEnv(context.Background(), defaultMonitorSystemInfoEnvID)
 	asst.Nil(err, common.CombineMessageWithError("test GetByEnv() failed", err))
 	asst.Equal(defaultMonitorSystemInfoEnvID, entities[0].GetEnvID(), common.CombineMessageWithError("test GetByEnv() failed", err))
 	// delete
@@ -140,7 +142,7 @@ func TestMonitorSystemRepo_GetByID(t *testing.T) {
 
 	entity, err := createMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
-	db, err := monitorSystemRepo.GetByID(entity.Identity())
+	db, err := monitorSystemRepo.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	monitorSystemName := db.GetSystemName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
@@ -167,9 +169,9 @@ func TestMonitorSystemRepo_Update(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	err = entity.Set(map[string]interface{}{monitorSystemNameStruct: newMonitorSystemName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = monitorSystemRepo.Update(entity)
+	err = monitorSystemRepo.Update(context.Background(), entity)
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	entity, err = monitorSystemRepo.GetByID(entity.Identity())
+	entity, err = monitorSystemRepo.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	monitorSystemName := entity.GetSystemName()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
@@ -188,3 +190,21 @@ func TestMonitorSystemRepo_Delete(t *testing.T) {
 	err = deleteMonitorSystemByID(entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 }
+
+func TestMonitorSystemRepo_Undelete(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createMonitorSystem()
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	err = monitorSystemRepo.Delete(context.Background(), entity.Identity(), entity.GetLastUpdateTime())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = monitorSystemRepo.GetByID(context.Background(), entity.Identity())
+	asst.NotNil(err, "test Undelete() failed")
+	err = monitorSystemRepo.Undelete(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = monitorSystemRepo.GetByID(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	// delete
+	err = deleteMonitorSystemByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+}
@@ -1,12 +1,18 @@
 package metadata
 
 import (
+	"context"
+	"encoding/json"
+
 	"fmt"
 
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 
+	"github.com/romberli/das/internal/app/audit"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 )
 
@@ -27,16 +33,33 @@ var _ metadata.MonitorSystemService = (*MonitorSystemService)(nil)
 type MonitorSystemService struct {
 	metadata.MonitorSystemRepo
 	MonitorSystems []metadata.MonitorSystem `json:"monitorSystems"`
+	Page           *filter.Page             `json:"page"`
+
+	auditor depaudit.Auditor
+	ifMatch string
 }
 
 // NewMonitorSystemService returns a new *MonitorSystemService
 func NewMonitorSystemService(repo metadata.MonitorSystemRepo) *MonitorSystemService {
-	return &MonitorSystemService{repo, []metadata.MonitorSystem{}}
+	return &MonitorSystemService{repo, []metadata.MonitorSystem{}, nil, nil, constant.EmptyString}
 }
 
 // NewMonitorSystemServiceWithDefault returns a new *MonitorSystemService with default repository
 func NewMonitorSystemServiceWithDefault() *MonitorSystemService {
-	return NewMonitorSystemService(NewMonitorSystemRepoWithGlobal())
+	s := NewMonitorSystemService(NewMonitorSystemRepoWithGlobal())
+	s.SetAuditor(audit.NewAuditorWithGlobal(audit.DefaultActor, constant.EmptyString))
+
+	return s
+}
+
+// SetAuditor sets the auditor which records the changes of the monitor systems
+func (mss *MonitorSystemService) SetAuditor(auditor depaudit.Auditor) {
+	mss.auditor = auditor
+}
+
+// SetIfMatch sets the entity tags which the monitor system to update or delete must match, it is not checked if ifMatch is empty
+func (mss *MonitorSystemService) SetIfMatch(ifMatch string) {
+	mss.ifMatch = ifMatch
 }
 
 // GetMonitorSystems returns monitor systems of the service
@@ -45,17 +68,34 @@ func (mss *MonitorSystemService) GetMonitorSystems() []metadata.MonitorSystem {
 }
 
 // GetAll gets all monitor systems from the middleware
-func (mss *MonitorSystemService) GetAll() error {
+func (mss *MonitorSystemService) GetAll(ctx context.Context) error {
 	var err error
 
-	mss.MonitorSystems, err = mss.MonitorSystemRepo.GetAll()
+	mss.MonitorSystems, err = mss.MonitorSystemRepo.GetAll(ctx)
 
 	return err
 }
 
+// GetByQuery gets the monitor systems which match the query from the middleware, and the pagination information
+func (mss *MonitorSystemService) GetByQuery(ctx context.Context, query *filter.Query) error {
+	monitorSystems, total, err := mss.MonitorSystemRepo.GetByQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	mss.MonitorSystems = monitorSystems
+	ids := make([]int, len(monitorSystems))
+	for i, monitorSystem := range monitorSystems {
+		ids[i] = monitorSystem.Identity()
+	}
+	mss.Page = query.GetPage(total, ids)
+
+	return nil
+}
+
 // GetByID gets an monitor system of the given id from the middleware
-func (mss *MonitorSystemService) GetByID(id int) error {
-	monitorSystem, err := mss.MonitorSystemRepo.GetByID(id)
+func (mss *MonitorSystemService) GetByID(ctx context.Context, id int) error {
+	monitorSystem, err := mss.MonitorSystemRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -66,17 +106,17 @@ func (mss *MonitorSystemService) GetByID(id int) error {
 }
 
 // GetByEnv gets all monitor systems from the middleware by env_id
-func (mss *MonitorSystemService) GetByEnv(envID int) error {
+func (mss *MonitorSystemService) GetByEnv(ctx context.Context, envID int) error {
 	var err error
 
-	mss.MonitorSystems, err = mss.MonitorSystemRepo.GetByEnv(envID)
+	mss.MonitorSystems, err = mss.MonitorSystemRepo.GetByEnv(ctx, envID)
 
 	return err
 }
 
 // GetByHostInfo gets monitor system from the middleware by host_info
-func (mss *MonitorSystemService) GetByHostInfo(hostIP string, portNum int) error {
-	monitorSystem, err := mss.MonitorSystemRepo.GetByHostInfo(hostIP, portNum)
+func (mss *MonitorSystemService) GetByHostInfo(ctx context.Context, hostIP string, portNum int) error {
+	monitorSystem, err := mss.MonitorSystemRepo.GetByHostInfo(ctx, hostIP, portNum)
 	if err != nil {
 		return err
 	}
@@ -87,7 +127,7 @@ func (mss *MonitorSystemService) GetByHostInfo(hostIP string, portNum int) error
 }
 
 // Create creates an new monitor system in the middleware
-func (mss *MonitorSystemService) Create(fields map[string]interface{}) error {
+func (mss *MonitorSystemService) Create(ctx context.Context, fields map[string]interface{}) error {
 	// generate new map
 	_, monitorSystemNameExists := fields[monitorSystemNameStruct]
 	_, systemTypeExists := fields[monitorSystemTypeStruct]
@@ -108,11 +148,12 @@ func (mss *MonitorSystemService) Create(fields map[string]interface{}) error {
 		return err
 	}
 	// insert into middleware
-	monitorSystem, err := mss.MonitorSystemRepo.Create(monitorSystemInfo)
+	monitorSystem, err := mss.MonitorSystemRepo.Create(ctx, monitorSystemInfo)
 	if err != nil {
 		return err
 	}
 	mss.MonitorSystems = append(mss.MonitorSystems, monitorSystem)
+	record(ctx, mss.auditor, audit.EntityTypeMonitorSystem, monitorSystem.Identity(), audit.ActionCreate, nil, monitorSystem)
 
 	return nil
 }
@@ -121,8 +162,16 @@ func (mss *MonitorSystemService) Create(fields map[string]interface{}) error {
 // and then update its fields that was specified in fields argument,
 // key is the filed name and value is the new field value,
 // it saves the changes to the middleware
-func (mss *MonitorSystemService) Update(id int, fields map[string]interface{}) error {
-	err := mss.GetByID(id)
+func (mss *MonitorSystemService) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	err := mss.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mss.ifMatch, monitorSystemTable, id, mss.MonitorSystems[constant.ZeroInt].GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	before, err := mss.MonitorSystems[constant.ZeroInt].MarshalJSON()
 	if err != nil {
 		return err
 	}
@@ -130,13 +179,68 @@ func (mss *MonitorSystemService) Update(id int, fields map[string]interface{}) e
 	if err != nil {
 		return err
 	}
+	err = mss.MonitorSystemRepo.Update(ctx, mss.MonitorSystems[constant.ZeroInt])
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMonitorSystem, id, audit.ActionUpdate, json.RawMessage(before), mss.MonitorSystems[constant.ZeroInt])
 
-	return mss.MonitorSystemRepo.Update(mss.MonitorSystems[constant.ZeroInt])
+	return nil
 }
 
 // Delete deletes the monitor system of given id in the middleware
-func (mss *MonitorSystemService) Delete(id int) error {
-	return mss.MonitorSystemRepo.Delete(id)
+func (mss *MonitorSystemService) Delete(ctx context.Context, id int) error {
+	before, err := mss.MonitorSystemRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mss.ifMatch, monitorSystemTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	err = mss.MonitorSystemRepo.Delete(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMonitorSystem, id, audit.ActionDelete, before, nil)
+
+	return nil
+}
+
+// DeleteCascade deletes the monitor system of given id and the metadata which reference it in the middleware
+func (mss *MonitorSystemService) DeleteCascade(ctx context.Context, id int) error {
+	before, err := mss.MonitorSystemRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mss.ifMatch, monitorSystemTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	rows, err := mss.MonitorSystemRepo.DeleteCascade(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMonitorSystem, id, audit.ActionDeleteCascade, before, nil)
+	recordCascaded(ctx, mss.auditor, rows)
+
+	return nil
+}
+
+// Undelete restores the deleted monitor system of given id in the middleware, and then gets it
+func (mss *MonitorSystemService) Undelete(ctx context.Context, id int) error {
+	err := mss.MonitorSystemRepo.Undelete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = mss.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMonitorSystem, id, audit.ActionUndelete, nil, mss.MonitorSystems[constant.ZeroInt])
+
+	return nil
 }
 
 // Marshal marshals MonitorSystemService.MonitorSystems to json bytes
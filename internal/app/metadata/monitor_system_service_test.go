@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -29,7 +30,7 @@ func TestMonitorSystemService_GetMonitorSystems(t *testing.T) {
 	entity, err := createMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test GetMonitorSystems() failed", err))
 	s := NewMonitorSystemService(monitorSystemRepo)
-	err = s.GetAll()
+	err = s.GetAll(context.Background())
 	asst.Nil(err, "test GetMonitorSystems() failed")
 	entities := s.GetMonitorSystems()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetMonitorSystems() failed")
@@ -43,7 +44,7 @@ func TestMonitorSystemService_GetAll(t *testing.T) {
 	entity, err := createMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	s := NewMonitorSystemService(monitorSystemRepo)
-	err = s.GetAll()
+	err = s.GetAll(context.Background())
 	asst.Nil(err, "test GetAll() failed")
 	entities := s.GetMonitorSystems()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetAll() failed")
@@ -58,7 +59,7 @@ func TestMonitorSystemService_GetByID(t *testing.T) {
 	entity, err := createMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	s := NewMonitorSystemService(monitorSystemRepo)
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, "test GetByID() failed")
 	id := s.MonitorSystems[constant.ZeroInt].Identity()
 	asst.Equal(entity.Identity(), id, "test GetByID() failed")
@@ -73,7 +74,7 @@ func TestMonitorSystemService_GetByEnv(t *testing.T) {
 	entity, err := createMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test GetByEnv() failed", err))
 	s := NewMonitorSystemService(monitorSystemRepo)
-	err = s.GetByEnv(1)
+	err = s.GetByEnv(context.Background(), 1)
 	asst.Nil(err, "test GetByEnv() failed")
 	envId := s.MonitorSystems[constant.ZeroInt].GetEnvID()
 	asst.Equal(1, envId, "test GetByEnv() failed")
@@ -88,7 +89,7 @@ func TestMonitorSystemService_GetByHostInfo(t *testing.T) {
 	entity, err := createMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test GetByHostInfo() failed", err))
 	s := NewMonitorSystemService(monitorSystemRepo)
-	err = s.GetByHostInfo("0.0.0.0", 3306)
+	err = s.GetByHostInfo(context.Background(), "0.0.0.0", 3306)
 	asst.Nil(err, "test GetByHostInfo() failed")
 	systemName := s.MonitorSystems[constant.ZeroInt].GetSystemName()
 	asst.Equal(defaultMonitorSystemInfoSystemName, systemName, "test GetByHostInfo() failed")
@@ -101,7 +102,7 @@ func TestMonitorSystemService_Create(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMonitorSystemService(monitorSystemRepo)
-	err := s.Create(map[string]interface{}{monitorSystemNameStruct: defaultMonitorSystemInfoSystemName,
+	err := s.Create(context.Background(), map[string]interface{}{monitorSystemNameStruct: defaultMonitorSystemInfoSystemName,
 		monitorSystemTypeStruct: defaultMonitorSystemInfoSystemType, monitorSystemHostIPStruct: defaultMonitorSystemInfoHostIP,
 		monitorSystemPortNumStruct: defaultMonitorSystemInfoPortNum, monitorSystemPortNumSlowStruct: defaultMonitorSystemInfoPortNumSlow,
 		monitorSystemBaseUrlStruct: defaultMonitorSystemInfoBaseUrl, monitorSystemEnvIDStruct: defaultMonitorSystemInfoEnvID})
@@ -117,9 +118,9 @@ func TestMonitorSystemService_Update(t *testing.T) {
 	entity, err := createMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewMonitorSystemService(monitorSystemRepo)
-	err = s.Update(entity.Identity(), map[string]interface{}{monitorSystemNameStruct: newMonitorSystemName})
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{monitorSystemNameStruct: newMonitorSystemName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	monitorSystemName := s.GetMonitorSystems()[constant.ZeroInt].GetSystemName()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
@@ -135,7 +136,7 @@ func TestMonitorSystemService_Delete(t *testing.T) {
 	entity, err := createMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	s := NewMonitorSystemService(monitorSystemRepo)
-	err = s.Delete(entity.Identity())
+	err = s.Delete(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	// delete
 	err = deleteMonitorSystemByID(entity.Identity())
@@ -148,7 +149,7 @@ func TestMonitorSystemService_Marshal(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMonitorSystemService(monitorSystemRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
 	data, err := s.Marshal()
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
@@ -168,7 +169,7 @@ func TestMonitorSystemService_MarshalWithFields(t *testing.T) {
 	entity, err := createMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	s := NewMonitorSystemService(monitorSystemRepo)
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	dataService, err := s.MarshalWithFields(monitorSystemNameStruct)
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	dataEntity, err := entity.MarshalJSONWithFields(monitorSystemNameStruct)
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/common"
@@ -148,8 +149,8 @@ func (mci *MySQLClusterInfo) GetLastUpdateTime() time.Time {
 }
 
 // GetMySQLServerIDList gets the mysql server id list of this cluster
-func (mci *MySQLClusterInfo) GetMySQLServerIDList() ([]int, error) {
-	return mci.MySQLClusterRepo.GetMySQLServerIDList(mci.Identity())
+func (mci *MySQLClusterInfo) GetMySQLServerIDList(ctx context.Context) ([]int, error) {
+	return mci.MySQLClusterRepo.GetMySQLServerIDList(ctx, mci.Identity())
 }
 
 // Set sets mysql cluster with given fields, key is the field name and value is the relevant value of the key
@@ -1,7 +1,9 @@
 package metadata
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
@@ -10,6 +12,8 @@ import (
 
 	"github.com/romberli/das/global"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/tracing"
 )
 
 const (
@@ -38,9 +42,11 @@ func NewMySQLClusterRepoWithGlobal() *MySQLClusterRepo {
 
 // Execute implements metadata.MySQLClusterRepo interface,
 // it executes command with arguments on database
-func (mcr *MySQLClusterRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+func (mcr *MySQLClusterRepo) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
 	conn, err := mcr.Database.Get()
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
 	defer func() {
@@ -50,7 +56,10 @@ func (mcr *MySQLClusterRepo) Execute(command string, args ...interface{}) (middl
 		}
 	}()
 
-	return conn.Execute(command, args...)
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
 }
 
 // Transaction returns middleware.PoolConn
@@ -59,7 +68,7 @@ func (mcr *MySQLClusterRepo) Transaction() (middleware.Transaction, error) {
 }
 
 // GetAll returns all available entities
-func (mcr *MySQLClusterRepo) GetAll() ([]metadata.MySQLCluster, error) {
+func (mcr *MySQLClusterRepo) GetAll(ctx context.Context) ([]metadata.MySQLCluster, error) {
 	sql := `
 		select id, cluster_name, middleware_cluster_id, monitor_system_id, 
 			owner_id, env_id, del_flag, create_time, last_update_time
@@ -67,9 +76,9 @@ func (mcr *MySQLClusterRepo) GetAll() ([]metadata.MySQLCluster, error) {
 		where del_flag = 0
 		order by id;
 	`
-	log.Debugf("metadata MySQLClusterRepo.GetAll() sql: \n%s", sql)
+	tracing.Logger(ctx).Debugf("metadata MySQLClusterRepo.GetAll() sql: \n%s", sql)
 
-	result, err := mcr.Execute(sql)
+	result, err := mcr.Execute(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -92,8 +101,34 @@ func (mcr *MySQLClusterRepo) GetAll() ([]metadata.MySQLCluster, error) {
 	return mysqlClusterList, nil
 }
 
+// GetByQuery gets the mysql clusters which match the query from the middleware,
+// it also returns the total number of the matched mysql clusters without pagination
+func (mcr *MySQLClusterRepo) GetByQuery(ctx context.Context, query *filter.Query) ([]metadata.MySQLCluster, int, error) {
+	result, total, err := getByQuery(ctx, mcr, "MySQLClusterRepo", mysqlClusterTable, mysqlClusterColumns, query)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []*MySQLClusterInfo
+	mysqlClusterInfoList := make([]*MySQLClusterInfo, result.RowNumber())
+	for i := range mysqlClusterInfoList {
+		mysqlClusterInfoList[i] = NewEmptyMySQLClusterInfoWithGlobal()
+	}
+	// map to struct
+	err = result.MapToStructSlice(mysqlClusterInfoList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []metadata.MySQLCluster
+	entityList := make([]metadata.MySQLCluster, result.RowNumber())
+	for i := range entityList {
+		entityList[i] = mysqlClusterInfoList[i]
+	}
+
+	return entityList, total, nil
+}
+
 // GetByEnv gets mysql clusters of given env id from the middleware
-func (mcr *MySQLClusterRepo) GetByEnv(envID int) ([]metadata.MySQLCluster, error) {
+func (mcr *MySQLClusterRepo) GetByEnv(ctx context.Context, envID int) ([]metadata.MySQLCluster, error) {
 	sql := `
 		select id, cluster_name, middleware_cluster_id, monitor_system_id, 
 			owner_id, env_id, del_flag, create_time, last_update_time
@@ -101,9 +136,9 @@ func (mcr *MySQLClusterRepo) GetByEnv(envID int) ([]metadata.MySQLCluster, error
 		where del_flag = 0
 		and env_id = ?;
 	`
-	log.Debugf("metadata MySQLServerRepo.GetByEnv() sql: \n%s\nplaceholders: %s", sql, envID)
+	tracing.Logger(ctx).Debugf("metadata MySQLServerRepo.GetByEnv() sql: \n%s\nplaceholders: %d", sql, envID)
 
-	result, err := mcr.Execute(sql, envID)
+	result, err := mcr.Execute(ctx, sql, envID)
 	if err != nil {
 		return nil, err
 	}
@@ -117,7 +152,7 @@ func (mcr *MySQLClusterRepo) GetByEnv(envID int) ([]metadata.MySQLCluster, error
 			return nil, err
 		}
 
-		mysqlCluster, err := mcr.GetByID(mysqlClusterID)
+		mysqlCluster, err := mcr.GetByID(ctx, mysqlClusterID)
 		if err != nil {
 			return nil, err
 		}
@@ -129,7 +164,7 @@ func (mcr *MySQLClusterRepo) GetByEnv(envID int) ([]metadata.MySQLCluster, error
 }
 
 // GetByID Select returns an available entity of the given id
-func (mcr *MySQLClusterRepo) GetByID(id int) (metadata.MySQLCluster, error) {
+func (mcr *MySQLClusterRepo) GetByID(ctx context.Context, id int) (metadata.MySQLCluster, error) {
 	sql := `
 		select id, cluster_name, middleware_cluster_id, monitor_system_id, 
 			owner_id, env_id, del_flag, create_time, last_update_time
@@ -137,15 +172,15 @@ func (mcr *MySQLClusterRepo) GetByID(id int) (metadata.MySQLCluster, error) {
 		where del_flag = 0
 		and id = ?;
 	`
-	log.Debugf("metadata MySQLClusterRepo.GetByID() sql: \n%s\nplaceholders: %d", sql, id)
+	tracing.Logger(ctx).Debugf("metadata MySQLClusterRepo.GetByID() sql: \n%s\nplaceholders: %d", sql, id)
 
-	result, err := mcr.Execute(sql, id)
+	result, err := mcr.Execute(ctx, sql, id)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, fmt.Errorf("metadata MySQLClusterInfo.GetByID(): data does not exists, id: %d", id)
+		return nil, fmt.Errorf("metadata MySQLClusterInfo.GetByID(): %w, id: %d", ErrDataNotExists, id)
 	case 1:
 		mysqlClusterInfo := NewEmptyMySQLClusterInfoWithGlobal()
 		// map to struct
@@ -161,20 +196,20 @@ func (mcr *MySQLClusterRepo) GetByID(id int) (metadata.MySQLCluster, error) {
 }
 
 // GetByName gets a mysql cluster of given cluster name from the middle ware
-func (mcr *MySQLClusterRepo) GetByName(clusterName string) (metadata.MySQLCluster, error) {
+func (mcr *MySQLClusterRepo) GetByName(ctx context.Context, clusterName string) (metadata.MySQLCluster, error) {
 	sql := `
 		select id, cluster_name, middleware_cluster_id, monitor_system_id, 
 			owner_id, env_id, del_flag, create_time, last_update_time
 		from t_meta_mysql_cluster_info where del_flag = 0 and cluster_name = ?;
 	`
-	log.Debugf("metadata MySQLClusterRepo.GetByName() select sql: %s", sql)
-	result, err := mcr.Execute(sql, clusterName)
+	tracing.Logger(ctx).Debugf("metadata MySQLClusterRepo.GetByName() select sql: %s", sql)
+	result, err := mcr.Execute(ctx, sql, clusterName)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, fmt.Errorf("metadata MySQLClusterInfo.GetByID(): data does not exists, clusterName: %s", clusterName)
+		return nil, fmt.Errorf("metadata MySQLClusterInfo.GetByID(): %w, clusterName: %s", ErrDataNotExists, clusterName)
 	case 1:
 		mysqlClusterInfo := NewEmptyMySQLClusterInfoWithGlobal()
 		// map to struct
@@ -190,10 +225,10 @@ func (mcr *MySQLClusterRepo) GetByName(clusterName string) (metadata.MySQLCluste
 }
 
 // GetID checks identity of given entity from the middleware
-func (mcr *MySQLClusterRepo) GetID(clusterName string) (int, error) {
+func (mcr *MySQLClusterRepo) GetID(ctx context.Context, clusterName string) (int, error) {
 	sql := `select id from t_meta_mysql_cluster_info where del_flag = 0 and cluster_name = ?;`
-	log.Debugf("metadata MySQLClusterRepo.GetID() select sql: %s", sql)
-	result, err := mcr.Execute(sql, clusterName)
+	tracing.Logger(ctx).Debugf("metadata MySQLClusterRepo.GetID() select sql: %s", sql)
+	result, err := mcr.Execute(ctx, sql, clusterName)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -202,18 +237,18 @@ func (mcr *MySQLClusterRepo) GetID(clusterName string) (int, error) {
 }
 
 // GetMySQLServerIDList gets the mysql server id list of given cluster id
-func (mcr *MySQLClusterRepo) GetMySQLServerIDList(clusterID int) ([]int, error) {
+func (mcr *MySQLClusterRepo) GetMySQLServerIDList(ctx context.Context, clusterID int) ([]int, error) {
 	sql := `
 		select id from t_meta_mysql_server_info where del_flag = 0 and cluster_id = ?;
 	`
-	log.Debugf("metadata MySQLClusterRepo.GetMySQLServerIDList() select sql: %s", sql)
-	result, err := mcr.Execute(sql, clusterID)
+	tracing.Logger(ctx).Debugf("metadata MySQLClusterRepo.GetMySQLServerIDList() select sql: %s", sql)
+	result, err := mcr.Execute(ctx, sql, clusterID)
 	if err != nil {
 		return nil, err
 	}
 
 	resultNum := result.RowNumber()
-	log.Debugf(fmt.Sprint(resultNum))
+	tracing.Logger(ctx).Debugf(fmt.Sprint(resultNum))
 	mysqlServerIDList := make([]int, resultNum)
 
 	for row := 0; row < resultNum; row++ {
@@ -230,14 +265,14 @@ func (mcr *MySQLClusterRepo) GetMySQLServerIDList(clusterID int) ([]int, error)
 }
 
 // Create creates data with given entity in the middleware
-func (mcr *MySQLClusterRepo) Create(mysqlCluster metadata.MySQLCluster) (metadata.MySQLCluster, error) {
+func (mcr *MySQLClusterRepo) Create(ctx context.Context, mysqlCluster metadata.MySQLCluster) (metadata.MySQLCluster, error) {
 	sql := `
 		insert into t_meta_mysql_cluster_info(cluster_name,middleware_cluster_id,
 			 monitor_system_id, owner_id, env_id) 
 		values(?,?,?,?,?);`
-	log.Debugf("metadata MySQLClusterRepo.Create() insert sql: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata MySQLClusterRepo.Create() insert sql: %s", sql)
 	// execute
-	_, err := mcr.Execute(sql,
+	_, err := mcr.Execute(ctx, sql,
 		mysqlCluster.GetClusterName(),
 		mysqlCluster.GetMiddlewareClusterID(),
 		mysqlCluster.GetMonitorSystemID(),
@@ -247,40 +282,54 @@ func (mcr *MySQLClusterRepo) Create(mysqlCluster metadata.MySQLCluster) (metadat
 		return nil, err
 	}
 	// get cluster id
-	id, err := mcr.GetID(mysqlCluster.GetClusterName())
+	id, err := mcr.GetID(ctx, mysqlCluster.GetClusterName())
 	if err != nil {
 		return nil, err
 	}
 	// get entity
-	return mcr.GetByID(id)
+	return mcr.GetByID(ctx, id)
 }
 
 // Update updates data with given entity in the middleware
-func (mcr *MySQLClusterRepo) Update(entity metadata.MySQLCluster) error {
+// it returns a *ConflictError if the row was modified or deleted after the entity had been read
+func (mcr *MySQLClusterRepo) Update(ctx context.Context, entity metadata.MySQLCluster) error {
 	sql := `
 		update t_meta_mysql_cluster_info set cluster_name = ?, middleware_cluster_id = ?, 
 			monitor_system_id = ?, owner_id = ?, 
 			env_id = ?, del_flag = ? 
-		where id = ?;`
-	log.Debugf("metadata MySQLClusterRepo.Update() update sql: %s", sql)
+		where id = ? and date_format(last_update_time, '%Y-%m-%d %H:%i:%s.%f') = ?;`
+	tracing.Logger(ctx).Debugf("metadata MySQLClusterRepo.Update() update sql: %s", sql)
 	mysqlClusterInfo := entity.(*MySQLClusterInfo)
-	_, err := mcr.Execute(sql,
+	result, err := mcr.Execute(ctx, sql,
 		mysqlClusterInfo.ClusterName,
 		mysqlClusterInfo.MiddlewareClusterID,
 		mysqlClusterInfo.MonitorSystemID,
 		mysqlClusterInfo.OwnerID,
 		mysqlClusterInfo.EnvID,
-		mysqlClusterInfo.DelFlag, mysqlClusterInfo.ID)
+		mysqlClusterInfo.DelFlag, mysqlClusterInfo.ID, getVersion(entity.GetLastUpdateTime()))
+	if err != nil {
+		return err
+	}
 
-	return err
+	return checkUpdated(ctx, mcr, "MySQLClusterRepo", mysqlClusterTable, entity.Identity(), entity.GetLastUpdateTime(), result)
 }
 
-// Delete deletes data in the middleware, it is recommended to use soft deletion,
-// therefore use update instead of delete
-func (mcr *MySQLClusterRepo) Delete(id int) error {
-	sql := `delete from t_meta_mysql_cluster_info where id = ?;`
-	log.Debugf("metadata MySQLCLusterRepo.Delete() delete sql(t_meta_mysql_cluster_info): %s", sql)
+// Delete soft deletes the mysql cluster in the middleware,
+// it returns a *BlockedError if the mysql cluster is still referenced by the other metadata,
+// and a *ConflictError if the row was modified after it had been read with given last update time
+func (mcr *MySQLClusterRepo) Delete(ctx context.Context, id int, lastUpdateTime time.Time) error {
+	_, err := deleteByID(ctx, mcr, "MySQLClusterRepo", mysqlClusterTable, id, lastUpdateTime, false)
 
-	_, err := mcr.Execute(sql, id)
 	return err
 }
+
+// DeleteCascade soft deletes the mysql cluster and the metadata which reference it in the middleware,
+// it returns the rows which are deleted or detached along with the mysql cluster
+func (mcr *MySQLClusterRepo) DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]metadata.CascadedRow, error) {
+	return deleteByID(ctx, mcr, "MySQLClusterRepo", mysqlClusterTable, id, lastUpdateTime, true)
+}
+
+// Undelete restores the soft deleted mysql cluster in the middleware
+func (mcr *MySQLClusterRepo) Undelete(ctx context.Context, id int) error {
+	return undeleteByID(ctx, mcr, "MySQLClusterRepo", mysqlClusterTable, id)
+}
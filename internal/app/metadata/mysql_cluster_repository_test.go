@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"testing"
 
 	"github.com/romberli/go-util/common"
@@ -36,7 +37,7 @@ func createMySQLCluster() (metadata.MySQLCluster, error) {
 	mysqlClusterInfo := NewMySQLClusterInfoWithDefault(
 		defaultMySQLClusterInfoClusterName,
 		defaultMySQLClusterInfoEnvID)
-	entity, err := mysqlClusterRepo.Create(mysqlClusterInfo)
+	entity, err := mysqlClusterRepo.Create(context.Background(), mysqlClusterInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +47,7 @@ func createMySQLCluster() (metadata.MySQLCluster, error) {
 
 func deleteMySQLClusterByID(id int) error {
 	sql := `delete from t_meta_mysql_cluster_info where id = ?`
-	_, err := mysqlClusterRepo.Execute(sql, id)
+	_, err := mysqlClusterRepo.Execute(context.Background(), sql, id)
 	return err
 }
 
@@ -62,13 +63,14 @@ func TestMySQLClusterRepoAll(t *testing.T) {
 	TestMySQLClusterRepo_GetMySQLServerIDList(t)
 	TestMySQLClusterRepo_Update(t)
 	TestMySQLClusterRepo_Delete(t)
+	TestMySQLClusterRepo_Undelete(t)
 }
 
 func TestMySQLClusterRepo_Execute(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := `select 1;`
-	result, err := mysqlClusterRepo.Execute(sql)
+	result, err := mysqlClusterRepo.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
 	r, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
@@ -88,7 +90,7 @@ func TestMySQLClusterRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	err = tx.Begin()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
-	_, err = tx.Execute(sql,
+	_, err = tx.ExecuteContext(context.Background(), sql,
 		testTransactionClusterID,
 		testTransactionClusterName,
 		defaultMySQLClusterInfoMiddlewareClusterID,
@@ -98,7 +100,7 @@ func TestMySQLClusterRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if inserted
 	sql = `select cluster_name from t_meta_mysql_cluster_info where cluster_name=?`
-	result, err := tx.Execute(sql, testTransactionClusterName)
+	result, err := tx.ExecuteContext(context.Background(), sql, testTransactionClusterName)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	mysqlClusterName, err := result.GetString(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
@@ -108,7 +110,7 @@ func TestMySQLClusterRepo_Transaction(t *testing.T) {
 	err = tx.Rollback()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if rollbacked
-	entities, err := mysqlClusterRepo.GetAll()
+	entities, err := mysqlClusterRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	for _, entity := range entities {
 		mysqlClusterName := entity.GetClusterName()
@@ -129,7 +131,7 @@ func TestMySQLClusterRepo_GetAll(t *testing.T) {
 	values(?,?,?,?,?,?);`
 
 	// init data avoid empty set
-	_, err := mysqlClusterRepo.Execute(sql,
+	_, err := mysqlClusterRepo.Execute(context.Background(), sql,
 		testInitClusterID,
 		testInitClusterName,
 		defaultMySQLClusterInfoMiddlewareClusterID,
@@ -138,7 +140,7 @@ func TestMySQLClusterRepo_GetAll(t *testing.T) {
 		defaultMySQLClusterInfoEnvID)
 	// asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 
-	entities, err := mysqlClusterRepo.GetAll()
+	entities, err := mysqlClusterRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	mysqlClusterName := entities[0].GetClusterName()
 	asst.Equal(testInitClusterName, mysqlClusterName, "test GetAll() failed")
@@ -147,7 +149,7 @@ func TestMySQLClusterRepo_GetAll(t *testing.T) {
 func TestMySQLClusterRepo_GetByEnv(t *testing.T) {
 	asst := assert.New(t)
 
-	entities, err := mysqlClusterRepo.GetByEnv(testInitClusterID)
+	entities, err := mysqlClusterRepo.GetByEnv(context.Background(), testInitClusterID)
 	asst.Nil(err, common.CombineMessageWithError("test GetByEnv() failed", err))
 
 	for _, entity := range entities {
@@ -159,7 +161,7 @@ func TestMySQLClusterRepo_GetByEnv(t *testing.T) {
 func TestMySQLClusterRepo_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := mysqlClusterRepo.GetByID(testInitClusterID)
+	entity, err := mysqlClusterRepo.GetByID(context.Background(), testInitClusterID)
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	mysqlClusterName := entity.GetClusterName()
 	asst.Equal(testInitClusterName, mysqlClusterName, "test GetByID() failed")
@@ -168,7 +170,7 @@ func TestMySQLClusterRepo_GetByID(t *testing.T) {
 func TestMySQLClusterRepo_GetByName(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := mysqlClusterRepo.GetByName(testInitClusterName)
+	entity, err := mysqlClusterRepo.GetByName(context.Background(), testInitClusterName)
 	asst.Nil(err, common.CombineMessageWithError("test GetByName() failed", err))
 	clusterName := entity.GetClusterName()
 	asst.Equal(testInitClusterName, clusterName, "test GetByName() failed")
@@ -177,18 +179,18 @@ func TestMySQLClusterRepo_GetByName(t *testing.T) {
 func TestMySQLClusterRepo_GetID(t *testing.T) {
 	asst := assert.New(t)
 
-	id, err := mysqlClusterRepo.GetID(testInitClusterName)
+	id, err := mysqlClusterRepo.GetID(context.Background(), testInitClusterName)
 	asst.Nil(err, common.CombineMessageWithError("test GetID() failed", err))
 	asst.NotEqual(0, id, "test GetID() failed")
 }
 
 func TestMySQLClusterRepo_GetMySQLServerIDList(t *testing.T) {
 	asst := assert.New(t)
-	mysqlServerIDList, err := mysqlClusterRepo.GetMySQLServerIDList(testInitClusterID)
+	mysqlServerIDList, err := mysqlClusterRepo.GetMySQLServerIDList(context.Background(), testInitClusterID)
 	// mysqlServerIDList, err := mysqlClusterRepo.GetMySQLServerIDList(97)
 	asst.Nil(err, common.CombineMessageWithError("test GetMySQLServerIDList() failed", err))
 	for _, mysqlServerID := range mysqlServerIDList {
-		mysqlServer, err := mysqlServerRepo.GetByID(mysqlServerID)
+		mysqlServer, err := mysqlServerRepo.GetByID(context.Background(), mysqlServerID)
 		asst.Nil(err, common.CombineMessageWithError("test GetMySQLServerIDList() failed", err))
 		asst.Equal(mysqlServer.GetClusterID(), testInitClusterID, "test GetMySQLServerIDList() failed", err)
 	}
@@ -211,9 +213,9 @@ func TestMySQLClusterRepo_Update(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	err = entity.Set(map[string]interface{}{clusterNameStruct: testUpdateClusterName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = mysqlClusterRepo.Update(entity)
+	err = mysqlClusterRepo.Update(context.Background(), entity)
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	entity, err = mysqlClusterRepo.GetByID(entity.Identity())
+	entity, err = mysqlClusterRepo.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	mysqlClusterName := entity.GetClusterName()
 	asst.Equal(testUpdateClusterName, mysqlClusterName, "test Update() failed")
@@ -231,3 +233,21 @@ func TestMySQLClusterRepo_Delete(t *testing.T) {
 	err = deleteMySQLClusterByID(entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 }
+
+func TestMySQLClusterRepo_Undelete(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createMySQLCluster()
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	err = mysqlClusterRepo.Delete(context.Background(), entity.Identity(), entity.GetLastUpdateTime())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = mysqlClusterRepo.GetByID(context.Background(), entity.Identity())
+	asst.NotNil(err, "test Undelete() failed")
+	err = mysqlClusterRepo.Undelete(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = mysqlClusterRepo.GetByID(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	// delete
+	err = deleteMySQLClusterByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+}
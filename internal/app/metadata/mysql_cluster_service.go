@@ -1,12 +1,18 @@
 package metadata
 
 import (
+	"context"
+	"encoding/json"
+
 	"fmt"
 
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 
+	"github.com/romberli/das/internal/app/audit"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 )
 
@@ -27,16 +33,33 @@ type MySQLClusterService struct {
 	MySQLClusterRepo  metadata.MySQLClusterRepo
 	MySQLClusters     []metadata.MySQLCluster `json:"mysql_clusters"`
 	MySQLServerIDList []int                   `json:"mysql_server_id_list"`
+	Page              *filter.Page            `json:"page"`
+
+	auditor depaudit.Auditor
+	ifMatch string
 }
 
 // NewMySQLClusterService returns a new *MySQLClusterService
 func NewMySQLClusterService(repo metadata.MySQLClusterRepo) *MySQLClusterService {
-	return &MySQLClusterService{repo, []metadata.MySQLCluster{}, []int{}}
+	return &MySQLClusterService{repo, []metadata.MySQLCluster{}, []int{}, nil, nil, constant.EmptyString}
 }
 
 // NewMySQLClusterServiceWithDefault returns a new *MySQLClusterService with default repository
 func NewMySQLClusterServiceWithDefault() *MySQLClusterService {
-	return NewMySQLClusterService(NewMySQLClusterRepoWithGlobal())
+	s := NewMySQLClusterService(NewMySQLClusterRepoWithGlobal())
+	s.SetAuditor(audit.NewAuditorWithGlobal(audit.DefaultActor, constant.EmptyString))
+
+	return s
+}
+
+// SetAuditor sets the auditor which records the changes of the mysql clusters
+func (mcs *MySQLClusterService) SetAuditor(auditor depaudit.Auditor) {
+	mcs.auditor = auditor
+}
+
+// SetIfMatch sets the entity tags which the mysql cluster to update or delete must match, it is not checked if ifMatch is empty
+func (mcs *MySQLClusterService) SetIfMatch(ifMatch string) {
+	mcs.ifMatch = ifMatch
 }
 
 // GetMySQLClusters returns entities of the service
@@ -50,25 +73,42 @@ func (mcs *MySQLClusterService) GetMySQLClusters() []metadata.MySQLCluster {
 }
 
 // GetAll gets all mysql cluster entities from the middleware
-func (mcs *MySQLClusterService) GetAll() error {
+func (mcs *MySQLClusterService) GetAll(ctx context.Context) error {
 	var err error
-	mcs.MySQLClusters, err = mcs.MySQLClusterRepo.GetAll()
+	mcs.MySQLClusters, err = mcs.MySQLClusterRepo.GetAll(ctx)
 
 	return err
 }
 
+// GetByQuery gets the mysql clusters which match the query from the middleware, and the pagination information
+func (mcs *MySQLClusterService) GetByQuery(ctx context.Context, query *filter.Query) error {
+	mysqlClusters, total, err := mcs.MySQLClusterRepo.GetByQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	mcs.MySQLClusters = mysqlClusters
+	ids := make([]int, len(mysqlClusters))
+	for i, mysqlCluster := range mysqlClusters {
+		ids[i] = mysqlCluster.Identity()
+	}
+	mcs.Page = query.GetPage(total, ids)
+
+	return nil
+}
+
 // GetByEnv gets mysql clusters of given env id
-func (mcs *MySQLClusterService) GetByEnv(envID int) error {
+func (mcs *MySQLClusterService) GetByEnv(ctx context.Context, envID int) error {
 	var err error
 
-	mcs.MySQLClusters, err = mcs.MySQLClusterRepo.GetByEnv(envID)
+	mcs.MySQLClusters, err = mcs.MySQLClusterRepo.GetByEnv(ctx, envID)
 
 	return err
 }
 
 // GetByID gets an mysql cluster entity that contains the given id from the middleware
-func (mcs *MySQLClusterService) GetByID(id int) error {
-	mysqlCluster, err := mcs.MySQLClusterRepo.GetByID(id)
+func (mcs *MySQLClusterService) GetByID(ctx context.Context, id int) error {
+	mysqlCluster, err := mcs.MySQLClusterRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -79,8 +119,8 @@ func (mcs *MySQLClusterService) GetByID(id int) error {
 }
 
 // GetByName gets a mysql cluster of given cluster name
-func (mcs *MySQLClusterService) GetByName(clusterName string) error {
-	mysqlCluster, err := mcs.MySQLClusterRepo.GetByName(clusterName)
+func (mcs *MySQLClusterService) GetByName(ctx context.Context, clusterName string) error {
+	mysqlCluster, err := mcs.MySQLClusterRepo.GetByName(ctx, clusterName)
 
 	mcs.MySQLClusters = append(mcs.MySQLClusters, mysqlCluster)
 
@@ -88,19 +128,19 @@ func (mcs *MySQLClusterService) GetByName(clusterName string) error {
 }
 
 // GetMySQLServerIDList gets the mysql server id list of given cluster id
-func (mcs *MySQLClusterService) GetMySQLServerIDList(clusterID int) error {
-	mysqlCluster, err := mcs.MySQLClusterRepo.GetByID(clusterID)
+func (mcs *MySQLClusterService) GetMySQLServerIDList(ctx context.Context, clusterID int) error {
+	mysqlCluster, err := mcs.MySQLClusterRepo.GetByID(ctx, clusterID)
 	if err != nil {
 		return err
 	}
 
-	mcs.MySQLServerIDList, err = mysqlCluster.GetMySQLServerIDList()
+	mcs.MySQLServerIDList, err = mysqlCluster.GetMySQLServerIDList(ctx)
 
 	return err
 }
 
 // Create creates a new mysql cluster entity and insert it into the middleware
-func (mcs *MySQLClusterService) Create(fields map[string]interface{}) error {
+func (mcs *MySQLClusterService) Create(ctx context.Context, fields map[string]interface{}) error {
 	// generate new map
 	_, clusterNameExists := fields[clusterNameStruct]
 	_, envIDExists := fields[envIDStruct]
@@ -120,12 +160,14 @@ func (mcs *MySQLClusterService) Create(fields map[string]interface{}) error {
 		return err
 	}
 	// insert into middleware
-	mysqlCluster, err := mcs.MySQLClusterRepo.Create(mysqlClusterInfo)
+	mysqlCluster, err := mcs.MySQLClusterRepo.Create(ctx, mysqlClusterInfo)
 	if err != nil {
 		return err
 	}
 
 	mcs.MySQLClusters = append(mcs.MySQLClusters, mysqlCluster)
+	record(ctx, mcs.auditor, audit.EntityTypeMySQLCluster, mysqlCluster.Identity(), audit.ActionCreate, nil, mysqlCluster)
+
 	return nil
 }
 
@@ -133,8 +175,16 @@ func (mcs *MySQLClusterService) Create(fields map[string]interface{}) error {
 // and then update its fields that was specified in fields argument,
 // key is the filed name and value is the new field value,
 // it saves the changes to the middleware
-func (mcs *MySQLClusterService) Update(id int, fields map[string]interface{}) error {
-	err := mcs.GetByID(id)
+func (mcs *MySQLClusterService) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	err := mcs.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mcs.ifMatch, mysqlClusterTable, id, mcs.MySQLClusters[constant.ZeroInt].GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	before, err := mcs.MySQLClusters[constant.ZeroInt].MarshalJSON()
 	if err != nil {
 		return err
 	}
@@ -142,13 +192,68 @@ func (mcs *MySQLClusterService) Update(id int, fields map[string]interface{}) er
 	if err != nil {
 		return err
 	}
+	err = mcs.MySQLClusterRepo.Update(ctx, mcs.MySQLClusters[constant.ZeroInt])
+	if err != nil {
+		return err
+	}
+	record(ctx, mcs.auditor, audit.EntityTypeMySQLCluster, id, audit.ActionUpdate, json.RawMessage(before), mcs.MySQLClusters[constant.ZeroInt])
 
-	return mcs.MySQLClusterRepo.Update(mcs.MySQLClusters[constant.ZeroInt])
+	return nil
 }
 
 // Delete deletes the mysql cluster entity that contains the given id in the middleware
-func (mcs *MySQLClusterService) Delete(id int) error {
-	return mcs.MySQLClusterRepo.Delete(id)
+func (mcs *MySQLClusterService) Delete(ctx context.Context, id int) error {
+	before, err := mcs.MySQLClusterRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mcs.ifMatch, mysqlClusterTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	err = mcs.MySQLClusterRepo.Delete(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, mcs.auditor, audit.EntityTypeMySQLCluster, id, audit.ActionDelete, before, nil)
+
+	return nil
+}
+
+// DeleteCascade deletes the mysql cluster of given id and the metadata which reference it in the middleware
+func (mcs *MySQLClusterService) DeleteCascade(ctx context.Context, id int) error {
+	before, err := mcs.MySQLClusterRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mcs.ifMatch, mysqlClusterTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	rows, err := mcs.MySQLClusterRepo.DeleteCascade(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, mcs.auditor, audit.EntityTypeMySQLCluster, id, audit.ActionDeleteCascade, before, nil)
+	recordCascaded(ctx, mcs.auditor, rows)
+
+	return nil
+}
+
+// Undelete restores the deleted mysql cluster of given id in the middleware, and then gets it
+func (mcs *MySQLClusterService) Undelete(ctx context.Context, id int) error {
+	err := mcs.MySQLClusterRepo.Undelete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = mcs.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	record(ctx, mcs.auditor, audit.EntityTypeMySQLCluster, id, audit.ActionUndelete, nil, mcs.MySQLClusters[constant.ZeroInt])
+
+	return nil
 }
 
 // Marshal marshals service.Envs
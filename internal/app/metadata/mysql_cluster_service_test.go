@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -25,7 +26,7 @@ func TestMySQLClusterService_GetMySQLServers(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMySQLClusterService(mysqlClusterRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetEnvs() failed")
 	entities := s.GetMySQLClusters()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetEnvs() failed")
@@ -35,7 +36,7 @@ func TestMySQLClusterService_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMySQLClusterService(mysqlClusterRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetEnvs() failed")
 	entities := s.GetMySQLClusters()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetEnvs() failed")
@@ -45,7 +46,7 @@ func TestMySQLClusterService_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMySQLClusterService(mysqlClusterRepo)
-	err := s.GetByID(1)
+	err := s.GetByID(context.Background(), 1)
 	asst.Nil(err, "test GetByID() failed")
 	id := s.MySQLClusters[constant.ZeroInt].Identity()
 	asst.Equal("1", id, "test GetByID() failed")
@@ -56,7 +57,7 @@ func TestMySQLClusterService_Create(t *testing.T) {
 
 	s := NewMySQLClusterService(mysqlClusterRepo)
 
-	err := s.Create(map[string]interface{}{
+	err := s.Create(context.Background(), map[string]interface{}{
 		clusterNameStruct:         testInsertClusterName,
 		middlewareClusterIDStruct: defaultMySQLClusterInfoMiddlewareClusterID,
 		monitorSystemIDStruct:     defaultMySQLClusterInfoMonitorSystemID,
@@ -75,9 +76,9 @@ func TestMySQLClusterService_Update(t *testing.T) {
 	entity, err := createMySQLCluster()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewMySQLClusterService(mysqlClusterRepo)
-	err = s.Update(entity.Identity(), map[string]interface{}{clusterNameStruct: testUpdateClusterName})
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{clusterNameStruct: testUpdateClusterName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	mysqlClusterName := s.GetMySQLClusters()[constant.ZeroInt].GetClusterName()
 	asst.Equal(testUpdateClusterName, mysqlClusterName)
@@ -92,7 +93,7 @@ func TestMySQLClusterService_Delete(t *testing.T) {
 	entity, err := createMySQLCluster()
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	s := NewMySQLClusterService(mysqlClusterRepo)
-	err = s.Delete(entity.Identity())
+	err = s.Delete(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	// delete
 	err = deleteMySQLClusterByID(entity.Identity())
@@ -105,7 +106,7 @@ func TestMySQLClusterService_Marshal(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMySQLClusterService(mysqlClusterRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
 	data, err := s.Marshal()
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
@@ -125,7 +126,7 @@ func TestMySQLClusterService_MarshalWithFields(t *testing.T) {
 	entity, err := createMySQLCluster()
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	s := NewMySQLClusterService(mysqlClusterRepo)
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	dataService, err := s.MarshalWithFields(clusterNameStruct)
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	dataEntity, err := entity.MarshalJSONWithFields(clusterNameStruct)
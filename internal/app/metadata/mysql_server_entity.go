@@ -1,14 +1,96 @@
 package metadata
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/request"
 )
 
+const (
+	// ServerRolePrimary means the mysql server is the primary of the cluster
+	ServerRolePrimary = 1
+	// ServerRoleReplica means the mysql server is a replica of the cluster
+	ServerRoleReplica = 2
+	// ServerRoleDelayedReplica means the mysql server is a delayed replica of the cluster
+	ServerRoleDelayedReplica = 3
+
+	defaultServerRole = ServerRolePrimary
+	defaultReadWeight = 1
+)
+
+const (
+	// StateProvisioning means the mysql server is being deployed, it could not serve yet
+	StateProvisioning = 1
+	// StateOnline means the mysql server is serving
+	StateOnline = 2
+	// StateMaintenance means the mysql server is under maintenance temporarily, such as upgrading or rebuilding
+	StateMaintenance = 3
+	// StateDecommissioned means the mysql server was taken offline permanently
+	StateDecommissioned = 4
+
+	defaultState = StateOnline
+)
+
+const (
+	readOnlyJSON   = "read_only"
+	cpuCoresJSON   = "cpu_cores"
+	memorySizeJSON = "memory_size"
+	diskSizeJSON   = "disk_size"
+	stateJSON      = "state"
+)
+
+// ErrNotValidStateTransition is returned when the mysql server could not transit from its current state to the new state
+var ErrNotValidStateTransition = errors.New("state transition is not valid")
+
+// stateTransitions are the states which each state could transit to
+var stateTransitions = map[int][]int{
+	StateProvisioning:   {StateOnline, StateDecommissioned},
+	StateOnline:         {StateMaintenance, StateDecommissioned},
+	StateMaintenance:    {StateOnline, StateDecommissioned},
+	StateDecommissioned: {},
+}
+
+// IsValidState returns if the lifecycle state is valid
+func IsValidState(state int) bool {
+	_, ok := stateTransitions[state]
+
+	return ok
+}
+
+// ValidateStateTransition returns an error if the mysql server could not transit from the state to the other state,
+// staying in the same state is always valid
+func ValidateStateTransition(from, to int) error {
+	if !IsValidState(to) {
+		return &request.ValidationError{FieldErrors: []*request.FieldError{newStateFieldError(to)}}
+	}
+	if from == to {
+		return nil
+	}
+	for _, state := range stateTransitions[from] {
+		if state == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: state of the mysql server could not transit from %d to %d", ErrNotValidStateTransition, from, to)
+}
+
+// newStateFieldError returns the field error of the lifecycle state which is not valid
+func newStateFieldError(state int) *request.FieldError {
+	return &request.FieldError{
+		Field: stateJSON,
+		Message: fmt.Sprintf("must be one of [%d, %d, %d, %d], %d is not valid",
+			StateProvisioning, StateOnline, StateMaintenance, StateDecommissioned, state),
+	}
+}
+
 var _ metadata.MySQLServer = (*MySQLServerInfo)(nil)
 
 // MySQLServerInfo is a struct map to table in the database
@@ -21,7 +103,15 @@ type MySQLServerInfo struct {
 	HostIP         string    `middleware:"host_ip" json:"host_ip"`
 	PortNum        int       `middleware:"port_num" json:"port_num"`
 	DeploymentType int       `middleware:"deployment_type" json:"deployment_type"`
+	ServerRole     int       `middleware:"server_role" json:"server_role"`
+	ReadWeight     int       `middleware:"read_weight" json:"read_weight"`
+	ReadOnly       int       `middleware:"read_only" json:"read_only"`
+	CPUCores       int       `middleware:"cpu_cores" json:"cpu_cores"`
+	MemorySize     int       `middleware:"memory_size" json:"memory_size"`
+	DiskSize       int       `middleware:"disk_size" json:"disk_size"`
+	DataDir        string    `middleware:"data_dir" json:"data_dir"`
 	Version        string    `middleware:"version" json:"version"`
+	State          int       `middleware:"state" json:"state"`
 	DelFlag        int       `middleware:"del_flag" json:"del_flag"`
 	CreateTime     time.Time `middleware:"create_time" json:"create_time"`
 	LastUpdateTime time.Time `middleware:"last_update_time" json:"last_update_time"`
@@ -36,7 +126,15 @@ func NewMySQLServerInfo(repo metadata.MySQLServerRepo,
 	hostIP string,
 	portNum int,
 	deploymentType int,
+	serverRole int,
+	readWeight int,
+	readOnly int,
+	cpuCores int,
+	memorySize int,
+	diskSize int,
+	dataDir string,
 	version string,
+	state int,
 	delFlag int,
 	createTime, lastUpdateTime time.Time) *MySQLServerInfo {
 	return &MySQLServerInfo{
@@ -48,7 +146,15 @@ func NewMySQLServerInfo(repo metadata.MySQLServerRepo,
 		hostIP,
 		portNum,
 		deploymentType,
+		serverRole,
+		readWeight,
+		readOnly,
+		cpuCores,
+		memorySize,
+		diskSize,
+		dataDir,
 		version,
+		state,
 		delFlag,
 		createTime,
 		lastUpdateTime,
@@ -64,7 +170,15 @@ func NewMySQLServerInfoWithGlobal(
 	hostIP string,
 	portNum int,
 	deploymentType int,
+	serverRole int,
+	readWeight int,
+	readOnly int,
+	cpuCores int,
+	memorySize int,
+	diskSize int,
+	dataDir string,
 	version string,
+	state int,
 	delFlag int,
 	createTime, lastUpdateTime time.Time) *MySQLServerInfo {
 	return &MySQLServerInfo{
@@ -76,7 +190,15 @@ func NewMySQLServerInfoWithGlobal(
 		hostIP,
 		portNum,
 		deploymentType,
+		serverRole,
+		readWeight,
+		readOnly,
+		cpuCores,
+		memorySize,
+		diskSize,
+		dataDir,
 		version,
+		state,
 		delFlag,
 		createTime,
 		lastUpdateTime,
@@ -102,7 +224,10 @@ func NewMySQLServerInfoWithDefault(
 		HostIP:          hostIP,
 		PortNum:         portNum,
 		DeploymentType:  deploymentType,
+		ServerRole:      defaultServerRole,
+		ReadWeight:      defaultReadWeight,
 		Version:         constant.DefaultRandomString,
+		State:           defaultState,
 	}
 }
 
@@ -151,11 +276,51 @@ func (msi *MySQLServerInfo) GetDeploymentType() int {
 	return msi.DeploymentType
 }
 
+// GetServerRole returns the server role
+func (msi *MySQLServerInfo) GetServerRole() int {
+	return msi.ServerRole
+}
+
+// GetReadWeight returns the read weight
+func (msi *MySQLServerInfo) GetReadWeight() int {
+	return msi.ReadWeight
+}
+
+// GetReadOnly returns the read only flag, 1 means the mysql server is read only
+func (msi *MySQLServerInfo) GetReadOnly() int {
+	return msi.ReadOnly
+}
+
+// GetCPUCores returns the number of the cpu cores
+func (msi *MySQLServerInfo) GetCPUCores() int {
+	return msi.CPUCores
+}
+
+// GetMemorySize returns the memory size, the unit is MB
+func (msi *MySQLServerInfo) GetMemorySize() int {
+	return msi.MemorySize
+}
+
+// GetDiskSize returns the disk size, the unit is GB
+func (msi *MySQLServerInfo) GetDiskSize() int {
+	return msi.DiskSize
+}
+
+// GetDataDir returns the data directory
+func (msi *MySQLServerInfo) GetDataDir() string {
+	return msi.DataDir
+}
+
 // GetVersion returns the version
 func (msi *MySQLServerInfo) GetVersion() string {
 	return msi.Version
 }
 
+// GetState returns the lifecycle state
+func (msi *MySQLServerInfo) GetState() int {
+	return msi.State
+}
+
 // GetDelFlag returns the delete flag
 func (msi *MySQLServerInfo) GetDelFlag() int {
 	return msi.DelFlag
@@ -172,8 +337,8 @@ func (msi *MySQLServerInfo) GetLastUpdateTime() time.Time {
 }
 
 // GetMonitorSystem gets monitor system from the mysql
-func (msi *MySQLServerInfo) GetMonitorSystem() (metadata.MonitorSystem, error) {
-	return msi.MySQLServerRepo.GetMonitorSystem(msi.Identity())
+func (msi *MySQLServerInfo) GetMonitorSystem(ctx context.Context) (metadata.MonitorSystem, error) {
+	return msi.MySQLServerRepo.GetMonitorSystem(ctx, msi.Identity())
 }
 
 // Set sets entity with given fields, key is the field name and value is the relevant value of the key
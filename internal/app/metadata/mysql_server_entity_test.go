@@ -9,6 +9,8 @@ import (
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/pkg/request"
 )
 
 const (
@@ -19,7 +21,15 @@ const (
 	defaultMySQLServerInfoHostIP               = "127.0.0.1"
 	defaultMySQLServerInfoPortNum              = 3306
 	defaultMySQLServerInfoDeploymentType       = 1
+	defaultMySQLServerInfoServerRole           = 2
+	defaultMySQLServerInfoReadWeight           = 1
+	defaultMySQLServerInfoReadOnly             = 0
+	defaultMySQLServerInfoCPUCores             = 8
+	defaultMySQLServerInfoMemorySize           = 16384
+	defaultMySQLServerInfoDiskSize             = 500
+	defaultMySQLServerInfoDataDir              = "/data/mysql/data"
 	defaultMySQLServerInfoVersion              = "1.1.1"
+	defaultMySQLServerInfoState                = StateOnline
 	defaultMySQLServerInfoDelFlag              = 0
 	defaultMySQLServerInfoCreateTimeString     = "2021-01-21 10:00:00.000000"
 	defaultMySQLServerInfoLastUpdateTimeString = "2021-01-21 13:00:00.000000"
@@ -39,7 +49,15 @@ func initNewMySQLServerInfo() *MySQLServerInfo {
 		defaultMySQLServerInfoHostIP,
 		defaultMySQLServerInfoPortNum,
 		defaultMySQLServerInfoDeploymentType,
+		defaultMySQLServerInfoServerRole,
+		defaultMySQLServerInfoReadWeight,
+		defaultMySQLServerInfoReadOnly,
+		defaultMySQLServerInfoCPUCores,
+		defaultMySQLServerInfoMemorySize,
+		defaultMySQLServerInfoDiskSize,
+		defaultMySQLServerInfoDataDir,
 		defaultMySQLServerInfoVersion,
+		defaultMySQLServerInfoState,
 		defaultMySQLServerInfoDelFlag,
 		createTime,
 		lastUpdateTime)
@@ -53,7 +71,15 @@ func equalMySQLServerInfo(a, b *MySQLServerInfo) bool {
 		a.HostIP == b.HostIP &&
 		a.PortNum == b.PortNum &&
 		a.DeploymentType == b.DeploymentType &&
+		a.ServerRole == b.ServerRole &&
+		a.ReadWeight == b.ReadWeight &&
+		a.ReadOnly == b.ReadOnly &&
+		a.CPUCores == b.CPUCores &&
+		a.MemorySize == b.MemorySize &&
+		a.DiskSize == b.DiskSize &&
+		a.DataDir == b.DataDir &&
 		a.Version == b.Version &&
+		a.State == b.State &&
 		a.DelFlag == b.DelFlag &&
 		a.CreateTime == b.CreateTime &&
 		a.LastUpdateTime == b.LastUpdateTime
@@ -66,6 +92,7 @@ func TestMySQLServerEntityAll(t *testing.T) {
 	TestMySQLServerInfo_Delete(t)
 	TestMySQLServerInfo_MarshalJSON(t)
 	TestMySQLServerInfo_MarshalJSONWithFields(t)
+	TestMySQLServerInfo_ValidateStateTransition(t)
 }
 
 func TestMySQLServerInfo_Identity(t *testing.T) {
@@ -92,9 +119,33 @@ func TestMySQLServerInfo_Get(t *testing.T) {
 	deploymentType := mysqlServerInfo.GetDeploymentType()
 	asst.Equal(mysqlServerInfo.DeploymentType, deploymentType, "test GetDeploymentType() failed")
 
+	serverRole := mysqlServerInfo.GetServerRole()
+	asst.Equal(mysqlServerInfo.ServerRole, serverRole, "test GetServerRole() failed")
+
+	readWeight := mysqlServerInfo.GetReadWeight()
+	asst.Equal(mysqlServerInfo.ReadWeight, readWeight, "test GetReadWeight() failed")
+
+	readOnly := mysqlServerInfo.GetReadOnly()
+	asst.Equal(mysqlServerInfo.ReadOnly, readOnly, "test GetReadOnly() failed")
+
+	cpuCores := mysqlServerInfo.GetCPUCores()
+	asst.Equal(mysqlServerInfo.CPUCores, cpuCores, "test GetCPUCores() failed")
+
+	memorySize := mysqlServerInfo.GetMemorySize()
+	asst.Equal(mysqlServerInfo.MemorySize, memorySize, "test GetMemorySize() failed")
+
+	diskSize := mysqlServerInfo.GetDiskSize()
+	asst.Equal(mysqlServerInfo.DiskSize, diskSize, "test GetDiskSize() failed")
+
+	dataDir := mysqlServerInfo.GetDataDir()
+	asst.Equal(mysqlServerInfo.DataDir, dataDir, "test GetDataDir() failed")
+
 	version := mysqlServerInfo.GetVersion()
 	asst.Equal(mysqlServerInfo.Version, version, "test GetVersion() failed")
 
+	state := mysqlServerInfo.GetState()
+	asst.Equal(mysqlServerInfo.State, state, "test GetState() failed")
+
 	delFlag := mysqlServerInfo.GetDelFlag()
 	asst.Equal(mysqlServerInfo.DelFlag, delFlag, "test GetDelFlag() failed")
 
@@ -130,6 +181,11 @@ func TestMySQLServerInfo_Set(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Set() failed", err))
 	asst.Equal(newDeploymentType, mysqlServerInfo.DeploymentType, "test Set() failed")
 
+	newServerRole := ServerRoleDelayedReplica
+	err = mysqlServerInfo.Set(map[string]interface{}{"ServerRole": newServerRole})
+	asst.Nil(err, common.CombineMessageWithError("test Set() failed", err))
+	asst.Equal(newServerRole, mysqlServerInfo.ServerRole, "test Set() failed")
+
 	newVersion := defaultMySQLServerInfoVersion
 	err = mysqlServerInfo.Set(map[string]interface{}{"Version": newVersion})
 	asst.Nil(err, common.CombineMessageWithError("test Set() failed", err))
@@ -167,3 +223,28 @@ func TestMySQLServerInfo_MarshalJSONWithFields(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test MarshalJSONWithFields() failed", err))
 	asst.Equal(string(expect), string(data), "test MarshalJSONWithFields() failed")
 }
+
+func TestMySQLServerInfo_ValidateStateTransition(t *testing.T) {
+	asst := assert.New(t)
+
+	for _, state := range []int{StateProvisioning, StateOnline, StateMaintenance, StateDecommissioned} {
+		asst.True(IsValidState(state), "test IsValidState() failed")
+		err := ValidateStateTransition(state, state)
+		asst.Nil(err, common.CombineMessageWithError("test ValidateStateTransition() failed", err))
+	}
+	asst.False(IsValidState(constant.ZeroInt), "test IsValidState() failed")
+
+	err := ValidateStateTransition(StateProvisioning, StateOnline)
+	asst.Nil(err, common.CombineMessageWithError("test ValidateStateTransition() failed", err))
+	err = ValidateStateTransition(StateOnline, StateMaintenance)
+	asst.Nil(err, common.CombineMessageWithError("test ValidateStateTransition() failed", err))
+	err = ValidateStateTransition(StateMaintenance, StateDecommissioned)
+	asst.Nil(err, common.CombineMessageWithError("test ValidateStateTransition() failed", err))
+	err = ValidateStateTransition(StateDecommissioned, StateOnline)
+	asst.ErrorIs(err, ErrNotValidStateTransition, "test ValidateStateTransition() failed")
+	err = ValidateStateTransition(StateOnline, StateProvisioning)
+	asst.ErrorIs(err, ErrNotValidStateTransition, "test ValidateStateTransition() failed")
+	err = ValidateStateTransition(StateOnline, constant.ZeroInt)
+	var ve *request.ValidationError
+	asst.ErrorAs(err, &ve, "test ValidateStateTransition() failed")
+}
@@ -1,7 +1,9 @@
 package metadata
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
@@ -10,6 +12,8 @@ import (
 
 	"github.com/romberli/das/global"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/tracing"
 )
 
 var _ metadata.MySQLServerRepo = (*MySQLServerRepo)(nil)
@@ -31,9 +35,11 @@ func NewMySQLServerRepoWithGlobal() *MySQLServerRepo {
 
 // Execute implements dependency.Repository interface,
 // it executes command with arguments on database
-func (msr *MySQLServerRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+func (msr *MySQLServerRepo) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
 	conn, err := msr.Database.Get()
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
 	defer func() {
@@ -43,7 +49,10 @@ func (msr *MySQLServerRepo) Execute(command string, args ...interface{}) (middle
 		}
 	}()
 
-	return conn.Execute(command, args...)
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
 }
 
 // Transaction returns middleware.PoolConn
@@ -52,17 +61,17 @@ func (msr *MySQLServerRepo) Transaction() (middleware.Transaction, error) {
 }
 
 // GetAll returns all available entities
-func (msr *MySQLServerRepo) GetAll() ([]metadata.MySQLServer, error) {
+func (msr *MySQLServerRepo) GetAll(ctx context.Context) ([]metadata.MySQLServer, error) {
 	sql := `
-		select id, cluster_id, server_name, service_name, host_ip, port_num, deployment_type, version, del_flag, 
-			create_time, last_update_time
+		select id, cluster_id, server_name, service_name, host_ip, port_num, deployment_type, server_role, read_weight,
+			read_only, cpu_cores, memory_size, disk_size, data_dir, version, state, del_flag, create_time, last_update_time
 		from t_meta_mysql_server_info
 		where del_flag = 0
 		order by id;
 	`
-	log.Debugf("metadata MySQLServerRepo.GetAll() sql: \n%s", sql)
+	tracing.Logger(ctx).Debugf("metadata MySQLServerRepo.GetAll() sql: \n%s", sql)
 
-	result, err := msr.Execute(sql)
+	result, err := msr.Execute(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -85,18 +94,44 @@ func (msr *MySQLServerRepo) GetAll() ([]metadata.MySQLServer, error) {
 	return mysqlServerList, nil
 }
 
+// GetByQuery gets the mysql servers which match the query from the middleware,
+// it also returns the total number of the matched mysql servers without pagination
+func (msr *MySQLServerRepo) GetByQuery(ctx context.Context, query *filter.Query) ([]metadata.MySQLServer, int, error) {
+	result, total, err := getByQuery(ctx, msr, "MySQLServerRepo", mysqlServerTable, mysqlServerColumns, query)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []*MySQLServerInfo
+	mysqlServerInfoList := make([]*MySQLServerInfo, result.RowNumber())
+	for i := range mysqlServerInfoList {
+		mysqlServerInfoList[i] = NewEmptyMySQLServerInfoWithGlobal()
+	}
+	// map to struct
+	err = result.MapToStructSlice(mysqlServerInfoList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []metadata.MySQLServer
+	entityList := make([]metadata.MySQLServer, result.RowNumber())
+	for i := range entityList {
+		entityList[i] = mysqlServerInfoList[i]
+	}
+
+	return entityList, total, nil
+}
+
 // GetByClusterID Select returns an available mysqlServer of the given cluster id
-func (msr *MySQLServerRepo) GetByClusterID(clusterID int) ([]metadata.MySQLServer, error) {
+func (msr *MySQLServerRepo) GetByClusterID(ctx context.Context, clusterID int) ([]metadata.MySQLServer, error) {
 	sql := `
-		select id, cluster_id, server_name, service_name, host_ip, port_num, deployment_type, version, del_flag, 
-			create_time, last_update_time
+		select id, cluster_id, server_name, service_name, host_ip, port_num, deployment_type, server_role, read_weight,
+			read_only, cpu_cores, memory_size, disk_size, data_dir, version, state, del_flag, create_time, last_update_time
 		from t_meta_mysql_server_info 
 		where del_flag = 0
 		and cluster_id = ?;
 	`
-	log.Debugf("metadata MySQLServerRepo.GetByClusterID() sql: \n%s\nplaceholders: %s", sql, clusterID)
+	tracing.Logger(ctx).Debugf("metadata MySQLServerRepo.GetByClusterID() sql: \n%s\nplaceholders: %d", sql, clusterID)
 
-	result, err := msr.Execute(sql, clusterID)
+	result, err := msr.Execute(ctx, sql, clusterID)
 	if err != nil {
 		return nil, err
 	}
@@ -110,7 +145,7 @@ func (msr *MySQLServerRepo) GetByClusterID(clusterID int) ([]metadata.MySQLServe
 			return nil, err
 		}
 
-		mysqlServer, err := msr.GetByID(mysqlServerID)
+		mysqlServer, err := msr.GetByID(ctx, mysqlServerID)
 		if err != nil {
 			return nil, err
 		}
@@ -122,23 +157,23 @@ func (msr *MySQLServerRepo) GetByClusterID(clusterID int) ([]metadata.MySQLServe
 }
 
 // GetByID Select returns an available mysqlServer of the given id
-func (msr *MySQLServerRepo) GetByID(id int) (metadata.MySQLServer, error) {
+func (msr *MySQLServerRepo) GetByID(ctx context.Context, id int) (metadata.MySQLServer, error) {
 	sql := `
-		select id, cluster_id, server_name, service_name, host_ip, port_num, deployment_type, version, del_flag, 
-			create_time, last_update_time
+		select id, cluster_id, server_name, service_name, host_ip, port_num, deployment_type, server_role, read_weight,
+			read_only, cpu_cores, memory_size, disk_size, data_dir, version, state, del_flag, create_time, last_update_time
 		from t_meta_mysql_server_info
 		where del_flag = 0
 		and id = ?;
 	`
-	log.Debugf("metadata MySQLServerRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, id)
+	tracing.Logger(ctx).Debugf("metadata MySQLServerRepo.GetByID() sql: \n%s\nplaceholders: %d", sql, id)
 
-	result, err := msr.Execute(sql, id)
+	result, err := msr.Execute(ctx, sql, id)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, fmt.Errorf("metadata MySQLServerInfo.GetByID(): data does not exists, id: %d", id)
+		return nil, fmt.Errorf("metadata MySQLServerInfo.GetByID(): %w, id: %d", ErrDataNotExists, id)
 	case 1:
 		mysqlServerInfo := NewEmptyMySQLServerInfoWithGlobal()
 		// map to struct
@@ -154,23 +189,23 @@ func (msr *MySQLServerRepo) GetByID(id int) (metadata.MySQLServer, error) {
 }
 
 // GetByHostInfo gets a mysql server with given host ip and port number
-func (msr *MySQLServerRepo) GetByHostInfo(hostIP string, portNum int) (metadata.MySQLServer, error) {
+func (msr *MySQLServerRepo) GetByHostInfo(ctx context.Context, hostIP string, portNum int) (metadata.MySQLServer, error) {
 	sql := `
-		select id, cluster_id, server_name, service_name, host_ip, port_num, deployment_type, version, del_flag, 
-			create_time, last_update_time
+		select id, cluster_id, server_name, service_name, host_ip, port_num, deployment_type, server_role, read_weight,
+			read_only, cpu_cores, memory_size, disk_size, data_dir, version, state, del_flag, create_time, last_update_time
 		from t_meta_mysql_server_info
 		where del_flag = 0
 		and host_ip = ? and port_num = ?;
 	`
-	log.Debugf("metadata MySQLServerRepo.GetByHostInfo() sql: \n%s\nplaceholders: %s, %d", sql, hostIP, portNum)
+	tracing.Logger(ctx).Debugf("metadata MySQLServerRepo.GetByHostInfo() sql: \n%s\nplaceholders: %s, %d", sql, hostIP, portNum)
 
-	result, err := msr.Execute(sql, hostIP, portNum)
+	result, err := msr.Execute(ctx, sql, hostIP, portNum)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, fmt.Errorf("metadata MySQLServerInfo.GetByHostInfo(): data does not exists, hostIP: %s, portNum: %d", hostIP, portNum)
+		return nil, fmt.Errorf("metadata MySQLServerInfo.GetByHostInfo(): %w, hostIP: %s, portNum: %d", ErrDataNotExists, hostIP, portNum)
 	case 1:
 		mysqlServerInfo := NewEmptyMySQLServerInfoWithGlobal()
 		// map to struct
@@ -186,10 +221,10 @@ func (msr *MySQLServerRepo) GetByHostInfo(hostIP string, portNum int) (metadata.
 }
 
 // GetID gets the identity with given host ip and port number from the mysql
-func (msr *MySQLServerRepo) GetID(hostIP string, portNum int) (int, error) {
+func (msr *MySQLServerRepo) GetID(ctx context.Context, hostIP string, portNum int) (int, error) {
 	sql := `select id from t_meta_mysql_server_info where del_flag = 0 and host_ip = ? and port_num = ?;`
-	log.Debugf("metadata MySQLServerRepo.GetID() select sql: %s", sql)
-	result, err := msr.Execute(sql, hostIP, portNum)
+	tracing.Logger(ctx).Debugf("metadata MySQLServerRepo.GetID() select sql: %s", sql)
+	result, err := msr.Execute(ctx, sql, hostIP, portNum)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -198,7 +233,7 @@ func (msr *MySQLServerRepo) GetID(hostIP string, portNum int) (int, error) {
 }
 
 // GetMonitorSystem gets monitor system with given mysql server id from the mysql
-func (msr *MySQLServerRepo) GetMonitorSystem(id int) (metadata.MonitorSystem, error) {
+func (msr *MySQLServerRepo) GetMonitorSystem(ctx context.Context, id int) (metadata.MonitorSystem, error) {
 	sql := `
 		select monsi.id,
 			   monsi.system_name,
@@ -219,9 +254,9 @@ func (msr *MySQLServerRepo) GetMonitorSystem(id int) (metadata.MonitorSystem, er
 		  and monsi.del_flag = 0
 		  and mysi.id = ?;
 	`
-	log.Debugf("metadata MySQLServerRepo.GetMonitorSystem() select sql: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata MySQLServerRepo.GetMonitorSystem() select sql: %s", sql)
 	// execute
-	result, err := msr.Execute(sql, id)
+	result, err := msr.Execute(ctx, sql, id)
 	if err != nil {
 		return nil, err
 	}
@@ -235,62 +270,96 @@ func (msr *MySQLServerRepo) GetMonitorSystem(id int) (metadata.MonitorSystem, er
 }
 
 // Create creates data with given mysqlServer in the middleware
-func (msr *MySQLServerRepo) Create(mysqlServer metadata.MySQLServer) (metadata.MySQLServer, error) {
+func (msr *MySQLServerRepo) Create(ctx context.Context, mysqlServer metadata.MySQLServer) (metadata.MySQLServer, error) {
 	sql := `
 		insert into t_meta_mysql_server_info(
-			cluster_id, server_name, service_name, host_ip, port_num, deployment_type, version) 
-		values(?, ?, ?, ?, ?, ?, ?);`
-	log.Debugf("metadata MySQLServerRepo.Create() insert sql: %s", sql)
+			cluster_id, server_name, service_name, host_ip, port_num, deployment_type, server_role, read_weight,
+			read_only, cpu_cores, memory_size, disk_size, data_dir, version, state) 
+		values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+	tracing.Logger(ctx).Debugf("metadata MySQLServerRepo.Create() insert sql: %s", sql)
 	// execute
-	_, err := msr.Execute(sql,
+	_, err := msr.Execute(ctx, sql,
 		mysqlServer.GetClusterID(),
 		mysqlServer.GetServerName(),
 		mysqlServer.GetServiceName(),
 		mysqlServer.GetHostIP(),
 		mysqlServer.GetPortNum(),
 		mysqlServer.GetDeploymentType(),
+		mysqlServer.GetServerRole(),
+		mysqlServer.GetReadWeight(),
+		mysqlServer.GetReadOnly(),
+		mysqlServer.GetCPUCores(),
+		mysqlServer.GetMemorySize(),
+		mysqlServer.GetDiskSize(),
+		mysqlServer.GetDataDir(),
 		mysqlServer.GetVersion(),
+		mysqlServer.GetState(),
 	)
 	if err != nil {
 		return nil, err
 	}
 	// get id
-	id, err := msr.GetID(mysqlServer.GetHostIP(), mysqlServer.GetPortNum())
+	id, err := msr.GetID(ctx, mysqlServer.GetHostIP(), mysqlServer.GetPortNum())
 	if err != nil {
 		return nil, err
 	}
 	// get mysqlServer
-	return msr.GetByID(id)
+	return msr.GetByID(ctx, id)
 }
 
 // Update updates data with given mysqlServer in the middleware
-func (msr *MySQLServerRepo) Update(mysqlServer metadata.MySQLServer) error {
+// it returns a *ConflictError if the row was modified or deleted after the entity had been read
+func (msr *MySQLServerRepo) Update(ctx context.Context, mysqlServer metadata.MySQLServer) error {
 	sql := `
 		update t_meta_mysql_server_info set 
 			cluster_id = ?, server_name = ?, service_name = ?, host_ip = ?, port_num = ?, deployment_type = ?, 
-			version = ?, del_flag = ? 
-		where id = ?;`
-	log.Debugf("metadata MySQLServerRepo.Update() update sql: %s", sql)
+			server_role = ?, read_weight = ?, read_only = ?, cpu_cores = ?, memory_size = ?, disk_size = ?, data_dir = ?, 
+			version = ?, state = ?, del_flag = ? 
+		where id = ? and date_format(last_update_time, '%Y-%m-%d %H:%i:%s.%f') = ?;`
+	tracing.Logger(ctx).Debugf("metadata MySQLServerRepo.Update() update sql: %s", sql)
 	mysqlServerInfo := mysqlServer.(*MySQLServerInfo)
-	_, err := msr.Execute(sql,
+	result, err := msr.Execute(ctx, sql,
 		mysqlServerInfo.ClusterID,
 		mysqlServerInfo.ServerName,
 		mysqlServerInfo.ServiceName,
 		mysqlServerInfo.HostIP,
 		mysqlServerInfo.PortNum,
 		mysqlServerInfo.DeploymentType,
+		mysqlServerInfo.ServerRole,
+		mysqlServerInfo.ReadWeight,
+		mysqlServerInfo.ReadOnly,
+		mysqlServerInfo.CPUCores,
+		mysqlServerInfo.MemorySize,
+		mysqlServerInfo.DiskSize,
+		mysqlServerInfo.DataDir,
 		mysqlServerInfo.Version,
+		mysqlServerInfo.State,
 		mysqlServerInfo.DelFlag,
-		mysqlServerInfo.ID)
+		mysqlServerInfo.ID,
+		getVersion(mysqlServer.GetLastUpdateTime()))
+	if err != nil {
+		return err
+	}
 
-	return err
+	return checkUpdated(ctx, msr, "MySQLServerRepo", mysqlServerTable, mysqlServer.Identity(), mysqlServer.GetLastUpdateTime(), result)
 }
 
-// Delete deletes data in the middleware, it is recommended to use soft deletion,
-// therefore use update instead of delete
-func (msr *MySQLServerRepo) Delete(id int) error {
-	sql := `delete from t_meta_mysql_server_info where id = ?;`
-	log.Debugf("metadata MySQLServerRepo.Delete() delete sql(t_meta_mysql_server_info): %s", sql)
-	_, err := msr.Execute(sql, id)
+// Delete soft deletes the mysql server in the middleware,
+// it returns a *BlockedError if the mysql server is still referenced by the other metadata,
+// and a *ConflictError if the row was modified after it had been read with given last update time
+func (msr *MySQLServerRepo) Delete(ctx context.Context, id int, lastUpdateTime time.Time) error {
+	_, err := deleteByID(ctx, msr, "MySQLServerRepo", mysqlServerTable, id, lastUpdateTime, false)
+
 	return err
 }
+
+// DeleteCascade soft deletes the mysql server and the metadata which reference it in the middleware,
+// it returns the rows which are deleted or detached along with the mysql server
+func (msr *MySQLServerRepo) DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]metadata.CascadedRow, error) {
+	return deleteByID(ctx, msr, "MySQLServerRepo", mysqlServerTable, id, lastUpdateTime, true)
+}
+
+// Undelete restores the soft deleted mysql server in the middleware
+func (msr *MySQLServerRepo) Undelete(ctx context.Context, id int) error {
+	return undeleteByID(ctx, msr, "MySQLServerRepo", mysqlServerTable, id)
+}
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"testing"
 
 	"github.com/romberli/go-util/common"
@@ -42,7 +43,7 @@ func createMySQLServer() (metadata.MySQLServer, error) {
 		defaultMySQLServerInfoHostIP,
 		defaultMySQLServerInfoPortNum,
 		defaultMySQLServerInfoDeploymentType)
-	entity, err := mysqlServerRepo.Create(mysqlServerInfo)
+	entity, err := mysqlServerRepo.Create(context.Background(), mysqlServerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +53,7 @@ func createMySQLServer() (metadata.MySQLServer, error) {
 
 func deleteMySQLServerByID(id int) error {
 	sql := `delete from t_meta_mysql_server_info where id = ?`
-	_, err := mysqlServerRepo.Execute(sql, id)
+	_, err := mysqlServerRepo.Execute(context.Background(), sql, id)
 	return err
 }
 
@@ -67,13 +68,14 @@ func TestMySQLServerRepoAll(t *testing.T) {
 	TestMySQLServerRepo_GetID(t)
 	TestMySQLServerRepo_Update(t)
 	TestMySQLServerRepo_Delete(t)
+	TestMySQLServerRepo_Undelete(t)
 }
 
 func TestMySQLServerRepo_Execute(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := `select 1;`
-	result, err := mysqlServerRepo.Execute(sql)
+	result, err := mysqlServerRepo.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
 	r, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
@@ -92,7 +94,7 @@ func TestMySQLServerRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	err = tx.Begin()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
-	_, err = tx.Execute(sql,
+	_, err = tx.ExecuteContext(context.Background(), sql,
 		testTransactionServerID,
 		defaultMySQLServerInfoClusterID,
 		defaultMySQLServerInfoServerName,
@@ -104,7 +106,7 @@ func TestMySQLServerRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if inserted
 	sql = `select host_ip, port_num from t_meta_mysql_server_info where host_ip=? and port_num=?`
-	result, err := tx.Execute(sql, testTransactionHostIP, testTransactionPortNum)
+	result, err := tx.ExecuteContext(context.Background(), sql, testTransactionHostIP, testTransactionPortNum)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	hostIP, err := result.GetString(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
@@ -119,7 +121,7 @@ func TestMySQLServerRepo_Transaction(t *testing.T) {
 	err = tx.Rollback()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if rollbacked
-	entities, err := mysqlServerRepo.GetAll()
+	entities, err := mysqlServerRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	for _, entity := range entities {
 		hostIP := entity.GetHostIP()
@@ -140,7 +142,7 @@ func TestMySQLServerRepo_GetAll(t *testing.T) {
 	values(?,?,?,?,?,?,?,?);`
 
 	// init data avoid empty set
-	_, err := mysqlServerRepo.Execute(sql,
+	_, err := mysqlServerRepo.Execute(context.Background(), sql,
 		testInitServerID,
 		defaultMySQLServerInfoClusterID,
 		defaultMySQLServerInfoServerName,
@@ -150,7 +152,7 @@ func TestMySQLServerRepo_GetAll(t *testing.T) {
 		defaultMySQLServerInfoVersion)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 
-	entities, err := mysqlServerRepo.GetAll()
+	entities, err := mysqlServerRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	hostIP := entities[0].GetHostIP()
 	asst.Equal(testInitHostIP, hostIP, "test GetAll() failed")
@@ -161,7 +163,7 @@ func TestMySQLServerRepo_GetAll(t *testing.T) {
 func TestMySQLServerRepo_GetByClusterID(t *testing.T) {
 	asst := assert.New(t)
 
-	entities, err := mysqlServerRepo.GetByClusterID(testInitClusterID)
+	entities, err := mysqlServerRepo.GetByClusterID(context.Background(), testInitClusterID)
 
 	for _, entity := range entities {
 		asst.Nil(err, common.CombineMessageWithError("test GetByClusterID() failed", err))
@@ -175,7 +177,7 @@ func TestMySQLServerRepo_GetByClusterID(t *testing.T) {
 func TestMySQLServerRepo_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := mysqlServerRepo.GetByID(1)
+	entity, err := mysqlServerRepo.GetByID(context.Background(), 1)
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	hostIP := entity.GetHostIP()
 	asst.Equal(testInitHostIP, hostIP, "test GetByID() failed")
@@ -186,7 +188,7 @@ func TestMySQLServerRepo_GetByID(t *testing.T) {
 func TestMySQLServerRepo_GetByHostInfo(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := mysqlServerRepo.GetByHostInfo(testInitHostIP, testInitPortNum)
+	entity, err := mysqlServerRepo.GetByHostInfo(context.Background(), testInitHostIP, testInitPortNum)
 	asst.Nil(err, common.CombineMessageWithError("test GetByHostInfo() failed", err))
 	hostIP := entity.GetHostIP()
 	asst.Equal(testInitHostIP, hostIP, "test GetByHostInfo() failed")
@@ -197,7 +199,7 @@ func TestMySQLServerRepo_GetByHostInfo(t *testing.T) {
 func TestMySQLServerRepo_GetID(t *testing.T) {
 	asst := assert.New(t)
 
-	id, err := mysqlServerRepo.GetID(testInitHostIP, testInitPortNum)
+	id, err := mysqlServerRepo.GetID(context.Background(), testInitHostIP, testInitPortNum)
 	asst.Nil(err, common.CombineMessageWithError("test GetID() failed", err))
 	asst.NotEqual(0, id, "test GetID() failed")
 }
@@ -221,9 +223,9 @@ func TestMySQLServerRepo_Update(t *testing.T) {
 		hostIPStruct:  testUpdateHostIP,
 		portNumStruct: testUpdatePortNum})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = mysqlServerRepo.Update(entity)
+	err = mysqlServerRepo.Update(context.Background(), entity)
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	entity, err = mysqlServerRepo.GetByID(entity.Identity())
+	entity, err = mysqlServerRepo.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	hostIP := entity.GetHostIP()
 	asst.Equal(testUpdateHostIP, hostIP, "test Update() failed")
@@ -243,3 +245,21 @@ func TestMySQLServerRepo_Delete(t *testing.T) {
 	err = deleteMySQLServerByID(entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 }
+
+func TestMySQLServerRepo_Undelete(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createMySQLServer()
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	err = mysqlServerRepo.Delete(context.Background(), entity.Identity(), entity.GetLastUpdateTime())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = mysqlServerRepo.GetByID(context.Background(), entity.Identity())
+	asst.NotNil(err, "test Undelete() failed")
+	err = mysqlServerRepo.Undelete(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = mysqlServerRepo.GetByID(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	// delete
+	err = deleteMySQLServerByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+}
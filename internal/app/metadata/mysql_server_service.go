@@ -1,13 +1,23 @@
 package metadata
 
 import (
+	"context"
+	"encoding/json"
+
 	"fmt"
+	"sort"
 
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware/mysql"
+	"github.com/romberli/log"
 
+	"github.com/romberli/das/internal/app/audit"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/das/pkg/request"
 )
 
 const (
@@ -17,7 +27,22 @@ const (
 	hostIPStruct         = "HostIP"
 	portNumStruct        = "PortNum"
 	deploymentTypeStruct = "DeploymentType"
+	serverRoleStruct     = "ServerRole"
+	readWeightStruct     = "ReadWeight"
+	readOnlyStruct       = "ReadOnly"
+	cpuCoresStruct       = "CPUCores"
+	memorySizeStruct     = "MemorySize"
+	diskSizeStruct       = "DiskSize"
+	dataDirStruct        = "DataDir"
 	versionStruct        = "Version"
+	stateStruct          = "State"
+)
+
+const (
+	slaveIORunningColumn      = "Slave_IO_Running"
+	slaveSQLRunningColumn     = "Slave_SQL_Running"
+	secondsBehindMasterColumn = "Seconds_Behind_Master"
+	replicationRunningValue   = "Yes"
 )
 
 const msMySQLServersStruct = "MySQLServers"
@@ -28,16 +53,33 @@ var _ metadata.MySQLServerService = (*MySQLServerService)(nil)
 type MySQLServerService struct {
 	MySQLServerRepo metadata.MySQLServerRepo
 	MySQLServers    []metadata.MySQLServer
+	Page            *filter.Page `json:"page"`
+
+	auditor depaudit.Auditor
+	ifMatch string
 }
 
 // NewMySQLServerService returns a new *MySQLServerService
 func NewMySQLServerService(repo metadata.MySQLServerRepo) *MySQLServerService {
-	return &MySQLServerService{repo, []metadata.MySQLServer{}}
+	return &MySQLServerService{repo, []metadata.MySQLServer{}, nil, nil, constant.EmptyString}
 }
 
 // NewMySQLServerServiceWithDefault returns a new *MySQLServerService with default repository
 func NewMySQLServerServiceWithDefault() *MySQLServerService {
-	return NewMySQLServerService(NewMySQLServerRepoWithGlobal())
+	s := NewMySQLServerService(NewMySQLServerRepoWithGlobal())
+	s.SetAuditor(audit.NewAuditorWithGlobal(audit.DefaultActor, constant.EmptyString))
+
+	return s
+}
+
+// SetAuditor sets the auditor which records the changes of the mysql servers
+func (mss *MySQLServerService) SetAuditor(auditor depaudit.Auditor) {
+	mss.auditor = auditor
+}
+
+// SetIfMatch sets the entity tags which the mysql server to update or delete must match, it is not checked if ifMatch is empty
+func (mss *MySQLServerService) SetIfMatch(ifMatch string) {
+	mss.ifMatch = ifMatch
 }
 
 // GetMySQLServers returns entities of the service
@@ -51,16 +93,33 @@ func (mss *MySQLServerService) GetMySQLServers() []metadata.MySQLServer {
 }
 
 // GetAll gets all mysql server entities from the middleware
-func (mss *MySQLServerService) GetAll() error {
+func (mss *MySQLServerService) GetAll(ctx context.Context) error {
 	var err error
-	mss.MySQLServers, err = mss.MySQLServerRepo.GetAll()
+	mss.MySQLServers, err = mss.MySQLServerRepo.GetAll(ctx)
 
 	return err
 }
 
+// GetByQuery gets the mysql servers which match the query from the middleware, and the pagination information
+func (mss *MySQLServerService) GetByQuery(ctx context.Context, query *filter.Query) error {
+	mysqlServers, total, err := mss.MySQLServerRepo.GetByQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	mss.MySQLServers = mysqlServers
+	ids := make([]int, len(mysqlServers))
+	for i, mysqlServer := range mysqlServers {
+		ids[i] = mysqlServer.Identity()
+	}
+	mss.Page = query.GetPage(total, ids)
+
+	return nil
+}
+
 // GetByClusterID gets mysql servers with given cluster id
-func (mss *MySQLServerService) GetByClusterID(clusterID int) error {
-	mysqlServers, err := mss.MySQLServerRepo.GetByClusterID(clusterID)
+func (mss *MySQLServerService) GetByClusterID(ctx context.Context, clusterID int) error {
+	mysqlServers, err := mss.MySQLServerRepo.GetByClusterID(ctx, clusterID)
 	if err != nil {
 		return err
 	}
@@ -71,8 +130,8 @@ func (mss *MySQLServerService) GetByClusterID(clusterID int) error {
 }
 
 // GetByID gets an mysql server entity that contains the given id from the middleware
-func (mss *MySQLServerService) GetByID(id int) error {
-	entity, err := mss.MySQLServerRepo.GetByID(id)
+func (mss *MySQLServerService) GetByID(ctx context.Context, id int) error {
+	entity, err := mss.MySQLServerRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -83,8 +142,8 @@ func (mss *MySQLServerService) GetByID(id int) error {
 }
 
 // GetByHostInfo gets a mysql server with given host ip and port number
-func (mss *MySQLServerService) GetByHostInfo(hostIP string, portNum int) error {
-	mysqlServer, err := mss.MySQLServerRepo.GetByHostInfo(hostIP, portNum)
+func (mss *MySQLServerService) GetByHostInfo(ctx context.Context, hostIP string, portNum int) error {
+	mysqlServer, err := mss.MySQLServerRepo.GetByHostInfo(ctx, hostIP, portNum)
 	if err != nil {
 		return err
 	}
@@ -94,8 +153,181 @@ func (mss *MySQLServerService) GetByHostInfo(hostIP string, portNum int) error {
 	return nil
 }
 
+// GetReadableByClusterID gets readable mysql servers of the given cluster in the order of preference,
+// replicas come first, then the delayed replicas, both of them are ordered by read weight descending,
+// primary will be appended only when allowPrimary is true, servers with zero read weight or not online will never be chosen
+func (mss *MySQLServerService) GetReadableByClusterID(ctx context.Context, clusterID int, allowPrimary bool) error {
+	mysqlServers, err := mss.MySQLServerRepo.GetByClusterID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		replicas        []metadata.MySQLServer
+		delayedReplicas []metadata.MySQLServer
+		primaries       []metadata.MySQLServer
+	)
+
+	for _, mysqlServer := range mysqlServers {
+		if mysqlServer.GetReadWeight() <= constant.ZeroInt || mysqlServer.GetState() != StateOnline {
+			continue
+		}
+
+		switch mysqlServer.GetServerRole() {
+		case ServerRoleReplica:
+			replicas = append(replicas, mysqlServer)
+		case ServerRoleDelayedReplica:
+			delayedReplicas = append(delayedReplicas, mysqlServer)
+		case ServerRolePrimary:
+			if allowPrimary {
+				primaries = append(primaries, mysqlServer)
+			}
+		}
+	}
+
+	sortByReadWeight(replicas)
+	sortByReadWeight(delayedReplicas)
+
+	readableServers := append(replicas, delayedReplicas...)
+	readableServers = append(readableServers, primaries...)
+	if len(readableServers) == constant.ZeroInt {
+		return fmt.Errorf("could not find readable mysql server of the cluster. cluster id: %d, allow primary: %t", clusterID, allowPrimary)
+	}
+
+	mss.MySQLServers = readableServers
+
+	return nil
+}
+
+// GetHealthyReadableByClusterID checks the readable mysql servers of the given cluster in the order of preference,
+// and keeps only the first healthy one,
+// a replica is healthy only when both replication threads are running and the replication delay is not larger than maxReplicationDelay,
+// a delayed replica ignores the replication delay, a primary is healthy as long as it could be connected
+func (mss *MySQLServerService) GetHealthyReadableByClusterID(ctx context.Context, clusterID int, user, pass string, maxReplicationDelay int, allowPrimary bool) error {
+	err := mss.GetReadableByClusterID(ctx, clusterID, allowPrimary)
+	if err != nil {
+		return err
+	}
+
+	for _, mysqlServer := range mss.MySQLServers {
+		healthy, err := mss.isReadable(mysqlServer, user, pass, maxReplicationDelay)
+		if err != nil {
+			log.Warnf("metadata MySQLServerService.GetHealthyReadableByClusterID(): check mysql server failed, it will be skipped. host ip: %s, port num: %d\n%s",
+				mysqlServer.GetHostIP(), mysqlServer.GetPortNum(), err.Error())
+			continue
+		}
+		if healthy {
+			mss.MySQLServers = []metadata.MySQLServer{mysqlServer}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("could not find healthy readable mysql server of the cluster. cluster id: %d, allow primary: %t", clusterID, allowPrimary)
+}
+
+// isReadable connects to the given mysql server and checks if it is healthy enough to be read
+func (mss *MySQLServerService) isReadable(mysqlServer metadata.MySQLServer, user, pass string, maxReplicationDelay int) (bool, error) {
+	addr := fmt.Sprintf("%s:%d", mysqlServer.GetHostIP(), mysqlServer.GetPortNum())
+	conn, err := mysql.NewConn(addr, constant.EmptyString, user, pass)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("metadata MySQLServerService.isReadable(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	if mysqlServer.GetServerRole() == ServerRolePrimary {
+		return conn.CheckInstanceStatus(), nil
+	}
+
+	result, err := conn.GetReplicationSlavesStatus()
+	if err != nil {
+		return false, err
+	}
+	if result.RowNumber() == constant.ZeroInt {
+		// the replica is not replicating from any source
+		return false, nil
+	}
+
+	ioRunning, err := result.GetStringByName(constant.ZeroInt, slaveIORunningColumn)
+	if err != nil {
+		return false, err
+	}
+	sqlRunning, err := result.GetStringByName(constant.ZeroInt, slaveSQLRunningColumn)
+	if err != nil {
+		return false, err
+	}
+	if ioRunning != replicationRunningValue || sqlRunning != replicationRunningValue {
+		return false, nil
+	}
+	if mysqlServer.GetServerRole() == ServerRoleDelayedReplica {
+		// delayed replica lags behind on purpose
+		return true, nil
+	}
+
+	isNull, err := result.IsNullByName(constant.ZeroInt, secondsBehindMasterColumn)
+	if err != nil {
+		return false, err
+	}
+	if isNull {
+		return false, nil
+	}
+	delay, err := result.GetIntByName(constant.ZeroInt, secondsBehindMasterColumn)
+	if err != nil {
+		return false, err
+	}
+
+	return delay <= maxReplicationDelay, nil
+}
+
+// validateMySQLServer validates the read only flag, the hardware specification and the lifecycle state of the mysql server,
+// it returns a *request.ValidationError which contains all the field errors
+func validateMySQLServer(mysqlServer metadata.MySQLServer) error {
+	ve := &request.ValidationError{}
+	if mysqlServer.GetReadOnly() != constant.ZeroInt && mysqlServer.GetReadOnly() != 1 {
+		ve.FieldErrors = append(ve.FieldErrors, &request.FieldError{
+			Field:   readOnlyJSON,
+			Message: fmt.Sprintf("must be either 0 or 1, %d is not valid", mysqlServer.GetReadOnly()),
+		})
+	}
+	sizes := []struct {
+		field string
+		value int
+	}{
+		{cpuCoresJSON, mysqlServer.GetCPUCores()},
+		{memorySizeJSON, mysqlServer.GetMemorySize()},
+		{diskSizeJSON, mysqlServer.GetDiskSize()},
+	}
+	for _, size := range sizes {
+		if size.value < constant.ZeroInt {
+			ve.FieldErrors = append(ve.FieldErrors, &request.FieldError{
+				Field:   size.field,
+				Message: fmt.Sprintf("must not be negative, %d is not valid", size.value),
+			})
+		}
+	}
+	if !IsValidState(mysqlServer.GetState()) {
+		ve.FieldErrors = append(ve.FieldErrors, newStateFieldError(mysqlServer.GetState()))
+	}
+	if len(ve.FieldErrors) > constant.ZeroInt {
+		return ve
+	}
+
+	return nil
+}
+
+// sortByReadWeight sorts the mysql servers by read weight descending
+func sortByReadWeight(mysqlServers []metadata.MySQLServer) {
+	sort.SliceStable(mysqlServers, func(i, j int) bool {
+		return mysqlServers[i].GetReadWeight() > mysqlServers[j].GetReadWeight()
+	})
+}
+
 // Create creates a new mysql server entity and insert it into the middleware
-func (mss *MySQLServerService) Create(fields map[string]interface{}) error {
+func (mss *MySQLServerService) Create(ctx context.Context, fields map[string]interface{}) error {
 	// generate new map
 	_, clusterIDExists := fields[clusterIDStruct]
 	_, serverNameExists := fields[serverNameStruct]
@@ -118,18 +350,45 @@ func (mss *MySQLServerService) Create(fields map[string]interface{}) error {
 				deploymentTypeStruct))
 	}
 
+	_, serverRoleExists := fields[serverRoleStruct]
+	if !serverRoleExists {
+		fields[serverRoleStruct] = defaultServerRole
+	}
+	_, readWeightExists := fields[readWeightStruct]
+	if !readWeightExists {
+		fields[readWeightStruct] = defaultReadWeight
+	}
+	_, stateExists := fields[stateStruct]
+	if !stateExists {
+		fields[stateStruct] = defaultState
+	}
+
 	// create a new entity
 	mysqlServerInfo, err := NewMySQLServerInfoWithMapAndRandom(fields)
 	if err != nil {
 		return err
 	}
+	err = validateMySQLServer(mysqlServerInfo)
+	if err != nil {
+		return err
+	}
+	// a new mysql server is either being deployed or serving
+	if mysqlServerInfo.GetState() != StateProvisioning && mysqlServerInfo.GetState() != StateOnline {
+		return &request.ValidationError{FieldErrors: []*request.FieldError{{
+			Field: stateJSON,
+			Message: fmt.Sprintf("must be either %d or %d for the new mysql server, %d is not valid",
+				StateProvisioning, StateOnline, mysqlServerInfo.GetState()),
+		}}}
+	}
 	// insert into middleware
-	entity, err := mss.MySQLServerRepo.Create(mysqlServerInfo)
+	entity, err := mss.MySQLServerRepo.Create(ctx, mysqlServerInfo)
 	if err != nil {
 		return err
 	}
 
 	mss.MySQLServers = append(mss.MySQLServers, entity)
+	record(ctx, mss.auditor, audit.EntityTypeMySQLServer, entity.Identity(), audit.ActionCreate, nil, entity)
+
 	return nil
 }
 
@@ -137,22 +396,94 @@ func (mss *MySQLServerService) Create(fields map[string]interface{}) error {
 // and then update its fields that was specified in fields argument,
 // key is the filed name and value is the new field value,
 // it saves the changes to the middleware
-func (mss *MySQLServerService) Update(id int, fields map[string]interface{}) error {
-	err := mss.GetByID(id)
+func (mss *MySQLServerService) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	err := mss.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	err = checkIfMatch(mss.ifMatch, mysqlServerTable, id, mss.MySQLServers[constant.ZeroInt].GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	before, err := mss.MySQLServers[constant.ZeroInt].MarshalJSON()
+	if err != nil {
+		return err
+	}
+	state := mss.MySQLServers[constant.ZeroInt].GetState()
 	err = mss.MySQLServers[constant.ZeroInt].Set(fields)
 	if err != nil {
 		return err
 	}
+	err = validateMySQLServer(mss.MySQLServers[constant.ZeroInt])
+	if err != nil {
+		return err
+	}
+	err = ValidateStateTransition(state, mss.MySQLServers[constant.ZeroInt].GetState())
+	if err != nil {
+		return err
+	}
+	err = mss.MySQLServerRepo.Update(ctx, mss.MySQLServers[constant.ZeroInt])
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMySQLServer, id, audit.ActionUpdate, json.RawMessage(before), mss.MySQLServers[constant.ZeroInt])
 
-	return mss.MySQLServerRepo.Update(mss.MySQLServers[constant.ZeroInt])
+	return nil
 }
 
 // Delete deletes the mysql server entity that contains the given id in the middleware
-func (mss *MySQLServerService) Delete(id int) error {
-	return mss.MySQLServerRepo.Delete(id)
+func (mss *MySQLServerService) Delete(ctx context.Context, id int) error {
+	before, err := mss.MySQLServerRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mss.ifMatch, mysqlServerTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	err = mss.MySQLServerRepo.Delete(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMySQLServer, id, audit.ActionDelete, before, nil)
+
+	return nil
+}
+
+// DeleteCascade deletes the mysql server of given id and the metadata which reference it in the middleware
+func (mss *MySQLServerService) DeleteCascade(ctx context.Context, id int) error {
+	before, err := mss.MySQLServerRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(mss.ifMatch, mysqlServerTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	rows, err := mss.MySQLServerRepo.DeleteCascade(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMySQLServer, id, audit.ActionDeleteCascade, before, nil)
+	recordCascaded(ctx, mss.auditor, rows)
+
+	return nil
+}
+
+// Undelete restores the deleted mysql server of given id in the middleware, and then gets it
+func (mss *MySQLServerService) Undelete(ctx context.Context, id int) error {
+	err := mss.MySQLServerRepo.Undelete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = mss.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	record(ctx, mss.auditor, audit.EntityTypeMySQLServer, id, audit.ActionUndelete, nil, mss.MySQLServers[constant.ZeroInt])
+
+	return nil
 }
 
 // Marshal marshals service.Envs
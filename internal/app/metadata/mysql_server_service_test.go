@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -8,6 +9,8 @@ import (
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/pkg/request"
 )
 
 func TestMySQLServerServiceAll(t *testing.T) {
@@ -16,18 +19,20 @@ func TestMySQLServerServiceAll(t *testing.T) {
 	TestMySQLServerService_GetByClusterID(t)
 	TestMySQLServerService_GetByID(t)
 	TestMySQLServerService_GetByHostInfo(t)
+	TestMySQLServerService_GetReadableByClusterID(t)
 	TestMySQLServerService_Create(t)
 	TestMySQLServerService_Update(t)
 	TestMySQLServerService_Delete(t)
 	TestMySQLServerService_Marshal(t)
 	TestMySQLServerService_MarshalWithFields(t)
+	TestValidateMySQLServer(t)
 }
 
 func TestMySQLServerService_GetMySQLServers(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMySQLServerService(mysqlServerRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetEnvs() failed")
 	entities := s.GetMySQLServers()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetEnvs() failed")
@@ -37,7 +42,7 @@ func TestMySQLServerService_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMySQLServerService(mysqlServerRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetEnvs() failed")
 	entities := s.GetMySQLServers()
 	asst.Greater(len(entities), constant.ZeroInt, "test GetEnvs() failed")
@@ -47,7 +52,7 @@ func TestMySQLServerService_GetByClusterID(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMySQLServerService(mysqlServerRepo)
-	err := s.GetByClusterID(testInitClusterID)
+	err := s.GetByClusterID(context.Background(), testInitClusterID)
 	asst.Nil(err, "test GetByClusterID() failed")
 	clusterID := s.MySQLServers[constant.ZeroInt].GetClusterID()
 	asst.Equal(testInitClusterID, clusterID, "test GetByClusterID() failed")
@@ -57,7 +62,7 @@ func TestMySQLServerService_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMySQLServerService(mysqlServerRepo)
-	err := s.GetByID(testInitServerID)
+	err := s.GetByID(context.Background(), testInitServerID)
 	asst.Nil(err, "test GetByID() failed")
 	id := s.MySQLServers[constant.ZeroInt].Identity()
 	asst.Equal(testInitServerID, id, "test GetByID() failed")
@@ -67,7 +72,7 @@ func TestMySQLServerService_GetByHostInfo(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMySQLServerService(mysqlServerRepo)
-	err := s.GetByHostInfo(testInitHostIP, testInitPortNum)
+	err := s.GetByHostInfo(context.Background(), testInitHostIP, testInitPortNum)
 	asst.Nil(err, "test GetByHostInfo() failed")
 	hostIP := s.MySQLServers[constant.ZeroInt].GetHostIP()
 	asst.Equal(testInitHostIP, hostIP, "test GetByHostInfo() failed")
@@ -75,11 +80,36 @@ func TestMySQLServerService_GetByHostInfo(t *testing.T) {
 	asst.Equal(testInitPortNum, portNum, "test GetByHostInfo() failed")
 }
 
+func TestMySQLServerService_GetReadableByClusterID(t *testing.T) {
+	asst := assert.New(t)
+
+	s := NewMySQLServerService(mysqlServerRepo)
+	err := s.GetReadableByClusterID(context.Background(), testInitClusterID, true)
+	asst.Nil(err, "test GetReadableByClusterID() failed")
+	primaryFound := false
+	for _, mysqlServer := range s.GetMySQLServers() {
+		asst.Greater(mysqlServer.GetReadWeight(), constant.ZeroInt, "test GetReadableByClusterID() failed")
+		// primary should always be the last choice
+		if primaryFound {
+			asst.Equal(ServerRolePrimary, mysqlServer.GetServerRole(), "test GetReadableByClusterID() failed")
+		}
+		primaryFound = mysqlServer.GetServerRole() == ServerRolePrimary
+	}
+	// primary should not be chosen when it is not allowed
+	s = NewMySQLServerService(mysqlServerRepo)
+	err = s.GetReadableByClusterID(context.Background(), testInitClusterID, false)
+	if err == nil {
+		for _, mysqlServer := range s.GetMySQLServers() {
+			asst.NotEqual(ServerRolePrimary, mysqlServer.GetServerRole(), "test GetReadableByClusterID() failed")
+		}
+	}
+}
+
 func TestMySQLServerService_Create(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMySQLServerService(mysqlServerRepo)
-	err := s.Create(map[string]interface{}{
+	err := s.Create(context.Background(), map[string]interface{}{
 		clusterIDStruct:      defaultMySQLServerInfoClusterID,
 		serverNameStruct:     defaultMySQLServerInfoServerName,
 		serviceNameStruct:    defaultMySQLServerInfoServiceName,
@@ -99,11 +129,11 @@ func TestMySQLServerService_Update(t *testing.T) {
 	entity, err := createMySQLServer()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewMySQLServerService(mysqlServerRepo)
-	err = s.Update(entity.Identity(), map[string]interface{}{
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{
 		hostIPStruct:  testUpdateHostIP,
 		portNumStruct: testUpdatePortNum})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	hostIP := s.GetMySQLServers()[constant.ZeroInt].GetHostIP()
 	asst.Equal(testUpdateHostIP, hostIP)
@@ -120,7 +150,7 @@ func TestMySQLServerService_Delete(t *testing.T) {
 	entity, err := createMySQLServer()
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	s := NewMySQLServerService(mysqlServerRepo)
-	err = s.Delete(entity.Identity())
+	err = s.Delete(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	// delete
 	err = deleteMySQLServerByID(entity.Identity())
@@ -133,7 +163,7 @@ func TestMySQLServerService_Marshal(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewMySQLServerService(mysqlServerRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
 	data, err := s.Marshal()
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
@@ -153,7 +183,7 @@ func TestMySQLServerService_MarshalWithFields(t *testing.T) {
 	entity, err := createMySQLServer()
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	s := NewMySQLServerService(mysqlServerRepo)
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	dataService, err := s.MarshalWithFields(clusterNameStruct)
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	dataEntity, err := entity.MarshalJSONWithFields(clusterNameStruct)
@@ -163,3 +193,19 @@ func TestMySQLServerService_MarshalWithFields(t *testing.T) {
 	err = deleteMySQLServerByID(entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 }
+
+func TestValidateMySQLServer(t *testing.T) {
+	asst := assert.New(t)
+
+	mysqlServerInfo := &MySQLServerInfo{State: StateOnline}
+	err := validateMySQLServer(mysqlServerInfo)
+	asst.Nil(err, common.CombineMessageWithError("test validateMySQLServer() failed", err))
+
+	mysqlServerInfo = &MySQLServerInfo{ReadOnly: 2, CPUCores: -1, DiskSize: -1, State: constant.ZeroInt}
+	err = validateMySQLServer(mysqlServerInfo)
+	var ve *request.ValidationError
+	asst.ErrorAs(err, &ve, "test validateMySQLServer() failed")
+	asst.Equal(4, len(ve.FieldErrors), "test validateMySQLServer() failed")
+	asst.Equal(readOnlyJSON, ve.FieldErrors[0].Field, "test validateMySQLServer() failed")
+	asst.Equal(stateJSON, ve.FieldErrors[3].Field, "test validateMySQLServer() failed")
+}
@@ -0,0 +1,72 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+// ErrDataNotExists is returned when the metadata to get does not exist
+var ErrDataNotExists = errors.New("data does not exists")
+
+// ErrNotValidQuery is returned when the query filters or sorts by a field which is not supported
+var ErrNotValidQuery = errors.New("query is not valid")
+
+var (
+	appColumns               = []string{"id", "app_name", "level", "owner_id", "del_flag", "create_time", "last_update_time"}
+	dbColumns                = []string{"id", "db_name", "cluster_id", "cluster_type", "owner_id", "env_id", "del_flag", "create_time", "last_update_time"}
+	envColumns               = []string{"id", "env_name", "del_flag", "create_time", "last_update_time"}
+	middlewareClusterColumns = []string{"id", "cluster_name", "owner_id", "env_id", "del_flag", "create_time", "last_update_time"}
+	middlewareServerColumns  = []string{"id", "cluster_id", "server_name", "middleware_role", "host_ip", "port_num", "del_flag", "create_time", "last_update_time"}
+	monitorSystemColumns     = []string{"id", "system_name", "system_type", "host_ip", "port_num", "port_num_slow", "base_url", "env_id", "del_flag", "create_time", "last_update_time"}
+	mysqlClusterColumns      = []string{"id", "cluster_name", "middleware_cluster_id", "monitor_system_id", "owner_id", "env_id", "del_flag", "create_time", "last_update_time"}
+	mysqlServerColumns       = []string{"id", "cluster_id", "server_name", "service_name", "host_ip", "port_num", "deployment_type", "server_role", "read_weight", "read_only", "cpu_cores", "memory_size", "disk_size", "data_dir", "version", "state", "del_flag", "create_time", "last_update_time"}
+	userColumns              = []string{"id", "user_name", "department_name", "employee_id", "account_name", "email", "telephone", "mobile", "role", "del_flag", "create_time", "last_update_time"}
+)
+
+// executor executes the command on the middleware, all the repositories implement it
+type executor interface {
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
+}
+
+// getByQuery selects the columns of the rows which match the query from the table,
+// and returns the rows and the total number of the matched rows without pagination,
+// the columns are also the fields which could be filtered and sorted
+func getByQuery(ctx context.Context, repo executor, caller, table string, columns []string, query *filter.Query) (middleware.Result, int, error) {
+	err := query.Validate(columns...)
+	if err != nil {
+		return nil, constant.ZeroInt, fmt.Errorf("%w: %s", ErrNotValidQuery, err.Error())
+	}
+
+	where, args := query.GetWhereClause()
+	cursor, cursorArgs := query.GetCursorClause()
+
+	countSQL := fmt.Sprintf(`select count(*) from %s where del_flag = 0%s;`, table, where)
+	tracing.Logger(ctx).Debugf("metadata %s.GetByQuery() count sql: \n%s\nplaceholders: %v", caller, countSQL, args)
+	result, err := repo.Execute(ctx, countSQL, args...)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	total, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+
+	sql := fmt.Sprintf(`select %s from %s where del_flag = 0%s%s%s%s;`,
+		strings.Join(columns, ", "), table, where, cursor, query.GetOrderByClause(), query.GetLimitClause())
+	args = append(args, cursorArgs...)
+	tracing.Logger(ctx).Debugf("metadata %s.GetByQuery() sql: \n%s\nplaceholders: %v", caller, sql, args)
+	result, err = repo.Execute(ctx, sql, args...)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+
+	return result, total, nil
+}
@@ -0,0 +1,88 @@
+package metadata
+
+import (
+	"context"
+
+	"github.com/romberli/das/internal/dependency/metadata"
+)
+
+// Snapshot is all the registered metadata entities and the maps of the apps and databases
+type Snapshot struct {
+	Envs               []metadata.Env
+	Users              []metadata.User
+	MonitorSystems     []metadata.MonitorSystem
+	MiddlewareClusters []metadata.MiddlewareCluster
+	MiddlewareServers  []metadata.MiddlewareServer
+	MySQLClusters      []metadata.MySQLCluster
+	MySQLServers       []metadata.MySQLServer
+	DBs                []metadata.DB
+	Apps               []metadata.App
+	// AppDBMap is the database identities of each app, the key is the app id
+	AppDBMap map[int][]int
+}
+
+// GetSnapshot loads all the registered metadata entities and the maps of the apps and databases from the middleware
+func GetSnapshot(ctx context.Context) (*Snapshot, error) {
+	envService := NewEnvServiceWithDefault()
+	err := envService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userService := NewUserServiceWithDefault()
+	err = userService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	monitorSystemService := NewMonitorSystemServiceWithDefault()
+	err = monitorSystemService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	middlewareClusterService := NewMiddlewareClusterServiceWithDefault()
+	err = middlewareClusterService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	middlewareServerService := NewMiddlewareServerServiceWithDefault()
+	err = middlewareServerService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mysqlClusterService := NewMySQLClusterServiceWithDefault()
+	err = mysqlClusterService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mysqlServerService := NewMySQLServerServiceWithDefault()
+	err = mysqlServerService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dbService := NewDBServiceWithDefault()
+	err = dbService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appService := NewAppServiceWithDefault()
+	err = appService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appDBMap, err := appService.GetAppDBMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		Envs:               envService.GetEnvs(),
+		Users:              userService.GetUsers(),
+		MonitorSystems:     monitorSystemService.GetMonitorSystems(),
+		MiddlewareClusters: middlewareClusterService.GetMiddlewareClusters(),
+		MiddlewareServers:  middlewareServerService.GetMiddlewareServers(),
+		MySQLClusters:      mysqlClusterService.GetMySQLClusters(),
+		MySQLServers:       mysqlServerService.GetMySQLServers(),
+		DBs:                dbService.GetDBs(),
+		Apps:               appService.GetApps(),
+		AppDBMap:           appDBMap,
+	}, nil
+}
@@ -0,0 +1,84 @@
+package metadata
+
+import (
+	"github.com/romberli/go-util/middleware"
+)
+
+var _ middleware.Pool = (*transactionPool)(nil)
+
+// transactionPool is a middleware.Pool which executes all the statements in the same transaction,
+// the repositories created with it take part in the transaction, so do the nested transactions of them,
+// the transaction is begun and ended by ExecuteInTransaction()
+type transactionPool struct {
+	tx middleware.Transaction
+}
+
+// newTransactionPool returns a new *transactionPool with given transaction
+func newTransactionPool(tx middleware.Transaction) *transactionPool {
+	return &transactionPool{tx: tx}
+}
+
+// Close does nothing, the transaction is closed by ExecuteInTransaction()
+func (tp *transactionPool) Close() error {
+	return nil
+}
+
+// IsClosed always returns false
+func (tp *transactionPool) IsClosed() bool {
+	return false
+}
+
+// Get returns the connection of the transaction
+func (tp *transactionPool) Get() (middleware.PoolConn, error) {
+	return &transactionConn{tp.tx}, nil
+}
+
+// Transaction returns the connection of the transaction, the nested transaction joins the outer one
+func (tp *transactionPool) Transaction() (middleware.Transaction, error) {
+	return &transactionConn{tp.tx}, nil
+}
+
+// Supply does nothing
+func (tp *transactionPool) Supply(num int) error {
+	return nil
+}
+
+// Release does nothing
+func (tp *transactionPool) Release(num int) error {
+	return nil
+}
+
+// transactionConn executes the statements in the transaction, but it does not end the transaction
+type transactionConn struct {
+	middleware.Transaction
+}
+
+// Close does nothing, the connection is returned to the pool by ExecuteInTransaction()
+func (tc *transactionConn) Close() error {
+	return nil
+}
+
+// Begin does nothing, the transaction had been begun
+func (tc *transactionConn) Begin() error {
+	return nil
+}
+
+// Commit does nothing, the transaction is committed by ExecuteInTransaction() after all the statements succeed
+func (tc *transactionConn) Commit() error {
+	return nil
+}
+
+// Rollback does nothing, the nested transaction returns the error,
+// and the transaction is rolled back by ExecuteInTransaction()
+func (tc *transactionConn) Rollback() error {
+	return nil
+}
+
+// ExecuteInTransaction executes the function in a transaction of given pool,
+// the repositories created with the pool passed to the function execute all the statements in the transaction,
+// so the changes made by multiple services are committed together, or rolled back if the function fails
+func ExecuteInTransaction(pool middleware.Pool, f func(pool middleware.Pool) error) error {
+	return executeInTransaction(pool, "ExecuteInTransaction()", func(tx middleware.Transaction) error {
+		return f(newTransactionPool(tx))
+	})
+}
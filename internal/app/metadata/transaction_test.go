@@ -0,0 +1,103 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/romberli/go-util/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// testTransaction counts the calls of the transaction, the statements are not executed
+type testTransaction struct {
+	middleware.Transaction
+	begin      int
+	commit     int
+	rollback   int
+	close      int
+	statements int
+}
+
+func (tt *testTransaction) Begin() error {
+	tt.begin++
+	return nil
+}
+
+func (tt *testTransaction) Commit() error {
+	tt.commit++
+	return nil
+}
+
+func (tt *testTransaction) Rollback() error {
+	tt.rollback++
+	return nil
+}
+
+func (tt *testTransaction) Close() error {
+	tt.close++
+	return nil
+}
+
+func (tt *testTransaction) ExecuteContext(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	tt.statements++
+	return nil, nil
+}
+
+// testPool returns the same test transaction
+type testPool struct {
+	middleware.Pool
+	tx *testTransaction
+}
+
+func (tp *testPool) Transaction() (middleware.Transaction, error) {
+	return tp.tx, nil
+}
+
+func TestTransactionAll(t *testing.T) {
+	TestExecuteInTransaction(t)
+}
+
+func TestExecuteInTransaction(t *testing.T) {
+	asst := assert.New(t)
+
+	// the statements of the nested transaction are executed in the outer one, which is committed once
+	pool := &testPool{tx: &testTransaction{}}
+	err := ExecuteInTransaction(pool, func(pool middleware.Pool) error {
+		conn, err := pool.Get()
+		if err != nil {
+			return err
+		}
+		_, err = conn.ExecuteContext(context.Background(), "select 1;")
+		if err != nil {
+			return err
+		}
+		err = conn.Close()
+		if err != nil {
+			return err
+		}
+
+		return executeInTransaction(pool, "TestExecuteInTransaction()", func(tx middleware.Transaction) error {
+			_, err := tx.ExecuteContext(context.Background(), "select 1;")
+			return err
+		})
+	})
+	asst.Nil(err, "test ExecuteInTransaction() failed")
+	asst.Equal(1, pool.tx.begin, "test ExecuteInTransaction() failed")
+	asst.Equal(1, pool.tx.commit, "test ExecuteInTransaction() failed")
+	asst.Equal(0, pool.tx.rollback, "test ExecuteInTransaction() failed")
+	asst.Equal(1, pool.tx.close, "test ExecuteInTransaction() failed")
+	asst.Equal(2, pool.tx.statements, "test ExecuteInTransaction() failed")
+
+	// the failure of the nested transaction rolls back the outer one
+	pool = &testPool{tx: &testTransaction{}}
+	err = ExecuteInTransaction(pool, func(pool middleware.Pool) error {
+		return executeInTransaction(pool, "TestExecuteInTransaction()", func(tx middleware.Transaction) error {
+			return errors.New("test error")
+		})
+	})
+	asst.NotNil(err, "test ExecuteInTransaction() failed")
+	asst.Equal(0, pool.tx.commit, "test ExecuteInTransaction() failed")
+	asst.Equal(1, pool.tx.rollback, "test ExecuteInTransaction() failed")
+	asst.Equal(1, pool.tx.close, "test ExecuteInTransaction() failed")
+}
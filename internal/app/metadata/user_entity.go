@@ -9,6 +9,12 @@ import (
 	"github.com/romberli/das/internal/dependency/metadata"
 )
 
+const (
+	UserRoleAdmin     = 1
+	UserRoleDBA       = 2
+	UserRoleDeveloper = 3
+)
+
 var _ metadata.User = (*UserInfo)(nil)
 
 // UserInfo create userinfo struct
@@ -1,8 +1,10 @@
 package metadata
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
@@ -11,6 +13,8 @@ import (
 
 	"github.com/romberli/das/global"
 	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/filter"
+	"github.com/romberli/das/pkg/tracing"
 )
 
 var _ metadata.UserRepo = (*UserRepo)(nil)
@@ -33,9 +37,11 @@ func NewUserRepoWithGlobal() *UserRepo {
 // Execute implements dependency.Repository interface,
 // it executes command with arguments on database
 // Execute executes given command and placeholders on the middleware
-func (ur *UserRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+func (ur *UserRepo) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
 	conn, err := ur.Database.Get()
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
 	defer func() {
@@ -45,20 +51,23 @@ func (ur *UserRepo) Execute(command string, args ...interface{}) (middleware.Res
 		}
 	}()
 
-	return conn.Execute(command, args...)
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
 }
 
 // GetByName gets users of given user name from the middleware
-func (ur *UserRepo) GetByName(userName string) ([]metadata.User, error) {
+func (ur *UserRepo) GetByName(ctx context.Context, userName string) ([]metadata.User, error) {
 	sql := `
 	select id, user_name, department_name, employee_id, account_name, email, telephone, mobile, role, del_flag, create_time, last_update_time
 	from t_meta_user_info
 	where del_flag = 0
 	and user_name = ?;
 `
-	log.Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, userName)
+	tracing.Logger(ctx).Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, userName)
 
-	result, err := ur.Execute(sql, userName)
+	result, err := ur.Execute(ctx, sql, userName)
 	if err != nil {
 		return nil, err
 	}
@@ -88,16 +97,16 @@ func (ur *UserRepo) Transaction() (middleware.Transaction, error) {
 }
 
 // GetAll gets all databases from the middleware
-func (ur *UserRepo) GetAll() ([]metadata.User, error) {
+func (ur *UserRepo) GetAll(ctx context.Context) ([]metadata.User, error) {
 	sql := `
 	select id, user_name, department_name, employee_id, account_name, email, telephone, mobile, role, del_flag, create_time, last_update_time
 	from t_meta_user_info
 	where del_flag = 0
 	order by id;
 	`
-	log.Debugf("metadata UserRepo.GetAll() sql: \n%s", sql)
+	tracing.Logger(ctx).Debugf("metadata UserRepo.GetAll() sql: \n%s", sql)
 
-	result, err := ur.Execute(sql)
+	result, err := ur.Execute(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -120,23 +129,49 @@ func (ur *UserRepo) GetAll() ([]metadata.User, error) {
 	return userList, nil
 }
 
+// GetByQuery gets the users which match the query from the middleware,
+// it also returns the total number of the matched users without pagination
+func (ur *UserRepo) GetByQuery(ctx context.Context, query *filter.Query) ([]metadata.User, int, error) {
+	result, total, err := getByQuery(ctx, ur, "UserRepo", userTable, userColumns, query)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []*UserInfo
+	userInfoList := make([]*UserInfo, result.RowNumber())
+	for i := range userInfoList {
+		userInfoList[i] = NewEmptyUserInfoWithGlobal()
+	}
+	// map to struct
+	err = result.MapToStructSlice(userInfoList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, constant.ZeroInt, err
+	}
+	// init []metadata.User
+	entityList := make([]metadata.User, result.RowNumber())
+	for i := range entityList {
+		entityList[i] = userInfoList[i]
+	}
+
+	return entityList, total, nil
+}
+
 // GetByTelephone gets a user of given mobile from the middleware
-func (ur *UserRepo) GetByMobile(mobile string) (metadata.User, error) {
+func (ur *UserRepo) GetByMobile(ctx context.Context, mobile string) (metadata.User, error) {
 	sql := `
 		select id, user_name, department_name, employee_id, account_name, email, telephone, mobile, role, del_flag, create_time, last_update_time
 		from t_meta_user_info
 		where del_flag = 0
 		and mobile = ?;
 `
-	log.Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, mobile)
+	tracing.Logger(ctx).Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, mobile)
 
-	result, err := ur.Execute(sql, mobile)
+	result, err := ur.Execute(ctx, sql, mobile)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata UserInfo.GetByMobile(): data does not exists, id: %s", mobile))
+		return nil, fmt.Errorf("metadata UserInfo.GetByMobile(): %w, id: %s", ErrDataNotExists, mobile)
 	case 1:
 		userInfo := NewEmptyUserInfoWithGlobal()
 		// map to struct
@@ -152,22 +187,22 @@ func (ur *UserRepo) GetByMobile(mobile string) (metadata.User, error) {
 }
 
 // GetByTelephone gets a user of given telephone from the middleware
-func (ur *UserRepo) GetByTelephone(telephone string) (metadata.User, error) {
+func (ur *UserRepo) GetByTelephone(ctx context.Context, telephone string) (metadata.User, error) {
 	sql := `
 		select id, user_name, department_name, employee_id, account_name, email, telephone, mobile, role, del_flag, create_time, last_update_time
 		from t_meta_user_info
 		where del_flag = 0
 		and telephone = ?;
 `
-	log.Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, telephone)
+	tracing.Logger(ctx).Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, telephone)
 
-	result, err := ur.Execute(sql, telephone)
+	result, err := ur.Execute(ctx, sql, telephone)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata UserInfo.GetByTelephone(): data does not exists, id: %s", telephone))
+		return nil, fmt.Errorf("metadata UserInfo.GetByTelephone(): %w, id: %s", ErrDataNotExists, telephone)
 	case 1:
 		userInfo := NewEmptyUserInfoWithGlobal()
 		// map to struct
@@ -183,22 +218,22 @@ func (ur *UserRepo) GetByTelephone(telephone string) (metadata.User, error) {
 }
 
 // GetByID gets a user by the identity from the middleware
-func (ur *UserRepo) GetByID(id int) (metadata.User, error) {
+func (ur *UserRepo) GetByID(ctx context.Context, id int) (metadata.User, error) {
 	sql := `
 		select id, user_name, department_name, employee_id, account_name, email, telephone, mobile, role, del_flag, create_time, last_update_time
 		from t_meta_user_info
 		where del_flag = 0
 		and id = ?;
 	`
-	log.Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, id)
+	tracing.Logger(ctx).Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %d", sql, id)
 
-	result, err := ur.Execute(sql, id)
+	result, err := ur.Execute(ctx, sql, id)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata UserInfo.GetByID(): data does not exists, id: %d", id))
+		return nil, fmt.Errorf("metadata UserInfo.GetByID(): %w, id: %d", ErrDataNotExists, id)
 	case 1:
 		userInfo := NewEmptyUserInfoWithGlobal()
 		// map to struct
@@ -214,22 +249,22 @@ func (ur *UserRepo) GetByID(id int) (metadata.User, error) {
 }
 
 // GetByAccountName gets a user of given account name from the middleware
-func (ur *UserRepo) GetByAccountName(accountName string) (metadata.User, error) {
+func (ur *UserRepo) GetByAccountName(ctx context.Context, accountName string) (metadata.User, error) {
 	sql := `
 	select id, user_name, department_name, employee_id, account_name, email, telephone, mobile, role, del_flag, create_time, last_update_time
 	from t_meta_user_info
 	where del_flag = 0
 	and account_name = ?;
 `
-	log.Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, accountName)
+	tracing.Logger(ctx).Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, accountName)
 
-	result, err := ur.Execute(sql, accountName)
+	result, err := ur.Execute(ctx, sql, accountName)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata UserInfo.GetByAccountName(): data does not exists, id: %s", accountName))
+		return nil, fmt.Errorf("metadata UserInfo.GetByAccountName(): %w, id: %s", ErrDataNotExists, accountName)
 	case 1:
 		userInfo := NewEmptyUserInfoWithGlobal()
 		// map to struct
@@ -245,22 +280,22 @@ func (ur *UserRepo) GetByAccountName(accountName string) (metadata.User, error)
 }
 
 // GetByEmail gets a user of given email from the middleware
-func (ur *UserRepo) GetByEmail(email string) (metadata.User, error) {
+func (ur *UserRepo) GetByEmail(ctx context.Context, email string) (metadata.User, error) {
 	sql := `
 	select id, user_name, department_name, employee_id, account_name, email, telephone, mobile, role, del_flag, create_time, last_update_time
 	from t_meta_user_info
 	where del_flag = 0
 	and email = ?;
 `
-	log.Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, email)
+	tracing.Logger(ctx).Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, email)
 
-	result, err := ur.Execute(sql, email)
+	result, err := ur.Execute(ctx, sql, email)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata UserInfo.GetByEmail(): data does not exists, id: %s", email))
+		return nil, fmt.Errorf("metadata UserInfo.GetByEmail(): %w, id: %s", ErrDataNotExists, email)
 	case 1:
 		userInfo := NewEmptyUserInfoWithGlobal()
 		// map to struct
@@ -276,10 +311,10 @@ func (ur *UserRepo) GetByEmail(email string) (metadata.User, error) {
 }
 
 // GetID gets the identity with given accountName from the middleware
-func (ur *UserRepo) GetID(accountName string) (int, error) {
+func (ur *UserRepo) GetID(ctx context.Context, accountName string) (int, error) {
 	sql := `select id from t_meta_user_info where del_flag = 0 and account_name = ?;`
-	log.Debugf("metadata UserRepo.GetID() select sql: %s", sql)
-	result, err := ur.Execute(sql, accountName)
+	tracing.Logger(ctx).Debugf("metadata UserRepo.GetID() select sql: %s", sql)
+	result, err := ur.Execute(ctx, sql, accountName)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -288,22 +323,22 @@ func (ur *UserRepo) GetID(accountName string) (int, error) {
 }
 
 // GetByEmployeeID gets a user of given employee id from the middleware
-func (ur *UserRepo) GetByEmployeeID(employeeID string) (metadata.User, error) {
+func (ur *UserRepo) GetByEmployeeID(ctx context.Context, employeeID string) (metadata.User, error) {
 	sql := `
 	select id, user_name, department_name, employee_id, account_name, email, telephone, mobile, role, del_flag, create_time, last_update_time
 	from t_meta_user_info
 	where del_flag = 0
 	and employee_id = ?;
 `
-	log.Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, employeeID)
+	tracing.Logger(ctx).Debugf("metadata UserRepo.GetByID() sql: \n%s\nplaceholders: %s", sql, employeeID)
 
-	result, err := ur.Execute(sql, employeeID)
+	result, err := ur.Execute(ctx, sql, employeeID)
 	if err != nil {
 		return nil, err
 	}
 	switch result.RowNumber() {
 	case 0:
-		return nil, errors.New(fmt.Sprintf("metadata UserInfo.GetByEmployeeID(): data does not exists, id: %s", employeeID))
+		return nil, fmt.Errorf("metadata UserInfo.GetByEmployeeID(): %w, id: %s", ErrDataNotExists, employeeID)
 	case 1:
 		userInfo := NewEmptyUserInfoWithGlobal()
 		// map to struct
@@ -319,40 +354,54 @@ func (ur *UserRepo) GetByEmployeeID(employeeID string) (metadata.User, error) {
 }
 
 // Create creates a user in the middleware
-func (ur *UserRepo) Create(user metadata.User) (metadata.User, error) {
+func (ur *UserRepo) Create(ctx context.Context, user metadata.User) (metadata.User, error) {
 	sql := `insert into t_meta_user_info(user_name, department_name, employee_id, account_name, email , telephone , mobile, role) values(?,?,?,?,?,?,?,?);`
-	log.Debugf("metadata UserRepo.Create() insert sql: %s", sql)
+	tracing.Logger(ctx).Debugf("metadata UserRepo.Create() insert sql: %s", sql)
 	// execute
 	userInfo := user.(*UserInfo)
-	_, err := ur.Execute(sql, userInfo.UserName, userInfo.DepartmentName, userInfo.EmployeeID, userInfo.AccountName, userInfo.Email, userInfo.Telephone, userInfo.Mobile, userInfo.Role)
+	_, err := ur.Execute(ctx, sql, userInfo.UserName, userInfo.DepartmentName, userInfo.EmployeeID, userInfo.AccountName, userInfo.Email, userInfo.Telephone, userInfo.Mobile, userInfo.Role)
 	if err != nil {
 		return nil, err
 	}
 	// get id
-	id, err := ur.GetID(user.GetAccountName())
+	id, err := ur.GetID(ctx, user.GetAccountName())
 	if err != nil {
 		return nil, err
 	}
 	// get user
-	return ur.GetByID(id)
+	return ur.GetByID(ctx, id)
 }
 
 // Update updates a user in the middleware
-func (ur *UserRepo) Update(user metadata.User) error {
-	sql := `update t_meta_user_info set user_name = ?, del_flag = ?, department_name = ?, employee_id = ?, account_name = ?, email = ?, telephone = ?, mobile = ?, role = ? where id = ?;`
-	log.Debugf("metadata UserRepo.Update() update sql: %s", sql)
+// it returns a *ConflictError if the row was modified or deleted after the entity had been read
+func (ur *UserRepo) Update(ctx context.Context, user metadata.User) error {
+	sql := `update t_meta_user_info set user_name = ?, del_flag = ?, department_name = ?, employee_id = ?, account_name = ?, email = ?, telephone = ?, mobile = ?, role = ? where id = ? and date_format(last_update_time, '%Y-%m-%d %H:%i:%s.%f') = ?;`
+	tracing.Logger(ctx).Debugf("metadata UserRepo.Update() update sql: %s", sql)
 	userInfo := user.(*UserInfo)
-	_, err := ur.Execute(sql, userInfo.UserName, userInfo.DelFlag, userInfo.DepartmentName, userInfo.EmployeeID, userInfo.AccountName, userInfo.Email, userInfo.Telephone, userInfo.Mobile, userInfo.Role, userInfo.ID)
+	result, err := ur.Execute(ctx, sql, userInfo.UserName, userInfo.DelFlag, userInfo.DepartmentName, userInfo.EmployeeID, userInfo.AccountName, userInfo.Email, userInfo.Telephone, userInfo.Mobile, userInfo.Role, userInfo.ID, getVersion(user.GetLastUpdateTime()))
+	if err != nil {
+		return err
+	}
 
-	return err
+	return checkUpdated(ctx, ur, "UserRepo", userTable, user.Identity(), user.GetLastUpdateTime(), result)
 }
 
-// Delete deletes the user of given id in the middleware
-func (ur *UserRepo) Delete(id int) error {
-	sql := `delete from t_meta_user_info where id = ?;`
-	log.Debugf("metadata UserRepo.Delete() update sql: %s", sql)
-
-	_, err := ur.Execute(sql, id)
+// Delete soft deletes the user in the middleware,
+// it returns a *BlockedError if the user is still referenced by the other metadata,
+// and a *ConflictError if the row was modified after it had been read with given last update time
+func (ur *UserRepo) Delete(ctx context.Context, id int, lastUpdateTime time.Time) error {
+	_, err := deleteByID(ctx, ur, "UserRepo", userTable, id, lastUpdateTime, false)
 
 	return err
 }
+
+// DeleteCascade soft deletes the user and the metadata which reference it in the middleware,
+// it returns the rows which are deleted or detached along with the user
+func (ur *UserRepo) DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]metadata.CascadedRow, error) {
+	return deleteByID(ctx, ur, "UserRepo", userTable, id, lastUpdateTime, true)
+}
+
+// Undelete restores the soft deleted user in the middleware
+func (ur *UserRepo) Undelete(ctx context.Context, id int) error {
+	return undeleteByID(ctx, ur, "UserRepo", userTable, id)
+}
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"testing"
 
 	"github.com/romberli/das/internal/dependency/metadata"
@@ -40,7 +41,7 @@ func createUser() (metadata.User, error) {
 		defaultUserInfoEmail,
 		defaultUserInfoRole,
 	)
-	entity, err := userRepo.Create(userInfo)
+	entity, err := userRepo.Create(context.Background(), userInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +51,7 @@ func createUser() (metadata.User, error) {
 
 func deleteUserByID(id int) error {
 	sql := `delete from t_meta_user_info where id = ?`
-	_, err := userRepo.Execute(sql, id)
+	_, err := userRepo.Execute(context.Background(), sql, id)
 	return err
 }
 
@@ -61,6 +62,7 @@ func TestUserRepoAll(t *testing.T) {
 	TestUserRepo_Create(t)
 	TestUserRepo_Update(t)
 	TestUserRepo_Delete(t)
+	TestUserRepo_Undelete(t)
 	TestUserRepo_GetByName(t)
 	TestUserRepo_GetByAccountName(t)
 	TestUserRepo_GetByEmail(t)
@@ -73,7 +75,7 @@ func TestUserRepoAll(t *testing.T) {
 func TestUserRepo_GetByName(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := userRepo.GetByName(onlineUserName)
+	entity, err := userRepo.GetByName(context.Background(), onlineUserName)
 	asst.Nil(err, common.CombineMessageWithError("test GetByName() failed", err))
 	userName := entity[0].GetUserName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByName() failed", err))
@@ -83,7 +85,7 @@ func TestUserRepo_GetByName(t *testing.T) {
 func TestUserRepo_GetByAccountName(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := userRepo.GetByAccountName(OnlineUserAccountName)
+	entity, err := userRepo.GetByAccountName(context.Background(), OnlineUserAccountName)
 	asst.Nil(err, common.CombineMessageWithError("test GetByAccountName failed", err))
 	userName := entity.GetUserName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByAccountName failed", err))
@@ -93,7 +95,7 @@ func TestUserRepo_GetByAccountName(t *testing.T) {
 func TestUserRepo_GetByEmail(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := userRepo.GetByEmail(OnlineUserEmail)
+	entity, err := userRepo.GetByEmail(context.Background(), OnlineUserEmail)
 	asst.Nil(err, common.CombineMessageWithError("test GetByEmail failed", err))
 	userName := entity.GetUserName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByEmail failed", err))
@@ -104,7 +106,7 @@ func TestUserRepo_GetByEmail(t *testing.T) {
 func TestUserRepo_GetByTelephone(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := userRepo.GetByTelephone(OnlineUserTelephone)
+	entity, err := userRepo.GetByTelephone(context.Background(), OnlineUserTelephone)
 	asst.Nil(err, common.CombineMessageWithError("test GetByTelephone failed", err))
 	userName := entity.GetUserName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByTelephone failed", err))
@@ -114,7 +116,7 @@ func TestUserRepo_GetByTelephone(t *testing.T) {
 func TestUserRepo_GetByMobile(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := userRepo.GetByMobile(OnlineUserMobile)
+	entity, err := userRepo.GetByMobile(context.Background(), OnlineUserMobile)
 	asst.Nil(err, common.CombineMessageWithError("test GetByMobile failed", err))
 	userName := entity.GetUserName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByMobile failed", err))
@@ -124,9 +126,9 @@ func TestUserRepo_GetByMobile(t *testing.T) {
 func TestUserRepo_GetID(t *testing.T) {
 	asst := assert.New(t)
 
-	UserID, err := userRepo.GetID(OnlineUserAccountName)
+	UserID, err := userRepo.GetID(context.Background(), OnlineUserAccountName)
 	asst.Nil(err, common.CombineMessageWithError("test GetID failed", err))
-	entity, err := userRepo.GetByID(UserID)
+	entity, err := userRepo.GetByID(context.Background(), UserID)
 	asst.Nil(err, common.CombineMessageWithError("test GetID failed", err))
 	userAccountName := entity.GetAccountName()
 	asst.Equal(OnlineUserAccountName, userAccountName, "test GetID failed")
@@ -136,7 +138,7 @@ func TestUserRepo_GetID(t *testing.T) {
 func TestUserRepo_GetByEmployeeID(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := userRepo.GetByEmployeeID(OnlineUserEmployeeID)
+	entity, err := userRepo.GetByEmployeeID(context.Background(), OnlineUserEmployeeID)
 	asst.Nil(err, common.CombineMessageWithError("test GetByEmployeeID failed", err))
 	userName := entity.GetUserName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByEmployeeID failed", err))
@@ -147,7 +149,7 @@ func TestUserRepo_Execute(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := `select 1;`
-	result, err := userRepo.Execute(sql)
+	result, err := userRepo.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
 	r, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
@@ -162,7 +164,7 @@ func TestUserRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	err = tx.Begin()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
-	_, err = tx.Execute(sql,
+	_, err = tx.ExecuteContext(context.Background(), sql,
 		defaultUserInfoUserName,
 		defaultUserInfoDepartmentName,
 		defaultUserInfoEmployeeID,
@@ -174,7 +176,7 @@ func TestUserRepo_Transaction(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if inserted
 	sql = `select user_name from t_meta_user_info where user_name=?`
-	result, err := tx.Execute(sql,
+	result, err := tx.ExecuteContext(context.Background(), sql,
 		defaultUserInfoUserName,
 	)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
@@ -186,7 +188,7 @@ func TestUserRepo_Transaction(t *testing.T) {
 	err = tx.Rollback()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if rollbacked
-	entities, err := userRepo.GetAll()
+	entities, err := userRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	for _, entity := range entities {
 		userName := entity.GetUserName()
@@ -201,7 +203,7 @@ func TestUserRepo_Transaction(t *testing.T) {
 func TestUserRepo_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
-	entities, err := userRepo.GetAll()
+	entities, err := userRepo.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
 	userName := entities[0].GetUserName()
 	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
@@ -211,7 +213,7 @@ func TestUserRepo_GetAll(t *testing.T) {
 func TestUserRepo_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
-	entity, err := userRepo.GetByID(2)
+	entity, err := userRepo.GetByID(context.Background(), 2)
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
 	userName := entity.GetUserName()
 	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
@@ -237,9 +239,9 @@ func TestUserRepo_Update(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	err = entity.Set(map[string]interface{}{userNameStruct: newUserName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = userRepo.Update(entity)
+	err = userRepo.Update(context.Background(), entity)
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	entity, err = userRepo.GetByID(entity.Identity())
+	entity, err = userRepo.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	userName := entity.GetUserName()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
@@ -258,3 +260,21 @@ func TestUserRepo_Delete(t *testing.T) {
 	err = deleteUserByID(entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 }
+
+func TestUserRepo_Undelete(t *testing.T) {
+	asst := assert.New(t)
+
+	entity, err := createUser()
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	err = userRepo.Delete(context.Background(), entity.Identity(), entity.GetLastUpdateTime())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = userRepo.GetByID(context.Background(), entity.Identity())
+	asst.NotNil(err, "test Undelete() failed")
+	err = userRepo.Undelete(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	_, err = userRepo.GetByID(context.Background(), entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+	// delete
+	err = deleteUserByID(entity.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test Undelete() failed", err))
+}
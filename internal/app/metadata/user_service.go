@@ -1,11 +1,17 @@
 package metadata
 
 import (
+	"context"
+	"encoding/json"
+
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 
+	"github.com/romberli/das/pkg/filter"
 	"github.com/romberli/das/pkg/message"
 
+	"github.com/romberli/das/internal/app/audit"
+	depaudit "github.com/romberli/das/internal/dependency/audit"
 	"github.com/romberli/das/internal/dependency/metadata"
 )
 
@@ -27,29 +33,63 @@ const (
 type UserService struct {
 	metadata.UserRepo
 	Users []metadata.User `json:"users"`
+	Page  *filter.Page    `json:"page"`
+
+	auditor depaudit.Auditor
+	ifMatch string
 }
 
 // NewUserService returns a new *UserService
 func NewUserService(repo metadata.UserRepo) *UserService {
-	return &UserService{repo, []metadata.User{}}
+	return &UserService{repo, []metadata.User{}, nil, nil, constant.EmptyString}
 }
 
 // NewUserServiceWithDefault returns a new *UserService with default repository
 func NewUserServiceWithDefault() *UserService {
-	return NewUserService(NewUserRepoWithGlobal())
+	s := NewUserService(NewUserRepoWithGlobal())
+	s.SetAuditor(audit.NewAuditorWithGlobal(audit.DefaultActor, constant.EmptyString))
+
+	return s
+}
+
+// SetAuditor sets the auditor which records the changes of the users
+func (us *UserService) SetAuditor(auditor depaudit.Auditor) {
+	us.auditor = auditor
+}
+
+// SetIfMatch sets the entity tags which the user to update or delete must match, it is not checked if ifMatch is empty
+func (us *UserService) SetIfMatch(ifMatch string) {
+	us.ifMatch = ifMatch
 }
 
 // GetAll gets all users
-func (us *UserService) GetAll() error {
+func (us *UserService) GetAll(ctx context.Context) error {
 	var err error
-	us.Users, err = us.UserRepo.GetAll()
+	us.Users, err = us.UserRepo.GetAll(ctx)
 
 	return err
 }
 
+// GetByQuery gets the users which match the query from the middleware, and the pagination information
+func (us *UserService) GetByQuery(ctx context.Context, query *filter.Query) error {
+	users, total, err := us.UserRepo.GetByQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	us.Users = users
+	ids := make([]int, len(users))
+	for i, user := range users {
+		ids[i] = user.Identity()
+	}
+	us.Page = query.GetPage(total, ids)
+
+	return nil
+}
+
 // GetByID gets a user by the identity
-func (us *UserService) GetByID(id int) error {
-	user, err := us.UserRepo.GetByID(id)
+func (us *UserService) GetByID(ctx context.Context, id int) error {
+	user, err := us.UserRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -60,7 +100,7 @@ func (us *UserService) GetByID(id int) error {
 }
 
 // Create creates a user in the middleware
-func (us *UserService) Create(fields map[string]interface{}) error {
+func (us *UserService) Create(ctx context.Context, fields map[string]interface{}) error {
 	// generate new map
 	_, ok := fields[userNameStruct]
 	if !ok {
@@ -95,12 +135,14 @@ func (us *UserService) Create(fields map[string]interface{}) error {
 	}
 
 	// insert into middleware
-	user, err := us.UserRepo.Create(userInfo)
+	user, err := us.UserRepo.Create(ctx, userInfo)
 	if err != nil {
 		return err
 	}
 
 	us.Users = append(us.Users, user)
+	record(ctx, us.auditor, audit.EntityTypeUser, user.Identity(), audit.ActionCreate, nil, user)
+
 	return nil
 }
 
@@ -108,8 +150,16 @@ func (us *UserService) Create(fields map[string]interface{}) error {
 // and then updates its fields that was specified in fields argument,
 // key is the filed name and value is the new field value,
 // it saves the changes to the middleware
-func (us *UserService) Update(id int, fields map[string]interface{}) error {
-	err := us.GetByID(id)
+func (us *UserService) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	err := us.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(us.ifMatch, userTable, id, us.Users[constant.ZeroInt].GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	before, err := us.Users[constant.ZeroInt].MarshalJSON()
 	if err != nil {
 		return err
 	}
@@ -117,13 +167,68 @@ func (us *UserService) Update(id int, fields map[string]interface{}) error {
 	if err != nil {
 		return err
 	}
+	err = us.UserRepo.Update(ctx, us.Users[constant.ZeroInt])
+	if err != nil {
+		return err
+	}
+	record(ctx, us.auditor, audit.EntityTypeUser, id, audit.ActionUpdate, json.RawMessage(before), us.Users[constant.ZeroInt])
 
-	return us.UserRepo.Update(us.Users[constant.ZeroInt])
+	return nil
 }
 
 // Delete deletes the user of given id in the middleware
-func (us *UserService) Delete(id int) error {
-	return us.UserRepo.Delete(id)
+func (us *UserService) Delete(ctx context.Context, id int) error {
+	before, err := us.UserRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(us.ifMatch, userTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	err = us.UserRepo.Delete(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, us.auditor, audit.EntityTypeUser, id, audit.ActionDelete, before, nil)
+
+	return nil
+}
+
+// DeleteCascade deletes the user of given id and the metadata which reference it in the middleware
+func (us *UserService) DeleteCascade(ctx context.Context, id int) error {
+	before, err := us.UserRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = checkIfMatch(us.ifMatch, userTable, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	rows, err := us.UserRepo.DeleteCascade(ctx, id, before.GetLastUpdateTime())
+	if err != nil {
+		return err
+	}
+	record(ctx, us.auditor, audit.EntityTypeUser, id, audit.ActionDeleteCascade, before, nil)
+	recordCascaded(ctx, us.auditor, rows)
+
+	return nil
+}
+
+// Undelete restores the deleted user of given id in the middleware, and then gets it
+func (us *UserService) Undelete(ctx context.Context, id int) error {
+	err := us.UserRepo.Undelete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = us.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	record(ctx, us.auditor, audit.EntityTypeUser, id, audit.ActionUndelete, nil, us.Users[constant.ZeroInt])
+
+	return nil
 }
 
 // Marshal marshals UserService.Users to json bytes
@@ -137,8 +242,8 @@ func (us *UserService) MarshalWithFields(fields ...string) ([]byte, error) {
 }
 
 // GetByAccountName gets a user of given account name
-func (us *UserService) GetByAccountName(accountName string) error {
-	user, err := us.UserRepo.GetByAccountName(accountName)
+func (us *UserService) GetByAccountName(ctx context.Context, accountName string) error {
+	user, err := us.UserRepo.GetByAccountName(ctx, accountName)
 	if err != nil {
 		return err
 	}
@@ -149,8 +254,8 @@ func (us *UserService) GetByAccountName(accountName string) error {
 }
 
 // GetByEmail gets a user of given email
-func (us *UserService) GetByEmail(email string) error {
-	user, err := us.UserRepo.GetByEmail(email)
+func (us *UserService) GetByEmail(ctx context.Context, email string) error {
+	user, err := us.UserRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return err
 	}
@@ -161,8 +266,8 @@ func (us *UserService) GetByEmail(email string) error {
 }
 
 // GetByTelephone gets a user of given telephone
-func (us *UserService) GetByTelephone(telephone string) error {
-	user, err := us.UserRepo.GetByTelephone(telephone)
+func (us *UserService) GetByTelephone(ctx context.Context, telephone string) error {
+	user, err := us.UserRepo.GetByTelephone(ctx, telephone)
 	if err != nil {
 		return err
 	}
@@ -173,8 +278,8 @@ func (us *UserService) GetByTelephone(telephone string) error {
 }
 
 // GetByTelephone gets a user of given mobile
-func (us *UserService) GetByMobile(mobile string) error {
-	user, err := us.UserRepo.GetByMobile(mobile)
+func (us *UserService) GetByMobile(ctx context.Context, mobile string) error {
+	user, err := us.UserRepo.GetByMobile(ctx, mobile)
 	if err != nil {
 		return err
 	}
@@ -185,8 +290,8 @@ func (us *UserService) GetByMobile(mobile string) error {
 }
 
 // GetByEmployeeID gets a user of given employee id
-func (us *UserService) GetByEmployeeID(employeeID string) error {
-	user, err := us.UserRepo.GetByEmployeeID(employeeID)
+func (us *UserService) GetByEmployeeID(ctx context.Context, employeeID string) error {
+	user, err := us.UserRepo.GetByEmployeeID(ctx, employeeID)
 	if err != nil {
 		return err
 	}
@@ -197,9 +302,9 @@ func (us *UserService) GetByEmployeeID(employeeID string) error {
 }
 
 // GetByName gets users of given user name
-func (us *UserService) GetByName(userName string) error {
+func (us *UserService) GetByName(ctx context.Context, userName string) error {
 	var err error
-	us.Users, err = us.UserRepo.GetByName(userName)
+	us.Users, err = us.UserRepo.GetByName(ctx, userName)
 
 	return err
 }
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -30,7 +31,7 @@ func TestUserService_GetByAccountName(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewUserService(userRepo)
-	err := s.GetByAccountName(OnlineUserAccountName)
+	err := s.GetByAccountName(context.Background(), OnlineUserAccountName)
 	asst.Nil(err, "test GetByAccountName() failed")
 	AccountName := s.Users[constant.ZeroInt].GetAccountName()
 	asst.Equal(OnlineUserAccountName, AccountName, "test GetByAccountName() failed")
@@ -40,7 +41,7 @@ func TestUserService_GetByEmail(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewUserService(userRepo)
-	err := s.GetByEmail(OnlineUserEmail)
+	err := s.GetByEmail(context.Background(), OnlineUserEmail)
 	asst.Nil(err, "test GetByEmail() failed")
 	Email := s.Users[constant.ZeroInt].GetEmail()
 	asst.Equal(OnlineUserEmail, Email, "test GetByEmail() failed")
@@ -50,7 +51,7 @@ func TestUserService_GetByTelephone(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewUserService(userRepo)
-	err := s.GetByTelephone(OnlineUserTelephone)
+	err := s.GetByTelephone(context.Background(), OnlineUserTelephone)
 	asst.Nil(err, "test GetByTelephone() failed")
 	Telephone := s.Users[constant.ZeroInt].GetTelephone()
 	asst.Equal(OnlineUserTelephone, Telephone, "test GetByTelephone() failed")
@@ -60,7 +61,7 @@ func TestUserService_GetByMobile(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewUserService(userRepo)
-	err := s.GetByMobile(OnlineUserMobile)
+	err := s.GetByMobile(context.Background(), OnlineUserMobile)
 	asst.Nil(err, "test GetByMobile() failed")
 	Mobile := s.Users[constant.ZeroInt].GetMobile()
 	asst.Equal(OnlineUserMobile, Mobile, "test GetByMobile() failed")
@@ -70,7 +71,7 @@ func TestUserService_GetByEmployeeID(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewUserService(userRepo)
-	err := s.GetByEmployeeID(OnlineUserEmployeeID)
+	err := s.GetByEmployeeID(context.Background(), OnlineUserEmployeeID)
 	asst.Nil(err, "test GetByEmployeeID() failed")
 	EmployeeID := s.Users[constant.ZeroInt].GetEmployeeID()
 	asst.Equal(OnlineUserEmployeeID, EmployeeID, "test GetByEmployeeID() failed")
@@ -79,7 +80,7 @@ func TestUserService_GetUsers(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewUserService(userRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetEnvs() failed")
 	Users := s.GetUsers()
 	asst.Greater(len(Users), constant.ZeroInt, "test GetEnvs() failed")
@@ -89,7 +90,7 @@ func TestUserService_GetAll(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewUserService(userRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, "test GetEnvs() failed")
 	Users := s.GetUsers()
 	asst.Greater(len(Users), constant.ZeroInt, "test GetEnvs() failed")
@@ -99,7 +100,7 @@ func TestUserService_GetByID(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewUserService(userRepo)
-	err := s.GetByID(2)
+	err := s.GetByID(context.Background(), 2)
 	asst.Nil(err, "test GetByID() failed")
 	id := s.Users[constant.ZeroInt].Identity()
 	asst.Equal(2, id, "test GetByID() failed")
@@ -109,7 +110,7 @@ func TestUserService_Create(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewUserService(userRepo)
-	err := s.Create(map[string]interface{}{
+	err := s.Create(context.Background(), map[string]interface{}{
 
 		userNameStruct:       defaultUserInfoUserName,
 		departmentNameStruct: defaultUserInfoDepartmentName,
@@ -132,9 +133,9 @@ func TestUserService_Update(t *testing.T) {
 	entity, err := createUser()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	s := NewUserService(userRepo)
-	err = s.Update(entity.Identity(), map[string]interface{}{userNameStruct: newUserName})
+	err = s.Update(context.Background(), entity.Identity(), map[string]interface{}{userNameStruct: newUserName})
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
 	userName := s.GetUsers()[constant.ZeroInt].GetUserName()
 	asst.Nil(err, common.CombineMessageWithError("test Update() failed", err))
@@ -150,7 +151,7 @@ func TestUserService_Delete(t *testing.T) {
 	entity, err := createUser()
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	s := NewUserService(userRepo)
-	err = s.Delete(entity.Identity())
+	err = s.Delete(context.Background(), entity.Identity())
 	asst.Nil(err, common.CombineMessageWithError("test Delete() failed", err))
 	// delete
 	err = deleteUserByID(entity.Identity())
@@ -163,7 +164,7 @@ func TestUserService_Marshal(t *testing.T) {
 	asst := assert.New(t)
 
 	s := NewUserService(userRepo)
-	err := s.GetAll()
+	err := s.GetAll(context.Background())
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
 	data, err := s.Marshal()
 	asst.Nil(err, common.CombineMessageWithError("test Marshal() failed", err))
@@ -183,7 +184,7 @@ func TestUserService_MarshalWithFields(t *testing.T) {
 	entity, err := createUser()
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	s := NewUserService(userRepo)
-	err = s.GetByID(entity.Identity())
+	err = s.GetByID(context.Background(), entity.Identity())
 	dataService, err := s.MarshalWithFields(userNameStruct)
 	asst.Nil(err, common.CombineMessageWithError("test MarshalWithFields() failed", err))
 	dataEntity, err := entity.MarshalJSONWithFields(userNameStruct)
@@ -0,0 +1,121 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/pkg/tracing"
+)
+
+const (
+	// versionLayout is the layout of the last update time which is used as the version of the rows,
+	// it is the same as the precision of the last_update_time columns
+	versionLayout = "2006-01-02 15:04:05.000000"
+	// versionColumn is the expression which returns the version of the rows
+	versionColumn = "date_format(last_update_time, '%Y-%m-%d %H:%i:%s.%f')"
+	// etagLayout is the layout of the last update time in the entity tags
+	etagLayout = "20060102150405.000000"
+	// anyETag matches any version of the existing row
+	anyETag        = "*"
+	weakETagPrefix = "W/"
+)
+
+// ConflictError is returned when the row was modified after it had been read, or the version does not match the If-Match header
+type ConflictError struct {
+	Table string
+	ID    int
+	// ETag is the current entity tag of the row, it is set only if the row does not match the If-Match header
+	ETag string
+}
+
+// Error implements error interface
+func (ce *ConflictError) Error() string {
+	if ce.ETag == constant.EmptyString {
+		return fmt.Sprintf("%s(id: %d) was modified or deleted by others, get it again and retry", ce.Table, ce.ID)
+	}
+
+	return fmt.Sprintf("%s(id: %d) was modified by others, the current etag is %s, get it again and retry", ce.Table, ce.ID, ce.ETag)
+}
+
+// IsDuplicateKey returns if the error is returned by the middleware because the unique key of the metadata already exists
+func IsDuplicateKey(err error) bool {
+	for err != nil {
+		myErr, ok := err.(*mysql.MyError)
+		if ok {
+			return myErr.Code == mysql.ER_DUP_ENTRY
+		}
+		// the errors of the mysql client are traced with the stack which could only be unwrapped by Cause()
+		causer, ok := err.(interface{ Cause() error })
+		if ok && causer.Cause() != err {
+			err = causer.Cause()
+			continue
+		}
+		err = errors.Unwrap(err)
+	}
+
+	return false
+}
+
+// GetETag returns the entity tag of the entity with given last update time
+func GetETag(lastUpdateTime time.Time) string {
+	return fmt.Sprintf(`"%s"`, lastUpdateTime.Format(etagLayout))
+}
+
+// getVersion returns the version of the row with given last update time
+func getVersion(lastUpdateTime time.Time) string {
+	return lastUpdateTime.Format(versionLayout)
+}
+
+// checkIfMatch returns a *ConflictError if the value of the If-Match header does not match the last update time of the row,
+// the value could be a list of entity tags, * matches any version, it does nothing if ifMatch is empty
+func checkIfMatch(ifMatch, table string, id int, lastUpdateTime time.Time) error {
+	if ifMatch == constant.EmptyString {
+		return nil
+	}
+
+	etag := GetETag(lastUpdateTime)
+	for _, tag := range strings.Split(ifMatch, constant.CommaString) {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), weakETagPrefix)
+		if tag == anyETag || tag == etag {
+			return nil
+		}
+	}
+
+	return &ConflictError{Table: table, ID: id, ETag: etag}
+}
+
+// checkUpdated returns a *ConflictError if the conditional update did not match the row of given version,
+// the row which matched but was not changed because the values were the same is not a conflict
+func checkUpdated(ctx context.Context, repo executor, caller, table string, id int, lastUpdateTime time.Time, result middleware.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected > constant.ZeroInt {
+		return nil
+	}
+
+	version := getVersion(lastUpdateTime)
+	sql := fmt.Sprintf(`select count(*) from %s where id = ? and %s = ?;`, table, versionColumn)
+	tracing.Logger(ctx).Debugf("metadata %s.checkUpdated() select sql: %s\nplaceholders: %d, %s", caller, sql, id, version)
+	result, err = repo.Execute(ctx, sql, id, version)
+	if err != nil {
+		return err
+	}
+	count, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return err
+	}
+	if count == constant.ZeroInt {
+		return &ConflictError{Table: table, ID: id}
+	}
+
+	return nil
+}
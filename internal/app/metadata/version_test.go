@@ -0,0 +1,53 @@
+package metadata
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	pingcaperrors "github.com/pingcap/errors"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionAll(t *testing.T) {
+	TestVersion_GetETag(t)
+	TestVersion_checkIfMatch(t)
+	TestVersion_IsDuplicateKey(t)
+}
+
+func TestVersion_GetETag(t *testing.T) {
+	asst := assert.New(t)
+
+	lastUpdateTime := time.Date(2021, 6, 1, 12, 30, 45, 123456000, time.Local)
+	asst.Equal(`"20210601123045.123456"`, GetETag(lastUpdateTime), "test GetETag() failed")
+	asst.Equal("2021-06-01 12:30:45.123456", getVersion(lastUpdateTime), "test getVersion() failed")
+}
+
+func TestVersion_checkIfMatch(t *testing.T) {
+	asst := assert.New(t)
+
+	lastUpdateTime := time.Date(2021, 6, 1, 12, 30, 45, 123456000, time.Local)
+	for _, ifMatch := range []string{"", "*", `"20210601123045.123456"`, `W/"20210601123045.123456"`, `"20210601000000.000000", "20210601123045.123456"`} {
+		err := checkIfMatch(ifMatch, mysqlServerTable, 1, lastUpdateTime)
+		asst.Nil(err, common.CombineMessageWithError("test checkIfMatch() failed", err))
+	}
+
+	err := checkIfMatch(`"20210601000000.000000"`, mysqlServerTable, 1, lastUpdateTime)
+	asst.Equal(&ConflictError{Table: mysqlServerTable, ID: 1, ETag: `"20210601123045.123456"`}, err, "test checkIfMatch() failed")
+	asst.Equal(`t_meta_mysql_server_info(id: 1) was modified by others, the current etag is "20210601123045.123456", get it again and retry`,
+		err.Error(), "test checkIfMatch() failed")
+}
+
+func TestVersion_IsDuplicateKey(t *testing.T) {
+	asst := assert.New(t)
+
+	dupErr := &mysql.MyError{Code: mysql.ER_DUP_ENTRY, Message: "Duplicate entry 'test' for key 'idx01_env_name'"}
+	asst.True(IsDuplicateKey(dupErr), "test IsDuplicateKey() failed")
+	asst.True(IsDuplicateKey(pingcaperrors.Trace(dupErr)), "test IsDuplicateKey() failed")
+	asst.True(IsDuplicateKey(fmt.Errorf("create env failed. %w", dupErr)), "test IsDuplicateKey() failed")
+	asst.False(IsDuplicateKey(&mysql.MyError{Code: mysql.ER_NO_SUCH_TABLE}), "test IsDuplicateKey() failed")
+	asst.False(IsDuplicateKey(ErrDataNotExists), "test IsDuplicateKey() failed")
+	asst.False(IsDuplicateKey(nil), "test IsDuplicateKey() failed")
+}
@@ -0,0 +1,212 @@
+package monitorsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware/prometheus"
+
+	"github.com/romberli/das/pkg/metrics"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+const (
+	// pmm1MySQLUpQuery returns a series for each mysql service of pmm 1.x, the instance label is the service name
+	pmm1MySQLUpQuery    = `mysql_up`
+	pmm1InstanceLabel   = "instance"
+	pmm2ServicesListURL = "/v1/inventory/Services/List"
+	pmm2NodesListURL    = "/v1/inventory/Nodes/List"
+	pmm2MySQLService    = "MYSQL_SERVICE"
+	httpPrefix          = "http://"
+	httpsPrefix         = "https://"
+	contentTypeJSON     = "application/json"
+
+	defaultHTTPTimeout = 30 * time.Second
+)
+
+// MonitoredService is a mysql service which is monitored by the monitor system,
+// host ip and port number are empty if the monitor system does not expose them
+type MonitoredService struct {
+	ServiceName string `json:"service_name"`
+	NodeID      string `json:"node_id"`
+	NodeName    string `json:"node_name"`
+	HostIP      string `json:"host_ip"`
+	PortNum     int    `json:"port_num"`
+	ClusterName string `json:"cluster_name"`
+}
+
+// HasAddr returns if the host ip and port number of the service are known
+func (ms *MonitoredService) HasAddr() bool {
+	return ms.HostIP != constant.EmptyString && ms.PortNum != constant.ZeroInt
+}
+
+// pmm2Service is the mysql service in the response of the pmm 2.x inventory api
+type pmm2Service struct {
+	ServiceID   string `json:"service_id"`
+	ServiceName string `json:"service_name"`
+	NodeID      string `json:"node_id"`
+	Address     string `json:"address"`
+	Port        int    `json:"port"`
+	Cluster     string `json:"cluster"`
+}
+
+// pmm2Node is the node in the response of the pmm 2.x inventory api
+type pmm2Node struct {
+	NodeID   string `json:"node_id"`
+	NodeName string `json:"node_name"`
+}
+
+// getServicesFromPMM1 returns the mysql services of pmm 1.x from the mysql_up series of prometheus
+func getServicesFromPMM1(ctx context.Context, conn *prometheus.Conn) ([]*MonitoredService, error) {
+	_, span := tracing.StartBackendSpan(ctx, metrics.BackendPrometheus, pmm1MySQLUpQuery)
+	queryStartTime := time.Now()
+	result, err := conn.ExecuteContext(ctx, pmm1MySQLUpQuery)
+	metrics.ObserveBackendQuery(metrics.BackendPrometheus, queryStartTime, err)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePMM1Value(result.Raw.GetValue())
+}
+
+// parsePMM1Value parses the mysql_up series, each series is a mysql service
+func parsePMM1Value(value model.Value) ([]*MonitoredService, error) {
+	vector, ok := value.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("mysql_up query should return a vector, %s is not valid", value.Type().String())
+	}
+
+	serviceMap := make(map[string]*MonitoredService)
+	for _, sample := range vector {
+		serviceName := string(sample.Metric[pmm1InstanceLabel])
+		if serviceName == constant.EmptyString {
+			continue
+		}
+		// in pmm 1.x, the node name is the same as the service name
+		serviceMap[serviceName] = &MonitoredService{ServiceName: serviceName, NodeName: serviceName}
+	}
+
+	return sortServices(serviceMap), nil
+}
+
+// getServicesFromPMM2 returns the mysql services of pmm 2.x from the inventory api,
+// the inventory api is served at the root of the pmm server, so the base url of prometheus is not used
+func getServicesFromPMM2(ctx context.Context, addr, user, pass string) ([]*MonitoredService, error) {
+	client := &http.Client{Timeout: defaultHTTPTimeout}
+
+	servicesBody, err := postPMM2(ctx, client, addr+pmm2ServicesListURL, user, pass, map[string]string{"service_type": pmm2MySQLService})
+	if err != nil {
+		return nil, err
+	}
+	nodesBody, err := postPMM2(ctx, client, addr+pmm2NodesListURL, user, pass, map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePMM2Inventory(servicesBody, nodesBody)
+}
+
+// postPMM2 posts the request to the pmm 2.x api and returns the response body,
+// the url is recorded as the query of the span, the credentials are sent by the basic auth header
+func postPMM2(ctx context.Context, client *http.Client, url, user, pass string, body interface{}) ([]byte, error) {
+	if !strings.HasPrefix(strings.ToLower(url), httpPrefix) && !strings.HasPrefix(strings.ToLower(url), httpsPrefix) {
+		url = httpPrefix + url
+	}
+	ctx, span := tracing.StartBackendSpan(ctx, metrics.BackendPMMAPI, url)
+	queryStartTime := time.Now()
+	respBody, err := doPostPMM2(ctx, client, url, user, pass, body)
+	metrics.ObserveBackendQuery(metrics.BackendPMMAPI, queryStartTime, err)
+	tracing.EndSpan(span, err)
+
+	return respBody, err
+}
+
+// doPostPMM2 sends the post request to the pmm 2.x api, the request is canceled if ctx is done
+func doPostPMM2(ctx context.Context, client *http.Client, url, user, pass string, body interface{}) ([]byte, error) {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentTypeJSON)
+	req.SetBasicAuth(user, pass)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pmm api returns unexpected status. url: %s, status: %s, body: %s", url, resp.Status, string(respBody))
+	}
+
+	return respBody, nil
+}
+
+// parsePMM2Inventory parses the services and nodes responses of the pmm 2.x inventory api
+func parsePMM2Inventory(servicesBody, nodesBody []byte) ([]*MonitoredService, error) {
+	services := make(map[string][]*pmm2Service)
+	err := json.Unmarshal(servicesBody, &services)
+	if err != nil {
+		return nil, err
+	}
+	// the nodes are grouped by node type, such as generic, container and remote
+	nodes := make(map[string][]*pmm2Node)
+	if len(nodesBody) > constant.ZeroInt {
+		err = json.Unmarshal(nodesBody, &nodes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	nodeNames := make(map[string]string)
+	for _, nodeList := range nodes {
+		for _, node := range nodeList {
+			nodeNames[node.NodeID] = node.NodeName
+		}
+	}
+
+	serviceMap := make(map[string]*MonitoredService)
+	for _, service := range services["mysql"] {
+		serviceMap[service.ServiceName] = &MonitoredService{
+			ServiceName: service.ServiceName,
+			NodeID:      service.NodeID,
+			NodeName:    nodeNames[service.NodeID],
+			HostIP:      service.Address,
+			PortNum:     service.Port,
+			ClusterName: service.Cluster,
+		}
+	}
+
+	return sortServices(serviceMap), nil
+}
+
+// sortServices returns the services sorted by the service name
+func sortServices(serviceMap map[string]*MonitoredService) []*MonitoredService {
+	services := make([]*MonitoredService, 0, len(serviceMap))
+	for _, service := range serviceMap {
+		services = append(services, service)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].ServiceName < services[j].ServiceName
+	})
+
+	return services
+}
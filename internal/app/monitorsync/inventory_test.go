@@ -0,0 +1,86 @@
+package monitorsync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPMM2ServicesBody = `{"mysql": [
+		{"service_id": "/service_id/2", "service_name": "host12-mysql", "node_id": "/node_id/2", "address": "192.168.137.12", "port": 3306, "cluster": "cluster1"},
+		{"service_id": "/service_id/1", "service_name": "host11-mysql", "node_id": "/node_id/1", "address": "192.168.137.11", "port": 3306, "cluster": "cluster1"}
+	]}`
+	testPMM2NodesBody = `{"generic": [
+		{"node_id": "/node_id/1", "node_name": "host11"},
+		{"node_id": "/node_id/2", "node_name": "host12"}
+	]}`
+)
+
+func TestInventoryAll(t *testing.T) {
+	TestParsePMM1Value(t)
+	TestParsePMM2Inventory(t)
+	TestGetServicesFromPMM2(t)
+}
+
+func TestParsePMM1Value(t *testing.T) {
+	asst := assert.New(t)
+
+	vector := model.Vector{
+		&model.Sample{Metric: model.Metric{pmm1InstanceLabel: "host12-mysql", "job": "mysql"}, Value: 1},
+		&model.Sample{Metric: model.Metric{pmm1InstanceLabel: "host11-mysql", "job": "mysql"}, Value: 0},
+		&model.Sample{Metric: model.Metric{"job": "mysql"}, Value: 1},
+	}
+	services, err := parsePMM1Value(vector)
+	asst.Nil(err, common.CombineMessageWithError("test parsePMM1Value() failed", err))
+	asst.Equal(2, len(services), "test parsePMM1Value() failed")
+	asst.Equal("host11-mysql", services[0].ServiceName, "test parsePMM1Value() failed")
+	asst.False(services[0].HasAddr(), "test parsePMM1Value() failed")
+
+	_, err = parsePMM1Value(&model.Scalar{Value: 1})
+	asst.NotNil(err, "test parsePMM1Value() failed")
+}
+
+func TestParsePMM2Inventory(t *testing.T) {
+	asst := assert.New(t)
+
+	services, err := parsePMM2Inventory([]byte(testPMM2ServicesBody), []byte(testPMM2NodesBody))
+	asst.Nil(err, common.CombineMessageWithError("test parsePMM2Inventory() failed", err))
+	asst.Equal(2, len(services), "test parsePMM2Inventory() failed")
+	asst.Equal("host11-mysql", services[0].ServiceName, "test parsePMM2Inventory() failed")
+	asst.Equal("host11", services[0].NodeName, "test parsePMM2Inventory() failed")
+	asst.Equal("192.168.137.11", services[0].HostIP, "test parsePMM2Inventory() failed")
+	asst.Equal(3306, services[0].PortNum, "test parsePMM2Inventory() failed")
+	asst.Equal("cluster1", services[0].ClusterName, "test parsePMM2Inventory() failed")
+	asst.True(services[0].HasAddr(), "test parsePMM2Inventory() failed")
+}
+
+func TestGetServicesFromPMM2(t *testing.T) {
+	asst := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == pmm2ServicesListURL {
+			_, _ = w.Write([]byte(testPMM2ServicesBody))
+			return
+		}
+		_, _ = w.Write([]byte(testPMM2NodesBody))
+	}))
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, httpPrefix)
+	services, err := getServicesFromPMM2(context.Background(), addr, "admin", "admin")
+	asst.Nil(err, common.CombineMessageWithError("test getServicesFromPMM2() failed", err))
+	asst.Equal(2, len(services), "test getServicesFromPMM2() failed")
+	asst.Equal("host11", services[0].NodeName, "test getServicesFromPMM2() failed")
+	// the request is canceled with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = getServicesFromPMM2(ctx, addr, "admin", "admin")
+	asst.ErrorIs(err, context.Canceled, "test getServicesFromPMM2() failed")
+}
@@ -0,0 +1,146 @@
+package monitorsync
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/romberli/go-util/constant"
+
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+)
+
+// RegisteredServer is the brief information of a registered mysql server
+type RegisteredServer struct {
+	ID          int    `json:"id"`
+	ClusterID   int    `json:"cluster_id"`
+	ServiceName string `json:"service_name"`
+	HostIP      string `json:"host_ip"`
+	PortNum     int    `json:"port_num"`
+}
+
+// newRegisteredServer returns a new *RegisteredServer
+func newRegisteredServer(mysqlServer depmeta.MySQLServer) *RegisteredServer {
+	return &RegisteredServer{
+		ID:          mysqlServer.Identity(),
+		ClusterID:   mysqlServer.GetClusterID(),
+		ServiceName: mysqlServer.GetServiceName(),
+		HostIP:      mysqlServer.GetHostIP(),
+		PortNum:     mysqlServer.GetPortNum(),
+	}
+}
+
+// Match is a monitored service and the registered mysql server of it
+type Match struct {
+	Service     *MonitoredService `json:"service"`
+	MySQLServer *RegisteredServer `json:"mysql_server"`
+}
+
+// Report is the result of comparing the monitored services of a monitor system with the registered mysql servers
+type Report struct {
+	MonitorSystemID        int                 `json:"monitor_system_id"`
+	SystemType             int                 `json:"system_type"`
+	Matched                []*Match            `json:"matched"`
+	MonitoredNotRegistered []*MonitoredService `json:"monitored_not_registered"`
+	RegisteredNotMonitored []*RegisteredServer `json:"registered_not_monitored"`
+	Created                []*RegisteredServer `json:"created"`
+	Warnings               []string            `json:"warnings"`
+}
+
+// NewReport matches the monitored services with the registered mysql servers,
+// a service is matched by the host ip and port number if they are known, otherwise by the service name,
+// mysqlServers should contain all registered mysql servers, so that a service could be matched wherever it is registered,
+// the mysql servers of the clusters which use the monitor system are expected to be monitored
+func NewReport(monitorSystemID, systemType int, services []*MonitoredService,
+	clusters []depmeta.MySQLCluster, mysqlServers []depmeta.MySQLServer) *Report {
+	report := &Report{
+		MonitorSystemID:        monitorSystemID,
+		SystemType:             systemType,
+		Matched:                []*Match{},
+		MonitoredNotRegistered: []*MonitoredService{},
+		RegisteredNotMonitored: []*RegisteredServer{},
+		Created:                []*RegisteredServer{},
+		Warnings:               []string{},
+	}
+
+	monitoredClusterIDs := make(map[int]bool)
+	for _, cluster := range clusters {
+		if cluster.GetMonitorSystemID() == monitorSystemID {
+			monitoredClusterIDs[cluster.Identity()] = true
+		}
+	}
+
+	addrMap := make(map[string]depmeta.MySQLServer)
+	serviceNameMap := make(map[string]depmeta.MySQLServer)
+	for _, mysqlServer := range mysqlServers {
+		addrMap[getAddr(mysqlServer.GetHostIP(), mysqlServer.GetPortNum())] = mysqlServer
+		serviceNameMap[mysqlServer.GetServiceName()] = mysqlServer
+	}
+
+	matchedIDs := make(map[int]bool)
+	for _, service := range services {
+		var (
+			mysqlServer depmeta.MySQLServer
+			ok          bool
+		)
+		if service.HasAddr() {
+			mysqlServer, ok = addrMap[getAddr(service.HostIP, service.PortNum)]
+		} else {
+			mysqlServer, ok = serviceNameMap[service.ServiceName]
+		}
+		if !ok {
+			report.MonitoredNotRegistered = append(report.MonitoredNotRegistered, service)
+			continue
+		}
+
+		matchedIDs[mysqlServer.Identity()] = true
+		report.Matched = append(report.Matched, &Match{Service: service, MySQLServer: newRegisteredServer(mysqlServer)})
+		if mysqlServer.GetServiceName() != service.ServiceName {
+			// healthcheck queries the monitor system by the registered service name
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"service name of mysql server %d is %s, but it is monitored as %s",
+				mysqlServer.Identity(), mysqlServer.GetServiceName(), service.ServiceName))
+		}
+		if !monitoredClusterIDs[mysqlServer.GetClusterID()] {
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"mysql server %d is monitored, but its mysql cluster %d does not use the monitor system",
+				mysqlServer.Identity(), mysqlServer.GetClusterID()))
+		}
+	}
+
+	for _, mysqlServer := range mysqlServers {
+		if monitoredClusterIDs[mysqlServer.GetClusterID()] && !matchedIDs[mysqlServer.Identity()] {
+			report.RegisteredNotMonitored = append(report.RegisteredNotMonitored, newRegisteredServer(mysqlServer))
+		}
+	}
+	sort.Slice(report.RegisteredNotMonitored, func(i, j int) bool {
+		return report.RegisteredNotMonitored[i].ID < report.RegisteredNotMonitored[j].ID
+	})
+	sort.Strings(report.Warnings)
+
+	return report
+}
+
+// getClusterIDToCreate returns the mysql cluster id which the missing mysql server will be created in,
+// the specified cluster id is preferred, otherwise the cluster name of the service is used to find the registered cluster
+func getClusterIDToCreate(service *MonitoredService, clusterID int, clusters []depmeta.MySQLCluster) (int, error) {
+	if clusterID > constant.ZeroInt {
+		return clusterID, nil
+	}
+	if service.ClusterName == constant.EmptyString {
+		return constant.ZeroInt, fmt.Errorf("mysql cluster id must be specified, service %s does not have a cluster name", service.ServiceName)
+	}
+	for _, cluster := range clusters {
+		if cluster.GetClusterName() == service.ClusterName {
+			return cluster.Identity(), nil
+		}
+	}
+
+	return constant.ZeroInt, fmt.Errorf("mysql cluster %s of service %s is not registered", service.ClusterName, service.ServiceName)
+}
+
+// getAddr returns the address of the host and port
+func getAddr(hostIP string, portNum int) string {
+	return net.JoinHostPort(hostIP, strconv.Itoa(portNum))
+}
@@ -0,0 +1,94 @@
+package monitorsync
+
+import (
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/internal/app/metadata"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+)
+
+const (
+	defaultMonitorSystemID = 1
+	defaultClusterID       = 1
+	defaultClusterName     = "cluster1"
+)
+
+func newTestClusters() []depmeta.MySQLCluster {
+	return []depmeta.MySQLCluster{
+		&metadata.MySQLClusterInfo{ID: defaultClusterID, ClusterName: defaultClusterName, MonitorSystemID: defaultMonitorSystemID},
+		&metadata.MySQLClusterInfo{ID: 2, ClusterName: "cluster2", MonitorSystemID: 2},
+	}
+}
+
+func newTestMySQLServers() []depmeta.MySQLServer {
+	return []depmeta.MySQLServer{
+		&metadata.MySQLServerInfo{ID: 1, ClusterID: defaultClusterID, ServiceName: "host11-mysql", HostIP: "192.168.137.11", PortNum: 3306},
+		&metadata.MySQLServerInfo{ID: 2, ClusterID: defaultClusterID, ServiceName: "host12", HostIP: "192.168.137.12", PortNum: 3306},
+		&metadata.MySQLServerInfo{ID: 3, ClusterID: defaultClusterID, ServiceName: "host13-mysql", HostIP: "192.168.137.13", PortNum: 3306},
+		&metadata.MySQLServerInfo{ID: 4, ClusterID: 2, ServiceName: "host21-mysql", HostIP: "192.168.137.21", PortNum: 3306},
+	}
+}
+
+func TestReportAll(t *testing.T) {
+	TestNewReport_ByAddr(t)
+	TestNewReport_ByServiceName(t)
+	TestGetClusterIDToCreate(t)
+}
+
+func TestNewReport_ByAddr(t *testing.T) {
+	asst := assert.New(t)
+
+	services := []*MonitoredService{
+		{ServiceName: "host11-mysql", HostIP: "192.168.137.11", PortNum: 3306},
+		{ServiceName: "host12-mysql", HostIP: "192.168.137.12", PortNum: 3306},
+		{ServiceName: "host14-mysql", HostIP: "192.168.137.14", PortNum: 3306},
+		{ServiceName: "host21-mysql", HostIP: "192.168.137.21", PortNum: 3306},
+	}
+	report := NewReport(defaultMonitorSystemID, 2, services, newTestClusters(), newTestMySQLServers())
+	asst.Equal(3, len(report.Matched), "test NewReport() failed")
+	asst.Equal(1, len(report.MonitoredNotRegistered), "test NewReport() failed")
+	asst.Equal("host14-mysql", report.MonitoredNotRegistered[0].ServiceName, "test NewReport() failed")
+	asst.Equal(1, len(report.RegisteredNotMonitored), "test NewReport() failed")
+	asst.Equal(3, report.RegisteredNotMonitored[0].ID, "test NewReport() failed")
+	// the service name of mysql server 2 is different, and mysql server 4 belongs to a cluster which uses another monitor system
+	asst.Equal(2, len(report.Warnings), "test NewReport() failed")
+}
+
+func TestNewReport_ByServiceName(t *testing.T) {
+	asst := assert.New(t)
+
+	services := []*MonitoredService{
+		{ServiceName: "host11-mysql", NodeName: "host11-mysql"},
+		{ServiceName: "host12-mysql", NodeName: "host12-mysql"},
+	}
+	report := NewReport(defaultMonitorSystemID, 1, services, newTestClusters(), newTestMySQLServers())
+	asst.Equal(1, len(report.Matched), "test NewReport() failed")
+	asst.Equal(1, report.Matched[0].MySQLServer.ID, "test NewReport() failed")
+	asst.Equal(1, len(report.MonitoredNotRegistered), "test NewReport() failed")
+	asst.Equal(2, len(report.RegisteredNotMonitored), "test NewReport() failed")
+	asst.Equal(0, len(report.Warnings), "test NewReport() failed")
+}
+
+func TestGetClusterIDToCreate(t *testing.T) {
+	asst := assert.New(t)
+
+	service := &MonitoredService{ServiceName: "host14-mysql", HostIP: "192.168.137.14", PortNum: 3306}
+	clusterID, err := getClusterIDToCreate(service, 2, newTestClusters())
+	asst.Nil(err, common.CombineMessageWithError("test getClusterIDToCreate() failed", err))
+	asst.Equal(2, clusterID, "test getClusterIDToCreate() failed")
+	// cluster id is not specified and the service does not have a cluster name
+	_, err = getClusterIDToCreate(service, 0, newTestClusters())
+	asst.NotNil(err, "test getClusterIDToCreate() failed")
+
+	service.ClusterName = defaultClusterName
+	clusterID, err = getClusterIDToCreate(service, 0, newTestClusters())
+	asst.Nil(err, common.CombineMessageWithError("test getClusterIDToCreate() failed", err))
+	asst.Equal(defaultClusterID, clusterID, "test getClusterIDToCreate() failed")
+
+	service.ClusterName = "cluster3"
+	_, err = getClusterIDToCreate(service, 0, newTestClusters())
+	asst.NotNil(err, "test getClusterIDToCreate() failed")
+}
@@ -0,0 +1,183 @@
+package monitorsync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware/prometheus"
+	"github.com/romberli/log"
+	"github.com/spf13/viper"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/metadata"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/internal/dependency/monitorsync"
+)
+
+const (
+	ReportsStruct = "Reports"
+
+	// DefaultDeploymentType is the physical machine
+	DefaultDeploymentType = 2
+
+	clusterIDStruct      = "ClusterID"
+	serverNameStruct     = "ServerName"
+	serviceNameStruct    = "ServiceName"
+	hostIPStruct         = "HostIP"
+	portNumStruct        = "PortNum"
+	deploymentTypeStruct = "DeploymentType"
+	versionStruct        = "Version"
+)
+
+var _ monitorsync.Service = (*Service)(nil)
+
+type Service struct {
+	Reports []*Report `json:"reports"`
+}
+
+// NewService returns a new *Service
+func NewService() *Service {
+	return &Service{Reports: []*Report{}}
+}
+
+// NewServiceWithDefault returns a new *Service
+func NewServiceWithDefault() *Service {
+	return NewService()
+}
+
+// GetReports returns the reports of the service
+func (s *Service) GetReports() []*Report {
+	return s.Reports
+}
+
+// SyncByMonitorSystem pulls the monitored mysql services from the monitor system,
+// matches them with the registered mysql servers and reports the differences,
+// if create is true, the monitored but not registered mysql servers will be created in the mysql cluster of clusterID,
+// or in the registered mysql cluster which has the same name as the cluster of the service if clusterID is 0,
+// a service which could not be created is reported as a warning and does not stop creating the others
+func (s *Service) SyncByMonitorSystem(ctx context.Context, monitorSystemID int, create bool, clusterID int) error {
+	monitorSystemService := metadata.NewMonitorSystemServiceWithDefault()
+	err := monitorSystemService.GetByID(ctx, monitorSystemID)
+	if err != nil {
+		return err
+	}
+	monitorSystem := monitorSystemService.GetMonitorSystems()[constant.ZeroInt]
+
+	services, err := s.getServices(ctx, monitorSystem)
+	if err != nil {
+		return err
+	}
+
+	mysqlClusterService := metadata.NewMySQLClusterServiceWithDefault()
+	err = mysqlClusterService.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err = mysqlServerService.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	report := NewReport(monitorSystemID, monitorSystem.GetSystemType(), services,
+		mysqlClusterService.GetMySQLClusters(), mysqlServerService.GetMySQLServers())
+	s.Reports = []*Report{report}
+	if create {
+		s.create(ctx, report, clusterID, mysqlClusterService.GetMySQLClusters())
+	}
+
+	return nil
+}
+
+// GetMonitoredServices returns the mysql services which are monitored by the monitor system of given id
+func GetMonitoredServices(ctx context.Context, monitorSystemID int) ([]*MonitoredService, error) {
+	monitorSystemService := metadata.NewMonitorSystemServiceWithDefault()
+	err := monitorSystemService.GetByID(ctx, monitorSystemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewService().getServices(ctx, monitorSystemService.GetMonitorSystems()[constant.ZeroInt])
+}
+
+// getServices returns the mysql services which are monitored by the monitor system
+func (s *Service) getServices(ctx context.Context, monitorSystem depmeta.MonitorSystem) ([]*MonitoredService, error) {
+	switch monitorSystem.GetSystemType() {
+	case 1:
+		// pmm 1.x
+		prometheusAddr := fmt.Sprintf("%s:%d", monitorSystem.GetHostIP(), monitorSystem.GetPortNum())
+		prometheusConfig := prometheus.NewConfig(prometheusAddr, prometheus.DefaultRoundTripper)
+		conn, err := prometheus.NewConnWithConfig(prometheusConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		return getServicesFromPMM1(ctx, conn)
+	case 2:
+		// pmm 2.x
+		addr := fmt.Sprintf("%s:%d", monitorSystem.GetHostIP(), monitorSystem.GetPortNum())
+
+		return getServicesFromPMM2(ctx, addr, viper.GetString(config.DBMonitorPrometheusUserKey), viper.GetString(config.DBMonitorPrometheusPassKey))
+	default:
+		return nil, fmt.Errorf("monitor system type should be either 1 or 2, %d is not valid", monitorSystem.GetSystemType())
+	}
+}
+
+// create creates the monitored but not registered mysql servers,
+// the version and the server role are unknown, they could be corrected by discovering the mysql cluster
+func (s *Service) create(ctx context.Context, report *Report, clusterID int, clusters []depmeta.MySQLCluster) {
+	var notRegistered []*MonitoredService
+	for _, service := range report.MonitoredNotRegistered {
+		if !service.HasAddr() {
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"service %s could not be created, the monitor system does not expose its host ip and port number", service.ServiceName))
+			notRegistered = append(notRegistered, service)
+			continue
+		}
+		targetClusterID, err := getClusterIDToCreate(service, clusterID, clusters)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("service %s could not be created. error: %s", service.ServiceName, err.Error()))
+			notRegistered = append(notRegistered, service)
+			continue
+		}
+
+		serverName := service.NodeName
+		if serverName == constant.EmptyString {
+			serverName = service.HostIP
+		}
+		mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+		err = mysqlServerService.Create(ctx, map[string]interface{}{
+			clusterIDStruct:      targetClusterID,
+			serverNameStruct:     serverName,
+			serviceNameStruct:    service.ServiceName,
+			hostIPStruct:         service.HostIP,
+			portNumStruct:        service.PortNum,
+			deploymentTypeStruct: DefaultDeploymentType,
+			versionStruct:        constant.EmptyString,
+		})
+		if err != nil {
+			log.Errorf("monitorsync Service.create(): create mysql server failed. service name: %s\n%s", service.ServiceName, err.Error())
+			report.Warnings = append(report.Warnings, fmt.Sprintf("service %s could not be created. error: %s", service.ServiceName, err.Error()))
+			notRegistered = append(notRegistered, service)
+			continue
+		}
+		report.Created = append(report.Created, newRegisteredServer(mysqlServerService.GetMySQLServers()[constant.ZeroInt]))
+	}
+
+	if notRegistered == nil {
+		notRegistered = []*MonitoredService{}
+	}
+	report.MonitoredNotRegistered = notRegistered
+}
+
+// Marshal marshals Service to json bytes
+func (s *Service) Marshal() ([]byte, error) {
+	return s.MarshalWithFields(ReportsStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the Service to json bytes
+func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(s, fields...)
+}
@@ -0,0 +1,68 @@
+package probe
+
+import (
+	"context"
+	"errors"
+
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/dependency/probe"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+var _ probe.Repository = (*Repository)(nil)
+
+type Repository struct {
+	Database middleware.Pool
+}
+
+// NewRepository returns *Repository with given middleware.Pool
+func NewRepository(db middleware.Pool) *Repository {
+	return &Repository{Database: db}
+}
+
+// NewRepositoryWithGlobal returns *Repository with global mysql pool
+func NewRepositoryWithGlobal() *Repository {
+	// the global pool is nil if it is not initialized yet
+	if global.DASMySQLPool == nil {
+		return NewRepository(nil)
+	}
+
+	return NewRepository(global.DASMySQLPool)
+}
+
+// Execute executes given command and placeholders on the middleware
+func (r *Repository) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	if r.Database == nil {
+		return nil, errors.New("connection pool is not initialized")
+	}
+	_, span := tracing.StartSQLSpan(ctx, command)
+	conn, err := r.Database.Get()
+	if err != nil {
+		tracing.EndSpan(span, err)
+		return nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("probe Repository.Execute(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
+}
+
+// Ping checks if the middleware is available
+func (r *Repository) Ping(ctx context.Context) error {
+	sql := `select 1;`
+	tracing.Logger(ctx).Debugf("probe Repository.Ping() sql: \n%s", sql)
+
+	_, err := r.Execute(ctx, sql)
+
+	return err
+}
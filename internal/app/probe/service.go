@@ -0,0 +1,204 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/spf13/viper"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/probe"
+)
+
+const (
+	StatusStruct       = "Status"
+	DependenciesStruct = "Dependencies"
+
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+
+	DependencyStatusOK     = "ok"
+	DependencyStatusFailed = "failed"
+
+	DependencyDASMySQL       = "das_mysql"
+	DependencySoarBin        = "soar_bin"
+	DependencySoarConfig     = "soar_config"
+	DependencyMonitorSystems = "monitor_systems"
+	// the dependency name of a monitor system is monitor_system:<system name>
+	dependencyMonitorSystemPrefix = "monitor_system:"
+
+	defaultDialTimeout = 3 * time.Second
+	executableMode     = 0111
+)
+
+var _ probe.Service = (*Service)(nil)
+
+// Dependency is the check result of a dependency of das
+type Dependency struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Message   string  `json:"message,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// newDependency runs the check and returns the result of the dependency
+func newDependency(name string, check func() error) *Dependency {
+	startTime := time.Now()
+	err := check()
+	d := &Dependency{
+		Name:      name,
+		Status:    DependencyStatusOK,
+		LatencyMS: float64(time.Since(startTime).Microseconds()) / 1000,
+	}
+	if err != nil {
+		d.Status = DependencyStatusFailed
+		d.Message = err.Error()
+	}
+
+	return d
+}
+
+type Service struct {
+	probe.Repository
+	Status       string        `json:"status"`
+	Dependencies []*Dependency `json:"dependencies"`
+}
+
+// NewService returns a new *Service
+func NewService(repo probe.Repository) *Service {
+	return &Service{
+		Repository:   repo,
+		Status:       StatusNotReady,
+		Dependencies: []*Dependency{},
+	}
+}
+
+// NewServiceWithDefault returns a new *Service with default repository
+func NewServiceWithDefault() *Service {
+	return NewService(NewRepositoryWithGlobal())
+}
+
+// GetDependencies returns the check results of the dependencies
+func (s *Service) GetDependencies() []*Dependency {
+	return s.Dependencies
+}
+
+// Check checks the metadata database, the soar binary and config file,
+// the monitor systems are checked only if checkMonitorSystems is true, because they are not necessary for most of the apis
+func (s *Service) Check(ctx context.Context, checkMonitorSystems bool) {
+	s.Dependencies = []*Dependency{
+		newDependency(DependencyDASMySQL, func() error { return s.Repository.Ping(ctx) }),
+		newDependency(DependencySoarBin, func() error { return checkExecutable(viper.GetString(config.SQLAdvisorSoarBin)) }),
+		newDependency(DependencySoarConfig, func() error { return checkFile(viper.GetString(config.SQLAdvisorSoarConfig)) }),
+	}
+	if checkMonitorSystems {
+		s.Dependencies = append(s.Dependencies, s.checkMonitorSystems(ctx)...)
+	}
+
+	s.Status = StatusReady
+	if len(s.GetFailedDependencies()) > constant.ZeroInt {
+		s.Status = StatusNotReady
+	}
+}
+
+// IsReady returns if all the checked dependencies are available
+func (s *Service) IsReady() bool {
+	return s.Status == StatusReady
+}
+
+// GetFailedDependencies returns the names of the unavailable dependencies
+func (s *Service) GetFailedDependencies() []string {
+	var names []string
+	for _, d := range s.Dependencies {
+		if d.Status != DependencyStatusOK {
+			names = append(names, d.Name)
+		}
+	}
+
+	return names
+}
+
+// checkMonitorSystems checks if the registered monitor systems are reachable concurrently
+func (s *Service) checkMonitorSystems(ctx context.Context) []*Dependency {
+	monitorSystemService := metadata.NewMonitorSystemServiceWithDefault()
+	err := monitorSystemService.GetAll(ctx)
+	if err != nil {
+		return []*Dependency{newDependency(DependencyMonitorSystems, func() error { return err })}
+	}
+
+	monitorSystems := monitorSystemService.GetMonitorSystems()
+	dependencies := make([]*Dependency, len(monitorSystems))
+	wg := &sync.WaitGroup{}
+	for i, monitorSystem := range monitorSystems {
+		wg.Add(1)
+		go func(i int, name, addr string) {
+			defer wg.Done()
+			dependencies[i] = newDependency(dependencyMonitorSystemPrefix+name, func() error { return checkReachable(ctx, addr) })
+		}(i, monitorSystem.GetSystemName(), net.JoinHostPort(monitorSystem.GetHostIP(), strconv.Itoa(monitorSystem.GetPortNum())))
+	}
+	wg.Wait()
+
+	return dependencies
+}
+
+// Marshal marshals Service to json bytes
+func (s *Service) Marshal() ([]byte, error) {
+	return s.MarshalWithFields(StatusStruct, DependenciesStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the Service to json bytes
+func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(s, fields...)
+}
+
+// checkFile checks if the file exists and is a regular file
+func checkFile(path string) error {
+	_, err := statRegularFile(path)
+
+	return err
+}
+
+// checkExecutable checks if the file exists and is executable
+func checkExecutable(path string) error {
+	info, err := statRegularFile(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&executableMode == constant.ZeroInt {
+		return fmt.Errorf("%s is not executable", path)
+	}
+
+	return nil
+}
+
+// statRegularFile returns the file info of the path, it returns error if the path is not a regular file
+func statRegularFile(path string) (os.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+
+	return info, nil
+}
+
+// checkReachable checks if the tcp address could be connected
+func checkReachable(ctx context.Context, addr string) error {
+	dialer := &net.Dialer{Timeout: defaultDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("could not connect to %s.\n%s", addr, err.Error())
+	}
+
+	return conn.Close()
+}
@@ -0,0 +1,89 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/dependency/probe"
+)
+
+type testPingRepo struct {
+	probe.Repository
+	err error
+}
+
+func (tpr *testPingRepo) Ping(ctx context.Context) error {
+	return tpr.err
+}
+
+func TestServiceAll(t *testing.T) {
+	TestCheckFile(t)
+	TestCheckReachable(t)
+	TestService_Check(t)
+}
+
+func TestCheckFile(t *testing.T) {
+	asst := assert.New(t)
+
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "soar")
+	cfg := filepath.Join(dir, "soar.yaml")
+	asst.Nil(ioutil.WriteFile(bin, []byte("#!/bin/sh\n"), 0755), "test CheckFile() failed")
+	asst.Nil(ioutil.WriteFile(cfg, []byte("online-dsn:\n"), 0644), "test CheckFile() failed")
+
+	asst.Nil(checkFile(cfg), "test CheckFile() failed")
+	asst.NotNil(checkFile(dir), "test CheckFile() failed")
+	asst.NotNil(checkFile(filepath.Join(dir, "not_exists")), "test CheckFile() failed")
+	asst.Nil(checkExecutable(bin), "test CheckFile() failed")
+	asst.NotNil(checkExecutable(cfg), "test CheckFile() failed")
+}
+
+func TestCheckReachable(t *testing.T) {
+	asst := assert.New(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	asst.Nil(err, "test CheckReachable() failed")
+	addr := listener.Addr().String()
+	asst.Nil(checkReachable(context.Background(), addr), "test CheckReachable() failed")
+	asst.Nil(listener.Close(), "test CheckReachable() failed")
+	asst.NotNil(checkReachable(context.Background(), addr), "test CheckReachable() failed")
+}
+
+func TestService_Check(t *testing.T) {
+	asst := assert.New(t)
+
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "soar")
+	cfg := filepath.Join(dir, "soar.yaml")
+	asst.Nil(ioutil.WriteFile(bin, []byte("#!/bin/sh\n"), 0755), "test Check() failed")
+	asst.Nil(ioutil.WriteFile(cfg, []byte("online-dsn:\n"), 0644), "test Check() failed")
+	viper.Set(config.SQLAdvisorSoarBin, bin)
+	viper.Set(config.SQLAdvisorSoarConfig, cfg)
+
+	// all the dependencies are available
+	s := NewService(&testPingRepo{})
+	s.Check(context.Background(), false)
+	asst.True(s.IsReady(), "test Check() failed")
+	asst.Equal(3, len(s.GetDependencies()), "test Check() failed")
+	asst.Empty(s.GetFailedDependencies(), "test Check() failed")
+	jsonBytes, err := s.Marshal()
+	asst.Nil(err, "test Check() failed")
+	asst.Contains(string(jsonBytes), `"status":"ready"`, "test Check() failed")
+
+	// the metadata database and the soar config are not available
+	asst.Nil(os.Remove(cfg), "test Check() failed")
+	s = NewService(&testPingRepo{err: errors.New("connection refused")})
+	s.Check(context.Background(), false)
+	asst.False(s.IsReady(), "test Check() failed")
+	asst.Equal([]string{DependencyDASMySQL, DependencySoarConfig}, s.GetFailedDependencies(), "test Check() failed")
+	asst.Equal("connection refused", s.GetDependencies()[0].Message, "test Check() failed")
+}
@@ -0,0 +1,256 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/go-util/middleware/clickhouse"
+	"github.com/romberli/go-util/middleware/mysql"
+
+	"github.com/romberli/das/pkg/metrics"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+const (
+	OrderByTotalExecTime   = "total_exec_time"
+	OrderByAvgExecTime     = "avg_exec_time"
+	OrderByRowsExaminedMax = "rows_examined_max"
+	OrderByExecCount       = "exec_count"
+)
+
+// NoLimit is the limit which returns all the slow queries
+const NoLimit = constant.ZeroInt
+
+var ValidOrderBys = []string{OrderByTotalExecTime, OrderByAvgExecTime, OrderByRowsExaminedMax, OrderByExecCount}
+
+// Querier gets slow queries from the query analytics of the monitor system,
+// pmm 1.x stores them in mysql, pmm 2.x stores them in clickhouse
+type Querier struct {
+	pmmVersion            int
+	monitorMySQLConn      *mysql.Conn
+	monitorClickhouseConn *clickhouse.Conn
+}
+
+// NewQuerier returns a new *Querier
+func NewQuerier(pmmVersion int, monitorMySQLConn *mysql.Conn, monitorClickhouseConn *clickhouse.Conn) *Querier {
+	return &Querier{
+		pmmVersion:            pmmVersion,
+		monitorMySQLConn:      monitorMySQLConn,
+		monitorClickhouseConn: monitorClickhouseConn,
+	}
+}
+
+// GetTop returns the top n slow queries of the given service in the given time window ordered by orderBy descending,
+// if dbName is empty, slow queries of all databases will be returned, if limit is NoLimit, all slow queries will be returned
+func (q *Querier) GetTop(ctx context.Context, serviceName, dbName string, startTime, endTime time.Time, orderBy string, limit int) ([]*SlowQuery, error) {
+	valid, err := common.ElementInSlice(ValidOrderBys, orderBy)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, fmt.Errorf("order by should be one of %v, %s is not valid", ValidOrderBys, orderBy)
+	}
+
+	var (
+		sql    string
+		result middleware.Result
+	)
+
+	args := []interface{}{serviceName, startTime, endTime}
+
+	switch q.pmmVersion {
+	case 1:
+		sql = `
+			select qc.checksum    as sql_id,
+				   qc.fingerprint,
+				   qe.query       as example,
+				   qe.db          as db_name,
+				   m.exec_count,
+				   m.total_exec_time,
+				   m.avg_exec_time,
+				   m.rows_examined_max
+			from (
+					 select qcm.query_class_id,
+							sum(qcm.query_count)                                        as exec_count,
+							truncate(sum(qcm.query_time_sum), 2)                        as total_exec_time,
+							truncate(sum(qcm.query_time_sum) / sum(qcm.query_count), 2) as avg_exec_time,
+							max(qcm.rows_examined_max)                                  as rows_examined_max
+					 from query_class_metrics qcm
+							  inner join instances i on qcm.instance_id = i.instance_id
+					 where i.name = ?
+					   and qcm.start_ts >= ?
+					   and qcm.start_ts < ?
+					 group by qcm.query_class_id) m
+					 inner join query_classes qc on m.query_class_id = qc.query_class_id
+					 inner join (
+				select query_class_id, max(db) as db, max(query) as query
+				from query_examples
+				group by query_class_id) qe on m.query_class_id = qe.query_class_id
+		`
+		if dbName != constant.EmptyString {
+			sql += ` where qe.db = ? `
+			args = append(args, dbName)
+		}
+		sql += fmt.Sprintf(` order by m.%s desc `, orderBy)
+		if limit > NoLimit {
+			sql += ` limit ? `
+			args = append(args, limit)
+		}
+		tracing.Logger(ctx).Debugf("query Querier.GetTop() sql: \n%s\nplaceholders: %v", sql, args)
+
+		_, span := tracing.StartBackendSpan(ctx, metrics.BackendPMMMySQL, sql)
+		queryStartTime := time.Now()
+		result, err = q.monitorMySQLConn.ExecuteContext(ctx, sql, args...)
+		metrics.ObserveBackendQuery(metrics.BackendPMMMySQL, queryStartTime, err)
+		tracing.EndSpan(span, err)
+	case 2:
+		sql = `
+			select queryid                                               as sql_id,
+				   fingerprint,
+				   any(example)                                          as example,
+				   database                                              as db_name,
+				   sum(num_queries)                                      as exec_count,
+				   truncate(sum(m_query_time_sum), 2)                    as total_exec_time,
+				   truncate(sum(m_query_time_sum) / sum(num_queries), 2) as avg_exec_time,
+				   max(m_rows_examined_max)                              as rows_examined_max
+			from metrics
+			where service_type = 'mysql'
+			  and service_name = ?
+			  and period_start >= ?
+			  and period_start < ?
+		`
+		if dbName != constant.EmptyString {
+			sql += ` and database = ? `
+			args = append(args, dbName)
+		}
+		sql += fmt.Sprintf(` group by queryid, fingerprint, database order by %s desc `, orderBy)
+		if limit > NoLimit {
+			sql += ` limit ? `
+			args = append(args, limit)
+		}
+		tracing.Logger(ctx).Debugf("query Querier.GetTop() sql: \n%s\nplaceholders: %v", sql, args)
+
+		_, span := tracing.StartBackendSpan(ctx, metrics.BackendClickhouse, sql)
+		queryStartTime := time.Now()
+		result, err = q.monitorClickhouseConn.ExecuteContext(ctx, sql, args...)
+		metrics.ObserveBackendQuery(metrics.BackendClickhouse, queryStartTime, err)
+		tracing.EndSpan(span, err)
+	default:
+		return nil, errors.New(fmt.Sprintf("pmm version should be 1 or 2, %d is not valid", q.pmmVersion))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slowQueries := make([]*SlowQuery, result.RowNumber())
+	for i := range slowQueries {
+		slowQueries[i] = &SlowQuery{}
+	}
+	err = result.MapToStructSlice(slowQueries, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return slowQueries, nil
+}
+
+// GetTrend returns the metrics of the given sql id of the given service in the given time window, grouped by the period of the monitor system
+func (q *Querier) GetTrend(ctx context.Context, serviceName, sqlID string, startTime, endTime time.Time) ([]*TrendPoint, error) {
+	var (
+		sql    string
+		result middleware.Result
+		err    error
+	)
+
+	switch q.pmmVersion {
+	case 1:
+		sql = `
+			select qcm.start_ts                                                as period_start,
+				   sum(qcm.query_count)                                        as exec_count,
+				   truncate(sum(qcm.query_time_sum), 2)                        as total_exec_time,
+				   truncate(sum(qcm.query_time_sum) / sum(qcm.query_count), 2) as avg_exec_time,
+				   max(qcm.rows_examined_max)                                  as rows_examined_max
+			from query_class_metrics qcm
+					 inner join instances i on qcm.instance_id = i.instance_id
+					 inner join query_classes qc on qcm.query_class_id = qc.query_class_id
+			where i.name = ?
+			  and qc.checksum = ?
+			  and qcm.start_ts >= ?
+			  and qcm.start_ts < ?
+			group by qcm.start_ts
+			order by qcm.start_ts;
+		`
+		tracing.Logger(ctx).Debugf("query Querier.GetTrend() sql: \n%s\nplaceholders: %s, %s, %s, %s", sql, serviceName, sqlID, startTime, endTime)
+
+		_, span := tracing.StartBackendSpan(ctx, metrics.BackendPMMMySQL, sql)
+		queryStartTime := time.Now()
+		result, err = q.monitorMySQLConn.ExecuteContext(ctx, sql, serviceName, sqlID, startTime, endTime)
+		metrics.ObserveBackendQuery(metrics.BackendPMMMySQL, queryStartTime, err)
+		tracing.EndSpan(span, err)
+	case 2:
+		sql = `
+			select period_start,
+				   sum(num_queries)                                      as exec_count,
+				   truncate(sum(m_query_time_sum), 2)                    as total_exec_time,
+				   truncate(sum(m_query_time_sum) / sum(num_queries), 2) as avg_exec_time,
+				   max(m_rows_examined_max)                              as rows_examined_max
+			from metrics
+			where service_type = 'mysql'
+			  and service_name = ?
+			  and queryid = ?
+			  and period_start >= ?
+			  and period_start < ?
+			group by period_start
+			order by period_start;
+		`
+		tracing.Logger(ctx).Debugf("query Querier.GetTrend() sql: \n%s\nplaceholders: %s, %s, %s, %s", sql, serviceName, sqlID, startTime, endTime)
+
+		_, span := tracing.StartBackendSpan(ctx, metrics.BackendClickhouse, sql)
+		queryStartTime := time.Now()
+		result, err = q.monitorClickhouseConn.ExecuteContext(ctx, sql, serviceName, sqlID, startTime, endTime)
+		metrics.ObserveBackendQuery(metrics.BackendClickhouse, queryStartTime, err)
+		tracing.EndSpan(span, err)
+	default:
+		return nil, errors.New(fmt.Sprintf("pmm version should be 1 or 2, %d is not valid", q.pmmVersion))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	trend := make([]*TrendPoint, result.RowNumber())
+	for i := range trend {
+		trend[i] = &TrendPoint{}
+	}
+	err = result.MapToStructSlice(trend, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return trend, nil
+}
+
+// Close closes the connections to the monitor system
+func (q *Querier) Close() error {
+	merr := &multierror.Error{}
+
+	if q.monitorMySQLConn != nil {
+		err := q.monitorMySQLConn.Close()
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+	if q.monitorClickhouseConn != nil {
+		err := q.monitorClickhouseConn.Close()
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
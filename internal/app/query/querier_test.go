@@ -0,0 +1,41 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+const defaultServiceName = "service1"
+
+func TestQuerierAll(t *testing.T) {
+	TestQuerier_GetTop(t)
+	TestQuerier_GetTrend(t)
+}
+
+func TestQuerier_GetTop(t *testing.T) {
+	asst := assert.New(t)
+
+	endTime := time.Now()
+	startTime := endTime.Add(-time.Hour)
+
+	querier := NewQuerier(constant.ZeroInt, nil, nil)
+	_, err := querier.GetTop(context.Background(), defaultServiceName, defaultDBName, startTime, endTime, "not_valid_column", defaultLimit)
+	asst.NotNil(err, "test GetTop() failed")
+	_, err = querier.GetTop(context.Background(), defaultServiceName, defaultDBName, startTime, endTime, OrderByTotalExecTime, defaultLimit)
+	asst.NotNil(err, "test GetTop() failed")
+}
+
+func TestQuerier_GetTrend(t *testing.T) {
+	asst := assert.New(t)
+
+	endTime := time.Now()
+	startTime := endTime.Add(-time.Hour)
+
+	querier := NewQuerier(constant.ZeroInt, nil, nil)
+	_, err := querier.GetTrend(context.Background(), defaultServiceName, defaultSQLID, startTime, endTime)
+	asst.NotNil(err, "test GetTrend() failed")
+}
@@ -0,0 +1,59 @@
+package query
+
+import (
+	"time"
+)
+
+// SlowQuery is the aggregated slow query of a fingerprint in a time window
+type SlowQuery struct {
+	SQLID           string  `middleware:"sql_id" json:"sql_id"`
+	Fingerprint     string  `middleware:"fingerprint" json:"fingerprint"`
+	Example         string  `middleware:"example" json:"example"`
+	DBName          string  `middleware:"db_name" json:"db_name"`
+	ExecCount       int     `middleware:"exec_count" json:"exec_count"`
+	TotalExecTime   float64 `middleware:"total_exec_time" json:"total_exec_time"`
+	AvgExecTime     float64 `middleware:"avg_exec_time" json:"avg_exec_time"`
+	RowsExaminedMax int     `middleware:"rows_examined_max" json:"rows_examined_max"`
+}
+
+// TrendPoint is the aggregated metrics of a fingerprint in one period of the monitor system
+type TrendPoint struct {
+	PeriodStart     time.Time `middleware:"period_start" json:"period_start"`
+	ExecCount       int       `middleware:"exec_count" json:"exec_count"`
+	TotalExecTime   float64   `middleware:"total_exec_time" json:"total_exec_time"`
+	AvgExecTime     float64   `middleware:"avg_exec_time" json:"avg_exec_time"`
+	RowsExaminedMax int       `middleware:"rows_examined_max" json:"rows_examined_max"`
+}
+
+// Regression compares the same fingerprint between the base window and the current window,
+// Base will be nil if the fingerprint does not appear in the base window
+type Regression struct {
+	SQLID            string     `json:"sql_id"`
+	Fingerprint      string     `json:"fingerprint"`
+	DBName           string     `json:"db_name"`
+	Base             *SlowQuery `json:"base"`
+	Current          *SlowQuery `json:"current"`
+	AvgExecTimeRatio float64    `json:"avg_exec_time_ratio"`
+	IsNew            bool       `json:"is_new"`
+}
+
+// NewRegression returns a new *Regression
+func NewRegression(base, current *SlowQuery) *Regression {
+	r := &Regression{
+		SQLID:       current.SQLID,
+		Fingerprint: current.Fingerprint,
+		DBName:      current.DBName,
+		Base:        base,
+		Current:     current,
+	}
+
+	if base == nil {
+		r.IsNew = true
+		return r
+	}
+	if base.AvgExecTime > 0 {
+		r.AvgExecTimeRatio = current.AvgExecTime / base.AvgExecTime
+	}
+
+	return r
+}
@@ -0,0 +1,40 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	defaultSQLID       = "B95017DB61875675"
+	defaultFingerprint = "select * from t_meta_db_info where create_time<?"
+	defaultDBName      = "das"
+)
+
+func newSlowQuery(avgExecTime float64) *SlowQuery {
+	return &SlowQuery{
+		SQLID:       defaultSQLID,
+		Fingerprint: defaultFingerprint,
+		DBName:      defaultDBName,
+		ExecCount:   10,
+		AvgExecTime: avgExecTime,
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	TestNewRegression(t)
+}
+
+func TestNewRegression(t *testing.T) {
+	asst := assert.New(t)
+
+	regression := NewRegression(newSlowQuery(1), newSlowQuery(3))
+	asst.False(regression.IsNew, "test NewRegression() failed")
+	asst.Equal(3.0, regression.AvgExecTimeRatio, "test NewRegression() failed")
+	asst.Equal(defaultSQLID, regression.SQLID, "test NewRegression() failed")
+
+	regression = NewRegression(nil, newSlowQuery(3))
+	asst.True(regression.IsNew, "test NewRegression() failed")
+	asst.Nil(regression.Base, "test NewRegression() failed")
+}
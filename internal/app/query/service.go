@@ -0,0 +1,203 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware/clickhouse"
+	"github.com/romberli/go-util/middleware/mysql"
+	"github.com/romberli/log"
+	"github.com/spf13/viper"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/query"
+)
+
+const (
+	SlowQueriesStruct = "SlowQueries"
+	TrendStruct       = "Trend"
+	RegressionsStruct = "Regressions"
+
+	defaultMonitorClickhouseDBName = "pmm"
+	defaultMonitorMySQLDBName      = "pmm"
+)
+
+var _ query.Service = (*Service)(nil)
+
+// Service of slow query workload analysis
+type Service struct {
+	SlowQueries []*SlowQuery  `json:"slow_queries"`
+	Trend       []*TrendPoint `json:"trend"`
+	Regressions []*Regression `json:"regressions"`
+}
+
+// NewService returns a new *Service
+func NewService() *Service {
+	return &Service{
+		SlowQueries: []*SlowQuery{},
+		Trend:       []*TrendPoint{},
+		Regressions: []*Regression{},
+	}
+}
+
+// NewServiceWithDefault returns a new query.Service
+func NewServiceWithDefault() query.Service {
+	return NewService()
+}
+
+// GetSlowQueries returns the slow queries of the service
+func (s *Service) GetSlowQueries() []*SlowQuery {
+	return s.SlowQueries
+}
+
+// GetTrend returns the trend of the service
+func (s *Service) GetTrend() []*TrendPoint {
+	return s.Trend
+}
+
+// GetRegressions returns the regressions of the service
+func (s *Service) GetRegressions() []*Regression {
+	return s.Regressions
+}
+
+// GetTopByMySQLServerID gets the top n slow queries of the given mysql server in the given time window,
+// if db name is not empty, only slow queries of the db will be returned
+func (s *Service) GetTopByMySQLServerID(ctx context.Context, mysqlServerID int, dbName string, startTime, endTime time.Time, orderBy string, limit int) error {
+	querier, serviceName, err := s.getQuerier(ctx, mysqlServerID)
+	if err != nil {
+		return err
+	}
+	defer s.closeQuerier(querier)
+
+	s.SlowQueries, err = querier.GetTop(ctx, serviceName, dbName, startTime, endTime, orderBy, limit)
+
+	return err
+}
+
+// GetTrendByMySQLServerID gets the trend of the slow query with given sql id of the given mysql server in the given time window
+func (s *Service) GetTrendByMySQLServerID(ctx context.Context, mysqlServerID int, sqlID string, startTime, endTime time.Time) error {
+	querier, serviceName, err := s.getQuerier(ctx, mysqlServerID)
+	if err != nil {
+		return err
+	}
+	defer s.closeQuerier(querier)
+
+	s.Trend, err = querier.GetTrend(ctx, serviceName, sqlID, startTime, endTime)
+
+	return err
+}
+
+// GetRegressionByMySQLServerID compares the top n slow queries ordered by total execution time of the current window with the base window,
+// and keeps the slow queries of which average execution time grows at least threshold times, and the ones that are new in the current window,
+// the result is ordered by the growing ratio descending, new slow queries come last
+func (s *Service) GetRegressionByMySQLServerID(ctx context.Context, mysqlServerID int, dbName string, baseStartTime, baseEndTime, startTime, endTime time.Time,
+	threshold float64, limit int) error {
+	querier, serviceName, err := s.getQuerier(ctx, mysqlServerID)
+	if err != nil {
+		return err
+	}
+	defer s.closeQuerier(querier)
+
+	current, err := querier.GetTop(ctx, serviceName, dbName, startTime, endTime, OrderByTotalExecTime, limit)
+	if err != nil {
+		return err
+	}
+	// the base window is not limited, otherwise, the slow queries of the current window which fall out of the top n of the base window would be taken as new
+	base, err := querier.GetTop(ctx, serviceName, dbName, baseStartTime, baseEndTime, OrderByTotalExecTime, NoLimit)
+	if err != nil {
+		return err
+	}
+
+	s.Regressions = compare(base, current, threshold)
+
+	return nil
+}
+
+// compare compares the current slow queries with the base ones, see GetRegressionByMySQLServerID() for details
+func compare(base, current []*SlowQuery, threshold float64) []*Regression {
+	baseMap := make(map[string]*SlowQuery, len(base))
+	for _, slowQuery := range base {
+		baseMap[slowQuery.SQLID+slowQuery.DBName] = slowQuery
+	}
+
+	regressions := []*Regression{}
+	for _, slowQuery := range current {
+		regression := NewRegression(baseMap[slowQuery.SQLID+slowQuery.DBName], slowQuery)
+		if regression.IsNew || regression.AvgExecTimeRatio >= threshold {
+			regressions = append(regressions, regression)
+		}
+	}
+
+	sort.SliceStable(regressions, func(i, j int) bool {
+		if regressions[i].IsNew != regressions[j].IsNew {
+			return !regressions[i].IsNew
+		}
+
+		return regressions[i].AvgExecTimeRatio > regressions[j].AvgExecTimeRatio
+	})
+
+	return regressions
+}
+
+// getQuerier returns a querier that connects to the monitor system of the given mysql server,
+// it also returns the service name of the mysql server in the monitor system
+func (s *Service) getQuerier(ctx context.Context, mysqlServerID int) (*Querier, string, error) {
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err := mysqlServerService.GetByID(ctx, mysqlServerID)
+	if err != nil {
+		return nil, constant.EmptyString, err
+	}
+	mysqlServer := mysqlServerService.GetMySQLServers()[constant.ZeroInt]
+	monitorSystem, err := mysqlServer.GetMonitorSystem(ctx)
+	if err != nil {
+		return nil, constant.EmptyString, err
+	}
+
+	var (
+		monitorMySQLConn      *mysql.Conn
+		monitorClickhouseConn *clickhouse.Conn
+	)
+
+	addr := fmt.Sprintf("%s:%d", monitorSystem.GetHostIP(), monitorSystem.GetPortNumSlow())
+	monitorSystemType := monitorSystem.GetSystemType()
+	switch monitorSystemType {
+	case 1:
+		// pmm 1.x
+		monitorMySQLConn, err = mysql.NewConn(addr, defaultMonitorMySQLDBName,
+			viper.GetString(config.DBMonitorMySQLUserKey), viper.GetString(config.DBMonitorMySQLPassKey))
+	case 2:
+		// pmm 2.x
+		monitorClickhouseConn, err = clickhouse.NewConnWithDefault(addr, defaultMonitorClickhouseDBName,
+			viper.GetString(config.DBMonitorClickhouseUserKey), viper.GetString(config.DBMonitorClickhousePassKey))
+	default:
+		return nil, constant.EmptyString, fmt.Errorf("query: monitor system type should be either 1 or 2, %d is not valid", monitorSystemType)
+	}
+	if err != nil {
+		return nil, constant.EmptyString, err
+	}
+
+	return NewQuerier(monitorSystemType, monitorMySQLConn, monitorClickhouseConn), mysqlServer.GetServiceName(), nil
+}
+
+// closeQuerier closes the querier and logs the error
+func (s *Service) closeQuerier(querier *Querier) {
+	err := querier.Close()
+	if err != nil {
+		log.Errorf("query Service.closeQuerier(): close connections of monitor system failed.\n%s", err.Error())
+	}
+}
+
+// Marshal marshals Service to json bytes
+func (s *Service) Marshal() ([]byte, error) {
+	return s.MarshalWithFields(SlowQueriesStruct, TrendStruct, RegressionsStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the Service to json bytes
+func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(s, fields...)
+}
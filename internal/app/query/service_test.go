@@ -0,0 +1,83 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	defaultMySQLServerID = 1
+	defaultLimit         = 10
+	defaultThreshold     = 1.5
+)
+
+func TestServiceAll(t *testing.T) {
+	TestService_compare(t)
+	TestService_GetTopByMySQLServerID(t)
+	TestService_GetTrendByMySQLServerID(t)
+	TestService_GetRegressionByMySQLServerID(t)
+}
+
+func TestService_compare(t *testing.T) {
+	asst := assert.New(t)
+
+	newQuery := newSlowQuery(1)
+	newQuery.SQLID = "new_sql_id"
+	stableQuery := newSlowQuery(1)
+	stableQuery.SQLID = "stable_sql_id"
+
+	base := []*SlowQuery{newSlowQuery(1), newSlowQuery(1)}
+	base[1].SQLID = stableQuery.SQLID
+	current := []*SlowQuery{newQuery, newSlowQuery(2), stableQuery}
+
+	regressions := compare(base, current, defaultThreshold)
+	asst.Equal(2, len(regressions), "test compare() failed")
+	asst.Equal(defaultSQLID, regressions[0].SQLID, "test compare() failed")
+	asst.True(regressions[1].IsNew, "test compare() failed")
+}
+
+func TestService_GetTopByMySQLServerID(t *testing.T) {
+	asst := assert.New(t)
+
+	endTime := time.Now()
+	startTime := endTime.Add(-time.Hour)
+
+	s := NewService()
+	err := s.GetTopByMySQLServerID(context.Background(), defaultMySQLServerID, defaultDBName, startTime, endTime, OrderByAvgExecTime, defaultLimit)
+	asst.Nil(err, common.CombineMessageWithError("test GetTopByMySQLServerID() failed", err))
+	asst.LessOrEqual(len(s.GetSlowQueries()), defaultLimit, "test GetTopByMySQLServerID() failed")
+
+	err = s.GetTopByMySQLServerID(context.Background(), defaultMySQLServerID, defaultDBName, startTime, endTime, "not_valid_column", defaultLimit)
+	asst.NotNil(err, "test GetTopByMySQLServerID() failed")
+}
+
+func TestService_GetTrendByMySQLServerID(t *testing.T) {
+	asst := assert.New(t)
+
+	endTime := time.Now()
+	startTime := endTime.Add(-time.Hour)
+
+	s := NewService()
+	err := s.GetTrendByMySQLServerID(context.Background(), defaultMySQLServerID, defaultSQLID, startTime, endTime)
+	asst.Nil(err, common.CombineMessageWithError("test GetTrendByMySQLServerID() failed", err))
+}
+
+func TestService_GetRegressionByMySQLServerID(t *testing.T) {
+	asst := assert.New(t)
+
+	endTime := time.Now()
+	startTime := endTime.Add(-time.Hour)
+	baseEndTime := startTime.Add(-24 * time.Hour)
+	baseStartTime := baseEndTime.Add(-time.Hour)
+
+	s := NewService()
+	err := s.GetRegressionByMySQLServerID(context.Background(), defaultMySQLServerID, defaultDBName, baseStartTime, baseEndTime, startTime, endTime, defaultThreshold, defaultLimit)
+	asst.Nil(err, common.CombineMessageWithError("test GetRegressionByMySQLServerID() failed", err))
+	for _, regression := range s.GetRegressions() {
+		asst.True(regression.IsNew || regression.AvgExecTimeRatio >= defaultThreshold, "test GetRegressionByMySQLServerID() failed")
+	}
+}
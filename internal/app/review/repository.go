@@ -0,0 +1,156 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/log"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/dependency/review"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+var _ review.Repository = (*Repository)(nil)
+
+// ErrDataNotExists is returned when the review record to get does not exist
+var ErrDataNotExists = errors.New("data does not exists")
+
+type Repository struct {
+	Database middleware.Pool
+}
+
+// NewRepository returns *Repository with given middleware.Pool
+func NewRepository(db middleware.Pool) *Repository {
+	return &Repository{Database: db}
+}
+
+// NewRepositoryWithGlobal returns *Repository with global mysql pool
+func NewRepositoryWithGlobal() *Repository {
+	return NewRepository(global.DASMySQLPool)
+}
+
+// Execute executes given command and placeholders on the middleware
+func (r *Repository) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
+	conn, err := r.Database.Get()
+	if err != nil {
+		tracing.EndSpan(span, err)
+		return nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("review Repository.Execute(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
+}
+
+// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+func (r *Repository) Transaction() (middleware.Transaction, error) {
+	return r.Database.Transaction()
+}
+
+// GetByID gets the review record by the identity from the middleware
+func (r *Repository) GetByID(ctx context.Context, id int) (review.Review, error) {
+	sql := `
+		select id, db_id, sql_text, verdict, result, approval_status, approver_id, approve_time,
+		del_flag, create_time, last_update_time
+		from t_sr_review_info
+		where del_flag = 0
+		and id = ?;
+	`
+	tracing.Logger(ctx).Debugf("review Repository.GetByID() sql: \n%s\nplaceholders: %d", sql, id)
+
+	result, err := r.Execute(ctx, sql, id)
+	if err != nil {
+		return nil, err
+	}
+	switch result.RowNumber() {
+	case 0:
+		return nil, fmt.Errorf("review Repository.GetByID(): %w, id: %d", ErrDataNotExists, id)
+	case 1:
+		info := NewEmptyInfo()
+		// map to struct
+		err = result.MapToStructByRowIndex(info, constant.ZeroInt, constant.DefaultMiddlewareTag)
+		if err != nil {
+			return nil, err
+		}
+
+		return info, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("review Repository.GetByID(): duplicate key exists, id: %d", id))
+	}
+}
+
+// Create creates a review record in the middleware and returns the identity
+func (r *Repository) Create(ctx context.Context, review review.Review) (int, error) {
+	tx, err := r.Transaction()
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	defer func() {
+		err = tx.Close()
+		if err != nil {
+			log.Errorf("review Repository.Create(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	err = tx.Begin()
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	sql := `insert into t_sr_review_info(db_id, sql_text, verdict, result, approval_status) values(?, ?, ?, ?, ?);`
+	tracing.Logger(ctx).Debugf("review Repository.Create() insert sql: \n%s\nplaceholders: %d, %s, %d", sql,
+		review.GetDBID(), review.GetSQLText(), review.GetVerdict())
+	_, err = tx.ExecuteContext(ctx, sql, review.GetDBID(), review.GetSQLText(), review.GetVerdict(), review.GetResult(), review.GetApprovalStatus())
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	// the last insert id is bound to the connection, so it must be selected in the same transaction
+	result, err := tx.ExecuteContext(ctx, `select last_insert_id();`)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	id, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return id, tx.Commit()
+}
+
+// Approve updates the approval status of the pending review record to approved in the middleware,
+// it returns an error if the record does not exist or is not pending any more
+func (r *Repository) Approve(ctx context.Context, id, approverID int) error {
+	sql := `
+		update t_sr_review_info set approval_status = ?, approver_id = ?, approve_time = now(6)
+		where del_flag = 0
+		and id = ?
+		and approval_status = ?;
+	`
+	tracing.Logger(ctx).Debugf("review Repository.Approve() update sql: \n%s\nplaceholders: %d, %d, %d, %d", sql,
+		ApprovalStatusApproved, approverID, id, ApprovalStatusPending)
+
+	result, err := r.Execute(ctx, sql, ApprovalStatusApproved, approverID, id, ApprovalStatusPending)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == constant.ZeroInt {
+		return errors.New(fmt.Sprintf("review Repository.Approve(): review record does not exist or is not pending for approval. id: %d", id))
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package review
+
+import (
+	"context"
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/middleware/mysql"
+	"github.com/romberli/log"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/global"
+)
+
+func init() {
+	initDASMySQLPool()
+}
+
+const (
+	defaultDASMySQLAddr = "192.168.137.11:3306"
+	defaultDASMySQLName = "das"
+	defaultDASMySQLUser = "root"
+	defaultDASMySQLPass = "root"
+
+	defaultDBID       = 1
+	defaultSQLText    = "create table t01(id int primary key comment 'id') engine=innodb default charset=utf8mb4 comment 't01';"
+	defaultResult     = "[]"
+	defaultApproverID = 1
+)
+
+var repository = initRepository()
+
+func initDASMySQLPool() *mysql.Pool {
+	var err error
+
+	global.DASMySQLPool, err = mysql.NewPoolWithDefault(defaultDASMySQLAddr, defaultDASMySQLName, defaultDASMySQLUser, defaultDASMySQLPass)
+	log.Infof("pool: %v, error: %v", global.DASMySQLPool, err)
+	if err != nil {
+		log.Error(common.CombineMessageWithError("initRepository() failed", err))
+		return nil
+	}
+
+	return global.DASMySQLPool
+}
+
+func initRepository() *Repository {
+	return NewRepository(global.DASMySQLPool)
+}
+
+func deleteReviewByID(id int) error {
+	sql := `delete from t_sr_review_info where id = ?;`
+	_, err := repository.Execute(context.Background(), sql, id)
+
+	return err
+}
+
+func TestRepositoryAll(t *testing.T) {
+	TestRepository_Execute(t)
+	TestRepository_Create(t)
+	TestRepository_GetByID(t)
+	TestRepository_Approve(t)
+}
+
+func TestRepository_Execute(t *testing.T) {
+	asst := assert.New(t)
+
+	sql := "select 1;"
+	result, err := repository.Execute(context.Background(), sql)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	r, err := result.GetInt(0, 0)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	asst.Equal(1, r, "test Execute() failed")
+}
+
+func TestRepository_Create(t *testing.T) {
+	asst := assert.New(t)
+
+	id, err := repository.Create(context.Background(), NewInfo(defaultDBID, defaultSQLText, VerdictWarn, defaultResult))
+	asst.Nil(err, common.CombineMessageWithError("test Create() failed", err))
+	asst.True(id > 0, "test Create() failed")
+	err = deleteReviewByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test Create() failed", err))
+}
+
+func TestRepository_GetByID(t *testing.T) {
+	asst := assert.New(t)
+
+	id, err := repository.Create(context.Background(), NewInfo(defaultDBID, defaultSQLText, VerdictPass, defaultResult))
+	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
+	entity, err := repository.GetByID(context.Background(), id)
+	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
+	asst.Equal(defaultSQLText, entity.GetSQLText(), "test GetByID() failed")
+	asst.Equal(ApprovalStatusNotRequired, entity.GetApprovalStatus(), "test GetByID() failed")
+	err = deleteReviewByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test GetByID() failed", err))
+}
+
+func TestRepository_Approve(t *testing.T) {
+	asst := assert.New(t)
+
+	id, err := repository.Create(context.Background(), NewInfo(defaultDBID, defaultSQLText, VerdictBlock, defaultResult))
+	asst.Nil(err, common.CombineMessageWithError("test Approve() failed", err))
+	err = repository.Approve(context.Background(), id, defaultApproverID)
+	asst.Nil(err, common.CombineMessageWithError("test Approve() failed", err))
+	entity, err := repository.GetByID(context.Background(), id)
+	asst.Nil(err, common.CombineMessageWithError("test Approve() failed", err))
+	asst.Equal(ApprovalStatusApproved, entity.GetApprovalStatus(), "test Approve() failed")
+	asst.Equal(defaultApproverID, entity.GetApproverID(), "test Approve() failed")
+	// the record is not pending any more
+	err = repository.Approve(context.Background(), id, defaultApproverID)
+	asst.NotNil(err, "test Approve() failed")
+	err = deleteReviewByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test Approve() failed", err))
+}
@@ -0,0 +1,167 @@
+package review
+
+import (
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+
+	"github.com/romberli/das/internal/dependency/review"
+)
+
+const (
+	VerdictPass  = 1
+	VerdictWarn  = 2
+	VerdictBlock = 3
+
+	ApprovalStatusNotRequired = 0
+	ApprovalStatusPending     = 1
+	ApprovalStatusApproved    = 2
+)
+
+var _ review.Review = (*Info)(nil)
+
+// Info is the review record of a sql text
+type Info struct {
+	ID             int       `middleware:"id" json:"id"`
+	DBID           int       `middleware:"db_id" json:"db_id"`
+	SQLText        string    `middleware:"sql_text" json:"sql_text"`
+	Verdict        int       `middleware:"verdict" json:"verdict"`
+	Result         string    `middleware:"result" json:"result"`
+	ApprovalStatus int       `middleware:"approval_status" json:"approval_status"`
+	ApproverID     int       `middleware:"approver_id" json:"approver_id"`
+	ApproveTime    time.Time `middleware:"approve_time" json:"approve_time"`
+	DelFlag        int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime     time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewInfo returns a new *Info, the statement results will be marshaled as the result,
+// the approval is required only if the verdict is not pass
+func NewInfo(dbID int, sqlText string, verdict int, result string) *Info {
+	approvalStatus := ApprovalStatusNotRequired
+	if verdict != VerdictPass {
+		approvalStatus = ApprovalStatusPending
+	}
+
+	return &Info{
+		DBID:           dbID,
+		SQLText:        sqlText,
+		Verdict:        verdict,
+		Result:         result,
+		ApprovalStatus: approvalStatus,
+	}
+}
+
+// NewEmptyInfo returns an empty *Info
+func NewEmptyInfo() *Info {
+	return &Info{}
+}
+
+// Identity returns the identity
+func (i *Info) Identity() int {
+	return i.ID
+}
+
+// GetDBID returns the db id
+func (i *Info) GetDBID() int {
+	return i.DBID
+}
+
+// GetSQLText returns the sql text
+func (i *Info) GetSQLText() string {
+	return i.SQLText
+}
+
+// GetVerdict returns the verdict of the whole sql text
+func (i *Info) GetVerdict() int {
+	return i.Verdict
+}
+
+// GetResult returns the review results of each statement
+func (i *Info) GetResult() string {
+	return i.Result
+}
+
+// GetApprovalStatus returns the approval status
+func (i *Info) GetApprovalStatus() int {
+	return i.ApprovalStatus
+}
+
+// GetApproverID returns the approver id
+func (i *Info) GetApproverID() int {
+	return i.ApproverID
+}
+
+// GetApproveTime returns the approve time
+func (i *Info) GetApproveTime() time.Time {
+	return i.ApproveTime
+}
+
+// GetDelFlag returns the delete flag
+func (i *Info) GetDelFlag() int {
+	return i.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (i *Info) GetCreateTime() time.Time {
+	return i.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (i *Info) GetLastUpdateTime() time.Time {
+	return i.LastUpdateTime
+}
+
+// MarshalJSON marshals Info to json string
+func (i *Info) MarshalJSON() ([]byte, error) {
+	return common.MarshalStructWithTag(i, constant.DefaultMarshalTag)
+}
+
+// MarshalJSONWithFields marshals only specified fields of the Info to json string
+func (i *Info) MarshalJSONWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(i, fields...)
+}
+
+// Violation is a rule violated by a statement
+type Violation struct {
+	RuleName string `json:"rule_name"`
+	Level    int    `json:"level"`
+	Message  string `json:"message"`
+}
+
+// NewViolation returns a new *Violation
+func NewViolation(ruleName string, level int, message string) *Violation {
+	return &Violation{
+		RuleName: ruleName,
+		Level:    level,
+		Message:  message,
+	}
+}
+
+// StatementResult is the review result of a statement
+type StatementResult struct {
+	SQLText    string       `json:"sql_text"`
+	Verdict    int          `json:"verdict"`
+	Violations []*Violation `json:"violations"`
+}
+
+// NewStatementResult returns a new *StatementResult, the verdict is the highest level of the violations
+func NewStatementResult(sqlText string, violations []*Violation) *StatementResult {
+	if violations == nil {
+		violations = []*Violation{}
+	}
+
+	verdict := VerdictPass
+	for _, violation := range violations {
+		if violation.Level > verdict {
+			verdict = violation.Level
+		}
+	}
+
+	return &StatementResult{
+		SQLText:    sqlText,
+		Verdict:    verdict,
+		Violations: violations,
+	}
+}
@@ -0,0 +1,213 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware/mysql"
+	"github.com/romberli/go-util/middleware/sql/parser"
+	"github.com/spf13/viper"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/metadata"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/internal/dependency/review"
+	"github.com/romberli/das/pkg/tracing"
+)
+
+const (
+	singleClusterType = 1
+)
+
+// Reviewer reviews the statements of the sql text with the enabled rules
+type Reviewer struct {
+	repo          review.Repository
+	db            depmeta.DB
+	isOnline      bool
+	parser        *parser.Parser
+	ruleConfigs   map[string]*RuleConfig
+	readMySQLConn *mysql.Conn
+	// getTableRows returns the row number of the table, it is replaceable for testing
+	getTableRows func(ctx context.Context, table *TableName) (int, error)
+}
+
+// NewReviewer returns a new *Reviewer
+func NewReviewer(repo review.Repository, db depmeta.DB, isOnline bool) *Reviewer {
+	r := &Reviewer{
+		repo:        repo,
+		db:          db,
+		isOnline:    isOnline,
+		parser:      parser.NewParserWithDefault(),
+		ruleConfigs: make(map[string]*RuleConfig),
+	}
+	r.getTableRows = r.getTableRowsFromReadMySQL
+
+	return r
+}
+
+// Review reviews each statement of the sql text, the rule configs will be loaded from the middleware
+func (r *Reviewer) Review(ctx context.Context, sqlText string) ([]*StatementResult, error) {
+	err := r.loadRuleConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.review(ctx, sqlText)
+}
+
+// review reviews each statement of the sql text with loaded rule configs
+func (r *Reviewer) review(ctx context.Context, sqlText string) ([]*StatementResult, error) {
+	stmtNodes, err := r.parser.GetStatementNodes(sqlText)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*StatementResult, len(stmtNodes))
+	for i, stmtNode := range stmtNodes {
+		violations, err := r.reviewStatement(ctx, stmtNode)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = NewStatementResult(stmtNode.Text(), violations)
+	}
+
+	return results, nil
+}
+
+// reviewStatement checks the statement with each enabled rule
+func (r *Reviewer) reviewStatement(ctx context.Context, stmtNode ast.StmtNode) ([]*Violation, error) {
+	var violations []*Violation
+
+	addViolations := func(ruleName string, messages []string) {
+		for _, message := range messages {
+			violations = append(violations, NewViolation(ruleName, r.ruleConfigs[ruleName].RuleLevel, message))
+		}
+	}
+
+	if r.isEnabled(RuleDropOnline) && r.isOnline {
+		addViolations(RuleDropOnline, checkDrop(stmtNode))
+	}
+	if r.isEnabled(RuleMissingPrimaryKey) {
+		addViolations(RuleMissingPrimaryKey, checkPrimaryKey(stmtNode))
+	}
+	if r.isEnabled(RuleNotUTF8MB4Charset) {
+		addViolations(RuleNotUTF8MB4Charset, checkCharset(stmtNode))
+	}
+	if r.isEnabled(RuleMissingComment) {
+		addViolations(RuleMissingComment, checkComment(stmtNode))
+	}
+	if r.isEnabled(RuleAlterLargeTable) {
+		threshold := r.ruleConfigs[RuleAlterLargeTable].Threshold
+		for _, table := range getAlteredTables(stmtNode) {
+			tableRows, err := r.getTableRows(ctx, table)
+			if err != nil {
+				return nil, err
+			}
+			if tableRows > threshold {
+				addViolations(RuleAlterLargeTable, []string{fmt.Sprintf("table %s has about %d rows, which is more than %d", table.Name, tableRows, threshold)})
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// isEnabled returns if the rule exists and is enabled
+func (r *Reviewer) isEnabled(ruleName string) bool {
+	ruleConfig, ok := r.ruleConfigs[ruleName]
+
+	return ok && ruleConfig.IsEnabled()
+}
+
+// loadRuleConfigs loads the rule configs from the middleware
+func (r *Reviewer) loadRuleConfigs(ctx context.Context) error {
+	sql := `
+		select id, rule_name, rule_level, threshold, status, del_flag, create_time, last_update_time
+		from t_sr_rule_config
+		where del_flag = 0;
+	`
+	tracing.Logger(ctx).Debugf("review Reviewer.loadRuleConfigs() sql: \n%s\n", sql)
+
+	result, err := r.repo.Execute(ctx, sql)
+	if err != nil {
+		return err
+	}
+	ruleConfigList := make([]*RuleConfig, result.RowNumber())
+	for i := range ruleConfigList {
+		ruleConfigList[i] = &RuleConfig{}
+	}
+	err = result.MapToStructSlice(ruleConfigList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return err
+	}
+
+	for _, ruleConfig := range ruleConfigList {
+		r.ruleConfigs[ruleConfig.RuleName] = ruleConfig
+	}
+
+	return nil
+}
+
+// getTableRowsFromReadMySQL returns the estimated row number of the table from information_schema of a readable mysql server,
+// it returns zero if the table does not exist
+func (r *Reviewer) getTableRowsFromReadMySQL(ctx context.Context, table *TableName) (int, error) {
+	if r.readMySQLConn == nil {
+		err := r.initReadMySQLConn(ctx)
+		if err != nil {
+			return constant.ZeroInt, err
+		}
+	}
+
+	schema := table.Schema
+	if schema == constant.EmptyString {
+		schema = r.db.GetDBName()
+	}
+
+	sql := `select table_rows from information_schema.tables where table_schema = ? and table_name = ?;`
+	tracing.Logger(ctx).Debugf("review Reviewer.getTableRowsFromReadMySQL() sql: \n%s\nplaceholders: %s, %s", sql, schema, table.Name)
+
+	result, err := r.readMySQLConn.ExecuteContext(ctx, sql, schema, table.Name)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	if result.RowNumber() == constant.ZeroInt {
+		return constant.ZeroInt, nil
+	}
+
+	return result.GetInt(constant.ZeroInt, constant.ZeroInt)
+}
+
+// initReadMySQLConn connects to a healthy readable mysql server of the cluster of the db
+func (r *Reviewer) initReadMySQLConn(ctx context.Context) error {
+	if r.db.GetClusterType() != singleClusterType {
+		return fmt.Errorf("review: only the db of which cluster type is %d could be reviewed for table size, %d is not valid",
+			singleClusterType, r.db.GetClusterType())
+	}
+
+	user := viper.GetString(config.DBApplicationMySQLUserKey)
+	pass := viper.GetString(config.DBApplicationMySQLPassKey)
+
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err := mysqlServerService.GetHealthyReadableByClusterID(ctx, r.db.GetClusterID(), user, pass,
+		viper.GetInt(config.DBApplicationMySQLMaxReplicationDelayKey), viper.GetBool(config.DBApplicationMySQLAllowPrimaryKey))
+	if err != nil {
+		return err
+	}
+	mysqlServer := mysqlServerService.GetMySQLServers()[constant.ZeroInt]
+	addr := fmt.Sprintf("%s:%d", mysqlServer.GetHostIP(), mysqlServer.GetPortNum())
+
+	r.readMySQLConn, err = mysql.NewConn(addr, constant.EmptyString, user, pass)
+
+	return err
+}
+
+// Close closes the connection to the read mysql server if it was opened
+func (r *Reviewer) Close() error {
+	if r.readMySQLConn == nil {
+		return nil
+	}
+
+	return r.readMySQLConn.Close()
+}
@@ -0,0 +1,68 @@
+package review
+
+import (
+	"context"
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testLargeTableName = "t_large"
+	testLargeTableRows = 2000000
+)
+
+func initTestReviewer(isOnline bool) *Reviewer {
+	r := NewReviewer(nil, nil, isOnline)
+	r.ruleConfigs = map[string]*RuleConfig{
+		RuleDropOnline:        {RuleName: RuleDropOnline, RuleLevel: VerdictBlock, Status: ruleStatusEnabled},
+		RuleAlterLargeTable:   {RuleName: RuleAlterLargeTable, RuleLevel: VerdictWarn, Threshold: 1000000, Status: ruleStatusEnabled},
+		RuleMissingPrimaryKey: {RuleName: RuleMissingPrimaryKey, RuleLevel: VerdictBlock, Status: ruleStatusEnabled},
+		RuleNotUTF8MB4Charset: {RuleName: RuleNotUTF8MB4Charset, RuleLevel: VerdictBlock, Status: ruleStatusEnabled},
+		RuleMissingComment:    {RuleName: RuleMissingComment, RuleLevel: VerdictWarn, Status: ruleStatusEnabled},
+	}
+	r.getTableRows = func(ctx context.Context, table *TableName) (int, error) {
+		if table.Name == testLargeTableName {
+			return testLargeTableRows, nil
+		}
+
+		return 0, nil
+	}
+
+	return r
+}
+
+func TestReviewerAll(t *testing.T) {
+	TestReviewer_Review(t)
+}
+
+func TestReviewer_Review(t *testing.T) {
+	asst := assert.New(t)
+
+	sqlText := `
+		create table t01(id int primary key comment 'id') engine=innodb default charset=utf8mb4 comment 't01';
+		alter table t_large add column c1 int comment 'c1';
+		create table t02(id int);
+		drop table t03;
+	`
+	// online
+	results, err := initTestReviewer(true).review(context.Background(), sqlText)
+	asst.Nil(err, common.CombineMessageWithError("test Review() failed", err))
+	asst.Equal(4, len(results), "test Review() failed")
+	asst.Equal(VerdictPass, results[0].Verdict, "test Review() failed")
+	asst.Equal(VerdictWarn, results[1].Verdict, "test Review() failed")
+	asst.Equal(RuleAlterLargeTable, results[1].Violations[0].RuleName, "test Review() failed")
+	asst.Equal(VerdictBlock, results[2].Verdict, "test Review() failed")
+	asst.Equal(VerdictBlock, results[3].Verdict, "test Review() failed")
+	// not online, drop is allowed
+	results, err = initTestReviewer(false).review(context.Background(), sqlText)
+	asst.Nil(err, common.CombineMessageWithError("test Review() failed", err))
+	asst.Equal(VerdictPass, results[3].Verdict, "test Review() failed")
+	// disabled rule should be ignored
+	r := initTestReviewer(true)
+	r.ruleConfigs[RuleAlterLargeTable].Status = 0
+	results, err = r.review(context.Background(), sqlText)
+	asst.Nil(err, common.CombineMessageWithError("test Review() failed", err))
+	asst.Equal(VerdictPass, results[1].Verdict, "test Review() failed")
+}
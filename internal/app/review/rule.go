@@ -0,0 +1,239 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	RuleDropOnline        = "drop_online"
+	RuleAlterLargeTable   = "alter_large_table"
+	RuleMissingPrimaryKey = "missing_primary_key"
+	RuleNotUTF8MB4Charset = "not_utf8mb4_charset"
+	RuleMissingComment    = "missing_comment"
+
+	ruleStatusEnabled = 1
+	validCharset      = "utf8mb4"
+	binaryCharset     = "binary"
+)
+
+// RuleConfig is the config of a review rule, the level is the verdict when the rule is violated
+type RuleConfig struct {
+	ID             int       `middleware:"id" json:"id"`
+	RuleName       string    `middleware:"rule_name" json:"rule_name"`
+	RuleLevel      int       `middleware:"rule_level" json:"rule_level"`
+	Threshold      int       `middleware:"threshold" json:"threshold"`
+	Status         int       `middleware:"status" json:"status"`
+	DelFlag        int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime     time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// IsEnabled returns if the rule is enabled
+func (rc *RuleConfig) IsEnabled() bool {
+	return rc.Status == ruleStatusEnabled
+}
+
+// TableName is the table name with its schema, schema will be empty if it is not specified in the statement
+type TableName struct {
+	Schema string
+	Name   string
+}
+
+// checkDrop returns the messages if the statement drops or truncates any database object
+func checkDrop(stmtNode ast.StmtNode) []string {
+	var messages []string
+
+	switch node := stmtNode.(type) {
+	case *ast.DropDatabaseStmt:
+		messages = append(messages, fmt.Sprintf("drop database %s", node.Name))
+	case *ast.DropTableStmt:
+		objectType := "table"
+		if node.IsView {
+			objectType = "view"
+		}
+		for _, table := range node.Tables {
+			messages = append(messages, fmt.Sprintf("drop %s %s", objectType, table.Name.O))
+		}
+	case *ast.TruncateTableStmt:
+		messages = append(messages, fmt.Sprintf("truncate table %s", node.Table.Name.O))
+	case *ast.DropIndexStmt:
+		messages = append(messages, fmt.Sprintf("drop index %s on table %s", node.IndexName, node.Table.Name.O))
+	case *ast.AlterTableStmt:
+		for _, spec := range node.Specs {
+			switch spec.Tp {
+			case ast.AlterTableDropColumn:
+				messages = append(messages, fmt.Sprintf("drop column %s of table %s", spec.OldColumnName.Name.O, node.Table.Name.O))
+			case ast.AlterTableDropIndex:
+				messages = append(messages, fmt.Sprintf("drop index %s of table %s", spec.Name, node.Table.Name.O))
+			case ast.AlterTableDropPrimaryKey:
+				messages = append(messages, fmt.Sprintf("drop primary key of table %s", node.Table.Name.O))
+			case ast.AlterTableDropPartition:
+				messages = append(messages, fmt.Sprintf("drop partition of table %s", node.Table.Name.O))
+			}
+		}
+	}
+
+	return messages
+}
+
+// checkPrimaryKey returns the message if the created table does not have a primary key
+func checkPrimaryKey(stmtNode ast.StmtNode) []string {
+	node, ok := stmtNode.(*ast.CreateTableStmt)
+	if !ok || node.ReferTable != nil || node.Select != nil {
+		return nil
+	}
+
+	for _, constraint := range node.Constraints {
+		if constraint.Tp == ast.ConstraintPrimaryKey {
+			return nil
+		}
+	}
+	for _, col := range node.Cols {
+		for _, option := range col.Options {
+			if option.Tp == ast.ColumnOptionPrimaryKey {
+				return nil
+			}
+		}
+	}
+
+	return []string{fmt.Sprintf("table %s does not have a primary key", node.Table.Name.O)}
+}
+
+// checkCharset returns the messages if the charset of the database, table or column is not utf8mb4,
+// created table must specify the charset explicitly
+func checkCharset(stmtNode ast.StmtNode) []string {
+	var messages []string
+
+	switch node := stmtNode.(type) {
+	case *ast.CreateDatabaseStmt:
+		for _, option := range node.Options {
+			if option.Tp == ast.DatabaseOptionCharset && !isValidCharset(option.Value) {
+				messages = append(messages, fmt.Sprintf("charset of database %s is %s", node.Name, option.Value))
+			}
+		}
+	case *ast.AlterDatabaseStmt:
+		for _, option := range node.Options {
+			if option.Tp == ast.DatabaseOptionCharset && !isValidCharset(option.Value) {
+				messages = append(messages, fmt.Sprintf("charset of database %s is %s", node.Name, option.Value))
+			}
+		}
+	case *ast.CreateTableStmt:
+		if node.ReferTable != nil {
+			return nil
+		}
+		charsetSpecified := false
+		for _, option := range node.Options {
+			if option.Tp == ast.TableOptionCharset {
+				charsetSpecified = true
+				if !isValidCharset(option.StrValue) {
+					messages = append(messages, fmt.Sprintf("charset of table %s is %s", node.Table.Name.O, option.StrValue))
+				}
+			}
+		}
+		if !charsetSpecified {
+			messages = append(messages, fmt.Sprintf("charset of table %s is not specified", node.Table.Name.O))
+		}
+		messages = append(messages, checkColumnCharset(node.Table.Name.O, node.Cols)...)
+	case *ast.AlterTableStmt:
+		for _, spec := range node.Specs {
+			for _, option := range spec.Options {
+				if option.Tp == ast.TableOptionCharset && !isValidCharset(option.StrValue) {
+					messages = append(messages, fmt.Sprintf("charset of table %s is %s", node.Table.Name.O, option.StrValue))
+				}
+			}
+			messages = append(messages, checkColumnCharset(node.Table.Name.O, spec.NewColumns)...)
+		}
+	}
+
+	return messages
+}
+
+// checkColumnCharset returns the messages if the charset of the column is specified and is not utf8mb4
+func checkColumnCharset(tableName string, cols []*ast.ColumnDef) []string {
+	var messages []string
+
+	for _, col := range cols {
+		if col.Tp == nil || col.Tp.Charset == constant.EmptyString || col.Tp.Charset == binaryCharset {
+			continue
+		}
+		if !isValidCharset(col.Tp.Charset) {
+			messages = append(messages, fmt.Sprintf("charset of column %s of table %s is %s", col.Name.Name.O, tableName, col.Tp.Charset))
+		}
+	}
+
+	return messages
+}
+
+// isValidCharset returns if the charset is utf8mb4
+func isValidCharset(charset string) bool {
+	return strings.ToLower(charset) == validCharset
+}
+
+// checkComment returns the messages if the created table or the new columns do not have comments
+func checkComment(stmtNode ast.StmtNode) []string {
+	var messages []string
+
+	switch node := stmtNode.(type) {
+	case *ast.CreateTableStmt:
+		if node.ReferTable != nil {
+			return nil
+		}
+		hasComment := false
+		for _, option := range node.Options {
+			if option.Tp == ast.TableOptionComment && strings.TrimSpace(option.StrValue) != constant.EmptyString {
+				hasComment = true
+			}
+		}
+		if !hasComment {
+			messages = append(messages, fmt.Sprintf("table %s does not have a comment", node.Table.Name.O))
+		}
+		messages = append(messages, checkColumnComment(node.Table.Name.O, node.Cols)...)
+	case *ast.AlterTableStmt:
+		for _, spec := range node.Specs {
+			switch spec.Tp {
+			case ast.AlterTableAddColumns, ast.AlterTableModifyColumn, ast.AlterTableChangeColumn:
+				messages = append(messages, checkColumnComment(node.Table.Name.O, spec.NewColumns)...)
+			}
+		}
+	}
+
+	return messages
+}
+
+// checkColumnComment returns the messages if the columns do not have comments
+func checkColumnComment(tableName string, cols []*ast.ColumnDef) []string {
+	var messages []string
+
+	for _, col := range cols {
+		hasComment := false
+		for _, option := range col.Options {
+			if option.Tp == ast.ColumnOptionComment {
+				hasComment = true
+			}
+		}
+		if !hasComment {
+			messages = append(messages, fmt.Sprintf("column %s of table %s does not have a comment", col.Name.Name.O, tableName))
+		}
+	}
+
+	return messages
+}
+
+// getAlteredTables returns the tables which will be rebuilt or locked by the statement
+func getAlteredTables(stmtNode ast.StmtNode) []*TableName {
+	switch node := stmtNode.(type) {
+	case *ast.AlterTableStmt:
+		return []*TableName{{Schema: node.Table.Schema.O, Name: node.Table.Name.O}}
+	case *ast.CreateIndexStmt:
+		return []*TableName{{Schema: node.Table.Schema.O, Name: node.Table.Name.O}}
+	case *ast.DropIndexStmt:
+		return []*TableName{{Schema: node.Table.Schema.O, Name: node.Table.Name.O}}
+	default:
+		return nil
+	}
+}
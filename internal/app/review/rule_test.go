@@ -0,0 +1,72 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/romberli/go-util/middleware/sql/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseStatement(t *testing.T, sql string) ast.StmtNode {
+	stmtNodes, err := parser.NewParserWithDefault().GetStatementNodes(sql)
+	if err != nil {
+		t.Fatalf("parse sql failed. sql: %s, error: %s", sql, err.Error())
+	}
+
+	return stmtNodes[0]
+}
+
+func TestRuleAll(t *testing.T) {
+	TestCheckDrop(t)
+	TestCheckPrimaryKey(t)
+	TestCheckCharset(t)
+	TestCheckComment(t)
+	TestGetAlteredTables(t)
+}
+
+func TestCheckDrop(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Equal(1, len(checkDrop(parseStatement(t, "drop table t01;"))), "test checkDrop() failed")
+	asst.Equal(1, len(checkDrop(parseStatement(t, "truncate table t01;"))), "test checkDrop() failed")
+	asst.Equal(2, len(checkDrop(parseStatement(t, "alter table t01 drop column c1, drop index idx01;"))), "test checkDrop() failed")
+	asst.Equal(0, len(checkDrop(parseStatement(t, "alter table t01 add column c1 int;"))), "test checkDrop() failed")
+}
+
+func TestCheckPrimaryKey(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Equal(0, len(checkPrimaryKey(parseStatement(t, "create table t01(id int primary key);"))), "test checkPrimaryKey() failed")
+	asst.Equal(0, len(checkPrimaryKey(parseStatement(t, "create table t01(id int, primary key(id));"))), "test checkPrimaryKey() failed")
+	asst.Equal(1, len(checkPrimaryKey(parseStatement(t, "create table t01(id int);"))), "test checkPrimaryKey() failed")
+}
+
+func TestCheckCharset(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Equal(0, len(checkCharset(parseStatement(t, "create table t01(id int) default charset=utf8mb4;"))), "test checkCharset() failed")
+	asst.Equal(1, len(checkCharset(parseStatement(t, "create table t01(id int) default charset=utf8;"))), "test checkCharset() failed")
+	asst.Equal(1, len(checkCharset(parseStatement(t, "create table t01(id int);"))), "test checkCharset() failed")
+	asst.Equal(1, len(checkCharset(parseStatement(t, "alter table t01 add column c1 varchar(10) charset latin1;"))), "test checkCharset() failed")
+	asst.Equal(1, len(checkCharset(parseStatement(t, "create database db01 default charset=latin1;"))), "test checkCharset() failed")
+}
+
+func TestCheckComment(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Equal(0, len(checkComment(parseStatement(t, "create table t01(id int comment 'id') comment 't01';"))), "test checkComment() failed")
+	asst.Equal(2, len(checkComment(parseStatement(t, "create table t01(id int);"))), "test checkComment() failed")
+	asst.Equal(1, len(checkComment(parseStatement(t, "alter table t01 add column c1 int;"))), "test checkComment() failed")
+}
+
+func TestGetAlteredTables(t *testing.T) {
+	asst := assert.New(t)
+
+	tables := getAlteredTables(parseStatement(t, "alter table db01.t01 add column c1 int;"))
+	asst.Equal(1, len(tables), "test getAlteredTables() failed")
+	asst.Equal("db01", tables[0].Schema, "test getAlteredTables() failed")
+	asst.Equal("t01", tables[0].Name, "test getAlteredTables() failed")
+	asst.Equal(1, len(getAlteredTables(parseStatement(t, "create index idx01 on t01(c1);"))), "test getAlteredTables() failed")
+	asst.Equal(0, len(getAlteredTables(parseStatement(t, "select * from t01;"))), "test getAlteredTables() failed")
+}
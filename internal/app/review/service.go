@@ -0,0 +1,163 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+	"github.com/spf13/viper"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/review"
+)
+
+const (
+	ReviewsStruct = "Reviews"
+)
+
+var _ review.Service = (*Service)(nil)
+
+// ForbiddenError is returned when the user is not allowed to approve the review records, only admins and dbas could approve them
+type ForbiddenError struct {
+	AccountName string
+	Role        int
+}
+
+// Error implements error interface
+func (fe *ForbiddenError) Error() string {
+	return fmt.Sprintf("review Service.Approve(): only admins and dbas could approve the review records. account name: %s, role: %d",
+		fe.AccountName, fe.Role)
+}
+
+// isApprover returns if the users of the role could approve the review records
+func isApprover(role int) bool {
+	return role == metadata.UserRoleAdmin || role == metadata.UserRoleDBA
+}
+
+type Service struct {
+	review.Repository
+	Reviews []review.Review `json:"reviews"`
+}
+
+// NewService returns a new *Service
+func NewService(repo review.Repository) *Service {
+	return &Service{
+		Repository: repo,
+		Reviews:    []review.Review{},
+	}
+}
+
+// NewServiceWithDefault returns a new *Service with default repository
+func NewServiceWithDefault() *Service {
+	return NewService(NewRepositoryWithGlobal())
+}
+
+// GetReviews returns the review records of the service
+func (s *Service) GetReviews() []review.Review {
+	return s.Reviews
+}
+
+// GetByID gets the review record by the identity
+func (s *Service) GetByID(ctx context.Context, id int) error {
+	entity, err := s.Repository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.Reviews = []review.Review{entity}
+
+	return nil
+}
+
+// Review reviews each statement of the sql text of the given db, the verdict of the sql text is the highest one of the statements,
+// the review record will be saved, and it needs to be approved by a dba if the verdict is not pass
+func (s *Service) Review(ctx context.Context, dbID int, sqlText string) error {
+	// get db info
+	dbService := metadata.NewDBServiceWithDefault()
+	err := dbService.GetByID(ctx, dbID)
+	if err != nil {
+		return err
+	}
+	db := dbService.GetDBs()[constant.ZeroInt]
+	// get env info, the drop rule only applies to the online environment
+	envService := metadata.NewEnvServiceWithDefault()
+	err = envService.GetByID(ctx, db.GetEnvID())
+	if err != nil {
+		return err
+	}
+	isOnline := envService.GetEnvs()[constant.ZeroInt].GetEnvName() == viper.GetString(config.SQLAdvisorReviewOnlineEnvNameKey)
+	// review
+	reviewer := NewReviewer(s.Repository, db, isOnline)
+	defer func() {
+		err = reviewer.Close()
+		if err != nil {
+			log.Errorf("review Service.Review(): close read mysql connection failed.\n%s", err.Error())
+		}
+	}()
+	results, err := reviewer.Review(ctx, sqlText)
+	if err != nil {
+		return err
+	}
+
+	verdict := VerdictPass
+	for _, result := range results {
+		if result.Verdict > verdict {
+			verdict = result.Verdict
+		}
+	}
+	resultBytes, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	// save
+	id, err := s.Repository.Create(ctx, NewInfo(dbID, sqlText, verdict, string(resultBytes)))
+	if err != nil {
+		return err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Approve approves the pending review record by the user of given account name, only the user with the dba role could approve
+func (s *Service) Approve(ctx context.Context, id int, accountName string) error {
+	userService := metadata.NewUserServiceWithDefault()
+	err := userService.GetByAccountName(ctx, accountName)
+	if err != nil {
+		return err
+	}
+	user := userService.GetUsers()[constant.ZeroInt]
+	if !isApprover(user.GetRole()) {
+		return &ForbiddenError{AccountName: accountName, Role: user.GetRole()}
+	}
+
+	entity, err := s.Repository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if entity.GetApprovalStatus() != ApprovalStatusPending {
+		return errors.New(fmt.Sprintf("review Service.Approve(): review record is not pending for approval. id: %d, approval status: %d",
+			id, entity.GetApprovalStatus()))
+	}
+
+	err = s.Repository.Approve(ctx, id, user.Identity())
+	if err != nil {
+		return err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Marshal marshals Service to json bytes
+func (s *Service) Marshal() ([]byte, error) {
+	return s.MarshalWithFields(ReviewsStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the Service to json bytes
+func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(s, fields...)
+}
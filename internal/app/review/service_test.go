@@ -0,0 +1,22 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/internal/app/metadata"
+)
+
+func TestServiceAll(t *testing.T) {
+	TestIsApprover(t)
+}
+
+func TestIsApprover(t *testing.T) {
+	asst := assert.New(t)
+
+	// the approvers must agree with the authorization policy of the approve api
+	asst.True(isApprover(metadata.UserRoleAdmin), "test isApprover() failed")
+	asst.True(isApprover(metadata.UserRoleDBA), "test isApprover() failed")
+	asst.False(isApprover(metadata.UserRoleDeveloper), "test isApprover() failed")
+}
@@ -0,0 +1,311 @@
+package sqladvisor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+	"github.com/spf13/viper"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/app/query"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/internal/dependency/sqladvisor"
+	"github.com/romberli/das/pkg/message"
+	msgsa "github.com/romberli/das/pkg/message/sqladvisor"
+)
+
+const (
+	defaultClusterType = 1
+
+	skippedReasonEmptyDBName  = "db name is empty"
+	skippedReasonEmptyExample = "sql example is empty"
+)
+
+// AutoAdvisor periodically gets the top slow queries of all mysql clusters from the monitor systems,
+// advises the new or changed fingerprints, saves the advices and notifies the owners of the databases and apps
+type AutoAdvisor struct {
+	sqladvisor.Repository
+	Advisor  sqladvisor.Advisor
+	Notifier sqladvisor.Notifier
+	interval time.Duration
+	topNum   int
+	// ctx is canceled when the auto advisor is stopped, so that the running advice is stopped as well
+	ctx    context.Context
+	cancel context.CancelFunc
+	// started is set when Start() is called, done is closed when Start() returns
+	started int32
+	done    chan struct{}
+}
+
+// NewAutoAdvisor returns a new *AutoAdvisor
+func NewAutoAdvisor(repo sqladvisor.Repository, advisor sqladvisor.Advisor, notifier sqladvisor.Notifier,
+	interval time.Duration, topNum int) *AutoAdvisor {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &AutoAdvisor{
+		Repository: repo,
+		Advisor:    advisor,
+		Notifier:   notifier,
+		interval:   interval,
+		topNum:     topNum,
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+}
+
+// NewAutoAdvisorWithDefault returns a new *AutoAdvisor with global repository, default advisor and email notifier
+func NewAutoAdvisorWithDefault() *AutoAdvisor {
+	return NewAutoAdvisor(
+		NewRepositoryWithGlobal(),
+		NewDefaultAdvisor(viper.GetString(config.SQLAdvisorSoarBin), viper.GetString(config.SQLAdvisorSoarConfig)),
+		NewEmailNotifierWithDefault(),
+		time.Duration(viper.GetInt(config.SQLAdvisorAutoAdviceIntervalKey))*time.Second,
+		viper.GetInt(config.SQLAdvisorAutoAdviceTopNumKey),
+	)
+}
+
+// Start runs the auto advice at every interval until Stop() is called, it blocks the caller
+func (aa *AutoAdvisor) Start() {
+	atomic.StoreInt32(&aa.started, 1)
+	defer close(aa.done)
+
+	log.Info(message.NewMessage(msgsa.InfoSQLAdvisorAutoAdviceStart, aa.interval.String(), aa.topNum).Error())
+
+	ticker := time.NewTicker(aa.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-aa.ctx.Done():
+			log.Info(message.NewMessage(msgsa.InfoSQLAdvisorAutoAdviceStop).Error())
+			return
+		case <-ticker.C:
+			err := aa.Run(aa.ctx)
+			if err != nil {
+				log.Error(message.NewMessage(msgsa.ErrSQLAdvisorAutoAdvice, err.Error()).Error())
+			}
+		}
+	}
+}
+
+// Stop stops the auto advisor and cancels the running advice, if Start() was called,
+// it waits until Start() returns, so the caller could shut down safely after it returns,
+// it is safe to call it more than once
+func (aa *AutoAdvisor) Stop() {
+	aa.cancel()
+	if atomic.LoadInt32(&aa.started) == 1 {
+		<-aa.done
+	}
+}
+
+// Run advises the top slow queries of all mysql clusters in the last interval once,
+// failure of one mysql cluster does not stop advising the others
+func (aa *AutoAdvisor) Run(ctx context.Context) error {
+	endTime := time.Now()
+	startTime := endTime.Add(-aa.interval)
+
+	mysqlClusterService := metadata.NewMySQLClusterServiceWithDefault()
+	err := mysqlClusterService.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	merr := &multierror.Error{}
+	for _, mysqlCluster := range mysqlClusterService.GetMySQLClusters() {
+		err = aa.adviseCluster(ctx, mysqlCluster.Identity(), startTime, endTime)
+		if err != nil {
+			log.Error(message.NewMessage(msgsa.ErrSQLAdvisorAutoAdviceCluster, mysqlCluster.Identity(), err.Error()).Error())
+			merr = multierror.Append(merr, err)
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// adviseCluster advises the top slow queries of the given mysql cluster in the given time window
+func (aa *AutoAdvisor) adviseCluster(ctx context.Context, mysqlClusterID int, startTime, endTime time.Time) error {
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err := mysqlServerService.GetByClusterID(ctx, mysqlClusterID)
+	if err != nil {
+		return err
+	}
+
+	var slowQueryLists [][]*query.SlowQuery
+	for _, mysqlServer := range mysqlServerService.GetMySQLServers() {
+		queryService := query.NewService()
+		err = queryService.GetTopByMySQLServerID(ctx, mysqlServer.Identity(), constant.EmptyString, startTime, endTime, query.OrderByTotalExecTime, aa.topNum)
+		if err != nil {
+			// the monitor system of one mysql server may be unavailable, the other servers could still be advised
+			log.Errorf("sqladvisor AutoAdvisor.adviseCluster(): get top slow queries failed. mysql server id: %d\n%s",
+				mysqlServer.Identity(), err.Error())
+			continue
+		}
+		slowQueryLists = append(slowQueryLists, queryService.GetSlowQueries())
+	}
+
+	var advisedNum, skippedNum int
+	for _, slowQuery := range mergeSlowQueries(slowQueryLists, aa.topNum) {
+		advised, err := aa.advise(ctx, mysqlClusterID, slowQuery)
+		if err != nil {
+			return err
+		}
+		if advised {
+			advisedNum++
+			continue
+		}
+		skippedNum++
+	}
+
+	log.Info(message.NewMessage(msgsa.InfoSQLAdvisorAutoAdvice, mysqlClusterID, advisedNum, skippedNum).Error())
+
+	return nil
+}
+
+// advise advises the slow query if it has not been advised yet, it returns true if the slow query is advised
+func (aa *AutoAdvisor) advise(ctx context.Context, mysqlClusterID int, slowQuery *query.SlowQuery) (bool, error) {
+	if slowQuery.DBName == constant.EmptyString {
+		log.Info(message.NewMessage(msgsa.InfoSQLAdvisorAutoAdviceSkipped, mysqlClusterID, slowQuery.DBName, slowQuery.SQLID, skippedReasonEmptyDBName).Error())
+		return false, nil
+	}
+	if strings.TrimSpace(slowQuery.Example) == constant.EmptyString {
+		log.Info(message.NewMessage(msgsa.InfoSQLAdvisorAutoAdviceSkipped, mysqlClusterID, slowQuery.DBName, slowQuery.SQLID, skippedReasonEmptyExample).Error())
+		return false, nil
+	}
+	// get db info, the database may not be registered in the metadata, it should not stop advising the others
+	dbService := metadata.NewDBServiceWithDefault()
+	err := dbService.GetByNameAndClusterInfo(ctx, slowQuery.DBName, mysqlClusterID, defaultClusterType)
+	if err != nil {
+		log.Info(message.NewMessage(msgsa.InfoSQLAdvisorAutoAdviceSkipped, mysqlClusterID, slowQuery.DBName, slowQuery.SQLID, err.Error()).Error())
+		return false, nil
+	}
+	db := dbService.GetDBs()[constant.ZeroInt]
+	// check if the fingerprint had been advised
+	advised, err := aa.Repository.IsSlowQueryAdvised(ctx, db.Identity(), slowQuery.SQLID, slowQuery.Fingerprint)
+	if err != nil {
+		return false, err
+	}
+	if advised {
+		return false, nil
+	}
+	// advise
+	advice, msg, err := aa.Advisor.Advise(ctx, db.Identity(), slowQuery.Example)
+	if err != nil {
+		log.Error(message.NewMessage(msgsa.ErrSQLAdvisorAdvice, db.Identity(), slowQuery.Example, err.Error()).Error())
+		return false, nil
+	}
+	err = aa.Repository.SaveSlowQueryAdvice(ctx, NewSlowQueryAdvice(mysqlClusterID, db.Identity(), slowQuery.SQLID,
+		slowQuery.Fingerprint, slowQuery.Example, advice, msg))
+	if err != nil {
+		return false, err
+	}
+	// the advice had been saved, failing to notify should not make it be advised again
+	err = aa.notify(ctx, db, slowQuery, advice)
+	if err != nil {
+		log.Error(message.NewMessage(msgsa.ErrSQLAdvisorAutoAdviceNotify, db.Identity(), slowQuery.SQLID, err.Error()).Error())
+	}
+
+	return true, nil
+}
+
+// notify sends the advice to the owners of the database and the apps which use the database
+func (aa *AutoAdvisor) notify(ctx context.Context, db depmeta.DB, slowQuery *query.SlowQuery, advice string) error {
+	ownerIDList, err := aa.getOwnerIDList(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	userService := metadata.NewUserServiceWithDefault()
+	for _, ownerID := range ownerIDList {
+		err = userService.GetByID(ctx, ownerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	subject := fmt.Sprintf("[das] slow query advice. db name: %s, sql id: %s", db.GetDBName(), slowQuery.SQLID)
+	content := fmt.Sprintf("db name: %s\nsql id: %s\nfingerprint: %s\nexample: %s\nexec count: %d\ntotal exec time: %.2f\navg exec time: %.2f\nrows examined max: %d\n\nadvice:\n%s",
+		db.GetDBName(), slowQuery.SQLID, slowQuery.Fingerprint, slowQuery.Example, slowQuery.ExecCount,
+		slowQuery.TotalExecTime, slowQuery.AvgExecTime, slowQuery.RowsExaminedMax, advice)
+
+	return aa.Notifier.Notify(userService.GetUsers(), subject, content)
+}
+
+// getOwnerIDList returns the distinct owner id list of the database and the apps which use the database
+func (aa *AutoAdvisor) getOwnerIDList(ctx context.Context, db depmeta.DB) ([]int, error) {
+	var ownerIDList []int
+	ownerIDMap := make(map[int]bool)
+
+	addOwnerID := func(ownerID int) {
+		if ownerID > constant.ZeroInt && !ownerIDMap[ownerID] {
+			ownerIDMap[ownerID] = true
+			ownerIDList = append(ownerIDList, ownerID)
+		}
+	}
+
+	addOwnerID(db.GetOwnerID())
+
+	appIDList, err := db.GetAppIDList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appService := metadata.NewAppServiceWithDefault()
+	for _, appID := range appIDList {
+		err = appService.GetByID(ctx, appID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, app := range appService.GetApps() {
+		addOwnerID(app.GetOwnerID())
+	}
+
+	return ownerIDList, nil
+}
+
+// mergeSlowQueries merges the slow queries of the mysql servers of the same cluster,
+// slow queries with the same sql id and db name are aggregated,
+// and it returns the top n ones ordered by the total execution time descending
+func mergeSlowQueries(slowQueryLists [][]*query.SlowQuery, topNum int) []*query.SlowQuery {
+	var merged []*query.SlowQuery
+	slowQueryMap := make(map[string]*query.SlowQuery)
+
+	for _, slowQueries := range slowQueryLists {
+		for _, slowQuery := range slowQueries {
+			key := slowQuery.SQLID + slowQuery.DBName
+			existing, ok := slowQueryMap[key]
+			if !ok {
+				sq := *slowQuery
+				slowQueryMap[key] = &sq
+				merged = append(merged, &sq)
+				continue
+			}
+
+			existing.ExecCount += slowQuery.ExecCount
+			existing.TotalExecTime += slowQuery.TotalExecTime
+			if slowQuery.RowsExaminedMax > existing.RowsExaminedMax {
+				existing.RowsExaminedMax = slowQuery.RowsExaminedMax
+			}
+			if existing.ExecCount > constant.ZeroInt {
+				existing.AvgExecTime = existing.TotalExecTime / float64(existing.ExecCount)
+			}
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].TotalExecTime > merged[j].TotalExecTime
+	})
+	if topNum > constant.ZeroInt && len(merged) > topNum {
+		merged = merged[:topNum]
+	}
+
+	return merged
+}
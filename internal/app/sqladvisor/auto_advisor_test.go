@@ -0,0 +1,71 @@
+package sqladvisor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/internal/app/query"
+)
+
+func TestAutoAdvisorAll(t *testing.T) {
+	TestMergeSlowQueries(t)
+	TestAutoAdvisor_Stop(t)
+}
+
+func TestMergeSlowQueries(t *testing.T) {
+	asst := assert.New(t)
+
+	server1 := []*query.SlowQuery{
+		{SQLID: "A", DBName: "db1", ExecCount: 10, TotalExecTime: 10, AvgExecTime: 1, RowsExaminedMax: 100},
+		{SQLID: "B", DBName: "db1", ExecCount: 1, TotalExecTime: 5, AvgExecTime: 5, RowsExaminedMax: 10},
+	}
+	server2 := []*query.SlowQuery{
+		{SQLID: "A", DBName: "db1", ExecCount: 10, TotalExecTime: 30, AvgExecTime: 3, RowsExaminedMax: 200},
+		{SQLID: "A", DBName: "db2", ExecCount: 1, TotalExecTime: 8, AvgExecTime: 8, RowsExaminedMax: 10},
+	}
+
+	merged := mergeSlowQueries([][]*query.SlowQuery{server1, server2}, 2)
+	asst.Equal(2, len(merged), "test mergeSlowQueries() failed")
+	asst.Equal("A", merged[0].SQLID, "test mergeSlowQueries() failed")
+	asst.Equal("db1", merged[0].DBName, "test mergeSlowQueries() failed")
+	asst.Equal(20, merged[0].ExecCount, "test mergeSlowQueries() failed")
+	asst.Equal(40.0, merged[0].TotalExecTime, "test mergeSlowQueries() failed")
+	asst.Equal(2.0, merged[0].AvgExecTime, "test mergeSlowQueries() failed")
+	asst.Equal(200, merged[0].RowsExaminedMax, "test mergeSlowQueries() failed")
+	asst.Equal("db2", merged[1].DBName, "test mergeSlowQueries() failed")
+	// the input should not be modified
+	asst.Equal(10, server1[0].ExecCount, "test mergeSlowQueries() failed")
+
+	merged = mergeSlowQueries([][]*query.SlowQuery{server1, server2}, 0)
+	asst.Equal(3, len(merged), "test mergeSlowQueries() failed")
+}
+
+func TestAutoAdvisor_Stop(t *testing.T) {
+	asst := assert.New(t)
+
+	// stop without starting should not block
+	aa := NewAutoAdvisor(nil, nil, nil, time.Hour, 1)
+	aa.Stop()
+
+	aa = NewAutoAdvisor(nil, nil, nil, time.Hour, 1)
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		close(started)
+		aa.Start()
+		close(stopped)
+	}()
+	<-started
+	// give Start() a chance to enter the loop
+	time.Sleep(10 * time.Millisecond)
+	aa.Stop()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		asst.Fail("test Stop() failed", "Start() should return after Stop() returns")
+	}
+	// stop again should not block
+	aa.Stop()
+}
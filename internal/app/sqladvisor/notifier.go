@@ -0,0 +1,87 @@
+package sqladvisor
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+	"github.com/spf13/viper"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/internal/dependency/sqladvisor"
+	"github.com/romberli/das/pkg/message"
+	msgsa "github.com/romberli/das/pkg/message/sqladvisor"
+)
+
+var _ sqladvisor.Notifier = (*EmailNotifier)(nil)
+
+// EmailNotifier sends messages to the users by email,
+// if smtp address is empty, the messages will only be logged
+type EmailNotifier struct {
+	addr string
+	user string
+	pass string
+	from string
+}
+
+// NewEmailNotifier returns a new *EmailNotifier
+func NewEmailNotifier(addr, user, pass, from string) *EmailNotifier {
+	return &EmailNotifier{
+		addr: addr,
+		user: user,
+		pass: pass,
+		from: from,
+	}
+}
+
+// NewEmailNotifierWithDefault returns a new *EmailNotifier with smtp settings of the config
+func NewEmailNotifierWithDefault() *EmailNotifier {
+	return NewEmailNotifier(
+		viper.GetString(config.NotifySMTPAddrKey),
+		viper.GetString(config.NotifySMTPUserKey),
+		viper.GetString(config.NotifySMTPPassKey),
+		viper.GetString(config.NotifySMTPFromKey),
+	)
+}
+
+// Notify sends the message with given subject and content to the users,
+// users without email address will be ignored
+func (en *EmailNotifier) Notify(users []metadata.User, subject, content string) error {
+	var to []string
+	for _, user := range users {
+		email := strings.TrimSpace(user.GetEmail())
+		if email != constant.EmptyString {
+			to = append(to, email)
+		}
+	}
+	if len(to) == constant.ZeroInt {
+		return errors.New("none of the users has email address")
+	}
+
+	if en.addr == constant.EmptyString {
+		log.Info(message.NewMessage(msgsa.InfoSQLAdvisorAutoAdviceNotify, to, subject, content).Error())
+		return nil
+	}
+
+	var auth smtp.Auth
+	if en.user != constant.EmptyString {
+		host, _, err := net.SplitHostPort(en.addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth(constant.EmptyString, en.user, en.pass, host)
+	}
+
+	return smtp.SendMail(en.addr, auth, en.from, to, en.buildMessage(to, subject, content))
+}
+
+// buildMessage builds the email message which conforms to RFC 822
+func (en *EmailNotifier) buildMessage(to []string, subject, content string) []byte {
+	return []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		en.from, strings.Join(to, constant.CommaString), subject, content))
+}
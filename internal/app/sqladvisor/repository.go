@@ -1,8 +1,12 @@
 package sqladvisor
 
 import (
+	"context"
+
 	"github.com/romberli/das/global"
 	"github.com/romberli/das/internal/dependency/sqladvisor"
+	"github.com/romberli/das/pkg/tracing"
+	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
 	"github.com/romberli/log"
 )
@@ -24,9 +28,11 @@ func NewRepositoryWithGlobal() *Repository {
 }
 
 // Execute executes given command and placeholders on the middleware
-func (r *Repository) Execute(command string, args ...interface{}) (middleware.Result, error) {
+func (r *Repository) Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	_, span := tracing.StartSQLSpan(ctx, command)
 	conn, err := r.Database.Get()
 	if err != nil {
+		tracing.EndSpan(span, err)
 		return nil, err
 	}
 	defer func() {
@@ -36,7 +42,10 @@ func (r *Repository) Execute(command string, args ...interface{}) (middleware.Re
 		}
 	}()
 
-	return conn.Execute(command, args...)
+	result, err := conn.ExecuteContext(ctx, command, args...)
+	tracing.EndSpan(span, err)
+
+	return result, err
 }
 
 // Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
@@ -45,9 +54,84 @@ func (r *Repository) Transaction() (middleware.Transaction, error) {
 }
 
 // Save saves sql tuning advice into the middleware
-func (r *Repository) Save(dbID int, sqlText, advice, message string) error {
+func (r *Repository) Save(ctx context.Context, dbID int, sqlText, advice, message string) error {
 	sql := `insert into t_sa_operation_info(db_id, sql_text, advice, message) values(?, ?, ?, ?);`
-	_, err := r.Execute(sql, dbID, sqlText, advice, message)
+	_, err := r.Execute(ctx, sql, dbID, sqlText, advice, message)
+
+	return err
+}
+
+// GetSlowQueryAdviceBySQLID gets the slow query advices of all databases with given sql id from the middleware
+func (r *Repository) GetSlowQueryAdviceBySQLID(ctx context.Context, sqlID string) ([]sqladvisor.SlowQueryAdvice, error) {
+	sql := `
+		select id, mysql_cluster_id, db_id, sql_id, fingerprint, sql_text, advice, message, del_flag, create_time, last_update_time
+		from t_sa_slow_query_advice
+		where del_flag = 0
+		and sql_id = ?
+		order by id;
+	`
+	tracing.Logger(ctx).Debugf("sqladvisor Repository.GetSlowQueryAdviceBySQLID() sql: \n%s\nplaceholders: %s", sql, sqlID)
+
+	result, err := r.Execute(ctx, sql, sqlID)
+	if err != nil {
+		return nil, err
+	}
+	// init []*SlowQueryAdvice
+	slowQueryAdviceList := make([]*SlowQueryAdvice, result.RowNumber())
+	for i := range slowQueryAdviceList {
+		slowQueryAdviceList[i] = NewEmptySlowQueryAdvice()
+	}
+	// map to struct
+	err = result.MapToStructSlice(slowQueryAdviceList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []sqladvisor.SlowQueryAdvice
+	advices := make([]sqladvisor.SlowQueryAdvice, len(slowQueryAdviceList))
+	for i := range advices {
+		advices[i] = slowQueryAdviceList[i]
+	}
+
+	return advices, nil
+}
+
+// IsSlowQueryAdvised checks if the slow query with given sql id and fingerprint of the database had been advised
+func (r *Repository) IsSlowQueryAdvised(ctx context.Context, dbID int, sqlID, fingerprint string) (bool, error) {
+	sql := `
+		select count(*) from t_sa_slow_query_advice
+		where del_flag = 0
+		and db_id = ?
+		and sql_id = ?
+		and fingerprint = ?;
+	`
+	tracing.Logger(ctx).Debugf("sqladvisor Repository.IsSlowQueryAdvised() sql: \n%s\nplaceholders: %d, %s, %s", sql, dbID, sqlID, fingerprint)
+
+	result, err := r.Execute(ctx, sql, dbID, sqlID, fingerprint)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return false, err
+	}
+
+	return count > constant.ZeroInt, nil
+}
+
+// SaveSlowQueryAdvice saves the slow query advice into the middleware,
+// if the advice of the same database and sql id exists, it will be replaced
+func (r *Repository) SaveSlowQueryAdvice(ctx context.Context, advice sqladvisor.SlowQueryAdvice) error {
+	sql := `
+		insert into t_sa_slow_query_advice(mysql_cluster_id, db_id, sql_id, fingerprint, sql_text, advice, message)
+		values(?, ?, ?, ?, ?, ?, ?)
+		on duplicate key update mysql_cluster_id = values(mysql_cluster_id), fingerprint = values(fingerprint),
+		sql_text = values(sql_text), advice = values(advice), message = values(message), del_flag = 0;
+	`
+	tracing.Logger(ctx).Debugf("sqladvisor Repository.SaveSlowQueryAdvice() sql: \n%s\nplaceholders: %d, %d, %s", sql,
+		advice.GetMySQLClusterID(), advice.GetDBID(), advice.GetSQLID())
+
+	_, err := r.Execute(ctx, sql, advice.GetMySQLClusterID(), advice.GetDBID(), advice.GetSQLID(), advice.GetFingerprint(),
+		advice.GetSQLText(), advice.GetAdvice(), advice.GetMessage())
 
 	return err
 }
@@ -1,6 +1,7 @@
 package sqladvisor
 
 import (
+	"context"
 	"testing"
 
 	"github.com/romberli/das/global"
@@ -24,6 +25,8 @@ const (
 	defaultSQLText = "select * from t_meta_db_info where create_time<'2021-01-01';"
 	defaultAdvice  = "[\n {\n  \"ID\": \"B95017DB61875675\",\n  \"Fingerprint\": \"select * from t_meta_db_info where create_time\\u003c?\",\n  \"Score\": 95,\n  \"Sample\": \"select * from t_meta_db_info where create_time\\u003c'2021-01-01'\",\n  \"Explain\": null,\n  \"HeuristicRules\": [\n    {\n      \"Item\": \"COL.001\",\n      \"Severity\": \"L1\",\n      \"Summary\": \"不建议使用 SELECT * 类型查询\",\n      \"Content\": \"当表结构变更时，使用 * 通配符选择所有列将导致查询的含义和行为会发生更改，可能导致查询返回更多的数据。\",\n      \"Case\": \"select * from tbl where id=1\",\n      \"Position\": 0\n    }\n  ],\n  \"IndexRules\": null,\n  \"Tables\": [\n    \"`soar`.`t_meta_db_info`\"\n  ]\n}\n]"
 	defaultMessage = ""
+
+	defaultMySQLClusterID = 1
 )
 
 var repository = initRepository()
@@ -47,7 +50,14 @@ func initRepository() *Repository {
 
 func deleteResult() error {
 	sql := `delete from t_sa_operation_info;`
-	_, err := repository.Execute(sql)
+	_, err := repository.Execute(context.Background(), sql)
+
+	return err
+}
+
+func deleteSlowQueryAdvice() error {
+	sql := `delete from t_sa_slow_query_advice;`
+	_, err := repository.Execute(context.Background(), sql)
 
 	return err
 }
@@ -55,13 +65,16 @@ func deleteResult() error {
 func TestRepositoryAll(t *testing.T) {
 	TestRepository_Execute(t)
 	TestRepository_Save(t)
+	TestRepository_SaveSlowQueryAdvice(t)
+	TestRepository_IsSlowQueryAdvised(t)
+	TestRepository_GetSlowQueryAdviceBySQLID(t)
 }
 
 func TestRepository_Execute(t *testing.T) {
 	asst := assert.New(t)
 
 	sql := "select 1;"
-	result, err := repository.Execute(sql)
+	result, err := repository.Execute(context.Background(), sql)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
 	r, err := result.GetInt(0, 0)
 	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
@@ -72,7 +85,61 @@ func TestRepository_Save(t *testing.T) {
 	asst := assert.New(t)
 
 	err := deleteResult()
-	err = repository.Save(defaultDBID, defaultSQLText, defaultAdvice, defaultMessage)
+	err = repository.Save(context.Background(), defaultDBID, defaultSQLText, defaultAdvice, defaultMessage)
 	asst.Nil(err, "test Save() failed")
 	err = deleteResult()
 }
+
+func TestRepository_SaveSlowQueryAdvice(t *testing.T) {
+	asst := assert.New(t)
+
+	err := deleteSlowQueryAdvice()
+	asst.Nil(err, common.CombineMessageWithError("test SaveSlowQueryAdvice() failed", err))
+	advice := NewSlowQueryAdvice(defaultMySQLClusterID, defaultDBID, defaultSQLID, defaultFingerprint, defaultSQLText, defaultAdvice, defaultMessage)
+	err = repository.SaveSlowQueryAdvice(context.Background(), advice)
+	asst.Nil(err, common.CombineMessageWithError("test SaveSlowQueryAdvice() failed", err))
+	// save again should replace the existing one
+	err = repository.SaveSlowQueryAdvice(context.Background(), advice)
+	asst.Nil(err, common.CombineMessageWithError("test SaveSlowQueryAdvice() failed", err))
+	advices, err := repository.GetSlowQueryAdviceBySQLID(context.Background(), defaultSQLID)
+	asst.Nil(err, common.CombineMessageWithError("test SaveSlowQueryAdvice() failed", err))
+	asst.Equal(1, len(advices), "test SaveSlowQueryAdvice() failed")
+	err = deleteSlowQueryAdvice()
+	asst.Nil(err, common.CombineMessageWithError("test SaveSlowQueryAdvice() failed", err))
+}
+
+func TestRepository_IsSlowQueryAdvised(t *testing.T) {
+	asst := assert.New(t)
+
+	err := deleteSlowQueryAdvice()
+	asst.Nil(err, common.CombineMessageWithError("test IsSlowQueryAdvised() failed", err))
+	advised, err := repository.IsSlowQueryAdvised(context.Background(), defaultDBID, defaultSQLID, defaultFingerprint)
+	asst.Nil(err, common.CombineMessageWithError("test IsSlowQueryAdvised() failed", err))
+	asst.False(advised, "test IsSlowQueryAdvised() failed")
+	err = repository.SaveSlowQueryAdvice(context.Background(), NewSlowQueryAdvice(defaultMySQLClusterID, defaultDBID, defaultSQLID, defaultFingerprint, defaultSQLText, defaultAdvice, defaultMessage))
+	asst.Nil(err, common.CombineMessageWithError("test IsSlowQueryAdvised() failed", err))
+	advised, err = repository.IsSlowQueryAdvised(context.Background(), defaultDBID, defaultSQLID, defaultFingerprint)
+	asst.Nil(err, common.CombineMessageWithError("test IsSlowQueryAdvised() failed", err))
+	asst.True(advised, "test IsSlowQueryAdvised() failed")
+	// changed fingerprint should be advised again
+	advised, err = repository.IsSlowQueryAdvised(context.Background(), defaultDBID, defaultSQLID, defaultFingerprint+" limit ?")
+	asst.Nil(err, common.CombineMessageWithError("test IsSlowQueryAdvised() failed", err))
+	asst.False(advised, "test IsSlowQueryAdvised() failed")
+	err = deleteSlowQueryAdvice()
+	asst.Nil(err, common.CombineMessageWithError("test IsSlowQueryAdvised() failed", err))
+}
+
+func TestRepository_GetSlowQueryAdviceBySQLID(t *testing.T) {
+	asst := assert.New(t)
+
+	err := deleteSlowQueryAdvice()
+	asst.Nil(err, common.CombineMessageWithError("test GetSlowQueryAdviceBySQLID() failed", err))
+	err = repository.SaveSlowQueryAdvice(context.Background(), NewSlowQueryAdvice(defaultMySQLClusterID, defaultDBID, defaultSQLID, defaultFingerprint, defaultSQLText, defaultAdvice, defaultMessage))
+	asst.Nil(err, common.CombineMessageWithError("test GetSlowQueryAdviceBySQLID() failed", err))
+	advices, err := repository.GetSlowQueryAdviceBySQLID(context.Background(), defaultSQLID)
+	asst.Nil(err, common.CombineMessageWithError("test GetSlowQueryAdviceBySQLID() failed", err))
+	asst.Equal(1, len(advices), "test GetSlowQueryAdviceBySQLID() failed")
+	asst.Equal(defaultFingerprint, advices[0].GetFingerprint(), "test GetSlowQueryAdviceBySQLID() failed")
+	err = deleteSlowQueryAdvice()
+	asst.Nil(err, common.CombineMessageWithError("test GetSlowQueryAdviceBySQLID() failed", err))
+}
@@ -1,20 +1,30 @@
 package sqladvisor
 
 import (
+	"context"
+
 	"github.com/romberli/das/config"
 	"github.com/romberli/das/internal/dependency/sqladvisor"
+	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/log"
 	"github.com/spf13/viper"
 )
 
+const (
+	SlowQueryAdvicesStruct = "SlowQueryAdvices"
+	SQLInfosStruct         = "SQLInfos"
+)
+
 var _ sqladvisor.Service = (*Service)(nil)
 
 type Service struct {
 	sqladvisor.Repository
-	Advisor sqladvisor.Advisor
-	Advice  string `json:"advice"`
-	Message string `json:"message"`
+	Advisor          sqladvisor.Advisor
+	Advice           string                       `json:"advice"`
+	Message          string                       `json:"message"`
+	SlowQueryAdvices []sqladvisor.SlowQueryAdvice `json:"slow_query_advices"`
+	SQLInfos         []*SQLInfo                   `json:"sql_infos"`
 }
 
 // NewService returns a new *Service
@@ -33,11 +43,23 @@ func NewServiceWithDefault() *Service {
 // newService returns a new *Service
 func newService(soarBin, configFile string) *Service {
 	return &Service{
-		Repository: NewRepositoryWithGlobal(),
-		Advisor:    NewDefaultAdvisor(soarBin, configFile),
+		Repository:       NewRepositoryWithGlobal(),
+		Advisor:          NewDefaultAdvisor(soarBin, configFile),
+		SlowQueryAdvices: []sqladvisor.SlowQueryAdvice{},
+		SQLInfos:         []*SQLInfo{},
 	}
 }
 
+// GetSlowQueryAdvices returns the slow query advices of the service
+func (s *Service) GetSlowQueryAdvices() []sqladvisor.SlowQueryAdvice {
+	return s.SlowQueryAdvices
+}
+
+// GetSQLInfos returns the sql infos of the service
+func (s *Service) GetSQLInfos() []*SQLInfo {
+	return s.SQLInfos
+}
+
 // GetFingerprint returns the fingerprint of the sql text
 func (s *Service) GetFingerprint(sqlText string) string {
 	return s.Advisor.GetFingerprint(sqlText)
@@ -50,13 +72,13 @@ func (s *Service) GetSQLID(sqlText string) string {
 
 // Advise parses the sql text and returns the tuning advice,
 // note that only the first sql statement in the sql text will be advised
-func (s *Service) Advise(dbID int, sqlText string) (string, error) {
+func (s *Service) Advise(ctx context.Context, dbID int, sqlText string) (string, error) {
 	sqlList, err := s.Advisor.GetParser().Split(sqlText)
 	if err != nil {
 		return constant.EmptyString, err
 	}
 
-	advice, message, err := s.Advisor.Advise(dbID, sqlList[constant.ZeroInt])
+	advice, message, err := s.Advisor.Advise(ctx, dbID, sqlList[constant.ZeroInt])
 	if err != nil {
 		return constant.EmptyString, nil
 	}
@@ -65,10 +87,43 @@ func (s *Service) Advise(dbID int, sqlText string) (string, error) {
 		log.Infof("advisor message: %s", message)
 	}
 
-	err = s.Repository.Save(dbID, sqlText, advice, message)
+	err = s.Repository.Save(ctx, dbID, sqlText, advice, message)
 	if err != nil {
 		return constant.EmptyString, err
 	}
 
 	return advice, nil
 }
+
+// ParseSQLInfos gets the fingerprint, sql id and statement type of each sql text,
+// the sql text which could not be parsed still has the fingerprint and sql id, but its statement type is unknown
+func (s *Service) ParseSQLInfos(sqlTexts []string) {
+	p := s.Advisor.GetParser()
+
+	sqlInfos := make([]*SQLInfo, len(sqlTexts))
+	for i, sqlText := range sqlTexts {
+		statementType := StatementTypeUnknown
+		stmtNodes, err := p.GetStatementNodes(sqlText)
+		if err == nil && len(stmtNodes) > constant.ZeroInt {
+			statementType = getStatementType(stmtNodes[constant.ZeroInt])
+		}
+		sqlInfos[i] = NewSQLInfo(sqlText, p.GetFingerprint(sqlText), p.GetSQLID(sqlText), statementType)
+	}
+
+	s.SQLInfos = sqlInfos
+}
+
+// GetAdviceBySQLID gets the slow query advices of all databases with given sql id,
+// these advices are generated by the auto advisor in background
+func (s *Service) GetAdviceBySQLID(ctx context.Context, sqlID string) error {
+	var err error
+
+	s.SlowQueryAdvices, err = s.Repository.GetSlowQueryAdviceBySQLID(ctx, sqlID)
+
+	return err
+}
+
+// MarshalWithFields marshals only specified fields of the Service to json bytes
+func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(s, fields...)
+}
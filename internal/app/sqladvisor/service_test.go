@@ -1,6 +1,7 @@
 package sqladvisor
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,6 +24,7 @@ func TestService_All(t *testing.T) {
 	TestService_GetFingerprint(t)
 	TestService_GetFingerprint(t)
 	TestService_GetSQLID(t)
+	TestService_ParseSQLInfos(t)
 	TestService_Advise(t)
 }
 
@@ -40,10 +42,21 @@ func TestService_GetSQLID(t *testing.T) {
 	asst.Equal(defaultSQLID, sqlID, "test GetSQLID() failed")
 }
 
+func TestService_ParseSQLInfos(t *testing.T) {
+	asst := assert.New(t)
+
+	service.ParseSQLInfos([]string{defaultSQLText, "not a valid sql"})
+	asst.Equal(2, len(service.GetSQLInfos()), "test ParseSQLInfos() failed")
+	asst.Equal(defaultFingerprint, service.GetSQLInfos()[0].Fingerprint, "test ParseSQLInfos() failed")
+	asst.Equal(defaultSQLID, service.GetSQLInfos()[0].SQLID, "test ParseSQLInfos() failed")
+	asst.Equal(StatementTypeSelect, service.GetSQLInfos()[0].StatementType, "test ParseSQLInfos() failed")
+	asst.Equal(StatementTypeUnknown, service.GetSQLInfos()[1].StatementType, "test ParseSQLInfos() failed")
+}
+
 func TestService_Advise(t *testing.T) {
 	asst := assert.New(t)
 
-	advice, err := service.Advise(defaultDBID, defaultSQLText)
+	advice, err := service.Advise(context.Background(), defaultDBID, defaultSQLText)
 	asst.Nil(err, "test Advise() failed")
 	asst.NotEmpty(advice, "test Advise() failed")
 }
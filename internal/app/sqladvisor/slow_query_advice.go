@@ -0,0 +1,110 @@
+package sqladvisor
+
+import (
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+
+	"github.com/romberli/das/internal/dependency/sqladvisor"
+)
+
+var _ sqladvisor.SlowQueryAdvice = (*SlowQueryAdvice)(nil)
+
+// SlowQueryAdvice is the tuning advice of a slow query fingerprint of a database
+type SlowQueryAdvice struct {
+	ID             int       `middleware:"id" json:"id"`
+	MySQLClusterID int       `middleware:"mysql_cluster_id" json:"mysql_cluster_id"`
+	DBID           int       `middleware:"db_id" json:"db_id"`
+	SQLID          string    `middleware:"sql_id" json:"sql_id"`
+	Fingerprint    string    `middleware:"fingerprint" json:"fingerprint"`
+	SQLText        string    `middleware:"sql_text" json:"sql_text"`
+	Advice         string    `middleware:"advice" json:"advice"`
+	Message        string    `middleware:"message" json:"message"`
+	DelFlag        int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime     time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewSlowQueryAdvice returns a new *SlowQueryAdvice
+func NewSlowQueryAdvice(mysqlClusterID, dbID int, sqlID, fingerprint, sqlText, advice, message string) *SlowQueryAdvice {
+	return &SlowQueryAdvice{
+		MySQLClusterID: mysqlClusterID,
+		DBID:           dbID,
+		SQLID:          sqlID,
+		Fingerprint:    fingerprint,
+		SQLText:        sqlText,
+		Advice:         advice,
+		Message:        message,
+	}
+}
+
+// NewEmptySlowQueryAdvice returns an empty *SlowQueryAdvice
+func NewEmptySlowQueryAdvice() *SlowQueryAdvice {
+	return &SlowQueryAdvice{}
+}
+
+// Identity returns the identity
+func (sqa *SlowQueryAdvice) Identity() int {
+	return sqa.ID
+}
+
+// GetMySQLClusterID returns the mysql cluster id
+func (sqa *SlowQueryAdvice) GetMySQLClusterID() int {
+	return sqa.MySQLClusterID
+}
+
+// GetDBID returns the db id
+func (sqa *SlowQueryAdvice) GetDBID() int {
+	return sqa.DBID
+}
+
+// GetSQLID returns the sql id
+func (sqa *SlowQueryAdvice) GetSQLID() string {
+	return sqa.SQLID
+}
+
+// GetFingerprint returns the fingerprint
+func (sqa *SlowQueryAdvice) GetFingerprint() string {
+	return sqa.Fingerprint
+}
+
+// GetSQLText returns the sql text
+func (sqa *SlowQueryAdvice) GetSQLText() string {
+	return sqa.SQLText
+}
+
+// GetAdvice returns the advice
+func (sqa *SlowQueryAdvice) GetAdvice() string {
+	return sqa.Advice
+}
+
+// GetMessage returns the message
+func (sqa *SlowQueryAdvice) GetMessage() string {
+	return sqa.Message
+}
+
+// GetDelFlag returns the delete flag
+func (sqa *SlowQueryAdvice) GetDelFlag() int {
+	return sqa.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (sqa *SlowQueryAdvice) GetCreateTime() time.Time {
+	return sqa.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (sqa *SlowQueryAdvice) GetLastUpdateTime() time.Time {
+	return sqa.LastUpdateTime
+}
+
+// MarshalJSON marshals SlowQueryAdvice to json string
+func (sqa *SlowQueryAdvice) MarshalJSON() ([]byte, error) {
+	return common.MarshalStructWithTag(sqa, constant.DefaultMarshalTag)
+}
+
+// MarshalJSONWithFields marshals only specified fields of the SlowQueryAdvice to json string
+func (sqa *SlowQueryAdvice) MarshalJSONWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(sqa, fields...)
+}
@@ -1,16 +1,20 @@
 package sqladvisor
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os/exec"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/romberli/das/config"
 	"github.com/romberli/das/internal/app/metadata"
 	"github.com/romberli/das/internal/dependency/sqladvisor"
+	"github.com/romberli/das/pkg/metrics"
+	"github.com/romberli/das/pkg/tracing"
 	"github.com/romberli/go-util/constant"
-	"github.com/romberli/go-util/linux"
 	"github.com/romberli/go-util/middleware/sql/parser"
 	"github.com/spf13/viper"
 )
@@ -64,30 +68,34 @@ func (da *DefaultAdvisor) GetSQLID(sqlText string) string {
 
 // Advise parses the sql text and returns the tuning advice,
 // note that only the first sql statement in the sql text will be advised
-func (da *DefaultAdvisor) Advise(dbID int, sqlText string) (string, string, error) {
-	return da.adviseWithDefault(dbID, sqlText)
+func (da *DefaultAdvisor) Advise(ctx context.Context, dbID int, sqlText string) (string, string, error) {
+	return da.adviseWithDefault(ctx, dbID, sqlText)
 }
 
 // advise parses the sql text and returns the tuning advice,
 // note that only the first sql statement in the sql text will be advised
-func (da *DefaultAdvisor) adviseWithDefault(dbID int, sqlText string) (string, string, error) {
+func (da *DefaultAdvisor) adviseWithDefault(ctx context.Context, dbID int, sqlText string) (string, string, error) {
 	user := viper.GetString(config.DBSoarMySQLUserKey)
 	pass := viper.GetString(config.DBSoarMySQLPassKey)
 
-	return da.advise(dbID, sqlText, user, pass)
+	return da.advise(ctx, dbID, sqlText, user, pass)
 }
 
 // advise parses the sql text and returns the tuning advice,
 // note that only the first sql statement in the sql text will be advised
-func (da *DefaultAdvisor) advise(dbID int, sqlText, user, pass string) (string, string, error) {
-	dsn, err := da.getOnlineDSN(dbID, user, pass)
+func (da *DefaultAdvisor) advise(ctx context.Context, dbID int, sqlText, user, pass string) (string, string, error) {
+	dsn, err := da.getOnlineDSN(ctx, dbID, user, pass)
 	if err != nil {
 		return constant.EmptyString, constant.EmptyString, err
 	}
 
 	command := fmt.Sprintf(`%s -config=%s -online-dsn=%s -query="%s"`, da.soarBin, da.configFile, dsn, sqlText)
 
-	result, err := linux.ExecuteCommand(command)
+	_, span := tracing.StartSoarSpan(ctx, dbID)
+	startTime := time.Now()
+	result, err := executeCommand(ctx, command)
+	metrics.ObserveSoar(startTime, err)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return constant.EmptyString, constant.EmptyString, err
 	}
@@ -96,19 +104,21 @@ func (da *DefaultAdvisor) advise(dbID int, sqlText, user, pass string) (string,
 }
 
 // getOnlineDSN returns the online dsn which will be used by soar
-func (da *DefaultAdvisor) getOnlineDSNWithDefault(dbID int) (string, error) {
+func (da *DefaultAdvisor) getOnlineDSNWithDefault(ctx context.Context, dbID int) (string, error) {
 	user := viper.GetString(config.DBSoarMySQLUserKey)
 	pass := viper.GetString(config.DBSoarMySQLPassKey)
 
-	return da.getOnlineDSN(dbID, user, pass)
+	return da.getOnlineDSN(ctx, dbID, user, pass)
 }
 
-func (da *DefaultAdvisor) getOnlineDSN(dbID int, user, pass string) (string, error) {
+// getOnlineDSN returns the online dsn which will be used by soar,
+// it prefers a healthy replica of the cluster, and falls back to the primary only when it is allowed
+func (da *DefaultAdvisor) getOnlineDSN(ctx context.Context, dbID int, user, pass string) (string, error) {
 	// get db service
 	dbService := metadata.NewDBServiceWithDefault()
-	err := dbService.GetByID(dbID)
+	err := dbService.GetByID(ctx, dbID)
 	if err != nil {
-		return constant.EmptyString, nil
+		return constant.EmptyString, err
 	}
 	// get db
 	db := dbService.DBs[constant.ZeroInt]
@@ -116,14 +126,16 @@ func (da *DefaultAdvisor) getOnlineDSN(dbID int, user, pass string) (string, err
 	dbName := db.GetDBName()
 	// get mysql server service
 	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
-	err = mysqlServerService.GetByClusterID(clusterID)
+	err = mysqlServerService.GetHealthyReadableByClusterID(
+		ctx,
+		clusterID,
+		viper.GetString(config.DBApplicationMySQLUserKey),
+		viper.GetString(config.DBApplicationMySQLPassKey),
+		viper.GetInt(config.DBApplicationMySQLMaxReplicationDelayKey),
+		viper.GetBool(config.DBApplicationMySQLAllowPrimaryKey),
+	)
 	if err != nil {
-		return constant.EmptyString, err
-	}
-
-	mysqlServers := mysqlServerService.GetMySQLServers()
-	if len(mysqlServers) == constant.ZeroInt {
-		return constant.EmptyString, errors.New(fmt.Sprintf("could not find mysql server of the database. db id: %d", dbID))
+		return constant.EmptyString, errors.New(fmt.Sprintf("could not find mysql server of the database. db id: %d\n%s", dbID, err.Error()))
 	}
 	// get mysql server
 	mysqlServer := mysqlServerService.GetMySQLServers()[constant.ZeroInt]
@@ -179,3 +191,15 @@ func (da *DefaultAdvisor) parseResult(result string) (string, string, error) {
 
 	return advice, message, nil
 }
+
+// executeCommand executes the shell command and returns the combined output,
+// the command is killed when the context is done, and the error of the context is returned in that case,
+// the shell is replaced by the command, otherwise only the shell would be killed and the command kept running
+func executeCommand(ctx context.Context, command string) (string, error) {
+	output, err := exec.CommandContext(ctx, "/bin/sh", "-c", "exec "+command).CombinedOutput()
+	if ctx.Err() != nil {
+		return string(output), ctx.Err()
+	}
+
+	return string(output), err
+}
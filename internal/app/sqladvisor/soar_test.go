@@ -1,7 +1,9 @@
 package sqladvisor
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,6 +19,7 @@ func TestDefaultAdvisor_All(t *testing.T) {
 	TestDefaultAdvisor_GetFingerprint(t)
 	TestDefaultAdvisor_GetSQLID(t)
 	TestDefaultAdvisor_Advise(t)
+	TestExecuteCommand(t)
 }
 
 func TestDefaultAdvisor_GetFingerprint(t *testing.T) {
@@ -36,9 +39,24 @@ func TestDefaultAdvisor_GetSQLID(t *testing.T) {
 func TestDefaultAdvisor_Advise(t *testing.T) {
 	asst := assert.New(t)
 
-	advice, message, err := advisor.advise(defaultDBID, defaultSQLText, defaultDBSoarMySQLUser, defaultDBSoarMySQLPass)
+	advice, message, err := advisor.advise(context.Background(), defaultDBID, defaultSQLText, defaultDBSoarMySQLUser, defaultDBSoarMySQLPass)
 	asst.Nil(err, "test Advise() failed")
 	asst.NotEmpty(advice, "test Advise() failed")
 	t.Log(message)
 	t.Log(advice)
 }
+
+func TestExecuteCommand(t *testing.T) {
+	asst := assert.New(t)
+
+	output, err := executeCommand(context.Background(), "echo test")
+	asst.Nil(err, "test executeCommand() failed")
+	asst.Equal("test\n", output, "test executeCommand() failed")
+	// the command is killed when the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	startTime := time.Now()
+	_, err = executeCommand(ctx, "sleep 10")
+	asst.Equal(context.DeadlineExceeded, err, "test executeCommand() failed")
+	asst.Less(time.Since(startTime), 5*time.Second, "test executeCommand() failed")
+}
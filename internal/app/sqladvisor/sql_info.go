@@ -0,0 +1,77 @@
+package sqladvisor
+
+import (
+	"github.com/pingcap/parser/ast"
+)
+
+const (
+	StatementTypeSelect      = "select"
+	StatementTypeInsert      = "insert"
+	StatementTypeReplace     = "replace"
+	StatementTypeUpdate      = "update"
+	StatementTypeDelete      = "delete"
+	StatementTypeCreateTable = "create_table"
+	StatementTypeAlterTable  = "alter_table"
+	StatementTypeDropTable   = "drop_table"
+	StatementTypeTruncate    = "truncate_table"
+	StatementTypeCreateIndex = "create_index"
+	StatementTypeDropIndex   = "drop_index"
+	StatementTypeCreateDB    = "create_database"
+	StatementTypeDropDB      = "drop_database"
+	StatementTypeOther       = "other"
+	// StatementTypeUnknown is used when the sql text could not be parsed
+	StatementTypeUnknown = "unknown"
+)
+
+// SQLInfo is the identity information of a sql statement
+type SQLInfo struct {
+	SQLText       string `json:"sql_text"`
+	Fingerprint   string `json:"fingerprint"`
+	SQLID         string `json:"sql_id"`
+	StatementType string `json:"statement_type"`
+}
+
+// NewSQLInfo returns a new *SQLInfo
+func NewSQLInfo(sqlText, fingerprint, sqlID, statementType string) *SQLInfo {
+	return &SQLInfo{
+		SQLText:       sqlText,
+		Fingerprint:   fingerprint,
+		SQLID:         sqlID,
+		StatementType: statementType,
+	}
+}
+
+// getStatementType returns the normalized statement type of the statement node
+func getStatementType(stmtNode ast.StmtNode) string {
+	switch node := stmtNode.(type) {
+	case *ast.SelectStmt, *ast.SetOprStmt:
+		return StatementTypeSelect
+	case *ast.InsertStmt:
+		if node.IsReplace {
+			return StatementTypeReplace
+		}
+		return StatementTypeInsert
+	case *ast.UpdateStmt:
+		return StatementTypeUpdate
+	case *ast.DeleteStmt:
+		return StatementTypeDelete
+	case *ast.CreateTableStmt:
+		return StatementTypeCreateTable
+	case *ast.AlterTableStmt:
+		return StatementTypeAlterTable
+	case *ast.DropTableStmt:
+		return StatementTypeDropTable
+	case *ast.TruncateTableStmt:
+		return StatementTypeTruncate
+	case *ast.CreateIndexStmt:
+		return StatementTypeCreateIndex
+	case *ast.DropIndexStmt:
+		return StatementTypeDropIndex
+	case *ast.CreateDatabaseStmt:
+		return StatementTypeCreateDB
+	case *ast.DropDatabaseStmt:
+		return StatementTypeDropDB
+	default:
+		return StatementTypeOther
+	}
+}
@@ -0,0 +1,35 @@
+package sqladvisor
+
+import (
+	"testing"
+
+	"github.com/romberli/go-util/middleware/sql/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLInfoAll(t *testing.T) {
+	TestGetStatementType(t)
+}
+
+func TestGetStatementType(t *testing.T) {
+	asst := assert.New(t)
+
+	sqlTypes := map[string]string{
+		"select * from t01 union select * from t02;": StatementTypeSelect,
+		"insert into t01(id) values(1);":             StatementTypeInsert,
+		"replace into t01(id) values(1);":            StatementTypeReplace,
+		"update t01 set c1 = 1 where id = 1;":        StatementTypeUpdate,
+		"delete from t01 where id = 1;":              StatementTypeDelete,
+		"create table t01(id int primary key);":      StatementTypeCreateTable,
+		"alter table t01 add column c1 int;":         StatementTypeAlterTable,
+		"create index idx01 on t01(c1);":             StatementTypeCreateIndex,
+		"show tables;":                               StatementTypeOther,
+	}
+
+	p := parser.NewParserWithDefault()
+	for sql, sqlType := range sqlTypes {
+		stmtNodes, err := p.GetStatementNodes(sql)
+		asst.Nil(err, "test getStatementType() failed")
+		asst.Equal(sqlType, getStatementType(stmtNodes[0]), "test getStatementType() failed. sql: %s", sql)
+	}
+}
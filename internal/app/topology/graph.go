@@ -0,0 +1,247 @@
+package topology
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/romberli/go-util/constant"
+
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+)
+
+const (
+	NodeTypeApp               = "app"
+	NodeTypeDB                = "db"
+	NodeTypeMySQLCluster      = "mysql_cluster"
+	NodeTypeMySQLServer       = "mysql_server"
+	NodeTypeMiddlewareCluster = "middleware_cluster"
+	NodeTypeMiddlewareServer  = "middleware_server"
+	NodeTypeMonitorSystem     = "monitor_system"
+
+	// EdgeTypeUses links an app to the database it uses
+	EdgeTypeUses = "uses"
+	// EdgeTypeHostedBy links a database to the mysql cluster or the middleware cluster which hosts it
+	EdgeTypeHostedBy = "hosted_by"
+	// EdgeTypeMemberOf links a server to the cluster which it belongs to
+	EdgeTypeMemberOf = "member_of"
+	// EdgeTypeProxiedBy links a mysql cluster to the middleware cluster in front of it
+	EdgeTypeProxiedBy = "proxied_by"
+	// EdgeTypeMonitoredBy links a mysql cluster to its monitor system
+	EdgeTypeMonitoredBy = "monitored_by"
+
+	DefaultDepth = 2
+	MaxDepth     = 10
+
+	// clusterTypeSingle means the database is hosted by a mysql cluster,
+	// otherwise it is sharded and hosted by a middleware cluster
+	clusterTypeSingle = 1
+)
+
+var nodeTypes = map[string]bool{
+	NodeTypeApp:               true,
+	NodeTypeDB:                true,
+	NodeTypeMySQLCluster:      true,
+	NodeTypeMySQLServer:       true,
+	NodeTypeMiddlewareCluster: true,
+	NodeTypeMiddlewareServer:  true,
+	NodeTypeMonitorSystem:     true,
+}
+
+// IsValidNodeType returns if the node type is supported
+func IsValidNodeType(nodeType string) bool {
+	return nodeTypes[nodeType]
+}
+
+// Node is a metadata entity in the graph
+type Node struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	EntityID int    `json:"entity_id"`
+	Name     string `json:"name"`
+}
+
+// Edge is a relationship between two nodes, it points from the dependent to the dependency
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+// Snapshot is all the metadata entities and relationships which the graph is built from
+type Snapshot struct {
+	Apps               []depmeta.App
+	DBs                []depmeta.DB
+	MySQLClusters      []depmeta.MySQLCluster
+	MySQLServers       []depmeta.MySQLServer
+	MiddlewareClusters []depmeta.MiddlewareCluster
+	MiddlewareServers  []depmeta.MiddlewareServer
+	MonitorSystems     []depmeta.MonitorSystem
+	// AppDBMap is the database identities of each app, the key is the app id
+	AppDBMap map[int][]int
+}
+
+// Graph is a set of nodes and the edges between them
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+
+	nodes     map[string]*Node
+	adjacency map[string][]*Edge
+}
+
+// newGraph returns an empty *Graph
+func newGraph() *Graph {
+	return &Graph{
+		Nodes:     []*Node{},
+		Edges:     []*Edge{},
+		nodes:     make(map[string]*Node),
+		adjacency: make(map[string][]*Edge),
+	}
+}
+
+// NewGraph returns the whole graph of the snapshot,
+// the edges whose endpoints do not exist, such as a database of a deleted cluster, are ignored
+func NewGraph(snapshot *Snapshot) *Graph {
+	g := newGraph()
+
+	for _, app := range snapshot.Apps {
+		g.addNode(NodeTypeApp, app.Identity(), app.GetAppName())
+	}
+	for _, db := range snapshot.DBs {
+		g.addNode(NodeTypeDB, db.Identity(), db.GetDBName())
+	}
+	for _, mysqlCluster := range snapshot.MySQLClusters {
+		g.addNode(NodeTypeMySQLCluster, mysqlCluster.Identity(), mysqlCluster.GetClusterName())
+	}
+	for _, mysqlServer := range snapshot.MySQLServers {
+		g.addNode(NodeTypeMySQLServer, mysqlServer.Identity(), getAddr(mysqlServer.GetHostIP(), mysqlServer.GetPortNum()))
+	}
+	for _, middlewareCluster := range snapshot.MiddlewareClusters {
+		g.addNode(NodeTypeMiddlewareCluster, middlewareCluster.Identity(), middlewareCluster.GetClusterName())
+	}
+	for _, middlewareServer := range snapshot.MiddlewareServers {
+		g.addNode(NodeTypeMiddlewareServer, middlewareServer.Identity(), getAddr(middlewareServer.GetHostIP(), middlewareServer.GetPortNum()))
+	}
+	for _, monitorSystem := range snapshot.MonitorSystems {
+		g.addNode(NodeTypeMonitorSystem, monitorSystem.Identity(), monitorSystem.GetSystemName())
+	}
+
+	appIDs := make([]int, 0, len(snapshot.AppDBMap))
+	for appID := range snapshot.AppDBMap {
+		appIDs = append(appIDs, appID)
+	}
+	sort.Ints(appIDs)
+	for _, appID := range appIDs {
+		for _, dbID := range snapshot.AppDBMap[appID] {
+			g.addEdge(getNodeID(NodeTypeApp, appID), getNodeID(NodeTypeDB, dbID), EdgeTypeUses)
+		}
+	}
+	for _, db := range snapshot.DBs {
+		clusterNodeType := NodeTypeMySQLCluster
+		if db.GetClusterType() != clusterTypeSingle {
+			clusterNodeType = NodeTypeMiddlewareCluster
+		}
+		g.addEdge(getNodeID(NodeTypeDB, db.Identity()), getNodeID(clusterNodeType, db.GetClusterID()), EdgeTypeHostedBy)
+	}
+	for _, mysqlCluster := range snapshot.MySQLClusters {
+		clusterNodeID := getNodeID(NodeTypeMySQLCluster, mysqlCluster.Identity())
+		g.addEdge(clusterNodeID, getNodeID(NodeTypeMiddlewareCluster, mysqlCluster.GetMiddlewareClusterID()), EdgeTypeProxiedBy)
+		g.addEdge(clusterNodeID, getNodeID(NodeTypeMonitorSystem, mysqlCluster.GetMonitorSystemID()), EdgeTypeMonitoredBy)
+	}
+	for _, mysqlServer := range snapshot.MySQLServers {
+		g.addEdge(getNodeID(NodeTypeMySQLServer, mysqlServer.Identity()), getNodeID(NodeTypeMySQLCluster, mysqlServer.GetClusterID()), EdgeTypeMemberOf)
+	}
+	for _, middlewareServer := range snapshot.MiddlewareServers {
+		g.addEdge(getNodeID(NodeTypeMiddlewareServer, middlewareServer.Identity()),
+			getNodeID(NodeTypeMiddlewareCluster, middlewareServer.GetClusterID()), EdgeTypeMemberOf)
+	}
+
+	return g
+}
+
+// addNode adds the node to the graph
+func (g *Graph) addNode(nodeType string, entityID int, name string) *Node {
+	node := &Node{
+		ID:       getNodeID(nodeType, entityID),
+		Type:     nodeType,
+		EntityID: entityID,
+		Name:     name,
+	}
+	g.Nodes = append(g.Nodes, node)
+	g.nodes[node.ID] = node
+
+	return node
+}
+
+// addEdge adds the edge to the graph if both of the nodes exist
+func (g *Graph) addEdge(source, target, edgeType string) {
+	if g.nodes[source] == nil || g.nodes[target] == nil {
+		return
+	}
+
+	edge := &Edge{Source: source, Target: target, Type: edgeType}
+	g.Edges = append(g.Edges, edge)
+	g.adjacency[source] = append(g.adjacency[source], edge)
+	g.adjacency[target] = append(g.adjacency[target], edge)
+}
+
+// GetNode returns the node of the node type and entity id, it returns nil if the node does not exist
+func (g *Graph) GetNode(nodeType string, entityID int) *Node {
+	return g.nodes[getNodeID(nodeType, entityID)]
+}
+
+// Subgraph returns the nodes within depth edges of the start node and the edges between them,
+// edges are walked in both directions, but a monitor system is not walked through unless it is the start node,
+// otherwise all the clusters which share the monitor system would be reached
+func (g *Graph) Subgraph(nodeType string, entityID, depth int) (*Graph, error) {
+	start := g.GetNode(nodeType, entityID)
+	if start == nil {
+		return nil, fmt.Errorf("%s %d does not exist: %w", nodeType, entityID, ErrDataNotExists)
+	}
+
+	distances := map[string]int{start.ID: constant.ZeroInt}
+	queue := []*Node{start}
+	for len(queue) > constant.ZeroInt {
+		node := queue[constant.ZeroInt]
+		queue = queue[1:]
+
+		if distances[node.ID] >= depth || (node.Type == NodeTypeMonitorSystem && node != start) {
+			continue
+		}
+		for _, edge := range g.adjacency[node.ID] {
+			neighborID := edge.Target
+			if neighborID == node.ID {
+				neighborID = edge.Source
+			}
+			if _, visited := distances[neighborID]; visited {
+				continue
+			}
+			distances[neighborID] = distances[node.ID] + 1
+			queue = append(queue, g.nodes[neighborID])
+		}
+	}
+
+	subgraph := newGraph()
+	for _, node := range g.Nodes {
+		if _, ok := distances[node.ID]; ok {
+			subgraph.addNode(node.Type, node.EntityID, node.Name)
+		}
+	}
+	for _, edge := range g.Edges {
+		subgraph.addEdge(edge.Source, edge.Target, edge.Type)
+	}
+
+	return subgraph, nil
+}
+
+// getNodeID returns the identity of the node in the graph
+func getNodeID(nodeType string, entityID int) string {
+	return fmt.Sprintf("%s:%d", nodeType, entityID)
+}
+
+// getAddr returns the address of the host and port
+func getAddr(hostIP string, portNum int) string {
+	return net.JoinHostPort(hostIP, strconv.Itoa(portNum))
+}
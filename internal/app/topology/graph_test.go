@@ -0,0 +1,119 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/internal/app/metadata"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+)
+
+// newTestSnapshot returns a snapshot like below:
+//
+//	app1 -> db1 -> mysql cluster 1 <- mysql server 1(primary), mysql server 2(replica)
+//	app2 -> db2 -> middleware cluster 1 <- mysql cluster 1, middleware server 1
+//	app3 -> db3 -> mysql cluster 2 <- mysql server 3(primary)
+//	mysql cluster 1 and mysql cluster 2 -> monitor system 1
+func newTestSnapshot() *Snapshot {
+	return &Snapshot{
+		Apps: []depmeta.App{
+			&metadata.AppInfo{ID: 1, AppName: "app1"},
+			&metadata.AppInfo{ID: 2, AppName: "app2"},
+			&metadata.AppInfo{ID: 3, AppName: "app3"},
+		},
+		DBs: []depmeta.DB{
+			&metadata.DBInfo{ID: 1, DBName: "db1", ClusterID: 1, ClusterType: 1},
+			&metadata.DBInfo{ID: 2, DBName: "db2", ClusterID: 1, ClusterType: 2},
+			&metadata.DBInfo{ID: 3, DBName: "db3", ClusterID: 2, ClusterType: 1},
+		},
+		MySQLClusters: []depmeta.MySQLCluster{
+			&metadata.MySQLClusterInfo{ID: 1, ClusterName: "cluster1", MiddlewareClusterID: 1, MonitorSystemID: 1},
+			&metadata.MySQLClusterInfo{ID: 2, ClusterName: "cluster2", MonitorSystemID: 1},
+		},
+		MySQLServers: []depmeta.MySQLServer{
+			&metadata.MySQLServerInfo{ID: 1, ClusterID: 1, HostIP: "192.168.137.11", PortNum: 3306, ServerRole: metadata.ServerRolePrimary},
+			&metadata.MySQLServerInfo{ID: 2, ClusterID: 1, HostIP: "192.168.137.12", PortNum: 3306, ServerRole: metadata.ServerRoleReplica},
+			&metadata.MySQLServerInfo{ID: 3, ClusterID: 2, HostIP: "192.168.137.13", PortNum: 3306, ServerRole: metadata.ServerRolePrimary},
+		},
+		MiddlewareClusters: []depmeta.MiddlewareCluster{
+			&metadata.MiddlewareClusterInfo{ID: 1, ClusterName: "middleware1"},
+		},
+		MiddlewareServers: []depmeta.MiddlewareServer{
+			&metadata.MiddlewareServerInfo{ID: 1, ClusterID: 1, HostIP: "192.168.137.21", PortNum: 3307},
+		},
+		MonitorSystems: []depmeta.MonitorSystem{
+			&metadata.MonitorSystemInfo{ID: 1, MonitorSystemName: "pmm"},
+		},
+		AppDBMap: map[int][]int{1: {1}, 2: {2}, 3: {3}},
+	}
+}
+
+func TestGraphAll(t *testing.T) {
+	TestNewGraph(t)
+	TestGraph_Subgraph(t)
+	TestNewImpact(t)
+}
+
+func TestNewGraph(t *testing.T) {
+	asst := assert.New(t)
+
+	graph := NewGraph(newTestSnapshot())
+	asst.Equal(14, len(graph.Nodes), "test NewGraph() failed")
+	// 3 uses, 3 hosted_by, 1 proxied_by, 2 monitored_by, 4 member_of
+	asst.Equal(13, len(graph.Edges), "test NewGraph() failed")
+	asst.Equal("192.168.137.11:3306", graph.GetNode(NodeTypeMySQLServer, 1).Name, "test NewGraph() failed")
+	asst.Nil(graph.GetNode(NodeTypeMySQLServer, 4), "test NewGraph() failed")
+}
+
+func TestGraph_Subgraph(t *testing.T) {
+	asst := assert.New(t)
+
+	graph := NewGraph(newTestSnapshot())
+	subgraph, err := graph.Subgraph(NodeTypeApp, 1, 2)
+	asst.Nil(err, common.CombineMessageWithError("test Subgraph() failed", err))
+	// app1, db1, mysql cluster 1
+	asst.Equal(3, len(subgraph.Nodes), "test Subgraph() failed")
+	asst.Equal(2, len(subgraph.Edges), "test Subgraph() failed")
+
+	subgraph, err = graph.Subgraph(NodeTypeMySQLServer, 3, 3)
+	asst.Nil(err, common.CombineMessageWithError("test Subgraph() failed", err))
+	// mysql server 3, mysql cluster 2, db3, monitor system 1 and app3, mysql cluster 1 is not reached through the monitor system
+	asst.Equal(5, len(subgraph.Nodes), "test Subgraph() failed")
+	asst.Nil(subgraph.GetNode(NodeTypeMySQLCluster, 1), "test Subgraph() failed")
+
+	subgraph, err = graph.Subgraph(NodeTypeMonitorSystem, 1, 1)
+	asst.Nil(err, common.CombineMessageWithError("test Subgraph() failed", err))
+	asst.Equal(3, len(subgraph.Nodes), "test Subgraph() failed")
+
+	_, err = graph.Subgraph(NodeTypeDB, 4, 1)
+	asst.ErrorIs(err, ErrDataNotExists, "test Subgraph() failed")
+}
+
+func TestNewImpact(t *testing.T) {
+	asst := assert.New(t)
+
+	impact, err := NewImpact(newTestSnapshot(), 1)
+	asst.Nil(err, common.CombineMessageWithError("test NewImpact() failed", err))
+	asst.Equal(SeverityWriteUnavailable, impact.Severity, "test NewImpact() failed")
+	asst.Equal(1, len(impact.RemainingMySQLServers), "test NewImpact() failed")
+	asst.Equal("middleware1", impact.MiddlewareCluster.Name, "test NewImpact() failed")
+	// db1 is hosted by the mysql cluster, db2 is sharded by the middleware cluster in front of it
+	asst.Equal(2, len(impact.DBs), "test NewImpact() failed")
+	asst.Equal(2, len(impact.Apps), "test NewImpact() failed")
+
+	impact, err = NewImpact(newTestSnapshot(), 2)
+	asst.Nil(err, common.CombineMessageWithError("test NewImpact() failed", err))
+	asst.Equal(SeverityReadDegraded, impact.Severity, "test NewImpact() failed")
+
+	impact, err = NewImpact(newTestSnapshot(), 3)
+	asst.Nil(err, common.CombineMessageWithError("test NewImpact() failed", err))
+	asst.Equal(SeverityUnavailable, impact.Severity, "test NewImpact() failed")
+	asst.Nil(impact.MiddlewareCluster, "test NewImpact() failed")
+	asst.Equal(1, len(impact.Apps), "test NewImpact() failed")
+	asst.Equal("app3", impact.Apps[0].Name, "test NewImpact() failed")
+
+	_, err = NewImpact(newTestSnapshot(), 4)
+	asst.ErrorIs(err, ErrDataNotExists, "test NewImpact() failed")
+}
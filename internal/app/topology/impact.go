@@ -0,0 +1,110 @@
+package topology
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/romberli/go-util/constant"
+
+	"github.com/romberli/das/internal/app/metadata"
+)
+
+const (
+	// SeverityUnavailable means the mysql server is the only one of the cluster, the databases are unavailable
+	SeverityUnavailable = "unavailable"
+	// SeverityWriteUnavailable means the mysql server is the primary, the databases could not be written until failover
+	SeverityWriteUnavailable = "write_unavailable"
+	// SeverityReadDegraded means the mysql server is a replica, the reads are served by the remaining mysql servers
+	SeverityReadDegraded = "read_degraded"
+)
+
+// Impact is the entities which are affected if a mysql server goes down
+type Impact struct {
+	MySQLServer           *Node   `json:"mysql_server"`
+	MySQLCluster          *Node   `json:"mysql_cluster"`
+	MiddlewareCluster     *Node   `json:"middleware_cluster"`
+	Severity              string  `json:"severity"`
+	RemainingMySQLServers []*Node `json:"remaining_mysql_servers"`
+	DBs                   []*Node `json:"dbs"`
+	Apps                  []*Node `json:"apps"`
+}
+
+// NewImpact returns the impact of the mysql server going down,
+// the affected databases are the ones hosted by its mysql cluster directly,
+// and the sharded ones hosted by the middleware cluster in front of its mysql cluster
+func NewImpact(snapshot *Snapshot, mysqlServerID int) (*Impact, error) {
+	graph := NewGraph(snapshot)
+
+	serverNode := graph.GetNode(NodeTypeMySQLServer, mysqlServerID)
+	if serverNode == nil {
+		return nil, fmt.Errorf("mysql server %d does not exist: %w", mysqlServerID, ErrDataNotExists)
+	}
+	impact := &Impact{
+		MySQLServer:           serverNode,
+		RemainingMySQLServers: []*Node{},
+		DBs:                   []*Node{},
+		Apps:                  []*Node{},
+	}
+
+	var clusterID, serverRole int
+	for _, mysqlServer := range snapshot.MySQLServers {
+		if mysqlServer.Identity() == mysqlServerID {
+			clusterID = mysqlServer.GetClusterID()
+			serverRole = mysqlServer.GetServerRole()
+		}
+	}
+	for _, mysqlServer := range snapshot.MySQLServers {
+		if mysqlServer.GetClusterID() == clusterID && mysqlServer.Identity() != mysqlServerID {
+			impact.RemainingMySQLServers = append(impact.RemainingMySQLServers, graph.GetNode(NodeTypeMySQLServer, mysqlServer.Identity()))
+		}
+	}
+	switch {
+	case len(impact.RemainingMySQLServers) == constant.ZeroInt:
+		impact.Severity = SeverityUnavailable
+	case serverRole == metadata.ServerRolePrimary:
+		impact.Severity = SeverityWriteUnavailable
+	default:
+		impact.Severity = SeverityReadDegraded
+	}
+
+	impact.MySQLCluster = graph.GetNode(NodeTypeMySQLCluster, clusterID)
+	middlewareClusterID := constant.ZeroInt
+	for _, mysqlCluster := range snapshot.MySQLClusters {
+		if mysqlCluster.Identity() == clusterID {
+			middlewareClusterID = mysqlCluster.GetMiddlewareClusterID()
+		}
+	}
+	impact.MiddlewareCluster = graph.GetNode(NodeTypeMiddlewareCluster, middlewareClusterID)
+
+	dbIDs := make(map[int]bool)
+	for _, db := range snapshot.DBs {
+		hostedByCluster := db.GetClusterType() == clusterTypeSingle && db.GetClusterID() == clusterID
+		hostedByMiddleware := db.GetClusterType() != clusterTypeSingle && impact.MiddlewareCluster != nil &&
+			db.GetClusterID() == middlewareClusterID
+		if hostedByCluster || hostedByMiddleware {
+			dbIDs[db.Identity()] = true
+			impact.DBs = append(impact.DBs, graph.GetNode(NodeTypeDB, db.Identity()))
+		}
+	}
+
+	for _, app := range snapshot.Apps {
+		for _, dbID := range snapshot.AppDBMap[app.Identity()] {
+			if dbIDs[dbID] {
+				impact.Apps = append(impact.Apps, graph.GetNode(NodeTypeApp, app.Identity()))
+				break
+			}
+		}
+	}
+	sortNodes(impact.RemainingMySQLServers)
+	sortNodes(impact.DBs)
+	sortNodes(impact.Apps)
+
+	return impact, nil
+}
+
+// sortNodes sorts the nodes by the entity id
+func sortNodes(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].EntityID < nodes[j].EntityID
+	})
+}
@@ -0,0 +1,123 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/romberli/go-util/common"
+
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/topology"
+)
+
+const (
+	GraphsStruct  = "Graphs"
+	ImpactsStruct = "Impacts"
+)
+
+var (
+	// ErrDataNotExists is returned when the entity to start from does not exist
+	ErrDataNotExists = errors.New("data does not exists")
+	// ErrNotValidParameter is returned when the node type or the depth is not valid
+	ErrNotValidParameter = errors.New("parameter is not valid")
+)
+
+var _ topology.Service = (*Service)(nil)
+
+type Service struct {
+	topology.Repository
+	Graphs  []*Graph  `json:"graphs"`
+	Impacts []*Impact `json:"impacts"`
+}
+
+// NewService returns a new *Service
+func NewService(repo topology.Repository) *Service {
+	return &Service{
+		Repository: repo,
+		Graphs:     []*Graph{},
+		Impacts:    []*Impact{},
+	}
+}
+
+// NewServiceWithDefault returns a new *Service with default repository
+func NewServiceWithDefault() *Service {
+	return NewService(NewRepositoryWithGlobal())
+}
+
+// GetGraphs returns the graphs of the service
+func (s *Service) GetGraphs() []*Graph {
+	return s.Graphs
+}
+
+// GetImpacts returns the impacts of the service
+func (s *Service) GetImpacts() []*Impact {
+	return s.Impacts
+}
+
+// GetGraph gets the graph around the metadata entity of the given node type and id,
+// depth is the max number of the edges between the entity and the other nodes
+func (s *Service) GetGraph(ctx context.Context, nodeType string, id, depth int) error {
+	if !IsValidNodeType(nodeType) {
+		return fmt.Errorf("%w: node type %s is not valid", ErrNotValidParameter, nodeType)
+	}
+	if depth < 1 || depth > MaxDepth {
+		return fmt.Errorf("%w: depth must be between 1 and %d, %d is not valid", ErrNotValidParameter, MaxDepth, depth)
+	}
+
+	snapshot, err := s.getSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	graph, err := NewGraph(snapshot).Subgraph(nodeType, id, depth)
+	if err != nil {
+		return err
+	}
+	s.Graphs = []*Graph{graph}
+
+	return nil
+}
+
+// GetImpact gets the entities which are affected if the mysql server goes down
+func (s *Service) GetImpact(ctx context.Context, mysqlServerID int) error {
+	snapshot, err := s.getSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	impact, err := NewImpact(snapshot, mysqlServerID)
+	if err != nil {
+		return err
+	}
+	s.Impacts = []*Impact{impact}
+
+	return nil
+}
+
+// getSnapshot loads all the metadata entities and relationships from the middleware
+func (s *Service) getSnapshot(ctx context.Context) (*Snapshot, error) {
+	snapshot, err := metadata.GetSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		Apps:               snapshot.Apps,
+		DBs:                snapshot.DBs,
+		MySQLClusters:      snapshot.MySQLClusters,
+		MySQLServers:       snapshot.MySQLServers,
+		MiddlewareClusters: snapshot.MiddlewareClusters,
+		MiddlewareServers:  snapshot.MiddlewareServers,
+		MonitorSystems:     snapshot.MonitorSystems,
+		AppDBMap:           snapshot.AppDBMap,
+	}, nil
+}
+
+// Marshal marshals Service to json bytes
+func (s *Service) Marshal() ([]byte, error) {
+	return s.MarshalWithFields(GraphsStruct, ImpactsStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the Service to json bytes
+func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(s, fields...)
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/pkg/filter"
+)
+
+type Record interface {
+	// Identity returns the identity
+	Identity() int
+	// GetEntityType returns the type of the changed entity
+	GetEntityType() string
+	// GetEntityID returns the identity of the changed entity
+	GetEntityID() int
+	// GetAction returns the action of the change
+	GetAction() string
+	// GetActor returns the actor who made the change
+	GetActor() string
+	// GetRequestID returns the identity of the request which made the change
+	GetRequestID() string
+	// GetBefore returns the json of the entity before the change
+	GetBefore() string
+	// GetAfter returns the json of the entity after the change
+	GetAfter() string
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// MarshalJSON marshals Record to json string
+	MarshalJSON() ([]byte, error)
+}
+
+type Repository interface {
+	// Execute executes given command and placeholders on the middleware
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
+	// Create creates an audit record in the middleware
+	Create(ctx context.Context, record Record) error
+	// GetByQuery gets the audit records which match the query from the middleware,
+	// it also returns the total number of the matched records without pagination
+	GetByQuery(ctx context.Context, query *filter.Query) ([]Record, int, error)
+}
+
+type Auditor interface {
+	// Record records the change of the entity, before and after are the entity before and after the change,
+	// nil means the entity does not exist at that time
+	Record(ctx context.Context, entityType string, entityID int, action string, before, after interface{})
+}
+
+type Service interface {
+	// GetRecords returns the audit records of the service
+	GetRecords() []Record
+	// GetByQuery gets the audit records which match the query
+	GetByQuery(ctx context.Context, query *filter.Query) error
+	// GetByEntity gets the audit records of the entity which match the query
+	GetByEntity(ctx context.Context, entityType string, entityID int, query *filter.Query) error
+	// Marshal marshals Service to json bytes
+	Marshal() ([]byte, error)
+	// MarshalWithFields marshals only specified fields of the Service to json bytes
+	MarshalWithFields(fields ...string) ([]byte, error)
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+type Token interface {
+	// Identity returns the identity
+	Identity() int
+	// GetUserID returns the identity of the user who owns the token
+	GetUserID() int
+	// GetTokenType returns the token type
+	GetTokenType() int
+	// GetTokenKey returns the key which identifies the token
+	GetTokenKey() string
+	// GetDescription returns the description
+	GetDescription() string
+	// GetExpireTime returns the expire time
+	GetExpireTime() time.Time
+	// GetDelFlag returns the delete flag, 1 means the token was revoked
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+	// MarshalJSON marshals Token to json string
+	MarshalJSON() ([]byte, error)
+}
+
+type Repository interface {
+	// Execute executes given command and placeholders on the middleware
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
+	// Create creates a token in the middleware
+	Create(ctx context.Context, token Token) (Token, error)
+	// GetByKey gets the token of given key from the middleware
+	GetByKey(ctx context.Context, tokenKey string) (Token, error)
+	// GetByUserID gets the tokens of the user from the middleware, the revoked and expired tokens are also returned
+	GetByUserID(ctx context.Context, userID int) ([]Token, error)
+	// GetAccountName returns the account name of the user who owns the token of given type and key,
+	// it returns error if the token does not exist, was revoked or expired, or the user was deleted
+	GetAccountName(ctx context.Context, tokenType int, tokenKey string) (string, error)
+	// Revoke revokes the token of the user in the middleware
+	Revoke(ctx context.Context, userID, id int) error
+	// IsOwner returns if the user owns the resource of given type and identity
+	IsOwner(ctx context.Context, resource string, userID int, resourceID string) (bool, error)
+}
+
+type Authenticator interface {
+	// Supports returns if the authenticator could authenticate the token
+	Supports(token string) bool
+	// Authenticate authenticates the token and returns the account name of the user who owns the token
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+type Authorizer interface {
+	// Authorize returns an error if the user is not allowed to access the route,
+	// path is the full path of the route as it is registered, params are the path parameters of the request
+	Authorize(ctx context.Context, accountName, method, path string, params map[string]string) error
+}
+
+type Service interface {
+	// GetTokens returns the tokens of the service
+	GetTokens() []Token
+	// GetToken returns the issued token, it could be only got right after it was issued
+	GetToken() string
+	// Login binds to the ldap server as the user and issues a json web token to the user
+	Login(ctx context.Context, accountName, password string) error
+	// CreateToken issues an api token to the user, the token expires after given seconds
+	CreateToken(ctx context.Context, accountName, description string, expiration int) error
+	// GetByAccountName gets the tokens of the user
+	GetByAccountName(ctx context.Context, accountName string) error
+	// RevokeToken revokes the token of the user
+	RevokeToken(ctx context.Context, accountName string, id int) error
+	// Marshal marshals Service to json bytes
+	Marshal() ([]byte, error)
+	// MarshalWithFields marshals only specified fields of the Service to json bytes
+	MarshalWithFields(fields ...string) ([]byte, error)
+}
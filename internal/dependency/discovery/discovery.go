@@ -0,0 +1,20 @@
+package discovery
+
+import (
+	"context"
+)
+
+type Service interface {
+	// DiscoverBySeed discovers the replication topology and databases from the seed mysql server,
+	// and proposes the changes of the mysql cluster, mysql servers and databases metadata,
+	// the changes will be applied only if dryRun is false
+	DiscoverBySeed(ctx context.Context, hostIP string, portNum int, clusterName string, envID, monitorSystemID, deploymentType int,
+		serviceNames map[string]string, dryRun bool) error
+	// DiscoverByMonitorSystem discovers all the registered mysql clusters which are monitored by the given monitor system,
+	// and proposes the changes of the metadata, the changes will be applied only if dryRun is false
+	DiscoverByMonitorSystem(ctx context.Context, monitorSystemID int, dryRun bool) error
+	// Marshal marshals Service to json bytes
+	Marshal() ([]byte, error)
+	// MarshalWithFields marshals only specified fields of the Service to json bytes
+	MarshalWithFields(fields ...string) ([]byte, error)
+}
@@ -1,6 +1,7 @@
 package healthcheck
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/middleware"
@@ -85,34 +86,34 @@ type Result interface {
 
 type Repository interface {
 	// Execute executes given command and placeholders on the middleware
-	Execute(command string, args ...interface{}) (middleware.Result, error)
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
 	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
 	Transaction() (middleware.Transaction, error)
 	// GetResultByOperationID returns the result
-	GetResultByOperationID(operationID int) (Result, error)
+	GetResultByOperationID(ctx context.Context, operationID int) (Result, error)
 	// IsRunning returns if the healthcheck of given mysql server is still running
-	IsRunning(mysqlServerID int) (bool, error)
+	IsRunning(ctx context.Context, mysqlServerID int) (bool, error)
 	// InitOperation initiates the operation
-	InitOperation(mysqlServerID int, startTime, endTime time.Time, step time.Duration) (int, error)
+	InitOperation(ctx context.Context, mysqlServerID int, startTime, endTime time.Time, step time.Duration) (int, error)
 	// UpdateOperationStatus updates operation status
-	UpdateOperationStatus(operationID int, status int, message string) error
+	UpdateOperationStatus(ctx context.Context, operationID int, status int, message string) error
 	// SaveResult saves result into the middleware
-	SaveResult(result Result) error
+	SaveResult(ctx context.Context, result Result) error
 	// UpdateAccurateReviewByOperationID updates the accurate review
-	UpdateAccurateReviewByOperationID(operationID int, review int) error
+	UpdateAccurateReviewByOperationID(ctx context.Context, operationID int, review int) error
 }
 
 type Service interface {
 	// GetResult returns the result
 	GetResult() Result
 	// GetResultByOperationID gets the result by operation id from the middleware
-	GetResultByOperationID(id int) error
+	GetResultByOperationID(ctx context.Context, id int) error
 	// Check checks the server health status
-	Check(mysqlServerID int, startTime, endTime time.Time, step time.Duration) error
+	Check(ctx context.Context, mysqlServerID int, startTime, endTime time.Time, step time.Duration) error
 	// Check checks the server health status
-	CheckByHostInfo(hostIP string, portNum int, startTime, endTime time.Time, step time.Duration) error
+	CheckByHostInfo(ctx context.Context, hostIP string, portNum int, startTime, endTime time.Time, step time.Duration) error
 	// ReviewAccurate reviews the accurate of the check
-	ReviewAccurate(id, review int) error
+	ReviewAccurate(ctx context.Context, id, review int) error
 	// MarshalJSON marshals Service to json string
 	MarshalJSON() ([]byte, error)
 	// MarshalJSON marshals only specified field of the Service to json string
@@ -121,5 +122,5 @@ type Service interface {
 
 type Engine interface {
 	// Run checks the server health status
-	Run()
+	Run(ctx context.Context)
 }
@@ -0,0 +1,37 @@
+package inventory
+
+import (
+	"context"
+	"time"
+
+	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/internal/dependency/audit"
+)
+
+type Repository interface {
+	// Execute executes given command and placeholders on the middleware
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
+}
+
+type Service interface {
+	// SetAuditor sets the auditor which records the applied changes
+	SetAuditor(auditor audit.Auditor)
+	// Import decodes the inventory document of the format, and plans the changes of the metadata,
+	// the changes are applied in a transaction only if dryRun is false,
+	// kind must be specified if the format is csv, because the csv data contains only one kind of the entities
+	Import(ctx context.Context, data []byte, format, kind string, dryRun bool) error
+	// Reconcile decodes the inventory document of the format as the desired state of the metadata, and plans the changes,
+	// the registered entities of the kinds in the document which are not in the document are deleted if prune is true,
+	// the entities which were updated after since are conflicts, since is not checked if it is zero,
+	// the changes are applied in a transaction only if dryRun is false
+	Reconcile(ctx context.Context, data []byte, format, kind string, prune bool, since time.Time, dryRun bool) error
+	// Export exports the registered metadata as the inventory document, all kinds are exported if kind is empty
+	Export(ctx context.Context, kind string) error
+	// Encode encodes the exported inventory document to the data of the format
+	Encode(format, kind string) ([]byte, error)
+	// Marshal marshals Service to json bytes
+	Marshal() ([]byte, error)
+	// MarshalWithFields marshals only specified fields of the Service to json bytes
+	MarshalWithFields(fields ...string) ([]byte, error)
+}
@@ -1,9 +1,13 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
 )
 
 type App interface {
@@ -22,15 +26,15 @@ type App interface {
 	// GetLastUpdateTime returns the last update time
 	GetLastUpdateTime() time.Time
 	// GetDBIDList gets database identity list that the app uses
-	GetDBIDList() ([]int, error)
+	GetDBIDList(ctx context.Context) ([]int, error)
 	// Set sets App with given fields, key is the field name and value is the relevant value of the key
 	Set(fields map[string]interface{}) error
 	// Delete sets DelFlag to 1
 	Delete()
 	// AddDB adds a new map of the app and database in the middleware
-	AddDB(dbID int) error
+	AddDB(ctx context.Context, dbID int) error
 	// DeleteDB deletes the map of the app and database in the middleware
-	DeleteDB(dbID int) error
+	DeleteDB(ctx context.Context, dbID int) error
 	// MarshalJSON marshals App to json bytes
 	MarshalJSON() ([]byte, error)
 	// MarshalJSONWithFields marshals only specified fields of the App to json string
@@ -39,55 +43,76 @@ type App interface {
 
 type AppRepo interface {
 	// Execute executes command with arguments on the middleware
-	Execute(command string, args ...interface{}) (middleware.Result, error)
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
 	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
 	Transaction() (middleware.Transaction, error)
 	// GetAll gets all apps from the middleware
-	GetAll() ([]App, error)
+	GetAll(ctx context.Context) ([]App, error)
+	// GetByQuery gets the apps which match the query from the middleware, it also returns the total number of the matched apps
+	GetByQuery(ctx context.Context, query *filter.Query) ([]App, int, error)
 	// GetByID gets an app by the identity from the middleware
-	GetByID(id int) (App, error)
+	GetByID(ctx context.Context, id int) (App, error)
 	// GetID gets the identity with given app name from the middleware
-	GetID(appName string) (int, error)
+	GetID(ctx context.Context, appName string) (int, error)
 	// GetAppSystemByName gets the app by name from the middleware
-	GetAppByName(appName string) (App, error)
+	GetAppByName(ctx context.Context, appName string) (App, error)
 	// GetDBIDList gets a database identity list that app uses
-	GetDBIDList(id int) ([]int, error)
+	GetDBIDList(ctx context.Context, id int) ([]int, error)
+	// GetAppDBMap returns the database identities of each app, the key is the app id
+	GetAppDBMap(ctx context.Context) (map[int][]int, error)
 	// Create creates an app in the middleware
-	Create(appSystem App) (App, error)
+	Create(ctx context.Context, appSystem App) (App, error)
 	// Update updates the app in the middleware
-	Update(appSystem App) error
+	// only if the row was not modified after the entity had been read, otherwise, it returns a conflict error
+	Update(ctx context.Context, appSystem App) error
 	// Delete deletes the app in the middleware
-	Delete(id int) error
+	// only if the row was not modified after it had been read with given last update time, otherwise, it returns a conflict error
+	Delete(ctx context.Context, id int, lastUpdateTime time.Time) error
+	// DeleteCascade deletes the app and the metadata which reference it in the middleware,
+	// it returns the rows which are deleted or detached along with the app
+	DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]CascadedRow, error)
+	// Undelete restores the deleted app in the middleware
+	Undelete(ctx context.Context, id int) error
 	// AddDB adds a new map of app and database in the middleware
-	AddDB(appID, dbID int) error
+	AddDB(ctx context.Context, appID, dbID int) error
 	// DeleteDB delete the map of app and database in the middleware
-	DeleteDB(appID, dbID int) error
+	DeleteDB(ctx context.Context, appID, dbID int) error
 }
 
 type AppService interface {
 	// GetApps returns apps of the service
 	GetApps() []App
 	// GetAll gets all apps from the middleware
-	GetAll() error
+	GetAll(ctx context.Context) error
+	// GetByQuery gets the apps which match the query from the middleware, and the pagination information
+	GetByQuery(ctx context.Context, query *filter.Query) error
 	// GetByID gets an app of the given id from the middleware
-	GetByID(id int) error
+	GetByID(ctx context.Context, id int) error
 	// GetAppByName gets App from the middleware by name
-	GetAppByName(appName string) error
+	GetAppByName(ctx context.Context, appName string) error
 	// GetDBIDList gets a database identity list that the app uses
-	GetDBIDList(id int) error
+	GetDBIDList(ctx context.Context, id int) error
 	// Create creates an app in the middleware
-	Create(fields map[string]interface{}) error
+	Create(ctx context.Context, fields map[string]interface{}) error
 	// Update gets the app of the given id from the middleware,
 	// and then updates its fields that was specified in fields argument,
 	// key is the filed name and value is the new field value,
 	// it saves the changes to the middleware
-	Update(id int, fields map[string]interface{}) error
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
 	// Delete deletes the app of given id in the middleware
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
+	// DeleteCascade deletes the app of given id and the metadata which reference it in the middleware
+	DeleteCascade(ctx context.Context, id int) error
+	// Undelete restores the deleted app of given id in the middleware
+	Undelete(ctx context.Context, id int) error
+	// SetAuditor sets the auditor which records the changes made by the service
+	SetAuditor(auditor audit.Auditor)
+	// SetIfMatch sets the entity tags which the entity to update or delete must match, it is the value of the If-Match header
+	SetIfMatch(ifMatch string)
 	// AddDB adds a new map of app and database in the middleware
-	AddDB(appID, dbID int) error
+	AddDB(ctx context.Context, appID, dbID int) error
 	// DeleteDB deletes the map of app and database in the middleware
-	DeleteDB(appID, dbID int) error
+	DeleteDB(ctx context.Context, appID, dbID int) error
 	// Marshal marshals AppService.Apps to json bytes
 	Marshal() ([]byte, error)
 	// MarshalWithFields marshals only specified fields of the AppService to json bytes
@@ -1,9 +1,13 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
 )
 
 type DB interface {
@@ -26,15 +30,15 @@ type DB interface {
 	// GetLastUpdateTime returns the last update time
 	GetLastUpdateTime() time.Time
 	// GetAppIDList gets app identity list that uses this db
-	GetAppIDList() ([]int, error)
+	GetAppIDList(ctx context.Context) ([]int, error)
 	// Set sets DB with given fields, key is the field name and value is the relevant value of the key
 	Set(fields map[string]interface{}) error
 	// Delete sets DelFlag to 1
 	Delete()
 	// AddDB adds a new map of the app and database in the middleware
-	AddApp(appID int) error
+	AddApp(ctx context.Context, appID int) error
 	// DeleteApp deletes a new map of the app and database in the middleware
-	DeleteApp(appID int) error
+	DeleteApp(ctx context.Context, appID int) error
 	// MarshalJSON marshals DB to json string
 	MarshalJSON() ([]byte, error)
 	// MarshalJSONWithFields marshals only specified field of the DB to json string
@@ -43,59 +47,78 @@ type DB interface {
 
 type DBRepo interface {
 	// Execute executes given command and placeholders on the middleware
-	Execute(command string, args ...interface{}) (middleware.Result, error)
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
 	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
 	Transaction() (middleware.Transaction, error)
 	// GetAll gets all databases from the middleware
-	GetAll() ([]DB, error)
+	GetAll(ctx context.Context) ([]DB, error)
+	// GetByQuery gets the databases which match the query from the middleware, it also returns the total number of the matched databases
+	GetByQuery(ctx context.Context, query *filter.Query) ([]DB, int, error)
 	// GetByEnv gets databases of given env id from the middleware
-	GetByEnv(envID int) ([]DB, error)
+	GetByEnv(ctx context.Context, envID int) ([]DB, error)
 	// GetByID gets a database by the identity from the middleware
-	GetByID(id int) (DB, error)
+	GetByID(ctx context.Context, id int) (DB, error)
 	// GetByNameAndClusterInfo gets a database by the db name and cluster info from the middleware
-	GetByNameAndClusterInfo(name string, clusterID, clusterType int) (DB, error)
+	GetByNameAndClusterInfo(ctx context.Context, name string, clusterID, clusterType int) (DB, error)
 	// GetID gets the identity with given database name, cluster id and cluster type from the middleware
-	GetID(dbName string, clusterID int, clusterType int) (int, error)
+	GetID(ctx context.Context, dbName string, clusterID int, clusterType int) (int, error)
 	// GetAppIDList gets an app identity list that uses this db
-	GetAppIDList(id int) ([]int, error)
+	GetAppIDList(ctx context.Context, id int) ([]int, error)
 	// Create creates a database in the middleware
-	Create(db DB) (DB, error)
+	Create(ctx context.Context, db DB) (DB, error)
 	// Update updates the database in the middleware
-	Update(db DB) error
+	// only if the row was not modified after the entity had been read, otherwise, it returns a conflict error
+	Update(ctx context.Context, db DB) error
 	// Delete deletes the database in the middleware
-	Delete(id int) error
+	// only if the row was not modified after it had been read with given last update time, otherwise, it returns a conflict error
+	Delete(ctx context.Context, id int, lastUpdateTime time.Time) error
+	// DeleteCascade deletes the database and the metadata which reference it in the middleware,
+	// it returns the rows which are deleted or detached along with the database
+	DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]CascadedRow, error)
+	// Undelete restores the deleted database in the middleware
+	Undelete(ctx context.Context, id int) error
 	// AddApp adds a new map of the app and database in the middleware
-	AddApp(dbID, appID int) error
+	AddApp(ctx context.Context, dbID, appID int) error
 	// DeleteApp deletes a map of the app and database in the middleware
-	DeleteApp(dbID, appID int) error
+	DeleteApp(ctx context.Context, dbID, appID int) error
 }
 
 type DBService interface {
 	// GetDBs returns databases of the service
 	GetDBs() []DB
 	// GetAll gets all databases from the middleware
-	GetAll() error
+	GetAll(ctx context.Context) error
+	// GetByQuery gets the databases which match the query from the middleware, and the pagination information
+	GetByQuery(ctx context.Context, query *filter.Query) error
 	// GetByEnv gets databases of given env id
-	GetByEnv(envID int) error
+	GetByEnv(ctx context.Context, envID int) error
 	// GetByID gets a database of the given id from the middleware
-	GetByID(id int) error
+	GetByID(ctx context.Context, id int) error
 	// GetByNameAndClusterInfo gets an database of the given db name and cluster info from the middleware
-	GetByNameAndClusterInfo(name string, clusterID, clusterType int) error
+	GetByNameAndClusterInfo(ctx context.Context, name string, clusterID, clusterType int) error
 	// GetAppIDList gets an app identity list that uses this db
-	GetAppIDList(id int) error
+	GetAppIDList(ctx context.Context, id int) error
 	// Create creates a database in the middleware
-	Create(fields map[string]interface{}) error
+	Create(ctx context.Context, fields map[string]interface{}) error
 	// Update gets a database of the given id from the middleware,
 	// and then updates its fields that was specified in fields argument,
 	// key is the filed name and value is the new field value,
 	// it saves the changes to the middleware
-	Update(id int, fields map[string]interface{}) error
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
 	// Delete deletes the database of given id in the middleware
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
+	// DeleteCascade deletes the database of given id and the metadata which reference it in the middleware
+	DeleteCascade(ctx context.Context, id int) error
+	// Undelete restores the deleted database of given id in the middleware
+	Undelete(ctx context.Context, id int) error
+	// SetAuditor sets the auditor which records the changes made by the service
+	SetAuditor(auditor audit.Auditor)
+	// SetIfMatch sets the entity tags which the entity to update or delete must match, it is the value of the If-Match header
+	SetIfMatch(ifMatch string)
 	// AddApp adds a new map of app and database in the middleware
-	AddApp(dbID, appID int) error
+	AddApp(ctx context.Context, dbID, appID int) error
 	// DeleteApp deletes the map of app and database in the middleware
-	DeleteApp(dbID, appID int) error
+	DeleteApp(ctx context.Context, dbID, appID int) error
 	// Marshal marshals DBService.DBs to json bytes
 	Marshal() ([]byte, error)
 	// MarshalWithFields marshals only specified fields of the DBService to json bytes
@@ -1,9 +1,13 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
 )
 
 type Env interface {
@@ -29,43 +33,62 @@ type Env interface {
 
 type EnvRepo interface {
 	// Execute executes given command and placeholders on the middleware
-	Execute(command string, args ...interface{}) (middleware.Result, error)
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
 	// Transaction returns middleware.PoolConn, so it can run multiple statements in the same transaction
 	Transaction() (middleware.Transaction, error)
 	// GetAll gets all environments from the middleware
-	GetAll() ([]Env, error)
+	GetAll(ctx context.Context) ([]Env, error)
+	// GetByQuery gets the environments which match the query from the middleware, it also returns the total number of the matched environments
+	GetByQuery(ctx context.Context, query *filter.Query) ([]Env, int, error)
 	// GetByID gets an environment by the identity from the middleware
-	GetByID(id int) (Env, error)
+	GetByID(ctx context.Context, id int) (Env, error)
 	// GetID gets the identity with given environment name from the middleware
-	GetID(envName string) (int, error)
+	GetID(ctx context.Context, envName string) (int, error)
 	// GetEnvByName gets Env of given environment name
-	GetEnvByName(envName string) (Env, error)
+	GetEnvByName(ctx context.Context, envName string) (Env, error)
 	// Create creates an environment in the middleware
-	Create(env Env) (Env, error)
+	Create(ctx context.Context, env Env) (Env, error)
 	// Update updates the environment in the middleware
-	Update(env Env) error
+	// only if the row was not modified after the entity had been read, otherwise, it returns a conflict error
+	Update(ctx context.Context, env Env) error
 	// Delete deletes the environment in the middleware
-	Delete(id int) error
+	// only if the row was not modified after it had been read with given last update time, otherwise, it returns a conflict error
+	Delete(ctx context.Context, id int, lastUpdateTime time.Time) error
+	// DeleteCascade deletes the environment and the metadata which reference it in the middleware,
+	// it returns the rows which are deleted or detached along with the environment
+	DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]CascadedRow, error)
+	// Undelete restores the deleted environment in the middleware
+	Undelete(ctx context.Context, id int) error
 }
 
 type EnvService interface {
 	// GetEnvs returns environments of the service
 	GetEnvs() []Env
 	// GetAll gets all environments from the middleware
-	GetAll() error
+	GetAll(ctx context.Context) error
+	// GetByQuery gets the environments which match the query from the middleware, and the pagination information
+	GetByQuery(ctx context.Context, query *filter.Query) error
 	// GetByID gets an environment of the given id from the middleware
-	GetByID(id int) error
+	GetByID(ctx context.Context, id int) error
 	// GetEnvByName returns Env of given env name
-	GetEnvByName(envName string) error
+	GetEnvByName(ctx context.Context, envName string) error
 	// Create creates an environment in the middleware
-	Create(fields map[string]interface{}) error
+	Create(ctx context.Context, fields map[string]interface{}) error
 	// Update gets the environment of the given id from the middleware,
 	// and then updates its fields that was specified in fields argument,
 	// key is the filed name and value is the new field value,
 	// it saves the changes to the middleware
-	Update(id int, fields map[string]interface{}) error
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
 	// Delete deletes the environment of given id in the middleware
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
+	// DeleteCascade deletes the environment of given id and the metadata which reference it in the middleware
+	DeleteCascade(ctx context.Context, id int) error
+	// Undelete restores the deleted environment of given id in the middleware
+	Undelete(ctx context.Context, id int) error
+	// SetAuditor sets the auditor which records the changes made by the service
+	SetAuditor(auditor audit.Auditor)
+	// SetIfMatch sets the entity tags which the entity to update or delete must match, it is the value of the If-Match header
+	SetIfMatch(ifMatch string)
 	// Marshal marshals EnvService.Envs to json bytes
 	Marshal() ([]byte, error)
 	// MarshalWithFields marshals only specified fields of the EnvService to json bytes
@@ -0,0 +1,18 @@
+package metadata
+
+type CascadedRow interface {
+	// GetTable returns the table of the row
+	GetTable() string
+	// Identity returns the identity of the row
+	Identity() int
+	// GetName returns the name which describes the row
+	GetName() string
+	// GetColumn returns the column of the row which references the referenced row
+	GetColumn() string
+	// GetReferencedTable returns the table of the row which the row references
+	GetReferencedTable() string
+	// GetReferencedID returns the identity of the row which the row references
+	GetReferencedID() int
+	// IsDetached returns if the row is detached from the referenced row instead of being deleted
+	IsDetached() bool
+}
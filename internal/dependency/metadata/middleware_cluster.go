@@ -1,9 +1,13 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
 )
 
 type MiddlewareCluster interface {
@@ -22,7 +26,7 @@ type MiddlewareCluster interface {
 	// GetLastUpdateTime returns the last update time
 	GetLastUpdateTime() time.Time
 	// GetMiddlewareServerIDList gets the middleware server id list of this cluster
-	GetMiddlewareServerIDList() ([]int, error)
+	GetMiddlewareServerIDList(ctx context.Context) ([]int, error)
 	// Set sets MiddlewareCluster with given fields, key is the field name and value is the relevant value of the key
 	Set(fields map[string]interface{}) error
 	// Delete sets DelFlag to 1
@@ -35,51 +39,70 @@ type MiddlewareCluster interface {
 
 type MiddlewareClusterRepo interface {
 	// Execute executes given command and placeholders on the middleware
-	Execute(command string, args ...interface{}) (middleware.Result, error)
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
 	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
 	Transaction() (middleware.Transaction, error)
 	// GetAll gets all middleware clusters from the middleware
-	GetAll() ([]MiddlewareCluster, error)
+	GetAll(ctx context.Context) ([]MiddlewareCluster, error)
+	// GetByQuery gets the middleware clusters which match the query from the middleware, it also returns the total number of the matched middleware clusters
+	GetByQuery(ctx context.Context, query *filter.Query) ([]MiddlewareCluster, int, error)
 	// GetByEnv gets middleware clusters of given env id from the middleware
-	GetByEnv(envID int) ([]MiddlewareCluster, error)
+	GetByEnv(ctx context.Context, envID int) ([]MiddlewareCluster, error)
 	// GetByID gets a middleware cluster by the identity from the middleware
-	GetByID(id int) (MiddlewareCluster, error)
+	GetByID(ctx context.Context, id int) (MiddlewareCluster, error)
 	// GetByName gets a middleware cluster of given cluster name from the middle ware
-	GetByName(clusterName string) (MiddlewareCluster, error)
+	GetByName(ctx context.Context, clusterName string) (MiddlewareCluster, error)
 	// GetID gets the identity with given cluster name and env id from the middleware
-	GetID(clusterName string, envID int) (int, error)
+	GetID(ctx context.Context, clusterName string, envID int) (int, error)
 	// GetMiddlewareServerIDList gets the middleware server id list of given cluster id from the middle ware
-	GetMiddlewareServerIDList(clusterID int) ([]int, error)
+	GetMiddlewareServerIDList(ctx context.Context, clusterID int) ([]int, error)
 	// Create creates a middleware cluster in the middleware
-	Create(mc MiddlewareCluster) (MiddlewareCluster, error)
+	Create(ctx context.Context, mc MiddlewareCluster) (MiddlewareCluster, error)
 	// Update updates the middleware cluster in the middleware
-	Update(mc MiddlewareCluster) error
+	// only if the row was not modified after the entity had been read, otherwise, it returns a conflict error
+	Update(ctx context.Context, mc MiddlewareCluster) error
 	// Delete deletes the middleware cluster in the middleware
-	Delete(id int) error
+	// only if the row was not modified after it had been read with given last update time, otherwise, it returns a conflict error
+	Delete(ctx context.Context, id int, lastUpdateTime time.Time) error
+	// DeleteCascade deletes the middleware cluster and the metadata which reference it in the middleware,
+	// it returns the rows which are deleted or detached along with the middleware cluster
+	DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]CascadedRow, error)
+	// Undelete restores the deleted middleware cluster in the middleware
+	Undelete(ctx context.Context, id int) error
 }
 
 type MiddlewareClusterService interface {
 	// GetMiddlewareClusters returns middleware clusters of the service
 	GetMiddlewareClusters() []MiddlewareCluster
 	// GetAll gets all middleware clusters from the middleware
-	GetAll() error
+	GetAll(ctx context.Context) error
+	// GetByQuery gets the middleware clusters which match the query from the middleware, and the pagination information
+	GetByQuery(ctx context.Context, query *filter.Query) error
 	// GetByEnv gets middleware clusters of given env id
-	GetByEnv(envID int) error
+	GetByEnv(ctx context.Context, envID int) error
 	// GetByID gets a middleware cluster of the given id from the middleware
-	GetByID(id int) error
+	GetByID(ctx context.Context, id int) error
 	// GetByName gets a middleware cluster of given cluster name
-	GetByName(clusterName string) error
+	GetByName(ctx context.Context, clusterName string) error
 	// GetMiddlewareServerIDList gets the middleware server id list of given cluster id
-	GetMiddlewareServerIDList(clusterID int) ([]int, error)
+	GetMiddlewareServerIDList(ctx context.Context, clusterID int) ([]int, error)
 	// Create creates a middleware cluster in the middleware
-	Create(fields map[string]interface{}) error
+	Create(ctx context.Context, fields map[string]interface{}) error
 	// Update gets a middleware cluster of the given id from the middleware,
 	// and then updates its fields that was specified in fields argument,
 	// key is the filed name and value is the new field value,
 	// it saves the changes to the middleware
-	Update(id int, fields map[string]interface{}) error
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
 	// Delete deletes the middleware cluster of given id in the middleware
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
+	// DeleteCascade deletes the middleware cluster of given id and the metadata which reference it in the middleware
+	DeleteCascade(ctx context.Context, id int) error
+	// Undelete restores the deleted middleware cluster of given id in the middleware
+	Undelete(ctx context.Context, id int) error
+	// SetAuditor sets the auditor which records the changes made by the service
+	SetAuditor(auditor audit.Auditor)
+	// SetIfMatch sets the entity tags which the entity to update or delete must match, it is the value of the If-Match header
+	SetIfMatch(ifMatch string)
 	// Marshal marshals MiddlewareClusterService.MiddlewareClusters to json bytes
 	Marshal() ([]byte, error)
 	// MarshalWithFields marshals only specified fields of the MiddlewareClusterService to json bytes
@@ -1,9 +1,13 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
 )
 
 type MiddlewareServer interface {
@@ -37,47 +41,66 @@ type MiddlewareServer interface {
 
 type MiddlewareServerRepo interface {
 	// Execute executes given command and placeholders on the middleware
-	Execute(command string, args ...interface{}) (middleware.Result, error)
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
 	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
 	Transaction() (middleware.Transaction, error)
 	// GetAll gets all middleware servers from the middleware
-	GetAll() ([]MiddlewareServer, error)
+	GetAll(ctx context.Context) ([]MiddlewareServer, error)
+	// GetByQuery gets the middleware servers which match the query from the middleware, it also returns the total number of the matched middleware servers
+	GetByQuery(ctx context.Context, query *filter.Query) ([]MiddlewareServer, int, error)
 	// GetByClusterID gets middleware servers with given cluster id
-	GetByClusterID(clusterID int) ([]MiddlewareServer, error)
+	GetByClusterID(ctx context.Context, clusterID int) ([]MiddlewareServer, error)
 	// GetByID gets a middleware server by the identity from the middleware
-	GetByID(id int) (MiddlewareServer, error)
+	GetByID(ctx context.Context, id int) (MiddlewareServer, error)
 	// GetByHostInfo gets a middleware server with given host ip and port number
-	GetByHostInfo(hostIP string, portNum int) (MiddlewareServer, error)
+	GetByHostInfo(ctx context.Context, hostIP string, portNum int) (MiddlewareServer, error)
 	// GetID gets the identity with given host ip and port number from the middleware
-	GetID(hostIP string, portNum int) (int, error)
+	GetID(ctx context.Context, hostIP string, portNum int) (int, error)
 	// Create creates a middleware server in the middleware
-	Create(ms MiddlewareServer) (MiddlewareServer, error)
+	Create(ctx context.Context, ms MiddlewareServer) (MiddlewareServer, error)
 	// Update updates the middleware server in the middleware
-	Update(ms MiddlewareServer) error
+	// only if the row was not modified after the entity had been read, otherwise, it returns a conflict error
+	Update(ctx context.Context, ms MiddlewareServer) error
 	// Delete deletes the middleware server in the middleware
-	Delete(id int) error
+	// only if the row was not modified after it had been read with given last update time, otherwise, it returns a conflict error
+	Delete(ctx context.Context, id int, lastUpdateTime time.Time) error
+	// DeleteCascade deletes the middleware server and the metadata which reference it in the middleware,
+	// it returns the rows which are deleted or detached along with the middleware server
+	DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]CascadedRow, error)
+	// Undelete restores the deleted middleware server in the middleware
+	Undelete(ctx context.Context, id int) error
 }
 
 type MiddlewareServerService interface {
 	// GetMiddlewareServers returns middleware servers of the service
 	GetMiddlewareServers() []MiddlewareServer
 	// GetAll gets all middleware servers from the middleware
-	GetAll() error
+	GetAll(ctx context.Context) error
+	// GetByQuery gets the middleware servers which match the query from the middleware, and the pagination information
+	GetByQuery(ctx context.Context, query *filter.Query) error
 	// GetByClusterID gets middleware servers with given cluster id
-	GetByClusterID(clusterID int) error
+	GetByClusterID(ctx context.Context, clusterID int) error
 	// GetByID gets a middleware server of the given id from the middleware
-	GetByID(id int) error
+	GetByID(ctx context.Context, id int) error
 	// GetByHostInfo gets a middleware server with given host ip and port number
-	GetByHostInfo(hostIP string, portNum int) error
+	GetByHostInfo(ctx context.Context, hostIP string, portNum int) error
 	// Create creates a middleware server in the middleware
-	Create(fields map[string]interface{}) error
+	Create(ctx context.Context, fields map[string]interface{}) error
 	// Update gets a middleware server of the given id from the middleware,
 	// and then updates its fields that was specified in fields argument,
 	// key is the filed name and value is the new field value,
 	// it saves the changes to the middleware
-	Update(id int, fields map[string]interface{}) error
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
 	// Delete deletes the middleware server of given id in the middleware
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
+	// DeleteCascade deletes the middleware server of given id and the metadata which reference it in the middleware
+	DeleteCascade(ctx context.Context, id int) error
+	// Undelete restores the deleted middleware server of given id in the middleware
+	Undelete(ctx context.Context, id int) error
+	// SetAuditor sets the auditor which records the changes made by the service
+	SetAuditor(auditor audit.Auditor)
+	// SetIfMatch sets the entity tags which the entity to update or delete must match, it is the value of the If-Match header
+	SetIfMatch(ifMatch string)
 	// Marshal marshals MiddlewareServerService.MiddlewareServers to json bytes
 	Marshal() ([]byte, error)
 	// MarshalWithFields marshals only specified fields of the MiddlewareServerService to json bytes
@@ -1,9 +1,13 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
 )
 
 type MonitorSystem interface {
@@ -41,47 +45,66 @@ type MonitorSystem interface {
 
 type MonitorSystemRepo interface {
 	// Execute executes given command and placeholders on the middleware
-	Execute(command string, args ...interface{}) (middleware.Result, error)
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
 	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
 	Transaction() (middleware.Transaction, error)
 	// GetAll gets all monitor systems from the middleware
-	GetAll() ([]MonitorSystem, error)
+	GetAll(ctx context.Context) ([]MonitorSystem, error)
+	// GetByQuery gets the monitor systems which match the query from the middleware, it also returns the total number of the matched monitor systems
+	GetByQuery(ctx context.Context, query *filter.Query) ([]MonitorSystem, int, error)
 	// GetByEnv gets monitor systems of given env id from the middleware
-	GetByEnv(envID int) ([]MonitorSystem, error)
+	GetByEnv(ctx context.Context, envID int) ([]MonitorSystem, error)
 	// GetByID gets a monitor system by the identity from the middleware
-	GetByID(id int) (MonitorSystem, error)
+	GetByID(ctx context.Context, id int) (MonitorSystem, error)
 	// GetByHostInfo gets a monitor system with given host ip and port number
-	GetByHostInfo(hostIP string, portNum int) (MonitorSystem, error)
+	GetByHostInfo(ctx context.Context, hostIP string, portNum int) (MonitorSystem, error)
 	// GetID gets the identity with given host ip and port number from the middleware
-	GetID(hostIP string, portNum int) (int, error)
+	GetID(ctx context.Context, hostIP string, portNum int) (int, error)
 	// Create creates a monitor system in the middleware
-	Create(ms MonitorSystem) (MonitorSystem, error)
+	Create(ctx context.Context, ms MonitorSystem) (MonitorSystem, error)
 	// Update updates the monitor system in the middleware
-	Update(ms MonitorSystem) error
+	// only if the row was not modified after the entity had been read, otherwise, it returns a conflict error
+	Update(ctx context.Context, ms MonitorSystem) error
 	// Delete deletes the monitor system in the middleware
-	Delete(id int) error
+	// only if the row was not modified after it had been read with given last update time, otherwise, it returns a conflict error
+	Delete(ctx context.Context, id int, lastUpdateTime time.Time) error
+	// DeleteCascade deletes the monitor system and the metadata which reference it in the middleware,
+	// it returns the rows which are deleted or detached along with the monitor system
+	DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]CascadedRow, error)
+	// Undelete restores the deleted monitor system in the middleware
+	Undelete(ctx context.Context, id int) error
 }
 
 type MonitorSystemService interface {
 	// GetDBs returns monitor systems of the service
 	GetMonitorSystems() []MonitorSystem
 	// GetAll gets all monitor systems from the middleware
-	GetAll() error
+	GetAll(ctx context.Context) error
+	// GetByQuery gets the monitor systems which match the query from the middleware, and the pagination information
+	GetByQuery(ctx context.Context, query *filter.Query) error
 	// GetByEnv gets monitor systems of given env id
-	GetByEnv(envID int) error
+	GetByEnv(ctx context.Context, envID int) error
 	// GetByID gets a monitor system of the given id from the middleware
-	GetByID(id int) error
+	GetByID(ctx context.Context, id int) error
 	// GetByHostInfo gets a monitor system with given host ip and port number
-	GetByHostInfo(hostIP string, portNum int) error
+	GetByHostInfo(ctx context.Context, hostIP string, portNum int) error
 	// Create creates a monitor system in the middleware
-	Create(fields map[string]interface{}) error
+	Create(ctx context.Context, fields map[string]interface{}) error
 	// Update gets a monitor system of the given id from the middleware,
 	// and then updates its fields that was specified in fields argument,
 	// key is the filed name and value is the new field value,
 	// it saves the changes to the middleware
-	Update(id int, fields map[string]interface{}) error
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
 	// Delete deletes the monitor system of given id in the middleware
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
+	// DeleteCascade deletes the monitor system of given id and the metadata which reference it in the middleware
+	DeleteCascade(ctx context.Context, id int) error
+	// Undelete restores the deleted monitor system of given id in the middleware
+	Undelete(ctx context.Context, id int) error
+	// SetAuditor sets the auditor which records the changes made by the service
+	SetAuditor(auditor audit.Auditor)
+	// SetIfMatch sets the entity tags which the entity to update or delete must match, it is the value of the If-Match header
+	SetIfMatch(ifMatch string)
 	// Marshal marshals MonitorSystemService.MonitorSystems to json bytes
 	Marshal() ([]byte, error)
 	// MarshalWithFields marshals only specified fields of the MonitorSystemService to json bytes
@@ -1,9 +1,13 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
 )
 
 // MySQLCluster is the entity interface
@@ -27,7 +31,7 @@ type MySQLCluster interface {
 	// GetLastUpdateTime returns the last update time
 	GetLastUpdateTime() time.Time
 	// GetMySQLServerIDList gets the mysql server id list of this cluster
-	GetMySQLServerIDList() ([]int, error)
+	GetMySQLServerIDList(ctx context.Context) ([]int, error)
 	// Set sets MySQLCluster with given fields, key is the field name and value is the relevant value of the key
 	Set(fields map[string]interface{}) error
 	// Delete sets DelFlag to 1
@@ -41,27 +45,36 @@ type MySQLCluster interface {
 // MySQLClusterRepo is the repository interface
 type MySQLClusterRepo interface {
 	// Execute executes given command and placeholders on the middleware
-	Execute(command string, args ...interface{}) (middleware.Result, error)
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
 	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
 	Transaction() (middleware.Transaction, error)
 	// GetAll gets all mysql clusters from the middleware
-	GetAll() ([]MySQLCluster, error)
+	GetAll(ctx context.Context) ([]MySQLCluster, error)
+	// GetByQuery gets the mysql clusters which match the query from the middleware, it also returns the total number of the matched mysql clusters
+	GetByQuery(ctx context.Context, query *filter.Query) ([]MySQLCluster, int, error)
 	// GetByEnv gets mysql clusters of given env id from the middleware
-	GetByEnv(envID int) ([]MySQLCluster, error)
+	GetByEnv(ctx context.Context, envID int) ([]MySQLCluster, error)
 	// GetByID gets a mysql cluster by the identity from the middleware
-	GetByID(id int) (MySQLCluster, error)
+	GetByID(ctx context.Context, id int) (MySQLCluster, error)
 	// GetByName gets a mysql cluster of given cluster name from the middle ware
-	GetByName(clusterName string) (MySQLCluster, error)
+	GetByName(ctx context.Context, clusterName string) (MySQLCluster, error)
 	// GetID gets the identity with given cluster name from the middleware
-	GetID(clusterName string) (int, error)
+	GetID(ctx context.Context, clusterName string) (int, error)
 	// GetMySQLServerIDList gets the mysql server id list of given cluster id
-	GetMySQLServerIDList(clusterID int) ([]int, error)
+	GetMySQLServerIDList(ctx context.Context, clusterID int) ([]int, error)
 	// Create creates a mysql cluster in the middleware
-	Create(mc MySQLCluster) (MySQLCluster, error)
+	Create(ctx context.Context, mc MySQLCluster) (MySQLCluster, error)
 	// Update updates the mysql cluster in the middleware
-	Update(mc MySQLCluster) error
+	// only if the row was not modified after the entity had been read, otherwise, it returns a conflict error
+	Update(ctx context.Context, mc MySQLCluster) error
 	// Delete deletes the mysql cluster in the middleware
-	Delete(id int) error
+	// only if the row was not modified after it had been read with given last update time, otherwise, it returns a conflict error
+	Delete(ctx context.Context, id int, lastUpdateTime time.Time) error
+	// DeleteCascade deletes the mysql cluster and the metadata which reference it in the middleware,
+	// it returns the rows which are deleted or detached along with the mysql cluster
+	DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]CascadedRow, error)
+	// Undelete restores the deleted mysql cluster in the middleware
+	Undelete(ctx context.Context, id int) error
 }
 
 // MySQLClusterService is the service interface
@@ -69,24 +82,34 @@ type MySQLClusterService interface {
 	// GetMySQLClusters returns mysql clusters of the service
 	GetMySQLClusters() []MySQLCluster
 	// GetAll gets all mysql clusters from the middleware
-	GetAll() error
+	GetAll(ctx context.Context) error
+	// GetByQuery gets the mysql clusters which match the query from the middleware, and the pagination information
+	GetByQuery(ctx context.Context, query *filter.Query) error
 	// GetByEnv gets mysql clusters of given env id
-	GetByEnv(envID int) error
+	GetByEnv(ctx context.Context, envID int) error
 	// GetByID gets a mysql cluster of the given id from the middleware
-	GetByID(id int) error
+	GetByID(ctx context.Context, id int) error
 	// GetByName gets a mysql cluster of given cluster name
-	GetByName(clusterName string) error
+	GetByName(ctx context.Context, clusterName string) error
 	// GetMySQLServerIDList gets the mysql server id list of given cluster id
-	GetMySQLServerIDList(clusterID int) error
+	GetMySQLServerIDList(ctx context.Context, clusterID int) error
 	// Create creates a mysql cluster in the middleware
-	Create(fields map[string]interface{}) error
+	Create(ctx context.Context, fields map[string]interface{}) error
 	// Update gets a mysql cluster of the given id from the middleware,
 	// and then updates its fields that was specified in fields argument,
 	// key is the filed name and value is the new field value,
 	// it saves the changes to the middleware
-	Update(id int, fields map[string]interface{}) error
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
 	// Delete deletes the mysql cluster of given id in the middleware
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
+	// DeleteCascade deletes the mysql cluster of given id and the metadata which reference it in the middleware
+	DeleteCascade(ctx context.Context, id int) error
+	// Undelete restores the deleted mysql cluster of given id in the middleware
+	Undelete(ctx context.Context, id int) error
+	// SetAuditor sets the auditor which records the changes made by the service
+	SetAuditor(auditor audit.Auditor)
+	// SetIfMatch sets the entity tags which the entity to update or delete must match, it is the value of the If-Match header
+	SetIfMatch(ifMatch string)
 	// Marshal marshals MySQLClusterService.MySQLClusters to json bytes
 	Marshal() ([]byte, error)
 	// MarshalWithFields marshals only specified fields of the MySQLClusterService to json bytes
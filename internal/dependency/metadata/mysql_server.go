@@ -1,9 +1,13 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
 )
 
 // MySQLServer is the entity interface
@@ -22,8 +26,24 @@ type MySQLServer interface {
 	GetPortNum() int
 	// GetDeploymentType returns the deployment type
 	GetDeploymentType() int
+	// GetServerRole returns the server role, 1-primary, 2-replica, 3-delayed replica
+	GetServerRole() int
+	// GetReadWeight returns the read weight, 0 means the server will never be chosen for reading
+	GetReadWeight() int
+	// GetReadOnly returns the read only flag, 1 means the mysql server is read only
+	GetReadOnly() int
+	// GetCPUCores returns the number of the cpu cores, 0 means it is unknown
+	GetCPUCores() int
+	// GetMemorySize returns the memory size, the unit is MB, 0 means it is unknown
+	GetMemorySize() int
+	// GetDiskSize returns the disk size, the unit is GB, 0 means it is unknown
+	GetDiskSize() int
+	// GetDataDir returns the data directory
+	GetDataDir() string
 	// GetVersion returns the version
 	GetVersion() string
+	// GetState returns the lifecycle state, 1-provisioning, 2-online, 3-maintenance, 4-decommissioned
+	GetState() int
 	// GetDelFlag returns the delete flag
 	GetDelFlag() int
 	// GetCreateTime returns the create time
@@ -31,7 +51,7 @@ type MySQLServer interface {
 	// GetLastUpdateTime returns the last update time
 	GetLastUpdateTime() time.Time
 	// GetMonitorSystem gets monitor system from the mysql
-	GetMonitorSystem() (MonitorSystem, error)
+	GetMonitorSystem(ctx context.Context) (MonitorSystem, error)
 	// Set sets MySQLServer with given fields, key is the field name and value is the relevant value of the key
 	Set(fields map[string]interface{}) error
 	// Delete sets DelFlag to 1
@@ -45,27 +65,36 @@ type MySQLServer interface {
 // MySQLServerRepo is the repository interface
 type MySQLServerRepo interface {
 	// Execute executes given command and placeholders on the mysql
-	Execute(command string, args ...interface{}) (middleware.Result, error)
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
 	// Transaction returns a mysql.Transaction that could execute multiple commands as a transaction
 	Transaction() (middleware.Transaction, error)
 	// GetAll gets all mysql servers from the mysql
-	GetAll() ([]MySQLServer, error)
+	GetAll(ctx context.Context) ([]MySQLServer, error)
+	// GetByQuery gets the mysql servers which match the query from the middleware, it also returns the total number of the matched mysql servers
+	GetByQuery(ctx context.Context, query *filter.Query) ([]MySQLServer, int, error)
 	// GetByClusterID gets mysql servers with given cluster id
-	GetByClusterID(clusterID int) ([]MySQLServer, error)
+	GetByClusterID(ctx context.Context, clusterID int) ([]MySQLServer, error)
 	// GetByID gets a mysql server by the identity from the mysql
-	GetByID(id int) (MySQLServer, error)
+	GetByID(ctx context.Context, id int) (MySQLServer, error)
 	// GetByHostInfo gets a mysql server with given host ip and port number
-	GetByHostInfo(hostIP string, portNum int) (MySQLServer, error)
+	GetByHostInfo(ctx context.Context, hostIP string, portNum int) (MySQLServer, error)
 	// GetID gets the identity with given host ip and port number from the mysql
-	GetID(hostIP string, portNum int) (int, error)
+	GetID(ctx context.Context, hostIP string, portNum int) (int, error)
 	// GetMonitorSystem gets monitor system with given mysql server id from the mysql
-	GetMonitorSystem(id int) (MonitorSystem, error)
+	GetMonitorSystem(ctx context.Context, id int) (MonitorSystem, error)
 	// Create creates a mysql server in the mysql
-	Create(ms MySQLServer) (MySQLServer, error)
+	Create(ctx context.Context, ms MySQLServer) (MySQLServer, error)
 	// Update updates the mysql server in the mysql
-	Update(ms MySQLServer) error
+	// only if the row was not modified after the entity had been read, otherwise, it returns a conflict error
+	Update(ctx context.Context, ms MySQLServer) error
 	// Delete deletes the mysql server in the mysql
-	Delete(id int) error
+	// only if the row was not modified after it had been read with given last update time, otherwise, it returns a conflict error
+	Delete(ctx context.Context, id int, lastUpdateTime time.Time) error
+	// DeleteCascade deletes the mysql server and the metadata which reference it in the middleware,
+	// it returns the rows which are deleted or detached along with the mysql server
+	DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]CascadedRow, error)
+	// Undelete restores the deleted mysql server in the middleware
+	Undelete(ctx context.Context, id int) error
 }
 
 // MySQLServerService is the service interface
@@ -73,22 +102,38 @@ type MySQLServerService interface {
 	// GetMySQLServers returns mysql servers of the service
 	GetMySQLServers() []MySQLServer
 	// GetAll gets all mysql servers from the mysql
-	GetAll() error
+	GetAll(ctx context.Context) error
+	// GetByQuery gets the mysql servers which match the query from the middleware, and the pagination information
+	GetByQuery(ctx context.Context, query *filter.Query) error
 	// GetByClusterID gets mysql servers with given cluster id
-	GetByClusterID(clusterID int) error
+	GetByClusterID(ctx context.Context, clusterID int) error
 	// GetByID gets a mysql server of the given id from the mysql
-	GetByID(id int) error
+	GetByID(ctx context.Context, id int) error
 	// GetByHostInfo gets a mysql server with given host ip and port number
-	GetByHostInfo(hostIP string, portNum int) error
+	GetByHostInfo(ctx context.Context, hostIP string, portNum int) error
+	// GetReadableByClusterID gets readable mysql servers of the given cluster in the order of preference,
+	// replicas come first, then the delayed replicas, primary will be included only when allowPrimary is true
+	GetReadableByClusterID(ctx context.Context, clusterID int, allowPrimary bool) error
+	// GetHealthyReadableByClusterID checks the readable mysql servers of the given cluster in the order of preference,
+	// and keeps only the first healthy one
+	GetHealthyReadableByClusterID(ctx context.Context, clusterID int, user, pass string, maxReplicationDelay int, allowPrimary bool) error
 	// Create creates a mysql server in the mysql
-	Create(fields map[string]interface{}) error
+	Create(ctx context.Context, fields map[string]interface{}) error
 	// Update gets a mysql server of the given id from the mysql,
 	// and then updates its fields that was specified in fields argument,
 	// key is the filed name and value is the new field value,
 	// it saves the changes to the mysql
-	Update(id int, fields map[string]interface{}) error
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
 	// Delete deletes the mysql server of given id in the mysql
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
+	// DeleteCascade deletes the mysql server of given id and the metadata which reference it in the middleware
+	DeleteCascade(ctx context.Context, id int) error
+	// Undelete restores the deleted mysql server of given id in the middleware
+	Undelete(ctx context.Context, id int) error
+	// SetAuditor sets the auditor which records the changes made by the service
+	SetAuditor(auditor audit.Auditor)
+	// SetIfMatch sets the entity tags which the entity to update or delete must match, it is the value of the If-Match header
+	SetIfMatch(ifMatch string)
 	// Marshal marshals MySQLServerService.MySQLServers to json bytes
 	Marshal() ([]byte, error)
 	// MarshalWithFields marshals only specified fields of the MySQLServerService to json bytes
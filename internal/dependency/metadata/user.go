@@ -1,9 +1,13 @@
 package metadata
 
 import (
+	"context"
 	"time"
 
 	"github.com/romberli/go-util/middleware"
+
+	"github.com/romberli/das/internal/dependency/audit"
+	"github.com/romberli/das/pkg/filter"
 )
 
 type User interface {
@@ -23,6 +27,8 @@ type User interface {
 	GetTelephone() string
 	// GetMobile returns the mobile
 	GetMobile() string
+	// GetRole returns the role
+	GetRole() int
 	// GetDelFlag returns the delete flag
 	GetDelFlag() int
 	// GetCreateTime returns the create time
@@ -41,65 +47,84 @@ type User interface {
 
 type UserRepo interface {
 	// Execute executes given command and placeholders on the middleware
-	Execute(command string, args ...interface{}) (middleware.Result, error)
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
 	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
 	Transaction() (middleware.Transaction, error)
 	// GetAll gets all databases from the middleware
-	GetAll() ([]User, error)
+	GetAll(ctx context.Context) ([]User, error)
+	// GetByQuery gets the users which match the query from the middleware, it also returns the total number of the matched users
+	GetByQuery(ctx context.Context, query *filter.Query) ([]User, int, error)
 	// GetByName gets users of given user name from the middleware
-	GetByName(userName string) ([]User, error)
+	GetByName(ctx context.Context, userName string) ([]User, error)
 	// GetByID gets a user by the identity from the middleware
-	GetByID(id int) (User, error)
+	GetByID(ctx context.Context, id int) (User, error)
 	// GetByAccountName gets a user of given account name from the middleware
-	GetByAccountName(accountName string) (User, error)
+	GetByAccountName(ctx context.Context, accountName string) (User, error)
 	// GetByEmail gets a user of given email from the middleware
-	GetByEmail(email string) (User, error)
+	GetByEmail(ctx context.Context, email string) (User, error)
 	// GetByTelephone gets a user of given telephone from the middleware
-	GetByTelephone(telephone string) (User, error)
+	GetByTelephone(ctx context.Context, telephone string) (User, error)
 	// GetByTelephone gets a user of given mobile from the middleware
-	GetByMobile(mobile string) (User, error)
+	GetByMobile(ctx context.Context, mobile string) (User, error)
 	// GetID gets the identity with given accountName from the middleware
-	GetID(accountName string) (int, error)
+	GetID(ctx context.Context, accountName string) (int, error)
 	// Create creates a user in the middleware
-	Create(db User) (User, error)
+	Create(ctx context.Context, db User) (User, error)
 	// Update updates a user in the middleware
-	Update(db User) error
+	// only if the row was not modified after the entity had been read, otherwise, it returns a conflict error
+	Update(ctx context.Context, db User) error
 	// Delete deletes a user in the middleware
-	Delete(id int) error
+	// only if the row was not modified after it had been read with given last update time, otherwise, it returns a conflict error
+	Delete(ctx context.Context, id int, lastUpdateTime time.Time) error
+	// DeleteCascade deletes the user and the metadata which reference it in the middleware,
+	// it returns the rows which are deleted or detached along with the user
+	DeleteCascade(ctx context.Context, id int, lastUpdateTime time.Time) ([]CascadedRow, error)
+	// Undelete restores the deleted user in the middleware
+	Undelete(ctx context.Context, id int) error
 	// GetByEmployeeID gets a user of given employee id from the middleware
-	GetByEmployeeID(employeeID string) (User, error)
+	GetByEmployeeID(ctx context.Context, employeeID string) (User, error)
 }
 
 type UserService interface {
 	// GetUsers returns users of the service
 	GetUsers() []User
 	// GetAll gets all users
-	GetAll() error
+	GetAll(ctx context.Context) error
+	// GetByQuery gets the users which match the query from the middleware, and the pagination information
+	GetByQuery(ctx context.Context, query *filter.Query) error
 	// GetByName gets users of given user name
-	GetByName(userName string) error
+	GetByName(ctx context.Context, userName string) error
 	// GetByID gets a user by the identity
-	GetByID(id int) error
+	GetByID(ctx context.Context, id int) error
 	// GetByAccountName gets a user of given account name
-	GetByAccountName(accountName string) error
+	GetByAccountName(ctx context.Context, accountName string) error
 	// GetByEmail gets a user of given email
-	GetByEmail(email string) error
+	GetByEmail(ctx context.Context, email string) error
 	// GetByTelephone gets a user of given telephone
-	GetByTelephone(telephone string) error
+	GetByTelephone(ctx context.Context, telephone string) error
 	// GetByTelephone gets a user of given mobile
-	GetByMobile(mobile string) error
+	GetByMobile(ctx context.Context, mobile string) error
 	// Create creates a user in the middleware
-	Create(fields map[string]interface{}) error
+	Create(ctx context.Context, fields map[string]interface{}) error
 	// Update gets a user of the given id from the middleware,
 	// and then updates its fields that was specified in fields argument,
 	// key is the filed name and value is the new field value,
 	// it saves the changes to the middleware
-	Update(id int, fields map[string]interface{}) error
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
 	// Delete deletes the user of given id in the middleware
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
+	// DeleteCascade deletes the user of given id and the metadata which reference it in the middleware
+	DeleteCascade(ctx context.Context, id int) error
+	// Undelete restores the deleted user of given id in the middleware
+	Undelete(ctx context.Context, id int) error
+	// SetAuditor sets the auditor which records the changes made by the service
+	SetAuditor(auditor audit.Auditor)
+	// SetIfMatch sets the entity tags which the entity to update or delete must match, it is the value of the If-Match header
+	SetIfMatch(ifMatch string)
 	// Marshal marshals UserService.Users to json bytes
 	Marshal() ([]byte, error)
 	// MarshalWithFields marshals only specified fields of the UserService to json bytes
 	MarshalWithFields(fields ...string) ([]byte, error)
 	// GetByEmployeeID gets a user of given employee id
-	GetByEmployeeID(employeeID string) error
+	GetByEmployeeID(ctx context.Context, employeeID string) error
 }
@@ -0,0 +1,16 @@
+package monitorsync
+
+import (
+	"context"
+)
+
+type Service interface {
+	// SyncByMonitorSystem pulls the monitored mysql services from the monitor system,
+	// matches them with the registered mysql servers and reports the differences,
+	// the missing mysql servers will be created only if create is true
+	SyncByMonitorSystem(ctx context.Context, monitorSystemID int, create bool, clusterID int) error
+	// Marshal marshals Service to json bytes
+	Marshal() ([]byte, error)
+	// MarshalWithFields marshals only specified fields of the Service to json bytes
+	MarshalWithFields(fields ...string) ([]byte, error)
+}
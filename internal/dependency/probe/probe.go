@@ -0,0 +1,27 @@
+package probe
+
+import (
+	"context"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+type Repository interface {
+	// Execute executes given command and placeholders on the middleware
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
+	// Ping checks if the middleware is available
+	Ping(ctx context.Context) error
+}
+
+type Service interface {
+	// Check checks the dependencies of das, the monitor systems are checked only if checkMonitorSystems is true
+	Check(ctx context.Context, checkMonitorSystems bool)
+	// IsReady returns if all the checked dependencies are available
+	IsReady() bool
+	// GetFailedDependencies returns the names of the unavailable dependencies
+	GetFailedDependencies() []string
+	// Marshal marshals Service to json bytes
+	Marshal() ([]byte, error)
+	// MarshalWithFields marshals only specified fields of the Service to json bytes
+	MarshalWithFields(fields ...string) ([]byte, error)
+}
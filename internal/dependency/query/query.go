@@ -0,0 +1,21 @@
+package query
+
+import (
+	"context"
+	"time"
+)
+
+type Service interface {
+	// GetTopByMySQLServerID gets the top n slow queries of the given mysql server in the given time window,
+	// if db name is not empty, only slow queries of the db will be returned
+	GetTopByMySQLServerID(ctx context.Context, mysqlServerID int, dbName string, startTime, endTime time.Time, orderBy string, limit int) error
+	// GetTrendByMySQLServerID gets the trend of the slow query with given sql id of the given mysql server in the given time window
+	GetTrendByMySQLServerID(ctx context.Context, mysqlServerID int, sqlID string, startTime, endTime time.Time) error
+	// GetRegressionByMySQLServerID compares the slow queries of the current window with the base window,
+	// and returns the slow queries of which average execution time grows more than threshold times
+	GetRegressionByMySQLServerID(ctx context.Context, mysqlServerID int, dbName string, baseStartTime, baseEndTime, startTime, endTime time.Time, threshold float64, limit int) error
+	// Marshal marshals Service to json bytes
+	Marshal() ([]byte, error)
+	// MarshalWithFields marshals only specified fields of the Service to json bytes
+	MarshalWithFields(fields ...string) ([]byte, error)
+}
@@ -0,0 +1,65 @@
+package review
+
+import (
+	"context"
+	"time"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+type Review interface {
+	// Identity returns the identity
+	Identity() int
+	// GetDBID returns the db id
+	GetDBID() int
+	// GetSQLText returns the sql text
+	GetSQLText() string
+	// GetVerdict returns the verdict of the whole sql text
+	GetVerdict() int
+	// GetResult returns the review results of each statement
+	GetResult() string
+	// GetApprovalStatus returns the approval status
+	GetApprovalStatus() int
+	// GetApproverID returns the approver id
+	GetApproverID() int
+	// GetApproveTime returns the approve time
+	GetApproveTime() time.Time
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+	// MarshalJSON marshals Review to json string
+	MarshalJSON() ([]byte, error)
+	// MarshalJSONWithFields marshals only specified field of the Review to json string
+	MarshalJSONWithFields(fields ...string) ([]byte, error)
+}
+
+type Repository interface {
+	// Execute executes given command and placeholders on the middleware
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
+	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+	Transaction() (middleware.Transaction, error)
+	// GetByID gets the review record by the identity from the middleware
+	GetByID(ctx context.Context, id int) (Review, error)
+	// Create creates a review record in the middleware and returns the identity
+	Create(ctx context.Context, review Review) (int, error)
+	// Approve updates the approval status of the review record to approved in the middleware
+	Approve(ctx context.Context, id, approverID int) error
+}
+
+type Service interface {
+	// GetReviews returns the review records of the service
+	GetReviews() []Review
+	// GetByID gets the review record by the identity
+	GetByID(ctx context.Context, id int) error
+	// Review reviews the sql text of the given db, saves and returns the review record
+	Review(ctx context.Context, dbID int, sqlText string) error
+	// Approve approves the review record by the user of given account name, the user must have the dba role
+	Approve(ctx context.Context, id int, accountName string) error
+	// Marshal marshals Service to json bytes
+	Marshal() ([]byte, error)
+	// MarshalWithFields marshals only specified fields of the Service to json bytes
+	MarshalWithFields(fields ...string) ([]byte, error)
+}
@@ -1,8 +1,13 @@
 package sqladvisor
 
 import (
+	"context"
+	"time"
+
 	"github.com/romberli/go-util/middleware"
 	"github.com/romberli/go-util/middleware/sql/parser"
+
+	"github.com/romberli/das/internal/dependency/metadata"
 )
 
 type Advisor interface {
@@ -13,16 +18,57 @@ type Advisor interface {
 	// GetSQLID returns the identity of the sql text
 	GetSQLID(sqlText string) string
 	// Advise parses the sql text and returns the tuning advice
-	Advise(dbID int, sqlText string) (string, string, error)
+	Advise(ctx context.Context, dbID int, sqlText string) (string, string, error)
 }
 
 type Repository interface {
 	// Execute executes given command and placeholders on the middleware
-	Execute(command string, args ...interface{}) (middleware.Result, error)
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
 	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
 	Transaction() (middleware.Transaction, error)
 	// Save saves sql tuning advice into the middleware
-	Save(dbID int, sqlText, advice, message string) error
+	Save(ctx context.Context, dbID int, sqlText, advice, message string) error
+	// GetSlowQueryAdviceBySQLID gets the slow query advices of all databases with given sql id from the middleware
+	GetSlowQueryAdviceBySQLID(ctx context.Context, sqlID string) ([]SlowQueryAdvice, error)
+	// IsSlowQueryAdvised checks if the slow query with given sql id and fingerprint of the database had been advised
+	IsSlowQueryAdvised(ctx context.Context, dbID int, sqlID, fingerprint string) (bool, error)
+	// SaveSlowQueryAdvice saves the slow query advice into the middleware,
+	// if the advice of the same database and sql id exists, it will be replaced
+	SaveSlowQueryAdvice(ctx context.Context, advice SlowQueryAdvice) error
+}
+
+type SlowQueryAdvice interface {
+	// Identity returns the identity
+	Identity() int
+	// GetMySQLClusterID returns the mysql cluster id
+	GetMySQLClusterID() int
+	// GetDBID returns the db id
+	GetDBID() int
+	// GetSQLID returns the sql id
+	GetSQLID() string
+	// GetFingerprint returns the fingerprint
+	GetFingerprint() string
+	// GetSQLText returns the sql text
+	GetSQLText() string
+	// GetAdvice returns the advice
+	GetAdvice() string
+	// GetMessage returns the message
+	GetMessage() string
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+	// MarshalJSON marshals SlowQueryAdvice to json string
+	MarshalJSON() ([]byte, error)
+	// MarshalJSONWithFields marshals only specified field of the SlowQueryAdvice to json string
+	MarshalJSONWithFields(fields ...string) ([]byte, error)
+}
+
+type Notifier interface {
+	// Notify sends the message with given subject and content to the users
+	Notify(users []metadata.User, subject, content string) error
 }
 
 type Service interface {
@@ -30,7 +76,16 @@ type Service interface {
 	GetFingerprint(sqlText string) string
 	// GetSQLID returns the identity of the sql text
 	GetSQLID(sqlText string) string
+	// ParseSQLInfos gets the fingerprint, sql id and statement type of each sql text,
+	// the statement type of the sql text which could not be parsed is unknown
+	ParseSQLInfos(sqlTexts []string)
 	// Advise parses the sql text and returns the tuning advice,
 	// note that only the first sql statement in the sql text will be advised
-	Advise(dbID int, sqlText string) (string, error)
+	Advise(ctx context.Context, dbID int, sqlText string) (string, error)
+	// GetSlowQueryAdvices returns the slow query advices of the service
+	GetSlowQueryAdvices() []SlowQueryAdvice
+	// GetAdviceBySQLID gets the slow query advices of all databases with given sql id
+	GetAdviceBySQLID(ctx context.Context, sqlID string) error
+	// MarshalWithFields marshals only specified fields of the Service to json bytes
+	MarshalWithFields(fields ...string) ([]byte, error)
 }
@@ -0,0 +1,24 @@
+package topology
+
+import (
+	"context"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+type Repository interface {
+	// Execute executes given command and placeholders on the middleware
+	Execute(ctx context.Context, command string, args ...interface{}) (middleware.Result, error)
+}
+
+type Service interface {
+	// GetGraph gets the graph around the metadata entity of the given node type and id,
+	// depth is the max number of the edges between the entity and the other nodes
+	GetGraph(ctx context.Context, nodeType string, id, depth int) error
+	// GetImpact gets the entities which are affected if the mysql server goes down
+	GetImpact(ctx context.Context, mysqlServerID int) error
+	// Marshal marshals Service to json bytes
+	Marshal() ([]byte, error)
+	// MarshalWithFields marshals only specified fields of the Service to json bytes
+	MarshalWithFields(fields ...string) ([]byte, error)
+}
@@ -0,0 +1,309 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	// reserved query parameters, all the others are field filters
+	SortParam   = "sort"
+	LimitParam  = "limit"
+	OffsetParam = "offset"
+	CursorParam = "cursor"
+
+	// MaxLimit is the max number of rows of a page
+	MaxLimit = 10000
+
+	OperatorEqual        = "="
+	OperatorIn           = "in"
+	OperatorLike         = "like"
+	OperatorGreaterEqual = ">="
+	OperatorLessEqual    = "<="
+
+	likeSuffix         = "_like"
+	greaterEqualSuffix = "_ge"
+	lessEqualSuffix    = "_le"
+	descPrefix         = "-"
+	idColumn           = "id"
+	valueSeparator     = ","
+)
+
+// Condition is a filter on a column
+type Condition struct {
+	Column   string
+	Operator string
+	Values   []string
+}
+
+// Sort is an order by item
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Page is the pagination information of a list result
+type Page struct {
+	Total      int `json:"total"`
+	Limit      int `json:"limit"`
+	Offset     int `json:"offset"`
+	NextCursor int `json:"next_cursor"`
+}
+
+// Query is the filters, sort and pagination of a list request,
+// limit 0 means no limit, cursor is the id of the last row of the previous page, 0 means no cursor
+type Query struct {
+	Conditions []*Condition
+	Sorts      []*Sort
+	Limit      int
+	Offset     int
+	Cursor     int
+}
+
+// NewQuery returns an empty *Query which matches all rows ordered by id
+func NewQuery() *Query {
+	return &Query{
+		Conditions: []*Condition{},
+		Sorts:      []*Sort{},
+	}
+}
+
+// NewQueryWithValues returns a new *Query with the url query values, such as:
+//
+//	app_name=app1             equal
+//	env_id=1,2                in
+//	app_name_like=app         like '%app%'
+//	create_time_ge=2021-01-01 greater or equal
+//	create_time_le=2021-02-01 less or equal
+//	sort=-level,app_name      order by level desc, app_name
+//	limit=20&offset=40        pagination by offset
+//	limit=20&cursor=100       pagination by cursor, the rows after id 100
+//
+// the fields are not validated here, use Validate() to check them with the columns of the entity
+func NewQueryWithValues(values url.Values) (*Query, error) {
+	query := NewQuery()
+
+	for key, vals := range values {
+		if len(vals) == constant.ZeroInt {
+			continue
+		}
+		value := vals[len(vals)-1]
+
+		var err error
+		switch key {
+		case SortParam:
+			err = query.parseSorts(value)
+		case LimitParam:
+			query.Limit, err = parseNonNegative(key, value)
+			if err == nil && query.Limit > MaxLimit {
+				err = fmt.Errorf("limit must be less than or equal to %d, %d is not valid", MaxLimit, query.Limit)
+			}
+		case OffsetParam:
+			query.Offset, err = parseNonNegative(key, value)
+		case CursorParam:
+			query.Cursor, err = parseNonNegative(key, value)
+		default:
+			query.Conditions = append(query.Conditions, newCondition(key, value))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if query.Cursor > constant.ZeroInt {
+		if query.Offset > constant.ZeroInt {
+			return nil, errors.New("cursor and offset could not be specified at the same time")
+		}
+		for _, order := range query.Sorts {
+			if order.Column != idColumn {
+				return nil, errors.New("cursor could only be used when the rows are sorted by id")
+			}
+		}
+	}
+	// keep the order of the conditions stable, so that the generated sql is stable
+	sortConditions(query.Conditions)
+
+	return query, nil
+}
+
+// newCondition returns a new *Condition with the query parameter
+func newCondition(key, value string) *Condition {
+	switch {
+	case strings.HasSuffix(key, likeSuffix):
+		return &Condition{Column: strings.TrimSuffix(key, likeSuffix), Operator: OperatorLike, Values: []string{value}}
+	case strings.HasSuffix(key, greaterEqualSuffix):
+		return &Condition{Column: strings.TrimSuffix(key, greaterEqualSuffix), Operator: OperatorGreaterEqual, Values: []string{value}}
+	case strings.HasSuffix(key, lessEqualSuffix):
+		return &Condition{Column: strings.TrimSuffix(key, lessEqualSuffix), Operator: OperatorLessEqual, Values: []string{value}}
+	}
+
+	values := strings.Split(value, valueSeparator)
+	if len(values) > 1 {
+		return &Condition{Column: key, Operator: OperatorIn, Values: values}
+	}
+
+	return &Condition{Column: key, Operator: OperatorEqual, Values: values}
+}
+
+// parseSorts parses the sort parameter, a column with "-" prefix is sorted in descending order
+func (q *Query) parseSorts(value string) error {
+	for _, item := range strings.Split(value, valueSeparator) {
+		item = strings.TrimSpace(item)
+		if item == constant.EmptyString {
+			continue
+		}
+		order := &Sort{Column: item}
+		if strings.HasPrefix(item, descPrefix) {
+			order.Column = strings.TrimPrefix(item, descPrefix)
+			order.Desc = true
+		}
+		if order.Column == constant.EmptyString {
+			return fmt.Errorf("sort column could not be empty. sort: %s", value)
+		}
+		q.Sorts = append(q.Sorts, order)
+	}
+
+	return nil
+}
+
+// parseNonNegative parses the value as a non-negative integer
+func parseNonNegative(key, value string) (int, error) {
+	num, err := strconv.Atoi(value)
+	if err != nil || num < constant.ZeroInt {
+		return constant.ZeroInt, fmt.Errorf("%s must be a non-negative integer, %s is not valid", key, value)
+	}
+
+	return num, nil
+}
+
+// sortConditions sorts the conditions by the column and the operator
+func sortConditions(conditions []*Condition) {
+	sort.SliceStable(conditions, func(i, j int) bool {
+		if conditions[i].Column != conditions[j].Column {
+			return conditions[i].Column < conditions[j].Column
+		}
+
+		return conditions[i].Operator < conditions[j].Operator
+	})
+}
+
+// Validate checks if all the filtered and sorted columns are in the given columns,
+// the columns are trusted and will be used in the sql directly, so they must not come from the request
+func (q *Query) Validate(columns ...string) error {
+	columnMap := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		columnMap[column] = true
+	}
+
+	for _, condition := range q.Conditions {
+		if !columnMap[condition.Column] {
+			return fmt.Errorf("field %s could not be filtered", condition.Column)
+		}
+	}
+	for _, order := range q.Sorts {
+		if !columnMap[order.Column] {
+			return fmt.Errorf("field %s could not be sorted", order.Column)
+		}
+	}
+
+	return nil
+}
+
+// GetWhereClause returns the filter conditions which start with "and" and the placeholders,
+// the cursor is not included, so that the clause could also be used to count the total rows
+func (q *Query) GetWhereClause() (string, []interface{}) {
+	var (
+		builder strings.Builder
+		args    []interface{}
+	)
+
+	for _, condition := range q.Conditions {
+		switch condition.Operator {
+		case OperatorIn:
+			builder.WriteString(fmt.Sprintf(" and %s in (%s)", condition.Column,
+				strings.TrimSuffix(strings.Repeat("?, ", len(condition.Values)), ", ")))
+			for _, value := range condition.Values {
+				args = append(args, value)
+			}
+		case OperatorLike:
+			builder.WriteString(fmt.Sprintf(" and %s like ?", condition.Column))
+			args = append(args, "%"+escapeLike(condition.Values[constant.ZeroInt])+"%")
+		default:
+			builder.WriteString(fmt.Sprintf(" and %s %s ?", condition.Column, condition.Operator))
+			args = append(args, condition.Values[constant.ZeroInt])
+		}
+	}
+
+	return builder.String(), args
+}
+
+// GetCursorClause returns the cursor condition which starts with "and" and the placeholders
+func (q *Query) GetCursorClause() (string, []interface{}) {
+	if q.Cursor == constant.ZeroInt {
+		return constant.EmptyString, nil
+	}
+	if len(q.Sorts) > constant.ZeroInt && q.Sorts[constant.ZeroInt].Desc {
+		return fmt.Sprintf(" and %s < ?", idColumn), []interface{}{q.Cursor}
+	}
+
+	return fmt.Sprintf(" and %s > ?", idColumn), []interface{}{q.Cursor}
+}
+
+// GetOrderByClause returns the order by clause, id is always the last sort column to keep the order stable
+func (q *Query) GetOrderByClause() string {
+	var items []string
+	sortedByID := false
+	for _, order := range q.Sorts {
+		if order.Column == idColumn {
+			sortedByID = true
+		}
+		if order.Desc {
+			items = append(items, order.Column+" desc")
+			continue
+		}
+		items = append(items, order.Column)
+	}
+	if !sortedByID {
+		items = append(items, idColumn)
+	}
+
+	return " order by " + strings.Join(items, ", ")
+}
+
+// GetLimitClause returns the limit clause, it returns an empty string if limit is not specified
+func (q *Query) GetLimitClause() string {
+	if q.Limit == constant.ZeroInt {
+		return constant.EmptyString
+	}
+	if q.Offset == constant.ZeroInt {
+		return fmt.Sprintf(" limit %d", q.Limit)
+	}
+
+	return fmt.Sprintf(" limit %d offset %d", q.Limit, q.Offset)
+}
+
+// GetPage returns the pagination information with the total number and the ids of the returned rows,
+// next cursor is the id of the last row if the page is full, otherwise it is 0
+func (q *Query) GetPage(total int, ids []int) *Page {
+	page := &Page{
+		Total:  total,
+		Limit:  q.Limit,
+		Offset: q.Offset,
+	}
+	if q.Limit > constant.ZeroInt && len(ids) == q.Limit {
+		page.NextCursor = ids[len(ids)-1]
+	}
+
+	return page
+}
+
+// escapeLike escapes the wildcards of the like pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
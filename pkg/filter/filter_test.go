@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+var testColumns = []string{"id", "app_name", "level", "create_time"}
+
+func TestFilterAll(t *testing.T) {
+	TestNewQueryWithValues(t)
+	TestQuery_Validate(t)
+	TestQuery_GetWhereClause(t)
+	TestQuery_GetCursorClause(t)
+	TestQuery_GetOrderByClause(t)
+	TestQuery_GetLimitClause(t)
+	TestQuery_GetPage(t)
+}
+
+func TestNewQueryWithValues(t *testing.T) {
+	asst := assert.New(t)
+
+	query, err := NewQueryWithValues(url.Values{
+		"level":         {"1,2"},
+		"app_name_like": {"app"},
+		"sort":          {"-level,app_name"},
+		"limit":         {"20"},
+		"offset":        {"40"},
+	})
+	asst.Nil(err, common.CombineMessageWithError("test NewQueryWithValues() failed", err))
+	asst.Equal(2, len(query.Conditions), "test NewQueryWithValues() failed")
+	asst.Equal("app_name", query.Conditions[0].Column, "test NewQueryWithValues() failed")
+	asst.Equal(OperatorLike, query.Conditions[0].Operator, "test NewQueryWithValues() failed")
+	asst.Equal(OperatorIn, query.Conditions[1].Operator, "test NewQueryWithValues() failed")
+	asst.Equal(2, len(query.Sorts), "test NewQueryWithValues() failed")
+	asst.True(query.Sorts[0].Desc, "test NewQueryWithValues() failed")
+	asst.Equal(20, query.Limit, "test NewQueryWithValues() failed")
+	asst.Equal(40, query.Offset, "test NewQueryWithValues() failed")
+
+	invalidValues := []url.Values{
+		{"limit": {"-1"}},
+		{"limit": {"abc"}},
+		{"limit": {"10001"}},
+		{"sort": {"-"}},
+		{"cursor": {"10"}, "offset": {"10"}},
+		{"cursor": {"10"}, "sort": {"app_name"}},
+	}
+	for _, values := range invalidValues {
+		_, err = NewQueryWithValues(values)
+		asst.NotNil(err, "test NewQueryWithValues() failed")
+	}
+}
+
+func TestQuery_Validate(t *testing.T) {
+	asst := assert.New(t)
+
+	query, err := NewQueryWithValues(url.Values{"app_name": {"app1"}, "sort": {"-create_time"}})
+	asst.Nil(err, common.CombineMessageWithError("test Validate() failed", err))
+	err = query.Validate(testColumns...)
+	asst.Nil(err, common.CombineMessageWithError("test Validate() failed", err))
+
+	query, err = NewQueryWithValues(url.Values{"app_name; drop table t": {"1"}})
+	asst.Nil(err, common.CombineMessageWithError("test Validate() failed", err))
+	err = query.Validate(testColumns...)
+	asst.NotNil(err, "test Validate() failed")
+
+	query, err = NewQueryWithValues(url.Values{"sort": {"owner_id"}})
+	asst.Nil(err, common.CombineMessageWithError("test Validate() failed", err))
+	err = query.Validate(testColumns...)
+	asst.NotNil(err, "test Validate() failed")
+}
+
+func TestQuery_GetWhereClause(t *testing.T) {
+	asst := assert.New(t)
+
+	query, err := NewQueryWithValues(url.Values{
+		"level":          {"1,2"},
+		"app_name_like":  {"a_p%"},
+		"create_time_ge": {"2021-01-01"},
+		"create_time_le": {"2021-02-01"},
+		"id":             {"3"},
+	})
+	asst.Nil(err, common.CombineMessageWithError("test GetWhereClause() failed", err))
+	where, args := query.GetWhereClause()
+	asst.Equal(" and app_name like ? and create_time <= ? and create_time >= ? and id = ? and level in (?, ?)", where, "test GetWhereClause() failed")
+	asst.Equal([]interface{}{`%a\_p\%%`, "2021-02-01", "2021-01-01", "3", "1", "2"}, args, "test GetWhereClause() failed")
+
+	where, args = NewQuery().GetWhereClause()
+	asst.Equal("", where, "test GetWhereClause() failed")
+	asst.Nil(args, "test GetWhereClause() failed")
+}
+
+func TestQuery_GetCursorClause(t *testing.T) {
+	asst := assert.New(t)
+
+	query, err := NewQueryWithValues(url.Values{"cursor": {"10"}, "limit": {"5"}})
+	asst.Nil(err, common.CombineMessageWithError("test GetCursorClause() failed", err))
+	clause, args := query.GetCursorClause()
+	asst.Equal(" and id > ?", clause, "test GetCursorClause() failed")
+	asst.Equal([]interface{}{10}, args, "test GetCursorClause() failed")
+
+	query, err = NewQueryWithValues(url.Values{"cursor": {"10"}, "sort": {"-id"}})
+	asst.Nil(err, common.CombineMessageWithError("test GetCursorClause() failed", err))
+	clause, _ = query.GetCursorClause()
+	asst.Equal(" and id < ?", clause, "test GetCursorClause() failed")
+}
+
+func TestQuery_GetOrderByClause(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Equal(" order by id", NewQuery().GetOrderByClause(), "test GetOrderByClause() failed")
+
+	query, err := NewQueryWithValues(url.Values{"sort": {"-level,app_name"}})
+	asst.Nil(err, common.CombineMessageWithError("test GetOrderByClause() failed", err))
+	asst.Equal(" order by level desc, app_name, id", query.GetOrderByClause(), "test GetOrderByClause() failed")
+
+	query, err = NewQueryWithValues(url.Values{"sort": {"-id"}})
+	asst.Nil(err, common.CombineMessageWithError("test GetOrderByClause() failed", err))
+	asst.Equal(" order by id desc", query.GetOrderByClause(), "test GetOrderByClause() failed")
+}
+
+func TestQuery_GetLimitClause(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Equal("", NewQuery().GetLimitClause(), "test GetLimitClause() failed")
+	asst.Equal(" limit 20", (&Query{Limit: 20}).GetLimitClause(), "test GetLimitClause() failed")
+	asst.Equal(" limit 20 offset 40", (&Query{Limit: 20, Offset: 40}).GetLimitClause(), "test GetLimitClause() failed")
+}
+
+func TestQuery_GetPage(t *testing.T) {
+	asst := assert.New(t)
+
+	query := &Query{Limit: 2}
+	page := query.GetPage(5, []int{3, 4})
+	asst.Equal(5, page.Total, "test GetPage() failed")
+	asst.Equal(4, page.NextCursor, "test GetPage() failed")
+
+	page = query.GetPage(5, []int{5})
+	asst.Equal(0, page.NextCursor, "test GetPage() failed")
+}
@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initServiceDebugMessage()
+	initServiceInfoMessage()
+	initServiceErrorMessage()
+}
+
+const (
+	// debug
+	DebugAuditGetAll      = 108001
+	DebugAuditGetByEntity = 108002
+
+	// info
+	InfoAuditGetAll      = 208001
+	InfoAuditGetByEntity = 208002
+
+	// error
+	ErrAuditGetAll      = 408001
+	ErrAuditGetByEntity = 408002
+)
+
+func initServiceDebugMessage() {
+	message.Messages[DebugAuditGetAll] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugAuditGetAll,
+		"audit: get all audit records message: %s")
+	message.Messages[DebugAuditGetByEntity] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugAuditGetByEntity,
+		"audit: get audit records by entity message: %s")
+}
+
+func initServiceInfoMessage() {
+	message.Messages[InfoAuditGetAll] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoAuditGetAll,
+		"audit: get all audit records completed")
+	message.Messages[InfoAuditGetByEntity] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoAuditGetByEntity,
+		"audit: get audit records by entity completed. entity type: %s, entity id: %d")
+}
+
+func initServiceErrorMessage() {
+	message.Messages[ErrAuditGetAll] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrAuditGetAll,
+		"audit: get all audit records failed.\n%s")
+	message.Messages[ErrAuditGetByEntity] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrAuditGetByEntity,
+		"audit: get audit records by entity failed. entity type: %s, entity id: %d\n%s")
+}
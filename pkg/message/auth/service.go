@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initServiceDebugMessage()
+	initServiceInfoMessage()
+	initServiceErrorMessage()
+	initServiceHTTPStatus()
+}
+
+const (
+	// debug
+	DebugAuthLogin       = 109001
+	DebugAuthCreateToken = 109002
+	DebugAuthGetTokens   = 109003
+	DebugAuthRevokeToken = 109004
+
+	// info
+	InfoAuthLogin       = 209001
+	InfoAuthCreateToken = 209002
+	InfoAuthGetTokens   = 209003
+	InfoAuthRevokeToken = 209004
+
+	// error
+	ErrAuthLogin           = 409001
+	ErrAuthCreateToken     = 409002
+	ErrAuthGetTokens       = 409003
+	ErrAuthRevokeToken     = 409004
+	ErrAuthUnauthenticated = 409005
+	ErrAuthNotEnabled      = 409006
+	ErrAuthForbidden       = 409007
+	ErrAuthAuthorize       = 409008
+)
+
+func initServiceDebugMessage() {
+	message.Messages[DebugAuthLogin] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugAuthLogin,
+		"auth: issued json web token by login. account name: %s")
+	message.Messages[DebugAuthCreateToken] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugAuthCreateToken,
+		"auth: issued api token. account name: %s")
+	message.Messages[DebugAuthGetTokens] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugAuthGetTokens,
+		"auth: get tokens message: %s")
+	message.Messages[DebugAuthRevokeToken] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugAuthRevokeToken,
+		"auth: revoke token message: %s")
+}
+
+func initServiceInfoMessage() {
+	message.Messages[InfoAuthLogin] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoAuthLogin,
+		"auth: login completed. account name: %s")
+	message.Messages[InfoAuthCreateToken] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoAuthCreateToken,
+		"auth: create token completed. account name: %s")
+	message.Messages[InfoAuthGetTokens] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoAuthGetTokens,
+		"auth: get tokens completed. account name: %s")
+	message.Messages[InfoAuthRevokeToken] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoAuthRevokeToken,
+		"auth: revoke token completed. account name: %s, id: %d")
+}
+
+func initServiceErrorMessage() {
+	message.Messages[ErrAuthLogin] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrAuthLogin,
+		"auth: login failed. account name: %s\n%s")
+	message.Messages[ErrAuthCreateToken] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrAuthCreateToken,
+		"auth: create token failed. account name: %s\n%s")
+	message.Messages[ErrAuthGetTokens] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrAuthGetTokens,
+		"auth: get tokens failed. account name: %s\n%s")
+	message.Messages[ErrAuthRevokeToken] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrAuthRevokeToken,
+		"auth: revoke token failed. account name: %s, id: %d\n%s")
+	message.Messages[ErrAuthUnauthenticated] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrAuthUnauthenticated,
+		"auth: authentication failed.\n%s")
+	message.Messages[ErrAuthNotEnabled] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrAuthNotEnabled,
+		"auth: authentication is disabled, tokens could only be managed by the authenticated users")
+	message.Messages[ErrAuthForbidden] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrAuthForbidden,
+		"auth: permission denied.\n%s")
+	message.Messages[ErrAuthAuthorize] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrAuthAuthorize,
+		"auth: authorize failed.\n%s")
+}
+
+func initServiceHTTPStatus() {
+	message.SetHTTPStatus(http.StatusUnauthorized, ErrAuthUnauthenticated)
+	message.SetHTTPStatus(http.StatusForbidden, ErrAuthForbidden)
+}
@@ -0,0 +1,53 @@
+package discovery
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initServiceDebugMessage()
+	initServiceInfoMessage()
+	initServiceErrorMessage()
+}
+
+const (
+	// debug
+	DebugDiscoveryDiscoverBySeed          = 105001
+	DebugDiscoveryDiscoverByMonitorSystem = 105002
+
+	// info
+	InfoDiscoveryDiscoverBySeed          = 205001
+	InfoDiscoveryDiscoverByMonitorSystem = 205002
+
+	// error
+	ErrDiscoveryDiscoverBySeed          = 405001
+	ErrDiscoveryDiscoverByMonitorSystem = 405002
+)
+
+func initServiceDebugMessage() {
+	message.Messages[DebugDiscoveryDiscoverBySeed] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugDiscoveryDiscoverBySeed,
+		"discovery: discover by seed message: %s")
+	message.Messages[DebugDiscoveryDiscoverByMonitorSystem] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugDiscoveryDiscoverByMonitorSystem,
+		"discovery: discover by monitor system message: %s")
+}
+
+func initServiceInfoMessage() {
+	message.Messages[InfoDiscoveryDiscoverBySeed] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoDiscoveryDiscoverBySeed,
+		"discovery: discover by seed completed. host ip: %s, port num: %d, dry run: %t")
+	message.Messages[InfoDiscoveryDiscoverByMonitorSystem] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoDiscoveryDiscoverByMonitorSystem,
+		"discovery: discover by monitor system completed. monitor system id: %d, dry run: %t")
+}
+
+func initServiceErrorMessage() {
+	message.Messages[ErrDiscoveryDiscoverBySeed] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrDiscoveryDiscoverBySeed,
+		"discovery: discover by seed failed. host ip: %s, port num: %d, dry run: %t, error: %s")
+	message.Messages[ErrDiscoveryDiscoverByMonitorSystem] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrDiscoveryDiscoverByMonitorSystem,
+		"discovery: discover by monitor system failed. monitor system id: %d, dry run: %t, error: %s")
+}
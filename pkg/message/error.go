@@ -5,61 +5,92 @@ import (
 )
 
 const (
-	ErrPrintHelpInfo                    = 400001
-	ErrEmptyLogFileName                 = 400002
-	ErrNotValidLogFileName              = 400003
-	ErrNotValidLogLevel                 = 400004
-	ErrNotValidLogFormat                = 400005
-	ErrNotValidLogMaxSize               = 400006
-	ErrNotValidLogMaxDays               = 400007
-	ErrNotValidLogMaxBackups            = 400008
-	ErrNotValidServerPort               = 400009
-	ErrNotValidPidFile                  = 400010
-	ErrValidateConfig                   = 400011
-	ErrInitDefaultConfig                = 400012
-	ErrReadConfigFile                   = 400013
-	ErrOverrideCommandLineArgs          = 400014
-	ErrAbsoluteLogFilePath              = 400015
-	ErrInitLogger                       = 400016
-	ErrBaseDir                          = 400017
-	ErrInitConfig                       = 400018
-	ErrCheckServerPid                   = 400019
-	ErrCheckServerRunningStatus         = 400020
-	ErrServerIsRunning                  = 400021
-	ErrStartAsForeground                = 400022
-	ErrSavePidToFile                    = 400023
-	ErrKillServerWithPid                = 400024
-	ErrKillServerWithPidFile            = 400025
-	ErrGetPidFromPidFile                = 400026
-	ErrSetSid                           = 400027
-	ErrRemovePidFile                    = 400028
-	ErrNotValidDBAddr                   = 400029
-	ErrNotValidDBName                   = 400030
-	ErrNotValidDBUser                   = 400031
-	ErrNotValidDBPass                   = 400032
-	ErrNotValidDBPoolMaxConnections     = 400033
-	ErrNotValidDBPoolInitConnections    = 400034
-	ErrNotValidDBPoolMaxIdleConnections = 400035
-	ErrNotValidDBPoolMaxIdleTime        = 400036
-	ErrNotValidDBPoolKeepAliveInterval  = 400037
-	ErrInitConnectionPool               = 400038
-	ErrNotValidServerReadTimeout        = 400039
-	ErrNotValidServerWriteTimeout       = 400040
-	ErrNotValidServerAddr               = 400041
-	ErrFieldNotExists                   = 400042
-	ErrGetRawData                       = 400043
-	ErrUnmarshalRawData                 = 400044
-	ErrGenerateNewMapWithTag            = 400045
-	ErrMarshalData                      = 400046
-	ErrTypeConversion                   = 400047
-	ErrNotValidTimeLayout               = 400048
-	ErrNotValidTimeDuration             = 400049
-	ErrEmptySoarBin                     = 400050
-	ErrNotValidSoarBin                  = 400051
-	ErrEmptySoarConfig                  = 400052
-	ErrNotValidSoarConfig               = 400053
-	ErrEmptySoarBlacklist               = 400054
-	ErrNotValidSoarBlacklist            = 400055
+	ErrPrintHelpInfo                                 = 400001
+	ErrEmptyLogFileName                              = 400002
+	ErrNotValidLogFileName                           = 400003
+	ErrNotValidLogLevel                              = 400004
+	ErrNotValidLogFormat                             = 400005
+	ErrNotValidLogMaxSize                            = 400006
+	ErrNotValidLogMaxDays                            = 400007
+	ErrNotValidLogMaxBackups                         = 400008
+	ErrNotValidServerPort                            = 400009
+	ErrNotValidPidFile                               = 400010
+	ErrValidateConfig                                = 400011
+	ErrInitDefaultConfig                             = 400012
+	ErrReadConfigFile                                = 400013
+	ErrOverrideCommandLineArgs                       = 400014
+	ErrAbsoluteLogFilePath                           = 400015
+	ErrInitLogger                                    = 400016
+	ErrBaseDir                                       = 400017
+	ErrInitConfig                                    = 400018
+	ErrCheckServerPid                                = 400019
+	ErrCheckServerRunningStatus                      = 400020
+	ErrServerIsRunning                               = 400021
+	ErrStartAsForeground                             = 400022
+	ErrSavePidToFile                                 = 400023
+	ErrKillServerWithPid                             = 400024
+	ErrKillServerWithPidFile                         = 400025
+	ErrGetPidFromPidFile                             = 400026
+	ErrSetSid                                        = 400027
+	ErrRemovePidFile                                 = 400028
+	ErrNotValidDBAddr                                = 400029
+	ErrNotValidDBName                                = 400030
+	ErrNotValidDBUser                                = 400031
+	ErrNotValidDBPass                                = 400032
+	ErrNotValidDBPoolMaxConnections                  = 400033
+	ErrNotValidDBPoolInitConnections                 = 400034
+	ErrNotValidDBPoolMaxIdleConnections              = 400035
+	ErrNotValidDBPoolMaxIdleTime                     = 400036
+	ErrNotValidDBPoolKeepAliveInterval               = 400037
+	ErrInitConnectionPool                            = 400038
+	ErrNotValidServerReadTimeout                     = 400039
+	ErrNotValidServerWriteTimeout                    = 400040
+	ErrNotValidServerAddr                            = 400041
+	ErrFieldNotExists                                = 400042
+	ErrGetRawData                                    = 400043
+	ErrUnmarshalRawData                              = 400044
+	ErrGenerateNewMapWithTag                         = 400045
+	ErrMarshalData                                   = 400046
+	ErrTypeConversion                                = 400047
+	ErrNotValidTimeLayout                            = 400048
+	ErrNotValidTimeDuration                          = 400049
+	ErrEmptySoarBin                                  = 400050
+	ErrNotValidSoarBin                               = 400051
+	ErrEmptySoarConfig                               = 400052
+	ErrNotValidSoarConfig                            = 400053
+	ErrEmptySoarBlacklist                            = 400054
+	ErrNotValidSoarBlacklist                         = 400055
+	ErrNotValidDBApplicationMySQLMaxReplicationDelay = 400056
+	ErrNotValidSQLAdvisorAutoAdviceInterval          = 400057
+	ErrNotValidSQLAdvisorAutoAdviceTopNum            = 400058
+	ErrNotValidNotifySMTPAddr                        = 400059
+	ErrNotValidNotifySMTPFrom                        = 400060
+	ErrNotValidQueryParameter                        = 400061
+	ErrNotValidAuthToken                             = 400062
+	ErrNotValidAuthJWTAlgorithm                      = 400063
+	ErrNotValidAuthJWTSecret                         = 400064
+	ErrNotValidAuthJWTKeyFile                        = 400065
+	ErrNotValidAuthJWTExpiration                     = 400066
+	ErrNotValidAuthLDAPAddr                          = 400067
+	ErrNotValidAuthLDAPUserDN                        = 400068
+	ErrNotValidAuthLDAPTimeout                       = 400069
+	ErrNotValidRequest                               = 400070
+	ErrNotValidServerShutdownTimeout                 = 400071
+	ErrShutdownServer                                = 400072
+	ErrCloseConnectionPool                           = 400073
+	ErrGetServerReadiness                            = 400074
+	ErrNotValidTraceExporter                         = 400075
+	ErrNotValidTraceOTLPEndpoint                     = 400076
+	ErrNotValidTraceSampleRatio                      = 400077
+	ErrInitTracer                                    = 400078
+	ErrShutdownTracer                                = 400079
+	ErrNotValidServerRequestTimeout                  = 400080
+	ErrNotValidServerLongRequestTimeout              = 400081
+	ErrNotValidSQLAdvisorReviewOnlineEnvName         = 400082
+	ErrDataNotExists                                 = 400083
+	ErrDataConflict                                  = 400084
+	ErrDataNotMatch                                  = 400085
+	ErrNotValidServerWriteTimeoutWithRequestTimeout  = 400086
 )
 
 func initErrorMessage() {
@@ -118,4 +149,35 @@ func initErrorMessage() {
 	Messages[ErrNotValidSoarConfig] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidSoarConfig, "soar config path must be either unix or windows path format, %s is not valid")
 	Messages[ErrEmptySoarBlacklist] = config.NewErrMessage(DefaultMessageHeader, ErrEmptySoarBlacklist, "soar blacklist path could not be an empty string")
 	Messages[ErrNotValidSoarBlacklist] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidSoarBlacklist, "soar blacklist path must be either unix or windows path format, %s is not valid")
+	Messages[ErrNotValidDBApplicationMySQLMaxReplicationDelay] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidDBApplicationMySQLMaxReplicationDelay, "application mysql max replication delay must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidSQLAdvisorAutoAdviceInterval] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidSQLAdvisorAutoAdviceInterval, "sqladvisor auto advice interval must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidSQLAdvisorAutoAdviceTopNum] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidSQLAdvisorAutoAdviceTopNum, "sqladvisor auto advice top num must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidNotifySMTPAddr] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidNotifySMTPAddr, "smtp address must be formatted as host:port, %s is not valid")
+	Messages[ErrNotValidNotifySMTPFrom] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidNotifySMTPFrom, "smtp sender must be a valid email address, %s is not valid")
+	Messages[ErrNotValidQueryParameter] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidQueryParameter, "query parameter is not valid.\n%s")
+	Messages[ErrNotValidAuthToken] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidAuthToken, "auth token must be formatted as account_name:token and the token must contain at least %d characters, token of account %s is not valid")
+	Messages[ErrNotValidAuthJWTAlgorithm] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidAuthJWTAlgorithm, "jwt algorithm must be one of %v, %s is not valid")
+	Messages[ErrNotValidAuthJWTSecret] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidAuthJWTSecret, "jwt secret must contain at least %d characters")
+	Messages[ErrNotValidAuthJWTKeyFile] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidAuthJWTKeyFile, "jwt key file path must be either unix or windows path format, %s is not valid")
+	Messages[ErrNotValidAuthJWTExpiration] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidAuthJWTExpiration, "jwt expiration must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidAuthLDAPAddr] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidAuthLDAPAddr, "ldap address must be formatted as host:port, %s is not valid")
+	Messages[ErrNotValidAuthLDAPUserDN] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidAuthLDAPUserDN, "ldap user dn must contain exactly one %%s which will be replaced with the account name, %s is not valid")
+	Messages[ErrNotValidAuthLDAPTimeout] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidAuthLDAPTimeout, "ldap timeout must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidRequest] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidRequest, "request is not valid. %s")
+	Messages[ErrNotValidServerShutdownTimeout] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidServerShutdownTimeout, "server shutdown timeout must be between %d and %d, %d is not valid")
+	Messages[ErrShutdownServer] = config.NewErrMessage(DefaultMessageHeader, ErrShutdownServer, "shutdown http server failed, the running requests may be interrupted.\n%s")
+	Messages[ErrCloseConnectionPool] = config.NewErrMessage(DefaultMessageHeader, ErrCloseConnectionPool, "close connection pool failed.\n%s")
+	Messages[ErrGetServerReadiness] = config.NewErrMessage(DefaultMessageHeader, ErrGetServerReadiness, "get server readiness failed. url: %s\n%s")
+	Messages[ErrNotValidTraceExporter] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidTraceExporter, "trace exporter must be one of %v, %s is not valid")
+	Messages[ErrNotValidTraceOTLPEndpoint] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidTraceOTLPEndpoint, "trace otlp endpoint must be a valid url, such as http://127.0.0.1:4318/v1/traces, %s is not valid")
+	Messages[ErrNotValidTraceSampleRatio] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidTraceSampleRatio, "trace sample ratio must be between %.1f and %.1f, %f is not valid")
+	Messages[ErrInitTracer] = config.NewErrMessage(DefaultMessageHeader, ErrInitTracer, "init tracer failed.\n%s")
+	Messages[ErrShutdownTracer] = config.NewErrMessage(DefaultMessageHeader, ErrShutdownTracer, "shutdown tracer failed, the spans which have not been exported may be lost.\n%s")
+	Messages[ErrNotValidServerRequestTimeout] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidServerRequestTimeout, "server request timeout must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidServerLongRequestTimeout] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidServerLongRequestTimeout, "server long request timeout must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidSQLAdvisorReviewOnlineEnvName] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidSQLAdvisorReviewOnlineEnvName, "sqladvisor review online env name must not be empty")
+	Messages[ErrDataNotExists] = config.NewErrMessage(DefaultMessageHeader, ErrDataNotExists, "data does not exist.\n%s")
+	Messages[ErrDataConflict] = config.NewErrMessage(DefaultMessageHeader, ErrDataConflict, "data was modified by others or already exists, get it again and retry.\n%s")
+	Messages[ErrDataNotMatch] = config.NewErrMessage(DefaultMessageHeader, ErrDataNotMatch, "data does not match the If-Match header, get it again and retry.\n%s")
+	Messages[ErrNotValidServerWriteTimeoutWithRequestTimeout] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidServerWriteTimeoutWithRequestTimeout, "server write timeout must be 0, or not less than server request timeout %d and server long request timeout %d which must not be 0, %d is not valid")
 }
@@ -22,6 +22,7 @@ const (
 	InfoHealthcheckCheck                  = 201002
 	InfoHealthcheckCheckByHostInfo        = 201003
 	InfoHealthcheckReviewAccurate         = 201004
+	InfoHealthcheckInterruptOperation     = 201005
 	// error
 	ErrHealthcheckDefaultEngineRun       = 401013
 	ErrHealthcheckGetResultByOperationID = 401014
@@ -29,6 +30,7 @@ const (
 	ErrHealthcheckCheckByHostInfo        = 401016
 	ErrHealthcheckReviewAccurate         = 401017
 	ErrHealthcheckCloseConnection        = 401018
+	ErrHealthcheckInterruptOperation     = 401019
 )
 
 func initServiceDebugMessage() {
@@ -59,6 +61,9 @@ func initServiceInfoMessage() {
 	message.Messages[InfoHealthcheckReviewAccurate] = config.NewErrMessage(
 		message.DefaultMessageHeader, InfoHealthcheckReviewAccurate,
 		"healthcheck: review accurate completed. %s")
+	message.Messages[InfoHealthcheckInterruptOperation] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckInterruptOperation,
+		"healthcheck: operation is marked as interrupted because it is still running when the server shuts down. operation_id: %d")
 }
 
 func initServiceErrorMessage() {
@@ -77,5 +82,8 @@ func initServiceErrorMessage() {
 	message.Messages[ErrHealthcheckCloseConnection] = config.NewErrMessage(
 		message.DefaultMessageHeader, ErrHealthcheckCloseConnection,
 		"healthcheck: close middleware connection failed.\n%s")
+	message.Messages[ErrHealthcheckInterruptOperation] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckInterruptOperation,
+		"healthcheck: mark operation as interrupted failed. operation_id: %d\n%s")
 
 }
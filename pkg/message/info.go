@@ -10,6 +10,7 @@ const (
 	InfoServerStop       = 200002
 	InfoServerIsRunning  = 200003
 	InfoServerNotRunning = 200004
+	InfoServerShutdown   = 200005
 )
 
 func initInfoMessage() {
@@ -18,4 +19,5 @@ func initInfoMessage() {
 	Messages[InfoServerStop] = config.NewErrMessage(DefaultMessageHeader, InfoServerStop, "das stopped successfully. pid: %d, pid file: %s")
 	Messages[InfoServerIsRunning] = config.NewErrMessage(DefaultMessageHeader, InfoServerIsRunning, "das is running. pid: %d")
 	Messages[InfoServerNotRunning] = config.NewErrMessage(DefaultMessageHeader, InfoServerNotRunning, "das is not running. pid: %d")
+	Messages[InfoServerShutdown] = config.NewErrMessage(DefaultMessageHeader, InfoServerShutdown, "das is shutting down. signal: %s, shutdown timeout: %s")
 }
@@ -26,6 +26,7 @@ var Messages = map[int]*config.ErrMessage{}
 func init() {
 	initInfoMessage()
 	initErrorMessage()
+	initHTTPStatus()
 }
 
 // NewMessage returns *config.ErrMessage with specified values
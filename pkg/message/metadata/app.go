@@ -21,6 +21,7 @@ const (
 	DebugMetadataAddApp       = 100105
 	DebugMetadataUpdateApp    = 100106
 	DebugMetadataDeleteApp    = 100107
+	DebugMetadataUndeleteApp  = 100110
 	DebugMetadataAppAddDB     = 100108
 	DebugMetadataAppDeleteDB  = 100109
 	// info
@@ -31,6 +32,7 @@ const (
 	InfoMetadataAddApp       = 200105
 	InfoMetadataUpdateApp    = 200106
 	InfoMetadataDeleteApp    = 200107
+	InfoMetadataUndeleteApp  = 200110
 	InfoMetadataAppAddDB     = 200108
 	InfoMetadataAppDeleteDB  = 200109
 	// error
@@ -41,6 +43,7 @@ const (
 	ErrMetadataAddApp       = 400105
 	ErrMetadataUpdateApp    = 400106
 	ErrMetadataDeleteApp    = 400107
+	ErrMetadataUndeleteApp  = 400110
 	ErrMetadataAppAddDB     = 400108
 	ErrMetadataAppDeleteDB  = 400109
 )
@@ -53,6 +56,7 @@ func initDebugAppMessage() {
 	message.Messages[DebugMetadataAddApp] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataAddApp, "metadata: add new app completed. message: %s")
 	message.Messages[DebugMetadataUpdateApp] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUpdateApp, "metadata: update app completed. message: %s")
 	message.Messages[DebugMetadataDeleteApp] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataDeleteApp, "metadata: delete app completed. message: %s")
+	message.Messages[DebugMetadataUndeleteApp] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUndeleteApp, "metadata: undelete app completed. message: %s")
 	message.Messages[DebugMetadataAppAddDB] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataAppAddDB, "metadata: add map of app and database completed. message: %s")
 	message.Messages[DebugMetadataAppDeleteDB] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataAppDeleteDB, "metadata: delete map of app and database completed. message: %s")
 }
@@ -65,6 +69,7 @@ func initInfoAppMessage() {
 	message.Messages[InfoMetadataAddApp] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataAddApp, "metadata: add new app completed. app_name: %s")
 	message.Messages[InfoMetadataUpdateApp] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUpdateApp, "metadata: update app completed. id: %d")
 	message.Messages[InfoMetadataDeleteApp] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataDeleteApp, "metadata: delete app completed. id: %d")
+	message.Messages[InfoMetadataUndeleteApp] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUndeleteApp, "metadata: undelete app completed. id: %d")
 	message.Messages[InfoMetadataAppAddDB] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataAppAddDB, "metadata: add map of app and database completed. app_id: %d, db_id: %d")
 	message.Messages[InfoMetadataAppDeleteDB] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataAppDeleteDB, "metadata: delete map of app and database completed. app_id: %d, db_id: %d")
 }
@@ -77,6 +82,7 @@ func initErrorAppMessage() {
 	message.Messages[ErrMetadataAddApp] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataAddApp, "metadata: add new app failed. app_name: %s\n%s")
 	message.Messages[ErrMetadataUpdateApp] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUpdateApp, "metadata: update app failed. id: %d\n%s")
 	message.Messages[ErrMetadataDeleteApp] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataDeleteApp, "metadata: delete app failed. id: %d\n%s")
+	message.Messages[ErrMetadataUndeleteApp] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUndeleteApp, "metadata: undelete app failed. id: %d\n%s")
 	message.Messages[ErrMetadataAppAddDB] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataAppAddDB, "metadata: add map of app and database failed. id: %d\n%s")
 	message.Messages[ErrMetadataAppDeleteDB] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataAppDeleteDB, "metadata: delete map of app and database failed. id: %d\n%s")
 }
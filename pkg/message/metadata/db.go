@@ -22,6 +22,7 @@ const (
 	DebugMetadataAddDB                     = 100206
 	DebugMetadataUpdateDB                  = 100207
 	DebugMetadataDeleteDB                  = 100208
+	DebugMetadataUndeleteDB                = 100211
 	DebugMetadataDBAddApp                  = 100209
 	DebugMetadataDBDeleteApp               = 100210
 	// info
@@ -33,6 +34,7 @@ const (
 	InfoMetadataAddDB                     = 200206
 	InfoMetadataUpdateDB                  = 200207
 	InfoMetadataDeleteDB                  = 200208
+	InfoMetadataUndeleteDB                = 200211
 	InfoMetadataDBAddApp                  = 200209
 	InfoMetadataDBDeleteApp               = 200210
 	// error
@@ -44,6 +46,7 @@ const (
 	ErrMetadataAddDB                     = 400206
 	ErrMetadataUpdateDB                  = 400207
 	ErrMetadataDeleteDB                  = 400208
+	ErrMetadataUndeleteDB                = 400211
 	ErrMetadataDBAddApp                  = 400209
 	ErrMetadataDBDeleteApp               = 400210
 )
@@ -57,6 +60,7 @@ func initDebugDBMessage() {
 	message.Messages[DebugMetadataAddDB] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataAddDB, "metadata: add new database completed. message: %s")
 	message.Messages[DebugMetadataUpdateDB] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUpdateDB, "metadata: update database completed. message: %s")
 	message.Messages[DebugMetadataDeleteDB] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataDeleteDB, "metadata: delete database completed. message: %s")
+	message.Messages[DebugMetadataUndeleteDB] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUndeleteDB, "metadata: undelete database completed. message: %s")
 	message.Messages[DebugMetadataDBAddApp] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataDBAddApp, "metadata: add map of database and app completed. message: %s")
 	message.Messages[DebugMetadataDBDeleteApp] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataDBDeleteApp, "metadata: delete map of database and app completed. message: %s")
 }
@@ -70,6 +74,7 @@ func initInfoDBMessage() {
 	message.Messages[InfoMetadataAddDB] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataAddDB, "metadata: add new database completed. db_name: %s, cluster_id: %d, cluster_type: %d, env_id: %d")
 	message.Messages[InfoMetadataUpdateDB] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUpdateDB, "metadata: update database completed. id: %d")
 	message.Messages[InfoMetadataDeleteDB] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataDeleteDB, "metadata: delete database completed. id: %d")
+	message.Messages[InfoMetadataUndeleteDB] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUndeleteDB, "metadata: undelete database completed. id: %d")
 	message.Messages[InfoMetadataDBAddApp] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataDBAddApp, "metadata: add map of database and app completed. db_id: %d, app_id: %d")
 	message.Messages[InfoMetadataDBDeleteApp] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataDBDeleteApp, "metadata: delete map of database and app completed. db_id: %d, app_id: %d")
 }
@@ -83,6 +88,7 @@ func initErrorDBMessage() {
 	message.Messages[ErrMetadataAddDB] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataAddDB, "metadata: add new databases failed. db_name: %s, cluster_id: %d, cluster_type: %d, env_id: %d\n%s")
 	message.Messages[ErrMetadataUpdateDB] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUpdateDB, "metadata: update database failed. id: %d\n%s")
 	message.Messages[ErrMetadataDeleteDB] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataDeleteDB, "metadata: delete database failed. id: %d\n%s")
+	message.Messages[ErrMetadataUndeleteDB] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUndeleteDB, "metadata: undelete database failed. id: %d\n%s")
 	message.Messages[ErrMetadataDBAddApp] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataDBAddApp, "metadata: add map of database and app failed. id: %d\n%s")
 	message.Messages[ErrMetadataDBDeleteApp] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataDBDeleteApp, "metadata: delete map of database and app failed. id: %d\n%s")
 }
@@ -13,26 +13,29 @@ func init() {
 
 const (
 	//debug
-	DebugMetadataGetEnvAll     = 100301
-	DebugMetadataGetEnvByID    = 100302
-	DebugMetadataAddEnv        = 100303
-	DebugMetadataUpdateEnv     = 100304
-	DebugMetadataGetEnvByName  = 100305
-	DebugMetadataDeleteEnvByID = 100306
+	DebugMetadataGetEnvAll       = 100301
+	DebugMetadataGetEnvByID      = 100302
+	DebugMetadataAddEnv          = 100303
+	DebugMetadataUpdateEnv       = 100304
+	DebugMetadataGetEnvByName    = 100305
+	DebugMetadataDeleteEnvByID   = 100306
+	DebugMetadataUndeleteEnvByID = 100307
 	//error
-	ErrMetadataGetEnvAll     = 400301
-	ErrMetadataGetEnvByID    = 400302
-	ErrMetadataAddEnv        = 400303
-	ErrMetadataUpdateEnv     = 400304
-	ErrMetadataGetEnvByName  = 400305
-	ErrMetadataDeleteEnvByID = 400306
+	ErrMetadataGetEnvAll       = 400301
+	ErrMetadataGetEnvByID      = 400302
+	ErrMetadataAddEnv          = 400303
+	ErrMetadataUpdateEnv       = 400304
+	ErrMetadataGetEnvByName    = 400305
+	ErrMetadataDeleteEnvByID   = 400306
+	ErrMetadataUndeleteEnvByID = 400307
 	//info
-	InfoMetadataGetEnvAll     = 200301
-	InfoMetadataGetEnvByID    = 200302
-	InfoMetadataAddEnv        = 200303
-	InfoMetadataUpdateEnv     = 200304
-	InfoMetadataGetEnvByName  = 200305
-	InfoMetadataDeleteEnvByID = 200306
+	InfoMetadataGetEnvAll       = 200301
+	InfoMetadataGetEnvByID      = 200302
+	InfoMetadataAddEnv          = 200303
+	InfoMetadataUpdateEnv       = 200304
+	InfoMetadataGetEnvByName    = 200305
+	InfoMetadataDeleteEnvByID   = 200306
+	InfoMetadataUndeleteEnvByID = 200307
 )
 
 func initDebugEnvMessage() {
@@ -43,6 +46,7 @@ func initDebugEnvMessage() {
 	message.Messages[DebugMetadataUpdateEnv] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUpdateEnv, "metadata: update environment message: %s")
 	message.Messages[DebugMetadataGetEnvByName] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataGetEnvByName, "metadata: get environment by name message: %s")
 	message.Messages[DebugMetadataDeleteEnvByID] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataDeleteEnvByID, "metadata: delete environment by ID message: %s")
+	message.Messages[DebugMetadataUndeleteEnvByID] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUndeleteEnvByID, "metadata: undelete environment completed. message: %s")
 }
 
 func initErrorEnvMessage() {
@@ -52,6 +56,7 @@ func initErrorEnvMessage() {
 	message.Messages[ErrMetadataUpdateEnv] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUpdateEnv, "metadata: update environment failed. id: %d\n%s")
 	message.Messages[ErrMetadataGetEnvByName] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataGetEnvByName, "metadata: get environment by name failed. id: %d\n%s")
 	message.Messages[ErrMetadataDeleteEnvByID] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataDeleteEnvByID, "metadata: delete environment by ID failed. id: %d\n%s")
+	message.Messages[ErrMetadataUndeleteEnvByID] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUndeleteEnvByID, "metadata: undelete environment failed. id: %d\n%s")
 }
 
 func initInfoEnvMessage() {
@@ -61,4 +66,5 @@ func initInfoEnvMessage() {
 	message.Messages[InfoMetadataUpdateEnv] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUpdateEnv, "metadata: update environment completed. id: %d")
 	message.Messages[InfoMetadataGetEnvByName] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataGetEnvByName, "metadata: get environment by name completed. id: %d")
 	message.Messages[InfoMetadataDeleteEnvByID] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataDeleteEnvByID, "metadata: delete environment by ID completed. id: %d")
+	message.Messages[InfoMetadataUndeleteEnvByID] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUndeleteEnvByID, "metadata: undelete environment completed. id: %d")
 }
@@ -0,0 +1,45 @@
+package metadata
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initDebugInventoryMessage()
+	initInfoInventoryMessage()
+	initErrorInventoryMessage()
+}
+
+const (
+	// debug
+	DebugMetadataImport    = 101201
+	DebugMetadataExport    = 101202
+	DebugMetadataReconcile = 101203
+	// info
+	InfoMetadataImport    = 201201
+	InfoMetadataExport    = 201202
+	InfoMetadataReconcile = 201203
+	// error
+	ErrMetadataImport    = 401201
+	ErrMetadataExport    = 401202
+	ErrMetadataReconcile = 401203
+)
+
+func initDebugInventoryMessage() {
+	message.Messages[DebugMetadataImport] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataImport, "metadata: import inventory message: %s")
+	message.Messages[DebugMetadataExport] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataExport, "metadata: export inventory message: %s")
+	message.Messages[DebugMetadataReconcile] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataReconcile, "metadata: reconcile inventory message: %s")
+}
+
+func initInfoInventoryMessage() {
+	message.Messages[InfoMetadataImport] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataImport, "metadata: import inventory completed. format: %s, kind: %s, dry run: %t")
+	message.Messages[InfoMetadataExport] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataExport, "metadata: export inventory completed. format: %s, kind: %s")
+	message.Messages[InfoMetadataReconcile] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataReconcile, "metadata: reconcile inventory completed. format: %s, kind: %s, prune: %t, dry run: %t")
+}
+
+func initErrorInventoryMessage() {
+	message.Messages[ErrMetadataImport] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataImport, "metadata: import inventory failed. format: %s, kind: %s, dry run: %t\n%s")
+	message.Messages[ErrMetadataExport] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataExport, "metadata: export inventory failed. format: %s, kind: %s\n%s")
+	message.Messages[ErrMetadataReconcile] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataReconcile, "metadata: reconcile inventory failed. format: %s, kind: %s, prune: %t, dry run: %t\n%s")
+}
@@ -22,6 +22,7 @@ const (
 	DebugMetadataAddMiddlewareCluster       = 100406
 	DebugMetadataUpdateMiddlewareCluster    = 100407
 	DebugMetadataDeleteMiddlewareCluster    = 100408
+	DebugMetadataUndeleteMiddlewareCluster  = 100409
 	// info
 	InfoMetadataGetMiddlewareClusterAll    = 200401
 	InfoMetadataGetMiddlewareClusterByEnv  = 200402
@@ -31,6 +32,7 @@ const (
 	InfoMetadataAddMiddlewareCluster       = 200406
 	InfoMetadataUpdateMiddlewareCluster    = 200407
 	InfoMetadataDeleteMiddlewareCluster    = 200408
+	InfoMetadataUndeleteMiddlewareCluster  = 200409
 	// error
 	ErrMetadataGetMiddlewareClusterAll    = 400401
 	ErrMetadataGetMiddlewareClusterByEnv  = 400402
@@ -40,6 +42,7 @@ const (
 	ErrMetadataAddMiddlewareCluster       = 400406
 	ErrMetadataUpdateMiddlewareCluster    = 400407
 	ErrMetadataDeleteMiddlewareCluster    = 400408
+	ErrMetadataUndeleteMiddlewareCluster  = 400409
 )
 
 func initDebugMiddlewareClusterMessage() {
@@ -51,6 +54,7 @@ func initDebugMiddlewareClusterMessage() {
 	message.Messages[DebugMetadataAddMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataAddMiddlewareCluster, "metadata: add new middleware cluster message: %s")
 	message.Messages[DebugMetadataUpdateMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUpdateMiddlewareCluster, "metadata: update middleware cluster message: %s")
 	message.Messages[DebugMetadataDeleteMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataDeleteMiddlewareCluster, "metadata: delete middleware cluster completed. message: %s")
+	message.Messages[DebugMetadataUndeleteMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUndeleteMiddlewareCluster, "metadata: undelete middleware cluster completed. message: %s")
 }
 
 func initInfoMiddlewareClusteMessage() {
@@ -62,6 +66,7 @@ func initInfoMiddlewareClusteMessage() {
 	message.Messages[InfoMetadataAddMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataAddMiddlewareCluster, "metadata: add new middleware cluster completed. cluster_name: %s")
 	message.Messages[InfoMetadataUpdateMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUpdateMiddlewareCluster, "metadata: update middleware cluster completed. id: %d")
 	message.Messages[InfoMetadataDeleteMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataDeleteMiddlewareCluster, "metadata: delete middleware cluster completed. cluster_name: %s")
+	message.Messages[InfoMetadataUndeleteMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUndeleteMiddlewareCluster, "metadata: undelete middleware cluster completed. id: %d")
 }
 
 func initErrorMiddlewareClusteMessage() {
@@ -73,4 +78,5 @@ func initErrorMiddlewareClusteMessage() {
 	message.Messages[ErrMetadataAddMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataAddMiddlewareCluster, "metadata: add new middleware cluster failed. env_name: %s\n%s")
 	message.Messages[ErrMetadataUpdateMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUpdateMiddlewareCluster, "metadata: update middleware cluster failed. id: %d\n%s")
 	message.Messages[ErrMetadataDeleteMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataDeleteMiddlewareCluster, "metadata: delete middleware cluster failed. cluster_name: %s\n%s")
+	message.Messages[ErrMetadataUndeleteMiddlewareCluster] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUndeleteMiddlewareCluster, "metadata: undelete middleware cluster failed. id: %d\n%s")
 }
@@ -20,6 +20,7 @@ const (
 	DebugMetadataAddMiddlewareServer           = 100505
 	DebugMetadataUpdateMiddlewareServer        = 100506
 	DebugMetadataDeleteMiddlewareServer        = 100507
+	DebugMetadataUndeleteMiddlewareServer      = 100508
 
 	// info
 	InfoMetadataGetMiddlewareServerAll        = 200501
@@ -29,6 +30,7 @@ const (
 	InfoMetadataAddMiddlewareServer           = 200505
 	InfoMetadataUpdateMiddlewareServer        = 200506
 	InfoMetadataDeleteMiddlewareServer        = 200507
+	InfoMetadataUndeleteMiddlewareServer      = 200508
 	// error
 	ErrMetadataGetMiddlewareServerAll        = 400501
 	ErrMetadataGetMiddlewareSeverByClusterID = 400502
@@ -37,6 +39,7 @@ const (
 	ErrMetadataAddMiddlewareServer           = 400505
 	ErrMetadataUpdateMiddlewareServer        = 400506
 	ErrMetadataDeleteMiddlewareServer        = 400507
+	ErrMetadataUndeleteMiddlewareServer      = 400508
 )
 
 func initDebugMiddlewareServerMessage() {
@@ -47,6 +50,7 @@ func initDebugMiddlewareServerMessage() {
 	message.Messages[DebugMetadataAddMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataAddMiddlewareServer, "metadata: add new middleware server message: %s")
 	message.Messages[DebugMetadataUpdateMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUpdateMiddlewareServer, "metadata: update middleware server message: %s")
 	message.Messages[DebugMetadataDeleteMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataDeleteMiddlewareServer, "metadata: delete middleware server completed. message: %s")
+	message.Messages[DebugMetadataUndeleteMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUndeleteMiddlewareServer, "metadata: undelete middleware server completed. message: %s")
 }
 
 func initInfoMiddlewareServerMessage() {
@@ -57,6 +61,7 @@ func initInfoMiddlewareServerMessage() {
 	message.Messages[InfoMetadataAddMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataAddMiddlewareServer, "metadata: add new middleware server completed. server_name: %s")
 	message.Messages[InfoMetadataUpdateMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUpdateMiddlewareServer, "metadata: update middleware server completed. id: %d")
 	message.Messages[InfoMetadataDeleteMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataDeleteMiddlewareServer, "metadata: delete middleware server completed. server_name: %s")
+	message.Messages[InfoMetadataUndeleteMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUndeleteMiddlewareServer, "metadata: undelete middleware server completed. id: %d")
 }
 
 func initErrorMiddlewareServerMessage() {
@@ -67,4 +72,5 @@ func initErrorMiddlewareServerMessage() {
 	message.Messages[ErrMetadataAddMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataAddMiddlewareServer, "metadata: add new middleware server failed. server_name: %s\n%s")
 	message.Messages[ErrMetadataUpdateMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUpdateMiddlewareServer, "metadata: update middleware server failed. id: %d\n%s")
 	message.Messages[ErrMetadataDeleteMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataDeleteMiddlewareServer, "metadata: delete middleware server failed. server_name: %s\n%s")
+	message.Messages[ErrMetadataUndeleteMiddlewareServer] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUndeleteMiddlewareServer, "metadata: undelete middleware server failed. id: %d\n%s")
 }
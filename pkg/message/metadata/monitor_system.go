@@ -21,6 +21,7 @@ const (
 	DebugMetadataAddMonitorSystem           = 100605
 	DebugMetadataUpdateMonitorSystem        = 100606
 	DebugMetadataDeleteMonitorSystem        = 100607
+	DebugMetadataUndeleteMonitorSystem      = 100608
 	// info
 	InfoMetadataGetMonitorSystemAll        = 200601
 	InfoMetadataGetMonitorSystemByEnv      = 200602
@@ -29,6 +30,7 @@ const (
 	InfoMetadataAddMonitorSystem           = 200605
 	InfoMetadataUpdateMonitorSystem        = 200606
 	InfoMetadataDeleteMonitorSystem        = 200607
+	InfoMetadataUndeleteMonitorSystem      = 200608
 	// error
 	ErrMetadataGetMonitorSystemAll        = 400601
 	ErrMetadataGetMonitorSystemByEnv      = 400602
@@ -37,6 +39,7 @@ const (
 	ErrMetadataAddMonitorSystem           = 400605
 	ErrMetadataUpdateMonitorSystem        = 400606
 	ErrMetadataDeleteMonitorSystem        = 400607
+	ErrMetadataUndeleteMonitorSystem      = 400608
 )
 
 func initDebugMonitorSystemMessage() {
@@ -47,6 +50,7 @@ func initDebugMonitorSystemMessage() {
 	message.Messages[DebugMetadataAddMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataAddMonitorSystem, "metadata: add new monitor system completed. message: %s")
 	message.Messages[DebugMetadataUpdateMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUpdateMonitorSystem, "metadata: update monitor system completed. message: %s")
 	message.Messages[DebugMetadataDeleteMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataDeleteMonitorSystem, "metadata: delete monitor system completed. message: %s")
+	message.Messages[DebugMetadataUndeleteMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUndeleteMonitorSystem, "metadata: undelete monitor system completed. message: %s")
 }
 
 func initInfoMonitorSystemMessage() {
@@ -57,6 +61,7 @@ func initInfoMonitorSystemMessage() {
 	message.Messages[InfoMetadataAddMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataAddMonitorSystem, "metadata: add new monitor system completed. system_name: %s, system_type: %d, host_ip: %s, port_num: %d, port_num_slow: %d, base_url: %s, env_id: %d")
 	message.Messages[InfoMetadataUpdateMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUpdateMonitorSystem, "metadata: update monitor system completed. id: %d")
 	message.Messages[InfoMetadataDeleteMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataDeleteMonitorSystem, "metadata: delete monitor system completed. id: %d")
+	message.Messages[InfoMetadataUndeleteMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUndeleteMonitorSystem, "metadata: undelete monitor system completed. id: %d")
 }
 
 func initErrorMonitorSystemMessage() {
@@ -67,4 +72,5 @@ func initErrorMonitorSystemMessage() {
 	message.Messages[ErrMetadataAddMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataAddMonitorSystem, "metadata: add new monitor system failed. system_name: %s, system_type: %d, host_ip: %s, port_num: %d, port_num_slow: %d, base_url: %s, env_id: %d\n%s")
 	message.Messages[ErrMetadataUpdateMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUpdateMonitorSystem, "metadata: update monitor system failed. id: %d\n%s")
 	message.Messages[ErrMetadataDeleteMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataDeleteMonitorSystem, "metadata: delete monitor system failed. id: %d\n%s")
+	message.Messages[ErrMetadataUndeleteMonitorSystem] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUndeleteMonitorSystem, "metadata: undelete monitor system failed. id: %d\n%s")
 }
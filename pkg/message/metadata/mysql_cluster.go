@@ -22,6 +22,7 @@ const (
 	DebugMetadataAddMySQLCluster       = 100706
 	DebugMetadataUpdateMySQLCluster    = 100707
 	DebugMetadataDeleteMySQLCluster    = 100708
+	DebugMetadataUndeleteMySQLCluster  = 100709
 	// debug
 	InfoMetadataGetMySQLClusterAll    = 200701
 	InfoMetadataGetMySQLClusterByEnv  = 200702
@@ -31,6 +32,7 @@ const (
 	InfoMetadataAddMySQLCluster       = 200706
 	InfoMetadataUpdateMySQLCluster    = 200707
 	InfoMetadataDeleteMySQLCluster    = 200708
+	InfoMetadataUndeleteMySQLCluster  = 200709
 	// error
 	ErrMetadataGetMySQLClusterAll    = 400701
 	ErrMetadataGetMySQLClusterByEnv  = 400702
@@ -40,6 +42,7 @@ const (
 	ErrMetadataAddMySQLCluster       = 400706
 	ErrMetadataUpdateMySQLCluster    = 400707
 	ErrMetadataDeleteMySQLCluster    = 400708
+	ErrMetadataUndeleteMySQLCluster  = 400709
 )
 
 func initDebugMySQLCLusterMessage() {
@@ -75,6 +78,10 @@ func initDebugMySQLCLusterMessage() {
 		message.DefaultMessageHeader,
 		DebugMetadataDeleteMySQLCluster,
 		"metadata: delete mysql cluster message: %s")
+	message.Messages[DebugMetadataUndeleteMySQLCluster] = config.NewErrMessage(
+		message.DefaultMessageHeader,
+		DebugMetadataUndeleteMySQLCluster,
+		"metadata: undelete mysql cluster completed. message: %s")
 }
 
 func initInfoMySQLCLusterMessage() {
@@ -110,6 +117,10 @@ func initInfoMySQLCLusterMessage() {
 		message.DefaultMessageHeader,
 		InfoMetadataDeleteMySQLCluster,
 		"metadata: delete mysql cluster completed. id: %s")
+	message.Messages[InfoMetadataUndeleteMySQLCluster] = config.NewErrMessage(
+		message.DefaultMessageHeader,
+		InfoMetadataUndeleteMySQLCluster,
+		"metadata: undelete mysql cluster completed. id: %d")
 }
 
 func initErrorMySQLCLusterMessage() {
@@ -145,4 +156,8 @@ func initErrorMySQLCLusterMessage() {
 		message.DefaultMessageHeader,
 		ErrMetadataDeleteMySQLCluster,
 		"metadata: delete mysql cluster failed. id: %d\n%s")
+	message.Messages[ErrMetadataUndeleteMySQLCluster] = config.NewErrMessage(
+		message.DefaultMessageHeader,
+		ErrMetadataUndeleteMySQLCluster,
+		"metadata: undelete mysql cluster failed. id: %d\n%s")
 }
@@ -21,6 +21,7 @@ const (
 	DebugMetadataAddMySQLServer            = 100805
 	DebugMetadataUpdateMySQLServer         = 100806
 	DebugMetadataDeleteMySQLServer         = 100807
+	DebugMetadataUndeleteMySQLServer       = 100808
 )
 const (
 	// info
@@ -31,6 +32,7 @@ const (
 	InfoMetadataAddMySQLServer            = 200805
 	InfoMetadataUpdateMySQLServer         = 200806
 	InfoMetadataDeleteMySQLServer         = 200807
+	InfoMetadataUndeleteMySQLServer       = 200808
 )
 const (
 	// error
@@ -41,6 +43,7 @@ const (
 	ErrMetadataAddMySQLServer            = 400805
 	ErrMetadataUpdateMySQLServer         = 400806
 	ErrMetadataDeleteMySQLServer         = 400807
+	ErrMetadataUndeleteMySQLServer       = 400808
 )
 
 func initDebugMySQLServerMessage() {
@@ -72,6 +75,10 @@ func initDebugMySQLServerMessage() {
 		message.DefaultMessageHeader,
 		DebugMetadataDeleteMySQLServer,
 		"metadata: delete mysql server message: %s")
+	message.Messages[DebugMetadataUndeleteMySQLServer] = config.NewErrMessage(
+		message.DefaultMessageHeader,
+		DebugMetadataUndeleteMySQLServer,
+		"metadata: undelete mysql server completed. message: %s")
 }
 
 func initInfoMySQLServerMessage() {
@@ -103,6 +110,10 @@ func initInfoMySQLServerMessage() {
 		message.DefaultMessageHeader,
 		InfoMetadataDeleteMySQLServer,
 		"metadata: delete mysql server completed. id: %d")
+	message.Messages[InfoMetadataUndeleteMySQLServer] = config.NewErrMessage(
+		message.DefaultMessageHeader,
+		InfoMetadataUndeleteMySQLServer,
+		"metadata: undelete mysql server completed. id: %d")
 }
 
 func initErrorMySQLServerMessage() {
@@ -134,4 +145,8 @@ func initErrorMySQLServerMessage() {
 		message.DefaultMessageHeader,
 		ErrMetadataDeleteMySQLServer,
 		"metadata: delete mysql server failed. id: %s\n%s")
+	message.Messages[ErrMetadataUndeleteMySQLServer] = config.NewErrMessage(
+		message.DefaultMessageHeader,
+		ErrMetadataUndeleteMySQLServer,
+		"metadata: undelete mysql server failed. id: %d\n%s")
 }
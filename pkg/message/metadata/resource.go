@@ -0,0 +1,94 @@
+package metadata
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initDebugResourceMessage()
+	initInfoResourceMessage()
+	initErrorResourceMessage()
+}
+
+// the messages of the resource oriented api, the first argument is the plural name of the resource, such as apps
+const (
+	//debug
+	DebugMetadataGetResources          = 101101
+	DebugMetadataGetResource           = 101102
+	DebugMetadataCreateResource        = 101103
+	DebugMetadataReplaceResource       = 101104
+	DebugMetadataPatchResource         = 101105
+	DebugMetadataDeleteResource        = 101106
+	DebugMetadataUndeleteResource      = 101107
+	DebugMetadataGetRelatedResources   = 101108
+	DebugMetadataAddRelatedResource    = 101109
+	DebugMetadataDeleteRelatedResource = 101110
+	DebugMetadataGetOpenAPI            = 101111
+	//info
+	InfoMetadataGetResources          = 201101
+	InfoMetadataGetResource           = 201102
+	InfoMetadataCreateResource        = 201103
+	InfoMetadataReplaceResource       = 201104
+	InfoMetadataPatchResource         = 201105
+	InfoMetadataDeleteResource        = 201106
+	InfoMetadataUndeleteResource      = 201107
+	InfoMetadataGetRelatedResources   = 201108
+	InfoMetadataAddRelatedResource    = 201109
+	InfoMetadataDeleteRelatedResource = 201110
+	InfoMetadataGetOpenAPI            = 201111
+	//error
+	ErrMetadataGetResources          = 401101
+	ErrMetadataGetResource           = 401102
+	ErrMetadataCreateResource        = 401103
+	ErrMetadataReplaceResource       = 401104
+	ErrMetadataPatchResource         = 401105
+	ErrMetadataDeleteResource        = 401106
+	ErrMetadataUndeleteResource      = 401107
+	ErrMetadataGetRelatedResources   = 401108
+	ErrMetadataAddRelatedResource    = 401109
+	ErrMetadataDeleteRelatedResource = 401110
+	ErrMetadataGetOpenAPI            = 401111
+)
+
+func initDebugResourceMessage() {
+	message.Messages[DebugMetadataGetResources] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataGetResources, "metadata: get %s message: %s")
+	message.Messages[DebugMetadataGetResource] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataGetResource, "metadata: get %s by id message: %s")
+	message.Messages[DebugMetadataCreateResource] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataCreateResource, "metadata: create %s message: %s")
+	message.Messages[DebugMetadataReplaceResource] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataReplaceResource, "metadata: replace %s message: %s")
+	message.Messages[DebugMetadataPatchResource] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataPatchResource, "metadata: patch %s message: %s")
+	message.Messages[DebugMetadataDeleteResource] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataDeleteResource, "metadata: delete %s message: %s")
+	message.Messages[DebugMetadataUndeleteResource] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUndeleteResource, "metadata: undelete %s message: %s")
+	message.Messages[DebugMetadataGetRelatedResources] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataGetRelatedResources, "metadata: get %s of %s message: %s")
+	message.Messages[DebugMetadataAddRelatedResource] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataAddRelatedResource, "metadata: add %s to %s message: %s")
+	message.Messages[DebugMetadataDeleteRelatedResource] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataDeleteRelatedResource, "metadata: delete %s from %s message: %s")
+	message.Messages[DebugMetadataGetOpenAPI] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataGetOpenAPI, "metadata: get openapi specification message: %s")
+}
+
+func initInfoResourceMessage() {
+	message.Messages[InfoMetadataGetResources] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataGetResources, "metadata: get %s completed")
+	message.Messages[InfoMetadataGetResource] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataGetResource, "metadata: get %s by id completed. id: %d")
+	message.Messages[InfoMetadataCreateResource] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataCreateResource, "metadata: create %s completed")
+	message.Messages[InfoMetadataReplaceResource] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataReplaceResource, "metadata: replace %s completed. id: %d")
+	message.Messages[InfoMetadataPatchResource] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataPatchResource, "metadata: patch %s completed. id: %d")
+	message.Messages[InfoMetadataDeleteResource] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataDeleteResource, "metadata: delete %s completed. id: %d")
+	message.Messages[InfoMetadataUndeleteResource] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUndeleteResource, "metadata: undelete %s completed. id: %d")
+	message.Messages[InfoMetadataGetRelatedResources] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataGetRelatedResources, "metadata: get %s of %s completed. id: %d")
+	message.Messages[InfoMetadataAddRelatedResource] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataAddRelatedResource, "metadata: add %s to %s completed. id: %d, related id: %d")
+	message.Messages[InfoMetadataDeleteRelatedResource] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataDeleteRelatedResource, "metadata: delete %s from %s completed. id: %d, related id: %d")
+	message.Messages[InfoMetadataGetOpenAPI] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataGetOpenAPI, "metadata: get openapi specification completed")
+}
+
+func initErrorResourceMessage() {
+	message.Messages[ErrMetadataGetResources] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataGetResources, "metadata: get %s failed.\n%s")
+	message.Messages[ErrMetadataGetResource] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataGetResource, "metadata: get %s by id failed. id: %d\n%s")
+	message.Messages[ErrMetadataCreateResource] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataCreateResource, "metadata: create %s failed.\n%s")
+	message.Messages[ErrMetadataReplaceResource] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataReplaceResource, "metadata: replace %s failed. id: %d\n%s")
+	message.Messages[ErrMetadataPatchResource] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataPatchResource, "metadata: patch %s failed. id: %d\n%s")
+	message.Messages[ErrMetadataDeleteResource] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataDeleteResource, "metadata: delete %s failed. id: %d\n%s")
+	message.Messages[ErrMetadataUndeleteResource] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUndeleteResource, "metadata: undelete %s failed. id: %d\n%s")
+	message.Messages[ErrMetadataGetRelatedResources] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataGetRelatedResources, "metadata: get %s of %s failed. id: %d\n%s")
+	message.Messages[ErrMetadataAddRelatedResource] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataAddRelatedResource, "metadata: add %s to %s failed. id: %d, related id: %d\n%s")
+	message.Messages[ErrMetadataDeleteRelatedResource] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataDeleteRelatedResource, "metadata: delete %s from %s failed. id: %d, related id: %d\n%s")
+	message.Messages[ErrMetadataGetOpenAPI] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataGetOpenAPI, "metadata: get openapi specification failed.\n%s")
+}
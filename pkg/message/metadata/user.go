@@ -13,41 +13,44 @@ func init() {
 
 const (
 	// debug
-	DebugMetadataGetUserAll     = 100901
-	DebugMetadataGetUserByID    = 100902
-	DebugMetadataAddUser        = 100903
-	DebugMetadataUpdateUser     = 100904
-	DebugMetadataGetUserByName  = 100905
-	DebugMetadataGetEmployeeID  = 100906
-	DebugMetadataGetAccountName = 100907
-	DebugMetadataGetEmail       = 100908
-	DebugMetadataGetTelephone   = 100909
-	DebugMetadataGetMobile      = 100910
-	DebugMetadataDeleteUserByID = 100911
+	DebugMetadataGetUserAll       = 100901
+	DebugMetadataGetUserByID      = 100902
+	DebugMetadataAddUser          = 100903
+	DebugMetadataUpdateUser       = 100904
+	DebugMetadataGetUserByName    = 100905
+	DebugMetadataGetEmployeeID    = 100906
+	DebugMetadataGetAccountName   = 100907
+	DebugMetadataGetEmail         = 100908
+	DebugMetadataGetTelephone     = 100909
+	DebugMetadataGetMobile        = 100910
+	DebugMetadataDeleteUserByID   = 100911
+	DebugMetadataUndeleteUserByID = 100912
 	// info
-	InfoMetadataGetUserAll     = 200901
-	InfoMetadataGetUserByID    = 200902
-	InfoMetadataAddUser        = 200903
-	InfoMetadataUpdateUser     = 200904
-	InfoMetadataGetUserByName  = 200905
-	InfoMetadataGetEmployeeID  = 200906
-	InfoMetadataGetAccountName = 200907
-	InfoMetadataGetEmail       = 200908
-	InfoMetadataGetTelephone   = 200909
-	InfoMetadataGetMobile      = 200910
-	InfoMetadataDeleteUserByID = 200911
+	InfoMetadataGetUserAll       = 200901
+	InfoMetadataGetUserByID      = 200902
+	InfoMetadataAddUser          = 200903
+	InfoMetadataUpdateUser       = 200904
+	InfoMetadataGetUserByName    = 200905
+	InfoMetadataGetEmployeeID    = 200906
+	InfoMetadataGetAccountName   = 200907
+	InfoMetadataGetEmail         = 200908
+	InfoMetadataGetTelephone     = 200909
+	InfoMetadataGetMobile        = 200910
+	InfoMetadataDeleteUserByID   = 200911
+	InfoMetadataUndeleteUserByID = 200912
 	// error
-	ErrMetadataGetUserAll     = 400901
-	ErrMetadataGetUserByID    = 400902
-	ErrMetadataAddUser        = 400903
-	ErrMetadataUpdateUser     = 400904
-	ErrMetadataGetUserByName  = 400905
-	ErrMetadataGetEmployeeID  = 400906
-	ErrMetadataGetAccountName = 400907
-	ErrMetadataGetEmail       = 400908
-	ErrMetadataGetTelephone   = 400909
-	ErrMetadataGetMobile      = 400910
-	ErrMetadataDeleteUserByID = 400911
+	ErrMetadataGetUserAll       = 400901
+	ErrMetadataGetUserByID      = 400902
+	ErrMetadataAddUser          = 400903
+	ErrMetadataUpdateUser       = 400904
+	ErrMetadataGetUserByName    = 400905
+	ErrMetadataGetEmployeeID    = 400906
+	ErrMetadataGetAccountName   = 400907
+	ErrMetadataGetEmail         = 400908
+	ErrMetadataGetTelephone     = 400909
+	ErrMetadataGetMobile        = 400910
+	ErrMetadataDeleteUserByID   = 400911
+	ErrMetadataUndeleteUserByID = 400912
 )
 
 func initDebugUserMessage() {
@@ -62,6 +65,7 @@ func initDebugUserMessage() {
 	message.Messages[DebugMetadataGetTelephone] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataGetTelephone, "metadata: get user by telephone message: %s")
 	message.Messages[DebugMetadataGetMobile] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataGetMobile, "metadata: get user by mobile message: %s")
 	message.Messages[DebugMetadataDeleteUserByID] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataDeleteUserByID, "metadata: delete user by ID message: %s")
+	message.Messages[DebugMetadataUndeleteUserByID] = config.NewErrMessage(message.DefaultMessageHeader, DebugMetadataUndeleteUserByID, "metadata: undelete user completed. message: %s")
 }
 
 func initInfoUserMessage() {
@@ -76,6 +80,7 @@ func initInfoUserMessage() {
 	message.Messages[InfoMetadataGetTelephone] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataGetTelephone, "metadata: get user by telephone completed.telephone: %s\n%s")
 	message.Messages[InfoMetadataGetMobile] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataGetMobile, "metadata: get user by mobile completed.mobile: %s\n%s")
 	message.Messages[InfoMetadataDeleteUserByID] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataDeleteUserByID, "metadata: delete user by ID completed. id: %d")
+	message.Messages[InfoMetadataUndeleteUserByID] = config.NewErrMessage(message.DefaultMessageHeader, InfoMetadataUndeleteUserByID, "metadata: undelete user completed. id: %d")
 }
 
 func initErrorUserMessage() {
@@ -90,4 +95,5 @@ func initErrorUserMessage() {
 	message.Messages[ErrMetadataGetTelephone] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataGetTelephone, "metadata: get user by telephone failed.telephone: %s\n%s")
 	message.Messages[ErrMetadataGetMobile] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataGetMobile, "metadata: get user by mobile failed.mobile: %s\n%s")
 	message.Messages[ErrMetadataDeleteUserByID] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataDeleteUserByID, "metadata: delete user by ID failed. id: %d\n%s")
+	message.Messages[ErrMetadataUndeleteUserByID] = config.NewErrMessage(message.DefaultMessageHeader, ErrMetadataUndeleteUserByID, "metadata: undelete user failed. id: %d\n%s")
 }
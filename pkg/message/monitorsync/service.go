@@ -0,0 +1,41 @@
+package monitorsync
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initServiceDebugMessage()
+	initServiceInfoMessage()
+	initServiceErrorMessage()
+}
+
+const (
+	// debug
+	DebugMonitorSyncSyncByMonitorSystem = 106001
+
+	// info
+	InfoMonitorSyncSyncByMonitorSystem = 206001
+
+	// error
+	ErrMonitorSyncSyncByMonitorSystem = 406001
+)
+
+func initServiceDebugMessage() {
+	message.Messages[DebugMonitorSyncSyncByMonitorSystem] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugMonitorSyncSyncByMonitorSystem,
+		"monitorsync: sync by monitor system message: %s")
+}
+
+func initServiceInfoMessage() {
+	message.Messages[InfoMonitorSyncSyncByMonitorSystem] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoMonitorSyncSyncByMonitorSystem,
+		"monitorsync: sync by monitor system completed. monitor system id: %d, create: %t")
+}
+
+func initServiceErrorMessage() {
+	message.Messages[ErrMonitorSyncSyncByMonitorSystem] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrMonitorSyncSyncByMonitorSystem,
+		"monitorsync: sync by monitor system failed. monitor system id: %d, create: %t, error: %s")
+}
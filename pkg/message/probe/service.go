@@ -0,0 +1,52 @@
+package probe
+
+import (
+	"net/http"
+
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initServiceDebugMessage()
+	initServiceInfoMessage()
+	initServiceErrorMessage()
+	initServiceHTTPStatus()
+}
+
+const (
+	// debug
+	DebugProbeReady = 110001
+
+	// info
+	InfoProbeLive  = 210001
+	InfoProbeReady = 210002
+
+	// error
+	ErrProbeNotReady = 410001
+)
+
+func initServiceDebugMessage() {
+	message.Messages[DebugProbeReady] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugProbeReady,
+		"probe: ready message: %s")
+}
+
+func initServiceInfoMessage() {
+	message.Messages[InfoProbeLive] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoProbeLive,
+		"probe: das is alive. pid: %d")
+	message.Messages[InfoProbeReady] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoProbeReady,
+		"probe: das is ready")
+}
+
+func initServiceErrorMessage() {
+	message.Messages[ErrProbeNotReady] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrProbeNotReady,
+		"probe: das is not ready. failed dependencies: %s")
+}
+
+func initServiceHTTPStatus() {
+	message.SetHTTPStatus(http.StatusServiceUnavailable, ErrProbeNotReady)
+}
@@ -0,0 +1,63 @@
+package query
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initQueryDebugMessage()
+	initQueryInfoMessage()
+	initQueryErrorMessage()
+}
+
+const (
+	// debug
+	DebugQueryGetTop        = 103001
+	DebugQueryGetTrend      = 103002
+	DebugQueryGetRegression = 103003
+	// info
+	InfoQueryGetTop        = 203001
+	InfoQueryGetTrend      = 203002
+	InfoQueryGetRegression = 203003
+	// error
+	ErrQueryGetTop        = 403001
+	ErrQueryGetTrend      = 403002
+	ErrQueryGetRegression = 403003
+)
+
+func initQueryDebugMessage() {
+	message.Messages[DebugQueryGetTop] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugQueryGetTop,
+		"query: get top slow queries message: %s")
+	message.Messages[DebugQueryGetTrend] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugQueryGetTrend,
+		"query: get slow query trend message: %s")
+	message.Messages[DebugQueryGetRegression] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugQueryGetRegression,
+		"query: get slow query regression message: %s")
+}
+
+func initQueryInfoMessage() {
+	message.Messages[InfoQueryGetTop] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoQueryGetTop,
+		"query: get top slow queries completed. server_id: %d")
+	message.Messages[InfoQueryGetTrend] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoQueryGetTrend,
+		"query: get slow query trend completed. server_id: %d, sql_id: %s")
+	message.Messages[InfoQueryGetRegression] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoQueryGetRegression,
+		"query: get slow query regression completed. server_id: %d")
+}
+
+func initQueryErrorMessage() {
+	message.Messages[ErrQueryGetTop] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrQueryGetTop,
+		"query: get top slow queries failed. server_id: %d\n%s")
+	message.Messages[ErrQueryGetTrend] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrQueryGetTrend,
+		"query: get slow query trend failed. server_id: %d, sql_id: %s\n%s")
+	message.Messages[ErrQueryGetRegression] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrQueryGetRegression,
+		"query: get slow query regression failed. server_id: %d\n%s")
+}
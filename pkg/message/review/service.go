@@ -0,0 +1,65 @@
+package review
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initServiceDebugMessage()
+	initServiceInfoMessage()
+	initServiceErrorMessage()
+}
+
+const (
+	// debug
+	DebugReviewReview  = 104001
+	DebugReviewGetByID = 104002
+	DebugReviewApprove = 104003
+
+	// info
+	InfoReviewReview  = 204001
+	InfoReviewGetByID = 204002
+	InfoReviewApprove = 204003
+
+	// error
+	ErrReviewReview  = 404001
+	ErrReviewGetByID = 404002
+	ErrReviewApprove = 404003
+)
+
+func initServiceDebugMessage() {
+	message.Messages[DebugReviewReview] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugReviewReview,
+		"review: review sql text message: %s")
+	message.Messages[DebugReviewGetByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugReviewGetByID,
+		"review: get review record by id message: %s")
+	message.Messages[DebugReviewApprove] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugReviewApprove,
+		"review: approve review record message: %s")
+}
+
+func initServiceInfoMessage() {
+	message.Messages[InfoReviewReview] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoReviewReview,
+		"review: review sql text completed. db id: %d, verdict: %d")
+	message.Messages[InfoReviewGetByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoReviewGetByID,
+		"review: get review record by id completed. id: %d")
+	message.Messages[InfoReviewApprove] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoReviewApprove,
+		"review: approve review record completed. id: %d, account name: %s")
+}
+
+func initServiceErrorMessage() {
+	message.Messages[ErrReviewReview] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrReviewReview,
+		"review: review sql text failed. db id: %d, error: %s")
+	message.Messages[ErrReviewGetByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrReviewGetByID,
+		"review: get review record by id failed. id: %d, error: %s")
+	message.Messages[ErrReviewApprove] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrReviewApprove,
+		"review: approve review record failed. id: %d, account name: %s, error: %s")
+}
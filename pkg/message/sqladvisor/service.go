@@ -13,18 +13,36 @@ func init() {
 
 const (
 	// debug
+	DebugSQLAdvisorGetAdviceBySQLID = 102001
+	DebugSQLAdvisorGetSQLInfos      = 102002
 
 	// info
-	InfoSQLAdvisorGetFingerprint = 202001
-	InfoSQLAdvisorGetSQLID       = 202002
-	InfoSQLAdvisorAdvice         = 202003
+	InfoSQLAdvisorGetFingerprint    = 202001
+	InfoSQLAdvisorGetSQLID          = 202002
+	InfoSQLAdvisorAdvice            = 202003
+	InfoSQLAdvisorGetAdviceBySQLID  = 202004
+	InfoSQLAdvisorAutoAdvice        = 202005
+	InfoSQLAdvisorAutoAdviceStart   = 202006
+	InfoSQLAdvisorAutoAdviceStop    = 202007
+	InfoSQLAdvisorAutoAdviceNotify  = 202008
+	InfoSQLAdvisorAutoAdviceSkipped = 202009
+	InfoSQLAdvisorGetSQLInfos       = 202010
 
 	// error
-	ErrSQLAdvisorAdvice = 402001
+	ErrSQLAdvisorAdvice            = 402001
+	ErrSQLAdvisorGetAdviceBySQLID  = 402002
+	ErrSQLAdvisorAutoAdvice        = 402003
+	ErrSQLAdvisorAutoAdviceCluster = 402004
+	ErrSQLAdvisorAutoAdviceNotify  = 402005
 )
 
 func initServiceDebugMessage() {
-
+	message.Messages[DebugSQLAdvisorGetAdviceBySQLID] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugSQLAdvisorGetAdviceBySQLID,
+		"sqladvisor: get slow query advice by sql id message: %s")
+	message.Messages[DebugSQLAdvisorGetSQLInfos] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugSQLAdvisorGetSQLInfos,
+		"sqladvisor: get sql infos message: %s")
 }
 
 func initServiceInfoMessage() {
@@ -37,10 +55,43 @@ func initServiceInfoMessage() {
 	message.Messages[InfoSQLAdvisorAdvice] = config.NewErrMessage(
 		message.DefaultMessageHeader, InfoSQLAdvisorAdvice,
 		"sqladvisor: advice completed. db id: %d, sql text: %s, advice: %s")
+	message.Messages[InfoSQLAdvisorGetAdviceBySQLID] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoSQLAdvisorGetAdviceBySQLID,
+		"sqladvisor: get slow query advice by sql id completed. sql id: %s")
+	message.Messages[InfoSQLAdvisorAutoAdvice] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoSQLAdvisorAutoAdvice,
+		"sqladvisor: auto advice completed. mysql cluster id: %d, advised: %d, skipped: %d")
+	message.Messages[InfoSQLAdvisorAutoAdviceStart] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoSQLAdvisorAutoAdviceStart,
+		"sqladvisor: auto advisor started. interval: %s, top num: %d")
+	message.Messages[InfoSQLAdvisorAutoAdviceStop] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoSQLAdvisorAutoAdviceStop,
+		"sqladvisor: auto advisor stopped.")
+	message.Messages[InfoSQLAdvisorAutoAdviceNotify] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoSQLAdvisorAutoAdviceNotify,
+		"sqladvisor: notify completed. users: %v, subject: %s\n%s")
+	message.Messages[InfoSQLAdvisorAutoAdviceSkipped] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoSQLAdvisorAutoAdviceSkipped,
+		"sqladvisor: slow query is skipped. mysql cluster id: %d, db name: %s, sql id: %s, reason: %s")
+	message.Messages[InfoSQLAdvisorGetSQLInfos] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoSQLAdvisorGetSQLInfos,
+		"sqladvisor: get sql infos completed. sql num: %d")
 }
 
 func initServiceErrorMessage() {
 	message.Messages[ErrSQLAdvisorAdvice] = config.NewErrMessage(
 		message.DefaultMessageHeader, ErrSQLAdvisorAdvice,
 		"sqladvisor: advice failed. db id: %d, sql text: %s, error: %s")
+	message.Messages[ErrSQLAdvisorGetAdviceBySQLID] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrSQLAdvisorGetAdviceBySQLID,
+		"sqladvisor: get slow query advice by sql id failed. sql id: %s, error: %s")
+	message.Messages[ErrSQLAdvisorAutoAdvice] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrSQLAdvisorAutoAdvice,
+		"sqladvisor: auto advice failed. error: %s")
+	message.Messages[ErrSQLAdvisorAutoAdviceCluster] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrSQLAdvisorAutoAdviceCluster,
+		"sqladvisor: auto advice of mysql cluster failed. mysql cluster id: %d, error: %s")
+	message.Messages[ErrSQLAdvisorAutoAdviceNotify] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrSQLAdvisorAutoAdviceNotify,
+		"sqladvisor: notify owners failed. db id: %d, sql id: %s, error: %s")
 }
@@ -0,0 +1,54 @@
+package message
+
+import (
+	"net/http"
+)
+
+const (
+	minErrorCode = 400000
+	maxErrorCode = 500000
+)
+
+// httpStatuses maps the message codes to the http statuses which are responded to the clients
+var httpStatuses = map[int]int{}
+
+func initHTTPStatus() {
+	// the request parameters are not valid
+	SetHTTPStatus(http.StatusBadRequest,
+		ErrFieldNotExists,
+		ErrGetRawData,
+		ErrUnmarshalRawData,
+		ErrTypeConversion,
+		ErrNotValidTimeLayout,
+		ErrNotValidTimeDuration,
+		ErrNotValidQueryParameter,
+		ErrNotValidRequest,
+	)
+	// the data to get, update or delete does not exist
+	SetHTTPStatus(http.StatusNotFound, ErrDataNotExists)
+	// the data was modified by others, or the unique key of the data to create already exists
+	SetHTTPStatus(http.StatusConflict, ErrDataConflict)
+	// the data does not match the If-Match header
+	SetHTTPStatus(http.StatusPreconditionFailed, ErrDataNotMatch)
+}
+
+// SetHTTPStatus maps the message codes to the http status
+func SetHTTPStatus(status int, codes ...int) {
+	for _, code := range codes {
+		httpStatuses[code] = status
+	}
+}
+
+// GetHTTPStatus returns the http status of the message code,
+// the error messages which are not mapped are responded with 500, and the others are responded with 200
+func GetHTTPStatus(code int) int {
+	status, ok := httpStatuses[code]
+	if ok {
+		return status
+	}
+	if code >= minErrorCode && code < maxErrorCode {
+		return http.StatusInternalServerError
+	}
+
+	return http.StatusOK
+}
@@ -0,0 +1,53 @@
+package topology
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initServiceDebugMessage()
+	initServiceInfoMessage()
+	initServiceErrorMessage()
+}
+
+const (
+	// debug
+	DebugTopologyGetGraph  = 107001
+	DebugTopologyGetImpact = 107002
+
+	// info
+	InfoTopologyGetGraph  = 207001
+	InfoTopologyGetImpact = 207002
+
+	// error
+	ErrTopologyGetGraph  = 407001
+	ErrTopologyGetImpact = 407002
+)
+
+func initServiceDebugMessage() {
+	message.Messages[DebugTopologyGetGraph] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugTopologyGetGraph,
+		"topology: get graph message: %s")
+	message.Messages[DebugTopologyGetImpact] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugTopologyGetImpact,
+		"topology: get impact message: %s")
+}
+
+func initServiceInfoMessage() {
+	message.Messages[InfoTopologyGetGraph] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoTopologyGetGraph,
+		"topology: get graph completed. node type: %s, id: %d, depth: %d")
+	message.Messages[InfoTopologyGetImpact] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoTopologyGetImpact,
+		"topology: get impact completed. mysql server id: %d")
+}
+
+func initServiceErrorMessage() {
+	message.Messages[ErrTopologyGetGraph] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrTopologyGetGraph,
+		"topology: get graph failed. node type: %s, id: %d, depth: %d, error: %s")
+	message.Messages[ErrTopologyGetImpact] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrTopologyGetImpact,
+		"topology: get impact failed. mysql server id: %d, error: %s")
+}
@@ -0,0 +1,192 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/romberli/go-util/constant"
+
+	"github.com/romberli/das/global"
+)
+
+const (
+	Path = "/metrics"
+
+	namespace = "das"
+
+	// the backends which are queried by das
+	BackendPrometheus = "prometheus"
+	BackendClickhouse = "clickhouse"
+	BackendPMMMySQL   = "pmm_mysql"
+	BackendPMMAPI     = "pmm_api"
+
+	// the status of the healthcheck operations
+	HealthcheckStatusCompleted   = "completed"
+	HealthcheckStatusFailed      = "failed"
+	HealthcheckStatusInterrupted = "interrupted"
+
+	methodLabel  = "method"
+	routeLabel   = "route"
+	statusLabel  = "status"
+	itemLabel    = "item"
+	backendLabel = "backend"
+	poolLabel    = "pool"
+
+	unmatchedRoute = "unmatched"
+	dasPool        = "das"
+)
+
+var (
+	// Registry contains all the metrics of das, including the go runtime and the process metrics
+	Registry = prometheus.NewRegistry()
+
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "number of the http requests by method, route and status",
+	}, []string{methodLabel, routeLabel, statusLabel})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "latency of the http requests by method and route",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{methodLabel, routeLabel})
+
+	healthcheckOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "healthcheck",
+		Name:      "operations_total",
+		Help:      "number of the finished healthcheck operations by status",
+	}, []string{statusLabel})
+	healthcheckItemDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "healthcheck",
+		Name:      "item_duration_seconds",
+		Help:      "duration of checking the healthcheck items",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{itemLabel})
+
+	soarDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "soar",
+		Name:      "duration_seconds",
+		Help:      "latency of the soar invocations",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	})
+	soarFailuresTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "soar",
+		Name:      "failures_total",
+		Help:      "number of the failed soar invocations",
+	})
+
+	backendQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "backend",
+		Name:      "query_duration_seconds",
+		Help:      "latency of the queries to the monitor backends",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{backendLabel})
+	backendQueryErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "backend",
+		Name:      "query_errors_total",
+		Help:      "number of the failed queries to the monitor backends",
+	}, []string{backendLabel})
+
+	dbPoolUsedConnections = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Subsystem:   "db_pool",
+		Name:        "used_connections",
+		Help:        "number of the connections of the metadata database pool which are in use",
+		ConstLabels: prometheus.Labels{poolLabel: dasPool},
+	}, func() float64 {
+		if global.DASMySQLPool == nil {
+			return 0
+		}
+
+		return float64(global.DASMySQLPool.UsedConnections())
+	})
+	dbPoolMaxConnections = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Subsystem:   "db_pool",
+		Name:        "max_connections",
+		Help:        "max number of the connections of the metadata database pool",
+		ConstLabels: prometheus.Labels{poolLabel: dasPool},
+	}, func() float64 {
+		if global.DASMySQLPool == nil {
+			return 0
+		}
+
+		return float64(global.DASMySQLPool.MaxConnections)
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		healthcheckOperationsTotal,
+		healthcheckItemDuration,
+		soarDuration,
+		soarFailuresTotal,
+		backendQueryDuration,
+		backendQueryErrorsTotal,
+		dbPoolUsedConnections,
+		dbPoolMaxConnections,
+	)
+}
+
+// Handler returns the handler which exposes the metrics in the prometheus text format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware returns the middleware which observes the count and the latency of the http requests,
+// the route is the path pattern such as /api/v2/metadata/apps/:id, so that the ids do not blow up the cardinality
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == constant.EmptyString {
+			route = unmatchedRoute
+		}
+		httpRequestsTotal.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(startTime).Seconds())
+	}
+}
+
+// ObserveHealthcheckOperation counts the finished healthcheck operation of given status
+func ObserveHealthcheckOperation(status string) {
+	healthcheckOperationsTotal.WithLabelValues(status).Inc()
+}
+
+// ObserveHealthcheckItem observes the duration of checking the healthcheck item which started at given time
+func ObserveHealthcheckItem(item string, startTime time.Time) {
+	healthcheckItemDuration.WithLabelValues(item).Observe(time.Since(startTime).Seconds())
+}
+
+// ObserveSoar observes the latency of the soar invocation which started at given time, err is the error of the invocation
+func ObserveSoar(startTime time.Time, err error) {
+	soarDuration.Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		soarFailuresTotal.Inc()
+	}
+}
+
+// ObserveBackendQuery observes the latency of the query to the backend which started at given time, err is the error of the query
+func ObserveBackendQuery(backend string, startTime time.Time, err error) {
+	backendQueryDuration.WithLabelValues(backend).Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		backendQueryErrorsTotal.WithLabelValues(backend).Inc()
+	}
+}
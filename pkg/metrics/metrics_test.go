@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsAll(t *testing.T) {
+	TestMiddleware(t)
+	TestObserve(t)
+	TestHandler(t)
+}
+
+func TestMiddleware(t *testing.T) {
+	asst := assert.New(t)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Middleware())
+	engine.GET("/api/v2/metadata/apps/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	before := testutil.ToFloat64(httpRequestsTotal.WithLabelValues(http.MethodGet, "/api/v2/metadata/apps/:id", "404"))
+	unmatchedBefore := testutil.ToFloat64(httpRequestsTotal.WithLabelValues(http.MethodGet, unmatchedRoute, "404"))
+	for _, path := range []string{"/api/v2/metadata/apps/1", "/api/v2/metadata/apps/2", "/not/exists"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	// the requests are counted by the route pattern instead of the path
+	asst.Equal(before+2, testutil.ToFloat64(httpRequestsTotal.WithLabelValues(http.MethodGet, "/api/v2/metadata/apps/:id", "404")), "test Middleware() failed")
+	asst.Equal(unmatchedBefore+1, testutil.ToFloat64(httpRequestsTotal.WithLabelValues(http.MethodGet, unmatchedRoute, "404")), "test Middleware() failed")
+}
+
+func TestObserve(t *testing.T) {
+	asst := assert.New(t)
+
+	before := testutil.ToFloat64(backendQueryErrorsTotal.WithLabelValues(BackendClickhouse))
+	ObserveBackendQuery(BackendClickhouse, time.Now(), nil)
+	ObserveBackendQuery(BackendClickhouse, time.Now(), errors.New("test error"))
+	asst.Equal(before+1, testutil.ToFloat64(backendQueryErrorsTotal.WithLabelValues(BackendClickhouse)), "test ObserveBackendQuery() failed")
+
+	before = testutil.ToFloat64(soarFailuresTotal)
+	ObserveSoar(time.Now(), errors.New("test error"))
+	asst.Equal(before+1, testutil.ToFloat64(soarFailuresTotal), "test ObserveSoar() failed")
+
+	before = testutil.ToFloat64(healthcheckOperationsTotal.WithLabelValues(HealthcheckStatusInterrupted))
+	ObserveHealthcheckOperation(HealthcheckStatusInterrupted)
+	asst.Equal(before+1, testutil.ToFloat64(healthcheckOperationsTotal.WithLabelValues(HealthcheckStatusInterrupted)), "test ObserveHealthcheckOperation() failed")
+
+	// the pool is not initialized
+	asst.Equal(float64(0), testutil.ToFloat64(dbPoolUsedConnections), "test dbPoolUsedConnections failed")
+}
+
+func TestHandler(t *testing.T) {
+	asst := assert.New(t)
+
+	ObserveHealthcheckItem("cpu_usage", time.Now())
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET(Path, Handler())
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, Path, nil))
+	asst.Equal(http.StatusOK, recorder.Code, "test Handler() failed")
+	asst.Contains(recorder.Body.String(), `das_healthcheck_item_duration_seconds_count{item="cpu_usage"}`, "test Handler() failed")
+	asst.Contains(recorder.Body.String(), `das_db_pool_max_connections{pool="das"} 0`, "test Handler() failed")
+	asst.Contains(recorder.Body.String(), "go_goroutines", "test Handler() failed")
+}
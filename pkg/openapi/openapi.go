@@ -0,0 +1,296 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	Version = "3.0.3"
+
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+
+	jsonTag      = "json"
+	validateTag  = "validate"
+	ignoredField = "-"
+	refPrefix    = "#/components/schemas/"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Document is the root object of the openapi 3 specification
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       *Info                `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components,omitempty"`
+}
+
+// NewDocument returns a new *Document with given title and version
+func NewDocument(title, version string) *Document {
+	return &Document{
+		OpenAPI:    Version,
+		Info:       &Info{Title: title, Version: version},
+		Paths:      make(map[string]*PathItem),
+		Components: &Components{Schemas: make(map[string]*Schema)},
+	}
+}
+
+// AddOperation adds the operation of given method to the path
+func (d *Document) AddOperation(method, path string, operation *Operation) {
+	pathItem, ok := d.Paths[path]
+	if !ok {
+		pathItem = &PathItem{}
+		d.Paths[path] = pathItem
+	}
+	(*pathItem)[strings.ToLower(method)] = operation
+}
+
+// AddSchema adds the schema of v to the components with given name and returns a reference to it
+func (d *Document) AddSchema(name string, v interface{}) *Schema {
+	d.Components.Schemas[name] = NewSchema(v)
+
+	return &Schema{Ref: refPrefix + name}
+}
+
+// Info is the metadata of the api
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem contains the operations of a path, the keys are the lower case http methods
+type PathItem map[string]*Operation
+
+// Operation is an api operation on a path
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a parameter of an operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the request body of an operation, the keys of the content are the media types
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// Response is a response of an operation, the keys of the content are the media types
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header is a response header
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType contains the schema of a media type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components contains the reusable schemas
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is the json schema of a value
+type Schema struct {
+	Ref              string             `json:"$ref,omitempty"`
+	Type             string             `json:"type,omitempty"`
+	Format           string             `json:"format,omitempty"`
+	Description      string             `json:"description,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty"`
+	Required         []string           `json:"required,omitempty"`
+	Items            *Schema            `json:"items,omitempty"`
+	Enum             []interface{}      `json:"enum,omitempty"`
+	Minimum          *float64           `json:"minimum,omitempty"`
+	ExclusiveMinimum bool               `json:"exclusiveMinimum,omitempty"`
+	Maximum          *float64           `json:"maximum,omitempty"`
+	ExclusiveMaximum bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength        *int               `json:"minLength,omitempty"`
+	MaxLength        *int               `json:"maxLength,omitempty"`
+}
+
+// NewSchema returns the schema of v, the properties of the structs are named by the json tags,
+// and the validate tags, such as required, oneof, min and max, are converted to the constraints of the properties
+func NewSchema(v interface{}) *Schema {
+	return newSchema(reflect.TypeOf(v))
+}
+
+// NewObjectSchema returns an object schema with given properties
+func NewObjectSchema(properties map[string]*Schema) *Schema {
+	return &Schema{Type: TypeObject, Properties: properties}
+}
+
+// NewArraySchema returns an array schema of which the items are of given schema
+func NewArraySchema(items *Schema) *Schema {
+	return &Schema{Type: TypeArray, Items: items}
+}
+
+// newSchema returns the schema of the type
+func newSchema(typ reflect.Type) *Schema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		return &Schema{Type: TypeString, Format: "date-time"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		return newStructSchema(typ)
+	case reflect.Slice, reflect.Array:
+		return NewArraySchema(newSchema(typ.Elem()))
+	case reflect.Map:
+		return &Schema{Type: TypeObject}
+	case reflect.String:
+		return &Schema{Type: TypeString}
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: TypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeNumber}
+	default:
+		return &Schema{}
+	}
+}
+
+// newStructSchema returns the object schema of the struct type,
+// the anonymous fields and the fields without json tags, such as the embedded repositories, are ignored
+func newStructSchema(typ reflect.Type) *Schema {
+	schema := NewObjectSchema(make(map[string]*Schema))
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous || field.PkgPath != constant.EmptyString {
+			continue
+		}
+		name := strings.Split(field.Tag.Get(jsonTag), constant.CommaString)[constant.ZeroInt]
+		if name == constant.EmptyString || name == ignoredField {
+			continue
+		}
+
+		property := newSchema(field.Type)
+		if applyValidateTag(property, field.Tag.Get(validateTag)) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+
+	return schema
+}
+
+// applyValidateTag converts the validate tag to the constraints of the schema, it returns true if the field is required
+func applyValidateTag(schema *Schema, tag string) bool {
+	var required bool
+	for _, rule := range strings.Split(tag, constant.CommaString) {
+		// the rules after dive are applied to the items
+		if rule == "dive" {
+			break
+		}
+		name, param := rule, constant.EmptyString
+		if i := strings.Index(rule, "="); i >= constant.ZeroInt {
+			name, param = rule[:i], rule[i+1:]
+		}
+
+		switch name {
+		case "required":
+			required = true
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, parseValue(schema.Type, value))
+			}
+		case "min", "gte":
+			setMinimum(schema, param, false)
+		case "gt":
+			setMinimum(schema, param, true)
+		case "max", "lte":
+			setMaximum(schema, param, false)
+		case "lt":
+			setMaximum(schema, param, true)
+		case "ip":
+			schema.Format = "ip"
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		}
+	}
+
+	return required
+}
+
+// setMinimum sets the minimum of the numbers or the min length of the strings
+func setMinimum(schema *Schema, param string, exclusive bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	if schema.Type == TypeString {
+		minLength := int(value)
+		schema.MinLength = &minLength
+		return
+	}
+	schema.Minimum = &value
+	schema.ExclusiveMinimum = exclusive
+}
+
+// setMaximum sets the maximum of the numbers or the max length of the strings
+func setMaximum(schema *Schema, param string, exclusive bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	if schema.Type == TypeString {
+		maxLength := int(value)
+		schema.MaxLength = &maxLength
+		return
+	}
+	schema.Maximum = &value
+	schema.ExclusiveMaximum = exclusive
+}
+
+// parseValue parses the enum value with the type of the schema
+func parseValue(typ, value string) interface{} {
+	if typ == TypeInteger {
+		i, err := strconv.Atoi(value)
+		if err == nil {
+			return i
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRequest struct {
+	HostIP   *string   `json:"host_ip" validate:"required,ip"`
+	PortNum  *int      `json:"port_num" validate:"required,min=1,max=65535"`
+	Role     *int      `json:"role" validate:"omitempty,oneof=1 2 3"`
+	OwnerID  *int      `json:"owner_id" validate:"omitempty,gt=0"`
+	Name     *string   `json:"name" validate:"omitempty,min=1,max=100"`
+	Tags     []string  `json:"tags" validate:"omitempty,max=10,dive,min=1"`
+	Time     time.Time `json:"time"`
+	Ignored  string    `json:"-"`
+	NoTag    string
+	internal string
+}
+
+func TestOpenAPIAll(t *testing.T) {
+	TestNewSchema(t)
+	TestDocument(t)
+}
+
+func TestNewSchema(t *testing.T) {
+	asst := assert.New(t)
+
+	schema := NewSchema(&testRequest{})
+	asst.Equal(TypeObject, schema.Type, "test NewSchema() failed")
+	asst.Equal([]string{"host_ip", "port_num"}, schema.Required, "test NewSchema() failed")
+	asst.Equal(7, len(schema.Properties), "test NewSchema() failed")
+	asst.Equal("ip", schema.Properties["host_ip"].Format, "test NewSchema() failed")
+	asst.Equal(TypeInteger, schema.Properties["port_num"].Type, "test NewSchema() failed")
+	asst.Equal(float64(1), *schema.Properties["port_num"].Minimum, "test NewSchema() failed")
+	asst.Equal(float64(65535), *schema.Properties["port_num"].Maximum, "test NewSchema() failed")
+	asst.Equal([]interface{}{1, 2, 3}, schema.Properties["role"].Enum, "test NewSchema() failed")
+	asst.True(schema.Properties["owner_id"].ExclusiveMinimum, "test NewSchema() failed")
+	asst.Equal(1, *schema.Properties["name"].MinLength, "test NewSchema() failed")
+	asst.Equal(100, *schema.Properties["name"].MaxLength, "test NewSchema() failed")
+	// the rules after dive are not applied to the array
+	asst.Equal(TypeArray, schema.Properties["tags"].Type, "test NewSchema() failed")
+	asst.Equal(TypeString, schema.Properties["tags"].Items.Type, "test NewSchema() failed")
+	asst.Nil(schema.Properties["tags"].Items.MinLength, "test NewSchema() failed")
+	asst.Equal("date-time", schema.Properties["time"].Format, "test NewSchema() failed")
+}
+
+func TestDocument(t *testing.T) {
+	asst := assert.New(t)
+
+	doc := NewDocument("test", "1.0.0")
+	ref := doc.AddSchema("Test", testRequest{})
+	asst.Equal("#/components/schemas/Test", ref.Ref, "test AddSchema() failed")
+	doc.AddOperation(http.MethodGet, "/tests/{id}", &Operation{OperationID: "getTests"})
+	doc.AddOperation(http.MethodPut, "/tests/{id}", &Operation{OperationID: "replaceTests"})
+	asst.Equal(2, len(*doc.Paths["/tests/{id}"]), "test AddOperation() failed")
+
+	jsonBytes, err := json.Marshal(doc)
+	asst.Nil(err, common.CombineMessageWithError("test Document failed", err))
+	result := make(map[string]interface{})
+	err = json.Unmarshal(jsonBytes, &result)
+	asst.Nil(err, common.CombineMessageWithError("test Document failed", err))
+	asst.Equal(Version, result["openapi"], "test Document failed")
+	asst.Contains(result["paths"].(map[string]interface{})["/tests/{id}"], "get", "test Document failed")
+}
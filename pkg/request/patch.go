@@ -0,0 +1,59 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	// MergePatchContentType is the content type of the json merge patch documents, see RFC 7396
+	MergePatchContentType = "application/merge-patch+json"
+)
+
+// MergePatch applies the json merge patch to the json document and returns the patched document, see RFC 7396,
+// the members of the patch which are null are removed from the document, the objects are merged recursively,
+// and the other values replace the members of the document
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var patchValue interface{}
+	err := json.Unmarshal(patch, &patchValue)
+	if err != nil {
+		return nil, err
+	}
+	patchObject, ok := patchValue.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("json merge patch must be an object")
+	}
+
+	var docValue interface{}
+	if len(doc) > 0 {
+		err = json.Unmarshal(doc, &docValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(mergePatch(docValue, patchObject))
+}
+
+// mergePatch merges the patch object to the document value
+func mergePatch(doc interface{}, patch map[string]interface{}) map[string]interface{} {
+	docObject, ok := doc.(map[string]interface{})
+	if !ok {
+		docObject = make(map[string]interface{})
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(docObject, key)
+			continue
+		}
+		patchObject, ok := value.(map[string]interface{})
+		if ok {
+			docObject[key] = mergePatch(docObject[key], patchObject)
+			continue
+		}
+		docObject[key] = value
+	}
+
+	return docObject
+}
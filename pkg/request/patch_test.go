@@ -0,0 +1,45 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	asst := assert.New(t)
+
+	doc := []byte(`{"env_name": "online", "del_flag": 1, "labels": {"a": "1", "b": "2"}}`)
+	patched, err := MergePatch(doc, []byte(`{"env_name": "offline", "del_flag": null, "labels": {"a": null, "c": "3"}}`))
+	asst.Nil(err, common.CombineMessageWithError("test MergePatch() failed", err))
+	asst.JSONEq(`{"env_name": "offline", "labels": {"b": "2", "c": "3"}}`, string(patched), "test MergePatch() failed")
+	// the arrays are replaced
+	patched, err = MergePatch([]byte(`{"ids": [1, 2]}`), []byte(`{"ids": [3]}`))
+	asst.Nil(err, common.CombineMessageWithError("test MergePatch() failed", err))
+	asst.JSONEq(`{"ids": [3]}`, string(patched), "test MergePatch() failed")
+	// empty document
+	patched, err = MergePatch(nil, []byte(`{"env_name": "online"}`))
+	asst.Nil(err, common.CombineMessageWithError("test MergePatch() failed", err))
+	asst.JSONEq(`{"env_name": "online"}`, string(patched), "test MergePatch() failed")
+	// the patch must be an object
+	_, err = MergePatch(doc, []byte(`["env_name"]`))
+	asst.NotNil(err, "test MergePatch() failed")
+	_, err = MergePatch(doc, []byte(`{`))
+	asst.NotNil(err, "test MergePatch() failed")
+}
+
+func TestRequireAllFields(t *testing.T) {
+	asst := assert.New(t)
+
+	envName := "online"
+	err := RequireAllFields(&testUpdateRequest{EnvName: &envName})
+	ve, ok := err.(*ValidationError)
+	asst.True(ok, "test RequireAllFields() failed")
+	asst.Equal(1, len(ve.FieldErrors), "test RequireAllFields() failed")
+	asst.Equal("del_flag", ve.FieldErrors[0].Field, "test RequireAllFields() failed")
+	delFlag := 0
+	err = RequireAllFields(&testUpdateRequest{EnvName: &envName, DelFlag: &delFlag})
+	asst.Nil(err, common.CombineMessageWithError("test RequireAllFields() failed", err))
+	asst.Equal([]string{"env_name", "del_flag"}, GetJSONFields(&testUpdateRequest{}), "test GetJSONFields() failed")
+}
@@ -0,0 +1,323 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/romberli/go-util/constant"
+
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/das/pkg/resp"
+)
+
+const (
+	jsonTag      = "json"
+	validateTag  = "validate"
+	ignoredField = "-"
+	notBlankTag  = "notblank"
+	requiredTag  = "required"
+)
+
+// ErrEmptyRequest is returned when none of the fields of the request is specified
+var ErrEmptyRequest = &ValidationError{FieldErrors: []*FieldError{{Message: "at least one field must be specified"}}}
+
+// tagMessages are the formats of the messages of the validation tags, the parameter of the tag is the argument of the format
+var tagMessages = map[string]string{
+	"required": "is required",
+	"notblank": "must not be blank",
+	"min":      "must be greater than or equal to %s",
+	"max":      "must be less than or equal to %s",
+	"gt":       "must be greater than %s",
+	"gte":      "must be greater than or equal to %s",
+	"lt":       "must be less than %s",
+	"lte":      "must be less than or equal to %s",
+	"oneof":    "must be one of [%s]",
+	"ip":       "must be a valid ip address",
+	"email":    "must be a valid email address",
+	"url":      "must be a valid url",
+	"numeric":  "must be a numeric string",
+}
+
+var validate = newValidator()
+
+// newValidator returns a validator which reports the fields by the json names,
+// it also validates if the strings are not blank with the notblank tag
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName(validateTag)
+	_ = v.RegisterValidation(notBlankTag, func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != constant.EmptyString
+	})
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get(jsonTag), constant.CommaString)[constant.ZeroInt]
+		if name == ignoredField {
+			return constant.EmptyString
+		}
+
+		return name
+	})
+
+	return v
+}
+
+// FieldError is the validation error of a field of the request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError contains all the field errors of the request
+type ValidationError struct {
+	FieldErrors []*FieldError `json:"field_errors"`
+}
+
+// Error implements error interface
+func (ve *ValidationError) Error() string {
+	messages := make([]string, len(ve.FieldErrors))
+	for i, fe := range ve.FieldErrors {
+		if fe.Field == constant.EmptyString {
+			messages[i] = fe.Message
+			continue
+		}
+		messages[i] = fmt.Sprintf("%s %s", fe.Field, fe.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// hasField returns if the field has an error already
+func (ve *ValidationError) hasField(field string) bool {
+	for _, fe := range ve.FieldErrors {
+		if fe.Field == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Bind unmarshals the request body to req and validates it with the validate tags of req,
+// the unknown fields are not allowed, it returns a *ValidationError if any field is not valid
+func Bind(c *gin.Context, req interface{}) error {
+	data, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+
+	return Unmarshal(data, req)
+}
+
+// BindQuery sets the fields of req with the query parameters of which the names are the json names of the fields,
+// and validates req with the validate tags of req, the fields of which query parameters are not specified keep their values,
+// so that the default values could be set before binding,
+// the supported field types are string, int, float64 and time.Time which is formatted as yyyy-mm-dd hh:mm:ss,
+// it returns a *ValidationError which contains all the field errors if any field is not valid
+func BindQuery(c *gin.Context, req interface{}) error {
+	val := reflect.Indirect(reflect.ValueOf(req))
+	ve := &ValidationError{}
+	for i := 0; i < val.NumField(); i++ {
+		name := strings.Split(val.Type().Field(i).Tag.Get(jsonTag), constant.CommaString)[constant.ZeroInt]
+		valueStr := c.Query(name)
+		if name == constant.EmptyString || name == ignoredField || valueStr == constant.EmptyString {
+			continue
+		}
+		err := setQueryValue(val.Field(i), valueStr)
+		if err != nil {
+			ve.FieldErrors = append(ve.FieldErrors, &FieldError{Field: name, Message: err.Error()})
+		}
+	}
+
+	err := Validate(req)
+	if err != nil {
+		var validateErr *ValidationError
+		if !errors.As(err, &validateErr) {
+			return err
+		}
+		// the fields which could not be converted are reported only once
+		for _, fe := range validateErr.FieldErrors {
+			if !ve.hasField(fe.Field) {
+				ve.FieldErrors = append(ve.FieldErrors, fe)
+			}
+		}
+	}
+	if len(ve.FieldErrors) > constant.ZeroInt {
+		return ve
+	}
+
+	return nil
+}
+
+// setQueryValue converts the value of the query parameter to the type of the field and sets the field with it
+func setQueryValue(field reflect.Value, valueStr string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(valueStr)
+	case int:
+		value, err := strconv.Atoi(valueStr)
+		if err != nil {
+			return errors.New("must be int")
+		}
+		field.SetInt(int64(value))
+	case float64:
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			return errors.New("must be float64")
+		}
+		field.SetFloat(value)
+	case time.Time:
+		value, err := time.ParseInLocation(constant.TimeLayoutSecond, valueStr, time.Local)
+		if err != nil {
+			return errors.New("must be formatted as yyyy-mm-dd hh:mm:ss")
+		}
+		field.Set(reflect.ValueOf(value))
+	default:
+		return fmt.Errorf("type %s is not supported", field.Type().String())
+	}
+
+	return nil
+}
+
+// BindFields binds the request body as Bind() and returns the specified fields of req,
+// the fields of req must be pointers, and the names of them must be the same as the fields of the entity,
+// so that the returned fields could be used to create or update the entity,
+// it returns ErrEmptyRequest if none of the fields is specified
+func BindFields(c *gin.Context, req interface{}) (map[string]interface{}, error) {
+	err := Bind(c, req)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := GetFields(req)
+	if len(fields) == constant.ZeroInt {
+		return nil, ErrEmptyRequest
+	}
+
+	return fields, nil
+}
+
+// Unmarshal unmarshals the json data to req and validates it with the validate tags of req
+func Unmarshal(data []byte, req interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(req)
+	// empty data is validated as an empty object, so that the missing fields are reported
+	if err != nil && err != io.EOF {
+		var ute *json.UnmarshalTypeError
+		if errors.As(err, &ute) {
+			return &ValidationError{FieldErrors: []*FieldError{{Field: ute.Field, Message: fmt.Sprintf("must be %s", ute.Type.String())}}}
+		}
+
+		return err
+	}
+
+	return Validate(req)
+}
+
+// Validate validates req with the validate tags, it returns a *ValidationError which contains all the field errors
+func Validate(req interface{}) error {
+	err := validate.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var ves validator.ValidationErrors
+	if !errors.As(err, &ves) {
+		return err
+	}
+
+	ve := &ValidationError{FieldErrors: make([]*FieldError, len(ves))}
+	for i, fe := range ves {
+		ve.FieldErrors[i] = &FieldError{Field: fe.Field(), Message: getTagMessage(fe.Tag(), fe.Param())}
+	}
+
+	return ve
+}
+
+// RequireAllFields returns a *ValidationError which contains the pointer fields of req which are not specified,
+// it is used when the request replaces the entity, so that the optional fields of the request must be specified as well
+func RequireAllFields(req interface{}) error {
+	val := reflect.Indirect(reflect.ValueOf(req))
+	ve := &ValidationError{}
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		if field.Kind() != reflect.Ptr || !field.IsNil() {
+			continue
+		}
+		name := strings.Split(val.Type().Field(i).Tag.Get(jsonTag), constant.CommaString)[constant.ZeroInt]
+		ve.FieldErrors = append(ve.FieldErrors, &FieldError{Field: name, Message: tagMessages[requiredTag]})
+	}
+	if len(ve.FieldErrors) > constant.ZeroInt {
+		return ve
+	}
+
+	return nil
+}
+
+// GetJSONFields returns the json names of the fields of req
+func GetJSONFields(req interface{}) []string {
+	typ := reflect.Indirect(reflect.ValueOf(req)).Type()
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get(jsonTag), constant.CommaString)[constant.ZeroInt]
+		if name == constant.EmptyString || name == ignoredField {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// GetFields returns the non-nil pointer fields of req, the keys are the field names and the values are the pointed values
+func GetFields(req interface{}) map[string]interface{} {
+	val := reflect.Indirect(reflect.ValueOf(req))
+	fields := make(map[string]interface{})
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		if field.Kind() != reflect.Ptr || field.IsNil() {
+			continue
+		}
+		fields[val.Type().Field(i).Name] = field.Elem().Interface()
+	}
+
+	return fields
+}
+
+// ResponseNOK responses the error of binding the request,
+// the field errors are responded as the data if the request is not valid
+func ResponseNOK(c *gin.Context, err error) {
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+
+	jsonBytes, marshalErr := json.Marshal(ve)
+	if marshalErr != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, marshalErr.Error())
+		return
+	}
+	resp.ResponseNOKWithData(c, string(jsonBytes), message.ErrNotValidRequest, ve.Error())
+}
+
+// getTagMessage returns the message of the validation tag
+func getTagMessage(tag, param string) string {
+	format, ok := tagMessages[tag]
+	if !ok {
+		return fmt.Sprintf("does not satisfy %s", tag)
+	}
+	if strings.Contains(format, "%s") {
+		return fmt.Sprintf(format, param)
+	}
+
+	return format
+}
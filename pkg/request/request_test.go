@@ -0,0 +1,145 @@
+package request
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/das/pkg/resp"
+)
+
+type testAddRequest struct {
+	HostIP  string `json:"host_ip" validate:"required,ip"`
+	PortNum int    `json:"port_num" validate:"required,min=1,max=65535"`
+	SQLText string `json:"sql_text" validate:"omitempty,notblank"`
+}
+
+type testUpdateRequest struct {
+	EnvName *string `json:"env_name" validate:"omitempty,min=1,max=100"`
+	DelFlag *int    `json:"del_flag" validate:"omitempty,oneof=0 1"`
+}
+
+type testQueryRequest struct {
+	ServerID  int       `json:"server_id" validate:"required,gt=0"`
+	Threshold float64   `json:"threshold" validate:"gt=0"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+	OrderBy   string    `json:"order_by" validate:"oneof=exec_count total_exec_time"`
+}
+
+func newTestContext(body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/test", strings.NewReader(body))
+
+	return c, recorder
+}
+
+func newTestQueryContext(rawQuery string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/test?"+rawQuery, nil)
+
+	return c
+}
+
+func TestRequestAll(t *testing.T) {
+	TestUnmarshal(t)
+	TestBindFields(t)
+	TestBindQuery(t)
+	TestResponseNOK(t)
+	TestMergePatch(t)
+	TestRequireAllFields(t)
+}
+
+func TestUnmarshal(t *testing.T) {
+	asst := assert.New(t)
+
+	req := &testAddRequest{}
+	err := Unmarshal([]byte(`{"host_ip": "192.168.137.11", "port_num": 3306}`), req)
+	asst.Nil(err, common.CombineMessageWithError("test Unmarshal() failed", err))
+	asst.Equal(3306, req.PortNum, "test Unmarshal() failed")
+	// all the field errors are reported
+	err = Unmarshal([]byte(`{"host_ip": "not-an-ip", "sql_text": "  "}`), &testAddRequest{})
+	ve, ok := err.(*ValidationError)
+	asst.True(ok, "test Unmarshal() failed")
+	asst.Equal(3, len(ve.FieldErrors), "test Unmarshal() failed")
+	asst.Equal("host_ip", ve.FieldErrors[0].Field, "test Unmarshal() failed")
+	asst.Equal("port_num is required", ve.FieldErrors[1].Field+" "+ve.FieldErrors[1].Message, "test Unmarshal() failed")
+	asst.Equal("sql_text", ve.FieldErrors[2].Field, "test Unmarshal() failed")
+	// empty body
+	err = Unmarshal(nil, &testAddRequest{})
+	asst.IsType(&ValidationError{}, err, "test Unmarshal() failed")
+	// wrong type
+	err = Unmarshal([]byte(`{"host_ip": "192.168.137.11", "port_num": "3306"}`), &testAddRequest{})
+	ve, ok = err.(*ValidationError)
+	asst.True(ok, "test Unmarshal() failed")
+	asst.Equal("port_num", ve.FieldErrors[0].Field, "test Unmarshal() failed")
+	// unknown field
+	err = Unmarshal([]byte(`{"host_ip": "192.168.137.11", "port_num": 3306, "unknown": 1}`), &testAddRequest{})
+	asst.NotNil(err, "test Unmarshal() failed")
+	_, ok = err.(*ValidationError)
+	asst.False(ok, "test Unmarshal() failed")
+}
+
+func TestBindFields(t *testing.T) {
+	asst := assert.New(t)
+
+	c, _ := newTestContext(`{"env_name": "online"}`)
+	fields, err := BindFields(c, &testUpdateRequest{})
+	asst.Nil(err, common.CombineMessageWithError("test BindFields() failed", err))
+	asst.Equal(map[string]interface{}{"EnvName": "online"}, fields, "test BindFields() failed")
+	c, _ = newTestContext(`{"del_flag": 2}`)
+	_, err = BindFields(c, &testUpdateRequest{})
+	asst.IsType(&ValidationError{}, err, "test BindFields() failed")
+	c, _ = newTestContext(`{}`)
+	_, err = BindFields(c, &testUpdateRequest{})
+	asst.Equal(ErrEmptyRequest, err, "test BindFields() failed")
+}
+
+func TestBindQuery(t *testing.T) {
+	asst := assert.New(t)
+
+	c := newTestQueryContext("server_id=1&start_time=2021-01-21+10%3A00%3A00")
+	req := &testQueryRequest{Threshold: 1.5, OrderBy: "exec_count"}
+	err := BindQuery(c, req)
+	asst.Nil(err, common.CombineMessageWithError("test BindQuery() failed", err))
+	asst.Equal(1, req.ServerID, "test BindQuery() failed")
+	asst.Equal(1.5, req.Threshold, "test BindQuery() failed")
+	asst.Equal(10, req.StartTime.Hour(), "test BindQuery() failed")
+	// the conversion errors and the validation errors are reported together
+	c = newTestQueryContext("server_id=a&threshold=0&start_time=2021-01-21&order_by=unknown")
+	err = BindQuery(c, &testQueryRequest{})
+	ve, ok := err.(*ValidationError)
+	asst.True(ok, "test BindQuery() failed")
+	asst.Equal(4, len(ve.FieldErrors), "test BindQuery() failed")
+	asst.Equal("server_id must be int", ve.FieldErrors[0].Field+" "+ve.FieldErrors[0].Message, "test BindQuery() failed")
+	asst.Equal("start_time", ve.FieldErrors[1].Field, "test BindQuery() failed")
+	asst.Equal("threshold", ve.FieldErrors[2].Field, "test BindQuery() failed")
+	asst.Equal("order_by", ve.FieldErrors[3].Field, "test BindQuery() failed")
+}
+
+func TestResponseNOK(t *testing.T) {
+	asst := assert.New(t)
+
+	c, recorder := newTestContext(`{}`)
+	err := Bind(c, &testAddRequest{})
+	ResponseNOK(c, err)
+	asst.Equal(http.StatusBadRequest, recorder.Code, "test ResponseNOK() failed")
+	r := &resp.Response{}
+	err = json.Unmarshal(recorder.Body.Bytes(), r)
+	asst.Nil(err, common.CombineMessageWithError("test ResponseNOK() failed", err))
+	asst.Equal(message.ErrNotValidRequest, r.Code, "test ResponseNOK() failed")
+	ve := &ValidationError{}
+	err = json.Unmarshal(r.Data, ve)
+	asst.Nil(err, common.CombineMessageWithError("test ResponseNOK() failed", err))
+	asst.Equal(2, len(ve.FieldErrors), "test ResponseNOK() failed")
+}
@@ -0,0 +1,30 @@
+package request
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout returns the middleware which derives the context of the request with the timeout of the route,
+// the routes which are not specified in routeTimeouts use the default timeout, zero or negative timeout means no timeout,
+// the database queries, prometheus queries and the other calls which accept the context are canceled after the timeout
+func Timeout(timeout time.Duration, routeTimeouts map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		routeTimeout, ok := routeTimeouts[c.FullPath()]
+		if !ok {
+			routeTimeout = timeout
+		}
+		if routeTimeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), routeTimeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
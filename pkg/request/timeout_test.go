@@ -0,0 +1,37 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	asst := assert.New(t)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Timeout(time.Second, map[string]time.Duration{"/long/:id": time.Minute, "/none": 0}))
+	deadlines := make(map[string]time.Duration)
+	handler := func(c *gin.Context) {
+		deadline, ok := c.Request.Context().Deadline()
+		if ok {
+			deadlines[c.FullPath()] = time.Until(deadline)
+		}
+		c.Status(http.StatusOK)
+	}
+	engine.GET("/default", handler)
+	engine.GET("/long/:id", handler)
+	engine.GET("/none", handler)
+
+	for _, path := range []string{"/default", "/long/1", "/none"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	asst.InDelta(time.Second, deadlines["/default"], float64(100*time.Millisecond), "test Timeout() failed")
+	asst.InDelta(time.Minute, deadlines["/long/:id"], float64(100*time.Millisecond), "test Timeout() failed")
+	asst.NotContains(deadlines, "/none", "test Timeout() failed")
+}
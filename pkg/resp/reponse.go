@@ -1,27 +1,134 @@
 package resp
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/romberli/log"
+	"github.com/romberli/go-util/constant"
+	"github.com/spf13/viper"
 
+	"github.com/romberli/das/config"
 	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/das/pkg/tracing"
 )
 
+const (
+	// RequestIDHeader is the request header which identifies the request
+	RequestIDHeader = tracing.RequestIDHeader
+	// legacyPathPrefix is the path prefix of the api which could response as the old versions
+	legacyPathPrefix = "/api/v1/"
+)
+
+// Response is the envelope of the responses of the http api
+type Response struct {
+	// Code is the code of the message, see pkg/message
+	Code int `json:"code"`
+	// Message is the message of given code
+	Message string `json:"message"`
+	// Data is the responded data, it is null if the request failed
+	Data json.RawMessage `json:"data"`
+	// RequestID is the value of the X-Request-Id header of the request
+	RequestID string `json:"request_id"`
+}
+
+// NewResponse returns a new *Response,
+// respMessage is used as the data if it is a valid json, otherwise, it will be responded as a json string
+func NewResponse(c *gin.Context, respMessage string, code int, msg string) *Response {
+	var data json.RawMessage
+	if respMessage != constant.EmptyString {
+		data = json.RawMessage(respMessage)
+		if !json.Valid(data) {
+			data, _ = json.Marshal(respMessage)
+		}
+	}
+
+	return &Response{
+		Code:      code,
+		Message:   msg,
+		Data:      data,
+		RequestID: c.GetHeader(RequestIDHeader),
+	}
+}
+
 // ResponseNOK responses with given code and values,
-// if code is between 400000 and 500000, it will log error and resp 500 to client
-// otherwise, it will log info and resp 200 to client
+// it logs error and responses with the http status of the code, see message.GetHTTPStatus()
 func ResponseNOK(c *gin.Context, code int, values ...interface{}) {
+	ResponseNOKWithStatus(c, message.GetHTTPStatus(code), code, values...)
+}
+
+// ResponseNOKWithStatus responses with given http status, code and values, it always logs error
+func ResponseNOKWithStatus(c *gin.Context, status int, code int, values ...interface{}) {
 	msg := message.NewMessage(code, values...).Error()
-	log.Error(msg)
+	tracing.Logger(c.Request.Context()).Error(msg)
+
+	if isLegacy(c) {
+		// the old versions always response 500 except for the authentication and version conflicts
+		if status == http.StatusBadRequest || status == http.StatusNotFound {
+			status = http.StatusInternalServerError
+		}
+		c.String(status, msg)
+		return
+	}
+	// the unexpected error is caused by the timeout of the request if its context has exceeded the deadline
+	if status == http.StatusInternalServerError && c.Request.Context().Err() == context.DeadlineExceeded {
+		status = http.StatusGatewayTimeout
+	}
 
-	c.String(http.StatusInternalServerError, msg)
+	c.JSON(status, NewResponse(c, constant.EmptyString, code, msg))
 }
 
+// ResponseNOKWithData responses with given data, code and values, it is used when the data helps the clients to fix the request,
+// such as the field errors, it logs error and responses with the http status of the code
+func ResponseNOKWithData(c *gin.Context, respMessage string, code int, values ...interface{}) {
+	msg := message.NewMessage(code, values...).Error()
+	tracing.Logger(c.Request.Context()).Error(msg)
+
+	if isLegacy(c) {
+		c.String(http.StatusInternalServerError, msg)
+		return
+	}
+
+	c.JSON(message.GetHTTPStatus(code), NewResponse(c, respMessage, code, msg))
+}
+
+// ResponseOK responses 200 with given data, it logs info with given code and values
 func ResponseOK(c *gin.Context, respMessage string, code int, values ...interface{}) {
+	ResponseOKWithStatus(c, http.StatusOK, respMessage, code, values...)
+}
+
+// ResponseOKWithStatus responses with given http status and data, such as 201 when an entity is created,
+// it logs info with given code and values
+func ResponseOKWithStatus(c *gin.Context, status int, respMessage string, code int, values ...interface{}) {
+	msg := message.NewMessage(code, values...).Error()
+	tracing.Logger(c.Request.Context()).Info(msg)
+
+	if isLegacy(c) {
+		c.String(status, respMessage)
+		return
+	}
+
+	c.JSON(status, NewResponse(c, respMessage, code, msg))
+}
+
+// ResponseRaw responses 200 with given data and content type without the envelope,
+// it is used when the data is a document which is not json, such as the exported yaml or csv files
+func ResponseRaw(c *gin.Context, contentType string, data []byte, code int, values ...interface{}) {
 	msg := message.NewMessage(code, values...).Error()
-	log.Info(msg)
+	tracing.Logger(c.Request.Context()).Info(msg)
+
+	if isLegacy(c) {
+		c.String(http.StatusOK, string(data))
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
 
-	c.String(http.StatusOK, respMessage)
+// isLegacy returns if the responses should be compatible with the old clients,
+// only the v1 api responses as the old versions, the newer api always responses with the json envelope
+func isLegacy(c *gin.Context) bool {
+	return viper.GetBool(config.ServerLegacyResponseKey) && strings.HasPrefix(c.Request.URL.Path, legacyPathPrefix)
 }
@@ -0,0 +1,121 @@
+package resp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/common"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/pkg/message"
+)
+
+const testRequestID = "test-request-id"
+
+func newTestContext() (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/test", nil)
+	c.Request.Header.Set(RequestIDHeader, testRequestID)
+
+	return c, recorder
+}
+
+func TestResponseAll(t *testing.T) {
+	TestResponseOK(t)
+	TestResponseNOK(t)
+	TestResponseLegacy(t)
+}
+
+func TestResponseOK(t *testing.T) {
+	asst := assert.New(t)
+
+	viper.Set(config.ServerLegacyResponseKey, false)
+	// json data
+	c, recorder := newTestContext()
+	ResponseOK(c, `{"id": 1}`, message.InfoServerStart, 1, "pid")
+	asst.Equal(http.StatusOK, recorder.Code, "test ResponseOK() failed")
+	asst.Contains(recorder.Header().Get("Content-Type"), "application/json", "test ResponseOK() failed")
+	r := &Response{}
+	err := json.Unmarshal(recorder.Body.Bytes(), r)
+	asst.Nil(err, common.CombineMessageWithError("test ResponseOK() failed", err))
+	asst.Equal(message.InfoServerStart, r.Code, "test ResponseOK() failed")
+	asst.Equal(`{"id":1}`, string(r.Data), "test ResponseOK() failed")
+	asst.Equal(testRequestID, r.RequestID, "test ResponseOK() failed")
+	// plain text data is responded as a json string
+	c, recorder = newTestContext()
+	ResponseOK(c, "healthcheck started", message.InfoServerStart, 1, "pid")
+	err = json.Unmarshal(recorder.Body.Bytes(), r)
+	asst.Nil(err, common.CombineMessageWithError("test ResponseOK() failed", err))
+	asst.Equal(`"healthcheck started"`, string(r.Data), "test ResponseOK() failed")
+}
+
+func TestResponseNOK(t *testing.T) {
+	asst := assert.New(t)
+
+	viper.Set(config.ServerLegacyResponseKey, false)
+	// parameter errors
+	c, recorder := newTestContext()
+	ResponseNOK(c, message.ErrFieldNotExists, "id", "id")
+	asst.Equal(http.StatusBadRequest, recorder.Code, "test ResponseNOK() failed")
+	r := &Response{}
+	err := json.Unmarshal(recorder.Body.Bytes(), r)
+	asst.Nil(err, common.CombineMessageWithError("test ResponseNOK() failed", err))
+	asst.Equal(message.ErrFieldNotExists, r.Code, "test ResponseNOK() failed")
+	asst.Equal(message.NewMessage(message.ErrFieldNotExists, "id", "id").Error(), r.Message, "test ResponseNOK() failed")
+	asst.Equal("null", string(r.Data), "test ResponseNOK() failed")
+	// the codes which are not mapped
+	c, recorder = newTestContext()
+	ResponseNOK(c, message.ErrMarshalData, "error")
+	asst.Equal(http.StatusInternalServerError, recorder.Code, "test ResponseNOK() failed")
+	// the data does not exist or conflicts
+	c, recorder = newTestContext()
+	ResponseNOK(c, message.ErrDataNotExists, "error")
+	asst.Equal(http.StatusNotFound, recorder.Code, "test ResponseNOK() failed")
+	c, recorder = newTestContext()
+	ResponseNOK(c, message.ErrDataConflict, "error")
+	asst.Equal(http.StatusConflict, recorder.Code, "test ResponseNOK() failed")
+	c, recorder = newTestContext()
+	ResponseNOK(c, message.ErrDataNotMatch, "error")
+	asst.Equal(http.StatusPreconditionFailed, recorder.Code, "test ResponseNOK() failed")
+	// specified status
+	c, recorder = newTestContext()
+	ResponseNOKWithStatus(c, http.StatusNotFound, message.ErrMarshalData, "error")
+	asst.Equal(http.StatusNotFound, recorder.Code, "test ResponseNOKWithStatus() failed")
+	// the request timed out
+	c, recorder = newTestContext()
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 0)
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+	ResponseNOK(c, message.ErrMarshalData, "error")
+	asst.Equal(http.StatusGatewayTimeout, recorder.Code, "test ResponseNOK() failed")
+}
+
+func TestResponseLegacy(t *testing.T) {
+	asst := assert.New(t)
+
+	viper.Set(config.ServerLegacyResponseKey, true)
+	defer viper.Set(config.ServerLegacyResponseKey, false)
+
+	c, recorder := newTestContext()
+	ResponseOK(c, `{"id": 1}`, message.InfoServerStart, 1, "pid")
+	asst.Equal(`{"id": 1}`, recorder.Body.String(), "test ResponseOK() failed")
+	asst.Contains(recorder.Header().Get("Content-Type"), "text/plain", "test ResponseOK() failed")
+	c, recorder = newTestContext()
+	ResponseNOK(c, message.ErrFieldNotExists, "id", "id")
+	asst.Equal(http.StatusInternalServerError, recorder.Code, "test ResponseNOK() failed")
+	asst.Equal(message.NewMessage(message.ErrFieldNotExists, "id", "id").Error(), recorder.Body.String(), "test ResponseNOK() failed")
+	// the legacy response does not affect the v2 api
+	c, recorder = newTestContext()
+	c.Request.URL.Path = "/api/v2/metadata/envs"
+	ResponseOKWithStatus(c, http.StatusCreated, `{"id": 1}`, message.InfoServerStart, 1, "pid")
+	asst.Equal(http.StatusCreated, recorder.Code, "test ResponseOKWithStatus() failed")
+	asst.Contains(recorder.Header().Get("Content-Type"), "application/json", "test ResponseOKWithStatus() failed")
+}
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// RequestIDHeader is the request header which identifies the request
+	RequestIDHeader = "X-Request-Id"
+
+	requestIDBytes     = 16
+	maxRequestIDLength = 128
+
+	requestIDField = "request_id"
+	traceIDField   = "trace_id"
+	spanIDField    = "span_id"
+)
+
+// requestIDKey is the key of the request id in the context
+type requestIDKey struct{}
+
+// NewRequestID returns a new random request id which contains 32 hex characters
+func NewRequestID() string {
+	b := make([]byte, requestIDBytes)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// NewContextWithRequestID returns a copy of the context which carries the request id
+func NewContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// GetRequestID returns the request id which the context carries, it returns empty string if there is no request id
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+
+	return requestID
+}
+
+// Detach returns a copy of parent which carries the request id and the span of ctx,
+// it is used by the operations which run asynchronously and outlive the request,
+// so that they are still logged and traced as a part of the request but not canceled with it
+func Detach(parent, ctx context.Context) context.Context {
+	requestID := GetRequestID(ctx)
+	if requestID != constant.EmptyString {
+		parent = NewContextWithRequestID(parent, requestID)
+	}
+
+	return trace.ContextWithSpan(parent, trace.SpanFromContext(ctx))
+}
+
+// RequestID returns the middleware which identifies the request by the X-Request-Id header,
+// it generates a new request id if the header is empty or too long,
+// the request id is carried by the context of the request and responded by the X-Request-Id header,
+// it is also set to the request header, so that the handlers which read the header get the generated one as well
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == constant.EmptyString || len(requestID) > maxRequestIDLength {
+			requestID = NewRequestID()
+			c.Request.Header.Set(RequestIDHeader, requestID)
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(NewContextWithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
+
+// Logger returns the logger which logs the request id, the trace id and the span id of the context as the fields,
+// it returns the global logger if the context carries none of them
+func Logger(ctx context.Context) *log.Logger {
+	fields := getLogFields(ctx)
+	if len(fields) == constant.ZeroInt {
+		return log.L()
+	}
+
+	// log.With() returns the logger which skips the caller of the global logger,
+	// it is skipped by the returned logger again, so the extra skip is removed here
+	return log.NewMyLogger(log.With(fields...).WithOptions(zap.AddCallerSkip(-log.DefaultCallerSkip)))
+}
+
+// getLogFields returns the log fields of the request id, the trace id and the span id of the context
+func getLogFields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+	requestID := GetRequestID(ctx)
+	if requestID != constant.EmptyString {
+		fields = append(fields, zap.String(requestIDField, requestID))
+	}
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		fields = append(fields, zap.String(traceIDField, spanContext.TraceID().String()), zap.String(spanIDField, spanContext.SpanID().String()))
+	}
+
+	return fields
+}
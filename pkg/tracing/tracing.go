@@ -0,0 +1,144 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/constant"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/romberli/das/config"
+)
+
+const (
+	tracerName  = "github.com/romberli/das"
+	serviceName = "das"
+
+	unmatchedRoute = "unmatched"
+
+	requestIDAttribute = attribute.Key("das.request_id")
+	backendAttribute   = attribute.Key("das.backend")
+	dbIDAttribute      = attribute.Key("das.db_id")
+)
+
+// provider is the tracer provider which exports the spans, it is nil if tracing is disabled
+var provider *sdktrace.TracerProvider
+
+// Init initializes the global tracer provider with the trace section of the configuration,
+// the spans are not exported if tracing is disabled, but the trace context is still propagated
+func Init() error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !viper.GetBool(config.TraceEnabledKey) {
+		return nil
+	}
+
+	exporter, err := newExporter(viper.GetString(config.TraceExporterKey))
+	if err != nil {
+		return err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return err
+	}
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(viper.GetFloat64(config.TraceSampleRatioKey)))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return nil
+}
+
+// newExporter returns the span exporter of given type
+func newExporter(exporterType string) (sdktrace.SpanExporter, error) {
+	if exporterType == config.TraceExporterOTLP {
+		return NewOTLPExporter(context.Background(), viper.GetString(config.TraceOTLPEndpointKey))
+	}
+
+	return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+}
+
+// Shutdown exports the remaining spans and shuts down the tracer provider, it does nothing if tracing is disabled
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+
+	return provider.Shutdown(ctx)
+}
+
+// Tracer returns the tracer of das
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// StartSpan starts a span of given kind as the child of the span of the context
+func StartSpan(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// StartSQLSpan starts a client span of the sql execution on the das metadata database
+func StartSQLSpan(ctx context.Context, sql string) (context.Context, trace.Span) {
+	return StartSpan(ctx, "mysql execute", trace.SpanKindClient, semconv.DBSystemMySQL, semconv.DBStatementKey.String(sql))
+}
+
+// StartBackendSpan starts a client span of the query to the monitor backend
+func StartBackendSpan(ctx context.Context, backend, query string) (context.Context, trace.Span) {
+	return StartSpan(ctx, backend+" query", trace.SpanKindClient, backendAttribute.String(backend), semconv.DBStatementKey.String(query))
+}
+
+// StartSoarSpan starts a span of the soar execution which advises the sql of given database,
+// the command is not recorded because the online dsn contains the password
+func StartSoarSpan(ctx context.Context, dbID int) (context.Context, trace.Span) {
+	return StartSpan(ctx, "soar advise", trace.SpanKindInternal, dbIDAttribute.Int(dbID))
+}
+
+// EndSpan records the error if it is not nil, and then ends the span
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Middleware returns the middleware which starts a server span for each http request,
+// the span continues the trace of the traceparent header if it is specified,
+// it must be used after the request id middleware, so that the span could be correlated with the logs
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		route := c.FullPath()
+		if route == constant.EmptyString {
+			route = unmatchedRoute
+		}
+		ctx, span := StartSpan(ctx, c.Request.Method+" "+route, trace.SpanKindServer,
+			semconv.HTTPMethodKey.String(c.Request.Method),
+			semconv.HTTPRouteKey.String(route),
+			semconv.HTTPTargetKey.String(c.Request.URL.RequestURI()),
+			requestIDAttribute.String(GetRequestID(ctx)),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const testTraceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+func TestTracingAll(t *testing.T) {
+	TestRequestID(t)
+	TestDetach(t)
+	TestLogger(t)
+	TestMiddleware(t)
+	TestEndSpan(t)
+	TestOTLPExporter(t)
+}
+
+// initTestProvider sets the global tracer provider which records the ended spans
+func initTestProvider() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return recorder
+}
+
+func TestRequestID(t *testing.T) {
+	asst := assert.New(t)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(RequestID())
+	engine.GET("/test", func(c *gin.Context) {
+		// the handlers get the same request id from the context and the header
+		asst.Equal(c.GetHeader(RequestIDHeader), GetRequestID(c.Request.Context()), "test RequestID() failed")
+		c.Status(http.StatusOK)
+	})
+
+	// the request id of the header is kept
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(RequestIDHeader, "test-request-id")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	asst.Equal("test-request-id", w.Header().Get(RequestIDHeader), "test RequestID() failed")
+
+	// the request id is generated if the header is empty or too long
+	for _, requestID := range []string{"", strings.Repeat("a", maxRequestIDLength+1)} {
+		req = httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set(RequestIDHeader, requestID)
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		asst.Len(w.Header().Get(RequestIDHeader), requestIDBytes*2, "test RequestID() failed")
+	}
+
+	asst.NotEqual(NewRequestID(), NewRequestID(), "test NewRequestID() failed")
+	asst.Equal("", GetRequestID(context.Background()), "test GetRequestID() failed")
+}
+
+func TestDetach(t *testing.T) {
+	asst := assert.New(t)
+
+	initTestProvider()
+	ctx, span := StartSpan(NewContextWithRequestID(context.Background(), "test-request-id"), "test", trace.SpanKindInternal)
+	defer span.End()
+	ctx, cancel := context.WithCancel(ctx)
+	detached := Detach(context.Background(), ctx)
+	cancel()
+	// the detached context is not canceled with ctx, but carries its request id and span
+	asst.Nil(detached.Err(), "test Detach() failed")
+	asst.Equal("test-request-id", GetRequestID(detached), "test Detach() failed")
+	asst.Equal(span.SpanContext(), trace.SpanContextFromContext(detached), "test Detach() failed")
+	asst.Equal("", GetRequestID(Detach(context.Background(), context.Background())), "test Detach() failed")
+}
+
+func TestLogger(t *testing.T) {
+	asst := assert.New(t)
+
+	initTestProvider()
+	asst.Len(getLogFields(context.Background()), 0, "test getLogFields() failed")
+	asst.NotNil(Logger(context.Background()), "test Logger() failed")
+
+	ctx, span := StartSpan(NewContextWithRequestID(context.Background(), "test-request-id"), "test", trace.SpanKindInternal)
+	defer span.End()
+	fields := getLogFields(ctx)
+	asst.Len(fields, 3, "test getLogFields() failed")
+	asst.Equal("test-request-id", fields[0].String, "test getLogFields() failed")
+	asst.Equal(span.SpanContext().TraceID().String(), fields[1].String, "test getLogFields() failed")
+	asst.Equal(span.SpanContext().SpanID().String(), fields[2].String, "test getLogFields() failed")
+	asst.NotNil(Logger(ctx), "test Logger() failed")
+}
+
+func TestMiddleware(t *testing.T) {
+	asst := assert.New(t)
+
+	recorder := initTestProvider()
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(RequestID(), Middleware())
+	engine.GET("/api/v2/metadata/apps/:id", func(c *gin.Context) {
+		_, span := StartSQLSpan(c.Request.Context(), "select 1")
+		EndSpan(span, nil)
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/metadata/apps/1", nil)
+	req.Header.Set("traceparent", testTraceParent)
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	asst.Len(spans, 2, "test Middleware() failed")
+	sqlSpan, serverSpan := spans[0], spans[1]
+	// the server span continues the trace of the traceparent header
+	asst.Equal("0af7651916cd43dd8448eb211c80319c", serverSpan.SpanContext().TraceID().String(), "test Middleware() failed")
+	asst.Equal("GET /api/v2/metadata/apps/:id", serverSpan.Name(), "test Middleware() failed")
+	asst.Equal(codes.Error, serverSpan.Status().Code, "test Middleware() failed")
+	// the sql span is the child of the server span
+	asst.Equal(serverSpan.SpanContext().SpanID(), sqlSpan.Parent().SpanID(), "test Middleware() failed")
+	asst.Equal("mysql execute", sqlSpan.Name(), "test Middleware() failed")
+}
+
+func TestEndSpan(t *testing.T) {
+	asst := assert.New(t)
+
+	recorder := initTestProvider()
+	_, span := StartBackendSpan(context.Background(), "prometheus", "up")
+	EndSpan(span, errors.New("test error"))
+	_, span = StartSoarSpan(context.Background(), 1)
+	EndSpan(span, nil)
+
+	spans := recorder.Ended()
+	asst.Len(spans, 2, "test EndSpan() failed")
+	asst.Equal(codes.Error, spans[0].Status().Code, "test EndSpan() failed")
+	asst.Equal("test error", spans[0].Status().Description, "test EndSpan() failed")
+	asst.Len(spans[0].Events(), 1, "test EndSpan() failed")
+	asst.Equal(codes.Unset, spans[1].Status().Code, "test EndSpan() failed")
+}
+
+func TestOTLPExporter(t *testing.T) {
+	asst := assert.New(t)
+
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asst.Equal("application/x-protobuf", r.Header.Get("Content-Type"), "test ExportSpans() failed")
+		asst.Equal("/v1/traces", r.URL.Path, "test ExportSpans() failed")
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	recorder := initTestProvider()
+	ctx, parent := StartSpan(context.Background(), "parent", trace.SpanKindInternal)
+	_, child := StartSQLSpan(ctx, "select 1")
+	EndSpan(child, errors.New("test error"))
+	parent.End()
+
+	exporter, err := NewOTLPExporter(context.Background(), server.URL+"/v1/traces")
+	asst.Nil(err, common.CombineMessageWithError("test NewOTLPExporter() failed", err))
+	err = exporter.ExportSpans(context.Background(), recorder.Ended())
+	asst.Nil(err, common.CombineMessageWithError("test ExportSpans() failed", err))
+
+	req := &coltracepb.ExportTraceServiceRequest{}
+	err = proto.Unmarshal(body, req)
+	asst.Nil(err, common.CombineMessageWithError("test ExportSpans() failed", err))
+	asst.Len(req.ResourceSpans, 1, "test ExportSpans() failed")
+	asst.Len(req.ResourceSpans[0].ScopeSpans, 1, "test ExportSpans() failed")
+	asst.Equal(tracerName, req.ResourceSpans[0].ScopeSpans[0].Scope.Name, "test ExportSpans() failed")
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	asst.Len(spans, 2, "test ExportSpans() failed")
+	traceID := parent.SpanContext().TraceID()
+	spanID := parent.SpanContext().SpanID()
+	asst.Equal(traceID[:], spans[0].TraceId, "test ExportSpans() failed")
+	asst.Equal(spanID[:], spans[0].ParentSpanId, "test ExportSpans() failed")
+	asst.Equal(tracepb.Status_STATUS_CODE_ERROR, spans[0].Status.Code, "test ExportSpans() failed")
+	asst.Equal(uint64(recorder.Ended()[0].StartTime().UnixNano()), spans[0].StartTimeUnixNano, "test ExportSpans() failed")
+
+	// the endpoint responds with non-2xx status
+	errServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer errServer.Close()
+	errExporter, err := NewOTLPExporter(context.Background(), errServer.URL+"/v1/traces")
+	asst.Nil(err, common.CombineMessageWithError("test NewOTLPExporter() failed", err))
+	err = errExporter.ExportSpans(context.Background(), recorder.Ended())
+	asst.NotNil(err, "test ExportSpans() failed")
+
+	// the spans are not exported after shutdown
+	err = exporter.Shutdown(context.Background())
+	asst.Nil(err, common.CombineMessageWithError("test Shutdown() failed", err))
+	body = nil
+	err = exporter.ExportSpans(context.Background(), recorder.Ended())
+	asst.NotNil(err, "test ExportSpans() failed")
+	asst.Nil(body, "test ExportSpans() failed")
+}
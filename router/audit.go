@@ -0,0 +1,14 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/api/v1/audit"
+)
+
+func RegisterAudit(group *gin.RouterGroup) {
+	auditGroup := group.Group("/audit")
+	{
+		auditGroup.GET("", audit.GetAudit)
+		auditGroup.GET("/:entity_type/:id", audit.GetAuditByEntity)
+	}
+}
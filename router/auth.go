@@ -0,0 +1,21 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/romberli/das/api/v1/auth"
+)
+
+// RegisterAuth registers the auth apis, the login api is the only one which does not need authentication
+func RegisterAuth(group *gin.RouterGroup, authenticate gin.HandlerFunc) {
+	authGroup := group.Group("/auth")
+	{
+		authGroup.POST("/login", auth.Login)
+	}
+	tokenGroup := authGroup.Group("/token", authenticate)
+	{
+		tokenGroup.GET("", auth.GetTokens)
+		tokenGroup.POST("", auth.CreateToken)
+		tokenGroup.POST("/revoke/:id", auth.RevokeToken)
+	}
+}
@@ -0,0 +1,14 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/api/v1/discovery"
+)
+
+func RegisterDiscovery(group *gin.RouterGroup) {
+	discoveryGroup := group.Group("/discovery")
+	{
+		discoveryGroup.POST("/seed", discovery.DiscoverBySeed)
+		discoveryGroup.POST("/monitor-system/:monitor_system_id", discovery.DiscoverByMonitorSystem)
+	}
+}
@@ -4,11 +4,16 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/romberli/das/api/v1/metadata"
+	metadatav2 "github.com/romberli/das/api/v2/metadata"
 )
 
 func RegisterMetadata(group *gin.RouterGroup) {
 	metadataGroup := group.Group("/metadata")
 	{
+		// inventory
+		metadataGroup.POST("/import", metadata.Import)
+		metadataGroup.GET("/export", metadata.Export)
+		metadataGroup.POST("/reconcile", metadata.Reconcile)
 		// app
 		metadataGroup.GET("/app", metadata.GetApp)
 		metadataGroup.GET("/app/get/:id", metadata.GetAppByID)
@@ -17,6 +22,7 @@ func RegisterMetadata(group *gin.RouterGroup) {
 		metadataGroup.POST("/app", metadata.AddApp)
 		metadataGroup.POST("/app/update/:id", metadata.UpdateAppByID)
 		metadataGroup.POST("/app/delete/:id", metadata.DeleteAppByID)
+		metadataGroup.POST("/app/undelete/:id", metadata.UndeleteAppByID)
 		metadataGroup.POST("/app/add-db/:id", metadata.AppAddDB)
 		metadataGroup.POST("/app/delete-db/:id", metadata.AppDeleteDB)
 		// db
@@ -28,6 +34,7 @@ func RegisterMetadata(group *gin.RouterGroup) {
 		metadataGroup.POST("/db", metadata.AddDB)
 		metadataGroup.POST("/db/update/:id", metadata.UpdateDBByID)
 		metadataGroup.POST("/db/delete/:id", metadata.DeleteDBByID)
+		metadataGroup.POST("/db/undelete/:id", metadata.UndeleteDBByID)
 		metadataGroup.POST("/db/add-app/:id", metadata.DBAddApp)
 		metadataGroup.POST("/db/delete-app/:id", metadata.DBDeleteApp)
 		// env
@@ -37,6 +44,7 @@ func RegisterMetadata(group *gin.RouterGroup) {
 		metadataGroup.POST("/env", metadata.AddEnv)
 		metadataGroup.POST("/env/update/:id", metadata.UpdateEnvByID)
 		metadataGroup.POST("/env/delete/:id", metadata.DeleteEnvByID)
+		metadataGroup.POST("/env/undelete/:id", metadata.UndeleteEnvByID)
 		// middleware cluster
 		metadataGroup.GET("/middleware-cluster", metadata.GetMiddlewareCluster)
 		metadataGroup.GET("/middleware-cluster/env/:env_id", metadata.GetMiddlewareClusterByEnv)
@@ -46,6 +54,7 @@ func RegisterMetadata(group *gin.RouterGroup) {
 		metadataGroup.POST("/middleware-cluster", metadata.AddMiddlewareCluster)
 		metadataGroup.POST("/middleware-cluster/update/:id", metadata.UpdateMiddlewareClusterByID)
 		metadataGroup.POST("/middleware-cluster/delete/:id", metadata.DeleteMiddlewareClusterByID)
+		metadataGroup.POST("/middleware-cluster/undelete/:id", metadata.UndeleteMiddlewareClusterByID)
 		// middleware server
 		metadataGroup.GET("/middleware-server", metadata.GetMiddlewareServer)
 		metadataGroup.GET("/middleware-server/cluster-id/:cluster_id", metadata.GetMiddlewareServerByClusterID)
@@ -54,6 +63,7 @@ func RegisterMetadata(group *gin.RouterGroup) {
 		metadataGroup.POST("/middleware-server", metadata.AddMiddlewareServer)
 		metadataGroup.POST("/middleware-server/update/:id", metadata.UpdateMiddlewareServerByID)
 		metadataGroup.POST("/middleware-server/delete/:id", metadata.DeleteMiddlewareServerByID)
+		metadataGroup.POST("/middleware-server/undelete/:id", metadata.UndeleteMiddlewareServerByID)
 		// monitor system
 		metadataGroup.GET("/monitor-system", metadata.GetMonitorSystem)
 		metadataGroup.GET("/monitor-system/env/:env_id", metadata.GetMonitorSystemByEnv)
@@ -62,6 +72,7 @@ func RegisterMetadata(group *gin.RouterGroup) {
 		metadataGroup.POST("/monitor-system", metadata.AddMonitorSystem)
 		metadataGroup.POST("/monitor-system/update/:id", metadata.UpdateMonitorSystemByID)
 		metadataGroup.POST("/monitor-system/delete/:id", metadata.DeleteMonitorSystemByID)
+		metadataGroup.POST("/monitor-system/undelete/:id", metadata.UndeleteMonitorSystemByID)
 		// mysql cluster
 		metadataGroup.GET("/mysql-cluster", metadata.GetMySQLCluster)
 		metadataGroup.GET("/mysql-cluster/env/:env_id", metadata.GetMySQLClusterByEnv)
@@ -71,6 +82,7 @@ func RegisterMetadata(group *gin.RouterGroup) {
 		metadataGroup.POST("/mysql-cluster", metadata.AddMySQLCluster)
 		metadataGroup.POST("/mysql-cluster/update/:id", metadata.UpdateMySQLClusterByID)
 		metadataGroup.POST("/mysql-cluster/delete/:id", metadata.DeleteMySQLClusterByID)
+		metadataGroup.POST("/mysql-cluster/undelete/:id", metadata.UndeleteMySQLClusterByID)
 		// mysql server
 		metadataGroup.GET("/mysql-server", metadata.GetMySQLServer)
 		metadataGroup.GET("/mysql-server/cluster-id/:cluster_id", metadata.GetMySQLServerByClusterID)
@@ -79,6 +91,7 @@ func RegisterMetadata(group *gin.RouterGroup) {
 		metadataGroup.POST("/mysql-server", metadata.AddMySQLServer)
 		metadataGroup.POST("/mysql-server/update/:id", metadata.UpdateMySQLServerByID)
 		metadataGroup.POST("/mysql-server/delete/:id", metadata.DeleteMySQLServerByID)
+		metadataGroup.POST("/mysql-server/undelete/:id", metadata.UndeleteMySQLServerByID)
 		// user
 		metadataGroup.GET("/user", metadata.GetUser)
 		metadataGroup.GET("/user/user-name/:user_name", metadata.GetUserByName)
@@ -91,5 +104,16 @@ func RegisterMetadata(group *gin.RouterGroup) {
 		metadataGroup.POST("/user", metadata.AddUser)
 		metadataGroup.POST("/user/update/:id", metadata.UpdateUserByID)
 		metadataGroup.POST("/user/delete/:id", metadata.DeleteUserByID)
+		metadataGroup.POST("/user/undelete/:id", metadata.UndeleteUserByID)
+	}
+}
+
+func RegisterMetadataV2(group *gin.RouterGroup) {
+	metadataGroup := group.Group("/metadata")
+	{
+		// the routes of all the resources and the relations, see metadatav2.GetRoutes()
+		for _, route := range metadatav2.GetRoutes() {
+			metadataGroup.Handle(route.Method, route.Path, route.Handler)
+		}
 	}
 }
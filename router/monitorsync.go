@@ -0,0 +1,13 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/api/v1/monitorsync"
+)
+
+func RegisterMonitorSync(group *gin.RouterGroup) {
+	monitorSyncGroup := group.Group("/monitorsync")
+	{
+		monitorSyncGroup.POST("/sync/:monitor_system_id", monitorsync.SyncByMonitorSystem)
+	}
+}
@@ -0,0 +1,14 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/romberli/das/api/v1/probe"
+)
+
+// RegisterProbe is the sub-router of das for the liveness and readiness probes,
+// the probes are public so that the orchestrators could call them without credentials
+func RegisterProbe(engine *gin.Engine) {
+	engine.GET("/healthz", probe.Live)
+	engine.GET("/readyz", probe.Ready)
+}
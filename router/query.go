@@ -0,0 +1,16 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/api/v1/query"
+)
+
+// RegisterQuery is the sub-router of das for slow query workload analysis
+func RegisterQuery(group *gin.RouterGroup) {
+	queryGroup := group.Group("/query")
+	{
+		queryGroup.GET("/slow/top", query.GetTop)
+		queryGroup.GET("/slow/trend", query.GetTrend)
+		queryGroup.GET("/slow/regression", query.GetRegression)
+	}
+}
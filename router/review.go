@@ -0,0 +1,15 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/api/v1/review"
+)
+
+func RegisterReview(group *gin.RouterGroup) {
+	reviewGroup := group.Group("/review")
+	{
+		reviewGroup.POST("/submit/:db_id", review.Review)
+		reviewGroup.GET("/get/:id", review.GetByID)
+		reviewGroup.POST("/approve/:id", review.Approve)
+	}
+}
@@ -2,16 +2,40 @@ package router
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/romberli/log"
+	"github.com/spf13/viper"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/romberli/das/api/v1/auth"
+	metadatav2 "github.com/romberli/das/api/v2/metadata"
+	"github.com/romberli/das/config"
 	_ "github.com/romberli/das/docs"
+	"github.com/romberli/das/pkg/metrics"
+	"github.com/romberli/das/pkg/request"
+	"github.com/romberli/das/pkg/tracing"
 )
 
+// longRequestRoutes are the routes which may take long, they use the long request timeout instead of the default one,
+// such as invoking soar, querying prometheus and the monitor systems, and importing or exporting the whole inventory
+var longRequestRoutes = []string{
+	"/api/v1/metadata/import",
+	"/api/v1/metadata/export",
+	"/api/v1/metadata/reconcile",
+	"/api/v1/sqladvisor/advise/:db_id",
+	"/api/v1/query/slow/top",
+	"/api/v1/query/slow/trend",
+	"/api/v1/query/slow/regression",
+	"/api/v1/review/submit/:db_id",
+	"/api/v1/discovery/seed",
+	"/api/v1/discovery/monitor-system/:monitor_system_id",
+	"/api/v1/monitorsync/sync/:monitor_system_id",
+}
+
 type Router interface {
 	http.Handler
 	Register()
@@ -35,11 +59,24 @@ func (gr *GinRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 }
 
 func (gr *GinRouter) Register() {
+	// the middlewares must be used before registering the routes, so that they apply to all of them,
+	// the request id middleware must be the first one, so that the spans and the logs could be correlated by the request id
+	gr.Engine.Use(tracing.RequestID(), tracing.Middleware(), metrics.Middleware(), gr.timeout())
+	// metrics
+	gr.Metrics()
+	// probe
+	RegisterProbe(gr.Engine)
 	// swagger
 	gr.Swagger()
 
+	// the middleware is shared by all the groups, so that the authenticators are initialized only once
+	authenticate := auth.Authenticate()
+
 	api := gr.Engine.Group("/api")
-	v1 := api.Group("/v1")
+	// auth
+	RegisterAuth(api.Group("/v1"), authenticate)
+	// all the other apis need authentication and authorization
+	v1 := api.Group("/v1", authenticate, auth.Authorize())
 	{
 		// metadata
 		RegisterMetadata(v1)
@@ -47,6 +84,25 @@ func (gr *GinRouter) Register() {
 		RegisterHealthcheck(v1)
 		// sqladvisor
 		RegisterSQLAdvisor(v1)
+		// query
+		RegisterQuery(v1)
+		// review
+		RegisterReview(v1)
+		// discovery
+		RegisterDiscovery(v1)
+		// monitorsync
+		RegisterMonitorSync(v1)
+		// topology
+		RegisterTopology(v1)
+		// audit
+		RegisterAudit(v1)
+	}
+	// the openapi specification of v2 is public as the swagger of v1
+	api.GET("/v2/openapi.json", metadatav2.GetOpenAPI)
+	v2 := api.Group("/v2", authenticate, auth.Authorize())
+	{
+		// metadata
+		RegisterMetadataV2(v2)
 	}
 }
 
@@ -61,3 +117,19 @@ func (gr *GinRouter) Swagger() {
 		swaggerGroup.GET("/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
 	}
 }
+
+// timeout returns the middleware which derives the context of the requests with the timeouts of the routes
+func (gr *GinRouter) timeout() gin.HandlerFunc {
+	longRequestTimeout := time.Duration(viper.GetInt(config.ServerLongRequestTimeoutKey)) * time.Second
+	routeTimeouts := make(map[string]time.Duration, len(longRequestRoutes))
+	for _, route := range longRequestRoutes {
+		routeTimeouts[route] = longRequestTimeout
+	}
+
+	return request.Timeout(time.Duration(viper.GetInt(config.ServerRequestTimeoutKey))*time.Second, routeTimeouts)
+}
+
+// Metrics registers the prometheus metrics of das itself, it is public so that prometheus could scrape it without credentials
+func (gr *GinRouter) Metrics() {
+	gr.Engine.GET(metrics.Path, metrics.Handler())
+}
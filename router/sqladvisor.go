@@ -10,6 +10,9 @@ func RegisterSQLAdvisor(group *gin.RouterGroup) {
 	{
 		sqladvisorGroup.GET("/fingerprint", sqladvisor.GetFingerprint)
 		sqladvisorGroup.GET("/sql-id", sqladvisor.GetSQLID)
+		sqladvisorGroup.POST("/fingerprint", sqladvisor.BatchGetFingerprint)
+		sqladvisorGroup.POST("/sql-id", sqladvisor.BatchGetSQLID)
 		sqladvisorGroup.POST("/advise/:db_id", sqladvisor.Advise)
+		sqladvisorGroup.GET("/advice/:sql_id", sqladvisor.GetAdviceBySQLID)
 	}
 }
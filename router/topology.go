@@ -0,0 +1,14 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/api/v1/topology"
+)
+
+func RegisterTopology(group *gin.RouterGroup) {
+	topologyGroup := group.Group("/topology")
+	{
+		topologyGroup.GET("/graph/:node_type/:id", topology.GetGraph)
+		topologyGroup.GET("/impact/mysql-server/:id", topology.GetImpact)
+	}
+}
@@ -16,18 +16,23 @@ limitations under the License.
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/linux"
 	"github.com/romberli/log"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/app/healthcheck"
 	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/das/pkg/tracing"
 	"github.com/romberli/das/router"
 )
 
@@ -42,7 +47,7 @@ type Server interface {
 	Register()
 	// Run runs server
 	Run()
-	// Stop stops server
+	// Stop stops server gracefully
 	Stop()
 }
 
@@ -50,13 +55,14 @@ var _ Server = (*server)(nil)
 
 type server struct {
 	*http.Server
-	addr    string
-	pidFile string
-	router  router.Router
+	addr            string
+	pidFile         string
+	shutdownTimeout time.Duration
+	router          router.Router
 }
 
 // NewServer returns new *server
-func NewServer(addr string, pidFile string, readTimeout, writeTimeout int, router router.Router) *server {
+func NewServer(addr string, pidFile string, readTimeout, writeTimeout, shutdownTimeout int, router router.Router) *server {
 	return &server{
 		Server: &http.Server{
 			Addr:         addr,
@@ -64,21 +70,22 @@ func NewServer(addr string, pidFile string, readTimeout, writeTimeout int, route
 			ReadTimeout:  time.Duration(readTimeout) * time.Second,
 			WriteTimeout: time.Duration(writeTimeout) * time.Second,
 		},
-		addr:    addr,
-		pidFile: pidFile,
-		router:  router,
+		addr:            addr,
+		pidFile:         pidFile,
+		shutdownTimeout: time.Duration(shutdownTimeout) * time.Second,
+		router:          router,
 	}
 }
 
 // NewServerWithDefaultRouter returns new *server with default gin router
-func NewServerWithDefaultRouter(addr string, pidFile string, readTimeout, writeTimeout int) *server {
+func NewServerWithDefaultRouter(addr string, pidFile string, readTimeout, writeTimeout, shutdownTimeout int) *server {
 	if log.GetLevel() != zapcore.DebugLevel {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	r := router.NewGinRouter()
 
-	return NewServer(addr, pidFile, readTimeout, writeTimeout, r)
+	return NewServer(addr, pidFile, readTimeout, writeTimeout, shutdownTimeout, r)
 }
 
 // Addr returns listen address
@@ -91,6 +98,11 @@ func (s *server) PidFile() string {
 	return s.pidFile
 }
 
+// ShutdownTimeout returns how long the server waits for the running requests and operations when it stops
+func (s *server) ShutdownTimeout() time.Duration {
+	return s.shutdownTimeout
+}
+
 // Router returns router
 func (s *server) Router() router.Router {
 	return s.router
@@ -101,22 +113,54 @@ func (s *server) Register() {
 	s.router.Register()
 }
 
-// Run runs server
+// Run runs server with the read and write timeouts, it blocks until the server is stopped
 func (s *server) Run() {
 	fmt.Println(fmt.Sprintf("server started. addr: %s, pid file: %s", s.addr, s.pidFile))
 
-	err := s.router.Run(s.addr)
-	if err != nil {
+	err := s.Server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
 		log.Errorf("server run failed.\n%s", err.Error())
 	}
 }
 
-// Stop stops server
+// Stop stops server gracefully, it stops accepting new requests, waits for the running requests and healthcheck operations
+// until the shutdown timeout, marks the unfinished operations as interrupted, then closes the connection pool,
+// exports the remaining spans and removes the pid file
 func (s *server) Stop() {
-	err := linux.RemovePidFile(s.pidFile)
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	err := s.Server.Shutdown(ctx)
+	if err != nil {
+		log.Error(message.NewMessage(message.ErrShutdownServer, err.Error()).Error())
+	}
+	err = healthcheck.WaitRunningOperations(ctx)
+	if err != nil {
+		log.Error(err.Error())
+	}
+
+	if global.DASMySQLPool != nil {
+		err = global.DASMySQLPool.Close()
+		if err != nil {
+			log.Error(message.NewMessage(message.ErrCloseConnectionPool, err.Error()).Error())
+		}
+	}
+
+	err = tracing.Shutdown(ctx)
+	if err != nil {
+		log.Error(message.NewMessage(message.ErrShutdownTracer, err.Error()).Error())
+	}
+
+	err = linux.RemovePidFile(s.pidFile)
 	if err != nil {
 		log.Error(fmt.Sprintf("%s\n%s", message.Messages[message.ErrRemovePidFile].Error(), err.Error()))
 	}
+}
+
+// WaitForSignal blocks until the process receives SIGINT, SIGHUP or SIGTERM, and returns the signal
+func WaitForSignal() os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM)
 
-	os.Exit(constant.DefaultNormalExitCode)
+	return <-signals
 }
@@ -0,0 +1,46 @@
+alter table t_meta_app_info
+    add column `is_alive` tinyint(4) GENERATED ALWAYS AS (if(`del_flag` = 0, 1, null)) VIRTUAL COMMENT '是否未删除: 1-未删除, NULL-已删除, 用于唯一索引忽略已删除的行' after `del_flag`,
+    drop index `idx01_app_name`,
+    add unique key `idx01_app_name` (`app_name`, `is_alive`);
+alter table t_meta_db_info
+    add column `is_alive` tinyint(4) GENERATED ALWAYS AS (if(`del_flag` = 0, 1, null)) VIRTUAL COMMENT '是否未删除: 1-未删除, NULL-已删除, 用于唯一索引忽略已删除的行' after `del_flag`,
+    drop index `idx01_db_name_cluster_id_cluster_type`,
+    add unique key `idx01_db_name_cluster_id_cluster_type` (`db_name`, `cluster_id`, `cluster_type`, `is_alive`);
+alter table t_meta_env_info
+    add column `is_alive` tinyint(4) GENERATED ALWAYS AS (if(`del_flag` = 0, 1, null)) VIRTUAL COMMENT '是否未删除: 1-未删除, NULL-已删除, 用于唯一索引忽略已删除的行' after `del_flag`,
+    drop index `idx01_env_name`,
+    add unique key `idx01_env_name` (`env_name`, `is_alive`);
+alter table t_meta_middleware_cluster_info
+    add column `is_alive` tinyint(4) GENERATED ALWAYS AS (if(`del_flag` = 0, 1, null)) VIRTUAL COMMENT '是否未删除: 1-未删除, NULL-已删除, 用于唯一索引忽略已删除的行' after `del_flag`,
+    drop index `idx01_cluster_name`,
+    add unique key `idx01_cluster_name` (`cluster_name`, `is_alive`);
+alter table t_meta_middleware_server_info
+    add column `is_alive` tinyint(4) GENERATED ALWAYS AS (if(`del_flag` = 0, 1, null)) VIRTUAL COMMENT '是否未删除: 1-未删除, NULL-已删除, 用于唯一索引忽略已删除的行' after `del_flag`,
+    drop index `idx01_host_ip_port_num`,
+    add unique key `idx01_host_ip_port_num` (`host_ip`, `port_num`, `is_alive`);
+alter table t_meta_monitor_system_info
+    add column `is_alive` tinyint(4) GENERATED ALWAYS AS (if(`del_flag` = 0, 1, null)) VIRTUAL COMMENT '是否未删除: 1-未删除, NULL-已删除, 用于唯一索引忽略已删除的行' after `del_flag`,
+    drop index `idx01_system_name`,
+    drop index `idx02_host_ip_port_num`,
+    add unique key `idx01_system_name` (`system_name`, `is_alive`),
+    add unique key `idx02_host_ip_port_num` (`host_ip`, `port_num`, `is_alive`);
+alter table t_meta_mysql_cluster_info
+    add column `is_alive` tinyint(4) GENERATED ALWAYS AS (if(`del_flag` = 0, 1, null)) VIRTUAL COMMENT '是否未删除: 1-未删除, NULL-已删除, 用于唯一索引忽略已删除的行' after `del_flag`,
+    drop index `idx01_cluster_name`,
+    add unique key `idx01_cluster_name` (`cluster_name`, `is_alive`);
+alter table t_meta_mysql_server_info
+    add column `is_alive` tinyint(4) GENERATED ALWAYS AS (if(`del_flag` = 0, 1, null)) VIRTUAL COMMENT '是否未删除: 1-未删除, NULL-已删除, 用于唯一索引忽略已删除的行' after `del_flag`,
+    drop index `idx02_host_ip_port_num`,
+    add unique key `idx02_host_ip_port_num` (`host_ip`, `port_num`, `is_alive`);
+alter table t_meta_user_info
+    add column `is_alive` tinyint(4) GENERATED ALWAYS AS (if(`del_flag` = 0, 1, null)) VIRTUAL COMMENT '是否未删除: 1-未删除, NULL-已删除, 用于唯一索引忽略已删除的行' after `del_flag`,
+    drop index `idx01_employee_id`,
+    drop index `idx02_account_name`,
+    drop index `idx03_email`,
+    drop index `idx04_telephone`,
+    drop index `idx05_mobile`,
+    add unique key `idx01_employee_id` (`employee_id`, `is_alive`),
+    add unique key `idx02_account_name` (`account_name`, `is_alive`),
+    add unique key `idx03_email` (`email`, `is_alive`),
+    add unique key `idx04_telephone` (`telephone`, `is_alive`),
+    add unique key `idx05_mobile` (`mobile`, `is_alive`);
@@ -0,0 +1,3 @@
+alter table t_meta_mysql_server_info
+    add column `server_role` tinyint(4) NOT NULL DEFAULT '1' COMMENT '实例角色: 1-主库, 2-从库, 3-延迟从库' after `deployment_type`,
+    add column `read_weight` int(11) NOT NULL DEFAULT '1' COMMENT '读权重, 从库按权重从高到低选取, 0表示不参与读' after `server_role`;
@@ -0,0 +1,18 @@
+CREATE TABLE `t_sa_slow_query_advice` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `mysql_cluster_id` int(11) NOT NULL COMMENT 'mysql集群ID',
+  `db_id` int(11) NOT NULL COMMENT '数据库ID',
+  `sql_id` varchar(100) NOT NULL COMMENT 'sql指纹ID',
+  `fingerprint` mediumtext NOT NULL COMMENT 'sql指纹',
+  `sql_text` mediumtext NOT NULL COMMENT 'sql样例',
+  `advice` mediumtext DEFAULT NULL COMMENT '优化建议',
+  `message` mediumtext DEFAULT NULL COMMENT '执行日志',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx01_db_id_sql_id` (`db_id`, `sql_id`),
+  KEY `idx02_sql_id` (`sql_id`),
+  KEY `idx03_mysql_cluster_id` (`mysql_cluster_id`),
+  KEY `idx04_create_time` (`create_time`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '慢查询自动优化建议表';
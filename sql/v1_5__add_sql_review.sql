@@ -0,0 +1,35 @@
+CREATE TABLE `t_sr_rule_config` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `rule_name` varchar(100) NOT NULL COMMENT '规则名称',
+  `rule_level` tinyint(4) NOT NULL DEFAULT '1' COMMENT '违反规则时的结论: 2-警告, 3-阻断',
+  `threshold` bigint(20) NOT NULL DEFAULT '0' COMMENT '规则阈值, 例如: 大表的行数',
+  `status` tinyint(4) NOT NULL DEFAULT '1' COMMENT '状态: 0-停用, 1-启用',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx01_rule_name` (`rule_name`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = 'sql审核规则配置表';
+
+CREATE TABLE `t_sr_review_info` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `db_id` int(11) NOT NULL COMMENT '数据库ID',
+  `sql_text` mediumtext NOT NULL COMMENT 'sql脚本',
+  `verdict` tinyint(4) NOT NULL COMMENT '审核结论: 1-通过, 2-警告, 3-阻断',
+  `result` mediumtext NOT NULL COMMENT '每条语句的审核结果',
+  `approval_status` tinyint(4) NOT NULL DEFAULT '0' COMMENT '审批状态: 0-无需审批, 1-待审批, 2-已审批',
+  `approver_id` int(11) NOT NULL DEFAULT '0' COMMENT '审批人ID, 0表示未审批',
+  `approve_time` datetime(6) NOT NULL DEFAULT '1970-01-01 00:00:01.000000' COMMENT '审批时间',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx01_db_id` (`db_id`),
+  KEY `idx02_create_time` (`create_time`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = 'sql审核记录表';
+
+insert into t_sr_rule_config(rule_name, rule_level, threshold) values('drop_online', 3, 0);
+insert into t_sr_rule_config(rule_name, rule_level, threshold) values('alter_large_table', 2, 1000000);
+insert into t_sr_rule_config(rule_name, rule_level, threshold) values('missing_primary_key', 3, 0);
+insert into t_sr_rule_config(rule_name, rule_level, threshold) values('not_utf8mb4_charset', 3, 0);
+insert into t_sr_rule_config(rule_name, rule_level, threshold) values('missing_comment', 2, 0);
@@ -0,0 +1,15 @@
+CREATE TABLE `t_meta_audit_log` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `entity_type` varchar(100) NOT NULL COMMENT '实体类型: app, db, env, middleware_cluster, middleware_server, monitor_system, mysql_cluster, mysql_server, user',
+  `entity_id` int(11) NOT NULL COMMENT '实体ID',
+  `action` varchar(100) NOT NULL COMMENT '变更类型: create, update, delete, delete_cascade, undelete, add_relation, delete_relation',
+  `actor` varchar(100) NOT NULL DEFAULT '' COMMENT '变更人',
+  `request_id` varchar(100) NOT NULL DEFAULT '' COMMENT '请求ID',
+  `before_data` mediumtext NOT NULL COMMENT '变更前的实体, 空字符串表示实体不存在',
+  `after_data` mediumtext NOT NULL COMMENT '变更后的实体, 空字符串表示实体不存在',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx01_entity_type_entity_id` (`entity_type`, `entity_id`),
+  KEY `idx02_actor` (`actor`),
+  KEY `idx03_create_time` (`create_time`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '元数据变更审计表';
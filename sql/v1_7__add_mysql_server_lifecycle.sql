@@ -0,0 +1,7 @@
+alter table t_meta_mysql_server_info
+    add column `read_only` tinyint(4) NOT NULL DEFAULT '0' COMMENT '是否只读: 0-否, 1-是' after `read_weight`,
+    add column `cpu_cores` int(11) NOT NULL DEFAULT '0' COMMENT 'cpu核数, 0表示未知' after `read_only`,
+    add column `memory_size` int(11) NOT NULL DEFAULT '0' COMMENT '内存大小, 单位: MB, 0表示未知' after `cpu_cores`,
+    add column `disk_size` int(11) NOT NULL DEFAULT '0' COMMENT '磁盘大小, 单位: GB, 0表示未知' after `memory_size`,
+    add column `data_dir` varchar(200) NOT NULL DEFAULT '' COMMENT '数据目录' after `disk_size`,
+    add column `state` tinyint(4) NOT NULL DEFAULT '2' COMMENT '生命周期状态: 1-部署中, 2-在线, 3-维护中, 4-已下线' after `version`;
@@ -0,0 +1,14 @@
+CREATE TABLE `t_auth_token_info` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `user_id` int(11) NOT NULL COMMENT '用户ID',
+  `token_type` tinyint(4) NOT NULL COMMENT '令牌类型: 1-api token, 2-json web token',
+  `token_key` varchar(100) NOT NULL COMMENT '令牌标识, api token为令牌的sha256值, json web token为jti',
+  `description` varchar(100) NOT NULL DEFAULT '' COMMENT '描述',
+  `expire_time` datetime(6) NOT NULL COMMENT '过期时间',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已吊销',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx01_token_key` (`token_key`),
+  KEY `idx02_user_id` (`user_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '认证令牌表';
@@ -0,0 +1,2 @@
+alter table t_hc_operation_info
+    modify column `status` tinyint(4) NOT NULL DEFAULT '0' COMMENT '运行状态: 0-未运行, 1-运行中, 2-已完成, 3-已失败, 4-已中断';